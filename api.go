@@ -0,0 +1,36 @@
+package albatross
+
+import (
+	core "github.com/albatross-org/go-albatross/pkg/core"
+)
+
+// Store is an Albatross store, a folder containing a "config.yaml" file and an "entries" folder.
+type Store = core.Store
+
+// Reader is the part of a Store used to read entries and their attachments.
+type Reader = core.Reader
+
+// Writer is the part of a Store used to change entries.
+type Writer = core.Writer
+
+// ReadWriter is a Reader and a Writer, which a Store is.
+type ReadWriter = core.ReadWriter
+
+// The errors returned by a Store.
+type (
+	ErrStoreEncrypted     = core.ErrStoreEncrypted
+	ErrStoreDecrypted     = core.ErrStoreDecrypted
+	ErrEntryDoesntExist   = core.ErrEntryDoesntExist
+	ErrEntryAlreadyExists = core.ErrEntryAlreadyExists
+	ErrNotUsingGit        = core.ErrNotUsingGit
+)
+
+// Load loads the store at the path given, parsing its entries unless it's encrypted.
+func Load(path string) (*Store, error) {
+	return core.Load(path)
+}
+
+// LoadLazy is like Load but doesn't parse the entries until they're first needed.
+func LoadLazy(path string) (*Store, error) {
+	return core.LoadLazy(path)
+}
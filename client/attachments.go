@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Attachment is a file attached to an entry.
+type Attachment struct {
+	// Name is the path of the file relative to the entry's folder, such as "pizza.jpg" or "photos/slice.jpg".
+	Name string `json:"name"`
+
+	// Size is the size of the file in bytes.
+	Size int64 `json:"size"`
+
+	// Hash is the SHA-256 hash of the file's contents, in hex, which is used to download it.
+	Hash string `json:"hash"`
+
+	// URL is where the file can be downloaded from, relative to the server.
+	URL string `json:"url"`
+}
+
+// attachmentsResponse is the response listing the files attached to an entry.
+type attachmentsResponse struct {
+	Path        string       `json:"path"`
+	Attachments []Attachment `json:"attachments"`
+}
+
+// Attachments returns the files attached to the entry at the path given, sorted by name. The server must have been
+// started with access to the store for this to work.
+func (c *Client) Attachments(ctx context.Context, path string) ([]Attachment, error) {
+	var response attachmentsResponse
+
+	_, err := c.getJSON(ctx, "/attachments/"+strings.Trim(path, "/"), nil, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Attachments, nil
+}
+
+// Download writes the contents of an attachment to w, such as a file. If the token doesn't give access to the entry the
+// attachment belongs to, the error is an *ResponseError with the status code http.StatusNotFound.
+func (c *Client) Download(ctx context.Context, attachment Attachment, w io.Writer) error {
+	u := *c.base
+	u.Path += "/files/" + attachment.Hash
+
+	resp, err := c.do(ctx, func() (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, u.String(), nil)
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newResponseError(resp)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	if err != nil {
+		return fmt.Errorf("couldn't download %s: %w", attachment.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,278 @@
+// Package client is a Go client for the HTTP API served by the server package, such as by running
+// `albatross get server`. It lets other programs search and read entries without making the HTTP requests by hand.
+//
+//	c, err := client.NewClient("http://localhost:8080")
+//	if err != nil {
+//		return err
+//	}
+//
+//	c.SetToken("secret-token")
+//
+//	result, err := c.Search(ctx, client.SearchOptions{Paths: []string{"recipes"}, Sort: "alpha"})
+//
+// If the server allows it, entries can also be created, updated and deleted. Updates need the ETag of the entry as it
+// was read, so that changes made by someone else in the meantime aren't overwritten:
+//
+//	entry, etag, err := c.GetWithETag(ctx, "recipes/pizza")
+//	if err != nil {
+//		return err
+//	}
+//
+//	_, _, err = c.Update(ctx, "recipes/pizza", entry.OriginalContents+"\nMore cheese.", etag)
+//
+// To keep up to date without polling, Listen is told about every change to the entries being served:
+//
+//	err = c.Listen(ctx, func(event client.Event) error {
+//		fmt.Println(event.Type, event.Path)
+//		return nil
+//	})
+//
+// If the server has GraphQL enabled, Query can fetch exactly the fields needed, such as the backlinks of every entry in
+// a search, in a single request.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+)
+
+// DefaultRetries is the number of times a client retries a request which failed for a reason that might be temporary.
+const DefaultRetries = 3
+
+// DefaultBackoff is how long a client waits before retrying a request for the first time. It doubles after every retry.
+const DefaultBackoff = 500 * time.Millisecond
+
+// Client talks to an Albatross server.
+type Client struct {
+	base  *url.URL
+	http  *http.Client
+	token string
+
+	retries int
+	backoff time.Duration
+}
+
+// NewClient returns a new client for the server at baseURL, such as "http://localhost:8080".
+func NewClient(baseURL string) (*Client, error) {
+	base, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("server URL %q should start with http:// or https://", baseURL)
+	}
+
+	return &Client{
+		base:    base,
+		http:    http.DefaultClient,
+		retries: DefaultRetries,
+		backoff: DefaultBackoff,
+	}, nil
+}
+
+// SetToken sets the access token given with every request, needed if the server has an ACL. It's sent as a bearer
+// token in the Authorization header.
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// SetRetries sets how many times a request is retried if it fails because of a network error, the server being rate
+// limited or a server error, and how long to wait before the first retry. Zero retries disables retrying.
+func (c *Client) SetRetries(retries int, backoff time.Duration) {
+	c.retries = retries
+	c.backoff = backoff
+}
+
+// SetHTTPClient sets the *http.Client used to make requests, such as one with a timeout. By default,
+// http.DefaultClient is used.
+func (c *Client) SetHTTPClient(client *http.Client) {
+	c.http = client
+}
+
+// Get returns the entry at the path given, such as "food/pizza". If there's no entry at that path, or the token
+// doesn't give access to it, the error is an *ResponseError with the status code http.StatusNotFound.
+func (c *Client) Get(ctx context.Context, path string) (*entries.Entry, error) {
+	entry, _, err := c.GetWithETag(ctx, path)
+	return entry, err
+}
+
+// GetWithETag is like Get, but also returns the entry's ETag, which is needed to update it using Update.
+func (c *Client) GetWithETag(ctx context.Context, path string) (*entries.Entry, string, error) {
+	var entry entries.Entry
+
+	resp, err := c.getJSON(ctx, "/entries/"+strings.Trim(path, "/"), nil, "", &entry)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fillParents(&entry)
+	return &entry, resp.Header.Get("ETag"), nil
+}
+
+// Stats returns statistics about the entries being served. The server must have been started with access to the
+// store for this to work.
+func (c *Client) Stats(ctx context.Context) (albatross.Stats, error) {
+	var stats albatross.Stats
+
+	_, err := c.getJSON(ctx, "/stats", nil, "", &stats)
+	return stats, err
+}
+
+// getJSON makes a GET request and decodes the JSON response into out. If etag isn't blank, it's sent as If-None-Match
+// and the response status is http.StatusNotModified if it's unchanged, in which case out is left alone. The response
+// is returned so that its status and headers can be checked, though its body has already been closed.
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, etag string, out interface{}) (*http.Response, error) {
+	u := *c.base
+	u.Path += path
+	u.RawQuery = query.Encode()
+
+	resp, err := c.do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return resp, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, newResponseError(resp)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(out)
+	if err != nil {
+		return resp, fmt.Errorf("couldn't decode response from %s: %w", path, err)
+	}
+
+	return resp, nil
+}
+
+// sendJSON makes a request with a body, such as to create an entry, and decodes the JSON response into out. If etag
+// isn't blank, it's sent as If-Match so the request fails if the entry has changed. The response is returned so that
+// its headers can be checked, though its body has already been closed.
+func (c *Client) sendJSON(ctx context.Context, method, path, contentType string, body []byte, etag string, out interface{}) (*http.Response, error) {
+	u := *c.base
+	u.Path += path
+
+	resp, err := c.do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		if etag != "" {
+			req.Header.Set("If-Match", etag)
+		}
+
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, newResponseError(resp)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return resp, nil
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(out)
+	if err != nil {
+		return resp, fmt.Errorf("couldn't decode response from %s: %w", path, err)
+	}
+
+	return resp, nil
+}
+
+// do makes a request, retrying it if it fails for a reason that might be temporary. newRequest is called for every
+// attempt, since a request can't be reused once it has been sent.
+func (c *Client) do(ctx context.Context, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	backoff := c.backoff
+
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		req = req.WithContext(ctx)
+		req.Header.Set("Accept", "application/json")
+
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+
+		resp, err := c.http.Do(req)
+		if (err == nil && !retryable(resp.StatusCode)) || attempt >= c.retries {
+			return resp, err
+		}
+
+		wait := backoff
+		backoff *= 2
+
+		if err == nil {
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				wait = time.Duration(seconds) * time.Second
+			}
+
+			// The body has to be read and closed for the connection to be reused.
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// retryable returns true if a response with the status code given is worth retrying.
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// fillParents sets the parent of each of the entry's links, which isn't sent by the server.
+func fillParents(entry *entries.Entry) {
+	for i := range entry.OutboundLinks {
+		entry.OutboundLinks[i].Parent = entry
+	}
+}
@@ -0,0 +1,787 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/otiai10/copy"
+
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	"github.com/albatross-org/go-albatross/server"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+// testServer starts a server for the testing store, configured by setup if it isn't nil.
+func testServer(t *testing.T, setup func(s *server.Server)) (*Client, func()) {
+	t.Helper()
+
+	store, err := albatross.Load("../pkg/core/testdata/stores/testing.albatross")
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	return serveStore(t, store, setup)
+}
+
+// writableTestServer starts a server which allows writes for a copy of the testing store, configured by setup if it
+// isn't nil.
+func writableTestServer(t *testing.T, setup func(s *server.Server)) (*Client, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "albatross-client-test")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %s", err)
+	}
+
+	err = copy.Copy("../pkg/core/testdata/stores/testing.albatross", dir)
+	if err != nil {
+		t.Fatalf("couldn't copy test store: %s", err)
+	}
+
+	store, err := albatross.Load(dir)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	c, cleanup := serveStore(t, store, func(s *server.Server) {
+		s.SetWritable(nil)
+
+		if setup != nil {
+			setup(s)
+		}
+	})
+
+	return c, func() {
+		cleanup()
+		os.RemoveAll(dir)
+	}
+}
+
+// serveStore starts a server for the store given.
+func serveStore(t *testing.T, store *albatross.Store, setup func(s *server.Server)) (*Client, func()) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	collection, err := store.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error getting collection: %s", err)
+	}
+
+	s := server.NewServer(collection)
+	s.SetStore(store)
+
+	if setup != nil {
+		setup(s)
+	}
+
+	ts := httptest.NewServer(s.Handler())
+
+	c, err := NewClient(ts.URL)
+	if err != nil {
+		t.Fatalf("not expecting error creating client: %s", err)
+	}
+
+	return c, ts.Close
+}
+
+func TestClientSearch(t *testing.T) {
+	c, cleanup := testServer(t, nil)
+	defer cleanup()
+
+	result, err := c.Search(context.Background(), SearchOptions{Paths: []string{"food"}, Sort: "alpha"})
+	if err != nil {
+		t.Fatalf("not expecting error searching: %s", err)
+	}
+
+	Equal(t, 2, result.Matched, "expecting two food entries")
+	if Len(t, result.Entries, 2) {
+		Equal(t, "food/ice-cream", result.Entries[0].Path)
+		Equal(t, "food/pizza", result.Entries[1].Path)
+	}
+
+	result, err = c.Search(context.Background(), SearchOptions{Paths: []string{"food"}, Number: 1})
+	Nil(t, err)
+	Equal(t, 2, result.Matched, "expecting matched to count entries before number is applied")
+	Len(t, result.Entries, 1)
+
+	result, err = c.Search(context.Background(), SearchOptions{Paths: []string{"food"}, Contents: []string{"pizza"}})
+	Nil(t, err)
+	Nil(t, result.Matches, "expecting no matches unless they're asked for")
+
+	result, err = c.Search(context.Background(), SearchOptions{Paths: []string{"food"}, Contents: []string{"pizza"}, ShowMatches: true})
+	Nil(t, err)
+	if NotEmpty(t, result.Matches["food/pizza"]) {
+		match := result.Matches["food/pizza"][0]
+		Equal(t, "contents", match.Field)
+		Equal(t, "pizza", match.Snippet[match.SnippetStart:match.SnippetEnd])
+	}
+
+	result, err = c.Search(context.Background(), SearchOptions{PathsExact: []string{"does/not/exist"}})
+	Nil(t, err, "expecting no matches not to be an error")
+	Equal(t, 0, result.Matched)
+	Empty(t, result.Entries)
+
+	result, err = c.Search(context.Background(), SearchOptions{
+		Paths: []string{"journal"},
+		From:  time.Date(2020, 8, 7, 0, 0, 0, 0, time.UTC),
+	})
+	Nil(t, err)
+	for _, entry := range result.Entries {
+		False(t, entry.Date.Before(time.Date(2020, 8, 7, 0, 0, 0, 0, time.UTC)), "expecting %s to be after from", entry.Path)
+	}
+
+	result, err = c.Search(context.Background(), SearchOptions{Query: `path:food AND NOT title:~"(?i)ice"`})
+	Nil(t, err)
+	if Len(t, result.Entries, 1) {
+		Equal(t, "food/pizza", result.Entries[0].Path)
+	}
+
+	result, err = c.Search(context.Background(), SearchOptions{Paths: []string{"food"}, Sort: "length", Reverse: true})
+	Nil(t, err)
+	if Len(t, result.Entries, 2) {
+		GreaterOrEqual(t, result.Entries[0].WordCount(), result.Entries[1].WordCount(), "expecting the longest entry first")
+	}
+
+	seen := map[string]bool{}
+	options := SearchOptions{Sort: "alpha", Number: 2}
+	for {
+		result, err = c.Search(context.Background(), options)
+		if err != nil {
+			t.Fatalf("not expecting error paging through entries: %s", err)
+		}
+
+		Equal(t, options.Offset, result.Offset)
+		for _, entry := range result.Entries {
+			False(t, seen[entry.Path], "expecting %s to only be on one page", entry.Path)
+			seen[entry.Path] = true
+		}
+
+		if result.Next == 0 {
+			break
+		}
+
+		Equal(t, options.Offset+2, result.Next, "expecting the next page to start after this one")
+		options.Offset = result.Next
+	}
+	Equal(t, result.Matched, len(seen), "expecting every entry to be on a page")
+
+	result, err = c.Search(context.Background(), SearchOptions{Paths: []string{"food"}, Offset: 10})
+	Nil(t, err, "expecting an offset past the end not to be an error")
+	Equal(t, 2, result.Matched)
+	Empty(t, result.Entries)
+
+	_, err = c.Search(context.Background(), SearchOptions{Paths: []string{"food"}, Offset: -1})
+	Equal(t, http.StatusBadRequest, statusCode(err), "expecting an error for a negative offset")
+
+	_, err = c.Search(context.Background(), SearchOptions{Paths: []string{"food"}, Sort: "size"})
+	Equal(t, http.StatusBadRequest, statusCode(err), "expecting an error for an unknown sort")
+
+	_, err = c.Search(context.Background(), SearchOptions{Query: `path:food AND`})
+	var e *ResponseError
+	if True(t, errors.As(err, &e), "expecting a *ResponseError for an invalid query") {
+		Equal(t, http.StatusBadRequest, e.StatusCode)
+	}
+}
+
+func TestClientGet(t *testing.T) {
+	c, cleanup := testServer(t, nil)
+	defer cleanup()
+
+	entry, err := c.Get(context.Background(), "food/pizza")
+	if err != nil {
+		t.Fatalf("not expecting error getting entry: %s", err)
+	}
+
+	Equal(t, "food/pizza", entry.Path)
+	NotEmpty(t, entry.Title)
+	for _, link := range entry.OutboundLinks {
+		Equal(t, entry, link.Parent, "expecting links to have their parent set")
+	}
+
+	_, err = c.Get(context.Background(), "food/burger")
+
+	var e *ResponseError
+	if True(t, errors.As(err, &e), "expecting a *ResponseError for a missing entry") {
+		Equal(t, http.StatusNotFound, e.StatusCode)
+		Equal(t, "entry not found", e.Type)
+	}
+}
+
+func TestClientGetTrackViews(t *testing.T) {
+	dir, err := ioutil.TempDir("", "albatross-client-test")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = copy.Copy("../pkg/core/testdata/stores/testing.albatross", dir)
+	if err != nil {
+		t.Fatalf("couldn't copy test store: %s", err)
+	}
+
+	store, err := albatross.Load(dir)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	c, cleanup := serveStore(t, store, func(s *server.Server) { s.SetTrackViews(true) })
+	defer cleanup()
+
+	_, err = c.Get(context.Background(), "food/pizza")
+	Nil(t, err)
+
+	views, err := store.RecentViews()
+	Nil(t, err)
+	if Len(t, views, 1, "expecting getting an entry to record it as viewed") {
+		Equal(t, "food/pizza", views[0].Path)
+	}
+}
+
+func TestClientToken(t *testing.T) {
+	c, cleanup := testServer(t, func(s *server.Server) {
+		err := s.SetACL([]server.Grant{{Name: "food", Token: "secret", Paths: []string{"food"}}})
+		if err != nil {
+			t.Fatalf("not expecting error setting ACL: %s", err)
+		}
+	})
+	defer cleanup()
+
+	_, err := c.Search(context.Background(), SearchOptions{})
+
+	var e *ResponseError
+	if True(t, errors.As(err, &e), "expecting a *ResponseError without a token") {
+		Equal(t, http.StatusUnauthorized, e.StatusCode)
+	}
+
+	c.SetToken("secret")
+
+	result, err := c.Search(context.Background(), SearchOptions{})
+	Nil(t, err)
+	for _, entry := range result.Entries {
+		Contains(t, entry.Path, "food/", "expecting only entries the token gives access to")
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	var requests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			http.Error(w, `{"error_type": "rate limited", "error": "too many requests"}`, http.StatusTooManyRequests)
+			return
+		}
+
+		w.Write([]byte(`{"matched": 0, "entries": []}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL)
+	if err != nil {
+		t.Fatalf("not expecting error creating client: %s", err)
+	}
+
+	c.SetRetries(1, time.Millisecond)
+	_, err = c.Search(context.Background(), SearchOptions{})
+
+	var e *ResponseError
+	if True(t, errors.As(err, &e), "expecting an error after running out of retries") {
+		Equal(t, http.StatusTooManyRequests, e.StatusCode)
+		Equal(t, "rate limited", e.Type)
+	}
+
+	c.SetRetries(3, time.Millisecond)
+	_, err = c.Search(context.Background(), SearchOptions{})
+	Nil(t, err, "expecting request to succeed once retried")
+	Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestClientWatch(t *testing.T) {
+	c, cleanup := testServer(t, nil)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	err := c.Watch(ctx, SearchOptions{Paths: []string{"food"}}, 10*time.Millisecond, func(result SearchResult) error {
+		calls++
+		Equal(t, 2, result.Matched)
+		return nil
+	})
+
+	Equal(t, context.DeadlineExceeded, err)
+	Equal(t, 1, calls, "expecting fn to only be called once when entries don't change")
+
+	stop := errors.New("stop")
+	err = c.Watch(context.Background(), SearchOptions{}, time.Millisecond, func(result SearchResult) error {
+		return stop
+	})
+	Equal(t, stop, err, "expecting Watch to return the error from fn")
+}
+
+func TestClientWrite(t *testing.T) {
+	c, cleanup := writableTestServer(t, func(s *server.Server) {
+		err := s.SetACL([]server.Grant{{Name: "me", Token: "secret", Paths: []string{""}, Write: true}})
+		if err != nil {
+			t.Fatalf("not expecting error setting ACL: %s", err)
+		}
+	})
+	defer cleanup()
+
+	c.SetToken("secret")
+
+	ctx := context.Background()
+
+	entry, etag, err := c.Create(ctx, "food/burger", "---\ntitle: Burger\n---\n\nA burger.")
+	if err != nil {
+		t.Fatalf("not expecting error creating entry: %s", err)
+	}
+
+	Equal(t, "Burger", entry.Title)
+	NotEmpty(t, etag)
+
+	_, _, err = c.Create(ctx, "food/burger", "Another burger.")
+	Equal(t, http.StatusConflict, statusCode(err), "expecting creating an existing entry to conflict")
+
+	_, _, err = c.Create(ctx, "../outside", "Outside.")
+	Equal(t, http.StatusBadRequest, statusCode(err), "expecting paths outside the store to be rejected")
+
+	result, err := c.Search(ctx, SearchOptions{Paths: []string{"food"}})
+	Nil(t, err)
+	Equal(t, 3, result.Matched, "expecting the new entry to be served")
+
+	updated, newETag, err := c.Update(ctx, "food/burger", "---\ntitle: Cheeseburger\n---\n\nA burger with cheese.", etag)
+	if err != nil {
+		t.Fatalf("not expecting error updating entry: %s", err)
+	}
+
+	Equal(t, "Cheeseburger", updated.Title)
+	NotEqual(t, etag, newETag)
+
+	_, _, err = c.Update(ctx, "food/burger", "Stale.", etag)
+	Equal(t, http.StatusPreconditionFailed, statusCode(err), "expecting updates with an old ETag to be rejected")
+
+	_, _, err = c.Update(ctx, "food/burger", "No ETag.", "")
+	Equal(t, http.StatusPreconditionRequired, statusCode(err), "expecting updates without an ETag to be rejected")
+
+	entry, etag, err = c.GetWithETag(ctx, "food/burger")
+	Nil(t, err)
+	Equal(t, "Cheeseburger", entry.Title, "expecting rejected updates not to change the entry")
+	Equal(t, newETag, etag)
+
+	attachments, err := c.Attach(ctx, "food/burger", "burger.txt", strings.NewReader("bun, patty, bun"))
+	if err != nil {
+		t.Fatalf("not expecting error attaching file: %s", err)
+	}
+
+	if Len(t, attachments, 1) {
+		Equal(t, "burger.txt", attachments[0].Name)
+		Equal(t, int64(len("bun, patty, bun")), attachments[0].Size)
+
+		var buf bytes.Buffer
+		Nil(t, c.Download(ctx, attachments[0], &buf))
+		Equal(t, "bun, patty, bun", buf.String())
+	}
+
+	_, err = c.Attach(ctx, "food/burger", "burger.txt", strings.NewReader("again"))
+	Equal(t, http.StatusConflict, statusCode(err), "expecting attaching a file with the same name to conflict")
+
+	err = c.Delete(ctx, "food/burger", "")
+	if err != nil {
+		t.Fatalf("not expecting error deleting entry: %s", err)
+	}
+
+	_, err = c.Get(ctx, "food/burger")
+	Equal(t, http.StatusNotFound, statusCode(err), "expecting the deleted entry not to be served")
+
+	err = c.Delete(ctx, "food/burger", "")
+	Equal(t, http.StatusNotFound, statusCode(err))
+}
+
+func TestClientListen(t *testing.T) {
+	c, cleanup := writableTestServer(t, func(s *server.Server) {
+		err := s.SetACL([]server.Grant{
+			{Name: "all", Token: "all", Paths: []string{""}, Write: true},
+			{Name: "food", Token: "food", Paths: []string{"food"}},
+		})
+		if err != nil {
+			t.Fatalf("not expecting error setting ACL: %s", err)
+		}
+	})
+	defer cleanup()
+
+	c.SetToken("all")
+
+	listener, err := NewClient(c.base.String())
+	if err != nil {
+		t.Fatalf("not expecting error creating client: %s", err)
+	}
+
+	listener.SetToken("food")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan Event, 16)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- listener.Listen(ctx, func(event Event) error {
+			events <- event
+			return nil
+		})
+	}()
+
+	// There's no way to tell when the listener has connected, so entries are created until one of them is seen.
+	connected := false
+	for i := 0; i < 100 && !connected; i++ {
+		_, _, err = c.Create(ctx, fmt.Sprintf("food/sync-%d", i), "Sync.")
+		if err != nil {
+			t.Fatalf("not expecting error creating entry: %s", err)
+		}
+
+		select {
+		case <-events:
+			connected = true
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+
+	if !connected {
+		t.Fatalf("expecting an event once the listener has connected")
+	}
+
+	// Events for earlier entries might still be on their way, so they're skipped.
+	_, _, err = c.Create(ctx, "food/burger", "---\ntitle: Burger\n---\n\nA burger.")
+	if err != nil {
+		t.Fatalf("not expecting error creating entry: %s", err)
+	}
+
+	next := func() Event {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(time.Second):
+			t.Fatalf("expecting an event")
+			return Event{}
+		}
+	}
+
+	event := next()
+	for event.Path != "food/burger" {
+		event = next()
+	}
+
+	Equal(t, EventCreated, event.Type)
+	NotEmpty(t, event.ETag)
+
+	_, _, err = c.Create(ctx, "journal/secret", "Not for the food token.")
+	if err != nil {
+		t.Fatalf("not expecting error creating entry: %s", err)
+	}
+
+	_, etag, err := c.GetWithETag(ctx, "food/burger")
+	Nil(t, err)
+
+	_, newETag, err := c.Update(ctx, "food/burger", "---\ntitle: Cheeseburger\n---\n\nA burger with cheese.", etag)
+	if err != nil {
+		t.Fatalf("not expecting error updating entry: %s", err)
+	}
+
+	Equal(t, Event{Type: EventUpdated, Path: "food/burger", ETag: newETag}, next(), "expecting changes to entries the token can't access to be left out")
+
+	err = c.Delete(ctx, "food/burger", "")
+	if err != nil {
+		t.Fatalf("not expecting error deleting entry: %s", err)
+	}
+
+	Equal(t, Event{Type: EventDeleted, Path: "food/burger"}, next())
+
+	cancel()
+	Equal(t, context.Canceled, <-done, "expecting Listen to stop when the context is cancelled")
+}
+
+func TestClientAttachments(t *testing.T) {
+	c, cleanup := testServer(t, func(s *server.Server) {
+		err := s.SetACL([]server.Grant{
+			{Name: "food", Token: "food", Paths: []string{"food"}},
+			{Name: "journal", Token: "journal", Paths: []string{"journal"}},
+		})
+		if err != nil {
+			t.Fatalf("not expecting error setting ACL: %s", err)
+		}
+	})
+	defer cleanup()
+
+	ctx := context.Background()
+	c.SetToken("food")
+
+	attachments, err := c.Attachments(ctx, "food/pizza")
+	if err != nil {
+		t.Fatalf("not expecting error listing attachments: %s", err)
+	}
+
+	if !Len(t, attachments, 1) {
+		return
+	}
+
+	expected, err := ioutil.ReadFile("../pkg/core/testdata/stores/testing.albatross/entries/food/pizza/pizza.jpg")
+	if err != nil {
+		t.Fatalf("couldn't read attachment: %s", err)
+	}
+
+	Equal(t, "pizza.jpg", attachments[0].Name)
+	Equal(t, fmt.Sprintf("%x", sha256.Sum256(expected)), attachments[0].Hash)
+	Equal(t, int64(len(expected)), attachments[0].Size)
+
+	var buf bytes.Buffer
+	Nil(t, c.Download(ctx, attachments[0], &buf))
+	Equal(t, expected, buf.Bytes())
+
+	attachments, err = c.Attachments(ctx, "food/ice-cream")
+	Nil(t, err)
+	Empty(t, attachments)
+
+	c.SetToken("journal")
+
+	err = c.Download(ctx, Attachment{Name: "pizza.jpg", Hash: fmt.Sprintf("%x", sha256.Sum256(expected))}, &buf)
+	Equal(t, http.StatusNotFound, statusCode(err), "expecting attachments of entries the token can't access not to be found")
+
+	_, err = c.Attachments(ctx, "food/pizza")
+	Equal(t, http.StatusNotFound, statusCode(err))
+
+	err = c.Download(ctx, Attachment{Hash: "not-a-hash"}, &buf)
+	Equal(t, http.StatusBadRequest, statusCode(err))
+}
+
+func TestClientFolders(t *testing.T) {
+	c, cleanup := testServer(t, func(s *server.Server) {
+		err := s.SetACL([]server.Grant{
+			{Name: "food", Token: "food", Paths: []string{"food"}},
+			{Name: "journal", Token: "journal", Paths: []string{"journal"}},
+		})
+		if err != nil {
+			t.Fatalf("not expecting error setting ACL: %s", err)
+		}
+	})
+	defer cleanup()
+
+	ctx := context.Background()
+	c.SetToken("food")
+
+	folders, err := c.Folders(ctx)
+	if err != nil {
+		t.Fatalf("not expecting error listing folders: %s", err)
+	}
+
+	if Len(t, folders, 1) {
+		Equal(t, "food", folders[0].Path)
+		Equal(t, "Food & Drink", folders[0].Title)
+		Equal(t, 1, folders[0].Weight)
+		Equal(t, []string{"Food & Drink"}, folders[0].Titles)
+	}
+
+	c.SetToken("journal")
+
+	folders, err = c.Folders(ctx)
+	Nil(t, err)
+	Empty(t, folders, "expecting folders without any entries the token can access not to be listed")
+}
+
+func TestClientGraphQL(t *testing.T) {
+	c, cleanup := testServer(t, nil)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	err := c.Query(ctx, "{ tags { tag } }", nil, nil)
+	Equal(t, http.StatusNotFound, statusCode(err), "expecting GraphQL to be disabled by default")
+
+	c, cleanup = testServer(t, func(s *server.Server) {
+		s.SetGraphQL(true)
+
+		err := s.SetACL([]server.Grant{
+			{Name: "all", Token: "all", Paths: []string{""}},
+			{Name: "food", Token: "food", Paths: []string{"food"}},
+		})
+		if err != nil {
+			t.Fatalf("not expecting error setting ACL: %s", err)
+		}
+	})
+	defer cleanup()
+
+	c.SetToken("food")
+
+	var out struct {
+		Pizzas struct {
+			Matched int
+			Entries []struct {
+				Path          string
+				Title         string
+				Date          string
+				OutboundLinks []struct {
+					Path  string
+					Name  string
+					Entry *struct{ Title string }
+				}
+				Attachments []struct{ Name string }
+			}
+		}
+		Journal *struct{ Title string }
+	}
+
+	query := `
+		query Pizza($path: [[String!]!], $format: String) {
+			pizzas: search(pathsMatch: $path, sort: "path") {
+				matched
+				entries { ...entryFields }
+			}
+			journal: entry(path: "journal/2020-08-06") { title }
+		}
+
+		fragment entryFields on Entry {
+			path
+			title
+			date(format: $format)
+			outboundLinks { path name entry { title } }
+			attachments { name }
+		}`
+
+	err = c.Query(ctx, query, map[string]interface{}{"path": "food/pizza", "format": "2006-01-02"}, &out)
+	if !Nil(t, err) || !Len(t, out.Pizzas.Entries, 1) {
+		return
+	}
+
+	pizza := out.Pizzas.Entries[0]
+	Equal(t, 1, out.Pizzas.Matched)
+	Equal(t, "food/pizza", pizza.Path)
+	Equal(t, "Pizza!", pizza.Title)
+	Equal(t, "2020-08-06", pizza.Date)
+	Equal(t, []struct{ Name string }{{"pizza.jpg"}}, pizza.Attachments)
+	Nil(t, out.Journal, "expecting entries the token can't access not to be found")
+
+	if Len(t, pizza.OutboundLinks, 1) {
+		Equal(t, "moods/hunger", pizza.OutboundLinks[0].Path)
+		Equal(t, "Hungry", pizza.OutboundLinks[0].Name)
+		Nil(t, pizza.OutboundLinks[0].Entry, "expecting links to entries the token can't access not to be resolved")
+	}
+
+	c.SetToken("all")
+
+	var backlinks struct {
+		Entry struct {
+			Backlinks []struct{ Path string }
+		}
+	}
+
+	err = c.Query(ctx, `{ entry(path: "moods/hunger") { backlinks { path } } }`, nil, &backlinks)
+	Nil(t, err)
+	Contains(t, backlinks.Entry.Backlinks, struct{ Path string }{"food/pizza"})
+
+	var transcluded struct {
+		Entry struct {
+			Contents            string
+			TranscludedContents string
+		}
+	}
+
+	err = c.Query(ctx, `{ entry(path: "moods/hunger") { contents transcludedContents } }`, nil, &transcluded)
+	Nil(t, err)
+	Equal(t, transcluded.Entry.Contents, transcluded.Entry.TranscludedContents, "expecting an entry without embeds to be unchanged")
+
+	var directives map[string]map[string]interface{}
+
+	err = c.Query(ctx, `query($full: Boolean!) { entry(path: "food/pizza") { __typename title @include(if: $full) path @skip(if: true) } }`, map[string]interface{}{"full": true}, &directives)
+	Nil(t, err)
+	Equal(t, map[string]interface{}{"__typename": "Entry", "title": "Pizza!"}, directives["entry"])
+
+	var errs GraphQLErrors
+
+	err = c.Query(ctx, "{ search { entries { colour } } }", nil, nil)
+	if True(t, errors.As(err, &errs), "expecting GraphQLErrors for unknown fields") && Len(t, errs, 1) {
+		Contains(t, errs[0].Message, "colour")
+		Len(t, errs[0].Locations, 1)
+	}
+
+	var partial struct {
+		Valid   *struct{ Matched int }
+		Invalid *struct{ Matched int }
+	}
+
+	err = c.Query(ctx, `{ valid: search { matched } invalid: search(pathsRegex: "(") { matched } }`, nil, &partial)
+	if True(t, errors.As(err, &errs), "expecting GraphQLErrors for invalid regular expressions") && Len(t, errs, 1) {
+		Equal(t, []interface{}{"invalid"}, errs[0].Path)
+	}
+
+	if NotNil(t, partial.Valid, "expecting fields without errors to still be given") {
+		Equal(t, 7, partial.Valid.Matched)
+	}
+	Nil(t, partial.Invalid)
+
+	err = c.Query(ctx, "mutation { tags { tag } }", nil, nil)
+	True(t, errors.As(err, &errs), "expecting mutations not to be supported")
+}
+
+func TestClientWriteForbidden(t *testing.T) {
+	c, cleanup := testServer(t, nil)
+	defer cleanup()
+
+	_, _, err := c.Create(context.Background(), "food/burger", "A burger.")
+	Equal(t, http.StatusForbidden, statusCode(err), "expecting servers to be read-only by default")
+
+	c, cleanup = writableTestServer(t, func(s *server.Server) {
+		err := s.SetACL([]server.Grant{
+			{Name: "reader", Token: "reader", Paths: []string{""}},
+			{Name: "food", Token: "food", Paths: []string{"food"}, Write: true},
+		})
+		if err != nil {
+			t.Fatalf("not expecting error setting ACL: %s", err)
+		}
+	})
+	defer cleanup()
+
+	c.SetToken("reader")
+	_, _, err = c.Create(context.Background(), "food/burger", "A burger.")
+	Equal(t, http.StatusForbidden, statusCode(err), "expecting read-only grants not to be able to write")
+
+	c.SetToken("food")
+	_, _, err = c.Create(context.Background(), "journal/burger", "A burger.")
+	Equal(t, http.StatusForbidden, statusCode(err), "expecting grants not to be able to write outside their paths")
+
+	_, _, err = c.Create(context.Background(), "food/burger", "A burger.")
+	Nil(t, err)
+
+	c, cleanup = writableTestServer(t, nil)
+	defer cleanup()
+
+	_, _, err = c.Create(context.Background(), "food/burger", "A burger.")
+	Equal(t, http.StatusUnauthorized, statusCode(err), "expecting writes to need a token even without an ACL")
+}
+
+// statusCode returns the status code of a *ResponseError, or 0 if err isn't one.
+func statusCode(err error) int {
+	var e *ResponseError
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+
+	return 0
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient("localhost:8080")
+	NotNil(t, err, "expecting error for URL without scheme")
+}
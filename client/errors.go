@@ -0,0 +1,45 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// ResponseError is returned when the server responds with an error.
+type ResponseError struct {
+	// StatusCode is the HTTP status code of the response, such as http.StatusNotFound.
+	StatusCode int
+
+	// Type is a short description of what went wrong, such as "error parsing date".
+	Type string `json:"error_type"`
+
+	// Message is the error message.
+	Message string `json:"error"`
+}
+
+// Error returns the error message.
+func (e *ResponseError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("server responded with %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+	}
+
+	return fmt.Sprintf("server responded with %d %s: %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Type, e.Message)
+}
+
+// newResponseError returns the *ResponseError for a response which wasn't successful.
+func newResponseError(resp *http.Response) error {
+	e := &ResponseError{StatusCode: resp.StatusCode}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if json.Unmarshal(body, e) != nil {
+		e.Message = string(body)
+	}
+
+	return e
+}
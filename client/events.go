@@ -0,0 +1,100 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// The types of change an Event can describe.
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+)
+
+// Event describes a change to an entry being served.
+type Event struct {
+	// Type is the type of change, EventCreated, EventUpdated or EventDeleted.
+	Type string `json:"type"`
+
+	// Path is the path of the entry which changed.
+	Path string `json:"path"`
+
+	// ETag is the entry's ETag after the change. It's blank for deleted entries.
+	ETag string `json:"etag,omitempty"`
+}
+
+// Listen calls handle for every change made to the entries being served, until the context is cancelled, handle
+// returns an error or the connection is lost. The server only knows about changes if it's watching the store or allows
+// writes. Changes made while not listening are missed, so after reconnecting entries should be read again.
+//
+// Since the connection stays open, the *http.Client given to SetHTTPClient shouldn't have a timeout.
+func (c *Client) Listen(ctx context.Context, handle func(Event) error) error {
+	u := *c.base
+	u.Path += "/events"
+
+	resp, err := c.do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Accept", "text/event-stream")
+
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newResponseError(resp)
+	}
+
+	var data strings.Builder
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Events are separated by blank lines. Lines starting with a colon are comments, and only the data is needed
+		// since it includes the type of the event.
+		if line == "" {
+			if data.Len() == 0 {
+				continue
+			}
+
+			var event Event
+
+			err = json.Unmarshal([]byte(data.String()), &event)
+			if err != nil {
+				return fmt.Errorf("couldn't decode event: %w", err)
+			}
+
+			data.Reset()
+
+			err = handle(event)
+			if err != nil {
+				return err
+			}
+		} else if strings.HasPrefix(line, "data:") {
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("server closed the connection")
+}
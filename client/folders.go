@@ -0,0 +1,34 @@
+package client
+
+import (
+	"context"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// Folder is a folder described by a _meta.yaml file, see entries.Folder.
+type Folder struct {
+	entries.Folder
+
+	// Titles are the titles of every folder leading to this one, including itself, such as ["School", "A-Level"] for
+	// "school/a-level".
+	Titles []string `json:"titles"`
+}
+
+// foldersResponse is the response listing the folders described by a _meta.yaml file.
+type foldersResponse struct {
+	Folders []Folder `json:"folders"`
+}
+
+// Folders returns the folders described by a _meta.yaml file which contain at least one of the entries being served,
+// sorted by path.
+func (c *Client) Folders(ctx context.Context) ([]Folder, error) {
+	var response foldersResponse
+
+	_, err := c.getJSON(ctx, "/folders", nil, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Folders, nil
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GraphQLError is an error from a GraphQL query.
+type GraphQLError struct {
+	// Message is the error message.
+	Message string `json:"message"`
+
+	// Locations are where in the query the error is, if anywhere.
+	Locations []struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"locations"`
+
+	// Path is the path to the field in the response which gave the error, such as ["search", "entries", 0, "attachments"].
+	Path []interface{} `json:"path"`
+}
+
+// GraphQLErrors is returned by Query when the server gave errors for the query.
+type GraphQLErrors []GraphQLError
+
+// Error returns the error messages.
+func (e GraphQLErrors) Error() string {
+	messages := []string{}
+	for _, err := range e {
+		messages = append(messages, err.Message)
+	}
+
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+// Query runs a GraphQL query and decodes the data of the response into out, which should be a pointer to a struct or
+// map matching the shape of the query. The server has to have GraphQL enabled, and its schema is served at
+// /graphql/schema. For example:
+//
+//	var out struct {
+//		Search struct {
+//			Entries []struct {
+//				Path  string
+//				Title string
+//			}
+//		}
+//	}
+//
+//	err := c.Query(ctx, `query($path: String!) { search(pathsMatch: [[$path]]) { entries { path title } } }`, map[string]interface{}{"path": "recipes"}, &out)
+//
+// If the server gives errors, they're returned as GraphQLErrors. Fields which gave an error are null, but the rest of
+// the data is still decoded into out.
+func (c *Client) Query(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	u := *c.base
+	u.Path += "/graphql"
+
+	resp, err := c.do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Queries which can't be run at all, such as because they're invalid, are given with 400 Bad Request and the same
+	// format of body.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		return newResponseError(resp)
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors GraphQLErrors   `json:"errors"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return fmt.Errorf("couldn't decode response from /graphql: %w", err)
+	}
+
+	if out != nil && len(result.Data) != 0 {
+		err = json.Unmarshal(result.Data, out)
+		if err != nil {
+			return fmt.Errorf("couldn't decode data from /graphql: %w", err)
+		}
+	}
+
+	if len(result.Errors) != 0 {
+		return result.Errors
+	}
+
+	return nil
+}
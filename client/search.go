@@ -0,0 +1,224 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// SearchOptions are the options for searching entries. They mirror the flags of `albatross get`: each value in a slice
+// must match, and a single value can give alternatives separated by " OR ", such as "recipes OR food".
+type SearchOptions struct {
+	// From and Until only allow entries with dates between them. Zero values aren't used.
+	From  time.Time
+	Until time.Time
+
+	// MinLength and MaxLength only allow entries with lengths between them. Zero values aren't used.
+	MinLength int
+	MaxLength int
+
+	Tags    []string
+	TagsNot []string
+
+	Paths         []string // Substring.
+	PathsExact    []string
+	PathsNot      []string // Substring.
+	PathsExactNot []string
+
+	Titles         []string // Substring.
+	TitlesExact    []string
+	TitlesNot      []string // Substring.
+	TitlesExactNot []string
+
+	Contents         []string // Substring.
+	ContentsExact    []string
+	ContentsNot      []string // Substring.
+	ContentsExactNot []string
+
+	// Query is an expression in the query language which entries must also match, such as
+	// `path:school/* AND tag:@?physics`. See entries.ParseExpression for the syntax.
+	Query string
+
+	// Sort is the sorting scheme, such as "alpha", "date", "length" or "meta:author", see entries.List.SortBy. If it's
+	// blank, entries aren't in any particular order.
+	Sort string
+
+	// Reverse reverses the entries returned.
+	Reverse bool
+
+	// Number is the number of entries to return. Zero returns every entry that matched.
+	Number int
+
+	// Offset is the number of entries to skip, for paging through entries along with Number. When either is given, the
+	// server breaks any ties left by Sort using the path, so each page carries on from the last. SearchResult.Next is
+	// the offset of the next page.
+	Offset int
+
+	// ShowMatches also returns where Titles and Contents matched each entry, in SearchResult.Matches.
+	ShowMatches bool
+}
+
+// dateFormat is the format used to send dates to the server.
+const dateFormat = time.RFC3339
+
+// values returns the search options as query parameters for /search.
+func (o SearchOptions) values() url.Values {
+	v := url.Values{}
+
+	if !o.From.IsZero() || !o.Until.IsZero() {
+		v.Set("date-format", dateFormat)
+	}
+
+	if !o.From.IsZero() {
+		v.Set("from", o.From.Format(dateFormat))
+	}
+
+	if !o.Until.IsZero() {
+		v.Set("until", o.Until.Format(dateFormat))
+	}
+
+	if o.MinLength != 0 {
+		v.Set("min-length", strconv.Itoa(o.MinLength))
+	}
+
+	if o.MaxLength != 0 {
+		v.Set("max-length", strconv.Itoa(o.MaxLength))
+	}
+
+	lists := map[string][]string{
+		"tag":                o.Tags,
+		"tag-not":            o.TagsNot,
+		"path":               o.Paths,
+		"path-exact":         o.PathsExact,
+		"path-not":           o.PathsNot,
+		"path-exact-not":     o.PathsExactNot,
+		"title":              o.Titles,
+		"title-exact":        o.TitlesExact,
+		"title-not":          o.TitlesNot,
+		"title-exact-not":    o.TitlesExactNot,
+		"contents":           o.Contents,
+		"contents-exact":     o.ContentsExact,
+		"contents-not":       o.ContentsNot,
+		"contents-exact-not": o.ContentsExactNot,
+	}
+
+	for key, values := range lists {
+		for _, value := range values {
+			v.Add(key, value)
+		}
+	}
+
+	if o.Query != "" {
+		v.Set("q", o.Query)
+	}
+
+	if o.Sort != "" {
+		v.Set("sort", o.Sort)
+	}
+
+	if o.Reverse {
+		v.Set("rev", "true")
+	}
+
+	if o.Number != 0 {
+		v.Set("number", strconv.Itoa(o.Number))
+	}
+
+	if o.Offset != 0 {
+		v.Set("offset", strconv.Itoa(o.Offset))
+	}
+
+	if o.ShowMatches {
+		v.Set("show", "matches")
+	}
+
+	return v
+}
+
+// SearchResult is the result of a search.
+type SearchResult struct {
+	// Matched is the number of entries that matched, before SearchOptions.Offset and SearchOptions.Number were applied.
+	Matched int `json:"matched"`
+
+	// Offset is the number of entries skipped before Entries.
+	Offset int `json:"offset"`
+
+	// Next is the offset of the next page of entries, or zero if there aren't any entries after Entries.
+	Next int `json:"next"`
+
+	// Entries are the entries that matched.
+	Entries []*entries.Entry `json:"entries"`
+
+	// Matches are where Titles and Contents matched each entry, by its path. They're only set if
+	// SearchOptions.ShowMatches is true.
+	Matches map[string][]entries.Match `json:"matches"`
+}
+
+// Search returns the entries matching the options given. If no entries match, the result is empty rather than an
+// error.
+func (c *Client) Search(ctx context.Context, options SearchOptions) (SearchResult, error) {
+	result, _, err := c.search(ctx, options, "")
+	return result, err
+}
+
+// search searches entries. If etag isn't blank and the result hasn't changed, the result is empty and the ETag is
+// the same. Otherwise the ETag of the result is returned.
+func (c *Client) search(ctx context.Context, options SearchOptions, etag string) (SearchResult, string, error) {
+	var result SearchResult
+
+	resp, err := c.getJSON(ctx, "/search", options.values(), etag, &result)
+
+	var e *ResponseError
+	if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+		return SearchResult{Entries: []*entries.Entry{}}, resp.Header.Get("ETag"), nil
+	} else if err != nil {
+		return result, "", err
+	}
+
+	for _, entry := range result.Entries {
+		fillParents(entry)
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		return result, etag, nil
+	}
+
+	return result, resp.Header.Get("ETag"), nil
+}
+
+// Watch searches entries every interval, calling fn with the result when it first runs and then whenever it changes.
+// It stops when the context is cancelled, returning the context's error, or if searching or fn return an error.
+// Unchanged results are detected using the server's ETags, so polling is cheap.
+func (c *Client) Watch(ctx context.Context, options SearchOptions, interval time.Duration, fn func(SearchResult) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	etag := ""
+
+	for {
+		result, newETag, err := c.search(ctx, options, etag)
+		if err != nil {
+			return err
+		}
+
+		if etag == "" || newETag != etag {
+			err = fn(result)
+			if err != nil {
+				return err
+			}
+		}
+
+		etag = newETag
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
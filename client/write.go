@@ -0,0 +1,91 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// writeRequest is the body of a request to create or update an entry.
+type writeRequest struct {
+	Contents string `json:"contents"`
+}
+
+// Create creates a new entry at the path given with the contents given, including any front matter. It returns the new
+// entry and its ETag. If there's already an entry at that path, the error is an *ResponseError with the status code
+// http.StatusConflict.
+func (c *Client) Create(ctx context.Context, path, contents string) (*entries.Entry, string, error) {
+	return c.write(ctx, http.MethodPost, path, contents, "")
+}
+
+// Update replaces the contents of the entry at the path given, returning the updated entry and its new ETag. The etag
+// is the ETag of the entry when it was last read, from GetWithETag or a previous call to Create or Update. If the entry
+// has changed since then, nothing is changed and the error is an *ResponseError with the status code
+// http.StatusPreconditionFailed.
+func (c *Client) Update(ctx context.Context, path, contents, etag string) (*entries.Entry, string, error) {
+	return c.write(ctx, http.MethodPut, path, contents, etag)
+}
+
+// write creates or updates an entry.
+func (c *Client) write(ctx context.Context, method, path, contents, etag string) (*entries.Entry, string, error) {
+	body, err := json.Marshal(writeRequest{Contents: contents})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var entry entries.Entry
+
+	resp, err := c.sendJSON(ctx, method, "/entries/"+strings.Trim(path, "/"), "application/json", body, etag, &entry)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fillParents(&entry)
+	return &entry, resp.Header.Get("ETag"), nil
+}
+
+// Delete deletes the entry at the path given along with its attachments. If etag isn't blank, the entry is only deleted
+// if it hasn't changed since it was read, like Update.
+func (c *Client) Delete(ctx context.Context, path, etag string) error {
+	_, err := c.sendJSON(ctx, http.MethodDelete, "/entries/"+strings.Trim(path, "/"), "", nil, etag, nil)
+	return err
+}
+
+// Attach attaches the contents of r to the entry at the path given as a file with the name given, such as "pizza.jpg".
+// It returns all the entry's attachments, including the new one. If the entry already has an attachment with that name,
+// the error is an *ResponseError with the status code http.StatusConflict.
+func (c *Client) Attach(ctx context.Context, path, name string, r io.Reader) ([]Attachment, error) {
+	var buf bytes.Buffer
+
+	form := multipart.NewWriter(&buf)
+
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = io.Copy(part, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = form.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var response attachmentsResponse
+
+	_, err = c.sendJSON(ctx, http.MethodPost, "/attachments/"+strings.Trim(path, "/"), form.FormDataContentType(), buf.Bytes(), "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Attachments, nil
+}
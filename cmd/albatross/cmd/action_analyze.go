@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/spf13/cobra"
+)
+
+// ActionAnalyzeCmd represents the 'analyze' action.
+var ActionAnalyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "analyze the text of matched entries",
+	Long: `analyze computes statistics about the text of matched entries.
+
+See the available subcommands for more information.`,
+}
+
+// ActionAnalyzeVocabCmd represents the 'analyze vocab' action.
+var ActionAnalyzeVocabCmd = &cobra.Command{
+	Use:   "vocab",
+	Short: "display the most frequent words in matched entries",
+	Long: `vocab displays the most frequently used words across matched entries, along with how often they were used each
+year. This is useful for spotting themes, such as what was on your mind in a journal.
+
+	$ albatross get -p journal analyze vocab
+	Word          Total  Entries   2019   2020
+	school          212       98     80    132
+	pizza            97       41     12     85
+	...
+
+Words are counted in the plain text of each entry, so Markdown formatting, code blocks and images are ignored and
+links count as the text shown for them. Common words like "the" and "and" are left out unless --include-stopwords is
+given, as are words shorter than --min-word-length. The number of words shown can be changed with --top.
+
+To get the counts as JSON, use the --json flag.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		_, _, list := getFromCommand(cmd)
+
+		top, err := cmd.Flags().GetInt("top")
+		checkArg(err)
+
+		minLength, err := cmd.Flags().GetInt("min-word-length")
+		checkArg(err)
+
+		includeStopwords, err := cmd.Flags().GetBool("include-stopwords")
+		checkArg(err)
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		words := vocabulary(list, minLength, includeStopwords)
+
+		if top > 0 && len(words) > top {
+			words = words[:top]
+		}
+
+		if outputJSON {
+			out, err := json.Marshal(words)
+			if err != nil {
+				fmt.Println("Error marshalling vocabulary:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		years := vocabularyYears(words)
+
+		fmt.Printf("%-12s %6s %8s", "Word", "Total", "Entries")
+		for _, year := range years {
+			fmt.Printf(" %6d", year)
+		}
+		fmt.Println()
+
+		for _, word := range words {
+			fmt.Printf("%-12s %6d %8d", word.Word, word.Total, word.Entries)
+			for _, year := range years {
+				fmt.Printf(" %6d", word.Years[strconv.Itoa(year)])
+			}
+			fmt.Println()
+		}
+	},
+}
+
+// wordCount is the number of times a word is used in a list of entries.
+type wordCount struct {
+	Word string `json:"word"`
+
+	// Total is the number of times the word is used overall.
+	Total int `json:"total"`
+
+	// Entries is the number of entries the word is used in.
+	Entries int `json:"entries"`
+
+	// Years is the number of times the word is used in each year, keyed by the year of the entries it's used in.
+	Years map[string]int `json:"years"`
+}
+
+// vocabulary counts the words used in the list of entries, sorted by how often they're used. Words shorter than
+// minLength are ignored, as are stopwords unless includeStopwords is true.
+func vocabulary(list entries.List, minLength int, includeStopwords bool) []wordCount {
+	counts := map[string]*wordCount{}
+
+	for _, entry := range list.Slice() {
+		year := strconv.Itoa(entry.Date.Year())
+		seen := map[string]bool{}
+
+		for _, token := range entry.Tokens() {
+			if utf8.RuneCountInString(token) < minLength || (!includeStopwords && entries.IsStopword(token)) {
+				continue
+			}
+
+			count, ok := counts[token]
+			if !ok {
+				count = &wordCount{Word: token, Years: map[string]int{}}
+				counts[token] = count
+			}
+
+			count.Total++
+			count.Years[year]++
+
+			if !seen[token] {
+				count.Entries++
+				seen[token] = true
+			}
+		}
+	}
+
+	words := []wordCount{}
+	for _, count := range counts {
+		words = append(words, *count)
+	}
+
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].Total != words[j].Total {
+			return words[i].Total > words[j].Total
+		}
+
+		return words[i].Word < words[j].Word
+	})
+
+	return words
+}
+
+// vocabularyYears returns every year any of the words are used in, in order.
+func vocabularyYears(words []wordCount) []int {
+	seen := map[int]bool{}
+	years := []int{}
+
+	for _, word := range words {
+		for yearStr := range word.Years {
+			year, err := strconv.Atoi(yearStr)
+			if err != nil || seen[year] {
+				continue
+			}
+
+			seen[year] = true
+			years = append(years, year)
+		}
+	}
+
+	sort.Ints(years)
+	return years
+}
+
+func init() {
+	GetCmd.AddCommand(ActionAnalyzeCmd)
+	ActionAnalyzeCmd.AddCommand(ActionAnalyzeVocabCmd)
+
+	ActionAnalyzeVocabCmd.Flags().Int("top", 20, "number of words to display, 0 for all")
+	ActionAnalyzeVocabCmd.Flags().Int("min-word-length", 3, "ignore words shorter than this")
+	ActionAnalyzeVocabCmd.Flags().Bool("include-stopwords", false, "include common words like \"the\" and \"and\"")
+	ActionAnalyzeVocabCmd.Flags().Bool("json", false, "output word counts as JSON")
+}
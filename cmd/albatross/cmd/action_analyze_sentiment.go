@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ActionAnalyzeSentimentCmd represents the 'analyze sentiment' action.
+var ActionAnalyzeSentimentCmd = &cobra.Command{
+	Use:   "sentiment",
+	Short: "score the sentiment of matched entries",
+	Long: `sentiment gives each matched entry a simple sentiment score, showing how positive or negative it is, and
+outputs the scores as CSV for plotting.
+
+	$ albatross get -p journal --sort date analyze sentiment
+	path,date,score,comparative,positive,negative,words
+	journal/2020-08-06,2020-08-06T21:03:00Z,7,0.0614,5,1,114
+	journal/2020-08-07,2020-08-07T22:15:00Z,-4,-0.0263,2,4,152
+	...
+
+Each word in the entry is looked up in a lexicon which gives it a score, such as +3 for "happy" and -2 for "tired",
+and the score is the sum of these. If a word follows a negation like "not" or "never", its score is flipped. Since
+longer entries will have bigger scores, the comparative column divides the score by the number of words. positive and
+negative are the number of positive and negative words found.
+
+This is only a rough measure: sarcasm, context and anything not in the lexicon is lost. A small English lexicon is
+built in, but a different one can be used with --lexicon. The lexicon should be a file with one word and score per
+line, separated by a tab, like the AFINN lexicon:
+
+	abandon	-2
+	happy	3
+
+To avoid giving this every time, the path can also be set as 'sentiment.lexicon' in the global config file.
+
+To get the scores as JSON, use the --json flag.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		_, _, list := getFromCommand(cmd)
+
+		lexiconPath, err := cmd.Flags().GetString("lexicon")
+		checkArg(err)
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		if !cmd.Flags().Changed("lexicon") && viper.IsSet("sentiment.lexicon") {
+			lexiconPath = viper.GetString("sentiment.lexicon")
+		}
+
+		lexicon := defaultLexicon
+		if lexiconPath != "" {
+			lexicon, err = loadLexicon(lexiconPath)
+			if err != nil {
+				log.Fatalf("Couldn't load lexicon: %s", err)
+			}
+		}
+
+		scores := []sentimentScore{}
+		for _, entry := range list.Slice() {
+			score := lexicon.score(entry.Tokens())
+			score.Path = entry.Path
+			score.Date = entry.Date
+
+			scores = append(scores, score)
+		}
+
+		if outputJSON {
+			out, err := json.Marshal(scores)
+			if err != nil {
+				fmt.Println("Error marshalling sentiment scores:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		csvw := csv.NewWriter(os.Stdout)
+		checkCSV(csvw.Write([]string{"path", "date", "score", "comparative", "positive", "negative", "words"}))
+
+		for _, score := range scores {
+			checkCSV(csvw.Write([]string{
+				score.Path,
+				score.Date.Format(time.RFC3339),
+				strconv.FormatFloat(score.Score, 'f', -1, 64),
+				strconv.FormatFloat(score.Comparative, 'f', 4, 64),
+				strconv.Itoa(score.Positive),
+				strconv.Itoa(score.Negative),
+				strconv.Itoa(score.Words),
+			}))
+		}
+
+		csvw.Flush()
+	},
+}
+
+// sentimentScore is the sentiment of a single entry.
+type sentimentScore struct {
+	Path string    `json:"path"`
+	Date time.Time `json:"date"`
+
+	// Score is the sum of the scores of every word in the entry.
+	Score float64 `json:"score"`
+
+	// Comparative is the score divided by the number of words, so entries of different lengths can be compared.
+	Comparative float64 `json:"comparative"`
+
+	// Positive and Negative are the number of positive and negative words.
+	Positive int `json:"positive"`
+	Negative int `json:"negative"`
+
+	// Words is the number of words in the entry.
+	Words int `json:"words"`
+}
+
+// lexicon maps words to their sentiment, positive for words with a positive meaning and negative for words with a
+// negative one.
+type lexicon map[string]float64
+
+// negations are words which flip the sentiment of the word that follows them.
+var negations = map[string]bool{
+	"not": true, "no": true, "never": true, "don't": true, "didn't": true, "isn't": true, "wasn't": true,
+	"can't": true, "cannot": true, "won't": true, "couldn't": true, "wouldn't": true, "shouldn't": true,
+}
+
+// score computes the sentiment score for some words.
+func (l lexicon) score(tokens []string) sentimentScore {
+	score := sentimentScore{Words: len(tokens)}
+
+	for i, token := range tokens {
+		value, ok := l[token]
+		if !ok {
+			continue
+		}
+
+		if i > 0 && negations[tokens[i-1]] {
+			value = -value
+		}
+
+		if value > 0 {
+			score.Positive++
+		} else if value < 0 {
+			score.Negative++
+		}
+
+		score.Score += value
+	}
+
+	if score.Words > 0 {
+		score.Comparative = score.Score / float64(score.Words)
+	}
+
+	return score
+}
+
+// loadLexicon reads a lexicon from a file with one word and score per line, separated by a tab. Blank lines and lines
+// starting with '#' are ignored.
+func loadLexicon(path string) (lexicon, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	l := lexicon{}
+	scanner := bufio.NewScanner(f)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		i := strings.LastIndexAny(line, "\t ")
+		if i == -1 {
+			return nil, fmt.Errorf("%s:%d: expected a word and a score separated by a tab", path, n)
+		}
+
+		value, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid score %q", path, n, line[i+1:])
+		}
+
+		l[strings.ToLower(strings.TrimSpace(line[:i]))] = value
+	}
+
+	return l, scanner.Err()
+}
+
+// checkCSV exits if a row couldn't be written as CSV.
+func checkCSV(err error) {
+	if err != nil {
+		log.Fatalf("Couldn't write CSV: %s", err)
+	}
+}
+
+// defaultLexicon is a small lexicon of common English words used to describe how someone is feeling or how something
+// went, with scores from -5 to +5.
+var defaultLexicon = lexicon{
+	"amazing": 4, "awesome": 4, "beautiful": 3, "best": 3, "better": 2, "brilliant": 4, "calm": 2, "celebrate": 3,
+	"cheerful": 2, "confident": 2, "cool": 1, "delighted": 3, "easy": 1, "energetic": 2, "enjoy": 2,
+	"enjoyed": 2, "excellent": 3, "excited": 3, "exciting": 3, "fantastic": 4, "fine": 1, "fun": 4, "glad": 3,
+	"good": 3, "grateful": 3, "great": 3, "happy": 3, "hope": 2, "hopeful": 2, "inspired": 2, "interesting": 2,
+	"joy": 3, "kind": 2, "laugh": 1, "laughed": 1, "love": 3, "loved": 3, "lovely": 3, "lucky": 3,
+	"nice": 3, "optimistic": 2, "peaceful": 2, "perfect": 3, "pleased": 3, "productive": 2, "proud": 2, "relaxed": 2,
+	"relief": 1, "relieved": 2, "rested": 2, "satisfied": 2, "smile": 2, "success": 2, "successful": 3,
+	"thankful": 2, "win": 4, "wonderful": 4,
+
+	"afraid": -2, "angry": -3, "annoyed": -2, "anxious": -2, "ashamed": -2, "awful": -3, "bad": -3, "bored": -2,
+	"boring": -3, "broken": -1, "confused": -2, "cried": -2, "cry": -1, "depressed": -2, "difficult": -1,
+	"disappointed": -2, "disappointing": -2, "dread": -2, "embarrassed": -2, "exhausted": -2, "fail": -2,
+	"failed": -2, "fear": -2, "frustrated": -2, "frustrating": -2, "guilty": -3, "hate": -3, "hated": -3, "hurt": -2,
+	"ill": -2, "lonely": -2, "lost": -3, "mad": -3, "miserable": -3, "nervous": -2, "overwhelmed": -2, "pain": -2,
+	"panic": -3, "regret": -2, "sad": -2, "scared": -2, "sick": -2, "stress": -1, "stressed": -2, "stressful": -2,
+	"terrible": -3, "tired": -2, "upset": -2, "worried": -3, "worry": -3, "worse": -3, "worst": -3, "wrong": -2,
+}
+
+func init() {
+	ActionAnalyzeCmd.AddCommand(ActionAnalyzeSentimentCmd)
+
+	ActionAnalyzeSentimentCmd.Flags().String("lexicon", "", "path to a lexicon of tab-separated words and scores, instead of the built-in one")
+	ActionAnalyzeSentimentCmd.Flags().Bool("json", false, "output scores as JSON")
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ActionAnalyzeTimeCmd represents the 'analyze time' action.
+var ActionAnalyzeTimeCmd = &cobra.Command{
+	Use:   "time",
+	Short: "display when matched entries were written",
+	Long: `time counts how many matched entries were written in each hour of the day, and outputs the counts as CSV for
+plotting.
+
+	$ albatross get -p journal analyze time
+	hour,entries
+	0,41
+	1,12
+	...
+	23,160
+
+The time an entry was written is taken from its date. Entries can also be grouped by day of the week or month of the
+year using --by:
+
+	$ albatross get -p journal analyze time --by weekday
+	weekday,entries
+	Sunday,230
+	Monday,251
+	...
+
+Every hour, day or month is included, even if no entries were written in it. To get the counts as JSON, use the
+--json flag.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		_, _, list := getFromCommand(cmd)
+
+		by, err := cmd.Flags().GetString("by")
+		checkArg(err)
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		var buckets []string
+		var bucket func(time.Time) int
+
+		switch by {
+		case "hour":
+			for hour := 0; hour < 24; hour++ {
+				buckets = append(buckets, strconv.Itoa(hour))
+			}
+			bucket = func(t time.Time) int { return t.Hour() }
+		case "weekday":
+			for day := time.Sunday; day <= time.Saturday; day++ {
+				buckets = append(buckets, day.String())
+			}
+			bucket = func(t time.Time) int { return int(t.Weekday()) }
+		case "month":
+			for month := time.January; month <= time.December; month++ {
+				buckets = append(buckets, month.String())
+			}
+			bucket = func(t time.Time) int { return int(t.Month()) - 1 }
+		default:
+			fmt.Printf("Invalid value for --by %q, expected 'hour', 'weekday' or 'month'.\n", by)
+			os.Exit(1)
+		}
+
+		counts := make([]timeCount, len(buckets))
+		for i, name := range buckets {
+			counts[i].Bucket = name
+		}
+
+		for _, entry := range list.Slice() {
+			counts[bucket(entry.Date)].Entries++
+		}
+
+		if outputJSON {
+			out, err := json.Marshal(counts)
+			if err != nil {
+				fmt.Println("Error marshalling time distribution:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		csvw := csv.NewWriter(os.Stdout)
+		checkCSV(csvw.Write([]string{by, "entries"}))
+
+		for _, count := range counts {
+			checkCSV(csvw.Write([]string{count.Bucket, strconv.Itoa(count.Entries)}))
+		}
+
+		csvw.Flush()
+	},
+}
+
+// timeCount is the number of entries written in an hour of the day, day of the week or month of the year.
+type timeCount struct {
+	Bucket  string `json:"bucket"`
+	Entries int    `json:"entries"`
+}
+
+func init() {
+	ActionAnalyzeCmd.AddCommand(ActionAnalyzeTimeCmd)
+
+	ActionAnalyzeTimeCmd.Flags().String("by", "hour", "how to group entries ('hour', 'weekday' or 'month')")
+	ActionAnalyzeTimeCmd.Flags().Bool("json", false, "output counts as JSON")
+}
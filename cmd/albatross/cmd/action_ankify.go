@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/albatross-org/go-albatross/entries"
+	"github.com/albatross-org/go-albatross/pkg/markdown"
 	"github.com/spf13/cobra"
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
@@ -30,7 +31,7 @@ var reMatchDoubleLatex = regexp.MustCompile(`\${2}(.+?)\${2}`)
 // ActionAnkifyCmd represents the 'tags' action.
 var ActionAnkifyCmd = &cobra.Command{
 	Use:   "ankify",
-	Short: "create anki flashcards",
+	Short: "create flashcards for Anki, Mochi or RemNote",
 	Long: `ankify converts entries into anki flashcards.
 
 Ankify will process all entries matched and convert headings with two
@@ -106,15 +107,32 @@ The following flags are also available:
 	--single-open "X" (convert the start of a '$...$' block to 'X')
 	--single-close "Y" (convert the start of a '$...$' block to 'Y')
 
-Bugs
-----
+LaTeX is passed through untouched by the markdown processor, so expressions like '\\' for a line break work as they're
+written:
 
-At the moment there is an issue with how backslashes are handled. The markdown processor, Goldmark, will convert something like '\\' to '\'.
-This means Latex expressions involving '\\', which triggers a line break, will not work properly. In order to temporarily fix this, it's
-necesary to double up and do 4: '\\\\'. For example:
+	What are the dimensions of $\begin{matrix} 3 & 3 & 3 \\ 3 & 3 & 3 \end{matrix}$??
+
+Math written as '\(...\)' and '\[...\]' is recognised too, and is converted in the same way as '$...$' and '$$...$$'.
+
+Other Formats
+-------------
+
+The flashcards can also be output for Mochi or RemNote using --format. Unlike the TSV file for Anki, the answers are
+left as Markdown rather than turned into HTML, and the "??" at the end of the question becomes a single "?".
+
+	$ albatross get -p school/physics ankify --format mochi > physics.md
+	$ albatross get -p school/physics ankify --format remnote > physics.txt
+
+For Mochi, each card is written with its question and answer separated by a '---' line, which Mochi uses to split
+the sides of a card, and the cards are separated by a '***' line. When importing the file as Markdown, choose '***'
+as the card separator.
 
-	What are the dimensions of $\begin{matrix} 3 & 3 & 3 \\\\ 3 & 3 & 3 \end{matrix}$??
-														 ^^^^
+For RemNote, each card is a bullet with the question and answer separated by '>>', which RemNote turns into a
+flashcard when importing or pasting text. Answers longer than one paragraph are written using '>>>', with each paragraph
+as a bullet underneath the question.
+
+Both Mochi and RemNote understand '$...$' and '$$...$$' themselves, so the LaTeX is only converted for them if
+--fix-latex is given explicitly.
 `,
 
 	Run: func(cmd *cobra.Command, args []string) {
@@ -134,7 +152,44 @@ necesary to double up and do 4: '\\\\'. For example:
 		doubleClose, err := cmd.Flags().GetString("double-close")
 		checkArg(err)
 
-		generateAnkiFlashcards(list.Slice(), fixLatex, singleOpen, singleClose, doubleOpen, doubleClose)
+		format, err := cmd.Flags().GetString("format")
+		checkArg(err)
+
+		switch format {
+		case "anki":
+			generateAnkiFlashcards(list.Slice(), fixLatex, singleOpen, singleClose, doubleOpen, doubleClose)
+			return
+		case "mochi", "remnote":
+		default:
+			fmt.Printf("Invalid --format %q, expected 'anki', 'mochi' or 'remnote'\n", format)
+			os.Exit(1)
+		}
+
+		flashcards := []markdownFlashcard{}
+
+		for _, entry := range list.Slice() {
+			entryFlashcards, err := extractMarkdownFlashcards(entry)
+			if err != nil {
+				fmt.Printf("Error parsing markdown for entry %q: %s\n", entry.Path, err)
+				continue
+			}
+
+			for _, flashcard := range entryFlashcards {
+				// The delimiters Mochi and RemNote use are the same as Albatross's, so LaTeX is only changed if asked for.
+				if cmd.Flags().Changed("fix-latex") && fixLatex {
+					fixed := fixFlashcardLatex([]string{flashcard.Question, flashcard.Answer}, singleOpen, singleClose, doubleOpen, doubleClose)
+					flashcard.Question, flashcard.Answer = fixed[0], fixed[1]
+				}
+
+				flashcards = append(flashcards, flashcard)
+			}
+		}
+
+		if format == "mochi" {
+			fmt.Print(mochiDeck(flashcards))
+		} else {
+			fmt.Print(remNoteDocument(flashcards))
+		}
 	},
 }
 
@@ -143,8 +198,25 @@ func generateAnkiFlashcards(entries []*entries.Entry, fixLatex bool, singleOpen,
 	csvw := csv.NewWriter(os.Stdout)
 	csvw.Comma = '\t'
 
+	// Math in the answers is handled while rendering, so it isn't mangled by the markdown processor. Headings are
+	// taken as plain text so the LaTeX there is fixed afterwards.
+	math := markdown.NewMath(markdown.WithInlineDelimiters("$", "$"), markdown.WithDisplayDelimiters("$$", "$$"))
+	if fixLatex {
+		math = markdown.NewMath(
+			markdown.WithInlineDelimiters(singleOpen, singleClose),
+			markdown.WithDisplayDelimiters(doubleOpen, doubleClose),
+		)
+	}
+
+	md := goldmark.New(
+		goldmark.WithExtensions(math),
+		goldmark.WithRendererOptions(
+			html.WithUnsafe(),
+		),
+	)
+
 	for _, entry := range entries {
-		flashcards, err := extractFlashcards(entry)
+		flashcards, err := extractFlashcards(md, entry)
 		if err != nil {
 			fmt.Printf("Error parsing markdown for entry %q: %s\n", entry.Path, err)
 			continue
@@ -153,7 +225,7 @@ func generateAnkiFlashcards(entries []*entries.Entry, fixLatex bool, singleOpen,
 		for _, flashcard := range flashcards {
 			row := []string{flashcard[0], strings.Join(flashcard[1:], ""), entry.Path}
 			if fixLatex {
-				row = fixFlashcardLatex(row, singleOpen, singleClose, doubleOpen, doubleClose)
+				row[0] = fixFlashcardLatex(row[:1], singleOpen, singleClose, doubleOpen, doubleClose)[0]
 			}
 
 			// Something has gone very wrong.
@@ -167,15 +239,8 @@ func generateAnkiFlashcards(entries []*entries.Entry, fixLatex bool, singleOpen,
 	csvw.Flush()
 }
 
-// extractFlashcards takes an entry and extracts the flashcards from its contents.
-func extractFlashcards(entry *entries.Entry) ([][]string, error) {
-	md := goldmark.New(
-		goldmark.WithParserOptions(),
-		goldmark.WithRendererOptions(
-			html.WithUnsafe(),
-		),
-	)
-
+// extractFlashcards takes an entry and extracts the flashcards from its contents, rendering the answers using md.
+func extractFlashcards(md goldmark.Markdown, entry *entries.Entry) ([][]string, error) {
 	// Parse the contents into markdown.
 	parser := md.Parser()
 	renderer := md.Renderer()
@@ -202,7 +267,7 @@ func extractFlashcards(entry *entries.Entry) ([][]string, error) {
 				flashcards = append(flashcards, flashcard)
 			}
 
-			if strings.HasSuffix(string(text), "??") {
+			if isFlashcardQuestion(string(text)) {
 				state = "flashcard"
 			} else {
 				state = "none"
@@ -237,6 +302,113 @@ func extractFlashcards(entry *entries.Entry) ([][]string, error) {
 	return flashcards, nil
 }
 
+// isFlashcardQuestion returns true if the text of a heading ends with "??", meaning it's the question of a flashcard.
+func isFlashcardQuestion(heading string) bool {
+	return strings.HasSuffix(heading, "??")
+}
+
+// markdownFlashcard is a flashcard whose answer is left as Markdown, for apps which render Markdown themselves.
+type markdownFlashcard struct {
+	// Question is the text of the heading, without the second "?".
+	Question string
+
+	// Answer is the Markdown between the heading and the next one.
+	Answer string
+}
+
+// extractMarkdownFlashcards finds the flashcards in an entry like extractFlashcards, but keeps each answer as the Markdown
+// it was written in.
+func extractMarkdownFlashcards(entry *entries.Entry) ([]markdownFlashcard, error) {
+	contents := []byte(entry.Contents)
+	root := goldmark.New().Parser().Parse(text.NewReader(contents))
+
+	flashcards := []markdownFlashcard{}
+	current := -1
+
+	// The answer to a flashcard runs from the end of its heading to the start of the next heading, or the end of the
+	// entry.
+	answerStart := 0
+
+	for child := root.FirstChild(); child != nil; child = child.NextSibling() {
+		if child.Kind() != ast.KindHeading || child.Lines().Len() == 0 {
+			continue
+		}
+
+		lines := child.Lines()
+		start := bytes.LastIndexByte(contents[:lines.At(0).Start], '\n') + 1
+
+		if current != -1 {
+			flashcards[current].Answer = strings.TrimSpace(string(contents[answerStart:start]))
+			current = -1
+		}
+
+		question := strings.ReplaceAll(string(child.Text(contents)), "\n", "")
+		if !isFlashcardQuestion(question) {
+			continue
+		}
+
+		answerStart = lines.At(lines.Len() - 1).Stop
+		if end := bytes.IndexByte(contents[answerStart:], '\n'); end != -1 {
+			answerStart += end + 1
+		} else {
+			answerStart = len(contents)
+		}
+
+		flashcards = append(flashcards, markdownFlashcard{Question: strings.TrimSuffix(question, "?")})
+		current = len(flashcards) - 1
+	}
+
+	if current != -1 {
+		flashcards[current].Answer = strings.TrimSpace(string(contents[answerStart:]))
+	}
+
+	return flashcards, nil
+}
+
+// mochiDeck returns the flashcards as a Markdown deck for Mochi, with the sides of each card separated by a '---' line
+// and the cards separated by a '***' line.
+func mochiDeck(flashcards []markdownFlashcard) string {
+	var sb strings.Builder
+
+	for i, flashcard := range flashcards {
+		if i != 0 {
+			sb.WriteString("\n***\n\n")
+		}
+
+		sb.WriteString(flashcard.Question + "\n\n---\n\n" + flashcard.Answer + "\n")
+	}
+
+	return sb.String()
+}
+
+// remNoteDocument returns the flashcards as text for RemNote, with each card as a bullet like "- Question >> Answer". Answers
+// with more than one paragraph are written as "- Question >>>", with each paragraph as a bullet beneath it.
+func remNoteDocument(flashcards []markdownFlashcard) string {
+	var sb strings.Builder
+
+	for _, flashcard := range flashcards {
+		paragraphs := []string{}
+		for _, paragraph := range strings.Split(flashcard.Answer, "\n\n") {
+			if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+				// Each bullet is a single line in RemNote.
+				paragraphs = append(paragraphs, strings.Join(strings.Fields(paragraph), " "))
+			}
+		}
+
+		if len(paragraphs) == 1 {
+			sb.WriteString("- " + flashcard.Question + " >> " + paragraphs[0] + "\n")
+			continue
+		}
+
+		sb.WriteString("- " + flashcard.Question + " >>>\n")
+		for _, paragraph := range paragraphs {
+			sb.WriteString("    - " + paragraph + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
 // fixFlashcardLatex replaces '$' and '$$' with singleOpen, singleClose, doubleOpen and doubleClose.
 // This is to allow things like vim-markdown and pandoc to parse the latex properly whilst also allowing
 // proper rendering when using with Anki.
@@ -293,6 +465,7 @@ func init() {
 	GetCmd.AddCommand(ActionAnkifyCmd)
 
 	ActionAnkifyCmd.Flags().Bool("fix-latex", true, "converts '$' and '$$' to '[$]' and '[$$]'")
+	ActionAnkifyCmd.Flags().String("format", "anki", "format of the flashcards, 'anki', 'mochi' or 'remnote'")
 
 	ActionAnkifyCmd.Flags().String("single-open", "[$]", "what to convert opening '$' to")
 	ActionAnkifyCmd.Flags().String("single-close", "[/$]", "what to convert closing '$' to")
@@ -3,7 +3,10 @@ package cmd
 import (
 	"testing"
 
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/albatross-org/go-albatross/pkg/markdown"
 	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
 )
 
 func TestFixLatex(t *testing.T) {
@@ -45,3 +48,62 @@ func TestFixLatex(t *testing.T) {
 		assert.Equal(t, tc.out, got, "expected output latex to be correct")
 	}
 }
+
+func TestExtractFlashcardsLatex(t *testing.T) {
+	entry := &entries.Entry{
+		Path: "maths/matrices",
+		Contents: `##### What are the dimensions of $\begin{matrix} 3 & 3 \\ 3 & 3 \end{matrix}$??
+$$
+2 \times 2
+$$
+
+And $a_1 * b_1$ is not emphasis.
+`,
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(markdown.NewMath(
+		markdown.WithInlineDelimiters("[$]", "[/$]"),
+		markdown.WithDisplayDelimiters("[$$]", "[/$$]"),
+	)))
+
+	flashcards, err := extractFlashcards(md, entry)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{
+		`What are the dimensions of $\begin{matrix} 3 & 3 \\ 3 & 3 \end{matrix}$??`,
+		`<div class="math display">[$$]2 \times 2[/$$]</div>`,
+		`<p>And <span class="math inline">[$]a_1 * b_1[/$]</span> is not emphasis.</p>`,
+	}}, flashcards)
+}
+
+func TestMarkdownFlashcards(t *testing.T) {
+	entry := &entries.Entry{
+		Path: "school/physics",
+		Contents: `# Physics
+
+## What is the unit of force??
+The newton, $N$.
+
+## Notes
+
+Not a flashcard.
+
+## What are Newton's laws??
+
+1. Inertia.
+2. $F = ma$.
+
+They were published in 1687.
+`,
+	}
+
+	flashcards, err := extractMarkdownFlashcards(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, []markdownFlashcard{
+		{Question: "What is the unit of force?", Answer: "The newton, $N$."},
+		{Question: "What are Newton's laws?", Answer: "1. Inertia.\n2. $F = ma$.\n\nThey were published in 1687."},
+	}, flashcards)
+
+	assert.Equal(t, "What is the unit of force?\n\n---\n\nThe newton, $N$.\n\n***\n\nWhat are Newton's laws?\n\n---\n\n1. Inertia.\n2. $F = ma$.\n\nThey were published in 1687.\n", mochiDeck(flashcards))
+
+	assert.Equal(t, "- What is the unit of force? >> The newton, $N$.\n- What are Newton's laws? >>>\n    - 1. Inertia. 2. $F = ma$.\n    - They were published in 1687.\n", remNoteDocument(flashcards))
+}
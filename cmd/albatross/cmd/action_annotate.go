@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+// ActionAnnotateCmd represents the 'annotate' action.
+var ActionAnnotateCmd = &cobra.Command{
+	Use:   "annotate <text>",
+	Short: "add a note to an entry without editing it",
+	Long: `annotate attaches a note to an entry, or to some of its lines, without changing the entry itself. This is useful
+for commenting on old entries, like journal entries, while leaving what was written at the time alone.
+
+	$ albatross get -p journal/2020-08-06 annotate "I don't feel like this anymore."
+	Added annotation 1 to journal/2020-08-06.
+
+	$ albatross get -p journal/2020-08-06 annotate --lines 3-5 "This was the day we moved."
+	Added annotation 2 to journal/2020-08-06.
+
+Lines are counted from 1 at the start of the entry's contents, not including the front matter. Exactly one entry must be
+matched. If the text isn't given, it's read from an editor.
+
+Annotations are stored in an 'annotations.yaml' file next to the entry and committed like any other change. They aren't
+shown normally, but can be using the --annotations flag for 'contents' and 'export epub', which adds them to the entry as
+quotes after the lines they're about:
+
+	$ albatross get -p journal/2020-08-06 contents --annotations
+
+To list the annotations on entries, use the 'annotations' action. To remove one, use --remove with its ID:
+
+	$ albatross get -p journal/2020-08-06 annotate --remove 2`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		linesFlag, err := cmd.Flags().GetString("lines")
+		checkArg(err)
+
+		remove, err := cmd.Flags().GetInt("remove")
+		checkArg(err)
+
+		customEditor, err := cmd.Flags().GetString("editor")
+		checkArg(err)
+
+		_, _, list := getFromCommand(cmd)
+
+		if len(list.Slice()) != 1 {
+			fmt.Printf("Expecting exactly one entry to annotate, %d matched.\n", len(list.Slice()))
+			os.Exit(1)
+		}
+
+		entry := list.Slice()[0]
+
+		if remove != 0 {
+			err = store.RemoveAnnotation(entry.Path, remove)
+			if err != nil {
+				log.Fatalf("Couldn't remove annotation: %s", err)
+			}
+
+			fmt.Printf("Removed annotation %d from %s.\n", remove, entry.Path)
+			return
+		}
+
+		startLine, endLine, err := parseLineRange(linesFlag)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		text := strings.Join(args, " ")
+		if text == "" {
+			text, err = edit(customEditor, "")
+			if err != nil {
+				log.Fatal("Couldn't get annotation from editor: ", err)
+			}
+		}
+
+		if strings.TrimSpace(text) == "" {
+			fmt.Println("Empty annotation, nothing added.")
+			os.Exit(1)
+		}
+
+		annotation, err := store.Annotate(entry.Path, strings.TrimSpace(text), startLine, endLine)
+		if err != nil {
+			log.Fatalf("Couldn't annotate entry: %s", err)
+		}
+
+		fmt.Printf("Added annotation %d to %s.\n", annotation.ID, entry.Path)
+	},
+}
+
+// ActionAnnotationsCmd represents the 'annotations' action.
+var ActionAnnotationsCmd = &cobra.Command{
+	Use:   "annotations",
+	Short: "list the annotations on entries",
+	Long: `annotations lists the annotations on the matched entries, see 'albatross get annotate --help'.
+
+	$ albatross get -p journal annotations
+	journal/2020-08-06
+	    1  2020-10-16             I don't feel like this anymore.
+	    2  2020-10-16  lines 3-5  This was the day we moved.
+
+Entries without annotations aren't listed. To get the annotations as JSON, use --json.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		_, _, list := getFromCommand(cmd)
+
+		all := map[string][]albatross.Annotation{}
+
+		for _, entry := range list.Slice() {
+			annotations, err := store.Annotations(entry.Path)
+			if err != nil {
+				log.Fatalf("Couldn't get annotations for %s: %s", entry.Path, err)
+			}
+
+			if len(annotations) != 0 {
+				all[entry.Path] = annotations
+			}
+		}
+
+		if outputJSON {
+			out, err := json.Marshal(all)
+			if err != nil {
+				fmt.Println("Error marshalling annotations:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		for _, entry := range list.Slice() {
+			annotations := all[entry.Path]
+			if len(annotations) == 0 {
+				continue
+			}
+
+			fmt.Println(entry.Path)
+
+			for _, annotation := range annotations {
+				text := strings.ReplaceAll(annotation.Text, "\n", " ")
+				fmt.Printf("    %d  %s  %-9s  %s\n", annotation.ID, annotation.Created.Format("2006-01-02"), annotation.Lines(), text)
+			}
+		}
+	},
+}
+
+// parseLineRange parses a range of lines given to --lines, such as "3" or "3-5". A blank range gives zero for both lines,
+// meaning the whole entry.
+func parseLineRange(lines string) (start, end int, err error) {
+	if lines == "" {
+		return 0, 0, nil
+	}
+
+	parts := strings.SplitN(lines, "-", 2)
+
+	start, err = strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid line range %q, expected a line like '3' or a range like '3-5'", lines)
+	}
+
+	end = start
+
+	if len(parts) == 2 {
+		end, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid line range %q, expected a line like '3' or a range like '3-5'", lines)
+		}
+	}
+
+	return start, end, nil
+}
+
+// annotatedContents returns the contents of the entry with its annotations added, for the --annotations flag.
+func annotatedContents(entry *entries.Entry, contents string) (string, error) {
+	annotations, err := store.Annotations(entry.Path)
+	if err != nil {
+		return "", err
+	}
+
+	return albatross.InsertAnnotations(contents, annotations), nil
+}
+
+func init() {
+	GetCmd.AddCommand(ActionAnnotateCmd)
+	GetCmd.AddCommand(ActionAnnotationsCmd)
+
+	ActionAnnotateCmd.Flags().String("lines", "", "lines the annotation is about, like '3' or '3-5', by default the whole entry")
+	ActionAnnotateCmd.Flags().Int("remove", 0, "remove the annotation with this ID instead of adding one")
+	ActionAnnotateCmd.Flags().StringP("editor", "e", getEditor("vim"), "editor to write the annotation in if it isn't given (defaults to $EDITOR, then vim)")
+
+	ActionAnnotationsCmd.Flags().Bool("json", false, "output annotations as JSON, an object of entry paths to annotations")
+}
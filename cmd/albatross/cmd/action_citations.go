@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/spf13/cobra"
+)
+
+// ActionCitationsCmd represents the 'citations' action.
+var ActionCitationsCmd = &cobra.Command{
+	Use:   "citations",
+	Short: "list the sources cited by matched entries",
+	Long: `citations lists the sources each matched entry cites.
+
+	$ albatross get -p thesis citations
+	thesis/introduction: smith2020, jones2019
+	thesis/method: smith2020
+
+Citations are written like in Pandoc, with the citation key of the source from the store's bibliography:
+
+	Pizza is popular [@smith2020, p. 4], but see [@jones2019; @lee2018].
+
+The bibliography is a BibTeX (.bib) or CSL-JSON (.json) file, such as one exported from Zotero, set in the store's
+config.yaml. A relative path is relative to the store directory:
+
+	citations:
+	  bibliography: references.bib
+
+When matched entries are exported as an EPUB, citations are replaced by the author and year of the source, like
+"(Smith 2020, p. 4)", and a list of references is added to the end of each entry.
+
+To list which entries cite each source instead, use --by-key:
+
+	$ albatross get -p thesis citations --by-key
+	jones2019: thesis/introduction
+	smith2020: thesis/introduction, thesis/method
+
+To only list citations of sources which aren't in the bibliography, such as misspelled keys, use --missing. To get the
+citations as JSON, use the --json flag.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		_, _, list := getFromCommand(cmd)
+
+		byKey, err := cmd.Flags().GetBool("by-key")
+		checkArg(err)
+
+		missing, err := cmd.Flags().GetBool("missing")
+		checkArg(err)
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		bib, err := store.Bibliography()
+		if err != nil {
+			log.Fatalf("Couldn't load bibliography: %s", err)
+		}
+
+		if missing && len(bib) == 0 {
+			log.Warn("No bibliography is set in the store's config, so every citation is missing.")
+		}
+
+		byEntry := map[string][]string{}
+		entryPaths := []string{}
+		byKeyPaths := map[string][]string{}
+
+		for _, entry := range list.Slice() {
+			keys := []string{}
+
+			for _, key := range entry.CitedKeys() {
+				if _, ok := bib[key]; missing && ok {
+					continue
+				}
+
+				keys = append(keys, key)
+				byKeyPaths[key] = append(byKeyPaths[key], entry.Path)
+			}
+
+			if len(keys) != 0 {
+				byEntry[entry.Path] = keys
+				entryPaths = append(entryPaths, entry.Path)
+			}
+		}
+
+		output, order := byEntry, entryPaths
+		if byKey {
+			output, order = byKeyPaths, []string{}
+			for key := range byKeyPaths {
+				order = append(order, key)
+			}
+
+			sort.Strings(order)
+		}
+
+		if outputJSON {
+			out, err := json.Marshal(output)
+			if err != nil {
+				fmt.Println("Error marshalling citations:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		for _, name := range order {
+			fmt.Printf("%s: %s\n", name, strings.Join(output[name], ", "))
+		}
+	},
+}
+
+// reAnchorUnsafe matches characters which can't be used in an HTML id.
+var reAnchorUnsafe = regexp.MustCompile(`[^A-Za-z0-9_:.-]`)
+
+// citationAnchor returns the HTML id used for a reference in a list of references.
+func citationAnchor(key string) string {
+	return "ref-" + reAnchorUnsafe.ReplaceAllString(key, "-")
+}
+
+// renderCitations replaces the citations in an entry's contents with Markdown links to its references, such as
+// "([Smith 2020](#ref-smith2020), p. 4)", and returns the new contents along with the references cited, in the order
+// they're first cited. Keys which aren't in the bibliography are shown as "key?" and left out of the references.
+func renderCitations(entry *entries.Entry, bib entries.Bibliography) (string, []entries.Reference) {
+	contents := entry.Contents
+	citations := entry.Citations()
+
+	for i := len(citations) - 1; i >= 0; i-- {
+		citation := citations[i]
+		parts := []string{}
+
+		for _, cite := range citation.Cites {
+			var part string
+
+			if ref, ok := bib[cite.Key]; ok {
+				label := strings.NewReplacer("[", `\[`, "]", `\]`).Replace(ref.Label())
+				part = fmt.Sprintf("[%s](#%s)", label, citationAnchor(cite.Key))
+			} else {
+				part = cite.Key + "?"
+			}
+
+			if cite.Prefix != "" {
+				part = cite.Prefix + " " + part
+			}
+
+			if cite.Locator != "" {
+				part += ", " + cite.Locator
+			}
+
+			parts = append(parts, part)
+		}
+
+		contents = contents[:citation.Loc[0]] + "(" + strings.Join(parts, "; ") + ")" + contents[citation.Loc[1]:]
+	}
+
+	refs := []entries.Reference{}
+	for _, key := range entry.CitedKeys() {
+		if ref, ok := bib[key]; ok {
+			refs = append(refs, ref)
+		}
+	}
+
+	return contents, refs
+}
+
+// referencesHTML returns a list of references as HTML, with each reference given the id linked to by renderCitations.
+func referencesHTML(refs []entries.Reference) string {
+	if len(refs) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+
+	out.WriteString("<h5>References</h5><ol class='references'>")
+	for _, ref := range refs {
+		fmt.Fprintf(&out, "<li id='%s'>%s</li>", citationAnchor(ref.Key), html.EscapeString(ref.String()))
+	}
+	out.WriteString("</ol>")
+
+	return out.String()
+}
+
+func init() {
+	GetCmd.AddCommand(ActionCitationsCmd)
+
+	ActionCitationsCmd.Flags().Bool("by-key", false, "list the entries citing each source instead")
+	ActionCitationsCmd.Flags().Bool("missing", false, "only list citations of sources not in the bibliography")
+	ActionCitationsCmd.Flags().Bool("json", false, "output citations as JSON")
+}
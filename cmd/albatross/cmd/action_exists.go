@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// ActionExistsCmd represents the 'exists' action.
+var ActionExistsCmd = &cobra.Command{
+	Use:   "exists",
+	Short: "exit with a status of 0 if any entries matched, without printing anything",
+	Long: `exists prints nothing and exits with a status of 0 if any entries matched, or 1 if none did. This is for scripts and
+editor plugins which only need to know whether an entry exists, without having to parse the output:
+
+	$ albatross get --path-exact food/pizza exists && echo "There's an entry about pizza"
+
+	# Check whether a link like [[Pizza]] or {{food/pizza}} goes anywhere.
+	$ albatross get --title-exact Pizza exists
+	$ albatross get --path-exact food/pizza exists
+
+A status of 1 is also given if the query couldn't be run, such as because a filter wasn't valid, in which case the
+reason is printed. Since --number and --offset only limit the entries returned, they don't change the result.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		_, filtered, _ := getFromCommand(cmd)
+
+		if filtered.Len() == 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	GetCmd.AddCommand(ActionExistsCmd)
+}
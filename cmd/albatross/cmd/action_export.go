@@ -3,9 +3,17 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"runtime"
+	"sync"
 
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	"github.com/albatross-org/go-albatross/pkg/markdown"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/yuin/goldmark"
 )
 
 // ActionExportCmd represents the 'tags' action.
@@ -19,23 +27,66 @@ By default, the command will output a JSON serialised array of all the entries t
 
 	$ albatross get --sort 'date' export
 	# Export all entries chronologically in JSON.
+
+For JSON which also includes each entry's attachments and links, with a choice of fields and streaming, see
+
+	$ albatross get export json --help
 	
+To render entries into a static website, see
+
+	$ albatross get export html --help
+
+To write entries as plain Markdown files without Albatross-specific links and tags, for use in other tools, see
+
+	$ albatross get export markdown --help
+
+To write entries as a vault which can be opened in Obsidian, see
+
+	$ albatross get export obsidian --help
+
+To package entries and their attachments into a .tar.gz or .zip file, see
+
+	$ albatross get export archive --help
+
 For help with EPUB export, see
 
 	$ albatross get export epub --help
+
+To render entries into a single PDF for printing, see
+
+	$ albatross get export pdf --help
+
+For help with exporting entries as audio using text-to-speech, see
+
+	$ albatross get export audio --help
+
+Before exporting, entries are checked for anything which looks like a secret, such as AWS keys, private key blocks and
+long random tokens, since notes often have credentials pasted into them by accident. If any are found the export is
+stopped, unless --allow-secrets is given. To find them, use 'albatross lint'. If something isn't actually a secret,
+adding "albatross:allow-secret" to the line stops it being reported.
+
+Exports which render entries, like EPUB and HTML, render several entries at once using one worker for each CPU. The
+number of workers can be set using --jobs, such as '--jobs 1' to render one entry at a time.
 `,
 
 	Run: func(cmd *cobra.Command, args []string) {
 		_, _, list := getFromCommand(cmd)
+		refuseSecrets(cmd, list)
 
 		format, err := cmd.Flags().GetString("format")
 		checkArg(err)
 
-		var out []byte
-
 		switch format {
 		case "json":
-			out, err = json.Marshal(list.Slice())
+			// Entries are written one at a time so that the whole output doesn't have to be kept in memory.
+			encoder := newJSONArrayEncoder(os.Stdout, "")
+
+			err = list.ForEach(func(entry *entries.Entry) error {
+				return encoder.Encode(entry)
+			})
+			if err == nil {
+				err = encoder.Close()
+			}
 		case "epub":
 			fmt.Println("The correct command is: albatross get export epub")
 			os.Exit(1)
@@ -50,13 +101,216 @@ For help with EPUB export, see
 			fmt.Println(err)
 			os.Exit(1)
 		}
-
-		fmt.Println(string(out))
 	},
 }
 
+// jsonArrayEncoder writes a JSON array one element at a time, so that exporting a lot of entries doesn't need them all to
+// be serialised in memory at once. The output is the same as encoding the whole array with a json.Encoder.
+type jsonArrayEncoder struct {
+	w      io.Writer
+	indent string
+	n      int
+}
+
+// newJSONArrayEncoder returns a jsonArrayEncoder which writes to w. If indent isn't blank, the array is indented like
+// json.Encoder.SetIndent("", indent).
+func newJSONArrayEncoder(w io.Writer, indent string) *jsonArrayEncoder {
+	return &jsonArrayEncoder{w: w, indent: indent}
+}
+
+// Encode writes the next element of the array.
+func (e *jsonArrayEncoder) Encode(v interface{}) error {
+	var data []byte
+	var err error
+
+	if e.indent == "" {
+		data, err = json.Marshal(v)
+	} else {
+		data, err = json.MarshalIndent(v, e.indent, e.indent)
+	}
+	if err != nil {
+		return err
+	}
+
+	separator := ","
+	if e.n == 0 {
+		separator = "["
+	}
+
+	if e.indent != "" {
+		separator += "\n" + e.indent
+	}
+
+	e.n++
+
+	_, err = io.WriteString(e.w, separator+string(data))
+	return err
+}
+
+// Close finishes the array. It must be called even if no elements were written.
+func (e *jsonArrayEncoder) Close() error {
+	end := "]\n"
+	switch {
+	case e.n == 0:
+		end = "[]\n"
+	case e.indent != "":
+		end = "\n]\n"
+	}
+
+	_, err := io.WriteString(e.w, end)
+	return err
+}
+
 func init() {
 	GetCmd.AddCommand(ActionExportCmd)
 
+	ActionExportCmd.PersistentFlags().Bool("allow-secrets", false, "export entries even if they contain things which look like secrets")
+	ActionExportCmd.PersistentFlags().IntP("jobs", "j", 0, "number of entries to render at once, zero for one for each CPU")
 	ActionExportCmd.Flags().String("format", "json", "format to export entries in (currently only JSON is supported)")
 }
+
+// refuseSecrets exits if any of the entries contain something which looks like a secret, unless --allow-secrets was
+// given, so that credentials pasted into entries don't end up in exports by accident.
+func refuseSecrets(cmd *cobra.Command, list entries.List) {
+	allowSecrets, err := cmd.Flags().GetBool("allow-secrets")
+	checkArg(err)
+
+	if allowSecrets {
+		return
+	}
+
+	found := false
+
+	list.ForEach(func(entry *entries.Entry) error {
+		for _, secret := range albatross.FindSecrets(entry.OriginalContents) {
+			if !found {
+				fmt.Fprintln(os.Stderr, "Not exporting because some entries look like they contain secrets:")
+				fmt.Fprintln(os.Stderr, "")
+				found = true
+			}
+
+			fmt.Fprintf(os.Stderr, "    %s: %s\n", entry.Path, secret)
+		}
+
+		return nil
+	})
+
+	if found {
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Remove them, or use --allow-secrets to export anyway.")
+		os.Exit(1)
+	}
+}
+
+// exportJobs returns the number of entries to render at once given by --jobs, where zero or less uses one for each CPU.
+func exportJobs(cmd *cobra.Command) int {
+	jobs, err := cmd.Flags().GetInt("jobs")
+	checkArg(err)
+
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	return jobs
+}
+
+// renderEach calls render with each index from 0 up to n, running up to jobs calls at once. Each call should only write
+// to its own index of any results, so they stay in order. If any calls fail, it returns the error for the lowest index,
+// which is the same error as rendering them one after another would give.
+func renderEach(jobs, n int, render func(i int) error) error {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, jobs)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int) {
+			defer func() { <-sem; wg.Done() }()
+			errs[i] = render(i)
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newExportMarkdown returns the goldmark.Markdown used to render entries when exporting them, which renders diagrams
+// using the commands in the config under 'diagrams', such as 'diagrams.mermaid', and highlights code using the command
+// and style under 'highlighting'.
+func newExportMarkdown(options ...goldmark.Option) goldmark.Markdown {
+	diagramOptions := []markdown.DiagramOption{}
+
+	for language := range markdown.DefaultDiagramCommands {
+		if viper.IsSet("diagrams." + language) {
+			diagramOptions = append(diagramOptions, markdown.WithDiagramCommand(language, viper.GetString("diagrams."+language)))
+		}
+	}
+
+	highlightCommand, highlightStyle := markdown.DefaultHighlightCommand, markdown.DefaultHighlightStyle
+
+	if viper.IsSet("highlighting.command") {
+		highlightCommand = viper.GetString("highlighting.command")
+	}
+
+	if viper.IsSet("highlighting.style") {
+		highlightStyle = viper.GetString("highlighting.style")
+	}
+
+	extensions := goldmark.WithExtensions(
+		markdown.NewDiagrams(diagramOptions...),
+		markdown.NewHighlighting(markdown.CommandHighlighter(highlightCommand, highlightStyle)),
+	)
+
+	return markdown.New(append([]goldmark.Option{extensions}, options...)...)
+}
+
+// exportContents returns the contents of an entry as they should be exported, with the entries it includes added from
+// all, dynamic entries rendered, shortcodes expanded and embedded entries put in place of their embeds. It also returns
+// the entries which were included.
+func exportContents(all *entries.Collection, shortcodes albatross.Shortcodes, entry *entries.Entry) (string, []*entries.Entry, error) {
+	return all.Composite(entry, func(e *entries.Entry) (string, error) {
+		return all.Transclude(e, func(e *entries.Entry) (string, error) {
+			return expandContents(all, shortcodes, e)
+		})
+	})
+}
+
+// expandContents returns the contents of an entry with it rendered against all if it's dynamic, and then with its
+// shortcodes expanded.
+func expandContents(all *entries.Collection, shortcodes albatross.Shortcodes, entry *entries.Entry) (string, error) {
+	if albatross.IsDynamic(entry) {
+		contents, err := albatross.RenderDynamic(all, templateStore(), entry)
+		if err != nil {
+			return "", err
+		}
+
+		rendered := *entry
+		rendered.Contents = contents
+		entry = &rendered
+	}
+
+	return shortcodes.Expand(entry)
+}
+
+// templateStore returns what templates see as .Store, which is empty if no store has been loaded.
+func templateStore() albatross.TemplateStore {
+	if store == nil {
+		return albatross.TemplateStore{}
+	}
+
+	return store.TemplateStore()
+}
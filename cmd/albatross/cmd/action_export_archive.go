@@ -0,0 +1,583 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	"github.com/spf13/cobra"
+	"github.com/yuin/goldmark"
+)
+
+// archiveTime is the modification time given to every file in an archive, so that exporting the same entries twice gives
+// exactly the same archive. It's the earliest time a zip file can store.
+var archiveTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ActionExportArchiveCmd represents the 'export archive' action.
+var ActionExportArchiveCmd = &cobra.Command{
+	Use:     "archive",
+	Aliases: []string{"zip", "tar"},
+	Short:   "package entries into a .tar.gz or .zip archive",
+	Long: `archive packages the matched entries and their attachments into a single .tar.gz or .zip file, such as for handing
+in coursework or archiving the notes for a project.
+
+	$ albatross get -p school/physics/coursework export archive -o coursework.zip
+
+The format is chosen from the extension of the output file, which can be '.tar.gz', '.tgz' or '.zip', or given using
+--format. Inside the archive, the entries are laid out like a store:
+
+	manifest.json
+	entries/
+		school/
+			physics/
+				coursework/
+					entry.md
+					graph.png
+
+With --html, each entry is also rendered as an HTML page under 'html/', with links between the matched entries, so the
+notes can be read without Albatross:
+
+	$ albatross get -p school/physics/coursework export archive -o coursework.tar.gz --html
+
+If the pages will be published, give the URL they'll be published at using --base-url. Links between the pages then use
+absolute URLs, each page gets a canonical link and Open Graph metadata so that it's indexed and shared properly, and a
+sitemap.xml listing every page is added to 'html/':
+
+	$ albatross get -p blog export archive -o site.tar.gz --html --base-url https://notes.example.com
+
+The manifest lists each entry's path, title, date and tags, along with the files belonging to it and their SHA-256
+checksums.
+
+Files are always added in the same order with the same timestamps, so exporting the same entries twice gives exactly the
+same archive. This makes it easy to tell if anything has changed since an archive was last made.
+
+The same flags as 'export store' can be used to leave out attachments, see 'albatross get export store --help'.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		// Attachments are encrypted along with the entries, so the store has to stay decrypted after the entries are found.
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		all, collection, list := getFromCommand(cmd)
+		refuseSecrets(cmd, list)
+
+		outputDest, err := cmd.Flags().GetString("output")
+		checkArg(err)
+
+		format, err := cmd.Flags().GetString("format")
+		checkArg(err)
+
+		renderHTML, err := cmd.Flags().GetBool("html")
+		checkArg(err)
+
+		baseURL, err := cmd.Flags().GetString("base-url")
+		checkArg(err)
+
+		filter := attachmentFilter{}
+
+		filter.exclude, err = cmd.Flags().GetBool("exclude-attachments")
+		checkArg(err)
+
+		maxSize, err := cmd.Flags().GetString("attachments-max-size")
+		checkArg(err)
+
+		filter.include, err = cmd.Flags().GetStringSlice("attachments-include")
+		checkArg(err)
+
+		filter.excludeGlobs, err = cmd.Flags().GetStringSlice("attachments-exclude")
+		checkArg(err)
+
+		if maxSize != "" {
+			filter.maxSize, err = parseSize(maxSize)
+			if err != nil {
+				fmt.Printf("Invalid --attachments-max-size %q: %s\n", maxSize, err)
+				os.Exit(1)
+			}
+		}
+
+		if outputDest == "" {
+			fmt.Println("Please specify an output location using the -o flag.")
+			fmt.Println("For example: albatross get export archive -o notes.tar.gz")
+			os.Exit(1)
+		}
+
+		format, err = archiveFormat(outputDest, format)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if _, err := os.Stat(outputDest); !os.IsNotExist(err) {
+			fmt.Printf("Cannot output archive to %s:\n", outputDest)
+			fmt.Println("File already exists.")
+			os.Exit(1)
+		}
+
+		var site *siteURLs
+
+		if baseURL != "" {
+			if !renderHTML {
+				fmt.Println("--base-url is only used for the HTML pages, so it needs --html too.")
+				os.Exit(1)
+			}
+
+			site, err = newSiteURLs(baseURL)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		files, err := entryArchiveFiles(store, list, filter)
+		if err != nil {
+			fmt.Println("Couldn't find the files to archive:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if renderHTML {
+			pages, err := htmlArchiveFiles(all, collection, list, site, exportJobs(cmd))
+			if err != nil {
+				fmt.Println("Couldn't render entries as HTML:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			files = append(files, pages...)
+		}
+
+		manifest, err := archiveManifest(list, files)
+		if err != nil {
+			fmt.Println("Couldn't create the manifest:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		files = append(files, manifest)
+
+		// The archive is written straight to the output rather than built in memory first, since it can be large.
+		out, err := os.OpenFile(outputDest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			fmt.Println("Couldn't write to output destination:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		err = writeArchive(out, format, files)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+
+		if err != nil {
+			os.Remove(outputDest)
+
+			fmt.Println("Error when creating the archive:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// archiveFile is a file to add to an archive. Its contents are either read from source, a file on disk, or are data.
+type archiveFile struct {
+	name   string
+	source string
+	data   []byte
+
+	// entry is the path of the entry the file belongs to, if it belongs to one.
+	entry string
+}
+
+// open returns the contents of the file and its size.
+func (f archiveFile) open() (io.ReadCloser, int64, error) {
+	if f.source == "" {
+		return ioutil.NopCloser(bytes.NewReader(f.data)), int64(len(f.data)), nil
+	}
+
+	file, err := os.Open(f.source)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+
+	return file, info.Size(), nil
+}
+
+// archiveFormat returns the format of archive to write, either "tar.gz" or "zip". If format is blank, it's chosen using
+// the extension of the output file.
+func archiveFormat(output, format string) (string, error) {
+	if format == "" {
+		switch {
+		case strings.HasSuffix(output, ".tar.gz"), strings.HasSuffix(output, ".tgz"):
+			return "tar.gz", nil
+		case strings.HasSuffix(output, ".zip"):
+			return "zip", nil
+		}
+
+		return "", fmt.Errorf("can't tell what kind of archive %s should be, use --format or end it with .tar.gz or .zip", output)
+	}
+
+	switch format {
+	case "tar.gz", "tgz":
+		return "tar.gz", nil
+	case "zip":
+		return "zip", nil
+	}
+
+	return "", fmt.Errorf("invalid archive format %q, expected tar.gz or zip", format)
+}
+
+// entryArchiveFiles returns the files for the entries in the list, laid out like a store under "entries/". The attachments
+// added are the ones the filter allows.
+func entryArchiveFiles(store *albatross.Store, list entries.List, filter attachmentFilter) ([]archiveFile, error) {
+	files := []archiveFile{}
+	entriesPath := filepath.Join(store.Path, "entries")
+
+	err := list.ForEach(func(entry *entries.Entry) error {
+		folder := filepath.Join(entriesPath, filepath.FromSlash(entry.Path))
+
+		files = append(files, archiveFile{
+			name:   path.Join("entries", entry.Path, "entry.md"),
+			source: filepath.Join(folder, "entry.md"),
+			entry:  entry.Path,
+		})
+
+		attachments, err := store.Attachments(entry.Path)
+		if err != nil {
+			return fmt.Errorf("couldn't get attachments for %s: %w", entry.Path, err)
+		}
+
+		for _, attachment := range attachments {
+			source := filepath.Join(folder, filepath.FromSlash(attachment))
+
+			info, err := os.Stat(source)
+			if err != nil {
+				return err
+			}
+
+			if !filter.allow(attachment, info.Size()) {
+				continue
+			}
+
+			files = append(files, archiveFile{
+				name:   path.Join("entries", entry.Path, attachment),
+				source: source,
+				entry:  entry.Path,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// htmlArchiveFiles renders the entries in the list as HTML pages under "html/". Links to other entries in the collection
+// of matched entries link to their pages. Included entries are taken from all, the whole store. If site isn't nil, the
+// pages use absolute URLs and a sitemap.xml is added. Up to jobs entries are rendered at once.
+func htmlArchiveFiles(all *entries.Collection, collection *entries.Collection, list entries.List, site *siteURLs, jobs int) ([]archiveFile, error) {
+	bib, err := store.Bibliography()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load bibliography: %w", err)
+	}
+
+	shortcodes, err := store.Shortcodes()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load shortcodes: %w", err)
+	}
+
+	md := newExportMarkdown()
+	slice := list.Slice()
+	pages := make([]string, len(slice))
+
+	err = renderEach(jobs, len(slice), func(i int) error {
+		contents, _, err := exportContents(all, shortcodes, slice[i])
+		if err != nil {
+			return err
+		}
+
+		pages[i], err = entryHTMLPage(md, collection, bib, slice[i], contents, site)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := []archiveFile{}
+	sitemap := []sitemapURL{}
+
+	for i, entry := range slice {
+		page := pages[i]
+
+		if site != nil {
+			page := sitemapURL{Loc: site.page(entry.Path + ".html")}
+			if !entry.Date.IsZero() {
+				page.LastMod = entry.Date.Format("2006-01-02")
+			}
+
+			sitemap = append(sitemap, page)
+		}
+
+		files = append(files, archiveFile{
+			name:  path.Join("html", entry.Path+".html"),
+			data:  []byte(page),
+			entry: entry.Path,
+		})
+	}
+
+	if site != nil {
+		sort.Slice(sitemap, func(i, j int) bool { return sitemap[i].Loc < sitemap[j].Loc })
+
+		data, err := sitemapXML(sitemap)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create sitemap: %w", err)
+		}
+
+		files = append(files, archiveFile{name: "html/sitemap.xml", data: data})
+	}
+
+	return files, nil
+}
+
+// entryHTMLPage renders an entry as a standalone HTML page, for 'export archive --html'. Links to entries in the collection
+// point to their pages relative to this one, and links to anything else are left as text. If site isn't nil, links use
+// absolute URLs instead and the page is given a canonical link and Open Graph metadata.
+func entryHTMLPage(md goldmark.Markdown, collection *entries.Collection, bib entries.Bibliography, entry *entries.Entry, contents string, site *siteURLs) (string, error) {
+	body, err := entryHTML(md, collection, bib, entry, contents, func(linked *entries.Entry) string {
+		if site != nil {
+			return site.page(linked.Path + ".html")
+		}
+
+		return relativeHTMLPath(entry.Path, linked.Path)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var page strings.Builder
+
+	page.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&page, "<title>%s</title>\n", html.EscapeString(entry.Title))
+
+	if site != nil {
+		page.WriteString(pageMetaHTML(entry, site.page(entry.Path+".html")))
+	}
+	page.WriteString("</head>\n<body>\n")
+	fmt.Fprintf(&page, "<h1>%s</h1>\n", html.EscapeString(entry.Title))
+
+	if !entry.Date.IsZero() {
+		fmt.Fprintf(&page, "<p><time>%s</time></p>\n", entry.Date.Format("2006-01-02 15:04"))
+	}
+
+	page.WriteString(body)
+	page.WriteString("</body>\n</html>\n")
+
+	return page.String(), nil
+}
+
+// relativeHTMLPath returns the path from the HTML page of the entry at from to the page of the entry at to.
+func relativeHTMLPath(from, to string) string {
+	rel, err := filepath.Rel(filepath.Dir(filepath.FromSlash(from)), filepath.FromSlash(to)+".html")
+	if err != nil {
+		return to + ".html"
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// archiveManifestEntry is an entry listed in an archive's manifest.
+type archiveManifestEntry struct {
+	Path  string                `json:"path"`
+	Title string                `json:"title"`
+	Date  time.Time             `json:"date"`
+	Tags  []string              `json:"tags"`
+	Files []archiveManifestFile `json:"files"`
+}
+
+// archiveManifestFile is a file listed in an archive's manifest.
+type archiveManifestFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// archiveManifest returns the "manifest.json" file for an archive containing the entries in the list and the files given.
+func archiveManifest(list entries.List, files []archiveFile) (archiveFile, error) {
+	byEntry := map[string][]archiveManifestFile{}
+
+	for _, file := range files {
+		r, size, err := file.open()
+		if err != nil {
+			return archiveFile{}, err
+		}
+
+		h := sha256.New()
+		_, err = io.Copy(h, r)
+		r.Close()
+		if err != nil {
+			return archiveFile{}, err
+		}
+
+		byEntry[file.entry] = append(byEntry[file.entry], archiveManifestFile{
+			Name:   file.name,
+			Size:   size,
+			SHA256: hex.EncodeToString(h.Sum(nil)),
+		})
+	}
+
+	manifest := []archiveManifestEntry{}
+
+	for _, entry := range list.Slice() {
+		entryFiles := byEntry[entry.Path]
+		sort.Slice(entryFiles, func(i, j int) bool { return entryFiles[i].Name < entryFiles[j].Name })
+
+		tags := append([]string{}, entry.Tags...)
+		sort.Strings(tags)
+
+		manifest = append(manifest, archiveManifestEntry{
+			Path:  entry.Path,
+			Title: entry.Title,
+			Date:  entry.Date,
+			Tags:  tags,
+			Files: entryFiles,
+		})
+	}
+
+	sort.Slice(manifest, func(i, j int) bool { return manifest[i].Path < manifest[j].Path })
+
+	data, err := json.MarshalIndent(map[string]interface{}{"entries": manifest}, "", "  ")
+	if err != nil {
+		return archiveFile{}, err
+	}
+
+	return archiveFile{name: "manifest.json", data: append(data, '\n')}, nil
+}
+
+// writeArchive writes the files to w as an archive in the format given, either "tar.gz" or "zip". Files are sorted by
+// name and given the same modification time and permissions, so the same files always give the same archive.
+func writeArchive(w io.Writer, format string, files []archiveFile) error {
+	sorted := append([]archiveFile{}, files...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].name < sorted[j].name })
+
+	switch format {
+	case "tar.gz":
+		return writeTarGz(w, sorted)
+	case "zip":
+		return writeZip(w, sorted)
+	}
+
+	return fmt.Errorf("invalid archive format %q, expected tar.gz or zip", format)
+}
+
+// writeTarGz writes the files to w as a gzipped tar archive, in the order given.
+func writeTarGz(w io.Writer, files []archiveFile) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for _, file := range files {
+		r, size, err := file.open()
+		if err != nil {
+			return err
+		}
+
+		err = tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     file.name,
+			Size:     size,
+			Mode:     0644,
+			ModTime:  archiveTime,
+			Format:   tar.FormatPAX,
+		})
+		if err == nil {
+			_, err = io.Copy(tw, r)
+		}
+
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("couldn't add %s to archive: %w", file.name, err)
+		}
+	}
+
+	err := tw.Close()
+	if err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+// writeZip writes the files to w as a zip archive, in the order given.
+func writeZip(w io.Writer, files []archiveFile) error {
+	zw := zip.NewWriter(w)
+
+	for _, file := range files {
+		r, _, err := file.open()
+		if err != nil {
+			return err
+		}
+
+		header := &zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: archiveTime,
+		}
+		header.SetMode(0644)
+
+		fw, err := zw.CreateHeader(header)
+		if err == nil {
+			_, err = io.Copy(fw, r)
+		}
+
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("couldn't add %s to archive: %w", file.name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+func init() {
+	ActionExportCmd.AddCommand(ActionExportArchiveCmd)
+
+	ActionExportArchiveCmd.Flags().StringP("output", "o", "", "output location of the archive, ending in .tar.gz, .tgz or .zip")
+	ActionExportArchiveCmd.Flags().String("format", "", "format of the archive, tar.gz or zip, by default chosen from the output location")
+	ActionExportArchiveCmd.Flags().Bool("html", false, "also render each entry as an HTML page under 'html/'")
+	ActionExportArchiveCmd.Flags().String("base-url", "", "URL the HTML pages will be published at, for absolute links, canonical URLs and a sitemap")
+	ActionExportArchiveCmd.Flags().Bool("exclude-attachments", false, "don't add any attachments, only entries")
+	ActionExportArchiveCmd.Flags().String("attachments-max-size", "", "don't add attachments larger than this, like 10MB")
+	ActionExportArchiveCmd.Flags().StringSlice("attachments-include", []string{}, "only add attachments matching one of these globs")
+	ActionExportArchiveCmd.Flags().StringSlice("attachments-exclude", []string{}, "don't add attachments matching any of these globs")
+}
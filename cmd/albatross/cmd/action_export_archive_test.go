@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestArchiveFormat(t *testing.T) {
+	for _, test := range []struct {
+		output, format, expected string
+	}{
+		{"notes.tar.gz", "", "tar.gz"},
+		{"notes.tgz", "", "tar.gz"},
+		{"notes.zip", "", "zip"},
+		{"notes", "zip", "zip"},
+		{"notes.zip", "tgz", "tar.gz"},
+	} {
+		format, err := archiveFormat(test.output, test.format)
+		NoError(t, err)
+		Equal(t, test.expected, format, "format for output %q and --format %q", test.output, test.format)
+	}
+
+	_, err := archiveFormat("notes.rar", "")
+	Error(t, err, "expecting error for unknown extension")
+
+	_, err = archiveFormat("notes.zip", "rar")
+	Error(t, err, "expecting error for unknown format")
+}
+
+func TestWriteArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "albatross-archive-test")
+	if err != nil {
+		t.Fatalf("couldn't create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "entry.md")
+
+	err = ioutil.WriteFile(source, []byte("Pizza."), 0644)
+	if err != nil {
+		t.Fatalf("couldn't write file: %s", err)
+	}
+
+	files := []archiveFile{
+		{name: "manifest.json", data: []byte("{}")},
+		{name: "entries/food/pizza/entry.md", source: source},
+		{name: "entries/food/pizza/a.txt", data: []byte("Attachment.")},
+	}
+
+	expected := map[string]string{
+		"entries/food/pizza/a.txt":    "Attachment.",
+		"entries/food/pizza/entry.md": "Pizza.",
+		"manifest.json":               "{}",
+	}
+
+	for _, format := range []string{"tar.gz", "zip"} {
+		var first, second bytes.Buffer
+
+		err = writeArchive(&first, format, files)
+		NoError(t, err)
+
+		err = writeArchive(&second, format, []archiveFile{files[2], files[0], files[1]})
+		NoError(t, err)
+
+		Equal(t, first.Bytes(), second.Bytes(), "expecting %s archives of the same files to be identical", format)
+
+		names, contents := readTestArchive(t, format, first.Bytes())
+		Equal(t, []string{"entries/food/pizza/a.txt", "entries/food/pizza/entry.md", "manifest.json"}, names)
+		Equal(t, expected, contents)
+	}
+}
+
+func TestRelativeHTMLPath(t *testing.T) {
+	Equal(t, "pizza.html", relativeHTMLPath("food/ice-cream", "food/pizza"))
+	Equal(t, "../moods/hunger.html", relativeHTMLPath("food/pizza", "moods/hunger"))
+	Equal(t, "food/pizza.html", relativeHTMLPath("index", "food/pizza"))
+}
+
+// readTestArchive returns the names of the files in the archive in order, and their contents.
+func readTestArchive(t *testing.T, format string, data []byte) ([]string, map[string]string) {
+	t.Helper()
+
+	names := []string{}
+	contents := map[string]string{}
+
+	if format == "zip" {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("couldn't read zip: %s", err)
+		}
+
+		for _, file := range zr.File {
+			r, err := file.Open()
+			if err != nil {
+				t.Fatalf("couldn't open %s: %s", file.Name, err)
+			}
+
+			content, _ := ioutil.ReadAll(r)
+			r.Close()
+
+			names = append(names, file.Name)
+			contents[file.Name] = string(content)
+		}
+
+		return names, contents
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("couldn't read gzip: %s", err)
+	}
+
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("couldn't read tar: %s", err)
+		}
+
+		content, _ := ioutil.ReadAll(tr)
+
+		names = append(names, header.Name)
+		contents[header.Name] = string(content)
+	}
+
+	return names, contents
+}
@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// defaultTTSCommand is the command used to convert text to speech if no other is configured.
+const defaultTTSCommand = "espeak-ng --stdin -w {output}"
+
+// ActionExportAudioCmd represents the 'export audio' action.
+var ActionExportAudioCmd = &cobra.Command{
+	Use:   "audio",
+	Short: "convert matched entries to audio using text-to-speech",
+	Long: `audio converts matched entries to speech, producing one audio file per entry as well as an M3U playlist and a
+podcast RSS feed, so that entries can be listened to rather than read.
+
+	$ albatross get -p journal --sort date --from "2020-11-01 00:00" export audio -o ~/podcasts/journal
+
+Each entry's title and plain text is read, without Markdown formatting, code blocks or images. The output directory
+will contain:
+
+	001-journal-2020-11-01.wav
+	002-journal-2020-11-02.wav
+	...
+	playlist.m3u
+	feed.xml
+
+Backends
+--------
+
+By default, text is converted using a local command. The text is given on stdin and '{output}' in the command is
+replaced by the path of the file to write. The default command uses espeak-ng:
+
+	$ albatross get -p journal export audio -o out --tts-command "espeak-ng --stdin -w {output}"
+
+Alternatively, a TTS API can be used with --tts-url. The text is sent as the body of a POST request with the content
+type text/plain, and the response body is saved as the audio file. Headers such as API keys can be given using
+--tts-header:
+
+	$ albatross get -p journal export audio -o out --format mp3 \
+		--tts-url https://tts.example.com/speak --tts-header "Authorization: Bearer abc123"
+
+To avoid giving these every time, the defaults can be set in the global config file as 'tts.command', 'tts.url' and
+'tts.headers'.
+
+Feeds
+-----
+
+To subscribe to the output in a podcast app, serve the output directory over HTTP and give its URL with --feed-url so
+that the feed links to the audio files correctly:
+
+	$ albatross get -p journal export audio -o /var/www/journal --feed-url https://example.com/journal`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		_, _, list := getFromCommand(cmd)
+		refuseSecrets(cmd, list)
+
+		outputDir, err := cmd.Flags().GetString("output")
+		checkArg(err)
+
+		format, err := cmd.Flags().GetString("format")
+		checkArg(err)
+
+		feedURL, err := cmd.Flags().GetString("feed-url")
+		checkArg(err)
+
+		feedTitle, err := cmd.Flags().GetString("feed-title")
+		checkArg(err)
+
+		ttsCommand, err := cmd.Flags().GetString("tts-command")
+		checkArg(err)
+
+		ttsURL, err := cmd.Flags().GetString("tts-url")
+		checkArg(err)
+
+		ttsHeaders, err := cmd.Flags().GetStringArray("tts-header")
+		checkArg(err)
+
+		if !cmd.Flags().Changed("tts-command") && viper.IsSet("tts.command") {
+			ttsCommand = viper.GetString("tts.command")
+		}
+
+		if !cmd.Flags().Changed("tts-url") && viper.IsSet("tts.url") {
+			ttsURL = viper.GetString("tts.url")
+		}
+
+		if !cmd.Flags().Changed("tts-header") && viper.IsSet("tts.headers") {
+			ttsHeaders = viper.GetStringSlice("tts.headers")
+		}
+
+		if outputDir == "" {
+			fmt.Println("Please specify an output directory using the -o flag.")
+			fmt.Println("For example: albatross get export audio -o journal-audio")
+			os.Exit(1)
+		}
+
+		if feedTitle == "" {
+			feedTitle = "Albatross " + time.Now().Format("2006-01-02 15:04")
+		}
+
+		var speak ttsBackend
+		if ttsURL != "" {
+			speak, err = httpTTS(ttsURL, ttsHeaders)
+			if err != nil {
+				log.Fatalf("Invalid TTS options: %s", err)
+			}
+		} else {
+			speak = commandTTS(ttsCommand)
+		}
+
+		err = os.MkdirAll(outputDir, 0755)
+		if err != nil {
+			log.Fatalf("Couldn't create output directory: %s", err)
+		}
+
+		tracks := []audioTrack{}
+
+		for i, entry := range list.Slice() {
+			name := fmt.Sprintf("%03d-%s.%s", i+1, strings.ReplaceAll(entry.Path, "/", "-"), format)
+			output := filepath.Join(outputDir, name)
+
+			log.Infof("Converting %s to %s", entry.Path, name)
+
+			err = speak(entry.Title+".\n\n"+entry.PlainText(), output)
+			if err != nil {
+				log.Fatalf("Couldn't convert entry %s to speech: %s", entry.Path, err)
+			}
+
+			info, err := os.Stat(output)
+			if err != nil {
+				log.Fatalf("Text-to-speech didn't produce output for %s: %s", entry.Path, err)
+			}
+
+			tracks = append(tracks, audioTrack{entry: entry, file: name, size: info.Size()})
+		}
+
+		err = ioutil.WriteFile(filepath.Join(outputDir, "playlist.m3u"), []byte(audioPlaylist(tracks)), 0644)
+		if err != nil {
+			log.Fatalf("Couldn't write playlist: %s", err)
+		}
+
+		feed, err := audioFeed(tracks, feedTitle, feedURL, format)
+		if err != nil {
+			log.Fatalf("Couldn't create feed: %s", err)
+		}
+
+		err = ioutil.WriteFile(filepath.Join(outputDir, "feed.xml"), feed, 0644)
+		if err != nil {
+			log.Fatalf("Couldn't write feed: %s", err)
+		}
+
+		fmt.Printf("Converted %d entries to audio in %s\n", len(tracks), outputDir)
+	},
+}
+
+// ttsBackend converts text to speech, writing the audio to the file at output.
+type ttsBackend func(text, output string) error
+
+// commandTTS returns a ttsBackend which runs a local command, giving the text on stdin and replacing "{output}" in the
+// command with the output path.
+func commandTTS(command string) ttsBackend {
+	return func(text, output string) error {
+		args := strings.Fields(command)
+		if len(args) == 0 {
+			return fmt.Errorf("no text-to-speech command given")
+		}
+
+		for i, arg := range args {
+			args[i] = strings.ReplaceAll(arg, "{output}", output)
+		}
+
+		var stderr bytes.Buffer
+
+		c := exec.Command(args[0], args[1:]...)
+		c.Stdin = strings.NewReader(text)
+		c.Stderr = &stderr
+
+		err := c.Run()
+		if err != nil {
+			return fmt.Errorf("running %q: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+		}
+
+		return nil
+	}
+}
+
+// httpTTS returns a ttsBackend which sends the text as the body of a POST request to url and saves the response. Headers
+// are given in the format "Name: value".
+func httpTTS(url string, headers []string) (ttsBackend, error) {
+	parsed := http.Header{}
+
+	for _, header := range headers {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("header %q should be in the format 'Name: value'", header)
+		}
+
+		parsed.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+
+	return func(text, output string) error {
+		req, err := http.NewRequest("POST", url, strings.NewReader(text))
+		if err != nil {
+			return err
+		}
+
+		req.Header = parsed.Clone()
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("TTS API responded with %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		}
+
+		return ioutil.WriteFile(output, body, 0644)
+	}, nil
+}
+
+// audioTrack is an entry which has been converted to audio.
+type audioTrack struct {
+	entry *entries.Entry
+	file  string
+	size  int64
+}
+
+// audioPlaylist returns an M3U playlist of the tracks.
+func audioPlaylist(tracks []audioTrack) string {
+	var out strings.Builder
+
+	out.WriteString("#EXTM3U\n")
+	for _, track := range tracks {
+		fmt.Fprintf(&out, "#EXTINF:-1,%s\n%s\n", track.entry.Title, track.file)
+	}
+
+	return out.String()
+}
+
+// rssFeed is a podcast RSS feed.
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// rssChannel is the channel of a podcast RSS feed.
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+// rssItem is a single episode in a podcast RSS feed.
+type rssItem struct {
+	Title     string       `xml:"title"`
+	GUID      string       `xml:"guid"`
+	PubDate   string       `xml:"pubDate"`
+	Enclosure rssEnclosure `xml:"enclosure"`
+}
+
+// rssEnclosure is the audio file for an episode.
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// audioFeed returns a podcast RSS feed of the tracks. If baseURL is empty, the links to the audio files are relative.
+func audioFeed(tracks []audioTrack, title, baseURL, format string) ([]byte, error) {
+	mimeTypes := map[string]string{
+		"mp3":  "audio/mpeg",
+		"m4a":  "audio/mp4",
+		"ogg":  "audio/ogg",
+		"opus": "audio/ogg",
+		"wav":  "audio/wav",
+	}
+
+	mimeType := mimeTypes[format]
+	if mimeType == "" {
+		mimeType = "audio/" + format
+	}
+
+	if baseURL != "" && !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       title,
+			Link:        baseURL,
+			Description: "Entries exported by albatross",
+			Items:       []rssItem{},
+		},
+	}
+
+	for _, track := range tracks {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:   track.entry.Title,
+			GUID:    track.entry.Path,
+			PubDate: track.entry.Date.Format(time.RFC1123Z),
+			Enclosure: rssEnclosure{
+				URL:    baseURL + track.file,
+				Length: track.size,
+				Type:   mimeType,
+			},
+		})
+	}
+
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), out...), nil
+}
+
+func init() {
+	ActionExportCmd.AddCommand(ActionExportAudioCmd)
+
+	ActionExportAudioCmd.Flags().StringP("output", "o", "", "output directory for the audio files, playlist and feed")
+	ActionExportAudioCmd.Flags().String("format", "wav", "file extension of the audio produced by the TTS backend, such as wav or mp3")
+	ActionExportAudioCmd.Flags().String("feed-url", "", "URL the output directory will be served at, used for links in the feed")
+	ActionExportAudioCmd.Flags().String("feed-title", "", "title of the podcast feed, by default a timestamp")
+	ActionExportAudioCmd.Flags().String("tts-command", defaultTTSCommand, "local command used for text-to-speech, '{output}' is replaced by the output path")
+	ActionExportAudioCmd.Flags().String("tts-url", "", "URL of a TTS API to use instead of a local command")
+	ActionExportAudioCmd.Flags().StringArray("tts-header", []string{}, "header to send to the TTS API, like 'Authorization: Bearer abc123'")
+}
@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+// ActionExportChangelogCmd represents the 'export changelog' action.
+var ActionExportChangelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "generate a changelog of new and updated entries from git history",
+	Long: `changelog lists the matched entries which were created or significantly edited, grouped by week or month, using
+the git history of the store. It's useful for publishing "what's new in my notes" updates.
+
+	$ albatross get -p school export changelog --since 2021-01-01
+	# Changelog
+
+	## February 2021
+
+	### New
+
+	- Circular Motion (school/physics/circular-motion)
+
+	### Updated
+
+	- Forces (school/physics/forces), +24 -3 lines
+
+--since accepts dates like '2021-01-01' or in the format given by --date-format, as well as durations like "30d", "8w"
+or "72h". Without it, the whole history is used.
+
+Entries are grouped by month by default. To group by week instead, where weeks start on a Monday, use --group week.
+The most recent groups come first.
+
+An entry counts as updated if the lines added and removed by all the commits that changed it within a group add up to at
+least --min-lines, which is 5 by default. Entries created within a group are listed as new, even if they were edited
+afterwards. Only changes to the 'entry.md' files count, not attachments, and entries which have since been deleted or
+which weren't matched by the search aren't listed.
+
+The changelog is output as Markdown by default, or as an HTML page with --format html. It's printed to stdout unless
+an output location is given with --output/-o:
+
+	$ albatross get export changelog --since 8w --group week --format html -o changelog.html
+
+This requires the store to be using git.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		_, collection, _ := getFromCommand(cmd)
+
+		sinceStr, err := cmd.Flags().GetString("since")
+		checkArg(err)
+
+		dateFormat, err := cmd.Flags().GetString("date-format")
+		checkArg(err)
+
+		group, err := cmd.Flags().GetString("group")
+		checkArg(err)
+
+		minLines, err := cmd.Flags().GetInt("min-lines")
+		checkArg(err)
+
+		format, err := cmd.Flags().GetString("format")
+		checkArg(err)
+
+		title, err := cmd.Flags().GetString("changelog-title")
+		checkArg(err)
+
+		outputDest, err := cmd.Flags().GetString("output")
+		checkArg(err)
+
+		if group != "week" && group != "month" {
+			fmt.Printf("Invalid --group %q, expected week or month\n", group)
+			os.Exit(1)
+		}
+
+		if format != "markdown" && format != "html" {
+			fmt.Printf("Invalid --format %q, expected markdown or html\n", format)
+			os.Exit(1)
+		}
+
+		var since time.Time
+
+		if sinceStr != "" {
+			since, err = parseSince(sinceStr, dateFormat, time.Now())
+			if err != nil {
+				since, err = time.Parse("2006-01-02", sinceStr)
+			}
+			if err != nil {
+				log.Fatalf("Can't parse --since value %q: %s", sinceStr, err)
+			}
+		}
+
+		changes, err := store.Changes(since)
+		if err != nil {
+			log.Fatalf("Couldn't get changes: %s", err)
+		}
+
+		changelog := buildChangelog(changes, collection, title, group, minLines)
+
+		if format == "html" {
+			var buf bytes.Buffer
+
+			err = newExportMarkdown().Convert([]byte(changelog), &buf)
+			if err != nil {
+				fmt.Println("Error converting the changelog to HTML:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			changelog = fmt.Sprintf(
+				"<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n%s</body>\n</html>\n",
+				html.EscapeString(title),
+				buf.String(),
+			)
+		}
+
+		if outputDest == "" {
+			fmt.Print(changelog)
+			return
+		}
+
+		err = ioutil.WriteFile(outputDest, []byte(changelog), 0644)
+		if err != nil {
+			fmt.Println("Couldn't write to output destination:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// changelogEntry is an entry that was created or updated within a changelog group.
+type changelogEntry struct {
+	entry     *entries.Entry
+	created   bool
+	additions int
+	deletions int
+}
+
+// changelogGroup is the week or month that changes are grouped into.
+type changelogGroup struct {
+	start   time.Time
+	entries map[string]*changelogEntry
+}
+
+// changelogGroupStart returns the start of the week or month the time is in. Weeks start on a Monday.
+func changelogGroupStart(t time.Time, group string) time.Time {
+	if group == "month" {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, t.Location())
+}
+
+// buildChangelog returns a Markdown changelog of the entries in the collection which were created or edited by the
+// changes given, grouped by week or month with the most recent first. Edits only count if at least minLines lines were
+// added or removed within the group.
+func buildChangelog(changes []albatross.EntryChange, collection *entries.Collection, title, group string, minLines int) string {
+	groups := map[time.Time]*changelogGroup{}
+
+	for _, change := range changes {
+		if change.Kind == albatross.EntryDeleted {
+			continue
+		}
+
+		entry := collection.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: change.Path})
+		if entry == nil {
+			continue
+		}
+
+		start := changelogGroupStart(change.Time, group)
+		if groups[start] == nil {
+			groups[start] = &changelogGroup{start: start, entries: map[string]*changelogEntry{}}
+		}
+
+		current := groups[start].entries[change.Path]
+		if current == nil {
+			current = &changelogEntry{entry: entry}
+			groups[start].entries[change.Path] = current
+		}
+
+		if change.Kind == albatross.EntryCreated {
+			current.created = true
+		}
+
+		current.additions += change.Additions
+		current.deletions += change.Deletions
+	}
+
+	sorted := []*changelogGroup{}
+	for _, g := range groups {
+		sorted = append(sorted, g)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].start.After(sorted[j].start)
+	})
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n", title)
+
+	for _, g := range sorted {
+		created, updated := []*changelogEntry{}, []*changelogEntry{}
+
+		for _, e := range g.entries {
+			if e.created {
+				created = append(created, e)
+			} else if e.additions+e.deletions >= minLines {
+				updated = append(updated, e)
+			}
+		}
+
+		if len(created) == 0 && len(updated) == 0 {
+			continue
+		}
+
+		if group == "month" {
+			fmt.Fprintf(&b, "\n## %s\n", g.start.Format("January 2006"))
+		} else {
+			fmt.Fprintf(&b, "\n## Week of %s\n", g.start.Format("2 January 2006"))
+		}
+
+		if len(created) > 0 {
+			b.WriteString("\n### New\n\n")
+
+			for _, e := range sortChangelogEntries(created) {
+				fmt.Fprintf(&b, "- %s (%s)\n", e.entry.Title, e.entry.Path)
+			}
+		}
+
+		if len(updated) > 0 {
+			b.WriteString("\n### Updated\n\n")
+
+			for _, e := range sortChangelogEntries(updated) {
+				fmt.Fprintf(&b, "- %s (%s), +%d -%d lines\n", e.entry.Title, e.entry.Path, e.additions, e.deletions)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// sortChangelogEntries sorts changelog entries by their title, and then by their path.
+func sortChangelogEntries(changelogEntries []*changelogEntry) []*changelogEntry {
+	sort.Slice(changelogEntries, func(i, j int) bool {
+		a, b := changelogEntries[i].entry, changelogEntries[j].entry
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+
+		return a.Path < b.Path
+	})
+
+	return changelogEntries
+}
+
+func init() {
+	ActionExportCmd.AddCommand(ActionExportChangelogCmd)
+
+	ActionExportChangelogCmd.Flags().String("since", "", "only include changes after this, a date or a duration like '30d'")
+	ActionExportChangelogCmd.Flags().String("group", "month", "group changes by 'week' or 'month'")
+	ActionExportChangelogCmd.Flags().Int("min-lines", 5, "number of lines an entry has to change by to count as updated")
+	ActionExportChangelogCmd.Flags().String("format", "markdown", "output format, 'markdown' or 'html'")
+	ActionExportChangelogCmd.Flags().String("changelog-title", "Changelog", "title of the changelog")
+	ActionExportChangelogCmd.Flags().StringP("output", "o", "", "output location of the changelog, by default it's printed to stdout")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestChangelogGroupStart(t *testing.T) {
+	wednesday := time.Date(2021, 2, 17, 15, 4, 0, 0, time.UTC)
+
+	Equal(t, time.Date(2021, 2, 15, 0, 0, 0, 0, time.UTC), changelogGroupStart(wednesday, "week"))
+	Equal(t, time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), changelogGroupStart(wednesday, "month"))
+
+	sunday := time.Date(2021, 2, 21, 9, 0, 0, 0, time.UTC)
+	Equal(t, time.Date(2021, 2, 15, 0, 0, 0, 0, time.UTC), changelogGroupStart(sunday, "week"), "sundays are the end of the week")
+}
+
+func TestBuildChangelog(t *testing.T) {
+	collection := entries.NewCollection()
+
+	for _, entry := range []*entries.Entry{
+		{Path: "school/forces", Title: "Forces"},
+		{Path: "school/motion", Title: "Circular Motion"},
+		{Path: "school/waves", Title: "Waves"},
+	} {
+		if err := collection.Add(entry); err != nil {
+			t.Fatalf("not expecting error adding entry: %s", err)
+		}
+	}
+
+	january := time.Date(2021, 1, 10, 12, 0, 0, 0, time.UTC)
+	february := time.Date(2021, 2, 10, 12, 0, 0, 0, time.UTC)
+
+	changes := []albatross.EntryChange{
+		{Path: "school/forces", Kind: albatross.EntryCreated, Time: january, Additions: 10},
+		{Path: "school/motion", Kind: albatross.EntryCreated, Time: february, Additions: 4},
+		{Path: "school/motion", Kind: albatross.EntryEdited, Time: february, Additions: 20},
+		{Path: "school/forces", Kind: albatross.EntryEdited, Time: february, Additions: 3},
+		{Path: "school/forces", Kind: albatross.EntryEdited, Time: february, Additions: 1, Deletions: 2},
+		{Path: "school/waves", Kind: albatross.EntryEdited, Time: february, Additions: 1},
+		{Path: "school/deleted", Kind: albatross.EntryCreated, Time: february, Additions: 5},
+	}
+
+	expected := `# Changelog
+
+## February 2021
+
+### New
+
+- Circular Motion (school/motion)
+
+### Updated
+
+- Forces (school/forces), +4 -2 lines
+
+## January 2021
+
+### New
+
+- Forces (school/forces)
+`
+
+	Equal(t, expected, buildChangelog(changes, collection, "Changelog", "month", 5))
+}
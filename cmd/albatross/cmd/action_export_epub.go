@@ -3,17 +3,19 @@ package cmd
 import (
 	"bytes"
 	"fmt"
+	gohtml "html"
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
 	"github.com/bmaupin/go-epub"
 	"github.com/spf13/cobra"
 	"github.com/yuin/goldmark"
-	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/renderer/html"
 	"gopkg.in/yaml.v2"
 )
@@ -27,6 +29,11 @@ var ActionExportEpubCmd = &cobra.Command{
 It's often a good idea to sort entries when creating an EPUB because then the Chapters will be in the correct order in the export.
 
 	$ albatross get -p school --sort 'date' export epub -o book.epub
+
+If any of the entries have a 'weight' in their front matter, the chapters are put in order of their weight instead, with
+entries without a weight at the end. Ties are broken by the order from --sort, so a syllabus can be ordered like this:
+
+	$ albatross get -p school/physics --sort 'alpha' export epub -o physics.epub
 	
 The title is 'Albatross YYYY-MM-DD' by default and can be specified using the --book-title flag.
 
@@ -41,12 +48,46 @@ Contents
 The generated EPUB has the following structure:
 
 - Info: A page containing information such as the number of entries matched and links to the other sections of the book.
-- Table of Contents: A chronological list of all entries with month and year headings.
+- Table of Contents: A chronological list of all entries with month and year headings, or a numbered list in order of
+  weight if the entries have weights.
 - Tags: A list of all entries grouped by tags.
 - Paths: A list of all entries grouped by path.
 - Entries: Each entry is then written as its own chapter. It contains the entry's content, as well as it's metadata and 
   all links to different entries will work. It also contains a list of other entries that link to this entry (backlinks)
-  if any are present.
+  if any are present, and a list of references if the entry cites anything (see 'albatross get citations --help').
+
+LaTeX math, written like '$x^2$' or '$$x^2$$', is kept exactly as written and output using MathJax's '\(...\)' and
+'\[...\]' delimiters, so it can be rendered by readers which support MathJax.
+
+Diagrams in code blocks marked 'mermaid' or 'plantuml' are rendered to SVG using mermaid-cli's 'mmdc' and 'plantuml', if
+they're installed. Otherwise, or if a diagram can't be rendered, it's left as a code block. Different commands can be
+set in the config, where '{input}' and '{output}' are replaced by the paths of the diagram and the SVG. If neither is
+used, the diagram is given on stdin and the SVG read from stdout:
+
+	diagrams:
+	  mermaid: "mmdc --input {input} --output {output} --theme dark"
+	  plantuml: "java -jar /path/to/plantuml.jar -tsvg -pipe"
+
+Code blocks with a language are highlighted using chroma (https://github.com/alecthomas/chroma), if it's installed. The
+style can be set in the config, as can the command, where '{language}' and '{style}' are replaced and the code is given
+on stdin:
+
+	highlighting:
+	  style: "monokai"
+	  command: "pygmentize -l {language} -f html -O noclasses,style={style}"
+
+Shortcodes like '{{< contact "Jane" >}}' are expanded using the templates in the store's 'templates/shortcodes/'
+directory, and entries with an 'include' list in their front matter have the entries listed added to the end. These
+don't need to be matched themselves. See 'albatross get contents --help' for more information.
+
+Admonitions, written either like in Python-Markdown or as callouts like in Obsidian and GitHub, are kept as blocks with
+their title:
+
+	!!! warning "Be careful"
+	    Don't touch the hot pan.
+
+	> [!NOTE]
+	> Pans are hot.
 
 Links
 -----
@@ -82,7 +123,26 @@ Examples
 `,
 
 	Run: func(cmd *cobra.Command, args []string) {
-		_, collection, list := getFromCommand(cmd)
+		annotations, err := cmd.Flags().GetBool("annotations")
+		checkArg(err)
+
+		// Annotations are encrypted along with the entries, so the store has to stay decrypted after the entries are found.
+		if annotations {
+			encrypted, err := store.Encrypted()
+			if err != nil {
+				log.Fatal(err)
+			} else if encrypted {
+				decryptStore()
+
+				if !leaveDecrypted {
+					defer encryptStore()
+				}
+			}
+		}
+
+		all, collection, list := getFromCommand(cmd)
+		refuseSecrets(cmd, list)
+
 		command := "albatross " + strings.Join(os.Args[1:], " ")
 
 		author, err := cmd.Flags().GetString("book-author")
@@ -108,7 +168,21 @@ Examples
 			os.Exit(1)
 		}
 
-		output, err := convertToEpub(collection, list, title, author, command)
+		bib, err := store.Bibliography()
+		if err != nil {
+			fmt.Println("Couldn't load bibliography:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		shortcodes, err := store.Shortcodes()
+		if err != nil {
+			fmt.Println("Couldn't load shortcodes:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		output, err := convertToEpub(all, collection, list, bib, shortcodes, title, author, command, annotations, exportJobs(cmd))
 		if err != nil {
 			fmt.Println("Error when creating the EPUB:")
 			fmt.Println(err)
@@ -125,15 +199,20 @@ Examples
 }
 
 // convertToEpub returns an EPUB file built from the list of entries specified. It also takes an argument
-// for the title and author.
-func convertToEpub(collection *entries.Collection, list entries.List, title, author, command string) ([]byte, error) {
+// for the title and author, the bibliography used to resolve citations and the shortcodes to expand. Included entries
+// are taken from all, the whole store, rather than just the collection of matched entries. If annotations is true, each
+// entry's annotations are added to it. Up to jobs entries are rendered at once.
+func convertToEpub(all *entries.Collection, collection *entries.Collection, list entries.List, bib entries.Bibliography, shortcodes albatross.Shortcodes, title, author, command string, annotations bool, jobs int) ([]byte, error) {
 	e := epub.NewEpub(title)
 	e.SetAuthor(author)
 
-	md := goldmark.New(
-		goldmark.WithRendererOptions(html.WithXHTML()),
-		goldmark.WithExtensions(extension.GFM, extension.Typographer),
-	)
+	md := newExportMarkdown(goldmark.WithRendererOptions(html.WithXHTML()))
+
+	// If entries have weights, they're put in order of their weight. Since sorting is stable, ties are broken by the order
+	// the entries were given in, such as from --sort.
+	if list.Weighted() {
+		list = list.Sort(entries.SortWeight)
+	}
 
 	info := `<h1>Info</h1>
 	<p>This EPUB was generated <pre>%s</pre> by the command <pre>%s</pre>matching<pre>%d</pre> entries.</p>
@@ -143,12 +222,14 @@ func convertToEpub(collection *entries.Collection, list entries.List, title, aut
 		<li><a href="paths.xhtml">Path Search</a></li>
 	</ul>`
 
-	_, err := e.AddSection(fmt.Sprintf(info, time.Now().Format(time.RFC3339), command, len(list.Slice())), "Info", "info.xhtml", "")
+	_, err := e.AddSection(fmt.Sprintf(info, time.Now().Format(time.RFC3339), command, list.Len()), "Info", "info.xhtml", "")
 	if err != nil {
 		return nil, err
 	}
 
-	toc, err := epubBuildTableOfContents(list)
+	sections := newEpubSections(list)
+
+	toc, err := epubBuildTableOfContents(list, sections)
 	if err != nil {
 		return nil, err
 	}
@@ -157,7 +238,7 @@ func convertToEpub(collection *entries.Collection, list entries.List, title, aut
 		return nil, err
 	}
 
-	tags, err := epubBuildTagSearch(collection, list)
+	tags, err := epubBuildTagSearch(collection, list, sections)
 	if err != nil {
 		return nil, err
 	}
@@ -166,21 +247,50 @@ func convertToEpub(collection *entries.Collection, list entries.List, title, aut
 		return nil, err
 	}
 
-	paths := epubBuildPathSearch(list)
+	paths := epubBuildPathSearch(list, sections)
 	_, err = e.AddSection(paths, "Paths", "paths.xhtml", "")
 	if err != nil {
 		return nil, err
 	}
 
-	for _, entry := range list.Slice() {
-		contents, title, path, err := epubEntryToXHTML(md, collection, entry)
+	// Entries are rendered at the same time, but the sections are added afterwards so that they're in the same order as
+	// the list.
+	type section struct{ contents, title, path string }
+
+	slice := list.Slice()
+	rendered := make([]section, len(slice))
+
+	err = renderEach(jobs, len(slice), func(i int) error {
+		entry := slice[i]
+
+		markdown, included, err := exportContents(all, shortcodes, entry)
+		if err != nil {
+			return err
+		}
+
+		if annotations {
+			markdown, err = annotatedContents(entry, markdown)
+			if err != nil {
+				return fmt.Errorf("couldn't get annotations for entry %s: %w", entry.Path, err)
+			}
+		}
+
+		contents, title, path, err := epubEntryToXHTML(md, collection, bib, sections, entry, markdown, included)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		_, err = e.AddSection(contents, title, path, "")
+		rendered[i] = section{contents, title, path}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, section := range rendered {
+		_, err = e.AddSection(section.contents, section.title, section.path, "")
 		if err != nil {
-			return nil, fmt.Errorf("error adding section for entry %s: %w", entry.Path, err)
+			return nil, fmt.Errorf("error adding section for entry %s: %w", slice[i].Path, err)
 		}
 	}
 
@@ -207,19 +317,42 @@ func convertToEpub(collection *entries.Collection, list entries.List, title, aut
 		return nil, fmt.Errorf("error writing epub file to %s: %s", output, err)
 	}
 
-	return ioutil.ReadFile(output)
+	book, err := ioutil.ReadFile(output)
+	if err != nil {
+		return nil, err
+	}
+
+	return replaceEpubNav(book, epubNavTree(collection, list, sections))
 }
 
-// epubBuildTableOfContents creates the XHTML for a Table of Contents, built from a list of entries.
-func epubBuildTableOfContents(list entries.List) (string, error) {
+// epubBuildTableOfContents creates the XHTML for a Table of Contents, built from a list of entries. Usually the entries
+// are listed by date under headings for each month, but if they have weights they're listed in the order given instead.
+func epubBuildTableOfContents(list entries.List, sections epubSections) (string, error) {
+	var out bytes.Buffer
+
+	out.WriteString("<h1>Table of Contents</h1>")
+
+	if list.Weighted() {
+		out.WriteString("<ol>")
+
+		for _, entry := range list.Slice() {
+			out.WriteString("<li><a href='")
+			out.WriteString(sections.file(entry.Path))
+			out.WriteString("'>")
+			out.WriteString(entry.Title)
+			out.WriteString("</a></li>")
+		}
+
+		out.WriteString("</ol>")
+
+		return out.String(), nil
+	}
+
 	sorted := list.Sort(entries.SortDate)
 
-	var out bytes.Buffer
 	var currMonth string
 	var currYear string
 
-	out.WriteString("<h1>Table of Contents</h1>")
-
 	// Here we loop through the entries and print headings with the months.
 	for i, entry := range sorted.Slice() {
 		month := entry.Date.Format("January") // Using Go's date format syntax.
@@ -248,8 +381,8 @@ func epubBuildTableOfContents(list entries.List) (string, error) {
 			out.WriteString("<ul>")
 		}
 
-		path := hashString(entry.Path)
-		title := fmt.Sprintf("%s: %s", entry.Date.Format("Mon 2006-01-02"), entry.Title)
+		path := sections.file(entry.Path)
+		title := sections.title(entry)
 
 		// Write something like "<li><a href='aasfhsadkjhf3.xhtml'>Mon 2006-01-02</a></li>"
 		out.WriteString("<li><a href='")
@@ -267,7 +400,7 @@ func epubBuildTableOfContents(list entries.List) (string, error) {
 
 // epubBuildTagSearch creates the XHTML for a tag search page, where all tags are listed along with all
 // of the entries with those tags. It's useful for quickly hopping around.
-func epubBuildTagSearch(collection *entries.Collection, list entries.List) (string, error) {
+func epubBuildTagSearch(collection *entries.Collection, list entries.List, sections epubSections) (string, error) {
 	var out bytes.Buffer
 	tags := make(map[string]bool)
 
@@ -277,19 +410,27 @@ func epubBuildTagSearch(collection *entries.Collection, list entries.List) (stri
 		}
 	}
 
-	out.WriteString("<h1>Tags</h1><ul>")
+	sorted := []string{}
 	for tag := range tags {
+		sorted = append(sorted, tag)
+	}
+
+	sort.Strings(sorted)
+	ids := epubTagIDs(sorted)
+
+	out.WriteString("<h1>Tags</h1><ul>")
+	for _, tag := range sorted {
 		out.WriteString("<li><pre><a href='#")
-		out.WriteString(hashString(tag))
+		out.WriteString(ids[tag])
 		out.WriteString("'>")
 		out.WriteString(tag)
 		out.WriteString("</a></pre></li>")
 	}
 	out.WriteString("</ul>")
 
-	for tag := range tags {
+	for _, tag := range sorted {
 		out.WriteString("<h2 id='")
-		out.WriteString(hashString(tag))
+		out.WriteString(ids[tag])
 		out.WriteString("'><pre>")
 		out.WriteString(tag)
 		out.WriteString("</pre></h2><ul>")
@@ -300,8 +441,8 @@ func epubBuildTagSearch(collection *entries.Collection, list entries.List) (stri
 		}
 
 		for _, entry := range filtered.List().Sort(entries.SortDate).Slice() {
-			path := hashString(entry.Path)
-			title := fmt.Sprintf("%s: %s", entry.Date.Format("Mon 2006-01-02"), entry.Title)
+			path := sections.file(entry.Path)
+			title := sections.title(entry)
 
 			out.WriteString("<li><a href='")
 			out.WriteString(path)
@@ -319,7 +460,7 @@ func epubBuildTagSearch(collection *entries.Collection, list entries.List) (stri
 // epubBuildPathSearch creates XHTML for a path search page, a sequential list of all paths sorted alphabetically.
 // It's useful for quickly hopping around.
 // TODO: have this generate a tree like strucutre, like the `ls` command does.
-func epubBuildPathSearch(list entries.List) string {
+func epubBuildPathSearch(list entries.List, sections epubSections) string {
 	sorted := list.Sort(entries.SortPath)
 	var out bytes.Buffer
 
@@ -327,7 +468,7 @@ func epubBuildPathSearch(list entries.List) string {
 
 	for _, entry := range sorted.Slice() {
 		out.WriteString("<li><a href='")
-		out.WriteString(hashString(entry.Path))
+		out.WriteString(sections.file(entry.Path))
 		out.WriteString("'><kbd>")
 		out.WriteString(entry.Path)
 		out.WriteString("</kbd></a></li>")
@@ -336,19 +477,25 @@ func epubBuildPathSearch(list entries.List) string {
 	return out.String()
 }
 
-// epubEntryToXHTML creates the XHTML for an entry, ready to be placed into an EPUB.
+// epubEntryToXHTML creates the XHTML for an entry, ready to be placed into an EPUB. The contents are the entry's
+// contents as returned by exportContents, and included are the entries that were included in them.
 // This function returns the XHTML, the title and the path it should be written to, then an error if there
 // was one.
-func epubEntryToXHTML(md goldmark.Markdown, collection *entries.Collection, entry *entries.Entry) (xhtml string, title string, path string, err error) {
+func epubEntryToXHTML(md goldmark.Markdown, collection *entries.Collection, bib entries.Bibliography, sections epubSections, entry *entries.Entry, contents string, included []*entries.Entry) (xhtml string, title string, path string, err error) {
 	var buf bytes.Buffer
 
-	err = md.Convert([]byte(entry.Contents), &buf)
+	expanded := *entry
+	expanded.Contents = contents
+
+	markdown, refs := renderCitations(&expanded, bib)
+
+	err = md.Convert([]byte(markdown), &buf)
 	if err != nil {
 		return "", "", "", fmt.Errorf("couldn't convert entry %s to markdown: %s", entry.Path, err)
 	}
 
-	path = hashString(entry.Path)
-	title = fmt.Sprintf("%s: %s", entry.Date.Format("Mon 2006-01-02"), entry.Title)
+	path = sections.file(entry.Path)
+	title = sections.title(entry)
 
 	metadata, err := yaml.Marshal(entry.Metadata)
 	if err != nil {
@@ -358,26 +505,34 @@ func epubEntryToXHTML(md goldmark.Markdown, collection *entries.Collection, entr
 
 	entryContents := buf.String()
 
-	for _, link := range entry.OutboundLinks {
-		linkedEntry := collection.ResolveLink(link)
-		text := entry.Contents[link.Loc[0]:link.Loc[1]]
-
-		if linkedEntry == nil {
-			entryContents = strings.ReplaceAll(entryContents, text, "<a href='unknown.xhtml'><kbd>"+text+"</kbd></a>")
-		} else {
-			location := hashString(linkedEntry.Path)
-			entryContents = strings.ReplaceAll(entryContents, text, "<a href='"+location+"'><kbd>"+text+"</kbd></a>")
+	for _, linking := range append([]*entries.Entry{entry}, included...) {
+		for _, link := range linking.OutboundLinks {
+			linkedEntry := collection.ResolveLink(link)
+			text := linking.Contents[link.Loc[0]:link.Loc[1]]
+
+			if url, ok := crossStoreURL(link); ok {
+				entryContents = strings.ReplaceAll(entryContents, text, "<a href='"+gohtml.EscapeString(url)+"'><kbd>"+text+"</kbd></a>")
+			} else if linkedEntry == nil || link.Store != "" {
+				entryContents = strings.ReplaceAll(entryContents, text, "<a href='unknown.xhtml'><kbd>"+text+"</kbd></a>")
+			} else {
+				location := sections.file(linkedEntry.Path)
+				entryContents = strings.ReplaceAll(entryContents, text, "<a href='"+location+"'><kbd>"+text+"</kbd></a>")
+			}
 		}
 	}
 
-	contents := fmt.Sprintf("<h1>%s</h1>\n%s\n<hr />", title, entryContents)
+	contents = fmt.Sprintf("<h1>%s</h1>\n%s\n<hr />", title, entryContents)
+
+	if len(refs) != 0 {
+		contents += "\n" + referencesHTML(refs) + "<hr />"
+	}
 
 	backlinksText := `<h5>Links to this entry</h5><ul>`
-	backlinks := collection.FindLinksTo(entry)
+	backlinks := collection.Backlinks(entry)
 
 	if len(backlinks) != 0 {
 		for _, backlink := range backlinks {
-			backlinksText += "<li><a href='" + hashString(backlink.Parent.Path) + "'><kbd>" + backlink.Parent.Title + "</kbd></a>"
+			backlinksText += "<li><a href='" + sections.file(backlink.Path) + "'><kbd>" + backlink.Title + "</kbd></a>"
 		}
 
 		contents += "\n" + backlinksText + "</ul><hr />"
@@ -393,5 +548,6 @@ func init() {
 
 	ActionExportEpubCmd.Flags().String("book-author", "", "set the author of the output EPUB, by default the command used to search")
 	ActionExportEpubCmd.Flags().String("book-title", "", "set the title of the output EPUB, by default a timestamp")
+	ActionExportEpubCmd.Flags().Bool("annotations", false, "add annotations to the entries, see 'albatross get annotate --help'")
 	ActionExportEpubCmd.Flags().StringP("output", "o", "", "output location of the EPUB, by default the file contents are printed to stdout")
 }
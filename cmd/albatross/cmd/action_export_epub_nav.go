@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// epubReservedNames are the names of the sections in an EPUB which aren't entries.
+var epubReservedNames = map[string]bool{
+	"info.xhtml":    true,
+	"toc.xhtml":     true,
+	"tags.xhtml":    true,
+	"paths.xhtml":   true,
+	"unknown.xhtml": true,
+	"nav.xhtml":     true,
+}
+
+// epubSections holds the file name and title of the section for each entry in an EPUB. File names are based on a hash of
+// the path so that they stay the same between exports, and start with a letter so they're valid XML IDs, which the EPUB's
+// manifest uses them as. Titles are made unique so that entries with the same date and title can be told apart.
+type epubSections struct {
+	files  map[string]string
+	titles map[string]string
+}
+
+// newEpubSections returns the file names and titles of the sections for the entries in the list.
+func newEpubSections(list entries.List) epubSections {
+	sections := epubSections{files: map[string]string{}, titles: map[string]string{}}
+
+	// Entries are named in order of path so that which entry gets a suffix when names collide doesn't depend on the
+	// order they're given in.
+	sorted := list.Sort(entries.SortPath).Slice()
+
+	used := map[string]bool{}
+	titleCounts := map[string]int{}
+
+	for _, entry := range sorted {
+		base := fmt.Sprintf("entry-%x", sha1.Sum([]byte(entry.Path)))
+
+		name := base + ".xhtml"
+		for i := 2; used[name] || epubReservedNames[name]; i++ {
+			name = fmt.Sprintf("%s-%d.xhtml", base, i)
+		}
+
+		used[name] = true
+		sections.files[entry.Path] = name
+
+		titleCounts[epubSectionTitle(entry)]++
+	}
+
+	for _, entry := range sorted {
+		title := epubSectionTitle(entry)
+		if titleCounts[title] > 1 {
+			title = fmt.Sprintf("%s (%s)", title, entry.Path)
+		}
+
+		sections.titles[entry.Path] = title
+	}
+
+	return sections
+}
+
+// epubSectionTitle returns the title of an entry's section, before it's made unique.
+func epubSectionTitle(entry *entries.Entry) string {
+	return fmt.Sprintf("%s: %s", entry.Date.Format("Mon 2006-01-02"), entry.Title)
+}
+
+// file returns the name of the section for the entry at the path given, or the page for unknown entries if it isn't in
+// the EPUB.
+func (s epubSections) file(path string) string {
+	if name, ok := s.files[path]; ok {
+		return name
+	}
+
+	return "unknown.xhtml"
+}
+
+// title returns the title of the section for the entry.
+func (s epubSections) title(entry *entries.Entry) string {
+	if title, ok := s.titles[entry.Path]; ok {
+		return title
+	}
+
+	return epubSectionTitle(entry)
+}
+
+// epubNavNode is an item in the navigation of an EPUB, which can contain other items.
+type epubNavNode struct {
+	label    string
+	href     string
+	children []*epubNavNode
+}
+
+// epubNavTree returns the navigation for an EPUB: the sections which aren't entries, followed by the entries nested by
+// their paths. Folders which aren't entries themselves link to the first entry inside them and are labelled using the
+// collection, see Collection.FolderTitle. Entries and folders are in the order they first appear in the list, which is
+// the reading order.
+func epubNavTree(collection *entries.Collection, list entries.List, sections epubSections) []*epubNavNode {
+	nodes := []*epubNavNode{
+		{label: "Info", href: "xhtml/info.xhtml"},
+		{label: "Table of Contents", href: "xhtml/toc.xhtml"},
+		{label: "Tags", href: "xhtml/tags.xhtml"},
+		{label: "Paths", href: "xhtml/paths.xhtml"},
+	}
+
+	root := &epubNavNode{}
+	byPath := map[string]*epubNavNode{"": root}
+
+	for _, entry := range list.Slice() {
+		href := "xhtml/" + sections.file(entry.Path)
+		parent := root
+		parts := strings.Split(entry.Path, "/")
+
+		for i := range parts {
+			path := strings.Join(parts[:i+1], "/")
+
+			node, ok := byPath[path]
+			if !ok {
+				node = &epubNavNode{label: collection.FolderTitle(path), href: href}
+				byPath[path] = node
+				parent.children = append(parent.children, node)
+			}
+
+			parent = node
+		}
+
+		parent.label = sections.title(entry)
+		parent.href = href
+	}
+
+	return append(nodes, root.children...)
+}
+
+// epubNavHTML returns the nested list for the EPUB 3 navigation document, nav.xhtml.
+func epubNavHTML(nodes []*epubNavNode, indent string) string {
+	var out strings.Builder
+
+	out.WriteString("<ol>\n")
+
+	for _, node := range nodes {
+		fmt.Fprintf(&out, "%s  <li>\n%s    <a href=\"%s\">%s</a>\n", indent, indent, html.EscapeString(node.href), html.EscapeString(node.label))
+
+		if len(node.children) != 0 {
+			out.WriteString(indent + "    ")
+			out.WriteString(epubNavHTML(node.children, indent+"    "))
+		}
+
+		fmt.Fprintf(&out, "%s  </li>\n", indent)
+	}
+
+	out.WriteString(indent + "</ol>\n")
+
+	return out.String()
+}
+
+// epubNavNCX returns the nested navigation points for the EPUB 2 navigation file, toc.ncx. Each point is given an ID
+// and play order from count, which is increased for each one.
+func epubNavNCX(nodes []*epubNavNode, indent string, count *int) string {
+	var out strings.Builder
+
+	for _, node := range nodes {
+		*count++
+
+		fmt.Fprintf(&out, "%s<navPoint id=\"navPoint-%d\" playOrder=\"%d\">\n", indent, *count, *count)
+		fmt.Fprintf(&out, "%s  <navLabel>\n%s    <text>%s</text>\n%s  </navLabel>\n", indent, indent, html.EscapeString(node.label), indent)
+		fmt.Fprintf(&out, "%s  <content src=\"%s\"></content>\n", indent, html.EscapeString(node.href))
+		out.WriteString(epubNavNCX(node.children, indent+"  ", count))
+		fmt.Fprintf(&out, "%s</navPoint>\n", indent)
+	}
+
+	return out.String()
+}
+
+// replaceEpubNav returns the EPUB with its navigation documents replaced by the nested navigation given, since the EPUB
+// library only supports a flat list. Every other file is copied as it is, in the same order.
+func replaceEpubNav(book []byte, nodes []*epubNavNode) ([]byte, error) {
+	r, err := zip.NewReader(bytes.NewReader(book), int64(len(book)))
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	w := zip.NewWriter(&out)
+
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read %s from EPUB: %w", f.Name, err)
+		}
+
+		switch f.Name {
+		case "EPUB/nav.xhtml":
+			data, err = replaceBetween(data, "<ol>", "</ol>", epubNavHTML(nodes, "      "))
+		case "EPUB/toc.ncx":
+			count := 0
+			data, err = replaceBetween(data, "<navMap>", "</navMap>", "<navMap>\n"+epubNavNCX(nodes, "    ", &count)+"  </navMap>")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("couldn't replace navigation in %s: %w", f.Name, err)
+		}
+
+		// The mimetype file has to stay uncompressed, so the method of each file is kept.
+		fw, err := w.CreateHeader(&zip.FileHeader{Name: f.Name, Method: f.Method, Modified: f.Modified})
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = fw.Write(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+// replaceBetween replaces everything from the first occurrence of start to the last occurrence of end, inclusive.
+func replaceBetween(data []byte, start, end, replacement string) ([]byte, error) {
+	i := bytes.Index(data, []byte(start))
+	j := bytes.LastIndex(data, []byte(end))
+
+	if i == -1 || j == -1 || j < i {
+		return nil, fmt.Errorf("couldn't find %s", start)
+	}
+
+	return append(append(append([]byte{}, data[:i]...), replacement...), data[j+len(end):]...), nil
+}
+
+// epubTagIDs returns the ID of the heading for each tag on the tags page, numbered in alphabetical order.
+func epubTagIDs(tags []string) map[string]string {
+	sorted := append([]string{}, tags...)
+	sort.Strings(sorted)
+
+	ids := map[string]string{}
+	for i, tag := range sorted {
+		ids[tag] = fmt.Sprintf("tag-%d", i+1)
+	}
+
+	return ids
+}
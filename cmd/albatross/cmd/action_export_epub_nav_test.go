@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	. "github.com/stretchr/testify/assert"
+)
+
+// epubTestCollection returns a collection of entries for testing EPUB export, two of which have the same date and title.
+func epubTestCollection(t *testing.T) *entries.Collection {
+	date := time.Date(2020, 8, 6, 18, 24, 0, 0, time.UTC)
+
+	collection := entries.NewCollection()
+
+	err := collection.AddMany(
+		&entries.Entry{Path: "food", Title: "Food", Contents: "Food.", Date: date},
+		&entries.Entry{Path: "food/pizza", Title: "Pizza", Contents: "Pizza.", Date: date},
+		&entries.Entry{Path: "food/pizza/margherita", Title: "Pizza", Contents: "Margherita.", Date: date},
+		&entries.Entry{Path: "journal/2020-08-06", Title: "Journal", Contents: "Ate pizza.", Date: date},
+	)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	return collection
+}
+
+func TestNewEpubSections(t *testing.T) {
+	list := epubTestCollection(t).List()
+	sections := newEpubSections(list)
+
+	validID := regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9._-]*$`)
+	seen := map[string]bool{}
+
+	for _, entry := range list.Slice() {
+		name := sections.file(entry.Path)
+
+		Regexp(t, validID, name, "expecting file names to be valid XML IDs")
+		False(t, seen[name], "expecting file names to be unique")
+		seen[name] = true
+	}
+
+	Equal(t, "unknown.xhtml", sections.file("food/chips"))
+	Equal(t, sections.file("food/pizza"), newEpubSections(list.Sort(entries.SortDate)).file("food/pizza"), "expecting names not to depend on order")
+
+	pizza, margherita := list.Sort(entries.SortPath).Slice()[1], list.Sort(entries.SortPath).Slice()[2]
+	Equal(t, "Thu 2020-08-06: Pizza (food/pizza)", sections.title(pizza), "expecting duplicate titles to be told apart")
+	Equal(t, "Thu 2020-08-06: Pizza (food/pizza/margherita)", sections.title(margherita))
+}
+
+func TestEpubNavTree(t *testing.T) {
+	collection := epubTestCollection(t)
+	list := collection.List().Sort(entries.SortPath)
+	sections := newEpubSections(list)
+
+	nodes := epubNavTree(collection, list, sections)
+	Len(t, nodes, 6, "expecting the four pages and the two top-level folders")
+
+	food := nodes[4]
+	Equal(t, "Thu 2020-08-06: Food", food.label)
+	Len(t, food.children, 1)
+	Len(t, food.children[0].children, 1, "expecting sub-entries to be nested")
+
+	journal := nodes[5]
+	Equal(t, "journal", journal.label, "expecting folders which aren't entries to use their name")
+	Equal(t, "xhtml/"+sections.file("journal/2020-08-06"), journal.href, "expecting folders to link to their first entry")
+
+	collection.AddFolder(&entries.Folder{Path: "journal", Title: "Diary"})
+
+	nodes = epubNavTree(collection, list, sections)
+	Equal(t, "Diary", nodes[5].label, "expecting folders with a title in their _meta.yaml to use it")
+}
+
+func TestConvertToEpubNav(t *testing.T) {
+	collection := epubTestCollection(t)
+	list := collection.List().Sort(entries.SortPath)
+
+	book, err := convertToEpub(collection, collection, list, entries.Bibliography{}, albatross.Shortcodes{}, "Food", "Me", "albatross get", false, 2)
+	if err != nil {
+		t.Fatalf("not expecting error creating EPUB: %s", err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(book), int64(len(book)))
+	if err != nil {
+		t.Fatalf("not expecting error reading EPUB: %s", err)
+	}
+
+	Equal(t, "mimetype", r.File[0].Name, "expecting the mimetype file to stay first")
+	Equal(t, zip.Store, r.File[0].Method, "expecting the mimetype file to stay uncompressed")
+
+	files := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("not expecting error opening %s: %s", f.Name, err)
+		}
+
+		data, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("not expecting error reading %s: %s", f.Name, err)
+		}
+
+		files[f.Name] = string(data)
+	}
+
+	Regexp(t, `(?s)<li>\s*<a href="xhtml/entry-[0-9a-f]+\.xhtml">Thu 2020-08-06: Food</a>\s*<ol>`, files["EPUB/nav.xhtml"], "expecting nested navigation")
+	NotContains(t, files["EPUB/nav.xhtml"], "unknown.xhtml")
+	Regexp(t, `(?s)<navPoint id="navPoint-5" playOrder="5">.*?<navPoint id="navPoint-6" playOrder="6">.*?</navPoint>.*?</navPoint>`, files["EPUB/toc.ncx"])
+	Contains(t, files["EPUB/toc.ncx"], `<meta name="dtb:uid"`, "expecting the rest of the NCX to be kept")
+}
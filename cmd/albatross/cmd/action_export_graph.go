@@ -0,0 +1,382 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/spf13/cobra"
+)
+
+// ActionExportGraphCmd represents the 'export graph' action.
+var ActionExportGraphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "export the graph of links between entries",
+	Long: `graph exports the links between the matched entries as a graph, so the store can be visualised in tools like
+Graphviz, Gephi, Cytoscape or anything which can draw a graph from JSON.
+
+	$ albatross get -p school export graph -o school.dot
+	$ dot -Tsvg school.dot -o school.svg
+
+	$ albatross get export graph --format gexf --colour -o store.gexf
+
+Each matched entry is a node, and there's an edge from one entry to another if it links to it, weighted by the number
+of links. Only links between matched entries are included, so links to entries which don't exist or which weren't
+matched are left out, as are links from entries to themselves. To leave out entries which don't link to or from any
+other entry, use --exclude-orphans.
+
+The format is given by --format:
+
+	dot      Graphviz DOT. This is the default.
+	graphml  GraphML, which most graph tools can import.
+	gexf     GEXF, the format used by Gephi.
+	json     An object with "nodes" and "edges", where edges go "from" one path "to" another.
+
+Nodes are labelled with the entry's title, and have its path, title and its top-level folder as a "group", like
+"school" for "school/physics/forces". To colour nodes by their top-level folder, use --colour.
+
+The graph is printed to stdout unless an output location is given with --output/-o.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		_, collection, _ := getFromCommand(cmd)
+
+		format, err := cmd.Flags().GetString("format")
+		checkArg(err)
+
+		excludeOrphans, err := cmd.Flags().GetBool("exclude-orphans")
+		checkArg(err)
+
+		colour, err := cmd.Flags().GetBool("colour")
+		checkArg(err)
+
+		outputDest, err := cmd.Flags().GetString("output")
+		checkArg(err)
+
+		graph := collection.Graph()
+		if excludeOrphans {
+			graph = graph.WithoutOrphans()
+		}
+
+		var colours map[string]string
+		if colour {
+			colours = groupColours(graph.Groups())
+		}
+
+		out, err := renderGraph(graph, format, colours)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if outputDest == "" {
+			os.Stdout.Write(out)
+			return
+		}
+
+		err = ioutil.WriteFile(outputDest, out, 0644)
+		if err != nil {
+			fmt.Println("Couldn't write to output destination:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// graphPalette are the colours given to the groups of nodes by --colour, in order. If there are more groups than
+// colours, they're reused.
+var graphPalette = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f", "#edc948",
+	"#b07aa1", "#ff9da7", "#9c755f", "#bab0ac", "#86bcb6", "#d37295",
+}
+
+// groupColours returns the colour for each of the groups given, from graphPalette.
+func groupColours(groups []string) map[string]string {
+	colours := map[string]string{}
+	for i, group := range groups {
+		colours[group] = graphPalette[i%len(graphPalette)]
+	}
+
+	return colours
+}
+
+// renderGraph renders the graph in the format given, either "dot", "graphml", "gexf" or "json". If colours isn't nil, nodes
+// are coloured using the colour of their group in it, as a hex colour like "#4e79a7".
+func renderGraph(graph *entries.Graph, format string, colours map[string]string) ([]byte, error) {
+	switch format {
+	case "dot":
+		return graphDOT(graph, colours), nil
+	case "graphml":
+		return graphML(graph, colours)
+	case "gexf":
+		return graphGEXF(graph, colours)
+	case "json":
+		return graphJSON(graph, colours)
+	}
+
+	return nil, fmt.Errorf("invalid --format %q, expected 'dot', 'graphml', 'gexf' or 'json'", format)
+}
+
+// dotQuote quotes a string for use as an ID or attribute in the DOT language.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// graphDOT renders the graph in the Graphviz DOT language.
+func graphDOT(graph *entries.Graph, colours map[string]string) []byte {
+	var out bytes.Buffer
+
+	out.WriteString("digraph albatross {\n")
+	out.WriteString("\tnode [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\"];\n")
+
+	for _, node := range graph.Nodes {
+		fmt.Fprintf(&out, "\t%s [label=%s, group=%s", dotQuote(node.Path), dotQuote(node.Title), dotQuote(node.Group))
+		if colour, ok := colours[node.Group]; ok {
+			fmt.Fprintf(&out, ", fillcolor=%s", dotQuote(colour))
+		}
+		out.WriteString("];\n")
+	}
+
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&out, "\t%s -> %s [weight=%d];\n", dotQuote(edge.From), dotQuote(edge.To), edge.Weight)
+	}
+
+	out.WriteString("}\n")
+
+	return out.Bytes()
+}
+
+// graphJSONNode is a node in the graph exported as JSON, which has a colour if nodes are being coloured.
+type graphJSONNode struct {
+	entries.GraphNode
+	Colour string `json:"colour,omitempty"`
+}
+
+// graphJSON renders the graph as JSON.
+func graphJSON(graph *entries.Graph, colours map[string]string) ([]byte, error) {
+	nodes := make([]graphJSONNode, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		nodes[i] = graphJSONNode{GraphNode: node, Colour: colours[node.Group]}
+	}
+
+	out, err := json.Marshal(map[string]interface{}{"nodes": nodes, "edges": graph.Edges})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(out, '\n'), nil
+}
+
+// rgb returns the red, green and blue parts of a hex colour like "#4e79a7".
+func rgb(colour string) (r, g, b int) {
+	n, _ := strconv.ParseUint(strings.TrimPrefix(colour, "#"), 16, 32)
+	return int(n >> 16 & 0xff), int(n >> 8 & 0xff), int(n & 0xff)
+}
+
+// graphMLKey declares an attribute of nodes or edges in GraphML.
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+// graphMLData is the value of an attribute of a node or edge in GraphML.
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// graphMLNode is a node in GraphML.
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+// graphMLEdge is an edge in GraphML.
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+// graphML renders the graph as GraphML. Colours are given as "r", "g" and "b" attributes, which is how Gephi reads them.
+func graphML(graph *entries.Graph, colours map[string]string) ([]byte, error) {
+	keys := []graphMLKey{
+		{ID: "title", For: "node", Name: "title", Type: "string"},
+		{ID: "group", For: "node", Name: "group", Type: "string"},
+	}
+
+	if colours != nil {
+		keys = append(keys,
+			graphMLKey{ID: "r", For: "node", Name: "r", Type: "int"},
+			graphMLKey{ID: "g", For: "node", Name: "g", Type: "int"},
+			graphMLKey{ID: "b", For: "node", Name: "b", Type: "int"},
+		)
+	}
+
+	keys = append(keys, graphMLKey{ID: "weight", For: "edge", Name: "weight", Type: "int"})
+
+	nodes := []graphMLNode{}
+	for _, node := range graph.Nodes {
+		data := []graphMLData{{Key: "title", Value: node.Title}, {Key: "group", Value: node.Group}}
+
+		if colour, ok := colours[node.Group]; ok {
+			r, g, b := rgb(colour)
+			data = append(data,
+				graphMLData{Key: "r", Value: strconv.Itoa(r)},
+				graphMLData{Key: "g", Value: strconv.Itoa(g)},
+				graphMLData{Key: "b", Value: strconv.Itoa(b)},
+			)
+		}
+
+		nodes = append(nodes, graphMLNode{ID: node.Path, Data: data})
+	}
+
+	edges := []graphMLEdge{}
+	for _, edge := range graph.Edges {
+		edges = append(edges, graphMLEdge{
+			Source: edge.From,
+			Target: edge.To,
+			Data:   []graphMLData{{Key: "weight", Value: strconv.Itoa(edge.Weight)}},
+		})
+	}
+
+	document := struct {
+		XMLName xml.Name     `xml:"graphml"`
+		XMLNS   string       `xml:"xmlns,attr"`
+		Keys    []graphMLKey `xml:"key"`
+		Graph   struct {
+			ID          string        `xml:"id,attr"`
+			EdgeDefault string        `xml:"edgedefault,attr"`
+			Nodes       []graphMLNode `xml:"node"`
+			Edges       []graphMLEdge `xml:"edge"`
+		} `xml:"graph"`
+	}{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  keys,
+	}
+
+	document.Graph.ID = "albatross"
+	document.Graph.EdgeDefault = "directed"
+	document.Graph.Nodes = nodes
+	document.Graph.Edges = edges
+
+	return encodeXML(document)
+}
+
+// gexfColour is the colour of a node in GEXF.
+type gexfColour struct {
+	R int `xml:"r,attr"`
+	G int `xml:"g,attr"`
+	B int `xml:"b,attr"`
+}
+
+// gexfAttValue is the value of an attribute of a node in GEXF.
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// gexfNode is a node in GEXF.
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+	Colour    *gexfColour    `xml:"viz:color,omitempty"`
+}
+
+// gexfEdge is an edge in GEXF.
+type gexfEdge struct {
+	ID     string `xml:"id,attr"`
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+	Weight int    `xml:"weight,attr"`
+}
+
+// gexfAttribute declares an attribute of nodes in GEXF.
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+// graphGEXF renders the graph as GEXF 1.2, the format used by Gephi.
+func graphGEXF(graph *entries.Graph, colours map[string]string) ([]byte, error) {
+	nodes := []gexfNode{}
+	for _, node := range graph.Nodes {
+		gexf := gexfNode{
+			ID:        node.Path,
+			Label:     node.Title,
+			AttValues: []gexfAttValue{{For: "path", Value: node.Path}, {For: "group", Value: node.Group}},
+		}
+
+		if colour, ok := colours[node.Group]; ok {
+			r, g, b := rgb(colour)
+			gexf.Colour = &gexfColour{R: r, G: g, B: b}
+		}
+
+		nodes = append(nodes, gexf)
+	}
+
+	edges := []gexfEdge{}
+	for i, edge := range graph.Edges {
+		edges = append(edges, gexfEdge{ID: strconv.Itoa(i), Source: edge.From, Target: edge.To, Weight: edge.Weight})
+	}
+
+	document := struct {
+		XMLName  xml.Name `xml:"gexf"`
+		XMLNS    string   `xml:"xmlns,attr"`
+		XMLNSViz string   `xml:"xmlns:viz,attr"`
+		Version  string   `xml:"version,attr"`
+		Graph    struct {
+			DefaultEdgeType string          `xml:"defaultedgetype,attr"`
+			Attributes      []gexfAttribute `xml:"attributes>attribute"`
+			Nodes           []gexfNode      `xml:"nodes>node"`
+			Edges           []gexfEdge      `xml:"edges>edge"`
+		} `xml:"graph"`
+	}{
+		XMLNS:    "http://www.gexf.net/1.2draft",
+		XMLNSViz: "http://www.gexf.net/1.2draft/viz",
+		Version:  "1.2",
+	}
+
+	document.Graph.DefaultEdgeType = "directed"
+	document.Graph.Attributes = []gexfAttribute{{ID: "path", Title: "path", Type: "string"}, {ID: "group", Title: "group", Type: "string"}}
+	document.Graph.Nodes = nodes
+	document.Graph.Edges = edges
+
+	return encodeXML(document)
+}
+
+// encodeXML encodes v as an indented XML document.
+func encodeXML(v interface{}) ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&out)
+	enc.Indent("", "  ")
+
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	out.WriteString("\n")
+
+	return out.Bytes(), nil
+}
+
+func init() {
+	ActionExportCmd.AddCommand(ActionExportGraphCmd)
+
+	ActionExportGraphCmd.Flags().String("format", "dot", "format of the graph, 'dot', 'graphml', 'gexf' or 'json'")
+	ActionExportGraphCmd.Flags().Bool("exclude-orphans", false, "leave out entries which don't link to or from any other entry")
+	ActionExportGraphCmd.Flags().Bool("colour", false, "colour entries by their top-level folder")
+	ActionExportGraphCmd.Flags().StringP("output", "o", "", "output location of the graph, by default it's printed to stdout")
+}
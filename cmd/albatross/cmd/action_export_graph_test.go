@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/albatross-org/go-albatross/entries"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func testGraph() *entries.Graph {
+	return &entries.Graph{
+		Nodes: []entries.GraphNode{
+			{Path: "food/pizza", Title: `The "Best" Pizza`, Group: "food", Outbound: 1},
+			{Path: "moods/hunger", Title: "Hunger", Group: "moods", Inbound: 1},
+		},
+		Edges: []entries.GraphEdge{
+			{From: "food/pizza", To: "moods/hunger", Weight: 2},
+		},
+	}
+}
+
+func TestGraphDOT(t *testing.T) {
+	graph := testGraph()
+
+	out := string(graphDOT(graph, nil))
+	Contains(t, out, `"food/pizza" [label="The \"Best\" Pizza", group="food"];`)
+	Contains(t, out, `"food/pizza" -> "moods/hunger" [weight=2];`)
+
+	out = string(graphDOT(graph, groupColours(graph.Groups())))
+	Contains(t, out, `"moods/hunger" [label="Hunger", group="moods", fillcolor="#f28e2b"];`)
+}
+
+func TestGraphXML(t *testing.T) {
+	graph := testGraph()
+	colours := groupColours(graph.Groups())
+
+	for _, format := range []string{"graphml", "gexf"} {
+		out, err := renderGraph(graph, format, colours)
+		Nil(t, err)
+		True(t, strings.HasPrefix(string(out), xml.Header))
+
+		var document struct {
+			XMLName xml.Name
+		}
+		Nil(t, xml.Unmarshal(out, &document), "expecting %s to be valid XML", format)
+		Equal(t, format, document.XMLName.Local)
+	}
+
+	out, err := graphGEXF(graph, colours)
+	Nil(t, err)
+	Contains(t, string(out), `<viz:color r="78" g="121" b="167"></viz:color>`)
+}
+
+func TestGraphJSON(t *testing.T) {
+	out, err := renderGraph(testGraph(), "json", nil)
+	Nil(t, err)
+
+	var decoded struct {
+		Nodes []map[string]interface{} `json:"nodes"`
+		Edges []entries.GraphEdge      `json:"edges"`
+	}
+	Nil(t, json.Unmarshal(out, &decoded))
+	Len(t, decoded.Nodes, 2)
+	NotContains(t, decoded.Nodes[0], "colour", "expecting no colour unless colouring nodes")
+	Equal(t, testGraph().Edges, decoded.Edges)
+
+	_, err = renderGraph(testGraph(), "svg", nil)
+	NotNil(t, err)
+}
@@ -0,0 +1,765 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	"github.com/albatross-org/go-albatross/server"
+	"github.com/spf13/cobra"
+	"github.com/yuin/goldmark"
+)
+
+// htmlEngines are the engines 'export html' can use to build a site.
+var htmlEngines = []string{"native"}
+
+// ActionExportHTMLCmd represents the 'export html' action.
+var ActionExportHTMLCmd = &cobra.Command{
+	Use:   "html",
+	Short: "output entries as a static website",
+	Long: `html renders the matched entries into a self-contained static website, which can be opened straight from the
+folder or published anywhere that serves files.
+
+	$ albatross get -p notes export html -o site
+	$ open site/index.html
+
+The site contains:
+
+	index.html             Every entry, in the order given by --sort, or by path if it isn't given.
+	timeline.html          Every entry by date, newest first.
+	tags/index.html        Every tag, and a page for each one listing the entries with it.
+	graph.html             The links between entries as a graph which can be moved around and zoomed.
+	graph.json             The same graph as JSON, in the format used by 'export graph --format json'.
+	entries/<path>/        A page for each entry, along with its attachments.
+	style.css
+	graph.js
+	robots.txt
+
+Links between the matched entries become links between their pages, and each page lists the matched entries which link
+to it under "Links to this entry". Links to entries which weren't matched are left as text. To leave out the list of
+links to each entry, use --show-backlinks=false.
+
+On the graph page, entries are coloured by their top-level folder and clicking one opens its page. Each entry's page
+links to it on the graph, which then only shows that entry and the entries it links to or from. The graph is drawn by a
+small script included in the site, so it works offline too.
+
+The site is built by the 'native' engine, which renders the entries using the same Markdown as the other exports, with
+the templates built into albatross, so nothing else needs to be installed. This is the only engine at the moment, and
+is chosen using --engine.
+
+If the site will be published, give the URL it'll be published at using --base-url. Links between the pages then use
+absolute URLs, each entry's page gets a canonical link and Open Graph metadata so that it's indexed and shared properly,
+and a sitemap.xml listing every page is added, along with the time each entry was last changed according to git:
+
+	$ albatross get -p blog export html -o site --base-url https://notes.example.com
+
+Entries tagged "@?unlisted" still get a page, and links to them from other entries still work, but they're left out of
+the index, the timeline, the tag pages and the sitemap, and their page asks search engines not to index it. To use a
+different tag, use --unlisted-tag, or give an empty one to treat every entry the same:
+
+	$ albatross get -p blog export html -o site --unlisted-tag "@?draft"
+
+To preview the site, use --serve to serve it over HTTP once it's been written. Like 'albatross get server', use
+--cors-origin to allow other sites to fetch its pages using JavaScript and --allow-embed to allow them to embed pages in
+an iframe, such as a dashboard or homepage showing some notes:
+
+	$ albatross get -p recipes export html -o site --serve --allow-embed https://home.example.com`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		outputDest, err := cmd.Flags().GetString("output")
+		checkArg(err)
+
+		engine, err := cmd.Flags().GetString("engine")
+		checkArg(err)
+
+		title, err := cmd.Flags().GetString("site-title")
+		checkArg(err)
+
+		baseURL, err := cmd.Flags().GetString("base-url")
+		checkArg(err)
+
+		excludeAttachments, err := cmd.Flags().GetBool("exclude-attachments")
+		checkArg(err)
+
+		unlistedTag, err := cmd.Flags().GetString("unlisted-tag")
+		checkArg(err)
+
+		showBacklinks, err := cmd.Flags().GetBool("show-backlinks")
+		checkArg(err)
+
+		serve, err := cmd.Flags().GetBool("serve")
+		checkArg(err)
+
+		port, err := cmd.Flags().GetInt("port")
+		checkArg(err)
+
+		corsOrigins, err := cmd.Flags().GetStringSlice("cors-origin")
+		checkArg(err)
+
+		allowEmbed, err := cmd.Flags().GetStringSlice("allow-embed")
+		checkArg(err)
+
+		if engine != "native" {
+			fmt.Printf("Unknown engine %q, the engines available are: %s\n", engine, strings.Join(htmlEngines, ", "))
+			os.Exit(1)
+		}
+
+		if _, err := os.Stat(outputDest); !os.IsNotExist(err) {
+			fmt.Printf("Cannot output site to %s:\n", outputDest)
+			fmt.Println("Directory/file already exists.")
+			os.Exit(1)
+		}
+
+		var handler http.Handler
+		if serve {
+			handler, err = server.SiteHandler(outputDest, corsOrigins, allowEmbed)
+			if err != nil {
+				fmt.Printf("Invalid --cors-origin: %s\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// Attachments are encrypted along with the entries, so the store has to stay decrypted after the entries are found.
+		reencrypt := false
+
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+			reencrypt = !leaveDecrypted
+		}
+
+		defer func() {
+			if reencrypt {
+				encryptStore()
+			}
+		}()
+
+		all, collection, list := getFromCommand(cmd)
+		refuseSecrets(cmd, list)
+
+		site := &nativeSite{
+			title:        title,
+			md:           newExportMarkdown(),
+			backlinks:    showBacklinks,
+			unlistedTag:  unlistedTag,
+			lastModified: store.LastModified,
+			jobs:         exportJobs(cmd),
+		}
+
+		if baseURL != "" {
+			site.urls, err = newSiteURLs(baseURL)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		site.bib, err = store.Bibliography()
+		if err != nil {
+			fmt.Println("Couldn't load bibliography:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		site.shortcodes, err = store.Shortcodes()
+		if err != nil {
+			fmt.Println("Couldn't load shortcodes:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if !excludeAttachments {
+			site.entriesPath = filepath.Join(store.Path, "entries")
+			site.attachments = store.Attachments
+		}
+
+		if !cmd.Flag("sort").Changed {
+			list = list.Sort(entries.SortPath)
+		}
+
+		files, err := site.build(all, collection, list)
+		if err != nil {
+			fmt.Println("Error when building the site:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		err = writeFiles(outputDest, files)
+		if err != nil {
+			fmt.Println("Couldn't write the site:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Written %d entries to %s\n", list.Len(), filepath.Join(outputDest, "index.html"))
+
+		if serve {
+			// Serving only stops when the process is killed, so the store is encrypted again first. Everything the site
+			// needs has already been written.
+			if reencrypt {
+				encryptStore()
+				reencrypt = false
+			}
+
+			fmt.Printf("Serving the site at http://localhost:%d\n", port)
+
+			err = http.ListenAndServe(":"+fmt.Sprint(port), handler)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	},
+}
+
+// nativeSite builds a static website from entries without needing anything other than albatross, for 'export html'.
+type nativeSite struct {
+	// title is the name of the site, shown at the top of every page.
+	title string
+
+	// urls are used to give pages absolute URLs if the site will be published, or nil to use relative links.
+	urls *siteURLs
+
+	md         goldmark.Markdown
+	bib        entries.Bibliography
+	shortcodes albatross.Shortcodes
+
+	// attachments returns the attachments of the entry at a path, which are copied from entriesPath. If it's nil, no
+	// attachments are copied.
+	attachments func(path string) ([]string, error)
+	entriesPath string
+
+	// backlinks is set if each entry's page lists the entries which link to it.
+	backlinks bool
+
+	// unlistedTag is the tag of entries which get a page but are left out of the lists of entries and the sitemap, and
+	// aren't indexed by search engines. If it's empty, no entries are unlisted.
+	unlistedTag string
+
+	// jobs is the number of entries to render at once.
+	jobs int
+
+	// lastModified returns the time the entry at a path was last changed, for the sitemap. If it's nil or returns the
+	// zero time, the entry's date is used instead.
+	lastModified func(path string) (time.Time, error)
+}
+
+// sitePage is the data given to the templates for a page.
+type sitePage struct {
+	SiteTitle string
+	Title     string
+
+	// Root is the relative path from the page to the root of the site, like "../../".
+	Root string
+
+	// Meta are extra tags for the <head> of the page.
+	Meta template.HTML
+
+	// Date, Tags, Body and Backlinks are set for the pages of entries.
+	Date      time.Time
+	Tags      []siteLink
+	Body      template.HTML
+	Backlinks []siteLink
+
+	// GraphHref is the link to the entry on the graph page, set for the pages of listed entries.
+	GraphHref string
+
+	// Links are set for pages which list entries or tags, and Groups for the timeline.
+	Links  []siteLink
+	Groups []siteGroup
+
+	// Graph is the JSON drawn by the graph page.
+	Graph template.JS
+}
+
+// siteLink is a link to another page of the site.
+type siteLink struct {
+	Text  string
+	Href  string
+	Date  time.Time
+	Count int
+}
+
+// siteGroup is a heading followed by a list of links, like a month in the timeline.
+type siteGroup struct {
+	Heading string
+	Links   []siteLink
+}
+
+// build returns the files of the site for the entries in the list, in the order of the list. Links between entries only
+// point to the entries in collection, and included entries come from all.
+func (n *nativeSite) build(all, collection *entries.Collection, list entries.List) ([]archiveFile, error) {
+	slice := list.Slice()
+
+	listed := []*entries.Entry{}
+	for _, entry := range slice {
+		if !n.unlisted(entry) {
+			listed = append(listed, entry)
+		}
+	}
+
+	tagPages := siteTagPages(listed)
+
+	modified := map[string]time.Time{}
+	for _, entry := range listed {
+		when, err := n.modified(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		modified[entry.Path] = when
+	}
+
+	latest := latestModified(modified, listed)
+
+	files := []archiveFile{{name: "style.css", data: []byte(siteStylesheet)}, {name: "graph.js", data: []byte(siteGraphScript)}}
+	sitemap := []sitemapURL{{Loc: "index.html"}, {Loc: "timeline.html"}, {Loc: "tags/index.html"}, {Loc: "graph.html"}}
+	for i := range sitemap {
+		sitemap[i].LastMod = sitemapDate(latest)
+	}
+
+	pages := make([]archiveFile, len(slice))
+
+	err := renderEach(n.jobs, len(slice), func(i int) error {
+		var err error
+		pages[i], err = n.entryPage(all, collection, slice[i], tagPages)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, entry := range slice {
+		page := pages[i].name
+		files = append(files, pages[i])
+
+		if !n.unlisted(entry) {
+			sitemap = append(sitemap, sitemapURL{Loc: page, LastMod: sitemapDate(modified[entry.Path])})
+		}
+
+		if n.attachments == nil {
+			continue
+		}
+
+		attachments, err := n.attachments(entry.Path)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get attachments for %s: %w", entry.Path, err)
+		}
+
+		for _, attachment := range attachments {
+			files = append(files, archiveFile{
+				name:   path.Join(path.Dir(page), attachment),
+				source: filepath.Join(n.entriesPath, filepath.FromSlash(entry.Path), filepath.FromSlash(attachment)),
+			})
+		}
+	}
+
+	index, err := n.render("list", "index.html", sitePage{Links: n.entryLinks("", listed)})
+	if err != nil {
+		return nil, err
+	}
+
+	timeline, err := n.render("timeline", "timeline.html", sitePage{Title: "Timeline", Groups: n.timelineGroups(listed)})
+	if err != nil {
+		return nil, err
+	}
+
+	graphJSON, err := n.graphJSON(collection)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create graph: %w", err)
+	}
+
+	graph, err := n.render("graph", "graph.html", sitePage{Title: "Graph", Graph: template.JS(graphJSON)})
+	if err != nil {
+		return nil, err
+	}
+
+	files = append(files, index, timeline, graph, archiveFile{name: "graph.json", data: graphJSON})
+
+	tags := []string{}
+	for tag := range tagPages {
+		tags = append(tags, tag)
+	}
+
+	sort.Strings(tags)
+
+	tagsIndex := sitePage{Title: "Tags", Root: "../"}
+
+	for _, tag := range tags {
+		tagged := []*entries.Entry{}
+		for _, entry := range listed {
+			if hasTag(entry, tag) {
+				tagged = append(tagged, entry)
+			}
+		}
+
+		sort.SliceStable(tagged, func(i, j int) bool { return tagged[i].Date.After(tagged[j].Date) })
+
+		tagsIndex.Links = append(tagsIndex.Links, siteLink{Text: tag, Href: n.href("../", tagPages[tag]), Count: len(tagged)})
+
+		file, err := n.render("list", tagPages[tag], sitePage{Title: tag, Root: "../", Links: n.entryLinks("../", tagged)})
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+		sitemap = append(sitemap, sitemapURL{Loc: tagPages[tag], LastMod: sitemapDate(latestModified(modified, tagged))})
+	}
+
+	file, err := n.render("tags", "tags/index.html", tagsIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	files = append(files, file)
+
+	if n.urls != nil {
+		for i := range sitemap {
+			sitemap[i].Loc = n.href("", sitemap[i].Loc)
+		}
+
+		data, err := sitemapXML(sitemap)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create sitemap: %w", err)
+		}
+
+		files = append(files, archiveFile{name: "sitemap.xml", data: data})
+	}
+
+	files = append(files, archiveFile{name: "robots.txt", data: robotsTxt(n.urls)})
+
+	return files, nil
+}
+
+// siteGraphNode is an entry on the graph page, which links to the entry's page.
+type siteGraphNode struct {
+	entries.GraphNode
+	Href   string `json:"href"`
+	Colour string `json:"colour"`
+}
+
+// graphJSON returns the graph of links between the listed entries in the collection as JSON, for the graph page and
+// graph.json. Nodes are coloured by their top-level folder, like 'export graph --colour'.
+func (n *nativeSite) graphJSON(collection *entries.Collection) ([]byte, error) {
+	graph := collection.Graph()
+
+	nodes := []siteGraphNode{}
+	edges := []entries.GraphEdge{}
+
+	unlisted := map[string]bool{}
+	for _, node := range graph.Nodes {
+		if n.unlisted(collection.Get(node.Path)) {
+			unlisted[node.Path] = true
+		}
+	}
+
+	// Links to and from unlisted entries aren't shown, so they aren't counted either.
+	inbound, outbound := map[string]int{}, map[string]int{}
+	for _, edge := range graph.Edges {
+		if !unlisted[edge.From] && !unlisted[edge.To] {
+			edges = append(edges, edge)
+			inbound[edge.To]++
+			outbound[edge.From]++
+		}
+	}
+
+	colours := groupColours(graph.Groups())
+
+	for _, node := range graph.Nodes {
+		if unlisted[node.Path] {
+			continue
+		}
+
+		node.Inbound, node.Outbound = inbound[node.Path], outbound[node.Path]
+
+		nodes = append(nodes, siteGraphNode{
+			GraphNode: node,
+			Href:      n.href("", entrySitePage(node.Path)),
+			Colour:    colours[node.Group],
+		})
+	}
+
+	return json.Marshal(map[string]interface{}{"nodes": nodes, "edges": edges})
+}
+
+// unlisted returns true if the entry is left out of the lists of entries and the sitemap.
+func (n *nativeSite) unlisted(entry *entries.Entry) bool {
+	return n.unlistedTag != "" && hasTag(entry, n.unlistedTag)
+}
+
+// modified returns the time the entry was last changed, or its date if that isn't known.
+func (n *nativeSite) modified(entry *entries.Entry) (time.Time, error) {
+	if n.lastModified != nil {
+		when, err := n.lastModified(entry.Path)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("couldn't get the last modified time of %s: %w", entry.Path, err)
+		}
+
+		if !when.IsZero() {
+			return when, nil
+		}
+	}
+
+	return entry.Date, nil
+}
+
+// entryPage renders the page for an entry, linking its tags to the pages given.
+func (n *nativeSite) entryPage(all, collection *entries.Collection, entry *entries.Entry, tagPages map[string]string) (archiveFile, error) {
+	page := entrySitePage(entry.Path)
+	root := siteRoot(page)
+
+	contents, _, err := exportContents(all, n.shortcodes, entry)
+	if err != nil {
+		return archiveFile{}, err
+	}
+
+	body, err := entryHTML(n.md, collection, n.bib, entry, contents, func(linked *entries.Entry) string {
+		return n.href(root, entrySitePage(linked.Path))
+	})
+	if err != nil {
+		return archiveFile{}, err
+	}
+
+	data := sitePage{
+		Title: entry.Title,
+		Root:  root,
+		Date:  entry.Date,
+		Body:  template.HTML(body),
+	}
+
+	if n.unlisted(entry) {
+		data.Meta = template.HTML("<meta name=\"robots\" content=\"noindex\">\n")
+	} else {
+		data.GraphHref = n.href(root, "graph.html") + "#" + url.PathEscape(entry.Path)
+	}
+
+	if n.urls != nil {
+		data.Meta += template.HTML(pageMetaHTML(entry, n.href("", page)))
+	}
+
+	for _, tag := range sortedTags(entry) {
+		// Tags only used by unlisted entries don't have a page.
+		link := siteLink{Text: tag}
+		if tagPage, ok := tagPages[tag]; ok {
+			link.Href = n.href(root, tagPage)
+		}
+
+		data.Tags = append(data.Tags, link)
+	}
+
+	if !n.backlinks {
+		return n.render("entry", page, data)
+	}
+
+	for _, backlink := range collection.Backlinks(entry) {
+		data.Backlinks = append(data.Backlinks, siteLink{Text: backlink.Title, Href: n.href(root, entrySitePage(backlink.Path))})
+	}
+
+	return n.render("entry", page, data)
+}
+
+// render executes the template for a kind of page, returning the file for it at the path given.
+func (n *nativeSite) render(kind, name string, data sitePage) (archiveFile, error) {
+	data.SiteTitle = n.title
+
+	var buf bytes.Buffer
+
+	err := siteTemplates[kind].Execute(&buf, data)
+	if err != nil {
+		return archiveFile{}, fmt.Errorf("couldn't render %s: %w", name, err)
+	}
+
+	return archiveFile{name: name, data: buf.Bytes()}, nil
+}
+
+// href returns the link to a page of the site, given by its path from the root of the site, from a page whose path to the
+// root is root. If the site has a base URL, the link is absolute instead and leaves off "index.html".
+func (n *nativeSite) href(root, page string) string {
+	if n.urls != nil {
+		return n.urls.page(strings.TrimSuffix(page, "index.html"))
+	}
+
+	return root + page
+}
+
+// entryLinks returns links to the pages of the entries, from a page whose path to the root is root.
+func (n *nativeSite) entryLinks(root string, list []*entries.Entry) []siteLink {
+	links := []siteLink{}
+
+	for _, entry := range list {
+		links = append(links, siteLink{Text: entry.Title, Href: n.href(root, entrySitePage(entry.Path)), Date: entry.Date})
+	}
+
+	return links
+}
+
+// timelineGroups returns links to the entries grouped by the month they're from, newest first.
+func (n *nativeSite) timelineGroups(list []*entries.Entry) []siteGroup {
+	sorted := append([]*entries.Entry{}, list...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.After(sorted[j].Date) })
+
+	groups := []siteGroup{}
+
+	for _, entry := range sorted {
+		heading := entry.Date.Format("January 2006")
+		if len(groups) == 0 || groups[len(groups)-1].Heading != heading {
+			groups = append(groups, siteGroup{Heading: heading})
+		}
+
+		group := &groups[len(groups)-1]
+		group.Links = append(group.Links, n.entryLinks("", []*entries.Entry{entry})...)
+	}
+
+	return groups
+}
+
+// latestModified returns the latest of the modified times of the entries.
+func latestModified(modified map[string]time.Time, list []*entries.Entry) time.Time {
+	latest := time.Time{}
+
+	for _, entry := range list {
+		if modified[entry.Path].After(latest) {
+			latest = modified[entry.Path]
+		}
+	}
+
+	return latest
+}
+
+// entrySitePage returns the path of the page for the entry at the path given, from the root of the site.
+func entrySitePage(entryPath string) string {
+	return "entries/" + entryPath + "/index.html"
+}
+
+// siteRoot returns the relative path from a page to the root of the site, like "../../" for "entries/food/index.html".
+func siteRoot(page string) string {
+	return strings.Repeat("../", strings.Count(page, "/"))
+}
+
+// siteTagPages returns the path of the page for each tag used by the entries, like "tags/food.html" for "@?food".
+func siteTagPages(list []*entries.Entry) map[string]string {
+	tags := []string{}
+	seen := map[string]bool{}
+
+	for _, entry := range list {
+		for _, tag := range entry.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	sort.Strings(tags)
+
+	pages := map[string]string{}
+	used := map[string]bool{}
+
+	for _, tag := range tags {
+		slug := albatross.Slugify(tag)
+		if slug == "" {
+			slug = "tag"
+		}
+
+		// Tags like "#food" and "@?food" have the same slug, so a number is added to the ones after the first.
+		name := slug
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s-%d", slug, i)
+		}
+
+		used[name] = true
+		pages[tag] = "tags/" + name + ".html"
+	}
+
+	return pages
+}
+
+// sortedTags returns the entry's tags in alphabetical order.
+func sortedTags(entry *entries.Entry) []string {
+	tags := append([]string{}, entry.Tags...)
+	sort.Strings(tags)
+
+	return tags
+}
+
+// hasTag returns true if the entry has the tag given.
+func hasTag(entry *entries.Entry, tag string) bool {
+	for _, t := range entry.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeFiles writes the files into the folder given, creating it and any folders inside it.
+func writeFiles(dir string, files []archiveFile) error {
+	for _, file := range files {
+		err := writeFile(dir, file)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeFile writes a single file to the folder, like writeFiles.
+func writeFile(dir string, file archiveFile) error {
+	dest := filepath.Join(dir, filepath.FromSlash(file.name))
+
+	err := os.MkdirAll(filepath.Dir(dest), 0755)
+	if err != nil {
+		return err
+	}
+
+	r, _, err := file.open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	out, err := os.Create(dest)
+	if err == nil {
+		_, err = io.Copy(out, r)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("couldn't write %s: %w", file.name, err)
+	}
+
+	return nil
+}
+
+func init() {
+	ActionExportCmd.AddCommand(ActionExportHTMLCmd)
+
+	ActionExportHTMLCmd.Flags().StringP("output", "o", "site", "folder to output the site to, which mustn't already exist")
+	ActionExportHTMLCmd.Flags().String("engine", "native", "engine used to build the site, currently only 'native'")
+	ActionExportHTMLCmd.Flags().String("site-title", "Albatross", "title of the site, shown at the top of every page")
+	ActionExportHTMLCmd.Flags().String("base-url", "", "URL the site will be published at, for absolute links, canonical URLs and a sitemap")
+	ActionExportHTMLCmd.Flags().Bool("exclude-attachments", false, "don't copy any attachments, only render entries")
+	ActionExportHTMLCmd.Flags().Bool("show-backlinks", true, "list the entries which link to each entry on its page")
+	ActionExportHTMLCmd.Flags().String("unlisted-tag", "@?unlisted", "tag of entries to leave out of lists and the sitemap and mark noindex, empty for none")
+	ActionExportHTMLCmd.Flags().Bool("serve", false, "serve the site over HTTP after writing it")
+	ActionExportHTMLCmd.Flags().Int("port", 2719, "port to serve the site on with --serve")
+	ActionExportHTMLCmd.Flags().StringSlice("cors-origin", []string{}, "origins allowed to make cross-origin requests with --serve, '*' for any")
+	ActionExportHTMLCmd.Flags().StringSlice("allow-embed", []string{}, "origins allowed to embed pages in an iframe with --serve, '*' for any")
+}
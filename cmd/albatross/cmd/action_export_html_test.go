@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/albatross-org/go-albatross/pkg/markdown"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestNativeSiteBuild(t *testing.T) {
+	collection := entries.NewCollection()
+
+	pizza := &entries.Entry{
+		Path:     "food/pizza",
+		Title:    "Pizza",
+		Contents: "Pizza is *great*.",
+		Date:     time.Date(2020, 8, 6, 18, 24, 0, 0, time.UTC),
+		Tags:     []string{"@?food", "#food"},
+	}
+
+	journal := &entries.Entry{
+		Path:     "journal/2020-09-01",
+		Title:    "Journal",
+		Contents: "Ate {{food/pizza}} and {{food/chips}}, then more {{food/pizza}}.",
+		Date:     time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC),
+	}
+	journal.OutboundLinks = []entries.Link{
+		{Parent: journal, Path: "food/pizza", Type: entries.LinkPathNoName, Loc: []int{4, 18}},
+		{Parent: journal, Path: "food/chips", Type: entries.LinkPathNoName, Loc: []int{23, 37}},
+		{Parent: journal, Path: "food/pizza", Type: entries.LinkPathNoName, Loc: []int{49, 63}},
+	}
+
+	err := collection.AddMany(pizza, journal)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	site := &nativeSite{title: "Notes", md: markdown.New(), backlinks: true, jobs: 4}
+
+	files, err := site.build(collection, collection, collection.List().Sort(entries.SortPath))
+	if err != nil {
+		t.Fatalf("not expecting error building site: %s", err)
+	}
+
+	pages := map[string]string{}
+	for _, file := range files {
+		pages[file.name] = string(file.data)
+	}
+
+	Equal(t, []string{
+		"entries/food/pizza/index.html",
+		"entries/journal/2020-09-01/index.html",
+		"graph.html",
+		"graph.js",
+		"graph.json",
+		"index.html",
+		"robots.txt",
+		"style.css",
+		"tags/food-2.html",
+		"tags/food.html",
+		"tags/index.html",
+		"timeline.html",
+	}, sortedKeys(pages))
+
+	journalPage := pages["entries/journal/2020-09-01/index.html"]
+	Contains(t, journalPage, `<a href="../../../entries/food/pizza/index.html">{{food/pizza}}</a> and {{food/chips}}`)
+	Contains(t, journalPage, `more <a href="../../../entries/food/pizza/index.html">{{food/pizza}}</a>.`, "expecting repeated links to be linked once each")
+	Contains(t, journalPage, `<link rel="stylesheet" href="../../../style.css">`)
+
+	pizzaPage := pages["entries/food/pizza/index.html"]
+	Contains(t, pizzaPage, "<title>Pizza - Notes</title>")
+	Contains(t, pizzaPage, "<em>great</em>")
+	Contains(t, pizzaPage, `<li><a href="../../../entries/journal/2020-09-01/index.html">Journal</a></li>`, "expecting backlink")
+	Contains(t, pizzaPage, `<a class="tag" href="../../../tags/food.html">#food</a>`)
+
+	Contains(t, pages["index.html"], `<a href="entries/food/pizza/index.html">Pizza</a>`)
+	Regexp(t, `(?s)September 2020.*Journal.*August 2020.*Pizza`, pages["timeline.html"], "expecting newest entries first")
+	Contains(t, pages["tags/index.html"], `<a class="tag" href="../tags/food-2.html">@?food</a> (1)`)
+
+	Contains(t, pizzaPage, `<a href="../../../graph.html#food%2Fpizza">Show in graph</a>`)
+	Contains(t, pages["graph.html"], `<script src="graph.js"></script>`)
+	JSONEq(t, `{
+		"nodes": [
+			{"path": "food/pizza", "title": "Pizza", "group": "food", "inbound": 1, "outbound": 0, "href": "entries/food/pizza/index.html", "colour": "#4e79a7"},
+			{"path": "journal/2020-09-01", "title": "Journal", "group": "journal", "inbound": 0, "outbound": 1, "href": "entries/journal/2020-09-01/index.html", "colour": "#f28e2b"}
+		],
+		"edges": [{"from": "journal/2020-09-01", "to": "food/pizza", "weight": 2}]
+	}`, pages["graph.json"])
+	Contains(t, pages["graph.html"], pages["graph.json"], "expecting the graph to be in the page")
+
+	site.urls, err = newSiteURLs("https://notes.example.com")
+	if err != nil {
+		t.Fatalf("not expecting error parsing base URL: %s", err)
+	}
+
+	files, err = site.build(collection, collection, collection.List().Sort(entries.SortPath))
+	if err != nil {
+		t.Fatalf("not expecting error building site: %s", err)
+	}
+
+	pages = map[string]string{}
+	for _, file := range files {
+		pages[file.name] = string(file.data)
+	}
+
+	Contains(t, pages["entries/food/pizza/index.html"], `<link rel="canonical" href="https://notes.example.com/entries/food/pizza/">`)
+	Contains(t, pages["entries/journal/2020-09-01/index.html"], `<a href="https://notes.example.com/entries/food/pizza/">{{food/pizza}}</a>`)
+	Contains(t, pages["sitemap.xml"], "<loc>https://notes.example.com/tags/food.html</loc>")
+
+	site.backlinks = false
+
+	files, err = site.build(collection, collection, collection.List().Sort(entries.SortPath))
+	if err != nil {
+		t.Fatalf("not expecting error building site: %s", err)
+	}
+
+	for _, file := range files {
+		NotContains(t, string(file.data), "Links to this entry", "expecting no backlinks in %s", file.name)
+	}
+}
+
+func TestNativeSiteUnlisted(t *testing.T) {
+	collection := entries.NewCollection()
+
+	pizza := &entries.Entry{
+		Path:     "food/pizza",
+		Title:    "Pizza",
+		Contents: "Pizza is great.",
+		Date:     time.Date(2020, 8, 6, 18, 24, 0, 0, time.UTC),
+		Tags:     []string{"#food"},
+	}
+
+	draft := &entries.Entry{
+		Path:     "food/draft",
+		Title:    "Draft",
+		Contents: "Not finished yet.",
+		Date:     time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC),
+		Tags:     []string{"#food", "#secret", "@?unlisted"},
+	}
+
+	err := collection.AddMany(pizza, draft)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	site := &nativeSite{
+		title:       "Notes",
+		md:          markdown.New(),
+		unlistedTag: "@?unlisted",
+		lastModified: func(path string) (time.Time, error) {
+			if path == "food/pizza" {
+				return time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC), nil
+			}
+
+			return time.Time{}, nil
+		},
+	}
+
+	site.urls, err = newSiteURLs("https://notes.example.com")
+	if err != nil {
+		t.Fatalf("not expecting error parsing base URL: %s", err)
+	}
+
+	files, err := site.build(collection, collection, collection.List().Sort(entries.SortPath))
+	if err != nil {
+		t.Fatalf("not expecting error building site: %s", err)
+	}
+
+	pages := map[string]string{}
+	for _, file := range files {
+		pages[file.name] = string(file.data)
+	}
+
+	draftPage := pages["entries/food/draft/index.html"]
+	Contains(t, draftPage, `<meta name="robots" content="noindex">`, "expecting unlisted entries to still get a page")
+	Contains(t, draftPage, `<span class="tag">#secret</span>`, "expecting tags only used by unlisted entries to have no page")
+	NotContains(t, pages["entries/food/pizza/index.html"], "noindex")
+	NotContains(t, draftPage, "Show in graph")
+	Contains(t, pages["entries/food/pizza/index.html"], `<a href="https://notes.example.com/graph.html#food%2Fpizza">Show in graph</a>`)
+
+	for _, name := range []string{"index.html", "timeline.html", "tags/food.html", "sitemap.xml", "graph.json"} {
+		NotContains(t, pages[name], "food/draft", "expecting unlisted entries to be left out of %s", name)
+	}
+
+	NotContains(t, pages, "tags/secret.html")
+
+	Contains(t, pages["sitemap.xml"], "<loc>https://notes.example.com/entries/food/pizza/</loc>\n    <lastmod>2021-01-02</lastmod>")
+	Contains(t, pages["sitemap.xml"], "<loc>https://notes.example.com/</loc>\n    <lastmod>2021-01-02</lastmod>")
+	Equal(t, "User-agent: *\nAllow: /\n\nSitemap: https://notes.example.com/sitemap.xml\n", pages["robots.txt"])
+}
+
+func TestSiteRoot(t *testing.T) {
+	Equal(t, "", siteRoot("index.html"))
+	Equal(t, "../", siteRoot("tags/food.html"))
+	Equal(t, "../../../", siteRoot(entrySitePage("food/pizza")))
+}
+
+// sortedKeys returns the keys of the map in alphabetical order.
+func sortedKeys(m map[string]string) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
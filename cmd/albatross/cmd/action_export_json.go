@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+// ActionExportJSONCmd represents the 'export json' action.
+var ActionExportJSONCmd = &cobra.Command{
+	Use:   "json",
+	Short: "export entries as JSON, including their attachments and links",
+	Long: `json serialises matched entries as JSON so that other tools can consume the data in a store.
+
+	$ albatross get -p food export json --pretty
+	[
+	  {
+	    "path": "food/pizza",
+	    "title": "Pizza",
+	    ...
+	  }
+	]
+
+Each entry has these fields:
+
+	path            the path to the entry, like "food/pizza"
+	title           the title of the entry
+	date            the date of the entry, in RFC 3339 format
+	tags            the tags in the entry, like "@?food"
+	metadata        the front matter of the entry
+	contents        the contents of the entry, without the front matter
+	attachments     the files in the entry's folder, relative to it, not including sub-entries
+	outboundLinks   the links in the entry, as objects with the link's "text" and the "path" of the entry it links to,
+	                which is blank if the entry doesn't exist
+	inboundLinks    the paths of the entries which link to the entry
+
+To only output some fields, use --fields:
+
+	$ albatross get -p food export json --fields path,title,tags
+
+By default all the entries are output as a single JSON array. For large stores, --stream outputs one JSON object per line
+as each entry is serialised instead (the JSON Lines format), which tools like 'jq' can read as it's output:
+
+	$ albatross get export json --stream --fields path,inboundLinks | jq -c 'select(.inboundLinks | length == 0)'
+
+Attachments can't be listed if the store is encrypted and only some entries were decrypted using --selective, so they're
+left empty.
+
+Unlike 'albatross get export', which outputs entries exactly as they are stored, the output of this command is meant to
+stay the same between versions.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		collection, _, list := getFromCommand(cmd)
+		refuseSecrets(cmd, list)
+
+		pretty, err := cmd.Flags().GetBool("pretty")
+		checkArg(err)
+
+		stream, err := cmd.Flags().GetBool("stream")
+		checkArg(err)
+
+		fields, err := cmd.Flags().GetStringSlice("fields")
+		checkArg(err)
+
+		if pretty && stream {
+			fmt.Println("--pretty can't be used with --stream, since streamed entries are one per line.")
+			os.Exit(1)
+		}
+
+		selected, err := selectJSONFields(fields)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		// Entries are serialised and written one at a time, so memory use doesn't grow with the number of entries.
+		var encode func(v interface{}) error
+		var array *jsonArrayEncoder
+
+		if stream {
+			encode = json.NewEncoder(os.Stdout).Encode
+		} else {
+			indent := ""
+			if pretty {
+				indent = "  "
+			}
+
+			array = newJSONArrayEncoder(os.Stdout, indent)
+			encode = array.Encode
+		}
+
+		err = list.ForEach(func(entry *entries.Entry) error {
+			serialised, err := serialiseEntry(collection, entry, selected)
+			if err != nil {
+				return fmt.Errorf("couldn't serialise entry %s: %w", entry.Path, err)
+			}
+
+			return encode(serialised)
+		})
+		if err == nil && array != nil {
+			err = array.Close()
+		}
+
+		if err != nil {
+			fmt.Println("Couldn't export entries:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// jsonFields are the fields of an entry exported by 'export json', in the order they're documented.
+var jsonFields = []string{"path", "title", "date", "tags", "metadata", "contents", "attachments", "outboundLinks", "inboundLinks"}
+
+// jsonLink is a link in an entry exported by 'export json'.
+type jsonLink struct {
+	// Text is the text of the link, like "[[Pizza]]" or "{{food/pizza}}".
+	Text string `json:"text"`
+
+	// Path is the path of the entry being linked to. It's blank if the entry doesn't exist.
+	Path string `json:"path"`
+
+	// Store is the name of the store the entry is in, for links to other stores like "{{work:notes/project}}".
+	Store string `json:"store,omitempty"`
+}
+
+// selectJSONFields returns the set of fields to export given the --fields flag. If none are given, all of them are.
+func selectJSONFields(fields []string) (map[string]bool, error) {
+	selected := map[string]bool{}
+
+	if len(fields) == 0 {
+		fields = jsonFields
+	}
+
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+
+		known := false
+		for _, jsonField := range jsonFields {
+			if field == jsonField {
+				known = true
+				break
+			}
+		}
+
+		if !known {
+			return nil, fmt.Errorf("unknown field %q, expected one of: %s", field, strings.Join(jsonFields, ", "))
+		}
+
+		selected[field] = true
+	}
+
+	return selected, nil
+}
+
+// serialiseEntry converts an entry into the object output by 'export json', only including the fields selected.
+// Attachments and inbound links are only worked out if they're selected, since they're slower to find.
+func serialiseEntry(collection *entries.Collection, entry *entries.Entry, selected map[string]bool) (map[string]interface{}, error) {
+	serialised := map[string]interface{}{}
+
+	if selected["path"] {
+		serialised["path"] = entry.Path
+	}
+
+	if selected["title"] {
+		serialised["title"] = entry.Title
+	}
+
+	if selected["date"] {
+		serialised["date"] = entry.Date.Format(time.RFC3339)
+	}
+
+	if selected["tags"] {
+		tags := entry.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+
+		serialised["tags"] = tags
+	}
+
+	if selected["metadata"] {
+		metadata := entry.Metadata
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+		}
+
+		serialised["metadata"] = metadata
+	}
+
+	if selected["contents"] {
+		serialised["contents"] = entry.Contents
+	}
+
+	if selected["attachments"] {
+		attachments, err := store.Attachments(entry.Path)
+		switch err.(type) {
+		case nil:
+		case albatross.ErrStoreEncrypted, albatross.ErrEntryDoesntExist:
+			attachments = []string{}
+		default:
+			return nil, err
+		}
+
+		serialised["attachments"] = attachments
+	}
+
+	if selected["outboundLinks"] {
+		links := []jsonLink{}
+
+		for _, link := range entry.OutboundLinks {
+			serialisedLink := jsonLink{Text: entry.Contents[link.Loc[0]:link.Loc[1]], Store: link.Store}
+
+			if linked := collection.ResolveLink(link); linked != nil {
+				serialisedLink.Path = linked.Path
+			}
+
+			links = append(links, serialisedLink)
+		}
+
+		serialised["outboundLinks"] = links
+	}
+
+	if selected["inboundLinks"] {
+		inbound := []string{}
+		for _, backlink := range collection.Backlinks(entry) {
+			inbound = append(inbound, backlink.Path)
+		}
+
+		serialised["inboundLinks"] = inbound
+	}
+
+	return serialised, nil
+}
+
+func init() {
+	ActionExportCmd.AddCommand(ActionExportJSONCmd)
+
+	ActionExportJSONCmd.Flags().Bool("pretty", false, "indent the JSON output")
+	ActionExportJSONCmd.Flags().Bool("stream", false, "output one JSON object per line as entries are serialised, rather than an array")
+	ActionExportJSONCmd.Flags().StringSlice("fields", []string{}, "only output these fields, separated by commas")
+}
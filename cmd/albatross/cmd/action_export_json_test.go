@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/albatross-org/go-albatross/entries"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestSerialiseEntry(t *testing.T) {
+	collection := entries.NewCollection()
+
+	pizza := &entries.Entry{Path: "food/pizza", Title: "Pizza", Contents: "Pizza."}
+	journal := &entries.Entry{Path: "journal/2020-08-06", Title: "Journal", Contents: "Ate [[Pizza]] and [[Chips]]."}
+	journal.OutboundLinks = []entries.Link{
+		{Parent: journal, Title: "Pizza", Type: entries.LinkTitleNoName, Loc: []int{4, 13}},
+		{Parent: journal, Title: "Chips", Type: entries.LinkTitleNoName, Loc: []int{18, 27}},
+	}
+
+	for _, entry := range []*entries.Entry{pizza, journal} {
+		err := collection.Add(entry)
+		if err != nil {
+			t.Fatalf("not expecting error adding entry: %s", err)
+		}
+	}
+
+	selected, err := selectJSONFields([]string{"path", "outboundLinks", "inboundLinks"})
+	if err != nil {
+		t.Fatalf("not expecting error selecting fields: %s", err)
+	}
+
+	serialised, err := serialiseEntry(collection, journal, selected)
+	NoError(t, err)
+	Equal(t, map[string]interface{}{
+		"path":          "journal/2020-08-06",
+		"outboundLinks": []jsonLink{{Text: "[[Pizza]]", Path: "food/pizza"}, {Text: "[[Chips]]", Path: ""}},
+		"inboundLinks":  []string{},
+	}, serialised)
+
+	serialised, err = serialiseEntry(collection, pizza, selected)
+	NoError(t, err)
+	Equal(t, []string{"journal/2020-08-06"}, serialised["inboundLinks"])
+
+	all, err := selectJSONFields(nil)
+	NoError(t, err)
+	Len(t, all, len(jsonFields), "expecting every field to be selected by default")
+
+	_, err = selectJSONFields([]string{"path", "colour"})
+	Error(t, err, "expecting error for unknown field")
+}
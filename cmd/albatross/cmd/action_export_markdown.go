@@ -0,0 +1,404 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// ActionExportMarkdownCmd represents the 'export markdown' action.
+var ActionExportMarkdownCmd = &cobra.Command{
+	Use:     "markdown",
+	Aliases: []string{"md"},
+	Short:   "output entries as plain Markdown files",
+	Long: `markdown writes the matched entries as plain Markdown files without any Albatross-specific syntax, so that they can
+be used by other tools like Obsidian, static site generators or any Markdown editor.
+
+	$ albatross get -p notes export markdown -o notes
+
+Each entry is written to a file named after the last part of its path, inside a folder for the entry, along with its
+attachments. This mirrors the store, so images and other relative links keep working:
+
+	notes/
+		food/
+			food.md
+			pizza/
+				pizza.md
+				pizza.jpg
+
+Links like {{food/pizza}} and [[Pizza]] become regular relative Markdown links like [Pizza](../pizza/pizza.md), using
+the link's name if it has one. Links to entries which weren't matched, or which don't exist, are replaced by their text.
+
+Front matter is kept, with the title and tags of each entry always filled in. Tags are given without their prefix, so
+"@?food" becomes "food". How tags written in the contents are handled is chosen using --tags:
+
+	hashtag    Tags like "@?food" become "#food", which most tools recognise. This is the default.
+	strip      Tags are removed from the contents, leaving them only in the front matter.
+	keep       Tags are left as they are.
+
+Shortcodes are expanded using the store's templates, like the other exports. To leave out attachments, use
+--exclude-attachments.
+
+Most static site generators, like Hugo, don't know which pages link to each other. To add a "Links to this entry"
+section to the end of each entry, listing the matched entries which link to it, use --show-backlinks:
+
+	$ albatross get -p notes export markdown -o site/content --show-backlinks`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		// Attachments are encrypted along with the entries, so the store has to stay decrypted after the entries are found.
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		_, collection, list := getFromCommand(cmd)
+		refuseSecrets(cmd, list)
+
+		outputDest, err := cmd.Flags().GetString("output")
+		checkArg(err)
+
+		tagMode, err := cmd.Flags().GetString("tags")
+		checkArg(err)
+
+		excludeAttachments, err := cmd.Flags().GetBool("exclude-attachments")
+		checkArg(err)
+
+		showBacklinks, err := cmd.Flags().GetBool("show-backlinks")
+		checkArg(err)
+
+		switch tagMode {
+		case "hashtag", "strip", "keep":
+		default:
+			fmt.Printf("Invalid --tags %q, expected 'hashtag', 'strip' or 'keep'\n", tagMode)
+			os.Exit(1)
+		}
+
+		exportPlainMarkdown(collection, list, outputDest, tagMode, excludeAttachments, showBacklinks, false)
+	},
+}
+
+// exportPlainMarkdown writes each entry in the list as a plain Markdown file to the folder given, along with its
+// attachments unless excludeAttachments is set. If backlinks is set, each file ends with the entries linking to it, see
+// plainBacklinks. If obsidian is set, the files are written for Obsidian, see plainMarkdown.
+func exportPlainMarkdown(collection *entries.Collection, list entries.List, outputDest, tagMode string, excludeAttachments, backlinks, obsidian bool) {
+	if _, err := os.Stat(outputDest); !os.IsNotExist(err) {
+		fmt.Printf("Cannot output entries to %s:\n", outputDest)
+		fmt.Println("Directory/file already exists.")
+		os.Exit(1)
+	}
+
+	shortcodes, err := store.Shortcodes()
+	if err != nil {
+		fmt.Println("Couldn't load shortcodes:")
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// Each entry is written as soon as it's converted, so that only one is kept in memory at a time.
+	err = list.ForEach(func(entry *entries.Entry) error {
+		contents, err := plainMarkdown(collection, shortcodes, entry, tagMode, obsidian)
+		if err != nil {
+			return fmt.Errorf("couldn't convert entry %s: %w", entry.Path, err)
+		}
+
+		if backlinks {
+			if section := plainBacklinks(collection, entry, obsidian); section != "" {
+				contents = strings.TrimRight(contents, "\n") + "\n\n" + section
+			}
+		}
+
+		err = writeFile(outputDest, archiveFile{name: markdownFile(entry.Path), data: []byte(contents)})
+		if err != nil {
+			return err
+		}
+
+		if excludeAttachments {
+			return nil
+		}
+
+		attachments, err := store.Attachments(entry.Path)
+		if err != nil {
+			return fmt.Errorf("couldn't get attachments for %s: %w", entry.Path, err)
+		}
+
+		for _, attachment := range attachments {
+			err = writeFile(outputDest, archiveFile{
+				name:   path.Join(entry.Path, attachment),
+				source: filepath.Join(store.Path, "entries", filepath.FromSlash(entry.Path), filepath.FromSlash(attachment)),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		fmt.Println("Couldn't write the entries:")
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if obsidian {
+		// Obsidian shows when notes were last modified, so the files are given the dates of their entries.
+		err = list.ForEach(func(entry *entries.Entry) error {
+			if entry.Date.IsZero() {
+				return nil
+			}
+
+			err := os.Chtimes(filepath.Join(outputDest, filepath.FromSlash(markdownFile(entry.Path))), entry.Date, entry.Date)
+			if err != nil {
+				return fmt.Errorf("couldn't set the date of %s: %w", entry.Path, err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("Written %d entries to %s\n", list.Len(), outputDest)
+}
+
+// markdownFile returns the path of the file an entry is written to by 'export markdown', like "food/pizza/pizza.md".
+func markdownFile(entryPath string) string {
+	return path.Join(entryPath, path.Base(entryPath)+".md")
+}
+
+// reInlineTag matches tags written in the contents of an entry, capturing the tag without its prefix.
+var reInlineTag = regexp.MustCompile(`@[!?]([a-zA-Z0-9_|-]+)`)
+
+// plainMarkdown returns the entry as a plain Markdown file, with its front matter, links rewritten to point to the files
+// of other entries in the collection and tags handled according to tagMode. If obsidian is set, links are written as
+// Obsidian wikilinks and the front matter always has a date and the title as an alias, so [[Title]] links work.
+func plainMarkdown(collection *entries.Collection, shortcodes albatross.Shortcodes, entry *entries.Entry, tagMode string, obsidian bool) (string, error) {
+	contents, err := collection.Transclude(entry, func(e *entries.Entry) (string, error) {
+		// Links in embedded entries are written relative to the file they end up in.
+		from := *e
+		from.Path = entry.Path
+
+		expanded := *e
+		expanded.Contents = plainLinks(collection, &from, obsidian)
+
+		return expandContents(collection, shortcodes, &expanded)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	switch tagMode {
+	case "hashtag":
+		contents = reInlineTag.ReplaceAllStringFunc(contents, func(tag string) string {
+			return "#" + plainTag(tag)
+		})
+	case "strip":
+		contents = reInlineTag.ReplaceAllString(contents, "")
+	}
+
+	frontMatter, err := plainFrontMatter(entry, obsidian)
+	if err != nil {
+		return "", err
+	}
+
+	return "---\n" + frontMatter + "---\n\n" + strings.TrimLeft(contents, "\n"), nil
+}
+
+// plainLinks returns the contents of the entry with its links replaced by Markdown links to the files of the entries
+// they point to, or by their text if the entry isn't in the collection. If obsidian is set, wikilinks are used instead.
+// Embeds are left for Collection.Transclude, unless obsidian is set, in which case they're written as Obsidian embeds.
+func plainLinks(collection *entries.Collection, entry *entries.Entry, obsidian bool) string {
+	contents := entry.Contents
+
+	links := make([]entries.Link, len(entry.OutboundLinks))
+	copy(links, entry.OutboundLinks)
+
+	// Links are replaced from the end of the entry backwards so that the locations of the ones before don't change.
+	sort.Slice(links, func(i, j int) bool { return links[i].Loc[0] > links[j].Loc[0] })
+
+	// Different kinds of link can overlap, in which case only the last one is replaced.
+	replacedFrom := len(contents)
+
+	for _, link := range links {
+		if link.Loc[1] > replacedFrom {
+			continue
+		}
+
+		linked := collection.ResolveLink(link)
+
+		// Embeds are left to be transcluded, apart from in Obsidian which has its own syntax for them.
+		if link.Embed {
+			if obsidian && linked != nil {
+				target := strings.TrimSuffix(markdownFile(linked.Path), ".md")
+				if link.Section != "" {
+					target += "#" + link.Section
+				}
+
+				contents = contents[:link.Loc[0]] + "![[" + target + "]]" + contents[link.Loc[1]:]
+				replacedFrom = link.Loc[0]
+			}
+
+			continue
+		}
+
+		text := link.Name
+		if text == "" && linked != nil {
+			text = linked.Title
+		} else if text == "" && link.Title != "" {
+			text = link.Title
+		} else if text == "" {
+			text = link.Path
+		}
+
+		replacement := text
+		if link.Store != "" {
+			if url, ok := crossStoreURL(link); ok {
+				replacement = "[" + linkTextEscaper.Replace(text) + "](" + url + ")"
+			}
+		} else if linked != nil && obsidian {
+			replacement = "[[" + strings.TrimSuffix(markdownFile(linked.Path), ".md") + "|" + wikiLinkTextEscaper.Replace(text) + "]]"
+		} else if linked != nil {
+			rel := relativeMarkdownPath(markdownFile(entry.Path), markdownFile(linked.Path))
+			replacement = "[" + linkTextEscaper.Replace(text) + "](" + (&url.URL{Path: rel}).String() + ")"
+		}
+
+		contents = contents[:link.Loc[0]] + replacement + contents[link.Loc[1]:]
+		replacedFrom = link.Loc[0]
+	}
+
+	return contents
+}
+
+// plainBacklinks returns a "Links to this entry" section listing the entries in the collection which link to the entry,
+// with links to their files, to go at the end of the entry's file. If nothing links to the entry, it returns "".
+func plainBacklinks(collection *entries.Collection, entry *entries.Entry, obsidian bool) string {
+	var section strings.Builder
+
+	seen := map[string]bool{}
+
+	for _, link := range collection.FindLinksTo(entry) {
+		from := link.Parent
+
+		// Title links only point here if this is the entry the title resolves to.
+		if from == entry || seen[from.Path] || collection.ResolveLink(link) != entry {
+			continue
+		}
+
+		seen[from.Path] = true
+
+		if obsidian {
+			fmt.Fprintf(&section, "- [[%s|%s]]\n", strings.TrimSuffix(markdownFile(from.Path), ".md"), wikiLinkTextEscaper.Replace(from.Title))
+			continue
+		}
+
+		rel := relativeMarkdownPath(markdownFile(entry.Path), markdownFile(from.Path))
+		fmt.Fprintf(&section, "- [%s](%s)\n", linkTextEscaper.Replace(from.Title), (&url.URL{Path: rel}).String())
+	}
+
+	if section.Len() == 0 {
+		return ""
+	}
+
+	return "## Links to this entry\n\n" + section.String()
+}
+
+// linkTextEscaper escapes the brackets in the text of a Markdown link.
+var linkTextEscaper = strings.NewReplacer("[", `\[`, "]", `\]`)
+
+// wikiLinkTextEscaper replaces the characters which can't be used in the text of an Obsidian wikilink.
+var wikiLinkTextEscaper = strings.NewReplacer("[", "(", "]", ")", "|", "-")
+
+// relativeMarkdownPath returns the path of the file to from the folder containing the file from, both given relative to
+// the same folder, like "../pizza/pizza.md".
+func relativeMarkdownPath(from, to string) string {
+	fromParts := strings.Split(path.Dir(from), "/")
+	toParts := strings.Split(to, "/")
+
+	i := 0
+	for i < len(fromParts) && i < len(toParts)-1 && fromParts[i] == toParts[i] {
+		i++
+	}
+
+	return strings.Repeat("../", len(fromParts)-i) + strings.Join(toParts[i:], "/")
+}
+
+// plainTag returns a tag without its prefix, and with characters other tools don't allow in tags replaced by dashes, so
+// "@?further|maths" becomes "further-maths".
+func plainTag(tag string) string {
+	tag = strings.TrimPrefix(strings.TrimPrefix(tag, "@?"), "@!")
+	return strings.ReplaceAll(tag, "|", "-")
+}
+
+// plainFrontMatter returns the YAML front matter for the entry, which is its metadata with the title and tags filled in
+// and the prefixes removed from the tags. If obsidian is set, the date and an alias for the title are filled in too.
+func plainFrontMatter(entry *entries.Entry, obsidian bool) (string, error) {
+	metadata := map[string]interface{}{}
+	for key, value := range entry.Metadata {
+		metadata[key] = value
+	}
+
+	metadata["title"] = entry.Title
+
+	if obsidian {
+		if _, ok := metadata["date"]; !ok && !entry.Date.IsZero() {
+			metadata["date"] = entry.Date.Format("2006-01-02 15:04")
+		}
+
+		aliases := []interface{}{entry.Title}
+		for _, alias := range entry.Aliases() {
+			if alias != entry.Title {
+				aliases = append(aliases, alias)
+			}
+		}
+
+		metadata["aliases"] = aliases
+	}
+
+	if len(entry.Tags) != 0 {
+		tags := []string{}
+		seen := map[string]bool{}
+
+		for _, tag := range sortedTags(entry) {
+			tag = plainTag(tag)
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+
+		metadata["tags"] = tags
+	}
+
+	out, err := yaml.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("couldn't create front matter for %s: %w", entry.Path, err)
+	}
+
+	return string(out), nil
+}
+
+func init() {
+	ActionExportCmd.AddCommand(ActionExportMarkdownCmd)
+
+	ActionExportMarkdownCmd.Flags().StringP("output", "o", "markdown", "folder to output the entries to, which mustn't already exist")
+	ActionExportMarkdownCmd.Flags().String("tags", "hashtag", "how to handle tags in the contents: 'hashtag', 'strip' or 'keep'")
+	ActionExportMarkdownCmd.Flags().Bool("exclude-attachments", false, "don't copy any attachments, only write entries")
+	ActionExportMarkdownCmd.Flags().Bool("show-backlinks", false, "add a section listing the entries which link to each entry")
+}
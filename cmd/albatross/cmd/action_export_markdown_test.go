@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestPlainMarkdown(t *testing.T) {
+	parser, err := entries.NewParser("2006-01-02 15:04", "@!", "@?")
+	if err != nil {
+		t.Fatalf("not expecting error creating parser: %s", err)
+	}
+
+	pizza, err := parser.Parse("food/pizza", "---\ntitle: Pizza [Italian]\n---\n\nPizza is great.")
+	if err != nil {
+		t.Fatalf("not expecting error parsing entry: %s", err)
+	}
+
+	pizza.Path = "food/pizza"
+
+	journal, err := parser.Parse("journal/2020-09-01", `---
+title: Journal
+date: 2020-09-01 12:00
+---
+
+Ate {{food/pizza}}, {{food/pizza}(a slice)}, [[Chips]] and {{food/chips}} @?food @?further|maths.`)
+	if err != nil {
+		t.Fatalf("not expecting error parsing entry: %s", err)
+	}
+
+	journal.Path = "journal/2020-09-01"
+
+	collection := entries.NewCollection()
+
+	err = collection.AddMany(pizza, journal)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	out, err := plainMarkdown(collection, albatross.Shortcodes{}, journal, "hashtag", false)
+	NoError(t, err)
+	Equal(t, `---
+date: 2020-09-01 12:00
+tags:
+- food
+- further-maths
+title: Journal
+---
+
+Ate [Pizza \[Italian\]](../../food/pizza/pizza.md), [a slice](../../food/pizza/pizza.md), Chips and food/chips #food #further-maths.`, out)
+
+	out, err = plainMarkdown(collection, albatross.Shortcodes{}, journal, "strip", false)
+	NoError(t, err)
+	Contains(t, out, "and food/chips  .")
+
+	out, err = plainMarkdown(collection, albatross.Shortcodes{}, journal, "keep", false)
+	NoError(t, err)
+	Contains(t, out, "and food/chips @?food @?further|maths.")
+}
+
+func TestRelativeMarkdownPath(t *testing.T) {
+	Equal(t, "../pizza/pizza.md", relativeMarkdownPath("food/chips/chips.md", "food/pizza/pizza.md"))
+	Equal(t, "pizza/pizza.md", relativeMarkdownPath("food/food.md", "food/pizza/pizza.md"))
+	Equal(t, "../food.md", relativeMarkdownPath("food/pizza/pizza.md", "food/food.md"))
+	Equal(t, "../../journal/2020/2020.md", relativeMarkdownPath("food/pizza/pizza.md", "journal/2020/2020.md"))
+}
+
+func TestPlainMarkdownObsidian(t *testing.T) {
+	parser, err := entries.NewParser("2006-01-02 15:04", "@!", "@?")
+	if err != nil {
+		t.Fatalf("not expecting error creating parser: %s", err)
+	}
+
+	pizza, err := parser.Parse("food/pizza", "---\ntitle: Pizza [Italian]\naliases: [Margherita]\n---\n\nPizza is great.")
+	if err != nil {
+		t.Fatalf("not expecting error parsing entry: %s", err)
+	}
+
+	pizza.Path = "food/pizza"
+	pizza.Date = time.Date(2020, 8, 6, 18, 24, 0, 0, time.UTC)
+
+	journal, err := parser.Parse("journal/2020-09-01", "Ate {{food/pizza}} and {{food/pizza}(a | slice)} @?food.")
+	if err != nil {
+		t.Fatalf("not expecting error parsing entry: %s", err)
+	}
+
+	journal.Path = "journal/2020-09-01"
+
+	collection := entries.NewCollection()
+
+	err = collection.AddMany(pizza, journal)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	out, err := plainMarkdown(collection, albatross.Shortcodes{}, journal, "hashtag", true)
+	NoError(t, err)
+	Contains(t, out, "Ate [[food/pizza/pizza|Pizza (Italian)]] and [[food/pizza/pizza|a - slice]] #food.")
+
+	out, err = plainMarkdown(collection, albatross.Shortcodes{}, pizza, "hashtag", true)
+	NoError(t, err)
+	Equal(t, "---\naliases:\n- Pizza [Italian]\n- Margherita\ndate: 2020-08-06 18:24\ntitle: Pizza [Italian]\n---\n\nPizza is great.", out)
+}
+
+func TestPlainMarkdownCrossStore(t *testing.T) {
+	parser, err := entries.NewParser("2006-01-02 15:04", "@!", "@?")
+	if err != nil {
+		t.Fatalf("not expecting error creating parser: %s", err)
+	}
+
+	journal, err := parser.Parse("journal/2020-09-01", "See {{work:notes/project}(the plan)} and {{school:physics}}.")
+	if err != nil {
+		t.Fatalf("not expecting error parsing entry: %s", err)
+	}
+
+	journal.Path = "journal/2020-09-01"
+
+	collection := entries.NewCollection()
+
+	err = collection.Add(journal)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	defer func(old *albatross.Registry) { registry = old }(registry)
+
+	registry = albatross.NewRegistry()
+	registry.Register("work", "/does/not/exist", "https://wiki.example.com/{path}.html")
+	registry.Register("school", "/does/not/exist", "")
+	collection.SetRegistry(registry)
+
+	out, err := plainMarkdown(collection, albatross.Shortcodes{}, journal, "keep", false)
+	NoError(t, err)
+	Contains(t, out, "See [the plan](https://wiki.example.com/notes/project.html) and physics.", "expecting links to stores without a URL to be left as text")
+}
+
+func TestPlainBacklinks(t *testing.T) {
+	parser, err := entries.NewParser("2006-01-02 15:04", "@!", "@?")
+	if err != nil {
+		t.Fatalf("not expecting error creating parser: %s", err)
+	}
+
+	parse := func(path, content string) *entries.Entry {
+		entry, err := parser.Parse(path, content)
+		if err != nil {
+			t.Fatalf("not expecting error parsing entry: %s", err)
+		}
+
+		entry.Path = path
+		return entry
+	}
+
+	pizza := parse("food/pizza", "---\ntitle: Pizza\n---\n\nSee {{food/pizza}}.")
+	journal := parse("journal/2020-09-01", "---\ntitle: Journal [1]\n---\n\nAte {{food/pizza}} and [[Pizza]].")
+	chips := parse("food/chips", "---\ntitle: Chips\n---\n\nNot as good as [[Pizza]].")
+	other := parse("moods/hunger", "---\ntitle: Hunger\n---\n\nHungry.")
+
+	collection := entries.NewCollection()
+
+	err = collection.AddMany(pizza, journal, chips, other)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	Equal(t, "## Links to this entry\n\n- [Chips](../chips/chips.md)\n- [Journal \\[1\\]](../../journal/2020-09-01/2020-09-01.md)\n",
+		plainBacklinks(collection, pizza, false), "expecting each entry once, without the entry itself")
+
+	Equal(t, "## Links to this entry\n\n- [[food/chips/chips|Chips]]\n- [[journal/2020-09-01/2020-09-01|Journal (1)]]\n",
+		plainBacklinks(collection, pizza, true))
+
+	Equal(t, "", plainBacklinks(collection, other, false))
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ActionExportObsidianCmd represents the 'export obsidian' action.
+var ActionExportObsidianCmd = &cobra.Command{
+	Use:   "obsidian",
+	Short: "output entries as an Obsidian vault",
+	Long: `obsidian writes the matched entries as a folder which can be opened as a vault in Obsidian.
+
+	$ albatross get -p notes export obsidian -o vault
+
+Entries are written in the same layout as 'export markdown', with each entry in a file named after the last part of its
+path, inside a folder for the entry along with its attachments:
+
+	vault/
+		food/
+			food.md
+			pizza/
+				pizza.md
+				pizza.jpg
+
+Links like {{food/pizza}} and [[Pizza]] become wikilinks like [[food/pizza/pizza|Pizza]], which Obsidian keeps up to
+date when notes are moved. Links to entries which weren't matched, or which don't exist, are replaced by their text.
+
+Tags like "@?food" become "#food" and are listed in the front matter without their prefix. The front matter also always
+has the date of the entry and its title as an alias, so that notes can be found and linked to by their title. Files are
+given the date of their entry as when they were last modified.
+
+Vaults exported like this can be imported again using 'albatross import obsidian'. To leave out attachments, use
+--exclude-attachments.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		// Attachments are encrypted along with the entries, so the store has to stay decrypted after the entries are found.
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		_, collection, list := getFromCommand(cmd)
+		refuseSecrets(cmd, list)
+
+		outputDest, err := cmd.Flags().GetString("output")
+		checkArg(err)
+
+		excludeAttachments, err := cmd.Flags().GetBool("exclude-attachments")
+		checkArg(err)
+
+		exportPlainMarkdown(collection, list, outputDest, "hashtag", excludeAttachments, false, true)
+	},
+}
+
+func init() {
+	ActionExportCmd.AddCommand(ActionExportObsidianCmd)
+
+	ActionExportObsidianCmd.Flags().StringP("output", "o", "vault", "folder to output the vault to, which mustn't already exist")
+	ActionExportObsidianCmd.Flags().Bool("exclude-attachments", false, "don't copy any attachments, only write entries")
+}
@@ -0,0 +1,384 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/yuin/goldmark"
+)
+
+// defaultPDFCommand is the command used to convert the HTML document to a PDF if no other is configured.
+const defaultPDFCommand = "wkhtmltopdf --quiet --enable-local-file-access --enable-internal-links {input} {output}"
+
+// pdfStylesheet is the CSS for the HTML document converted into a PDF. Each entry starts on a new page.
+const pdfStylesheet = `body {
+	font-family: Georgia, "Times New Roman", serif;
+	font-size: 11pt;
+	line-height: 1.5;
+	color: #111;
+}
+
+section.entry {
+	page-break-before: always;
+}
+
+nav.toc ol {
+	padding-left: 1.5em;
+}
+
+.meta {
+	color: #555;
+	font-size: 0.9em;
+}
+
+img {
+	max-width: 100%;
+}
+
+pre {
+	white-space: pre-wrap;
+	padding: 0.5em;
+	background: #f4f4f4;
+}
+`
+
+// ActionExportPDFCmd represents the 'export pdf' action.
+var ActionExportPDFCmd = &cobra.Command{
+	Use:   "pdf",
+	Short: "output matched entries as a single PDF",
+	Long: `pdf renders matched entries into a single PDF, ready for printing or sharing.
+
+	$ albatross get -p school/physics --sort 'alpha' export pdf -o physics.pdf
+
+The PDF starts with a title page and a table of contents, followed by each entry on a new page in the order given by
+--sort, or in order of weight if the entries have one, like 'export epub'. Links between the matched entries link to
+the entry inside the PDF, and links to entries which weren't matched are left as text.
+
+The title is 'Albatross YYYY-MM-DD' by default and can be specified using --book-title, and the author using
+--book-author.
+
+Renderers
+---------
+
+The entries are rendered into one HTML document, which is converted into a PDF by a command. By default this is
+wkhtmltopdf (https://wkhtmltopdf.org), which has to be installed. A different command can be given using --pdf-command,
+or set in the config as 'pdf.command'. If the command contains '{input}' and '{output}', they're replaced by the paths
+of the HTML document and the PDF to write, otherwise the HTML is given on stdin and the PDF read from stdout:
+
+	$ albatross get -p notes export pdf -o notes.pdf --pdf-command "weasyprint {input} {output}"
+	$ albatross get -p notes export pdf -o notes.pdf --pdf-command "chromium --headless --print-to-pdf={output} {input}"
+
+To see the HTML document, or to convert it some other way, use --html to write it instead of a PDF:
+
+	$ albatross get -p notes export pdf --html -o notes.html
+
+The entries are rendered in the same way as 'export epub', including diagrams, highlighting, shortcodes and included
+entries. Images and other attachments are linked using their full path, so the renderer has to be able to read files
+from the store.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		// Attachments are encrypted along with the entries, so the store has to stay decrypted until the PDF is rendered.
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		all, collection, list := getFromCommand(cmd)
+		refuseSecrets(cmd, list)
+
+		outputDest, err := cmd.Flags().GetString("output")
+		checkArg(err)
+
+		title, err := cmd.Flags().GetString("book-title")
+		checkArg(err)
+
+		author, err := cmd.Flags().GetString("book-author")
+		checkArg(err)
+
+		pdfCommand, err := cmd.Flags().GetString("pdf-command")
+		checkArg(err)
+
+		outputHTML, err := cmd.Flags().GetBool("html")
+		checkArg(err)
+
+		if !cmd.Flags().Changed("pdf-command") && viper.IsSet("pdf.command") {
+			pdfCommand = viper.GetString("pdf.command")
+		}
+
+		if outputDest == "" {
+			fmt.Println("Please specify an output location using the -o flag.")
+			fmt.Println("For example: albatross get export pdf -o notes.pdf")
+			os.Exit(1)
+		}
+
+		if title == "" {
+			title = "Albatross " + time.Now().Format("2006-01-02")
+		}
+
+		bib, err := store.Bibliography()
+		if err != nil {
+			fmt.Println("Couldn't load bibliography:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		shortcodes, err := store.Shortcodes()
+		if err != nil {
+			fmt.Println("Couldn't load shortcodes:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		doc := pdfDocument{
+			title:       title,
+			author:      author,
+			md:          newExportMarkdown(),
+			bib:         bib,
+			shortcodes:  shortcodes,
+			entriesPath: filepath.Join(store.Path, "entries"),
+			jobs:        exportJobs(cmd),
+		}
+
+		document, err := doc.build(all, collection, list)
+		if err != nil {
+			fmt.Println("Error when rendering the entries:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		output := []byte(document)
+
+		if !outputHTML {
+			output, err = renderPDF(pdfCommand, document)
+			if err != nil {
+				fmt.Println("Error when converting the entries to a PDF:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		err = ioutil.WriteFile(outputDest, output, 0644)
+		if err != nil {
+			fmt.Println("Couldn't write to output destination:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Written %d entries to %s\n", list.Len(), outputDest)
+	},
+}
+
+// pdfDocument renders entries into the single HTML document which is converted into a PDF.
+type pdfDocument struct {
+	title, author string
+
+	md         goldmark.Markdown
+	bib        entries.Bibliography
+	shortcodes albatross.Shortcodes
+
+	// entriesPath is the path of the store's entries folder, used to give attachments a full path.
+	entriesPath string
+
+	// jobs is the number of entries to render at once.
+	jobs int
+}
+
+// build returns the HTML document for the entries in the list. Links between entries only point to the entries in
+// collection, and included entries come from all.
+func (d *pdfDocument) build(all, collection *entries.Collection, list entries.List) (string, error) {
+	// Like EPUBs, entries with weights are put in order of their weight, with ties broken by the order they were given in.
+	if list.Weighted() {
+		list = list.Sort(entries.SortWeight)
+	}
+
+	slice := list.Slice()
+
+	anchors := map[string]string{}
+	for i, entry := range slice {
+		anchors[entry.Path] = fmt.Sprintf("entry-%d", i+1)
+	}
+
+	sections := make([]string, len(slice))
+
+	err := renderEach(d.jobs, len(slice), func(i int) error {
+		var err error
+		sections[i], err = d.section(all, collection, slice[i], anchors)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+
+	out.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&out, "<title>%s</title>\n", html.EscapeString(d.title))
+	if d.author != "" {
+		fmt.Fprintf(&out, "<meta name=\"author\" content=\"%s\">\n", html.EscapeString(d.author))
+	}
+	fmt.Fprintf(&out, "<style>\n%s</style>\n</head>\n<body>\n", pdfStylesheet)
+
+	fmt.Fprintf(&out, "<h1>%s</h1>\n", html.EscapeString(d.title))
+	if d.author != "" {
+		fmt.Fprintf(&out, "<p class=\"meta\">%s</p>\n", html.EscapeString(d.author))
+	}
+
+	out.WriteString("<nav class=\"toc\">\n<h2>Contents</h2>\n<ol>\n")
+	for _, entry := range slice {
+		fmt.Fprintf(&out, "<li><a href=\"#%s\">%s</a></li>\n", anchors[entry.Path], html.EscapeString(entry.Title))
+	}
+	out.WriteString("</ol>\n</nav>\n")
+
+	for _, section := range sections {
+		out.WriteString(section)
+	}
+
+	out.WriteString("</body>\n</html>\n")
+
+	return out.String(), nil
+}
+
+// section renders an entry as a section of the document, with links to the other entries pointing to their anchors.
+func (d *pdfDocument) section(all, collection *entries.Collection, entry *entries.Entry, anchors map[string]string) (string, error) {
+	contents, _, err := exportContents(all, d.shortcodes, entry)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := entryHTML(d.md, collection, d.bib, entry, contents, func(linked *entries.Entry) string {
+		return "#" + anchors[linked.Path]
+	})
+	if err != nil {
+		return "", err
+	}
+
+	body = absoluteSources(body, filepath.Join(d.entriesPath, filepath.FromSlash(entry.Path)))
+
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "<section class=\"entry\" id=\"%s\">\n", anchors[entry.Path])
+	fmt.Fprintf(&out, "<h1>%s</h1>\n", html.EscapeString(entry.Title))
+
+	meta := []string{}
+	if !entry.Date.IsZero() {
+		meta = append(meta, entry.Date.Format("Monday 2 January 2006, 15:04"))
+	}
+	meta = append(meta, sortedTags(entry)...)
+
+	if len(meta) != 0 {
+		fmt.Fprintf(&out, "<p class=\"meta\">%s</p>\n", html.EscapeString(strings.Join(meta, " · ")))
+	}
+
+	out.WriteString(body)
+	out.WriteString("</section>\n")
+
+	return out.String(), nil
+}
+
+// sourceAttr matches the src attribute of tags like <img>.
+var sourceAttr = regexp.MustCompile(`src="([^"]*)"`)
+
+// absoluteSources rewrites relative src attributes in the HTML, like the ones for images attached to an entry, to be
+// file:// URLs relative to dir instead.
+func absoluteSources(body, dir string) string {
+	return sourceAttr.ReplaceAllStringFunc(body, func(attr string) string {
+		src := html.UnescapeString(sourceAttr.FindStringSubmatch(attr)[1])
+		if src == "" || strings.HasPrefix(src, "/") || strings.HasPrefix(src, "#") || strings.Contains(src, ":") {
+			return attr
+		}
+
+		abs := filepath.ToSlash(filepath.Join(dir, filepath.FromSlash(src)))
+		if !strings.HasPrefix(abs, "/") {
+			abs = "/" + abs // Windows paths like "C:/..."
+		}
+
+		return `src="file://` + html.EscapeString(abs) + `"`
+	})
+}
+
+// renderPDF converts the HTML document into a PDF by running a command. If the command contains "{input}" and
+// "{output}", they're replaced with the paths of temporary files holding the document and where the PDF should be
+// written. Otherwise, the document is given on stdin and the PDF is read from stdout.
+func renderPDF(command, document string) ([]byte, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no PDF command given")
+	}
+
+	_, err := exec.LookPath(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w, see 'albatross get export pdf --help' for how to use a different command", err)
+	}
+
+	usesFiles := strings.Contains(command, "{input}") || strings.Contains(command, "{output}")
+	var input, output string
+
+	if usesFiles {
+		dir, err := ioutil.TempDir("", "albatross-pdf")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+
+		// Renderers often decide how to read the input from its extension, so it has to end with ".html".
+		input, output = filepath.Join(dir, "document.html"), filepath.Join(dir, "document.pdf")
+
+		err = ioutil.WriteFile(input, []byte(document), 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, arg := range args {
+			args[i] = strings.NewReplacer("{input}", input, "{output}", output).Replace(arg)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	c := exec.Command(args[0], args[1:]...)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	if !usesFiles {
+		c.Stdin = strings.NewReader(document)
+	}
+
+	err = c.Run()
+	if err != nil {
+		return nil, fmt.Errorf("running %q: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	if !usesFiles {
+		return stdout.Bytes(), nil
+	}
+
+	return ioutil.ReadFile(output)
+}
+
+func init() {
+	ActionExportCmd.AddCommand(ActionExportPDFCmd)
+
+	ActionExportPDFCmd.Flags().StringP("output", "o", "", "output location of the PDF")
+	ActionExportPDFCmd.Flags().String("book-title", "", "set the title of the PDF, by default a timestamp")
+	ActionExportPDFCmd.Flags().String("book-author", "", "set the author of the PDF")
+	ActionExportPDFCmd.Flags().String("pdf-command", defaultPDFCommand, "command used to convert HTML to PDF, '{input}' and '{output}' are replaced by their paths")
+	ActionExportPDFCmd.Flags().Bool("html", false, "output the HTML document instead of converting it to a PDF")
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/albatross-org/go-albatross/pkg/markdown"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestPDFDocumentBuild(t *testing.T) {
+	collection := entries.NewCollection()
+
+	pizza := &entries.Entry{
+		Path:     "food/pizza",
+		Title:    "Pizza",
+		Contents: "Pizza is *great*.\n\n![A pizza](pizza.jpg)",
+		Date:     time.Date(2020, 8, 6, 18, 24, 0, 0, time.UTC),
+		Tags:     []string{"@?food"},
+	}
+
+	journal := &entries.Entry{
+		Path:     "journal/2020-09-01",
+		Title:    "Journal",
+		Contents: "Ate {{food/pizza}} and {{food/chips}}.",
+		Date:     time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC),
+	}
+	journal.OutboundLinks = []entries.Link{
+		{Parent: journal, Path: "food/pizza", Type: entries.LinkPathNoName, Loc: []int{4, 18}},
+		{Parent: journal, Path: "food/chips", Type: entries.LinkPathNoName, Loc: []int{23, 37}},
+	}
+
+	err := collection.AddMany(pizza, journal)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	doc := &pdfDocument{title: "Food & Things", md: markdown.New(), entriesPath: "/store/entries", jobs: 2}
+
+	document, err := doc.build(collection, collection, collection.List().Sort(entries.SortPath))
+	if err != nil {
+		t.Fatalf("not expecting error building document: %s", err)
+	}
+
+	Contains(t, document, "<title>Food &amp; Things</title>")
+	Contains(t, document, "<li><a href=\"#entry-1\">Pizza</a></li>\n<li><a href=\"#entry-2\">Journal</a></li>", "expecting a table of contents in order")
+	Contains(t, document, `<section class="entry" id="entry-1">`)
+	Contains(t, document, "<em>great</em>")
+	Contains(t, document, `src="file:///store/entries/food/pizza/pizza.jpg"`, "expecting attachments to use their full path")
+	Contains(t, document, `<a href="#entry-1">{{food/pizza}}</a> and {{food/chips}}`, "expecting links to matched entries only")
+	Less(t, strings.Index(document, `id="entry-1"`), strings.Index(document, `id="entry-2"`))
+}
+
+func TestAbsoluteSources(t *testing.T) {
+	Equal(t, `<img src="file:///store/a/b.png">`, absoluteSources(`<img src="b.png">`, "/store/a"))
+	Equal(t, `<img src="file:///store/a/photos/b%20c.png">`, absoluteSources(`<img src="photos/b%20c.png">`, "/store/a"))
+	Equal(t, `<img src="https://example.com/b.png">`, absoluteSources(`<img src="https://example.com/b.png">`, "/store/a"))
+	Equal(t, `<img src="/b.png">`, absoluteSources(`<img src="/b.png">`, "/store/a"))
+}
+
+func TestRenderPDF(t *testing.T) {
+	out, err := renderPDF("cat", "<p>Hello</p>")
+	NoError(t, err)
+	Equal(t, "<p>Hello</p>", string(out), "expecting the document on stdin")
+
+	out, err = renderPDF("cp {input} {output}", "<p>Hello</p>")
+	NoError(t, err)
+	Equal(t, "<p>Hello</p>", string(out), "expecting the document to be given as a file")
+
+	_, err = renderPDF("albatross-no-such-renderer {input} {output}", "<p>Hello</p>")
+	Error(t, err)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/plus3it/gorecurcopy"
 	"github.com/spf13/cobra"
@@ -74,9 +76,31 @@ We should be left with something that looks like this:
       message.txt
     attachments-and-secret/
 	  some-attachment.jpg    < This is fine.
-	  `,
+
+Attachments
+-----------
+
+By default, all the attachments of the matched entries are copied. To export a lightweight, text-only subset of the
+store, for example to put on a phone, use --exclude-attachments:
+
+	$ albatross get -p school/ export store --exclude-attachments
+
+Attachments larger than a certain size can be left out using --attachments-max-size, which takes a size like "500KB",
+"10MB" or "1GB":
+
+	$ albatross get -p school/ export store --attachments-max-size 10MB
+
+Attachments can also be filtered by name using globs. --attachments-include only copies attachments matching at least
+one of the globs, and --attachments-exclude leaves out attachments matching any of them. Globs are matched against both
+the name of the attachment and its path within the entry's folder, such as 'photos/hello.jpg':
+
+	$ albatross get -p school/ export store --attachments-include '*.pdf,*.png'
+	$ albatross get -p school/ export store --attachments-exclude '*.mp4,recordings/*'
+
+The 'entry.md' files are always copied.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		_, _, list := getFromCommand(cmd)
+		refuseSecrets(cmd, list)
 
 		outputDest, err := cmd.Flags().GetString("output")
 		checkArg(err)
@@ -104,6 +128,35 @@ We should be left with something that looks like this:
 			os.Exit(1)
 		}
 
+		filter := attachmentFilter{}
+
+		filter.exclude, err = cmd.Flags().GetBool("exclude-attachments")
+		checkArg(err)
+
+		maxSize, err := cmd.Flags().GetString("attachments-max-size")
+		checkArg(err)
+
+		filter.include, err = cmd.Flags().GetStringSlice("attachments-include")
+		checkArg(err)
+
+		filter.excludeGlobs, err = cmd.Flags().GetStringSlice("attachments-exclude")
+		checkArg(err)
+
+		if maxSize != "" {
+			filter.maxSize, err = parseSize(maxSize)
+			if err != nil {
+				fmt.Printf("Invalid --attachments-max-size %q: %s\n", maxSize, err)
+				os.Exit(1)
+			}
+		}
+
+		for _, glob := range append(filter.include, filter.excludeGlobs...) {
+			if _, err := filepath.Match(glob, ""); err != nil {
+				fmt.Printf("Invalid attachment glob %q: %s\n", glob, err)
+				os.Exit(1)
+			}
+		}
+
 		fmt.Printf("Outputting store to folder: %s\n", outputDest)
 
 		os.Mkdir(outputDest, 0755)
@@ -154,7 +207,17 @@ We should be left with something that looks like this:
 				//
 				// The function copyFolderWithoutEntries handles this.
 				if fi.IsDir() {
-					copyFolderWithoutEntries(filepath.Join(origPath, fi.Name()), filepath.Join(destPath, fi.Name()))
+					err = copyFolderWithoutEntries(filepath.Join(origPath, fi.Name()), filepath.Join(destPath, fi.Name()), fi.Name(), filter)
+					if err != nil {
+						fmt.Println("Error copying folder:")
+						fmt.Println(err)
+						os.Exit(1)
+					}
+
+					continue
+				}
+
+				if fi.Name() != "entry.md" && !filter.allow(fi.Name(), fi.Size()) {
 					continue
 				}
 
@@ -195,8 +258,79 @@ func folderContainsEntry(folder string) (bool, error) {
 	return containsEntry, nil
 }
 
+// attachmentFilter decides which attachments are copied when exporting a store.
+type attachmentFilter struct {
+	// exclude leaves out all attachments.
+	exclude bool
+
+	// maxSize is the size in bytes above which attachments are left out, or zero for no limit.
+	maxSize int64
+
+	// include are globs at least one of which attachments must match, if there are any.
+	include []string
+
+	// excludeGlobs are globs which attachments mustn't match.
+	excludeGlobs []string
+}
+
+// allow returns true if the attachment should be copied. rel is the path of the attachment relative to the entry's folder.
+func (f attachmentFilter) allow(rel string, size int64) bool {
+	if f.exclude || (f.maxSize > 0 && size > f.maxSize) {
+		return false
+	}
+
+	if len(f.include) > 0 && !matchAnyGlob(f.include, rel) {
+		return false
+	}
+
+	return !matchAnyGlob(f.excludeGlobs, rel)
+}
+
+// matchAnyGlob returns true if either the path or its last element matches any of the globs.
+func matchAnyGlob(globs []string, path string) bool {
+	path = filepath.ToSlash(path)
+	name := filepath.Base(path)
+
+	for _, glob := range globs {
+		if ok, _ := filepath.Match(glob, path); ok {
+			return true
+		}
+
+		if ok, _ := filepath.Match(glob, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseSize parses a size like "10MB" or "512kb" into a number of bytes. Units are powers of 1024, and a number without
+// a unit is in bytes.
+func parseSize(size string) (int64, error) {
+	size = strings.ToLower(strings.TrimSpace(size))
+	multiplier := int64(1)
+
+	for i, unit := range []string{"kb", "mb", "gb", "tb"} {
+		if strings.HasSuffix(size, unit) {
+			multiplier = 1 << (10 * (i + 1))
+			size = strings.TrimSuffix(size, unit)
+			break
+		}
+	}
+
+	size = strings.TrimSpace(strings.TrimSuffix(size, "b"))
+
+	n, err := strconv.ParseFloat(size, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("expected a size like 10MB")
+	}
+
+	return int64(n * float64(multiplier)), nil
+}
+
 // copyFolderWithoutEntries will copy a folder and all it's subdirectories from src to dest but omitting subdirectories that contain entries themselves.
-func copyFolderWithoutEntries(src, dest string) error {
+// rel is the path of the folder relative to the entry's folder, and attachments which the filter doesn't allow aren't copied.
+func copyFolderWithoutEntries(src, dest, rel string, filter attachmentFilter) error {
 	f, _ := os.Open(src)
 	fis, _ := f.Readdir(-1)
 	f.Close()
@@ -210,14 +344,10 @@ func copyFolderWithoutEntries(src, dest string) error {
 		return nil
 	}
 
-	err = os.MkdirAll(dest, 0755)
-	if err != nil {
-		return err
-	}
-
 	for _, fi := range fis {
 		origPath := filepath.Join(src, fi.Name())
 		destPath := filepath.Join(dest, fi.Name())
+		relPath := filepath.Join(rel, fi.Name())
 
 		containsEntries, err := folderContainsEntry(origPath)
 		if err != nil {
@@ -229,10 +359,22 @@ func copyFolderWithoutEntries(src, dest string) error {
 		}
 
 		if fi.IsDir() {
-			err = copyFolderWithoutEntries(origPath, destPath)
+			err = copyFolderWithoutEntries(origPath, destPath, relPath, filter)
 			if err != nil {
 				return err
 			}
+
+			continue
+		}
+
+		if !filter.allow(relPath, fi.Size()) {
+			continue
+		}
+
+		// Folders are only made once something is copied into them, so filtering doesn't leave empty folders behind.
+		err = os.MkdirAll(dest, 0755)
+		if err != nil {
+			return err
 		}
 
 		err = gorecurcopy.Copy(origPath, destPath)
@@ -248,4 +390,8 @@ func init() {
 	ActionExportCmd.AddCommand(ActionExportStoreCmd)
 
 	ActionExportStoreCmd.Flags().StringP("output", "o", "entries", "output location of the store, a path. If a folder is specified which doesn't exist, it will be created")
+	ActionExportStoreCmd.Flags().Bool("exclude-attachments", false, "don't copy any attachments, only entries")
+	ActionExportStoreCmd.Flags().String("attachments-max-size", "", "don't copy attachments larger than this, like 10MB")
+	ActionExportStoreCmd.Flags().StringSlice("attachments-include", []string{}, "only copy attachments matching one of these globs")
+	ActionExportStoreCmd.Flags().StringSlice("attachments-exclude", []string{}, "don't copy attachments matching any of these globs")
 }
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]int64{
+		"100":    100,
+		"100B":   100,
+		"10kb":   10 << 10,
+		"10MB":   10 << 20,
+		"1.5 GB": 3 << 29,
+	}
+
+	for size, expected := range cases {
+		n, err := parseSize(size)
+		NoError(t, err, "not expecting error parsing %q", size)
+		Equal(t, expected, n, "size of %q", size)
+	}
+
+	for _, size := range []string{"", "MB", "-1KB", "ten"} {
+		_, err := parseSize(size)
+		Error(t, err, "expecting error parsing %q", size)
+	}
+}
+
+func TestAttachmentFilter(t *testing.T) {
+	True(t, attachmentFilter{}.allow("photo.jpg", 1<<30), "everything should be allowed by default")
+	False(t, attachmentFilter{exclude: true}.allow("photo.jpg", 1), "nothing should be allowed when excluding attachments")
+
+	maxSize := attachmentFilter{maxSize: 1 << 20}
+	True(t, maxSize.allow("small.jpg", 1<<20))
+	False(t, maxSize.allow("large.jpg", 1<<20+1))
+
+	globs := attachmentFilter{include: []string{"*.jpg", "*.pdf"}, excludeGlobs: []string{"private/*"}}
+	True(t, globs.allow("photos/hello.jpg", 1), "globs should match the name of nested attachments")
+	True(t, globs.allow("notes.pdf", 1))
+	False(t, globs.allow("video.mp4", 1), "attachments not matching an include glob shouldn't be allowed")
+	False(t, globs.allow("private/secret.jpg", 1), "attachments matching an exclude glob shouldn't be allowed")
+}
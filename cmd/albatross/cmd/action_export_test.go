@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestRenderEach(t *testing.T) {
+	results := make([]int, 100)
+
+	var running, most int32
+
+	err := renderEach(4, len(results), func(i int) error {
+		now := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			prev := atomic.LoadInt32(&most)
+			if now <= prev || atomic.CompareAndSwapInt32(&most, prev, now) {
+				break
+			}
+		}
+
+		results[i] = i * i
+		return nil
+	})
+	NoError(t, err)
+
+	for i, result := range results {
+		Equal(t, i*i, result, "expecting results to stay in order")
+	}
+
+	LessOrEqual(t, int(most), 4, "expecting no more than 4 renders at once")
+
+	err = renderEach(4, 10, func(i int) error {
+		if i == 3 || i == 7 {
+			return fmt.Errorf("error rendering %d", i)
+		}
+
+		return nil
+	})
+	EqualError(t, err, "error rendering 3", "expecting the error with the lowest index")
+}
+
+func TestJSONArrayEncoder(t *testing.T) {
+	values := []interface{}{
+		map[string]interface{}{"path": "food/pizza", "tags": []string{"@?food"}},
+		map[string]interface{}{"path": "food/<ice-cream>"},
+	}
+
+	for _, indent := range []string{"", "  "} {
+		for _, n := range []int{0, 1, 2} {
+			var expected, got bytes.Buffer
+
+			encoder := json.NewEncoder(&expected)
+			encoder.SetIndent("", indent)
+			err := encoder.Encode(values[:n])
+			if err != nil {
+				t.Fatalf("not expecting error encoding array: %s", err)
+			}
+
+			array := newJSONArrayEncoder(&got, indent)
+			for _, v := range values[:n] {
+				Nil(t, array.Encode(v))
+			}
+			Nil(t, array.Close())
+
+			Equal(t, expected.String(), got.String(), "expecting output to match json.Encoder with indent=%q and %d values", indent, n)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/albatross-org/go-albatross/entries"
 	"github.com/spf13/cobra"
 )
 
@@ -51,6 +53,9 @@ to nonexistant entries. For example:
 	school/a-level/physics/lessons -> [[Physics - Calculating Acceleration Due to Gravity]]
 	school/a-level/physics/lessons -> [[Physics - Calculating Acceleration Due to Gravity]] 
 
+Links to entries in other stores, like {{work:notes/project}}, are printed with the name of the store in front of
+the path, like 'work:notes/project'. See 'albatross stores --help'.
+
 And finally to print the link text (such as [[Link]] or {{path/to/link}}) instead of the path itself,
 you can use the --text flag:
 
@@ -58,6 +63,21 @@ you can use the --text flag:
 
 This behaviour is implicit for the --dont-exist flag because there's no real path for a link to an entry
 using a title that doesn't exist. 
+
+Entries can be linked to by their title or by any of the titles listed under 'aliases' in their front matter, so
+[[FM]] can link to an entry titled "Further Maths":
+
+	---
+	title: "Further Maths"
+	aliases: ["FM"]
+	---
+
+If more than one entry has the same title or alias, links using it are ambiguous. Titles come before aliases, and
+otherwise the entry with the first path is picked. To list the ambiguous links along with every entry they could mean,
+use --ambiguous:
+
+	$ albatross get links --ambiguous
+	school/timetable -> [[FM]] (school/further-maths, school/fm-club)
 `,
 
 	Run: func(cmd *cobra.Command, args []string) {
@@ -72,6 +92,14 @@ using a title that doesn't exist.
 		displayText, err := cmd.Flags().GetBool("text")
 		checkArg(err)
 
+		ambiguous, err := cmd.Flags().GetBool("ambiguous")
+		checkArg(err)
+
+		if ambiguous {
+			printAmbiguousLinks(collection, list.Slice())
+			return
+		}
+
 		for _, entry := range list.Slice() {
 			for _, link := range entry.OutboundLinks {
 				linkedEntry := collection.ResolveLink(link)
@@ -80,6 +108,8 @@ using a title that doesn't exist.
 
 					if displayText {
 						text = entry.Contents[link.Loc[0]:link.Loc[1]]
+					} else if link.Store != "" {
+						text = link.Store + ":" + linkedEntry.Path
 					} else {
 						text = linkedEntry.Path
 					}
@@ -104,10 +134,35 @@ using a title that doesn't exist.
 	},
 }
 
+// printAmbiguousLinks prints the title links in the entries which could mean more than one entry in the collection, because
+// of entries sharing a title or alias, followed by the paths of the entries they could mean.
+func printAmbiguousLinks(collection *entries.Collection, list []*entries.Entry) {
+	for _, entry := range list {
+		for _, link := range entry.OutboundLinks {
+			if link.Type != entries.LinkTitleNoName && link.Type != entries.LinkTitleWithName {
+				continue
+			}
+
+			matches := collection.TitleMatches(link.Title)
+			if len(matches) < 2 {
+				continue
+			}
+
+			paths := []string{}
+			for _, match := range matches {
+				paths = append(paths, match.Path)
+			}
+
+			fmt.Printf("%s -> %s (%s)\n", entry.Path, entry.Contents[link.Loc[0]:link.Loc[1]], strings.Join(paths, ", "))
+		}
+	}
+}
+
 func init() {
 	GetCmd.AddCommand(ActionLinksCmd)
 
 	ActionLinksCmd.Flags().BoolP("outbound", "o", false, "also show the outbound linker (i.e. the entry that's linking from) in the output")
 	ActionLinksCmd.Flags().BoolP("dont-exist", "e", false, "only show links to entries which don't exist")
 	ActionLinksCmd.Flags().Bool("text", false, "show the link text instead of the path, such as [[Link]] or {{path/to/linked}}")
+	ActionLinksCmd.Flags().Bool("ambiguous", false, "only show title links which could mean more than one entry, because of shared titles or aliases")
 }
@@ -11,8 +11,9 @@ import (
 // mapToTree converts a map[string]interface{} into a gotree.Tree.
 // maxDepth can be disabled by setting to -1.
 // By default, path should be an empty string.
-// renderFunc should take a path to an entry and should return how it should be displayed.
-func mapToTree(rootKey string, stringTree map[string]interface{}, maxDepth int, path string, renderFunc func(string) string) gotree.Tree {
+// renderFunc should take a path to an entry or folder and should return how it should be displayed.
+// sortFunc should sort the paths of the entries and folders in the same folder into the order they should be displayed.
+func mapToTree(rootKey string, stringTree map[string]interface{}, maxDepth int, path string, renderFunc func(string) string, sortFunc func([]string)) gotree.Tree {
 	tree := gotree.New(rootKey)
 
 	if len(stringTree) == 0 {
@@ -22,11 +23,19 @@ func mapToTree(rootKey string, stringTree map[string]interface{}, maxDepth int,
 		return tree
 	}
 
-	for key, subStringTree := range stringTree {
-		subtree := mapToTree(key, subStringTree.(map[string]interface{}), maxDepth-1, path+"/"+key, renderFunc)
+	children := []string{}
+	for key := range stringTree {
+		children = append(children, strings.TrimLeft(path+"/"+key, "/"))
+	}
+
+	sortFunc(children)
+
+	for _, childPath := range children {
+		key := childPath[strings.LastIndex(childPath, "/")+1:]
+		subtree := mapToTree(renderFunc(childPath), stringTree[key].(map[string]interface{}), maxDepth-1, childPath, renderFunc, sortFunc)
 
 		if len(subtree.Items()) == 0 {
-			tree.Add(renderFunc(strings.TrimLeft(path+"/"+key, "/")))
+			tree.Add(renderFunc(childPath))
 		} else {
 			tree.AddTree(subtree)
 		}
@@ -40,10 +49,7 @@ func mapToTree(rootKey string, stringTree map[string]interface{}, maxDepth int,
 //
 // Basically, it first converts a list of paths into a nested map[string]interface{}, like parsing a list of files into a tree.
 // Then it uses the mapToTree command to recursivly convert the nested map structure into a GoTree structure. It uses the renderFunc
-// to determine how it should display entries.
-//
-// BUG: When using the --display-title option, if an entry itself contains other entries, the path will be printed instead of the title.
-// TODO: Currently no sorting.
+// to determine how it should display entries and folders.
 var ActionLsCmd = &cobra.Command{
 	Use:     "ls",
 	Aliases: []string{"tree"},
@@ -76,11 +82,21 @@ var ActionLsCmd = &cobra.Command{
 			│       └── nuclear-fusion
 			└── results
 
-Currently, only printing the paths for entries is supported. In a future version you should be able to show more information.
+With --display-title, entries are shown by their title instead. Folders which aren't entries themselves are shown by
+their name, unless they contain a '_meta.yaml' file giving them a title:
+
+	$ cat school/a-level/_meta.yaml
+	title: A-Level
+	description: Notes from sixth form.
+	weight: 1
+
+Entries and folders are listed in alphabetical order, apart from folders with a weight in their '_meta.yaml', which
+are listed in order of weight, lightest first. Folders without one have a weight of 0. The title and weight are also
+used by other exports, like the contents of 'export epub', and by the server at /folders.
 `,
 
 	Run: func(cmd *cobra.Command, args []string) {
-		_, _, list := getFromCommand(cmd)
+		_, collection, list := getFromCommand(cmd)
 
 		depth, err := cmd.Flags().GetInt("depth")
 		checkArg(err)
@@ -93,15 +109,7 @@ Currently, only printing the paths for entries is supported. In a future version
 		renderFunc := func(path string) string { return path[strings.LastIndex(path, "/")+1:] }
 
 		if displayTitle {
-			renderFunc = func(path string) string {
-				for _, entry := range list.Slice() {
-					if entry.Path == path {
-						return entry.Title
-					}
-				}
-
-				return "<not found>"
-			}
+			renderFunc = collection.FolderTitle
 		}
 
 		for _, entry := range list.Slice() {
@@ -128,7 +136,7 @@ Currently, only printing the paths for entries is supported. In a future version
 			}
 		}
 
-		tree := mapToTree(".", stringTree, depth, "", renderFunc)
+		tree := mapToTree(".", stringTree, depth, "", renderFunc, collection.SortFolders)
 		fmt.Println(tree.Print())
 	},
 }
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/spf13/cobra"
+)
+
+// ActionMarkCmd represents the 'mark' action.
+var ActionMarkCmd = &cobra.Command{
+	Use:   "mark <read|unread>",
+	Short: "mark entries as read or unread",
+	Long: `mark marks the matched entries as read or unread.
+
+	$ albatross get -p imported/articles mark read
+	Marked 24 entries as read.
+
+	$ albatross get -p imported/articles/how-to-cook mark unread
+	Marked 1 entry as unread.
+
+Only entries which haven't been read can be matched using --unread with get:
+
+	$ albatross get --unread -p imported/articles
+
+Entries are also marked as read automatically when they're printed using 'contents' or opened using 'update', unless
+'read-tracking.automatic' is false in the store's config.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 || (args[0] != "read" && args[0] != "unread") {
+			fmt.Println("mark takes one argument, either 'read' or 'unread':")
+			fmt.Println("")
+			fmt.Println("    $ albatross get -p imported/articles mark read")
+			os.Exit(1)
+		}
+
+		_, _, list := getFromCommand(cmd)
+
+		paths := []string{}
+		for _, entry := range list.Slice() {
+			paths = append(paths, entry.Path)
+		}
+
+		state := args[0]
+
+		var err error
+		if state == "unread" {
+			err = store.MarkUnread(paths...)
+		} else {
+			err = store.MarkRead(paths...)
+		}
+		if err != nil {
+			log.Fatalf("Couldn't mark entries as %s: %s", state, err)
+		}
+
+		if len(paths) == 1 {
+			fmt.Printf("Marked 1 entry as %s.\n", state)
+		} else {
+			fmt.Printf("Marked %d entries as %s.\n", len(paths), state)
+		}
+	},
+}
+
+// markRead marks the entries as read if the store is set to do so automatically. Failing to mark them isn't fatal since
+// the entries have already been shown.
+func markRead(list ...*entries.Entry) {
+	if !store.MarkReadAutomatically() {
+		return
+	}
+
+	paths := []string{}
+	for _, entry := range list {
+		paths = append(paths, entry.Path)
+	}
+
+	err := store.MarkRead(paths...)
+	if err != nil {
+		log.Errorf("Couldn't mark entries as read: %s", err)
+	}
+}
+
+func init() {
+	GetCmd.AddCommand(ActionMarkCmd)
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+// pretendPaths are the paths of the pretend entries added to the collection by the last call to getFromCommand, so that
+// actions like 'materialise' can tell them apart from entries in the store.
+var pretendPaths = map[string]bool{}
+
+// ActionMaterialiseCmd represents the 'materialise' action.
+var ActionMaterialiseCmd = &cobra.Command{
+	Use:     "materialise",
+	Aliases: []string{"materialize"},
+	Short:   "write matched pretend entries into the store",
+	Long: `materialise creates entries in the store from pretend entries, given using --parse-content or --parse-file.
+
+This makes drafting entries outside of the store easy: check how a draft is matched and what it links to, then add it
+once it looks right.
+
+	$ albatross get --parse-file draft.md --parse-as ideas/hovercraft links
+	$ albatross get --parse-file draft.md --parse-as ideas/hovercraft materialise
+	Created ideas/hovercraft.
+
+Only pretend entries which were matched are created, so filters can be used to pick out some of them, such as from a
+folder of drafts:
+
+	$ albatross get --parse-file drafts/ --parse-as ideas --tag "@?ready" materialise
+
+If an entry already exists at the path of a pretend entry, it isn't changed unless --overwrite is given.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		_, _, list := getFromCommand(cmd)
+
+		overwrite, err := cmd.Flags().GetBool("overwrite")
+		checkArg(err)
+
+		pretend := []*entries.Entry{}
+		for _, entry := range list.Slice() {
+			if pretendPaths[entry.Path] {
+				pretend = append(pretend, entry)
+			}
+		}
+
+		if len(pretend) == 0 {
+			fmt.Println("No pretend entries were matched. Give them using --parse-content or --parse-file.")
+			os.Exit(1)
+		}
+
+		existing, err := storeCollection()
+		if err != nil {
+			log.Fatalf("Couldn't parse Albatross store to collection: %s", err)
+		}
+
+		// Conflicts are checked before anything is written, so that materialising doesn't stop part of the way through.
+		if !overwrite {
+			for _, entry := range pretend {
+				if existing.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: entry.Path}) != nil {
+					fmt.Printf("Not creating %s because an entry already exists there, use --overwrite to replace it.\n", entry.Path)
+					os.Exit(1)
+				}
+			}
+		}
+
+		for _, entry := range pretend {
+			if existing.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: entry.Path}) != nil {
+				err = store.Update(entry.Path, entry.OriginalContents)
+				if err != nil {
+					log.Fatalf("Couldn't update %s: %s", entry.Path, err)
+				}
+
+				fmt.Printf("Updated %s.\n", entry.Path)
+				continue
+			}
+
+			err = store.Create(entry.Path, entry.OriginalContents)
+			if err != nil {
+				log.Fatalf("Couldn't create %s: %s", entry.Path, err)
+			}
+
+			fmt.Printf("Created %s.\n", entry.Path)
+		}
+	},
+}
+
+// pretendEntriesFromCommand parses the pretend entries given by --parse-content and --parse-file, assigning them the
+// paths given by --parse-as in order.
+func pretendEntriesFromCommand(cmd *cobra.Command) []*entries.Entry {
+	contents, err := cmd.Flags().GetStringArray("parse-content")
+	checkArg(err)
+
+	files, err := cmd.Flags().GetStringArray("parse-file")
+	checkArg(err)
+
+	as, err := cmd.Flags().GetStringArray("parse-as")
+	checkArg(err)
+
+	pretend, err := parsePretendEntries(store, contents, files, as)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	return pretend
+}
+
+// parsePretendEntries parses the contents and files given as pretend entries. Each content and file is given the next path
+// from as, contents first, or "pretend/<n>" for contents and "pretend/<name>" for files if there aren't enough. Folders
+// have all the Markdown files in them parsed, under their path.
+func parsePretendEntries(store *albatross.Store, contents, files, as []string) ([]*entries.Entry, error) {
+	if len(as) > len(contents)+len(files) {
+		return nil, fmt.Errorf("--parse-as was given %d times but there are only %d pretend entries from --parse-content and --parse-file", len(as), len(contents)+len(files))
+	}
+
+	pathFor := func(i int, fallback string) string {
+		if i < len(as) {
+			return as[i]
+		}
+
+		return "pretend/" + fallback
+	}
+
+	pretend := []*entries.Entry{}
+	seen := map[string]bool{}
+
+	add := func(parsed ...*entries.Entry) error {
+		for _, entry := range parsed {
+			if seen[entry.Path] {
+				return fmt.Errorf("more than one pretend entry has the path %s, use --parse-as to give them different paths", entry.Path)
+			}
+
+			seen[entry.Path] = true
+			pretend = append(pretend, entry)
+		}
+
+		return nil
+	}
+
+	for i, content := range contents {
+		entry, err := store.PretendEntry(pathFor(i, strconv.Itoa(i+1)), content)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse --parse-content %d: %w", i+1, err)
+		}
+
+		err = add(entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, file := range files {
+		name := strings.TrimSuffix(filepath.Base(filepath.Clean(file)), ".md")
+
+		parsed, err := store.PretendEntriesFromFile(file, pathFor(len(contents)+i, name))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse --parse-file %s: %w", file, err)
+		}
+
+		err = add(parsed...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return pretend, nil
+}
+
+func init() {
+	GetCmd.AddCommand(ActionMaterialiseCmd)
+
+	ActionMaterialiseCmd.Flags().Bool("overwrite", false, "replace entries which already exist at the paths of pretend entries")
+}
@@ -2,10 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/albatross-org/go-albatross/entries"
 	"github.com/spf13/cobra"
 )
 
+// maxShownMatches is the most matches printed for each entry by --show matches.
+const maxShownMatches = 5
+
 // ActionPathCmd represents the 'tags' action.
 var ActionPathCmd = &cobra.Command{
 	Use:     "path",
@@ -35,14 +40,53 @@ The functionalities of this command can also be achieved by the template command
 	`,
 
 	Run: func(cmd *cobra.Command, args []string) {
+		show, err := cmd.Flags().GetStringSlice("show")
+		checkArg(err)
+
+		showMatches := false
+		for _, value := range show {
+			switch value {
+			case "matches":
+				showMatches = true
+			default:
+				fmt.Printf("Invalid --show %q, expected 'matches'\n", value)
+				os.Exit(1)
+			}
+		}
+
 		_, _, list := getFromCommand(cmd)
 
+		out := newOutput(os.Stdout)
+
 		for _, entry := range list.Slice() {
 			fmt.Println(entry.Path)
+
+			if showMatches {
+				printMatches(out, lastQuery.Matches(entry))
+			}
 		}
 	},
 }
 
+// printMatches prints where an entry was matched, for --show matches. The matched text is highlighted if the output is
+// using colour, and snippets are cut short to fit the terminal.
+func printMatches(out *output, matches []entries.Match) {
+	for i, match := range matches {
+		if i == maxShownMatches {
+			out.Println(out.style(styleDim, fmt.Sprintf("    ...and %d more", len(matches)-maxShownMatches)))
+			break
+		}
+
+		snippet := match.Snippet[:match.SnippetStart] + out.style(styleHighlight, match.Snippet[match.SnippetStart:match.SnippetEnd]) + match.Snippet[match.SnippetEnd:]
+
+		if match.Field == "contents" {
+			out.Println(fmt.Sprintf("    %s:%d: %s", match.Field, match.Line, snippet))
+		} else {
+			out.Println(fmt.Sprintf("    %s: %s", match.Field, snippet))
+		}
+	}
+}
+
 func init() {
 	GetCmd.AddCommand(ActionPathCmd)
 }
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
 	"github.com/spf13/cobra"
 )
 
@@ -25,10 +26,126 @@ This is more useful for doing stuff like word counts:
 	513362
 	
 	$ albatross get contents | tr -c '[:alnum:]' '[\n*]' | sort | uniq -c | sort -nr | head -100
-	# Get the most common 100 words in the search`,
+	# Get the most common 100 words in the search
+
+Shortcodes
+----------
+
+Shortcodes are reusable snippets which can be used in entries, like contact cards or recurring tables. They're defined as
+templates in the "templates/shortcodes/" directory of the store, and used by name:
+
+	.
+	├── config.yaml
+	├── entries/
+	└── templates/
+		└── shortcodes/
+			└── contact.md
+
+	(contact.md)
+	**<(.Arg 0)>**, <(.Params.phone | default "no phone number")>
+
+	(an entry)
+	Ring {{< contact "Jane Doe" phone="01234 567890" >}} about the party.
+
+Like templates for 'albatross create', they use "<(" and ")>" and have the Sprig helper functions available. The
+context has the following fields:
+
+	- .Name, the name of the shortcode.
+	- .Args, the positional arguments, which can also be accessed using .Arg, like '.Arg 0'.
+	- .Params, the named arguments.
+	- .Entry, the entry the shortcode is in.
+
+Shortcodes are left as they are in the entry itself, and expanded when exporting. To preview the expanded entry, use
+--expand:
+
+	$ albatross get -p people/jane contents --expand
+	Ring **Jane Doe**, 01234 567890 about the party.
+
+Shortcodes which aren't defined are left as they are.
+
+Includes
+--------
+
+An entry can include other entries by listing their paths under 'include' in its front matter, which is useful for
+overview pages like a course syllabus:
+
+	---
+	title: "Physics Syllabus"
+	include:
+	  - school/physics/topic1
+	  - school/physics/topic2
+	---
+
+When exporting, or when using --expand, the contents of the included entries are added to the end of the entry, each
+under a heading with its title. Included entries can include other entries too. To add them without headings, set
+'include-style' to 'concatenate':
+
+	include-style: "concatenate"
+
+Included entries don't need to be matched by the search themselves.
+
+Embeds
+------
+
+A path link starting with "!" embeds the entry it links to, so that when exporting, or when using --expand, its
+contents are shown in place of the link. Adding a heading after a "#" embeds just that section of it:
+
+	The recipe is below:
+
+	{{!food/pizza}}
+
+	{{!food/pizza#Toppings}}
+
+Embedded entries can embed other entries too, but an entry can't embed itself. Embeds inside code are left alone, and
+when exporting to Obsidian they're written as Obsidian embeds instead.
+
+Dynamic Entries
+---------------
+
+An entry with 'dynamic: true' in its front matter is a template which is run against the whole store whenever it's
+exported or printed using --expand, so it can keep something like an index up to date by itself:
+
+	---
+	title: "Projects"
+	dynamic: true
+	---
+
+	<(range .Tagged "@?project")>
+	* <(link .)> (<(.Title)>)
+	<(end)>
+
+Like shortcodes, they use "<(" and ")>" and have the Sprig helper functions available, as well as 'link', which gives a
+link to an entry by its path. The context has the following fields:
+
+	- .Entry, the dynamic entry itself.
+	- .Store, holding .Store.Vars from the config.
+	- .Entries, every other entry in the store.
+	- .Tagged, the entries with a tag, like '.Tagged "@?project"'.
+	- .Under, the entries inside a path, like '.Under "school/physics"'.
+	- .Backlinks, the entries which link to the dynamic entry.
+	- .Get, the entry at a path, like '.Get "school/physics"'.
+
+Lists of entries are sorted by path. The entry itself always keeps the template, so editing it edits the template.`,
 
 	Run: func(cmd *cobra.Command, args []string) {
-		_, _, list := getFromCommand(cmd)
+		annotations, err := cmd.Flags().GetBool("annotations")
+		checkArg(err)
+
+		// Annotations are encrypted along with the entries, so the store has to stay decrypted after the entries are found.
+		if annotations {
+			encrypted, err := store.Encrypted()
+			if err != nil {
+				log.Fatal(err)
+			} else if encrypted {
+				decryptStore()
+
+				if !leaveDecrypted {
+					defer encryptStore()
+				}
+			}
+		}
+
+		all, _, list := getFromCommand(cmd)
 
 		raw, err := cmd.Flags().GetBool("raw")
 		checkArg(err)
@@ -36,17 +153,49 @@ This is more useful for doing stuff like word counts:
 		between, err := cmd.Flags().GetString("between")
 		checkArg(err)
 
+		expand, err := cmd.Flags().GetBool("expand")
+		checkArg(err)
+
 		between += "\n"
 
+		var shortcodes albatross.Shortcodes
+		if expand {
+			shortcodes, err = store.Shortcodes()
+			if err != nil {
+				log.Fatalf("Couldn't load shortcodes: %s", err)
+			}
+		}
+
 		for _, entry := range list.Slice() {
 			if raw {
 				fmt.Println(entry.OriginalContents)
-			} else {
-				fmt.Println(entry.Contents)
+				fmt.Print(between)
+				continue
 			}
 
+			contents := entry.Contents
+
+			if expand {
+				contents, _, err = exportContents(all, shortcodes, entry)
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+
+			if annotations {
+				contents, err = annotatedContents(entry, contents)
+				if err != nil {
+					log.Fatalf("Couldn't get annotations for %s: %s", entry.Path, err)
+				}
+			}
+
+			fmt.Println(contents)
+
 			fmt.Print(between)
 		}
+
+		markRead(list.Slice()...)
+		recordView(list.Slice()...)
 	},
 }
 
@@ -55,4 +204,6 @@ func init() {
 
 	ContentsCmd.Flags().Bool("raw", false, "include front matter when printing")
 	ContentsCmd.Flags().String("between", "", "what to print between entries")
+	ContentsCmd.Flags().Bool("expand", false, "expand shortcodes, includes and dynamic entries when printing")
+	ContentsCmd.Flags().Bool("annotations", false, "show annotations on the entries, see 'albatross get annotate --help'")
 }
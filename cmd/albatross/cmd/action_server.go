@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
 	"github.com/albatross-org/go-albatross/server"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // responseMatchedMultiple is the response sent when a request matches multiple or zero entries.
@@ -15,7 +21,125 @@ type responseMatchedMultiple struct {
 var ActionServerCmd = &cobra.Command{
 	Use:   "server",
 	Short: "start HTTP server which serves JSON entries",
-	Long:  `date will print the date of all matched entries`,
+	Long: `server starts an HTTP server which serves the matched entries as JSON.
+
+	$ albatross get -p school server --port 2718
+
+The following endpoints are available:
+
+	GET /search          Search entries. Takes the same filters as 'albatross get' as query parameters, like ?path=school
+	GET /entries/<path>      Get a single entry by its path, like /entries/school/physics
+	GET /attachments/<path>  List the files attached to an entry, with their size and SHA-256 hash
+	GET /files/<hash>        Download an attached file by its SHA-256 hash
+	GET /events              Changes to entries as they happen, as server-sent events
+	GET /stats               Statistics about the entries being served, see 'albatross stats store --help'
+	GET /openapi.json        An OpenAPI 3 specification of these endpoints
+
+If --allow-writes is given, entries can also be changed by requests with the token of a grant with 'write: true' (see
+Access Control below), which is required to use it:
+
+	POST /entries/<path>      Create an entry from a JSON body like {"contents": "---\ntitle: Pizza\n---\n..."}
+	PUT /entries/<path>       Replace the contents of an entry, taking the same body as POST
+	DELETE /entries/<path>    Delete an entry and its attachments
+	POST /attachments/<path>  Attach the file uploaded as the "file" field of a multipart form to an entry
+
+Each change is committed, just like using 'albatross create' or 'albatross update'. So that changes from two clients
+don't overwrite each other, PUT requests need an If-Match header with the ETag of the entry from when the client last
+read it. If the entry has changed since then, the response is 412 Precondition Failed and the client should read it
+again. DELETE and attachment requests check If-Match too if it's given. Only entries being served can be updated,
+deleted or attached to. The bodies of POST and PUT requests must have a Content-Type of application/json.
+
+The OpenAPI specification can be used to generate clients in other languages. To print it without starting the
+server, use the --openapi flag:
+
+	$ albatross get server --openapi > openapi.json
+
+The endpoints which modify entries are only included if --allow-writes is given too.
+
+Responses from /search and /entries include ETag and Last-Modified headers, and conditional requests using
+If-None-Match or If-Modified-Since receive a 304 Not Modified if nothing has changed. Last-Modified is the time of
+the last git commit changing the entry if the store uses git, or the file modification time otherwise.
+
+Files are downloaded by their hash so that they can be cached forever, since the hash changes whenever the file
+does. Only files attached to entries being served can be downloaded.
+
+Search responses are cached by their query parameters, which can be configured with --cache-size.
+
+By default, the entries are read once when the server starts. To serve changes made while it's running, such as by
+editing entries in another program, use --watch. Only the entries which change are read again, and the cache is
+cleared whenever they do. This doesn't work for encrypted stores.
+
+Clients such as a web page or an editor plugin can listen to /events to hear about changes without polling. Each
+change is sent as a server-sent event named "created", "updated" or "deleted", with data like:
+
+	{"type": "updated", "path": "food/pizza", "etag": "\"...\""}
+
+Events are sent for changes the server makes itself when using --allow-writes, and for changes made by other programs
+when using --watch. Entries which stop matching the filters given to 'albatross get' are sent as deleted. Changes
+made while a client isn't connected aren't sent again, so clients should read the entries again when they reconnect.
+In a browser, use EventSource, giving the token as ?token= if the server has an ACL:
+
+	new EventSource("http://localhost:2718/events").addEventListener("updated", e => console.log(JSON.parse(e.data)))
+
+If the server is public-facing, you can limit the number of requests each client can make using --rate-limit and
+--rate-burst. Clients are identified by a bearer token in the Authorization header or a ?token= query parameter,
+and by their IP address otherwise.
+
+	$ albatross get --tag "@!public" server --rate-limit 60 --rate-burst 10
+	# Allow each client 60 requests a minute, and up to 10 at once.
+
+To use entries from other sites, such as a personal dashboard or homepage, use --cors-origin to allow cross-origin
+requests from JavaScript and --allow-embed to allow the responses to be embedded in an iframe. Both take origins like
+"https://example.com" or "https://*.example.com", and "*" allows any origin:
+
+	$ albatross get -p journal server --cors-origin https://dash.example.com --allow-embed https://dash.example.com
+
+By default, cross-origin requests are only allowed from https://cdpn.io and embedding is denied using the
+Content-Security-Policy frame-ancestors directive.
+
+To list entries fetched from /entries in 'albatross recent', use --track-views. Every request counts, including ones
+from other people's tokens, so it's best left off if the store is shared.
+
+Access Control
+--------------
+
+To share parts of a store with other people, such as a family store where someone can read recipes but not your
+journal, add grants to the store's section of the config file:
+
+	default:
+	  path: /home/user/.local/share/albatross/default
+	  server:
+	    acl:
+	      - name: partner
+	        token: "a long random string"
+	        paths: ["recipes", "shopping"]
+	      - name: me
+	        token: "another long random string"
+	        paths: [""]
+	        write: true
+
+If any grants are given, every request must include a token as a bearer token in the Authorization header or as
+a ?token= query parameter. Requests only see the entries under the paths of their grant, so searches and statistics
+leave out other entries and requesting them directly gives a 404. A path only matches whole path components and ""
+matches everything. Only grants with 'write: true' can use endpoints which modify entries.
+
+GraphQL
+-------
+
+For clients which need more than /search gives, such as the backlinks and attachments of every matched entry in one
+request, use --graphql to also serve a GraphQL endpoint:
+
+	GET /graphql             Run a query given as ?query=, with optional ?variables= as JSON and ?operationName=
+	POST /graphql            Run a query given as a JSON body like {"query": "...", "variables": {...}}
+	GET /graphql/schema      The GraphQL schema
+
+For example:
+
+	$ albatross get server --graphql
+	$ curl localhost:2718/graphql -d '{"query": "{ search(pathsMatch: [\"food\"], sort: \"date\") { matched entries { title backlinks { path } } } }"}'
+
+The arguments to search mirror the flags of 'albatross get', and queries only see the entries their token can access.
+Only queries are supported, so entries can't be changed using GraphQL.`,
 
 	Run: func(cmd *cobra.Command, args []string) {
 		_, collection, _ := getFromCommand(cmd)
@@ -23,7 +147,84 @@ var ActionServerCmd = &cobra.Command{
 		port, err := cmd.Flags().GetInt("port")
 		checkArg(err)
 
+		cacheSize, err := cmd.Flags().GetInt("cache-size")
+		checkArg(err)
+
+		rateLimit, err := cmd.Flags().GetInt("rate-limit")
+		checkArg(err)
+
+		rateBurst, err := cmd.Flags().GetInt("rate-burst")
+		checkArg(err)
+
+		corsOrigins, err := cmd.Flags().GetStringSlice("cors-origin")
+		checkArg(err)
+
+		allowEmbed, err := cmd.Flags().GetStringSlice("allow-embed")
+		checkArg(err)
+
+		openAPI, err := cmd.Flags().GetBool("openapi")
+		checkArg(err)
+
+		watch, err := cmd.Flags().GetBool("watch")
+		checkArg(err)
+
+		allowWrites, err := cmd.Flags().GetBool("allow-writes")
+		checkArg(err)
+
+		graphQL, err := cmd.Flags().GetBool("graphql")
+		checkArg(err)
+
+		trackViews, err := cmd.Flags().GetBool("track-views")
+		checkArg(err)
+
+		if openAPI {
+			out, err := json.MarshalIndent(server.OpenAPI(allowWrites), "", "  ")
+			if err != nil {
+				log.Fatalf("Couldn't marshal OpenAPI specification: %s", err)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
 		s := server.NewServer(collection)
+		s.SetStore(store)
+		s.SetCacheSize(cacheSize)
+		s.SetRateLimit(rateLimit, rateBurst)
+		s.SetAllowEmbed(allowEmbed)
+		s.SetGraphQL(graphQL)
+		s.SetTrackViews(trackViews)
+
+		var grants []server.Grant
+
+		err = viper.UnmarshalKey(fmt.Sprintf("%s.server.acl", storeName), &grants)
+		if err != nil {
+			log.Fatalf("Couldn't parse server ACL in config file: %s", err)
+		}
+
+		err = s.SetACL(grants)
+		if err != nil {
+			log.Fatalf("Invalid server ACL in config file: %s", err)
+		}
+
+		if allowWrites {
+			if !hasWriteGrant(grants) {
+				fmt.Printf("Can't use --allow-writes without a grant with 'write: true' in %s.server.acl in the config file.\n", storeName)
+				os.Exit(1)
+			}
+
+			s.SetWritable(lastFilter)
+		}
+
+		err = s.SetCORS(corsOrigins)
+		if err != nil {
+			log.Fatalf("Invalid --cors-origin: %s", err)
+		}
+
+		if watch {
+			watchForServer(s)
+		}
+
 		err = s.Serve(port)
 
 		if err != nil {
@@ -32,7 +233,65 @@ var ActionServerCmd = &cobra.Command{
 	},
 }
 
+// hasWriteGrant returns true if any of the grants can modify entries.
+func hasWriteGrant(grants []server.Grant) bool {
+	for _, grant := range grants {
+		if grant.Write {
+			return true
+		}
+	}
+
+	return false
+}
+
+// watchForServer watches the store for changes in the background, filtering the collection again and passing it to the
+// server whenever entries change, for --watch.
+func watchForServer(s *server.Server) {
+	encrypted, err := store.Encrypted()
+	if err != nil {
+		log.Fatal(err)
+	} else if encrypted {
+		fmt.Println("Can't use --watch with an encrypted store, since the entries are only decrypted in memory.")
+		os.Exit(1)
+	}
+
+	filter := lastFilter
+
+	go func() {
+		err := store.Watch(context.Background(), func(paths []string) {
+			collection, err := store.Collection()
+			if err != nil {
+				log.Errorf("Couldn't get collection after changes: %s", err)
+				return
+			}
+
+			filtered, err := collection.Filter(filter)
+			if err != nil {
+				log.Errorf("Couldn't filter collection after changes: %s", err)
+				return
+			}
+
+			s.SetCollection(filtered)
+			log.Infof("Updated %d changed entries.", len(paths))
+		})
+
+		if err != nil {
+			log.Errorf("Stopped watching store for changes: %s", err)
+		}
+	}()
+}
+
 func init() {
 	GetCmd.AddCommand(ActionServerCmd)
 	ActionServerCmd.Flags().Int("port", 2718, "port to run server")
+	ActionServerCmd.Flags().Int("cache-size", server.DefaultCacheSize, "number of search responses to cache, 0 disables caching")
+	ActionServerCmd.Flags().Int("rate-limit", 0, "requests per minute allowed for each client, 0 disables rate limiting")
+	ActionServerCmd.Flags().Int("rate-burst", 10, "number of requests a client can make at once before being rate limited")
+	ActionServerCmd.Flags().StringSlice("cors-origin", server.DefaultCORSOrigins, "origins allowed to make cross-origin requests, '*' for any")
+	ActionServerCmd.Flags().StringSlice("allow-embed", []string{}, "origins allowed to embed responses in an iframe, '*' for any")
+	ActionServerCmd.Flags().Bool("watch", false, "watch the store for changes and serve them without restarting")
+	ActionServerCmd.Flags().Bool("allow-writes", false, "allow requests to create, update and delete entries")
+	ActionServerCmd.Flags().Bool("graphql", false, "serve a GraphQL endpoint at /graphql")
+	ActionServerCmd.Flags().Bool("track-views", false, "record entries fetched from /entries as viewed, for 'albatross recent'")
+	ActionServerCmd.Flags().Bool("openapi", false, "print the OpenAPI specification for the server and exit")
 }
@@ -1,7 +1,9 @@
 package cmd
 
 import (
-	"fmt"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -24,17 +26,67 @@ The functionalities of this command can be achieved with the template command:
 	@?further-maths, @?latex-block-alt
 	@?further-maths, @?latex-block-alt
 	...
+
+To count how many of the matched entries have each tag instead, use --count. Tags are listed from most to least used:
+
+	$ albatross get -p school/a-level tags --count
+	@?further-maths    31
+	@?latex-block-alt  12
+	...
 `,
 
 	Run: func(cmd *cobra.Command, args []string) {
+		count, err := cmd.Flags().GetBool("count")
+		checkArg(err)
+
 		_, _, list := getFromCommand(cmd)
 
+		out := newOutput(os.Stdout)
+
+		if !count {
+			for _, entry := range list.Slice() {
+				tags := make([]string, len(entry.Tags))
+				for i, tag := range entry.Tags {
+					tags[i] = out.style(styleTag, tag)
+				}
+
+				out.Println(strings.Join(tags, ", "))
+			}
+
+			return
+		}
+
+		counts := map[string]int{}
 		for _, entry := range list.Slice() {
-			fmt.Println(strings.Join(entry.Tags, ", "))
+			for _, tag := range entry.Tags {
+				counts[tag]++
+			}
 		}
+
+		tags := make([]string, 0, len(counts))
+		for tag := range counts {
+			tags = append(tags, tag)
+		}
+
+		sort.Slice(tags, func(i, j int) bool {
+			if counts[tags[i]] != counts[tags[j]] {
+				return counts[tags[i]] > counts[tags[j]]
+			}
+
+			return tags[i] < tags[j]
+		})
+
+		table := newTable(out, 1)
+		for _, tag := range tags {
+			table.Row(out.style(styleTag, tag), strconv.Itoa(counts[tag]))
+		}
+
+		table.Flush()
 	},
 }
 
 func init() {
 	GetCmd.AddCommand(ActionTagsCmd)
+
+	ActionTagsCmd.Flags().Bool("count", false, "print how many entries have each tag instead")
 }
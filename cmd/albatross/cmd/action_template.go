@@ -7,7 +7,10 @@ import (
 	"text/template"
 
 	"github.com/Masterminds/sprig"
+	"github.com/albatross-org/go-albatross/entries"
 	"github.com/spf13/cobra"
+
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
 )
 
 // ActionTemplateCmd represents the 'tags' action.
@@ -71,7 +74,13 @@ The template context is an Entry struct. This contains the following fields:
 	  The title of the entry.
 
 	- .Metadata, map[string]interface{}
-	  All of the front matter. `,
+	  All of the front matter.
+
+	- .Store.Vars, map[string]interface{}
+	  The values from the 'variables' section of the store's config, such as .Store.Vars.author. Keys are always in
+	  lower case. With --all, the context is the list of entries, so use the storeVars function instead:
+
+	  $ albatross get template --all '{{range .}}{{.Title}} by {{storeVars.author}}{{end}}'`,
 
 	Run: func(cmd *cobra.Command, args []string) {
 		_, _, list := getFromCommand(cmd)
@@ -79,7 +88,11 @@ The template context is an Entry struct. This contains the following fields:
 		all, err := cmd.Flags().GetBool("all")
 		checkArg(err)
 
-		var tmpl = template.New("input").Funcs(sprig.TxtFuncMap())
+		templateStore := store.TemplateStore()
+
+		var tmpl = template.New("input").Funcs(sprig.TxtFuncMap()).Funcs(template.FuncMap{
+			"storeVars": func() map[string]interface{} { return templateStore.Vars },
+		})
 
 		fi, err := os.Stdin.Stat()
 		if err != nil {
@@ -127,7 +140,7 @@ The template context is an Entry struct. This contains the following fields:
 			}
 		} else {
 			for _, entry := range list.Slice() {
-				err = tmpl.Execute(os.Stdout, entry)
+				err = tmpl.Execute(os.Stdout, templateEntry{Entry: entry, Store: templateStore})
 				if err != nil {
 					fmt.Println("Error executing template:")
 					fmt.Println(err)
@@ -140,6 +153,15 @@ The template context is an Entry struct. This contains the following fields:
 	},
 }
 
+// templateEntry is the context the template is executed with for each entry. The entry's fields are available as they
+// are, such as .Title, along with .Store.
+type templateEntry struct {
+	*entries.Entry
+
+	// Store holds values set for the whole store, such as .Store.Vars.
+	Store albatross.TemplateStore
+}
+
 func init() {
 	GetCmd.AddCommand(ActionTemplateCmd)
 	ActionTemplateCmd.Flags().Bool("all", false, "Run a template on all entries instead of each one sequentially")
@@ -2,13 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"io/ioutil"
 	"os"
 
 	"github.com/albatross-org/go-albatross/entries"
 	"github.com/manifoldco/promptui"
 
-	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -20,7 +18,16 @@ var ActionUpdateCmd = &cobra.Command{
 	
 	$ albatross get -p food/pizza update
 
-If multiple entries are matched, a list is displayed to choose from.`,
+If multiple entries are matched, a list is displayed to choose from.
+
+After the editor is closed, the entry is checked before it's saved: the front matter has to parse, dates have to use
+the date format and links have to point to entries which exist. If there are problems, you can choose to edit the entry
+again, save it anyway or discard your changes. To skip the checks, use --no-check. To only check the front matter and
+not links, set 'editor.check-links' to false in the config file.
+
+To only edit the front matter, such as to change the title or tags of a long entry, use --front-matter:
+
+	$ albatross get -p food/pizza update --front-matter`,
 	Run: func(cmd *cobra.Command, args []string) {
 		_, _, list := getFromCommand(cmd)
 		var chosen *entries.Entry
@@ -28,6 +35,12 @@ If multiple entries are matched, a list is displayed to choose from.`,
 		customEditor, err := cmd.Flags().GetString("editor")
 		checkArg(err)
 
+		noCheck, err := cmd.Flags().GetBool("no-check")
+		checkArg(err)
+
+		frontMatterOnly, err := cmd.Flags().GetBool("front-matter")
+		checkArg(err)
+
 		length := len(list.Slice())
 
 		if length == 0 {
@@ -60,46 +73,42 @@ If multiple entries are matched, a list is displayed to choose from.`,
 			chosen = list.Slice()[0]
 		}
 
-		updateEntry(chosen, customEditor)
+		updateEntry(chosen, customEditor, !noCheck, frontMatterOnly)
 	},
 }
 
-func updateEntry(entry *entries.Entry, editorName string) {
-	content, err := edit(
-		editorName,
-		entry.OriginalContents,
-	)
-	if err != nil {
+func updateEntry(entry *entries.Entry, editorName string, check, frontMatterOnly bool) {
+	recordView(entry)
+
+	content, err := editChecked(editorName, entry.Path, entry.OriginalContents, check, frontMatterOnly)
+	if err == errEditDiscarded {
+		fmt.Println("Discarded changes to entry:", entry.Path)
+		return
+	} else if err != nil {
 		log.Fatal("Couldn't get content from editor: ", err)
 	}
 
 	if entry.OriginalContents == content {
 		fmt.Println("No change made to entry:", entry.Path)
+		markRead(entry)
 		return
 	}
 
 	err = store.Update(entry.Path, content)
 	if err != nil {
-		f, tempErr := ioutil.TempFile("", "albatross-recover")
-		if tempErr != nil {
-			logrus.Fatal("Couldn't get create temporary file to save recovery entry to. You're on your own! ", err)
-		}
-
-		_, err = f.Write([]byte(content))
-		if err != nil {
-			logrus.Fatal("Error writing to temporary file to save recovery entry to. You're on your own! ", err)
-		}
-
-		fmt.Println("Error updating entry. A copy of the updated file has been saved to:", f.Name())
+		saveRecovery(entry.Path, entry.OriginalContents, content, "Error updating entry.")
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
 	fmt.Println("Successfully updated entry:", entry.Path)
+	markRead(entry)
 }
 
 func init() {
 	GetCmd.AddCommand(ActionUpdateCmd)
 
+	ActionUpdateCmd.Flags().Bool("no-check", false, "don't check the entry for problems before saving it")
+	ActionUpdateCmd.Flags().Bool("front-matter", false, "only edit the YAML front matter of the entry")
 	ActionUpdateCmd.Flags().StringP("editor", "e", getEditor("vim"), "Editor to use (defaults to $EDITOR, then vim)")
 }
@@ -0,0 +1,506 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// AliasCmd represents the alias command.
+var AliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "list, add and remove shortcuts for commands",
+	Long: `alias manages aliases, which are shortcuts for commands you use often. They're kept in the 'aliases' section of
+the config file:
+
+	aliases:
+	  revise: "get -p school --tag @?ankify ankify"
+	  today: "get --from today ls"
+
+Running an alias runs the command it stands for, with any other arguments added to the end:
+
+	$ albatross revise --exclude-missing
+	# Same as: albatross get -p school --tag @?ankify ankify --exclude-missing
+
+Global flags like --store can still be given before the alias. Aliases can use other aliases, but not the names of
+built-in commands, which always take precedence. The commands are split into arguments like a shell would, so
+arguments containing spaces can be quoted. An alias can also be given as a list of arguments:
+
+	aliases:
+	  pizza: ["get", "--title", "Pizza Margherita"]
+
+	$ albatross alias list
+	$ albatross alias add revise get -p school --tag @?ankify ankify
+	$ albatross alias remove revise`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		AliasListCmd.Run(cmd, args)
+	},
+}
+
+// AliasListCmd represents the alias list command.
+var AliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the aliases in the config",
+	Long:  `list prints the name of each alias in the config and the command it stands for.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		aliases, err := parseAliases(viper.Get("aliases"))
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if len(aliases) == 0 {
+			fmt.Println("There are no aliases in the config, see 'albatross alias --help'.")
+			return
+		}
+
+		names := []string{}
+		width := 0
+
+		for name := range aliases {
+			names = append(names, name)
+
+			if len(name) > width {
+				width = len(name)
+			}
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Printf("%-*s  %s\n", width, name, joinCommand(aliases[name]))
+		}
+	},
+}
+
+// AliasAddCmd represents the alias add command.
+var AliasAddCmd = &cobra.Command{
+	Use:   "add [name] [command...]",
+	Short: "add an alias to the config",
+	Long: `add saves an alias in the config, replacing any existing alias with the same name. Everything after the name is
+the command, so flags like -p don't need quoting:
+
+	$ albatross alias add revise get -p school --tag @?ankify ankify
+	$ albatross revise`,
+	Args: cobra.MinimumNArgs(2),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+
+		err := validAliasName(name)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		_, err = updateAliasConfig(viper.ConfigFileUsed(), name, joinCommand(args[1:]), false)
+		if err != nil {
+			log.Fatalf("Couldn't save alias: %s", err)
+		}
+
+		fmt.Printf("Added alias '%s'.\n", name)
+	},
+}
+
+// AliasRemoveCmd represents the alias remove command.
+var AliasRemoveCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "remove an alias from the config",
+	Long:  `remove deletes an alias from the config.`,
+	Args:  cobra.ExactArgs(1),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		found, err := updateAliasConfig(viper.ConfigFileUsed(), args[0], "", true)
+		if err != nil {
+			log.Fatalf("Couldn't remove alias: %s", err)
+		}
+
+		if !found {
+			fmt.Printf("No alias named '%s' in the config, see 'albatross alias list'.\n", args[0])
+			os.Exit(1)
+		}
+
+		fmt.Printf("Removed alias '%s'.\n", args[0])
+	},
+}
+
+// validAliasName returns an error if the name can't be used for an alias.
+func validAliasName(name string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t\n:#\"'") {
+		return fmt.Errorf("'%s' isn't a valid alias name", name)
+	}
+
+	if builtinCommand(name) {
+		return fmt.Errorf("'%s' is already a command, so it can't be used as an alias", name)
+	}
+
+	return nil
+}
+
+// builtinCommand returns true if name is the name, or one of the aliases, of a command added to the root command.
+func builtinCommand(name string) bool {
+	if name == "help" {
+		return true
+	}
+
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loadAliases reads the aliases from the config file before the command line is parsed, since the aliases change
+// which command is run. If configFile is blank, the default config file is used. A missing config file has no aliases.
+func loadAliases(configFile string) (map[string][]string, error) {
+	v := viper.New()
+
+	if configFile != "" {
+		v.SetConfigFile(configFile)
+	} else {
+		v.AddConfigPath(getConfigDirectory())
+		v.SetConfigName("config")
+	}
+
+	err := v.ReadInConfig()
+	if err != nil {
+		return nil, nil
+	}
+
+	return parseAliases(v.Get("aliases"))
+}
+
+// parseAliases returns the arguments of each alias in the 'aliases' section of the config, which can be given as a
+// string or a list of arguments.
+func parseAliases(value interface{}) (map[string][]string, error) {
+	aliases := map[string][]string{}
+
+	if value == nil {
+		return aliases, nil
+	}
+
+	section, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid aliases in config file: expected a map of names to commands")
+	}
+
+	for name, command := range section {
+		switch command := command.(type) {
+		case string:
+			args, err := splitCommand(command)
+			if err != nil {
+				return nil, fmt.Errorf("invalid alias '%s' in config file: %w", name, err)
+			}
+
+			aliases[name] = args
+
+		case []interface{}:
+			args := []string{}
+			for _, arg := range command {
+				args = append(args, fmt.Sprint(arg))
+			}
+
+			aliases[name] = args
+
+		default:
+			return nil, fmt.Errorf("invalid alias '%s' in config file: expected a command or a list of arguments", name)
+		}
+	}
+
+	return aliases, nil
+}
+
+// expandAliases replaces the command in the arguments given with what it stands for if it's an alias, returning the
+// arguments unchanged if it isn't. Flags before the command, like '--store thesis', are kept where they are. Config
+// keys are case-insensitive, so aliases are too.
+func expandAliases(args []string, aliases map[string][]string) ([]string, error) {
+	seen := map[string]bool{}
+
+	for {
+		i := commandIndex(args)
+		if i == -1 {
+			return args, nil
+		}
+
+		name := strings.ToLower(args[i])
+
+		expansion, ok := aliases[name]
+		if !ok || builtinCommand(args[i]) {
+			return args, nil
+		}
+
+		if seen[name] {
+			return nil, fmt.Errorf("alias '%s' refers to itself", name)
+		}
+
+		seen[name] = true
+
+		expanded := append([]string{}, args[:i]...)
+		expanded = append(expanded, expansion...)
+		args = append(expanded, args[i+1:]...)
+	}
+}
+
+// commandIndex returns the index of the first argument which isn't a global flag or the value of one, which is the
+// command being run, or -1 if there isn't one.
+func commandIndex(args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			return -1
+		}
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return i
+		}
+
+		if strings.Contains(arg, "=") {
+			continue
+		}
+
+		flags := rootCmd.PersistentFlags()
+
+		flag := flags.Lookup(strings.TrimLeft(arg, "-"))
+		if !strings.HasPrefix(arg, "--") && len(arg) == 2 {
+			flag = flags.ShorthandLookup(arg[1:])
+		}
+
+		if flag != nil && flag.Value.Type() != "bool" {
+			i++ // Skip the flag's value.
+		}
+	}
+
+	return -1
+}
+
+// configFlag returns the value of --config in the global flags before the command, or "" if it isn't given.
+func configFlag(args []string) string {
+	end := commandIndex(args)
+	if end == -1 {
+		end = len(args)
+	}
+
+	for i := 0; i < end; i++ {
+		if args[i] == "--config" && i+1 < len(args) {
+			return args[i+1]
+		}
+
+		if strings.HasPrefix(args[i], "--config=") {
+			return strings.TrimPrefix(args[i], "--config=")
+		}
+	}
+
+	return ""
+}
+
+// splitCommand splits a command into arguments like a shell would. Arguments are separated by whitespace, single quotes
+// keep everything inside them as it is, and double quotes allow '\"' and '\\' to be escaped.
+func splitCommand(command string) ([]string, error) {
+	args := []string{}
+
+	var (
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else if r == '\\' {
+				escaped = true
+			} else {
+				current.WriteRune(r)
+			}
+
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+
+		case r == '\\':
+			escaped = true
+			inArg = true
+
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in %q", quote, command)
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash in %q", command)
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+// joinCommand joins arguments into a command which splitCommand splits back into the same arguments, quoting those
+// which need it.
+func joinCommand(args []string) string {
+	quoted := []string{}
+
+	for _, arg := range args {
+		if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\") {
+			quoted = append(quoted, arg)
+		} else if !strings.Contains(arg, "'") {
+			quoted = append(quoted, "'"+arg+"'")
+		} else {
+			quoted = append(quoted, `"`+strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg)+`"`)
+		}
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// updateAliasConfig adds or replaces the alias with the name given in the 'aliases' section of the YAML config file
+// given, or removes it if remove is true. It returns whether the alias was already there. Like setCurrentStore, the
+// rest of the file is left as it is, so comments aren't lost.
+func updateAliasConfig(configFile, name, command string, remove bool) (found bool, err error) {
+	if configFile == "" {
+		configFile = filepath.Join(getConfigDirectory(), "config.yaml")
+	}
+
+	if ext := filepath.Ext(configFile); ext != ".yaml" && ext != ".yml" {
+		return false, fmt.Errorf("can only save aliases to a YAML config, not %s", configFile)
+	}
+
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		lines = []string{}
+	}
+
+	line := fmt.Sprintf("%s: %s", name, strconv.Quote(command))
+
+	// The section runs from the 'aliases:' line until the next line which isn't indented, blank lines aside.
+	start, end := -1, -1
+
+	for i, existing := range lines {
+		if start == -1 {
+			if strings.HasPrefix(existing, "aliases:") {
+				rest := strings.TrimSpace(strings.TrimPrefix(existing, "aliases:"))
+				if rest != "" && !strings.HasPrefix(rest, "#") {
+					return false, fmt.Errorf("can't edit aliases written on one line in %s", configFile)
+				}
+
+				start, end = i, i+1
+			}
+
+			continue
+		}
+
+		if strings.TrimSpace(existing) == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(existing, " ") && !strings.HasPrefix(existing, "\t") {
+			break
+		}
+
+		end = i + 1
+	}
+
+	if start == -1 {
+		if remove {
+			return false, nil
+		}
+
+		lines = append(lines, "aliases:", "  "+line)
+	} else {
+		indent := "  "
+
+		for i := start + 1; i < end; i++ {
+			trimmed := strings.TrimSpace(lines[i])
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+
+			indent = lines[i][:len(lines[i])-len(strings.TrimLeft(lines[i], " \t"))]
+
+			if !strings.HasPrefix(trimmed, name+":") {
+				continue
+			}
+
+			found = true
+
+			if remove {
+				lines = append(lines[:i], lines[i+1:]...)
+			} else {
+				lines[i] = indent + line
+			}
+
+			break
+		}
+
+		if !found && !remove {
+			lines = append(lines[:end], append([]string{indent + line}, lines[end:]...)...)
+		}
+	}
+
+	if remove && !found {
+		return false, nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(configFile), 0755)
+	if err != nil {
+		return false, err
+	}
+
+	return found, ioutil.WriteFile(configFile, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+func init() {
+	rootCmd.AddCommand(AliasCmd)
+
+	AliasCmd.AddCommand(AliasListCmd)
+	AliasCmd.AddCommand(AliasAddCmd)
+	AliasCmd.AddCommand(AliasRemoveCmd)
+
+	// Everything after the alias's name is its command, so flags in it aren't parsed.
+	AliasAddCmd.Flags().SetInterspersed(false)
+}
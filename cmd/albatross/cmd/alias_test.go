@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestSplitCommand(t *testing.T) {
+	args, err := splitCommand(`get -p school --tag @?ankify  ankify`)
+	NoError(t, err)
+	Equal(t, []string{"get", "-p", "school", "--tag", "@?ankify", "ankify"}, args)
+
+	args, err = splitCommand(`get --title "Pizza \"Margherita\"" --path 'a b\c' x\ y ""`)
+	NoError(t, err)
+	Equal(t, []string{"get", "--title", `Pizza "Margherita"`, "--path", `a b\c`, "x y", ""}, args)
+
+	_, err = splitCommand(`get --title "Pizza`)
+	Error(t, err, "expecting error for unterminated quote")
+
+	for _, args := range [][]string{
+		{"get", "-p", "school"},
+		{"get", "--title", "Pizza Margherita"},
+		{"it's", `"quoted"`, `back\slash`, ""},
+	} {
+		split, err := splitCommand(joinCommand(args))
+		NoError(t, err)
+		Equal(t, args, split, "expecting joinCommand to be undone by splitCommand")
+	}
+}
+
+func TestExpandAliases(t *testing.T) {
+	aliases := map[string][]string{
+		"revise": {"get", "-p", "school", "--tag", "@?ankify", "ankify"},
+		"school": {"revise"},
+		"loop":   {"loop"},
+		"get":    {"ls"},
+	}
+
+	cases := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"revise"}, []string{"get", "-p", "school", "--tag", "@?ankify", "ankify"}},
+		{[]string{"revise", "--exclude-missing"}, []string{"get", "-p", "school", "--tag", "@?ankify", "ankify", "--exclude-missing"}},
+		{[]string{"--store", "thesis", "-l", "Revise"}, []string{"--store", "thesis", "-l", "get", "-p", "school", "--tag", "@?ankify", "ankify"}},
+		{[]string{"--store=revise", "school"}, []string{"--store=revise", "get", "-p", "school", "--tag", "@?ankify", "ankify"}},
+		{[]string{"get", "revise"}, []string{"get", "revise"}},
+		{[]string{"--store", "revise"}, []string{"--store", "revise"}},
+		{[]string{}, []string{}},
+	}
+
+	for _, tc := range cases {
+		expanded, err := expandAliases(tc.args, aliases)
+		NoError(t, err)
+		Equal(t, tc.expected, expanded, "expanding %v", tc.args)
+	}
+
+	_, err := expandAliases([]string{"loop"}, aliases)
+	Error(t, err, "expecting error for an alias which refers to itself")
+
+	Equal(t, "other.yaml", configFlag([]string{"--config", "other.yaml", "revise"}))
+	Equal(t, "other.yaml", configFlag([]string{"--config=other.yaml", "revise"}))
+	Equal(t, "", configFlag([]string{"revise", "--config", "other.yaml"}))
+}
+
+func TestParseAliases(t *testing.T) {
+	aliases, err := parseAliases(map[string]interface{}{
+		"revise": "get -p school ankify",
+		"pizza":  []interface{}{"get", "--title", "Pizza Margherita"},
+	})
+	NoError(t, err)
+	Equal(t, map[string][]string{
+		"revise": {"get", "-p", "school", "ankify"},
+		"pizza":  {"get", "--title", "Pizza Margherita"},
+	}, aliases)
+
+	_, err = parseAliases(map[string]interface{}{"revise": 1})
+	Error(t, err)
+
+	_, err = parseAliases("revise")
+	Error(t, err)
+}
+
+func TestUpdateAliasConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "albatross-alias-test")
+	if err != nil {
+		t.Fatalf("couldn't create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "config.yaml")
+
+	err = ioutil.WriteFile(configFile, []byte("# My stores.\ndefault:\n  path: /notes\n"), 0644)
+	if err != nil {
+		t.Fatalf("couldn't write config: %s", err)
+	}
+
+	found, err := updateAliasConfig(configFile, "revise", "get -p school", false)
+	NoError(t, err)
+	False(t, found)
+
+	found, err = updateAliasConfig(configFile, "today", "get --from today ls", false)
+	NoError(t, err)
+	False(t, found)
+
+	found, err = updateAliasConfig(configFile, "revise", "get -p school ankify", false)
+	NoError(t, err)
+	True(t, found)
+
+	Equal(t, `# My stores.
+default:
+  path: /notes
+aliases:
+  revise: "get -p school ankify"
+  today: "get --from today ls"
+`, mustReadFile(t, configFile))
+
+	found, err = updateAliasConfig(configFile, "revise", "", true)
+	NoError(t, err)
+	True(t, found)
+
+	found, err = updateAliasConfig(configFile, "revise", "", true)
+	NoError(t, err)
+	False(t, found, "expecting removing a missing alias not to find it")
+
+	Equal(t, "# My stores.\ndefault:\n  path: /notes\naliases:\n  today: \"get --from today ls\"\n", mustReadFile(t, configFile))
+
+	aliases, err := loadAliases(configFile)
+	NoError(t, err)
+	Equal(t, map[string][]string{"today": {"get", "--from", "today", "ls"}}, aliases)
+}
@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+)
+
+// AttachmentsCmd represents the attachments command.
+var AttachmentsCmd = &cobra.Command{
+	Use:   "attachments",
+	Short: "manage the attachments of many entries at once",
+	Long: `attachments has commands for working with the attachments of many entries at once. To attach a file to a single
+entry, use the server or put it in the entry's folder and commit it.
+
+	$ albatross attachments import ~/Pictures/trip --match-path 'journal/2020/07/*'
+
+See 'albatross attachments [command] --help' for each command.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// AttachmentsImportCmd represents the 'attachments import' command.
+var AttachmentsImportCmd = &cobra.Command{
+	Use:   "import [folder]",
+	Short: "attach the files in a folder to the entries on the same day",
+	Long: `import attaches each file in a folder to the entry from the same day, such as for adding the photos from a trip
+to an existing journal:
+
+	$ albatross attachments import ~/Pictures/trip --match-path 'journal/2020/07/*' --dry-run
+	IMG_0412.jpg  2020-07-14 09:12  exif   journal/2020/07/14
+	IMG_0413.jpg  2020-07-14 18:40  exif   journal/2020/07/14
+	IMG_0502.jpg  2020-07-21 11:03  mtime  skipped: no entry on 2020-07-21
+	Would attach 2 files to 1 entry.
+
+Files in folders inside the folder are included, but hidden files are skipped. To only include some files, give
+--include one or more patterns for their names, like '*.jpg'.
+
+The date of each file is given by --by:
+
+	exif-date  The date the photo was taken, from its EXIF metadata, or else when the file was last modified. EXIF
+	           metadata is read from JPEG and TIFF files, which includes most camera raw formats. This is the default.
+	mtime      When the file was last modified.
+
+Each file is attached to the entry with a date on the same day. If there's more than one, the closest in time is used.
+Photos don't usually record the time zone they were taken in, so dates are compared as they'd be shown on a clock.
+
+The entries a file can be attached to are given by --match-path, which is a path or a pattern where "*" stands for
+anything, and --match-query, which is an expression in the same query language as 'albatross get --query'. Without
+either, files can be attached to any entry.
+
+Files are skipped if there isn't an entry on the same day, or if the entry already has an attachment with the same name.
+It's worth using --dry-run first to check the plan, and --json prints the plan as JSON. All the files are attached in a
+single commit.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Expecting exactly one argument: the folder of files to attach")
+			fmt.Println("For example:")
+			fmt.Println("")
+			fmt.Println("$ albatross attachments import ~/Pictures/trip --match-path 'journal/2020/07/*'")
+			os.Exit(1)
+		}
+
+		matchPaths, err := cmd.Flags().GetStringSlice("match-path")
+		checkArg(err)
+
+		matchQuery, err := cmd.Flags().GetString("match-query")
+		checkArg(err)
+
+		by, err := cmd.Flags().GetString("by")
+		checkArg(err)
+
+		include, err := cmd.Flags().GetStringSlice("include")
+		checkArg(err)
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		checkArg(err)
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		query := entries.Query{Expression: matchQuery}
+
+		if len(matchPaths) != 0 {
+			terms := []string{}
+			for _, path := range matchPaths {
+				terms = append(terms, "path:"+strconv.Quote(path))
+			}
+
+			if query.Expression != "" {
+				query.Expression = "(" + query.Expression + ") AND "
+			}
+
+			query.Expression += "(" + strings.Join(terms, " OR ") + ")"
+		}
+
+		err = query.Validate()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		files, err := attachmentFiles(args[0], include)
+		if err != nil {
+			fmt.Printf("Couldn't read folder %s:\n", args[0])
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		collection, err := store.Collection()
+		if err != nil {
+			log.Fatalf("Couldn't get entries: %s", err)
+		}
+
+		filtered, err := collection.Filter(query.Filter())
+		if err != nil {
+			log.Fatalf("Couldn't filter entries: %s", err)
+		}
+
+		plan, err := store.PlanAttachmentImport(filtered.List(), files, by)
+		if err != nil {
+			fmt.Println("Couldn't plan which entries to attach the files to:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if outputJSON {
+			out, err := json.Marshal(plan)
+			if err != nil {
+				fmt.Println("Error marshalling plan:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+		} else {
+			printAttachmentPlan(args[0], plan)
+		}
+
+		if !dryRun {
+			err = store.ImportAttachments(plan)
+			if err != nil {
+				fmt.Println("Couldn't attach files:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		if outputJSON {
+			return
+		}
+
+		attached, entryPaths := 0, map[string]bool{}
+		for _, planned := range plan {
+			if planned.Entry != "" && planned.Skip == "" {
+				attached++
+				entryPaths[planned.Entry] = true
+			}
+		}
+
+		verb := "Attached"
+		if dryRun {
+			verb = "Would attach"
+		}
+
+		fmt.Printf("%s %s to %s.\n", verb, plural(attached, "file"), plural(len(entryPaths), "entry"))
+	},
+}
+
+// attachmentFiles returns the files inside a folder, skipping hidden files and folders. If include isn't empty, only
+// files whose names match one of the patterns in it are returned.
+func attachmentFiles(dir string, include []string) ([]string, error) {
+	files := []string{}
+
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if file != dir && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		if len(include) != 0 {
+			matched := false
+			for _, pattern := range include {
+				if ok, _ := filepath.Match(pattern, info.Name()); ok {
+					matched = true
+					break
+				}
+			}
+
+			if !matched {
+				return nil
+			}
+		}
+
+		files = append(files, file)
+		return nil
+	})
+
+	return files, err
+}
+
+// printAttachmentPlan prints each file in the plan along with its date and the entry it'll be attached to, or why it
+// won't be.
+func printAttachmentPlan(dir string, plan []albatross.PlannedAttachment) {
+	names := make([]string, len(plan))
+	width := 0
+
+	for i, planned := range plan {
+		name, err := filepath.Rel(dir, planned.File)
+		if err != nil {
+			name = planned.File
+		}
+
+		names[i] = name
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+
+	for i, planned := range plan {
+		target := planned.Entry
+		if planned.Skip != "" {
+			target = "skipped: " + planned.Skip
+		}
+
+		fmt.Printf("%-*s  %s  %-5s  %s\n", width, names[i], planned.Date.Format("2006-01-02 15:04"), planned.DateSource, target)
+	}
+}
+
+// plural returns the count along with the noun, made plural if the count isn't one.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+
+	if strings.HasSuffix(noun, "y") {
+		return fmt.Sprintf("%d %sies", n, strings.TrimSuffix(noun, "y"))
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func init() {
+	rootCmd.AddCommand(AttachmentsCmd)
+	AttachmentsCmd.AddCommand(AttachmentsImportCmd)
+
+	AttachmentsImportCmd.Flags().StringSlice("match-path", []string{}, "only attach files to entries at these paths, where '*' stands for anything")
+	AttachmentsImportCmd.Flags().String("match-query", "", "only attach files to entries matching this query language expression, see 'albatross get --help'")
+	AttachmentsImportCmd.Flags().String("by", albatross.AttachByExifDate, "how to date each file, 'exif-date' or 'mtime'")
+	AttachmentsImportCmd.Flags().StringSlice("include", []string{}, "only attach files whose names match one of these patterns, like '*.jpg'")
+	AttachmentsImportCmd.Flags().Bool("dry-run", false, "print which entries the files would be attached to without attaching them")
+	AttachmentsImportCmd.Flags().Bool("json", false, "print the plan as JSON")
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// AuditCmd represents the audit command.
+var AuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "show the audit log of changes made to the store",
+	Long: `audit displays the audit log for a store.
+
+Every operation which modifies the store, such as creating, updating or deleting an entry, or encrypting and decrypting
+the store, is recorded in an append-only log located at '.albatross/audit.log' in the store directory. This is
+independent of git, so the history of what tooling did to the store is still available when using --disable-git.
+
+	$ albatross audit
+	2020-11-01 14:02  create   ok     food/pizza                 (albatross create food/pizza)
+	2020-11-01 14:05  update   ok     food/pizza                 (albatross get -p food/pizza update)
+
+You can restrict the output to recent events using the --since flag. It accepts durations like "7d", "2w" or
+"36h", days like "yesterday" or "last week", as well as dates in the format given by --date-format:
+
+	$ albatross audit --since 7d
+	$ albatross audit --since yesterday
+	$ albatross audit --since "2020-11-01 00:00"
+
+To get the raw events as JSON, use the --json flag.
+
+Note that the audit log is not encrypted along with the store's entries, so the paths of entries that have been
+modified are visible while the store is encrypted.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		sinceStr, err := cmd.Flags().GetString("since")
+		checkArg(err)
+
+		dateFormat, err := cmd.Flags().GetString("date-format")
+		checkArg(err)
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		var since time.Time
+
+		if sinceStr != "" {
+			since, err = parseSince(sinceStr, dateFormat, time.Now())
+			if err != nil {
+				log.Fatalf("Can't parse --since value %q: %s", sinceStr, err)
+			}
+		}
+
+		events, err := store.AuditLog(since)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if outputJSON {
+			out, err := json.Marshal(events)
+			if err != nil {
+				fmt.Println("Error marshalling events:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		for _, event := range events {
+			result := event.Result
+			if event.Error != "" {
+				result += ": " + event.Error
+			}
+
+			fmt.Printf(
+				"%s  %-8s %-6s %-26s (%s)\n",
+				event.Time.Format("2006-01-02 15:04"),
+				event.Operation,
+				result,
+				strings.Join(event.Paths, ", "),
+				event.Command,
+			)
+		}
+	},
+}
+
+// parseSince converts a string like "7d", "2w", "36h", "yesterday" or a date in the given format into the point in time
+// it refers to, relative to now. For spans of time like "yesterday", it's the start of the span.
+func parseSince(since, dateFormat string, now time.Time) (time.Time, error) {
+	date, err := entries.ParseDate(since, dateFormat, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return date.Start, nil
+}
+
+func init() {
+	rootCmd.AddCommand(AuditCmd)
+
+	AuditCmd.Flags().String("since", "", "only show events after this, a duration like '7d' or a date")
+	AuditCmd.Flags().String("date-format", "2006-01-02 15:04", "date format for parsing --since")
+	AuditCmd.Flags().Bool("json", false, "output events as JSON")
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// CommitCmd represents the commit command.
+var CommitCmd = &cobra.Command{
+	Use:   "commit",
+	Short: "commit outstanding changes to the store",
+	Long: `commit stages and commits every change in the store which hasn't been committed.
+
+Changes can build up without being committed when git is disabled using --disable-git, or when entries are edited
+outside of albatross. 'albatross status' shows these changes.
+
+	$ albatross commit -m "Tidy up recipes"
+	Committed changes to 2 entries:
+	    food/pizza
+	    food/ice-cream
+
+The commit message is followed by a list of the entries affected. If no message is given, a summary of the number
+of entries is used instead.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		message, err := cmd.Flags().GetString("message")
+		checkArg(err)
+
+		if !store.UsingGit() {
+			fmt.Printf("Store '%s' not using Git.\n", storeName)
+			os.Exit(0)
+		}
+
+		affected, err := store.CommitChanges(message)
+		if err != nil {
+			log.Fatalf("Couldn't commit changes: %s", err)
+		}
+
+		if len(affected) == 0 {
+			fmt.Println("Nothing to commit.")
+			return
+		}
+
+		fmt.Printf("Committed changes to %d entries:\n", len(affected))
+		for _, path := range affected {
+			fmt.Printf("    %s\n", path)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(CommitCmd)
+
+	CommitCmd.Flags().StringP("message", "m", "", "commit message")
+}
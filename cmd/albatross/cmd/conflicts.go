@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+)
+
+// ConflictsCmd represents the conflicts command.
+var ConflictsCmd = &cobra.Command{
+	Use:   "conflicts",
+	Short: "list and resolve conflicting versions of entries",
+	Long: `conflicts lists entries which have conflicting versions, such as when an entry was changed on two machines
+before they were synced.
+
+	$ albatross conflicts
+	food/pizza  food/pizza.conflict-laptop-20201101-150405
+
+Conflicts are kept as entries next to the original, named '<path>.conflict-<host>-<date>', so both versions can be
+read and searched like any other entry until they're resolved. To turn the conflicts left by syncing into these
+entries, use 'albatross conflicts capture'. To resolve them, use 'albatross conflicts resolve'.
+
+To get the conflicts as JSON, use the --json flag.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		conflicts, err := store.Conflicts()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if outputJSON {
+			out, err := json.Marshal(conflicts)
+			if err != nil {
+				fmt.Println("Error marshalling conflicts:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		for _, conflict := range conflicts {
+			fmt.Printf("%s  %s\n", conflict.Path, conflict.ConflictPath)
+		}
+	},
+}
+
+// ConflictsCaptureCmd represents the 'conflicts capture' command.
+var ConflictsCaptureCmd = &cobra.Command{
+	Use:   "capture",
+	Short: "move conflicting versions of entries into their own entries",
+	Long: `capture finds conflicts left by syncing and moves each conflicting version into its own entry.
+
+Two kinds of conflict are found:
+
+- entry.md files containing git conflict markers, such as after 'albatross git pull'. The local version is kept in
+  place and the incoming version becomes the conflict entry.
+- Conflicted copies made by file sync tools, such as 'entry.sync-conflict-....md' from Syncthing or
+  'entry (... conflicted copy).md' from Dropbox. The copy becomes the conflict entry.
+
+	$ albatross git pull
+	CONFLICT (content): Merge conflict in food/pizza/entry.md
+	$ albatross conflicts capture
+	Captured 1 conflict(s):
+	    food/pizza -> food/pizza.conflict-laptop-20201101-150405
+
+If a merge was stopped by the conflicts, the captured entries are committed as a merge commit, which finishes the
+merge. Conflicts in files other than entries have to be resolved using git first.
+
+The new entries are named using the hostname of this machine, which can be changed with --host.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		host, err := cmd.Flags().GetString("host")
+		checkArg(err)
+
+		if host == "" {
+			host, err = os.Hostname()
+			if err != nil {
+				log.Fatalf("Couldn't get hostname, use --host instead: %s", err)
+			}
+		}
+
+		captured, err := store.CaptureConflicts(host)
+		if err != nil {
+			log.Fatalf("Couldn't capture conflicts: %s", err)
+		}
+
+		if len(captured) == 0 {
+			fmt.Println("No conflicts found.")
+			return
+		}
+
+		fmt.Printf("Captured %d conflict(s):\n", len(captured))
+		for _, conflict := range captured {
+			fmt.Printf("    %s -> %s\n", conflict.Path, conflict.ConflictPath)
+		}
+	},
+}
+
+// ConflictsResolveCmd represents the 'conflicts resolve' command.
+var ConflictsResolveCmd = &cobra.Command{
+	Use:   "resolve [path]",
+	Short: "interactively resolve conflicts",
+	Long: `resolve goes through each conflict and asks which version to keep.
+
+	$ albatross conflicts resolve
+	food/pizza has a conflicting version food/pizza.conflict-laptop-20201101-150405
+	...
+	Keep [m]ine, [t]heirs, [e]dit both, or [s]kip?
+
+'Mine' keeps the original entry, 'theirs' replaces it with the conflicting version and 'edit' opens both versions in
+your editor, separated by conflict markers, to be combined by hand. Either way, the conflict entry is removed
+afterwards. Attachments which only exist in the conflicting version are kept.
+
+To only resolve conflicts for a single entry, give its path:
+
+	$ albatross conflicts resolve food/pizza`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		editorName, err := cmd.Flags().GetString("editor")
+		checkArg(err)
+
+		conflicts, err := store.Conflicts()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+
+		for _, conflict := range conflicts {
+			if len(args) > 0 && conflict.Path != args[0] && conflict.ConflictPath != args[0] {
+				continue
+			}
+
+			original, conflicting, err := store.ReadConflict(conflict)
+			if err != nil {
+				log.Fatalf("Couldn't read conflict %s: %s", conflict.ConflictPath, err)
+			}
+
+			fmt.Printf("%s has a conflicting version %s\n\n", conflict.Path, conflict.ConflictPath)
+			fmt.Printf("--- Mine (%s)\n%s\n", conflict.Path, original)
+			fmt.Printf("--- Theirs (%s)\n%s\n", conflict.ConflictPath, conflicting)
+
+			contents, ok := promptResolution(reader, editorName, conflict, original, conflicting)
+			if !ok {
+				fmt.Println("Skipped.")
+				continue
+			}
+
+			err = store.ResolveConflict(conflict, contents)
+			if err != nil {
+				log.Fatalf("Couldn't resolve conflict %s: %s", conflict.ConflictPath, err)
+			}
+
+			fmt.Println("Resolved", conflict.Path)
+		}
+	},
+}
+
+// promptResolution asks how a conflict should be resolved and returns the contents the entry should have. ok is false
+// if the conflict was skipped.
+func promptResolution(reader *bufio.Reader, editorName string, conflict albatross.Conflict, original, conflicting string) (contents string, ok bool) {
+	for {
+		fmt.Print("Keep [m]ine, [t]heirs, [e]dit both, or [s]kip? ")
+
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			return "", false
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "m", "mine":
+			return original, true
+		case "t", "theirs":
+			return conflicting, true
+		case "e", "edit":
+			withNewline := func(s string) string {
+				if strings.HasSuffix(s, "\n") {
+					return s
+				}
+
+				return s + "\n"
+			}
+
+			combined := fmt.Sprintf(
+				"<<<<<<< %s\n%s=======\n%s>>>>>>> %s\n",
+				conflict.Path, withNewline(original), withNewline(conflicting), conflict.ConflictPath,
+			)
+
+			edited, err := edit(editorName, combined)
+			if err != nil {
+				log.Fatal("Couldn't get content from editor: ", err)
+			}
+
+			return edited, true
+		case "s", "skip":
+			return "", false
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(ConflictsCmd)
+	ConflictsCmd.AddCommand(ConflictsCaptureCmd)
+	ConflictsCmd.AddCommand(ConflictsResolveCmd)
+
+	ConflictsCmd.Flags().Bool("json", false, "output conflicts as JSON")
+	ConflictsCaptureCmd.Flags().String("host", "", "name to use for this machine in conflict entries, default is the hostname")
+	ConflictsResolveCmd.Flags().StringP("editor", "e", getEditor("vim"), "Editor to use (defaults to $EDITOR, then vim)")
+}
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/Masterminds/sprig"
-	"github.com/sirupsen/logrus"
 
 	"github.com/spf13/cobra"
 )
@@ -63,13 +62,62 @@ As a context for the template, you can pass key values with the -c flag:
 
 	$ albatross create logs/exercise/2020/08/30 -t exercise -c distance=3.24 -c pace=7:47
 
-.date, as shown above, is set automatically to the current time. Sprig (https://github.com/Masterminds/sprig) helper
+.date, as shown above, is set automatically to the current time. Values which are the same for many entries, like
+your name, can be set once in the 'variables' section of the store's config and used as .Store.Vars:
+
+	variables:
+	  author: Jane Doe
+	  school-year: 12
+
+	(essay.tmpl)
+	---
+	title: 'Essay'
+	author: '<(.Store.Vars.author)>'
+	year: <(index .Store.Vars "school-year")>
+	---
+
+Keys are always in lower case, like the rest of the config. The same values are available to shortcodes and to
+'albatross get template'. Sprig (https://github.com/Masterminds/sprig) helper
 functions/pipelines are available, such as:
 
 	- date
 	- toJSON
 	- upper
 
+Metadata can be added to every entry created under a path using 'default-metadata' in the store's config. Keys which
+the template already sets are left alone, and where more than one path matches, the longest wins:
+
+	default-metadata:
+	  - path: work
+	    metadata:
+	      project: acme
+	  - path: journal
+	    metadata:
+	      type: journal
+
+Use 'albatross lint' to find entries which are missing their default metadata.
+
+Rather than making up a path for every entry, it can be made from the title using --from-title:
+
+	$ albatross create --from-title "My Great Idea"
+	Successfully created entry my-great-idea
+
+Where the entry goes is set by 'create.path-pattern' in the store's config, or for a single entry by --path-pattern.
+It uses the same syntax as templates, with .title, .slug (the title in lower case, with hyphens) and .date:
+
+	create:
+	  path-pattern: 'ideas/<(.date | date "2006")>/<(.slug)>'
+
+If there's already something at the path, a number is added to the end, like "ideas/2020/my-great-idea-2". To see the
+path which would be used without creating anything, use --print-path:
+
+	$ albatross create --from-title "My Great Idea" --print-path
+	ideas/2020/my-great-idea
+
+Like 'albatross get update', the entry is checked for problems after the editor is closed, such as front matter which
+doesn't parse or links to entries which don't exist. You can choose to edit it again, save it anyway or discard it, which
+removes the new entry. To skip the checks, use --no-check.
+
 The default template is:
 
 	---
@@ -104,17 +152,58 @@ The default template is:
 		contextStrings, err := cmd.Flags().GetStringToString("context")
 		checkArg(err)
 
+		fromTitle, err := cmd.Flags().GetString("from-title")
+		checkArg(err)
+
+		pathPattern, err := cmd.Flags().GetString("path-pattern")
+		checkArg(err)
+
+		printPath, err := cmd.Flags().GetBool("print-path")
+		checkArg(err)
+
+		noCheck, err := cmd.Flags().GetBool("no-check")
+		checkArg(err)
+
+		if fromTitle != "" {
+			if len(args) != 0 {
+				fmt.Println("Expecting no arguments when using --from-title, since the path comes from the title:")
+				fmt.Println("")
+				fmt.Println("$ albatross create --from-title \"My Great Idea\"")
+				os.Exit(1)
+			}
+
+			path, err := store.PathFromTitle(pathPattern, fromTitle, time.Now())
+			if err != nil {
+				fmt.Println("Couldn't make a path from the title:", err)
+				os.Exit(1)
+			}
+
+			args = []string{path, fromTitle}
+		}
+
 		if len(args) == 0 {
 			fmt.Println("Expecting exactly one or more arguments: path to entry and optional title")
 			fmt.Println("For example:")
 			fmt.Println("")
 			fmt.Println("$ albatross create food/pizza Pizza")
+			os.Exit(1)
+		}
+
+		if printPath {
+			fmt.Println(args[0])
+			return
 		}
 
 		contextStrings["title"] = strings.Join(args[1:], " ")
 
 		contents := getTemplate(templateFile, contextStrings)
 
+		// The default metadata is added now rather than only by Create so that it shows up in the editor.
+		contents, err = store.ApplyDefaultMetadata(args[0], contents)
+		if err != nil {
+			log.Fatal("Couldn't add default metadata to entry: ", err)
+		}
+
 		// Here we create an empty entry first, then update it.
 		// This means that an error like "EntryAlreadyExists" will come up now rather than
 		// after the entry has been created, which could lead to data loss and be frustrating in general.
@@ -123,24 +212,22 @@ The default template is:
 			log.Fatal("Couldn't create entry: ", err)
 		}
 
-		content, err := edit(editorName, contents)
-		if err != nil {
+		content, err := editChecked(editorName, args[0], contents, !noCheck, false)
+		if err == errEditDiscarded {
+			err = store.Delete(args[0])
+			if err != nil {
+				log.Fatal("Couldn't remove discarded entry: ", err)
+			}
+
+			fmt.Println("Discarded new entry", args[0])
+			return
+		} else if err != nil {
 			log.Fatal("Couldn't get content from editor: ", err)
 		}
 
 		err = store.Update(args[0], content)
 		if err != nil {
-			f, err := ioutil.TempFile("", "albatross-recover")
-			if err != nil {
-				logrus.Fatal("Couldn't get create temporary file to save recovery entry to. You're on your own! ", err)
-			}
-
-			_, err = f.Write([]byte(content))
-			if err != nil {
-				logrus.Fatal("Error writing to temporary file to save recovery entry to. You're on your own! ", err)
-			}
-
-			fmt.Println("Error creating entry. A copy has been saved to:", f.Name())
+			saveRecovery(args[0], contents, content, "Error creating entry.")
 			os.Exit(1)
 		}
 
@@ -149,12 +236,18 @@ The default template is:
 }
 
 func getTemplate(name string, contextStrings map[string]string) string {
+	return getTemplateAt(name, contextStrings, time.Now())
+}
+
+// getTemplateAt is like getTemplate, but sets .date to the time given rather than now.
+func getTemplateAt(name string, contextStrings map[string]string, date time.Time) string {
 	var context = make(map[string]interface{})
 	for k, v := range contextStrings {
 		context[k] = v
 	}
 
-	context["date"] = time.Now()
+	context["date"] = date
+	context["Store"] = store.TemplateStore()
 
 	templates, err := ioutil.ReadDir(filepath.Join(storePath, "templates"))
 	if err != nil && name != "" {
@@ -166,6 +259,10 @@ func getTemplate(name string, contextStrings map[string]string) string {
 
 	if name != "" {
 		for _, info := range templates {
+			if info.IsDir() {
+				continue
+			}
+
 			templateName := strings.TrimSuffix(info.Name(), filepath.Ext(info.Name()))
 
 			if templateName == name {
@@ -207,4 +304,8 @@ func init() {
 	CreateCmd.Flags().StringP("editor", "e", "", "Editor to use (defaults to $EDITOR, then vim)")
 	CreateCmd.Flags().StringP("template", "t", "", "Template file to use")
 	CreateCmd.Flags().StringToStringP("context", "c", map[string]string{}, "Context for template")
+	CreateCmd.Flags().String("from-title", "", "create the entry with this title, at a path made from it using the path pattern")
+	CreateCmd.Flags().String("path-pattern", "", "pattern for the path used by --from-title, instead of 'create.path-pattern' in the config")
+	CreateCmd.Flags().Bool("print-path", false, "print the path the entry would be created at without creating it")
+	CreateCmd.Flags().Bool("no-check", false, "don't check the entry for problems before saving it")
 }
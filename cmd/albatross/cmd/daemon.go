@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/albatross-org/go-albatross/daemon"
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// DaemonCmd represents the daemon command.
+var DaemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "keep the store in memory between commands",
+	Long: `daemon keeps the store parsed, and optionally decrypted, in memory so that other commands don't have to do it
+every time they're run. This makes commands like 'get' much quicker on large or encrypted stores.
+
+	$ albatross daemon start &
+	$ albatross get -p food title    # Uses the entries held by the daemon.
+	$ albatross daemon stop
+
+While the daemon is running, commands which search entries use it automatically. It listens on a unix socket in the
+store's '.albatross' folder, which only the user who started it can connect to. To not use the daemon for a command,
+use the --no-daemon flag.
+
+The daemon runs until it's stopped, so it can be run in the background like above or by a service manager. Each store
+needs its own daemon, chosen using --store like any other command.
+
+Encrypted Stores
+----------------
+
+If the store is encrypted, 'daemon start' decrypts it when it starts and encrypts it again when it stops, unless
+--leave-decrypted is given. Alternatively, --keep-encrypted decrypts the entries into the daemon's memory only and
+leaves the store encrypted on disk:
+
+	$ albatross daemon start --keep-encrypted &
+
+Commands can then search the entries without decrypting the store, though commands which change entries, like
+'create' or 'get ... update', won't work until it's decrypted. Attachments aren't decrypted either.
+
+Changes
+-------
+
+The daemon reloads the entries whenever the audit log shows that they've been changed, such as by 'albatross create'
+(see 'albatross audit --help'). Changes made to the entries by hand or by other programs, like 'git pull', aren't
+noticed, so the daemon needs to be told to reload:
+
+	$ albatross daemon reload`,
+}
+
+// DaemonStartCmd represents the daemon start command.
+var DaemonStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "start the daemon",
+	Long: `start starts the daemon for the store and runs until it's stopped, either by 'albatross daemon stop' or by
+interrupting it. See 'albatross daemon --help' for more information.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		keepEncrypted, err := cmd.Flags().GetBool("keep-encrypted")
+		checkArg(err)
+
+		if daemonClient != nil {
+			fmt.Printf("A daemon is already running for store '%s'.\n", storeName)
+			os.Exit(1)
+		}
+
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		load := store.Collection
+
+		if encrypted && keepEncrypted {
+			load = decryptCollectionLoader()
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		d, err := daemon.NewDaemon(store, load, encrypted && keepEncrypted)
+		if err != nil {
+			log.Fatalf("Couldn't load entries: %s", err)
+		}
+
+		listener, err := daemon.Listen(storePath)
+		if err != nil {
+			log.Fatalf("Couldn't start daemon: %s", err)
+		}
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+		go func() {
+			<-signals
+			d.Stop()
+		}()
+
+		fmt.Printf("Daemon for store '%s' listening on %s\n", storeName, daemon.SocketPath(storePath))
+
+		err = d.Serve(listener)
+		if err != nil {
+			log.Errorf("Error serving daemon: %s", err)
+		}
+
+		fmt.Println("Daemon stopped.")
+	},
+}
+
+// decryptCollectionLoader prompts for the store's password and decrypts the entries into memory, returning a function
+// which gives the entries the first time it's called and decrypts them again using the same password afterwards.
+func decryptCollectionLoader() func() (*entries.Collection, error) {
+	var collection *entries.Collection
+
+	password, err := unlock(func(passwordFunc func() (string, error)) error {
+		var err error
+		collection, err = store.DecryptCollection(passwordFunc)
+		return err
+	})
+	if err != nil {
+		log.Fatalf("Couldn't decrypt entries: %s", err)
+	}
+
+	return func() (*entries.Collection, error) {
+		if collection != nil {
+			decrypted := collection
+			collection = nil
+			return decrypted, nil
+		}
+
+		return store.DecryptCollection(func() (string, error) { return password, nil })
+	}
+}
+
+// DaemonStopCmd represents the daemon stop command.
+var DaemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "stop the daemon",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := requireDaemon()
+
+		err := client.Stop()
+		if err != nil {
+			log.Fatalf("Couldn't stop daemon: %s", err)
+		}
+
+		fmt.Printf("Stopped daemon for store '%s'.\n", storeName)
+	},
+}
+
+// DaemonReloadCmd represents the daemon reload command.
+var DaemonReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "make the daemon read the entries again",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := requireDaemon()
+
+		err := client.Reload()
+		if err != nil {
+			log.Fatalf("Couldn't reload daemon: %s", err)
+		}
+	},
+}
+
+// DaemonStatusCmd represents the daemon status command.
+var DaemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show whether the daemon is running",
+	Run: func(cmd *cobra.Command, args []string) {
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		client := requireDaemon()
+
+		status, err := client.Status()
+		if err != nil {
+			log.Fatalf("Couldn't get daemon status: %s", err)
+		}
+
+		if outputJSON {
+			out, err := json.Marshal(status)
+			if err != nil {
+				fmt.Println("Error marshalling status:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		fmt.Printf("Daemon for store '%s':\n", storeName)
+		fmt.Println("  PID:", status.PID)
+		fmt.Println("  Entries:", status.Entries)
+		fmt.Println("  Kept encrypted:", status.Encrypted)
+		fmt.Println("  Started:", status.Started.Format("2006-01-02 15:04:05"))
+		fmt.Println("  Loaded:", status.Loaded.Format("2006-01-02 15:04:05"), fmt.Sprintf("(%s ago)", time.Since(status.Loaded).Round(time.Second)))
+	},
+}
+
+// requireDaemon returns the client for the running daemon, or exits if there isn't one.
+func requireDaemon() *daemon.Client {
+	if daemonClient == nil {
+		fmt.Printf("No daemon running for store '%s'.\n", storeName)
+		os.Exit(1)
+	}
+
+	return daemonClient
+}
+
+func init() {
+	rootCmd.AddCommand(DaemonCmd)
+
+	DaemonCmd.AddCommand(DaemonStartCmd)
+	DaemonCmd.AddCommand(DaemonStopCmd)
+	DaemonCmd.AddCommand(DaemonReloadCmd)
+	DaemonCmd.AddCommand(DaemonStatusCmd)
+
+	DaemonStartCmd.Flags().Bool("keep-encrypted", false, "decrypt the entries into memory only, leaving the store encrypted on disk")
+	DaemonStatusCmd.Flags().Bool("json", false, "output status as JSON")
+}
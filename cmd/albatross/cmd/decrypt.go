@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/albatross-org/go-albatross/encryption"
@@ -16,46 +17,125 @@ import (
 var DecryptCmd = &cobra.Command{
 	Use:   "decrypt",
 	Short: "decrypt an albatross store",
-	Long:  `decrypt will decrypt an albatross store`,
+	Long: `decrypt will decrypt an albatross store.
+
+To avoid typing the passphrase every time, it can be saved in the system keyring (the Keychain on macOS, libsecret on
+Linux using 'secret-tool' and the Credential Manager on Windows) using --save-passphrase:
+
+	$ albatross decrypt --save-passphrase
+
+From then on, any command which needs to decrypt the store, including ones run by scheduled jobs, uses the saved
+passphrase instead of prompting for it. If the saved passphrase stops working, you're prompted as normal. To remove the
+saved passphrase, use:
+
+	$ albatross keys forget
+
+Note that on macOS, the passphrase is briefly visible to other processes as an argument to the 'security' command while
+it's being saved.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		decryptStore()
+		savePassphrase, err := cmd.Flags().GetBool("save-passphrase")
+		checkArg(err)
+
+		password := decryptStorePassword()
+
+		if savePassphrase && password != "" {
+			err = encryption.NewKeyring().Save(keyringAccount(), password)
+			if err != nil {
+				fmt.Println("Couldn't save passphrase in the system keyring:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println("Saved passphrase in the system keyring.")
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(DecryptCmd)
+
+	DecryptCmd.Flags().Bool("save-passphrase", false, "save the passphrase in the system keyring so it isn't asked for again")
+}
+
+// keyringAccount returns the account the store's passphrase is saved under in the system keyring, the absolute path
+// to the store.
+func keyringAccount() string {
+	path, err := filepath.Abs(storePath)
+	if err != nil {
+		return storePath
+	}
+
+	return path
 }
 
 // decryptStore is a utility function for decrypting the store, asking for a password three times.
 // It will exit if authentication fails three times.
 func decryptStore() {
-	var failCount int
+	decryptStorePassword()
+}
+
+// decryptStorePassword decrypts the store like decryptStore and returns the password which worked. If the store was
+// already decrypted, it returns "".
+func decryptStorePassword() string {
 	var start time.Time
 
 	fmt.Println("Decrypting...")
 
+	password, err := unlock(func(passwordFunc func() (string, error)) error {
+		return store.Decrypt(func() (string, error) {
+			password, err := passwordFunc()
+			start = time.Now()
+			return password, err
+		})
+	})
+
+	if _, ok := err.(albatross.ErrStoreDecrypted); ok {
+		fmt.Printf("Store '%s' is already decrypted.\n", storeName)
+		return ""
+	} else if _, ok := err.(encryption.ErrPrivateKeyDecryptionFailed); ok {
+		fmt.Println("Decryption failed three times. Exiting.")
+		os.Exit(1)
+	} else if err != nil {
+		logrus.Fatal(err)
+	}
+
+	fmt.Printf("Done in %s.\n", time.Since(start))
+
+	return password
+}
+
+// unlock calls decrypt with a password func, first giving it the password saved in the system keyring, if there is one,
+// and then prompting for a password up to three times for as long as decrypt fails because the password is wrong. It
+// returns the password which worked and the last error from decrypt.
+func unlock(decrypt func(passwordFunc func() (string, error)) error) (string, error) {
+	saved, err := encryption.NewKeyring().Load(keyringAccount())
+	if err == nil {
+		err = decrypt(func() (string, error) { return saved, nil })
+		if _, ok := err.(encryption.ErrPrivateKeyDecryptionFailed); !ok {
+			return saved, err
+		}
+
+		fmt.Printf("Saved passphrase is invalid, use 'albatross keys forget' to remove it.\n\n")
+	} else if _, ok := err.(encryption.ErrPasswordNotSaved); !ok {
+		log.Debugf("Couldn't load passphrase from system keyring: %s", err)
+	}
+
+	var password string
+
 	for i := 0; i < 3; i++ {
-		start = time.Now()
-		err := store.Decrypt(encryption.GetPassword)
+		err = decrypt(func() (string, error) {
+			var err error
+			password, err = encryption.GetPassword()
+			return password, err
+		})
 
 		if _, ok := err.(encryption.ErrPrivateKeyDecryptionFailed); ok {
 			fmt.Printf("Invalid password. Try again...\n\n")
-			failCount++
 			continue
-		} else if _, ok = err.(albatross.ErrStoreDecrypted); ok {
-			fmt.Printf("Store '%s' is already decrypted.\n", storeName)
-			break
-		} else if err != nil {
-			logrus.Fatal(err)
 		}
 
 		break
 	}
 
-	if failCount == 3 {
-		fmt.Println("Decryption failed three times. Exiting.")
-		os.Exit(1)
-	}
-
-	fmt.Printf("Done in %s.\n", time.Since(start))
+	return password, err
 }
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// DiffCmd represents the diff command.
+var DiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "show how entries have changed",
+	Long: `diff shows which entries have been added, removed or modified since a past git revision, followed by a
+unified diff of each changed entry.
+
+	$ albatross diff --since HEAD~5
+	added     food/truffles
+	modified  journal/2020-10-26
+
+	--- /dev/null
+	+++ b/food/truffles/entry.md
+	...
+
+The revision can be anything git understands, such as "HEAD~5", a branch name or a commit hash. By default the entries
+are compared against the current state of the store, including changes which haven't been committed. To compare two
+past revisions instead, use --until:
+
+	$ albatross diff --since HEAD~10 --until HEAD~5
+
+To only list the entries which changed, use --name-only. To get the changes as JSON, use the --json flag.
+
+This requires the store to be using git.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		since, err := cmd.Flags().GetString("since")
+		checkArg(err)
+
+		until, err := cmd.Flags().GetString("until")
+		checkArg(err)
+
+		nameOnly, err := cmd.Flags().GetBool("name-only")
+		checkArg(err)
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		old, err := store.CollectionAt(since)
+		if err != nil {
+			log.Fatalf("Couldn't get entries at %s: %s", since, err)
+		}
+
+		var new *entries.Collection
+		if until == "" {
+			new, err = store.Collection()
+		} else {
+			new, err = store.CollectionAt(until)
+		}
+		if err != nil {
+			log.Fatalf("Couldn't get entries: %s", err)
+		}
+
+		changes := entries.Diff(old, new)
+
+		if outputJSON {
+			out, err := json.Marshal(changes)
+			if err != nil {
+				fmt.Println("Error marshalling changes:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		for _, change := range changes {
+			fmt.Printf("%-8s  %s\n", change.Type, change.Path)
+		}
+
+		if nameOnly {
+			return
+		}
+
+		for _, change := range changes {
+			fmt.Println()
+			fmt.Print(change.Diff)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(DiffCmd)
+
+	DiffCmd.Flags().String("since", "HEAD", "git revision to compare against, such as HEAD~5")
+	DiffCmd.Flags().String("until", "", "git revision to compare with, defaults to the current state of the store")
+	DiffCmd.Flags().Bool("name-only", false, "only list the entries which changed")
+	DiffCmd.Flags().Bool("json", false, "output changes as JSON")
+}
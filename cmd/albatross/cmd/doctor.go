@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+// DoctorCmd represents the doctor command.
+var DoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "check the store for broken links, missing attachments and other problems",
+	Long: `doctor checks the integrity of the store as a whole and lists any problems it finds.
+
+	$ albatross doctor
+	notes/ideas    broken-link          link [[pizza!]] doesn't point to any entry (fix: change link to [[Pizza!]])
+	notes/ideas    missing-attachment   attachments/sketch.png is referred to but doesn't exist
+	food/pizza     duplicate-title      title "Pizza" is also used by recipes/pizza, so links to it are ambiguous
+
+The checks are:
+
+	broken-link          A link like [[Title]] or {{path}} doesn't point to any entry.
+	duplicate-title      More than one entry has the same title, so title links to them are ambiguous.
+	alias-collision      An alias from an entry's 'aliases' is the title or an alias of another entry too, so title
+	                     links using it are ambiguous.
+	missing-attachment   A Markdown link or image, like ![Sketch](attachments/sketch.png), points to a file in the
+	                     entry's folder which doesn't exist.
+	orphaned-attachment  A file in an entry's 'attachments/' folder isn't referred to anywhere in the entry.
+	dangling-symlink     A symlink in the entries folder points to something which doesn't exist.
+
+Entries which couldn't be loaded at all are also listed, using the same checks as 'albatross lint', such as
+'parse-failed'. For problems with the contents of individual entries, like secrets, see 'albatross lint'.
+
+Some problems can be repaired automatically, which is shown after them. Give --fix to repair them:
+
+	$ albatross doctor --fix
+	Fixed notes/ideas: change link to [[Pizza!]]
+
+The repairs are always safe: broken links are only changed when exactly one entry has a title or path which only
+differs from the link in case, and dangling symlinks are removed. Each repair is committed like any other change. Nothing
+else is changed, since the right fix for the other problems, like deleting an orphaned attachment, is up to you.
+
+If any problems are left, doctor exits with a status of 1. To get the problems as JSON, use the --json flag.
+
+For a report combining these problems with the ones found by 'albatross lint', orphaned entries, the state of git and
+statistics about the store, use 'albatross doctor report'.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		fix, err := cmd.Flags().GetBool("fix")
+		checkArg(err)
+
+		problems, err := store.Check()
+		if err != nil {
+			log.Fatalf("Couldn't check store: %s", err)
+		}
+
+		if fix {
+			fixed, err := store.Fix(problems)
+			for _, problem := range fixed {
+				fmt.Printf("Fixed %s: %s\n", problem.Path, problem.Fix)
+			}
+
+			if err != nil {
+				log.Fatalf("Couldn't fix problems: %s", err)
+			}
+
+			problems, err = store.Check()
+			if err != nil {
+				log.Fatalf("Couldn't check store: %s", err)
+			}
+		}
+
+		if outputJSON {
+			out, err := json.Marshal(problems)
+			if err != nil {
+				fmt.Println("Error marshalling problems:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+		} else {
+			printProblems(problems)
+		}
+
+		if len(problems) > 0 {
+			if !leaveDecrypted && encrypted {
+				encryptStore()
+			}
+
+			os.Exit(1)
+		}
+	},
+}
+
+// printProblems prints the problems found by 'doctor' in columns.
+func printProblems(problems []albatross.CheckProblem) {
+	pathWidth, checkWidth := 0, 0
+	for _, problem := range problems {
+		if len(problem.Path) > pathWidth {
+			pathWidth = len(problem.Path)
+		}
+
+		if len(problem.Check) > checkWidth {
+			checkWidth = len(problem.Check)
+		}
+	}
+
+	for _, problem := range problems {
+		path := problem.Path
+		if path == "" {
+			path = "-"
+		}
+
+		message := problem.Message
+		if problem.Fix != "" {
+			message += fmt.Sprintf(" (fix: %s)", problem.Fix)
+		}
+
+		fmt.Printf("%-*s  %-*s  %s\n", pathWidth, path, checkWidth, problem.Check, message)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(DoctorCmd)
+
+	DoctorCmd.Flags().Bool("json", false, "output problems as JSON")
+	DoctorCmd.Flags().Bool("fix", false, "repair the problems which can be repaired safely")
+}
@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+)
+
+// DoctorReportCmd represents the 'doctor report' command.
+var DoctorReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "write an HTML report on the health of the store",
+	Long: `report writes a single HTML page describing the health of the store, which is useful for a periodic review of how
+tidy it is:
+
+	$ albatross doctor report -o report.html
+
+The report has a summary at the top followed by a section for each of:
+
+	Entries which couldn't be loaded, such as because their front matter isn't valid YAML.
+	Problems with the contents of entries found by 'albatross lint', like secrets and truncated entries.
+	Broken links and duplicate titles found by 'albatross doctor'.
+	Missing and orphaned attachments and dangling symlinks found by 'albatross doctor'.
+	Orphaned entries, which don't link to any entry and which no entry links to.
+	The state of git, including the last commit and any changes which haven't been committed.
+	Statistics about the size of the store and how it has grown each month, like 'albatross stats store'.
+
+Orphaned entries and uncommitted changes aren't counted as problems, since they're often on purpose. Nothing in the store
+is changed, so to repair the problems which can be repaired safely, use 'albatross doctor --fix'.
+
+The report is printed to stdout unless an output location is given with --output/-o. Unlike 'albatross doctor', it
+exits with a status of 0 even if there are problems. To get the report as JSON instead, use the --json flag.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		outputDest, err := cmd.Flags().GetString("output")
+		checkArg(err)
+
+		report, err := store.HealthReport()
+		if err != nil {
+			log.Fatalf("Couldn't make health report: %s", err)
+		}
+
+		var out []byte
+		if outputJSON {
+			out, err = json.Marshal(report)
+			if err != nil {
+				fmt.Println("Error marshalling health report:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			out = append(out, '\n')
+		} else {
+			out, err = renderHealthReport(store.Path, report)
+			if err != nil {
+				fmt.Println("Error rendering health report:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		if outputDest == "" {
+			os.Stdout.Write(out)
+			return
+		}
+
+		err = ioutil.WriteFile(outputDest, out, 0644)
+		if err != nil {
+			fmt.Println("Couldn't write to output destination:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// healthReportPage is the data given to healthReportTemplate.
+type healthReportPage struct {
+	*albatross.HealthReport
+
+	// Store is the path to the store the report is about.
+	Store string
+}
+
+// healthReportFuncs are the functions available in healthReportTemplate.
+var healthReportFuncs = template.FuncMap{
+	"bytes": formatBytes,
+	"orDash": func(s string) string {
+		if s == "" {
+			return "-"
+		}
+
+		return s
+	},
+}
+
+// healthReportTemplate is the page written by 'doctor report'. It's a single file with the styles inline so that it can
+// be sent or archived on its own.
+var healthReportTemplate = template.Must(template.New("report").Funcs(healthReportFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Store health report - {{.Generated.Format "2006-01-02"}}</title>
+<style>
+body {
+	font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif;
+	line-height: 1.5;
+	color: #222;
+	max-width: 60em;
+	margin: 0 auto;
+	padding: 1em 1em 3em;
+}
+
+h2 .count {
+	font-size: 0.7em;
+	font-weight: normal;
+	color: #666;
+}
+
+.meta {
+	color: #666;
+}
+
+.summary {
+	display: flex;
+	flex-wrap: wrap;
+	gap: 1em;
+	padding: 0;
+	list-style: none;
+}
+
+.summary li {
+	flex: 1 1 8em;
+	padding: 0.75em 1em;
+	border: 1px solid #ddd;
+	border-radius: 4px;
+}
+
+.summary .value {
+	display: block;
+	font-size: 1.6em;
+	font-weight: bold;
+}
+
+.summary .bad {
+	border-color: #e01b24;
+	background: #fdf0f0;
+}
+
+.summary .good {
+	border-color: #2ec27e;
+	background: #f0fbf5;
+}
+
+table {
+	width: 100%;
+	border-collapse: collapse;
+	font-size: 0.9em;
+}
+
+th, td {
+	text-align: left;
+	vertical-align: top;
+	padding: 0.3em 0.6em;
+	border-bottom: 1px solid #eee;
+}
+
+td.number, th.number {
+	text-align: right;
+}
+
+code {
+	font-size: 0.95em;
+}
+
+.check {
+	white-space: nowrap;
+	color: #a51d2d;
+}
+
+.none {
+	color: #2ec27e;
+}
+</style>
+</head>
+<body>
+<h1>Store health report</h1>
+<p class="meta"><code>{{.Store}}</code>, generated {{.Generated.Format "Monday 2 January 2006, 15:04"}}</p>
+
+<ul class="summary">
+<li class="{{if .Problems}}bad{{else}}good{{end}}"><span class="value">{{.Problems}}</span> problems</li>
+<li><span class="value">{{len .Orphans}}</span> orphaned entries</li>
+<li class="{{if .State.Uncommitted}}bad{{else}}good{{end}}"><span class="value">{{len .State.Uncommitted}}</span> uncommitted changes</li>
+<li><span class="value">{{.Stats.Entries}}</span> entries ({{bytes .Stats.EntriesSize}})</li>
+<li><span class="value">{{.Stats.Attachments}}</span> attachments ({{bytes .Stats.AttachmentsSize}})</li>
+<li><span class="value">{{.Stats.Commits}}</span> commits</li>
+</ul>
+
+<h2>Entries which couldn't be loaded <span class="count">{{len .LoadErrors}}</span></h2>
+{{template "issues" .LoadErrors}}
+
+<h2>Lint <span class="count">{{len .Lint}}</span></h2>
+{{template "issues" .Lint}}
+
+<h2>Links <span class="count">{{len .Links}}</span></h2>
+{{template "problems" .Links}}
+
+<h2>Attachments <span class="count">{{len .Attachments}}</span></h2>
+{{template "problems" .Attachments}}
+
+<h2>Orphaned entries <span class="count">{{len .Orphans}}</span></h2>
+{{if .Orphans}}<p>These entries don't link to any entry and no entry links to them.</p>
+<ul>
+{{range .Orphans}}<li><code>{{.}}</code></li>
+{{end}}</ul>
+{{else}}<p class="none">Every entry is linked to or from another entry.</p>
+{{end}}
+<h2>Git</h2>
+{{if .State.UsingGit}}<table>
+<tr><th>Last commit</th><td>{{with .State.LastCommit}}<code>{{printf "%.7s" .Hash}}</code> {{.Message}} ({{.Time.Format "2006-01-02 15:04"}}){{else}}-{{end}}</td></tr>
+<tr><th>Locked</th><td>{{if .State.Locked}}yes, <code>.git/index.lock</code> may need removing by hand{{else}}no{{end}}</td></tr>
+<tr><th>Uncommitted</th><td>{{if .State.Uncommitted}}{{range .State.Uncommitted}}<code>{{.}}</code><br>
+{{end}}{{else}}none{{end}}</td></tr>
+</table>
+{{else}}<p>The store isn't using git.</p>
+{{end}}
+<h2>Growth</h2>
+{{if .Stats.Months}}<table>
+<tr><th>Month</th><th class="number">Entries</th><th class="number">Total</th><th class="number">Commits</th></tr>
+{{range .Stats.Months}}<tr><td>{{.Month}}</td><td class="number">{{.Entries}}</td><td class="number">{{.Cumulative}}</td><td class="number">{{.Commits}}</td></tr>
+{{end}}</table>
+{{else}}<p>There aren't any entries yet.</p>
+{{end}}</body>
+</html>
+{{define "issues"}}{{if .}}<table>
+<tr><th>Entry</th><th>Check</th><th>Message</th></tr>
+{{range .}}<tr><td><code>{{orDash .Path}}</code></td><td class="check">{{.Check}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>
+{{else}}<p class="none">No problems found.</p>
+{{end}}{{end}}
+{{define "problems"}}{{if .}}<table>
+<tr><th>Entry</th><th>Check</th><th>Message</th><th>Fix</th></tr>
+{{range .}}<tr><td><code>{{orDash .Path}}</code></td><td class="check">{{.Check}}</td><td>{{.Message}}</td><td>{{.Fix}}</td></tr>
+{{end}}</table>
+{{else}}<p class="none">No problems found.</p>
+{{end}}{{end}}`))
+
+// renderHealthReport renders a health report for the store at the path given as an HTML page.
+func renderHealthReport(storePath string, report *albatross.HealthReport) ([]byte, error) {
+	var b bytes.Buffer
+
+	err := healthReportTemplate.Execute(&b, healthReportPage{HealthReport: report, Store: storePath})
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func init() {
+	DoctorCmd.AddCommand(DoctorReportCmd)
+
+	DoctorReportCmd.Flags().StringP("output", "o", "", "output location of the report, by default it's printed to stdout")
+	DoctorReportCmd.Flags().Bool("json", false, "output the report as JSON rather than HTML")
+}
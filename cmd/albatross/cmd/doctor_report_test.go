@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestRenderHealthReport(t *testing.T) {
+	report := &albatross.HealthReport{
+		Generated:  time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC),
+		LoadErrors: []albatross.LintIssue{{Path: "notes/invalid", Check: albatross.LintParseFailed, Message: "yaml: <bad>"}},
+		Lint:       []albatross.LintIssue{},
+		Links: []albatross.CheckProblem{
+			{Path: "notes/ideas", Check: albatross.CheckBrokenLink, Message: "link [[pizza!]] doesn't point to any entry", Fix: "change link to [[Pizza!]]"},
+		},
+		Attachments: []albatross.CheckProblem{},
+		Orphans:     []string{"notes/alone"},
+		State:       &albatross.State{UsingGit: true, Uncommitted: []string{"food/pizza/entry.md"}},
+		Stats: &albatross.Stats{
+			Entries: 12,
+			Commits: 30,
+			Months:  []albatross.MonthStats{{Month: "2021-02", Entries: 12, Cumulative: 12, Commits: 30}},
+		},
+	}
+
+	out, err := renderHealthReport("/stores/default", report)
+	if err != nil {
+		t.Fatalf("not expecting error rendering report: %s", err)
+	}
+
+	html := string(out)
+
+	Contains(t, html, `<span class="value">2</span> problems`)
+	Contains(t, html, "yaml: &lt;bad&gt;", "expecting messages to be escaped")
+	Contains(t, html, "change link to [[Pizza!]]")
+	Contains(t, html, "<code>notes/alone</code>")
+	Contains(t, html, "<code>food/pizza/entry.md</code>")
+	Contains(t, html, `<td>2021-02</td><td class="number">12</td>`)
+	Equal(t, 2, strings.Count(html, "No problems found."), "expecting the empty sections to say so")
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/viper"
+)
+
+// errEditDiscarded is returned by editChecked when the user chooses to throw away what they've written.
+var errEditDiscarded = errors.New("changes discarded")
+
+// The choices given when an edited entry has problems.
+const (
+	editAgain   = "Edit again"
+	editSave    = "Save anyway"
+	editDiscard = "Discard changes"
+)
+
+// editChecked opens the content of the entry at the path given in an editor, like edit, and then checks what was written
+// for problems using entryProblems. If there are any, they're printed and the user can choose to edit the entry again,
+// save it anyway or discard their changes, in which case errEditDiscarded is returned. If check is false, the content
+// isn't checked.
+//
+// If frontMatterOnly is true, only the YAML in the entry's front matter is opened in the editor and the rest of the
+// entry is kept as it is.
+func editChecked(editorName, path, content string, check, frontMatterOnly bool) (string, error) {
+	original := content
+	frontMatter, rest := entries.SplitFrontMatter(content)
+
+	for {
+		var edited string
+		var err error
+
+		if frontMatterOnly {
+			frontMatter, err = edit(editorName, frontMatter)
+			edited = entries.JoinFrontMatter(frontMatter, rest)
+		} else {
+			edited, err = edit(editorName, content)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if !check {
+			return edited, nil
+		}
+
+		collection, err := storeCollection()
+		if err != nil {
+			return "", err
+		}
+
+		problems := entryProblems(collection, path, edited, viper.GetBool("editor.check-links"))
+		if len(problems) == 0 {
+			return edited, nil
+		}
+
+		fmt.Printf("Found %d problem(s) with %s:\n", len(problems), path)
+		for _, problem := range problems {
+			fmt.Println("  -", problem)
+		}
+
+		prompt := promptui.Select{
+			Label: "What now",
+			Items: []string{editAgain, editSave, editDiscard},
+		}
+
+		_, choice, err := prompt.Run()
+		if err != nil {
+			// There's no way to ask, such as when not running in a terminal, so the content is saved somewhere safe.
+			saveRecovery(path, original, edited, "Couldn't ask what to do with the entry.")
+			return "", err
+		}
+
+		switch choice {
+		case editSave:
+			return edited, nil
+		case editDiscard:
+			return "", errEditDiscarded
+		}
+
+		content = edited
+	}
+}
+
+// entryProblems returns the problems with content written for the entry at the path given which should be fixed before
+// it's saved: front matter which can't be parsed, including dates which don't match the date format, and, if
+// checkLinks is true, links which don't point to any entry in the collection.
+func entryProblems(collection *entries.Collection, path, content string, checkLinks bool) []string {
+	entry, err := entries.NewEntryFromContents(path, []byte(content), time.Now(), entries.Limits{})
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	problems := []string{}
+
+	if !checkLinks {
+		return problems
+	}
+
+	for _, link := range entry.OutboundLinks {
+		// The collection still has the old version of the entry, so links to the entry itself are checked against the
+		// new one.
+		if link.Path == entry.Path || (link.Title != "" && link.Title == entry.Title) {
+			continue
+		}
+
+		if collection.ResolveLink(link) == nil {
+			problems = append(problems, fmt.Sprintf("link %s doesn't point to any entry", entry.Contents[link.Loc[0]:link.Loc[1]]))
+		}
+	}
+
+	return problems
+}
+
+func init() {
+	viper.SetDefault("editor.check-links", true)
+}
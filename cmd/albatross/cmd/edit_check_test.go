@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/albatross-org/go-albatross/entries"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestEntryProblems(t *testing.T) {
+	collection := entries.NewCollection()
+
+	err := collection.Add(&entries.Entry{Path: "food/pizza", Title: "Pizza"})
+	if err != nil {
+		t.Fatalf("not expecting error adding entry: %s", err)
+	}
+
+	Empty(t, entryProblems(collection, "journal/today", "---\ntitle: Today\ndate: 2020-09-01 12:00\n---\n\nAte [[Pizza]] and {{food/pizza}}.", true))
+
+	problems := entryProblems(collection, "journal/today", "---\ntitle: Today\ndate: 01/09/2020\n---\n\nAte pizza.", true)
+	if Len(t, problems, 1, "expecting a problem for a date in the wrong format") {
+		Contains(t, problems[0], "couldn't parse date")
+	}
+
+	problems = entryProblems(collection, "journal/today", "---\ntitle: [Today\n---\n\nAte pizza.", true)
+	Len(t, problems, 1, "expecting a problem for front matter which doesn't parse")
+
+	problems = entryProblems(collection, "journal/today", "---\ntitle: Today\n---\n\nAte [[Chips]], see [[Today]] and {{journal/today}}.", true)
+	Equal(t, []string{"link [[Chips]] doesn't point to any entry"}, problems, "expecting links to the entry itself to be allowed")
+
+	Empty(t, entryProblems(collection, "journal/today", "Ate [[Chips]].", false), "expecting links not to be checked")
+}
@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/publish"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// exportProfile is a named export defined in the config file, see ExportCmd.
+type exportProfile struct {
+	// Exporter is the export action to use, such as "epub" for 'get export epub'. "json" or blank uses 'get export'.
+	Exporter string `mapstructure:"exporter"`
+
+	// Query are the arguments given to get to find the entries, such as ["--path", "journal", "--sort", "date"].
+	Query []string `mapstructure:"query"`
+
+	// Since only exports entries from this long ago, like "7d" or "2w", by adding --from to the query.
+	Since string `mapstructure:"since"`
+
+	// Flags are the flags given to the exporter, by name.
+	Flags map[string]interface{} `mapstructure:"flags"`
+
+	// Publish is a target to upload the export's output to once it's finished, like "sftp://me@example.com/var/www".
+	Publish string `mapstructure:"publish"`
+
+	// PublishDelete deletes files from the publish target which are no longer in the output.
+	PublishDelete bool `mapstructure:"publish-delete"`
+}
+
+// output returns the file or folder the export writes to, from its "output" flag.
+func (p exportProfile) output(now time.Time) (string, error) {
+	output, ok := p.Flags["output"]
+	if !ok {
+		return "", fmt.Errorf("can't publish without an output flag")
+	}
+
+	return expandProfileValue(fmt.Sprint(output), now), nil
+}
+
+// args returns the arguments which run the export, after 'albatross'. The time now is used for relative dates.
+func (p exportProfile) args(now time.Time) ([]string, error) {
+	args := append([]string{"get"}, p.Query...)
+
+	if p.Since != "" {
+		from, err := parseSince(p.Since, "2006-01-02 15:04", now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since %q: %w", p.Since, err)
+		}
+
+		args = append(args, "--from", from.Format("2006-01-02 15:04"), "--date-format", "2006-01-02 15:04")
+	}
+
+	args = append(args, "export")
+
+	switch p.Exporter {
+	case "", "json":
+	default:
+		found := false
+		for _, exporter := range ActionExportCmd.Commands() {
+			if exporter.Name() == p.Exporter {
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown exporter %q", p.Exporter)
+		}
+
+		args = append(args, p.Exporter)
+	}
+
+	names := []string{}
+	for name := range p.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		switch value := p.Flags[name].(type) {
+		case bool:
+			args = append(args, fmt.Sprintf("--%s=%t", name, value))
+		case []interface{}:
+			for _, item := range value {
+				args = append(args, "--"+name, expandProfileValue(fmt.Sprint(item), now))
+			}
+		default:
+			args = append(args, "--"+name, expandProfileValue(fmt.Sprint(value), now))
+		}
+	}
+
+	return args, nil
+}
+
+// expandProfileValue replaces "{date}" in a flag value with the date now and expands a leading "~" to the home
+// directory, so that profiles can give output paths like "~/exports/journal-{date}.epub".
+func expandProfileValue(value string, now time.Time) string {
+	value = strings.ReplaceAll(value, "{date}", now.Format("2006-01-02"))
+
+	expanded, err := homedir.Expand(value)
+	if err != nil {
+		return value
+	}
+
+	return expanded
+}
+
+// exportProfiles returns the export profiles for the current store.
+func exportProfiles() map[string]exportProfile {
+	profiles := map[string]exportProfile{}
+
+	err := viper.UnmarshalKey(fmt.Sprintf("%s.exports", storeName), &profiles)
+	if err != nil {
+		log.Fatalf("Couldn't parse export profiles in config file: %s", err)
+	}
+
+	return profiles
+}
+
+// ExportCmd represents the export command.
+var ExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "run exports defined in the config file",
+	Long: `export runs exports which are defined in the config file, so that exports which are done regularly don't need
+all their flags typing out each time and can be scheduled, such as by cron.
+
+Exports are defined under 'exports' for the store in the global config file, by name:
+
+	default:
+	  path: "/path/to/albatross/store"
+	  exports:
+	    weekly-epub:
+	      query: ["--path", "journal", "--sort", "date"]
+	      since: "7d"
+	      exporter: "epub"
+	      flags:
+	        book-title: "This Week"
+	        output: "~/exports/journal-{date}.epub"
+
+	$ albatross export run weekly-epub
+	# Runs: albatross get --path journal --sort date --from <a week ago> export epub --book-title "This Week" ...
+
+The keys are:
+
+	- query, the arguments to 'albatross get' which find the entries to export.
+	- since, optionally, how far back to export entries from, such as "7d" or "2w". Since this adds --from and
+	  --date-format to the query, the query shouldn't use its own --date-format.
+	- exporter, the export action to use, such as "epub", "audio" or "changelog". By default or if "json", the entries
+	  are exported as JSON using 'albatross get export'.
+	- flags, the flags given to the exporter. Lists give the flag more than once. In values, "{date}" is replaced by
+	  today's date and a leading "~" by the home directory.
+	- publish, optionally, a target to upload the output flag's file or folder to after the export has run, such as
+	  "sftp://me@example.com/var/www/site". See 'albatross publish --help' for the targets which are supported.
+	- publish-delete, whether to delete files from the publish target which are no longer in the output.
+
+Names are case insensitive. To see the exports which are defined, use 'albatross export list'. To see the command an
+export would run without running it, use --dry-run.`,
+}
+
+// ExportRunCmd represents the export run command.
+var ExportRunCmd = &cobra.Command{
+	Use:   "run <name>",
+	Short: "run an export defined in the config file",
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		checkArg(err)
+
+		if len(args) != 1 {
+			fmt.Println("Expecting exactly one argument, the name of the export:")
+			fmt.Println("")
+			fmt.Println("    $ albatross export run weekly-epub")
+			os.Exit(1)
+		}
+
+		profile, ok := exportProfiles()[strings.ToLower(args[0])]
+		if !ok {
+			fmt.Printf("No export named '%s' for store '%s', see 'albatross export list'.\n", args[0], storeName)
+			os.Exit(1)
+		}
+
+		exportArgs, err := profile.args(time.Now())
+		if err != nil {
+			log.Fatalf("Invalid export '%s': %s", args[0], err)
+		}
+
+		// Global flags like --store and --config are passed on so that the export uses the same store.
+		globalArgs := []string{}
+		rootCmd.PersistentFlags().Visit(func(flag *pflag.Flag) {
+			globalArgs = append(globalArgs, fmt.Sprintf("--%s=%s", flag.Name, flag.Value.String()))
+		})
+
+		exportArgs = append(globalArgs, exportArgs...)
+
+		output := ""
+		if profile.Publish != "" {
+			output, err = profile.output(time.Now())
+			if err != nil {
+				log.Fatalf("Invalid export '%s': %s", args[0], err)
+			}
+		}
+
+		if dryRun {
+			fmt.Println("albatross " + strings.Join(quoteArgs(exportArgs), " "))
+
+			if profile.Publish != "" {
+				fmt.Println("albatross publish " + strings.Join(quoteArgs([]string{output, profile.Publish}), " "))
+			}
+
+			return
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			log.Fatalf("Couldn't find albatross executable: %s", err)
+		}
+
+		export := exec.Command(executable, exportArgs...)
+		export.Stdin = os.Stdin
+		export.Stdout = os.Stdout
+		export.Stderr = os.Stderr
+
+		err = export.Run()
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		} else if err != nil {
+			log.Fatalf("Couldn't run export: %s", err)
+		}
+
+		if profile.Publish != "" {
+			// Exports are only published with secrets if the export itself was allowed to include them.
+			allowSecrets, _ := profile.Flags["allow-secrets"].(bool)
+			publishSource(output, profile.Publish, profile.PublishDelete, false, allowSecrets, publish.DefaultBatchSize, false)
+		}
+	},
+}
+
+// ExportListCmd represents the export list command.
+var ExportListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the exports defined in the config file",
+	Run: func(cmd *cobra.Command, args []string) {
+		profiles := exportProfiles()
+
+		if len(profiles) == 0 {
+			fmt.Printf("No exports defined for store '%s', see 'albatross export --help'.\n", storeName)
+			return
+		}
+
+		names := []string{}
+		for name := range profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			exportArgs, err := profiles[name].args(time.Now())
+			if err != nil {
+				fmt.Printf("%s\t(invalid: %s)\n", name, err)
+				continue
+			}
+
+			fmt.Printf("%s\talbatross %s\n", name, strings.Join(quoteArgs(exportArgs), " "))
+		}
+	},
+}
+
+// quoteArgs quotes arguments which contain spaces so that they can be copied into a shell.
+func quoteArgs(args []string) []string {
+	quoted := make([]string, len(args))
+
+	for i, arg := range args {
+		if strings.ContainsAny(arg, " \t\"'") {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		} else {
+			quoted[i] = arg
+		}
+	}
+
+	return quoted
+}
+
+func init() {
+	rootCmd.AddCommand(ExportCmd)
+
+	ExportCmd.AddCommand(ExportRunCmd)
+	ExportCmd.AddCommand(ExportListCmd)
+
+	ExportRunCmd.Flags().Bool("dry-run", false, "print the command the export would run instead of running it")
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestExportProfileArgs(t *testing.T) {
+	now := time.Date(2020, 8, 14, 12, 30, 0, 0, time.UTC)
+
+	profile := exportProfile{
+		Exporter: "epub",
+		Query:    []string{"--path", "journal", "--sort", "date"},
+		Since:    "7d",
+		Flags: map[string]interface{}{
+			"output":     "/exports/journal-{date}.epub",
+			"book-title": "This Week",
+		},
+	}
+
+	args, err := profile.args(now)
+	NoError(t, err)
+	Equal(t, []string{
+		"get", "--path", "journal", "--sort", "date",
+		"--from", "2020-08-07 12:30", "--date-format", "2006-01-02 15:04",
+		"export", "epub",
+		"--book-title", "This Week",
+		"--output", "/exports/journal-2020-08-14.epub",
+	}, args)
+
+	profile = exportProfile{
+		Query: []string{"--tag", "@?recipe"},
+		Flags: map[string]interface{}{
+			"annotations": true,
+			"tag":         []interface{}{"a", "b"},
+		},
+	}
+
+	args, err = profile.args(now)
+	NoError(t, err)
+	Equal(t, []string{"get", "--tag", "@?recipe", "export", "--annotations=true", "--tag", "a", "--tag", "b"}, args, "expecting JSON export without an exporter")
+
+	_, err = exportProfile{Exporter: "docx"}.args(now)
+	Error(t, err, "expecting error for unknown exporter")
+
+	_, err = exportProfile{Since: "last tuesday"}.args(now)
+	Error(t, err, "expecting error for invalid since")
+}
+
+func TestExportProfileOutput(t *testing.T) {
+	now := time.Date(2020, 8, 14, 12, 30, 0, 0, time.UTC)
+
+	output, err := exportProfile{Flags: map[string]interface{}{"output": "/exports/site-{date}"}}.output(now)
+	NoError(t, err)
+	Equal(t, "/exports/site-2020-08-14", output)
+
+	_, err = exportProfile{Publish: "sftp://example.com/site"}.output(now)
+	Error(t, err, "expecting error publishing without an output flag")
+}
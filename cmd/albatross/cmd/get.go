@@ -2,14 +2,19 @@ package cmd
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/albatross-org/go-albatross/encryption"
 	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
 
 	"github.com/spf13/cobra"
 )
@@ -32,6 +37,7 @@ var GetCmd = &cobra.Command{
 	- Printing their paths
 	- Exporting them as JSON or YAML
 	- Generating flashcards
+	- Checking whether any exist, for scripts
 
 Some examples:
 
@@ -73,13 +79,164 @@ This will match entries from the path "notes/" or "school/". The filters that su
 	--path     --path-exact     --path-not     --path-exact-not
 	--title    --title-exact    --title-not    --title-exact-not
 	--contents --contents-exact --contents-not --contents-exact-not
+	--path-regex --title-regex --contents-regex
+
+The --path-regex, --title-regex and --contents-regex flags take regular expressions in Go's syntax
+(https://golang.org/s/re2syntax), which match anywhere unless anchored with "^" and "$":
+
+	$ albatross get --path-regex '^journal/\d{4}-08-' --contents-regex '(?i)\bpizza\b'
 
 You can also change the delimeter used from " OR " using the --delimeter flag.
 
+--from and --until take a date in the format given by --date-format, or something relative to now:
+
+	today, yesterday, tomorrow          the whole day
+	this week, last week, next week     Monday to Sunday
+	this month, last month, next month  the whole month
+	this year, last year, next year     the whole year
+	2021, 2021-03, 2021-03-14           the whole year, month or day
+	30d, 2w, 12h, 6mo, 1y               that long ago, or that long from now if it starts with "+"
+
+Since these cover a span of time, --from uses the start and --until uses the end, so --from "last week" --until
+"last week" matches all of last week and --until 2021-03 includes the whole of March:
+
+	$ albatross get --tag "@!journal" --from yesterday
+	$ albatross get --from 2021-03 --until 2021-03
+
+For anything the flags can't express, --query (-q) takes an expression in a small query language, which can combine
+terms using AND, OR, NOT and parentheses:
+
+	$ albatross get -q 'path:school/* AND (tag:@?physics OR title:~"momentum") AND date>2021-01-01'
+
+The terms are:
+
+	path:school       path starts with "school", or matches it as a pattern if it contains "*"
+	title:pizza       title contains "pizza", contents:pizza is the same for the contents
+	title:~"^Pizza"   title matches a regular expression, also path:~ and contents:~
+	title="Pizza"     title is exactly "Pizza", also path= and contents=
+	tag:@?physics     entry has the tag "@?physics"
+	date>2021-01-01   entry is dated after the 1st of January 2021, also >=, <, <= and =
+	length>1000       contents are longer than 1000 bytes, also >=, <, <= and =
+	pizza             contents contain "pizza"
+
+Terms next to each other are AND-ed, and a term can be negated by starting it with "-", like -tag:@?draft. Values
+with spaces or parentheses in them need to be quoted. Dates can be given as 2006-01-02, "2006-01-02 15:04", RFC 3339
+or anything --from accepts, like date>="last week", and a date without a time means the whole day. The query is
+AND-ed with any other filters given.
+
+Entries are in a random order unless --sort is given. It can be:
+
+	alpha       By title.
+	date        By date, oldest first.
+	path        By path.
+	weight      By the number given by the 'weight' key in the front matter, so that entries like the topics in a
+	            syllabus can be put in a logical order. Entries without a weight come last.
+	length      By the number of words, shortest first.
+	mod         By when the entry.md file was last modified, least recently first.
+	meta:<key>  By the value of a key in the front matter, like 'meta:author'. Numbers are sorted numerically and come
+	            first, then everything else alphabetically. Entries without the key come last.
+
+Ties can be broken by giving more sorts, separated by commas, and --rev reverses the whole order:
+
+	$ albatross get --path school/physics --sort weight,alpha
+	$ albatross get --path books --sort meta:rating,alpha --rev
+
+To page through a lot of entries, use --offset to skip entries along with --number. So that each page carries on from
+the last, giving --offset always breaks any ties left by --sort using the path, and sorts by path if there's no --sort:
+
+	$ albatross get --path journal --sort date --offset 0 --number 20
+	$ albatross get --path journal --sort date --offset 20 --number 20
+
+To see why a query matched what it did, use --explain. This prints each filter the query was turned into, how many
+entries it eliminated and how long it took, along with the time taken to load the store and sort the results:
+
+	$ albatross get --path food --title-not Pizza --sort alpha --explain
+	Loaded 312 entries in 41.2ms.
+	Filters, applied in order:
+	    path contains "food"           312 -> 14   (-298)  1.1ms
+	    title doesn't contain "Pizza"   14 -> 13   (-1)    12µs
+	Sorted 13 entries by alpha in 3µs.
+	Matched 13 entries.
+
+Filters are applied one after the other, so an entry only counts as eliminated by the first filter which doesn't
+allow it. The explanation is printed to stderr, so it doesn't get in the way of piping the output.
+
+Paths can also be read from stdin using --stdin, which only allows entries with exactly those paths. This lets the
+output of one command be piped into another. By default there is one path per line, but --stdin-format can be used to
+change this:
+
+	lines  One path per line. Blank lines are ignored.
+	null   Paths separated by NUL bytes, as output by tools like 'find -print0' or 'fd -0'.
+	json   A JSON array of objects with a "path" key, such as entries exported as JSON.
+
+For example:
+
+	$ (cd ~/.local/share/albatross/default/entries && find food -name entry.md -printf '%h\0') | albatross get -i --stdin-format null
+	$ albatross get -p food export | albatross get -i --stdin-format json links
+
+If the store is encrypted, the whole store is decrypted before the query is run and encrypted again afterwards. To
+read a few entries without decrypting everything, use --selective with --path-exact. Only those entries are decrypted,
+into memory, and the store is left encrypted:
+
+	$ albatross get --selective --path-exact food/pizza contents
+
+Only the entries given by --path-exact can be matched, and actions which change entries, like 'update', won't work.
+
+Entries are marked as read when they're printed using 'contents' or opened using 'update', and can be marked by hand
+using 'mark'. To only match entries which haven't been read, or have changed since they were last read, use --unread:
+
+	$ albatross get --unread -p imported/articles contents | less
+
+Which entries have been read is kept in the store's '.albatross' folder rather than alongside the entries, so reading
+doesn't create changes to commit. To stop entries being marked as read automatically, set 'read-tracking.automatic' to
+false in the store's config.
+
+Similarly, --recent only matches the entries viewed most recently, such as to open the last entry viewed again:
+
+	$ albatross get --recent 1 update
+
+See 'albatross recent' for listing them.
+
+Entries which aren't in the store yet, like drafts, can be added to the entries being searched as 'pretend' entries,
+to see how they'd be matched and what they link to before creating them. --parse-content parses text as an entry and
+--parse-file parses a file, or every Markdown file in a folder. Both can be given more than once:
+
+	$ albatross get --parse-file draft.md --parse-file ~/drafts/ links
+
+Pretend entries are at "pretend/<n>" for --parse-content and "pretend/<name>" for --parse-file by default. --parse-as
+gives them paths instead, in order, first to each --parse-content and then to each --parse-file. A pretend entry with
+the same path as an entry in the store replaces it, which is useful for seeing how an edit would change things. For
+folders, the entries in them go under the path given. Once a pretend entry looks right, 'materialise' writes it into
+the store:
+
+	$ albatross get --parse-file draft.md --parse-as ideas/hovercraft materialise
+
+To see why entries matched, use --show matches. Each place the --title, --title-regex, --contents and --contents-regex
+filters matched is printed under the path of the entry, with the line it's on and the text around it:
+
+	$ albatross get -c pineapple --show matches
+	food/pizza
+	    contents:2: I could eat pizza every day, especially with pineapple.
+
+When printing to a terminal, the matched text is highlighted. Only the first few matches in each entry are shown.
+
 By default, the command will print all the entries to all the paths that it matched. However, you can do
 much more. 'Actions' are mini-programs that operate on lists of entries. For all available entries, see
-the available subcommands.`,
+the available subcommands.
+
+To run more than one action on the same entries, give them after "--" separated by "::". The store is only loaded and
+the query only run once, which is much quicker than running get once for each action:
+
+	$ albatross get -p school -- path :: tags :: links --text
+
+The actions are run in order, each with its own flags. Filters have to be given before the "--", and each action can
+only be given once.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if dash := cmd.ArgsLenAtDash(); dash != -1 {
+			runActions(cmd, args[dash:])
+			return
+		}
+
 		ActionPathCmd.Run(cmd, args)
 	},
 }
@@ -87,40 +244,205 @@ the available subcommands.`,
 func init() {
 	rootCmd.AddCommand(GetCmd)
 
+	addFilterFlags(GetCmd)
+
+	GetCmd.PersistentFlags().StringSlice("show", []string{}, "extra information to print with each path, 'matches' shows where --title and --contents matched")
+}
+
+// addFilterFlags adds the flags used by getFromCommand to filter entries to a command, so that commands other than get
+// can operate on matched entries.
+func addFilterFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+
 	// Filters
-	GetCmd.PersistentFlags().IntP("number", "n", -1, "number of entries to return, -1 means all")
-	GetCmd.PersistentFlags().StringP("from", "f", "", "only show entries with creation dates after this")
-	GetCmd.PersistentFlags().StringP("until", "u", "", "only show entries with creation dates before this")
+	flags.IntP("number", "n", -1, "number of entries to return, -1 means all")
+	flags.Int("offset", 0, "number of entries to skip after sorting, for paging through entries with --number")
+	flags.StringP("from", "f", "", "only show entries with creation dates after this, a date or something like 'yesterday', 'last week', '2021-03' or '30d'")
+	flags.StringP("until", "u", "", "only show entries with creation dates before this, a date or something like 'yesterday', 'last week', '2021-03' or '30d'")
 
-	GetCmd.PersistentFlags().Int("min-length", 0, "minimum length to allow")
-	GetCmd.PersistentFlags().Int("max-length", 0, "maximum length to allow")
+	flags.Int("min-length", 0, "minimum length to allow")
+	flags.Int("max-length", 0, "maximum length to allow")
 
-	GetCmd.PersistentFlags().StringSliceP("tag", "a", []string{}, "tags to allow")
-	GetCmd.PersistentFlags().StringSlice("tag-not", []string{}, "tags to disallow")
+	flags.StringSliceP("tag", "a", []string{}, "tags to allow")
+	flags.StringSlice("tag-not", []string{}, "tags to disallow")
 
-	GetCmd.PersistentFlags().StringSliceP("path", "p", []string{}, "paths to allow, substring")
-	GetCmd.PersistentFlags().StringSliceP("title", "t", []string{}, "titles to allow, substring")
-	GetCmd.PersistentFlags().StringSliceP("contents", "c", []string{}, "contents to allow, substring")
+	flags.StringSliceP("path", "p", []string{}, "paths to allow, substring")
+	flags.StringSliceP("title", "t", []string{}, "titles to allow, substring")
+	flags.StringSliceP("contents", "c", []string{}, "contents to allow, substring")
 
-	GetCmd.PersistentFlags().StringSlice("path-exact", []string{}, "paths to allow, exact")
-	GetCmd.PersistentFlags().StringSlice("title-exact", []string{}, "titles to allow, exact")
-	GetCmd.PersistentFlags().StringSlice("contents-exact", []string{}, "substrings to allow, exact")
+	flags.StringSlice("path-exact", []string{}, "paths to allow, exact")
+	flags.StringSlice("title-exact", []string{}, "titles to allow, exact")
+	flags.StringSlice("contents-exact", []string{}, "substrings to allow, exact")
 
-	GetCmd.PersistentFlags().StringSlice("path-not", []string{}, "paths to disallow, substring")
-	GetCmd.PersistentFlags().StringSlice("title-not", []string{}, "titles to disallow, substring")
-	GetCmd.PersistentFlags().StringSlice("contents-not", []string{}, "contents to disallow, substring")
+	flags.StringSlice("path-not", []string{}, "paths to disallow, substring")
+	flags.StringSlice("title-not", []string{}, "titles to disallow, substring")
+	flags.StringSlice("contents-not", []string{}, "contents to disallow, substring")
 
-	GetCmd.PersistentFlags().StringSlice("path-exact-not", []string{}, "paths to disallow, exact")
-	GetCmd.PersistentFlags().StringSlice("title-exact-not", []string{}, "titles to disallow, exact")
-	GetCmd.PersistentFlags().StringSlice("contents-exact-not", []string{}, "substrings to disallow, exact")
+	flags.StringSlice("path-exact-not", []string{}, "paths to disallow, exact")
+	flags.StringSlice("title-exact-not", []string{}, "titles to disallow, exact")
+	flags.StringSlice("contents-exact-not", []string{}, "substrings to disallow, exact")
 
-	GetCmd.PersistentFlags().BoolP("stdin", "i", false, "read list of exact paths from stdin")
+	flags.StringP("query", "q", "", "query language expression entries must match, like 'path:school/* AND tag:@?physics'")
+
+	flags.StringSlice("path-regex", []string{}, "paths to allow, regular expression")
+	flags.StringSlice("title-regex", []string{}, "titles to allow, regular expression")
+	flags.StringSlice("contents-regex", []string{}, "contents to allow, regular expression")
+
+	flags.BoolP("stdin", "i", false, "read list of exact paths from stdin")
+	flags.String("stdin-format", "lines", "format of paths read from stdin ('lines', 'null' or 'json')")
+	flags.Bool("selective", false, "if the store is encrypted, only decrypt the entries given by --path-exact into memory")
+	flags.Bool("unread", false, "only allow entries which haven't been read, or have changed since they were read")
+	flags.Int("recent", 0, "only allow this many of the most recently viewed entries, see 'albatross recent'")
+
+	// Pretend entries
+	flags.StringArray("parse-content", []string{}, "parse this as a pretend entry and add it to the entries being searched, can be given more than once")
+	flags.StringArray("parse-file", []string{}, "parse this file, or the Markdown files in this folder, as pretend entries, can be given more than once")
+	flags.StringArray("parse-as", []string{}, "paths to give the pretend entries from --parse-content and --parse-file, in order")
 
 	// Misc
-	GetCmd.PersistentFlags().BoolP("rev", "r", false, "reverse the list returned")
-	GetCmd.PersistentFlags().String("sort", "", "sorting scheme ('alpha', 'date' or '' for random)")
-	GetCmd.PersistentFlags().String("date-format", "2006-01-02 15:04", "date format for parsing from and until")
-	GetCmd.PersistentFlags().String("delimeter", " OR ", "delimeter to use for splitting up arguments")
+	flags.BoolP("rev", "r", false, "reverse the list returned")
+	flags.String("sort", "", "sorting scheme ('alpha', 'date', 'path', 'weight', 'length', 'mod', 'meta:<key>' or '' for random), more can be given separated by commas to break ties")
+	flags.String("date-format", "2006-01-02 15:04", "date format for parsing from and until")
+	flags.String("delimeter", " OR ", "delimeter to use for splitting up arguments")
+	flags.Bool("explain", false, "print the filters used, how many entries each one eliminated and how long it all took to stderr")
+}
+
+// selectiveCollection decrypts only the entries with the paths given, for --selective, and returns a collection of them.
+// The paths are all the paths given to --path-exact, and the query still filters them afterwards.
+func selectiveCollection(pathsExact [][]string) *entries.Collection {
+	paths := []string{}
+	for _, group := range pathsExact {
+		paths = append(paths, group...)
+	}
+
+	if len(paths) == 0 {
+		fmt.Println("--selective needs the paths of the entries to decrypt, given using --path-exact.")
+		os.Exit(1)
+	}
+
+	var decrypted []*albatross.DecryptedEntry
+
+	_, err := unlock(func(passwordFunc func() (string, error)) error {
+		var err error
+		decrypted, err = store.DecryptEntries(paths, passwordFunc)
+		return err
+	})
+	if _, ok := err.(encryption.ErrPrivateKeyDecryptionFailed); ok {
+		fmt.Println("Decryption failed three times. Exiting.")
+		os.Exit(1)
+	} else if err != nil {
+		log.Fatalf("Couldn't decrypt entries: %s", err)
+	}
+
+	collection := entries.NewCollection()
+
+	for _, entry := range decrypted {
+		// The same path could be given more than once.
+		if collection.In(entry.Entry) {
+			continue
+		}
+
+		err = collection.Add(entry.Entry)
+		if err != nil {
+			log.Fatalf("Couldn't add decrypted entry %s: %s", entry.Entry.Path, err)
+		}
+	}
+
+	return collection
+}
+
+// explainQuery prints an explanation of how the list of entries was found, for the --explain flag.
+func explainQuery(w io.Writer, collection, filtered *entries.Collection, list entries.List, steps []entries.FilterStep, loadTime, sortTime time.Duration, sort string, rev bool, offset, number int) {
+	fmt.Fprintf(w, "Loaded %d entries in %s.\n", collection.Len(), loadTime.Round(time.Microsecond))
+
+	if len(steps) == 0 {
+		fmt.Fprintln(w, "No filters, all entries are allowed.")
+	} else {
+		fmt.Fprintln(w, "Filters, applied in order:")
+
+		nameWidth, countWidth := 0, 0
+		for _, step := range steps {
+			if len(step.Name) > nameWidth {
+				nameWidth = len(step.Name)
+			}
+
+			if n := len(strconv.Itoa(step.Before)); n > countWidth {
+				countWidth = n
+			}
+		}
+
+		for _, step := range steps {
+			fmt.Fprintf(
+				w, "    %-*s  %*d -> %-*d  %-*s  %s\n",
+				nameWidth, step.Name,
+				countWidth, step.Before,
+				countWidth, step.After,
+				countWidth+3, fmt.Sprintf("(-%d)", step.Eliminated()),
+				step.Duration.Round(time.Microsecond),
+			)
+		}
+	}
+
+	ordering := "randomly"
+	if sort != "" {
+		ordering = fmt.Sprintf("by %s", strings.ReplaceAll(sort, ",", " then "))
+	}
+
+	if rev {
+		ordering += ", reversed"
+	}
+
+	fmt.Fprintf(w, "Sorted %d entries %s in %s.\n", filtered.Len(), ordering, sortTime.Round(time.Microsecond))
+
+	if offset > 0 {
+		fmt.Fprintf(w, "Skipped the first %d entries because of --offset.\n", offset)
+	}
+
+	if number != -1 && number < filtered.Len()-offset {
+		fmt.Fprintf(w, "Kept the first %d entries because of --number.\n", number)
+	}
+
+	fmt.Fprintf(w, "Matched %d entries.\n", len(list.Slice()))
+}
+
+// parseStdinPaths parses the paths given on stdin in the format given, either "lines", "null" or "json". Empty paths are
+// ignored.
+func parseStdinPaths(input []byte, format string) ([]string, error) {
+	var paths []string
+
+	switch format {
+	case "lines":
+		paths = strings.Split(strings.ReplaceAll(string(input), "\r\n", "\n"), "\n")
+
+	case "null":
+		paths = strings.Split(string(input), "\x00")
+
+	case "json":
+		var objects []struct {
+			Path string `json:"path"`
+		}
+
+		err := json.Unmarshal(input, &objects)
+		if err != nil {
+			return nil, fmt.Errorf("expected a JSON array of objects with a \"path\" key: %w", err)
+		}
+
+		for _, object := range objects {
+			paths = append(paths, object.Path)
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected 'lines', 'null' or 'json'", format)
+	}
+
+	nonEmpty := []string{}
+	for _, path := range paths {
+		if path = strings.TrimSpace(path); path != "" {
+			nonEmpty = append(nonEmpty, path)
+		}
+	}
+
+	return nonEmpty, nil
 }
 
 // multiSplit is like strings.Split except it splits a slice of strings into a slice of slices.
@@ -134,12 +456,28 @@ func multiSplit(strs []string, delimeter string) [][]string {
 	return res
 }
 
-// getFromCommand runs a get query by parsing a command for flags.
+// lastQuery is the query made by the last call to getFromCommand, so that actions can show which parts of entries it
+// matched.
+var lastQuery entries.Query
+
+// lastFilter is the filter made by the last call to getFromCommand, so that actions which keep running, like 'server
+// --watch', can filter the collection again when it changes.
+var lastFilter entries.Filter
+
+// getFromCommand runs a get query by parsing a command for flags. When actions are run together using runActions, they
+// all share the result of the first query.
 func getFromCommand(cmd *cobra.Command) (collection *entries.Collection, filtered *entries.Collection, list entries.List) {
+	if sharedResult != nil {
+		return sharedResult.all, sharedResult.filtered, sharedResult.list
+	}
+
+	selective, err := cmd.Flags().GetBool("selective")
+	checkArg(err)
+
 	encrypted, err := store.Encrypted()
 	if err != nil {
 		log.Fatal(err)
-	} else if encrypted {
+	} else if encrypted && !selective && daemonClient == nil {
 		decryptStore()
 
 		if !leaveDecrypted {
@@ -160,10 +498,25 @@ func getFromCommand(cmd *cobra.Command) (collection *entries.Collection, filtere
 	delimeter, err := cmd.Flags().GetString("delimeter")
 	checkArg(err)
 
+	explain, err := cmd.Flags().GetBool("explain")
+	checkArg(err)
+
 	// Get the filter flags, generic
 	number, err := cmd.Flags().GetInt("number")
 	checkArg(err)
 
+	offset, err := cmd.Flags().GetInt("offset")
+	checkArg(err)
+
+	if offset < 0 {
+		fmt.Println("--offset can't be negative")
+		os.Exit(1)
+	}
+
+	if cmd.Flags().Changed("offset") {
+		sort = entries.DeterministicSort(sort)
+	}
+
 	from, err := cmd.Flags().GetString("from")
 	checkArg(err)
 
@@ -219,24 +572,51 @@ func getFromCommand(cmd *cobra.Command) (collection *entries.Collection, filtere
 	contentsExactNot, err := cmd.Flags().GetStringSlice("contents-exact-not")
 	checkArg(err)
 
+	pathsRegex, err := cmd.Flags().GetStringSlice("path-regex")
+	checkArg(err)
+
+	titlesRegex, err := cmd.Flags().GetStringSlice("title-regex")
+	checkArg(err)
+
+	contentsRegex, err := cmd.Flags().GetStringSlice("contents-regex")
+	checkArg(err)
+
+	expression, err := cmd.Flags().GetString("query")
+	checkArg(err)
+
 	stdin, err := cmd.Flags().GetBool("stdin")
 	checkArg(err)
 
+	stdinFormat, err := cmd.Flags().GetString("stdin-format")
+	checkArg(err)
+
+	unread, err := cmd.Flags().GetBool("unread")
+	checkArg(err)
+
+	recent, err := cmd.Flags().GetInt("recent")
+	checkArg(err)
+
 	// Parse dates using format
 	var fromDate, untilDate time.Time
 
+	now := entries.ClockTime(time.Now())
+
 	if from != "" {
-		fromDate, err = time.Parse(dateFormat, from)
+		fromRange, err := entries.ParseDate(from, dateFormat, now)
 		if err != nil {
-			log.Fatalf("Can't parse %s using format %s: %s", from, dateFormat, err)
+			log.Fatalf("Can't parse --from: %s", err)
 		}
+
+		fromDate = fromRange.Start
 	}
 
 	if until != "" {
-		untilDate, err = time.Parse(dateFormat, until)
+		untilRange, err := entries.ParseDate(until, dateFormat, now)
 		if err != nil {
-			log.Fatalf("Can't parse %s using format %s: %s", until, dateFormat, err)
+			log.Fatalf("Can't parse --until: %s", err)
 		}
+
+		untilDate = untilRange.End
 	}
 
 	// Build the query
@@ -264,6 +644,18 @@ func getFromCommand(cmd *cobra.Command) (collection *entries.Collection, filtere
 		TitlesMatch:        multiSplit(titlesMatch, delimeter),
 		TitlesExactExclude: multiSplit(titlesExactNot, delimeter),
 		TitlesMatchExclude: multiSplit(titlesMatchNot, delimeter),
+
+		ContentsRegex: multiSplit(contentsRegex, delimeter),
+		PathsRegex:    multiSplit(pathsRegex, delimeter),
+		TitlesRegex:   multiSplit(titlesRegex, delimeter),
+
+		Expression: expression,
+	}
+
+	err = query.Validate()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 
 	// Get stdin paths
@@ -273,7 +665,12 @@ func getFromCommand(cmd *cobra.Command) (collection *entries.Collection, filtere
 			log.Fatalf("Can't read stdin: %s", err)
 		}
 
-		query.PathsExact = append(query.PathsExact, strings.Split(string(stdin), "\n"))
+		paths, err := parseStdinPaths(stdin, stdinFormat)
+		if err != nil {
+			log.Fatalf("Can't parse paths from stdin: %s", err)
+		}
+
+		query.PathsExact = append(query.PathsExact, paths)
 	}
 
 	if log.IsLevelEnabled(logrus.TraceLevel) {
@@ -285,40 +682,47 @@ func getFromCommand(cmd *cobra.Command) (collection *entries.Collection, filtere
 		log.Tracef("Query created from command: %s", string(queryJSON))
 	}
 
-	collection, err = store.Collection()
-	if err != nil {
-		log.Fatalf("Couldn't parse Albatross store to collection: %s", err)
-	}
+	pretend := pretendEntriesFromCommand(cmd)
 
-	start := time.Now()
-
-	filtered, err = collection.Filter(query.Filter())
-	if err != nil {
-		log.Fatalf("Couldn't run filter on Albatross store: %s", err)
+	pretendPaths = map[string]bool{}
+	for _, entry := range pretend {
+		pretendPaths[entry.Path] = true
 	}
 
-	end := time.Now()
-
-	list = filtered.List()
+	runner := albatross.NewQueryRunner(store)
+	runner.SetSource(func() (*entries.Collection, error) {
+		if encrypted && selective {
+			return selectiveCollection(query.PathsExact), nil
+		}
 
-	switch sort {
-	case "alpha":
-		list = list.Sort(entries.SortAlpha)
-	case "date":
-		list = list.Sort(entries.SortDate)
+		return storeCollection()
+	})
+
+	result, err := runner.Run(albatross.QueryOptions{
+		Query:   query,
+		Unread:  unread,
+		Recent:  recent,
+		Sort:    sort,
+		Rev:     rev,
+		Number:  number,
+		Offset:  offset,
+		Explain: explain,
+		Pretend: pretend,
+	})
+	if err != nil {
+		log.Fatalf("Couldn't run query: %s", err)
 	}
 
-	if rev {
-		list = list.Reverse()
-	}
+	lastFilter = result.Filter
+	lastQuery = query
 
-	if number != -1 {
-		list = list.First(number)
+	if log.IsLevelEnabled(logrus.DebugLevel) {
+		log.Debugf("Query matched %d entries in %s.", len(result.List.Slice()), result.FilterTime)
 	}
 
-	if log.IsLevelEnabled(logrus.DebugLevel) {
-		log.Debugf("Query matched %d entries in %s.", len(list.Slice()), end.Sub(start))
+	if explain {
+		explainQuery(os.Stderr, result.Collection, result.Filtered, result.List, result.Steps, storeLoadTime+result.LoadTime, result.SortTime, sort, rev, offset, number)
 	}
 
-	return collection, filtered, list
+	return result.Collection, result.Filtered, result.List
 }
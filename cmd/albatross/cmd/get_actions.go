@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/spf13/cobra"
+)
+
+// actionSeparator separates the actions given to get after "--", like 'albatross get -p school -- path :: tags'.
+const actionSeparator = "::"
+
+// getResult is the result of running a get query, as returned by getFromCommand.
+type getResult struct {
+	all      *entries.Collection
+	filtered *entries.Collection
+	list     entries.List
+}
+
+// sharedResult is the result of the query shared by actions run together by runActions. While it's set, getFromCommand
+// returns it rather than loading the store and running the query again.
+var sharedResult *getResult
+
+// splitActions splits the arguments given after "--" into the arguments for each action, which are separated by
+// actionSeparator.
+func splitActions(args []string) [][]string {
+	actions := [][]string{{}}
+
+	for _, arg := range args {
+		if arg == actionSeparator {
+			actions = append(actions, []string{})
+			continue
+		}
+
+		actions[len(actions)-1] = append(actions[len(actions)-1], arg)
+	}
+
+	return actions
+}
+
+// runActions runs each of the actions given after "--" in order, separated by actionSeparator, against the entries
+// matched by the get command. The store is only loaded and the query only run once, however many actions there are.
+// Every action is found and its flags are parsed before any are run, so a mistake in the last action doesn't leave the
+// first half done.
+func runActions(cmd *cobra.Command, args []string) {
+	actions := []*cobra.Command{}
+	seen := map[*cobra.Command]bool{}
+
+	for _, actionArgs := range splitActions(args) {
+		if len(actionArgs) == 0 {
+			fmt.Printf("Expecting an action between each %q, like 'albatross get -p school -- path :: tags'\n", actionSeparator)
+			os.Exit(1)
+		}
+
+		action, flags, err := cmd.Find(actionArgs)
+		if err != nil || action == cmd || !action.Runnable() {
+			fmt.Printf("Unknown action %q, see 'albatross get --help' for the available actions\n", actionArgs[0])
+			os.Exit(1)
+		}
+
+		// Flags are kept between parses, so running the same action twice would mix up their flags.
+		if seen[action] {
+			fmt.Printf("The action %q is given more than once, each action can only be run once\n", action.CommandPath())
+			os.Exit(1)
+		}
+		seen[action] = true
+
+		err = action.ParseFlags(flags)
+		if err != nil {
+			fmt.Printf("Invalid flags for %q: %s\n", action.CommandPath(), err)
+			os.Exit(1)
+		}
+
+		actions = append(actions, action)
+	}
+
+	all, filtered, list := getFromCommand(cmd)
+
+	sharedResult = &getResult{all: all, filtered: filtered, list: list}
+	defer func() { sharedResult = nil }()
+
+	for _, action := range actions {
+		action.Run(action, action.Flags().Args())
+	}
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestSplitActions(t *testing.T) {
+	Equal(t, [][]string{{"path"}}, splitActions([]string{"path"}))
+
+	Equal(t,
+		[][]string{{"path"}, {"tags"}, {"links", "--text"}},
+		splitActions([]string{"path", "::", "tags", "::", "links", "--text"}),
+	)
+
+	Equal(t,
+		[][]string{{"export", "json", "--fields", "path"}, {}, {"title"}},
+		splitActions([]string{"export", "json", "--fields", "path", "::", "::", "title"}),
+		"expecting an empty action where there's nothing between separators",
+	)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestParseStdinPaths(t *testing.T) {
+	cases := []struct {
+		input    string
+		format   string
+		expected []string
+	}{
+		{"food/pizza\nfood/ice-cream\n", "lines", []string{"food/pizza", "food/ice-cream"}},
+		{"food/pizza\r\n\r\nfood/ice-cream", "lines", []string{"food/pizza", "food/ice-cream"}},
+		{"food/pizza\x00food/with\nnewline\x00", "null", []string{"food/pizza", "food/with\nnewline"}},
+		{`[{"path": "food/pizza", "title": "Pizza"}, {"path": "food/ice-cream"}]`, "json", []string{"food/pizza", "food/ice-cream"}},
+		{"", "lines", []string{}},
+	}
+
+	for _, tc := range cases {
+		paths, err := parseStdinPaths([]byte(tc.input), tc.format)
+		NoError(t, err, "not expecting error parsing %q as %s", tc.input, tc.format)
+		Equal(t, tc.expected, paths, "paths parsed from %q as %s", tc.input, tc.format)
+	}
+
+	_, err := parseStdinPaths([]byte("food/pizza"), "json")
+	Error(t, err, "expecting error parsing invalid JSON")
+
+	_, err = parseStdinPaths([]byte("food/pizza"), "csv")
+	Error(t, err, "expecting error for an unknown format")
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ImportCmd represents the import command.
+var ImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "import notes from other apps into the store",
+	Long: `import converts notes written using other apps into entries and adds them to the store.
+
+	$ albatross import obsidian ~/Documents/vault --path notes
+
+Each kind of notes has its own subcommand, see 'albatross import [kind] --help' for how they're converted. Nothing
+already in the store is changed: if an entry already exists at a path, a number is added to the end of the new one.
+To see what would be created without changing the store, use --dry-run.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ImportCmd)
+}
@@ -0,0 +1,693 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// ImportObsidianCmd represents the 'import obsidian' command.
+var ImportObsidianCmd = &cobra.Command{
+	Use:   "obsidian [vault]",
+	Short: "import the notes in an Obsidian vault",
+	Long: `obsidian imports the notes in an Obsidian vault as entries, along with the attachments they use.
+
+	$ albatross import obsidian ~/Documents/vault --path notes
+	Created notes/food from Food.md.
+	Created notes/food/pizza from Food/Pizza.md.
+
+Each note becomes an entry at a path made from its folders and file name, in lower case with hyphens, under the path
+given by --path. A note with the same name as the folder it's in, like "Food/Food.md", is used as the entry for the
+folder. If an entry already exists at a path, or two notes would have the same path, a number is added to the end of
+the later one, like "notes/food-2". Folders and files starting with a dot, like ".obsidian", are skipped.
+
+Notes are converted like so:
+
+	[[Pizza]]               {{notes/food/pizza}}
+	[[Pizza|a slice]]       {{notes/food/pizza}(a slice)}
+	[[Pizza#Toppings]]      {{notes/food/pizza}}
+	![[pizza.jpg]]          ![](pizza.jpg), and pizza.jpg is attached to the entry
+	![](photos/pizza.jpg)   ![](pizza.jpg), and pizza.jpg is attached to the entry
+	#food                   @?food
+	#food/italian           @?food-italian
+
+Links are resolved the same way Obsidian does, by the path of the note within the vault or else by its name. Links to
+notes which don't exist are kept as title links, like [[Pizza]], so that they show up in 'albatross lint'. Nothing
+inside code blocks is changed.
+
+Front matter is kept. The title comes from "title" if it's set, or else the name of the note. The date comes from "date"
+or "created" if either is set, or else from when the file was last modified. Tags given in "tags" are added to the
+front matter with the "@?" prefix.
+
+To see what would be created without changing the store, use --dry-run.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Expecting exactly one argument: the path to the vault")
+			fmt.Println("For example:")
+			fmt.Println("")
+			fmt.Println("$ albatross import obsidian ~/Documents/vault --path notes")
+			os.Exit(1)
+		}
+
+		prefix, err := cmd.Flags().GetString("path")
+		checkArg(err)
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		checkArg(err)
+
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		vault, err := readObsidianVault(args[0])
+		if err != nil {
+			fmt.Printf("Couldn't read vault %s:\n", args[0])
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		vault.assignPaths(prefix, func(entryPath string) bool {
+			_, err := os.Stat(filepath.Join(store.Path, "entries", filepath.FromSlash(entryPath), "entry.md"))
+			return err == nil
+		})
+
+		for _, note := range vault.notes {
+			data, err := ioutil.ReadFile(filepath.Join(vault.root, filepath.FromSlash(note.file)))
+			if err != nil {
+				fmt.Printf("Couldn't read note %s:\n", note.file)
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			content, attachments, err := vault.convert(note, string(data))
+			if err != nil {
+				fmt.Printf("Couldn't convert note %s:\n", note.file)
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			if dryRun {
+				fmt.Printf("Would create %s from %s", note.path, note.file)
+				if len(attachments) != 0 {
+					fmt.Printf(" with %d attachments", len(attachments))
+				}
+				fmt.Println(".")
+
+				continue
+			}
+
+			err = store.Create(note.path, content)
+			if err != nil {
+				fmt.Printf("Couldn't create entry %s from %s:\n", note.path, note.file)
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			for _, attachment := range attachments {
+				err = attachAs(note.path, attachment.source, attachment.name)
+				if err != nil {
+					fmt.Printf("Couldn't attach %s to %s:\n", attachment.source, note.path)
+					fmt.Println(err)
+					os.Exit(1)
+				}
+			}
+
+			fmt.Printf("Created %s from %s.\n", note.path, note.file)
+		}
+
+		if dryRun {
+			fmt.Printf("Would import %d notes.\n", len(vault.notes))
+		} else {
+			fmt.Printf("Imported %d notes.\n", len(vault.notes))
+		}
+	},
+}
+
+// attachAs attaches the file at source to the entry using the name given, which can be different to the name of the
+// file, by copying it into a temporary folder first.
+func attachAs(entryPath, source, name string) error {
+	if filepath.Base(source) == name {
+		return store.Attach(entryPath, source)
+	}
+
+	dir, err := ioutil.TempDir("", "albatross-import")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	data, err := ioutil.ReadFile(source)
+	if err != nil {
+		return err
+	}
+
+	renamed := filepath.Join(dir, name)
+
+	err = ioutil.WriteFile(renamed, data, 0644)
+	if err != nil {
+		return err
+	}
+
+	return store.Attach(entryPath, renamed)
+}
+
+// obsidianVault is the notes and other files in an Obsidian vault.
+type obsidianVault struct {
+	root  string
+	notes []*obsidianNote
+
+	// notesByPath and notesByName are the notes by their path within the vault and by their name, both in lower case
+	// and without the ".md" extension, which are the two ways Obsidian resolves links.
+	notesByPath map[string]*obsidianNote
+	notesByName map[string]*obsidianNote
+
+	// filesByPath and filesByName are the paths of the files which aren't notes, like images, by their path within the
+	// vault and by their name, both in lower case.
+	filesByPath map[string]string
+	filesByName map[string]string
+}
+
+// obsidianNote is a note in an Obsidian vault.
+type obsidianNote struct {
+	// file is the path of the note within the vault, like "Food/Pizza.md".
+	file string
+
+	// path is the path of the entry the note is imported as, like "notes/food/pizza".
+	path string
+
+	modified time.Time
+}
+
+// obsidianAttachment is a file to attach to the entry a note is imported as.
+type obsidianAttachment struct {
+	source string
+	name   string
+}
+
+// readObsidianVault finds the notes and other files in the vault at root, skipping anything whose name starts with a dot.
+func readObsidianVault(root string) (*obsidianVault, error) {
+	vault := &obsidianVault{
+		root:        root,
+		notesByPath: map[string]*obsidianNote{},
+		notesByName: map[string]*obsidianNote{},
+		filesByPath: map[string]string{},
+		filesByName: map[string]string{},
+	}
+
+	err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if file != root && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+
+		vault.add(filepath.ToSlash(rel), info.ModTime())
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return vault, nil
+}
+
+// add adds the file at the path given within the vault, as a note if it ends in ".md".
+func (v *obsidianVault) add(file string, modified time.Time) {
+	lower := strings.ToLower(file)
+
+	if path.Ext(lower) != ".md" {
+		v.filesByPath[lower] = file
+
+		// Where more than one file has the same name, Obsidian uses the one nearest to the top of the vault.
+		if existing, ok := v.filesByName[path.Base(lower)]; !ok || closerToRoot(file, existing) {
+			v.filesByName[path.Base(lower)] = file
+		}
+
+		return
+	}
+
+	note := &obsidianNote{file: file, modified: modified}
+	v.notes = append(v.notes, note)
+
+	lower = strings.TrimSuffix(lower, ".md")
+	v.notesByPath[lower] = note
+
+	if existing, ok := v.notesByName[path.Base(lower)]; !ok || closerToRoot(file, existing.file) {
+		v.notesByName[path.Base(lower)] = note
+	}
+}
+
+// closerToRoot returns true if the path a is in fewer folders than b, or in the same number and sorts first.
+func closerToRoot(a, b string) bool {
+	depthA, depthB := strings.Count(a, "/"), strings.Count(b, "/")
+	if depthA != depthB {
+		return depthA < depthB
+	}
+
+	return a < b
+}
+
+// assignPaths gives each note the path of the entry it's imported as, under prefix. taken returns true if an entry
+// already exists at a path, in which case a number is added to the end.
+func (v *obsidianVault) assignPaths(prefix string, taken func(entryPath string) bool) {
+	sort.Slice(v.notes, func(i, j int) bool { return v.notes[i].file < v.notes[j].file })
+
+	used := map[string]bool{}
+
+	for _, note := range v.notes {
+		base := obsidianEntryPath(prefix, note.file)
+
+		entryPath := base
+		for i := 2; used[entryPath] || taken(entryPath); i++ {
+			entryPath = base + "-" + strconv.Itoa(i)
+		}
+
+		used[entryPath] = true
+		note.path = entryPath
+	}
+}
+
+// obsidianEntryPath returns the path of the entry for the note at the path given within a vault, under prefix, like
+// "notes/food/pizza" for "Food/Pizza.md". Notes with the same name as their folder are used for the folder itself.
+func obsidianEntryPath(prefix, file string) string {
+	parts := strings.Split(strings.TrimSuffix(file, path.Ext(file)), "/")
+
+	for i, part := range parts {
+		parts[i] = albatross.Slugify(part)
+		if parts[i] == "" {
+			parts[i] = "untitled"
+		}
+	}
+
+	if n := len(parts); n > 1 && parts[n-1] == parts[n-2] {
+		parts = parts[:n-1]
+	}
+
+	return path.Join(append([]string{strings.Trim(prefix, "/")}, parts...)...)
+}
+
+// resolveNote returns the note a link points to, or nil if there isn't one.
+func (v *obsidianVault) resolveNote(target string) *obsidianNote {
+	target = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(target)), ".md")
+
+	if note, ok := v.notesByPath[target]; ok {
+		return note
+	}
+
+	return v.notesByName[path.Base(target)]
+}
+
+// resolveFile returns the path within the vault of the file which isn't a note that a link points to, or "" if there
+// isn't one.
+func (v *obsidianVault) resolveFile(target string) string {
+	target = strings.ToLower(strings.TrimSpace(target))
+
+	if file, ok := v.filesByPath[target]; ok {
+		return file
+	}
+
+	return v.filesByName[path.Base(target)]
+}
+
+var (
+	// reObsidianFrontMatter matches the front matter at the start of a note, capturing the YAML inside it.
+	reObsidianFrontMatter = regexp.MustCompile(`(?s)^---\n(?:(.*?)\n)?---[ \t]*(?:\n|$)`)
+
+	// reObsidianWikiLink matches wikilinks like "[[Pizza]]", "![[pizza.jpg|300]]" and "[[Pizza#Toppings|toppings]]".
+	// Group 1 is "!" for embeds, group 2 the note or file linked to, group 3 the heading or block and group 4 the alias.
+	reObsidianWikiLink = regexp.MustCompile(`(!?)\[\[([^\[\]|#^]*)((?:[#^][^\[\]|]*)?)(?:\|([^\[\]]*))?\]\]`)
+
+	// reObsidianMarkdownLink matches Markdown links and images like "![](photos/pizza.jpg)" and "[Pizza](<Pizza.md>)".
+	// Group 1 is "!" for images, group 2 the text and group 3 or 4 the destination.
+	reObsidianMarkdownLink = regexp.MustCompile(`(!?)\[([^\[\]]*)\]\((?:<([^<>]+)>|([^()\s]+))\)`)
+
+	// reObsidianTag matches tags like "#food" and "#food/italian" at the start of a line or after a space.
+	// Group 1 is what comes before the tag and group 2 the tag without the "#".
+	reObsidianTag = regexp.MustCompile(`(^|\s)#([\p{L}\p{N}_/-]+)`)
+
+	// reObsidianFence matches the start or end of a fenced code block.
+	reObsidianFence = regexp.MustCompile("^\\s*(```|~~~)")
+
+	// reObsidianSize matches the size given for an embedded image in place of an alias, like "300" or "300x200".
+	reObsidianSize = regexp.MustCompile(`^\d+(x\d+)?$`)
+
+	// reInvalidTagChars matches the characters which can't be used in an Albatross tag.
+	reInvalidTagChars = regexp.MustCompile(`[^a-zA-Z0-9_|-]+`)
+)
+
+// obsidianDateLayouts are the layouts tried when parsing a date in the front matter of a note.
+var obsidianDateLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+	"2006-01-02",
+}
+
+// convert returns the contents of the entry for a note and the attachments to add to it.
+func (v *obsidianVault) convert(note *obsidianNote, contents string) (string, []obsidianAttachment, error) {
+	contents, _ = entries.Normalise(contents)
+
+	metadata := map[string]interface{}{}
+
+	if match := reObsidianFrontMatter.FindStringSubmatch(contents); match != nil {
+		err := yaml.Unmarshal([]byte(match[1]), &metadata)
+		if err != nil {
+			return "", nil, fmt.Errorf("couldn't parse front matter: %w", err)
+		}
+
+		contents = contents[len(match[0]):]
+	}
+
+	title, _ := metadata["title"].(string)
+	if title == "" {
+		title = strings.TrimSuffix(path.Base(note.file), path.Ext(note.file))
+	}
+
+	date, ok := obsidianDate(metadata["date"])
+	if !ok {
+		date, ok = obsidianDate(metadata["created"])
+	}
+	if !ok {
+		date = note.modified
+	}
+
+	tags := []string{}
+	seen := map[string]bool{}
+
+	for _, key := range []string{"tags", "tag"} {
+		for _, tag := range obsidianTags(metadata[key]) {
+			tag = "@?" + obsidianTag(tag)
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+
+		delete(metadata, key)
+	}
+
+	delete(metadata, "created")
+
+	metadata["title"] = title
+	metadata["date"] = date.Format("2006-01-02 15:04")
+
+	if len(tags) != 0 {
+		metadata["tags"] = tags
+	}
+
+	frontMatter, err := yaml.Marshal(metadata)
+	if err != nil {
+		return "", nil, fmt.Errorf("couldn't create front matter: %w", err)
+	}
+
+	converter := &obsidianConverter{vault: v, note: note, names: map[string]string{}, used: map[string]bool{}}
+	body := converter.body(contents)
+
+	return "---\n" + string(frontMatter) + "---\n\n" + strings.TrimLeft(body, "\n"), converter.attachments, nil
+}
+
+// obsidianDate returns the date given in the front matter of a note, and whether it could be parsed.
+func obsidianDate(value interface{}) (time.Time, bool) {
+	switch value := value.(type) {
+	case time.Time:
+		return value, true
+	case string:
+		for _, layout := range obsidianDateLayouts {
+			if date, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+				return date, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// obsidianTags returns the tags given in the front matter of a note, which can be a list or a string of tags separated
+// by commas or spaces.
+func obsidianTags(value interface{}) []string {
+	tags := []string{}
+
+	switch value := value.(type) {
+	case []interface{}:
+		for _, tag := range value {
+			if tag != nil {
+				tags = append(tags, obsidianTags(fmt.Sprint(tag))...)
+			}
+		}
+	case string:
+		for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+			if tag = strings.TrimPrefix(tag, "#"); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+// obsidianTag returns an Obsidian tag without its "#" as an Albatross tag without its prefix, so "food/italian" becomes
+// "food-italian".
+func obsidianTag(tag string) string {
+	return reInvalidTagChars.ReplaceAllString(strings.TrimPrefix(tag, "#"), "-")
+}
+
+// obsidianConverter converts the body of a note, keeping track of the attachments it uses.
+type obsidianConverter struct {
+	vault *obsidianVault
+	note  *obsidianNote
+
+	attachments []obsidianAttachment
+
+	// names are the names attachments are given in the entry's folder by their path within the vault, and used are the
+	// names which have already been given out.
+	names map[string]string
+	used  map[string]bool
+}
+
+// body returns the body of a note with its links and tags converted. Code blocks and inline code are left as they are.
+func (c *obsidianConverter) body(contents string) string {
+	lines := strings.Split(contents, "\n")
+	fenced := false
+
+	for i, line := range lines {
+		if reObsidianFence.MatchString(line) {
+			fenced = !fenced
+			continue
+		}
+
+		if fenced {
+			continue
+		}
+
+		// Splitting on backticks puts inline code in every other part.
+		parts := strings.Split(line, "`")
+		for j := 0; j < len(parts); j += 2 {
+			parts[j] = c.line(parts[j])
+		}
+
+		lines[i] = strings.Join(parts, "`")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// line converts the links and tags in part of a line.
+func (c *obsidianConverter) line(line string) string {
+	// Markdown links are converted first so that the links wikilinks become aren't converted again.
+	line = reObsidianMarkdownLink.ReplaceAllStringFunc(line, func(link string) string {
+		match := reObsidianMarkdownLink.FindStringSubmatch(link)
+
+		destination := match[3]
+		if destination == "" {
+			destination = match[4]
+		}
+
+		return c.markdownLink(link, match[1] == "!", match[2], destination)
+	})
+
+	line = reObsidianWikiLink.ReplaceAllStringFunc(line, func(link string) string {
+		match := reObsidianWikiLink.FindStringSubmatch(link)
+		return c.wikiLink(match[1] == "!", match[2], match[3], match[4])
+	})
+
+	return reObsidianTag.ReplaceAllStringFunc(line, func(tag string) string {
+		match := reObsidianTag.FindStringSubmatch(tag)
+
+		// Obsidian doesn't treat anything made only of numbers as a tag, like "#1".
+		if strings.Trim(match[2], "0123456789") == "" {
+			return tag
+		}
+
+		return match[1] + "@?" + obsidianTag(match[2])
+	})
+}
+
+// wikiLink converts a wikilink to a link to an entry or an attachment.
+func (c *obsidianConverter) wikiLink(embed bool, target, heading, alias string) string {
+	alias = strings.TrimSpace(alias)
+
+	if strings.TrimSpace(target) == "" {
+		// Links to a heading in the same note become the text of the link, since entries can't be linked to in part.
+		if alias != "" {
+			return alias
+		}
+
+		return strings.TrimLeft(heading, "#^")
+	}
+
+	if file := c.vault.resolveFile(target); file != "" {
+		name := c.attach(file)
+
+		if embed {
+			if reObsidianSize.MatchString(alias) {
+				alias = ""
+			}
+
+			return "![" + linkTextEscaper.Replace(alias) + "](" + (&url.URL{Path: name}).String() + ")"
+		}
+
+		if alias == "" {
+			alias = name
+		}
+
+		return "[" + linkTextEscaper.Replace(alias) + "](" + (&url.URL{Path: name}).String() + ")"
+	}
+
+	if note := c.vault.resolveNote(target); note != nil {
+		return entryLink(note.path, alias)
+	}
+
+	if alias != "" && !strings.Contains(alias, ")") {
+		return "[[" + strings.TrimSpace(target) + "](" + alias + ")]"
+	}
+
+	return "[[" + strings.TrimSpace(target) + "]]"
+}
+
+// markdownLink converts a Markdown link to a file in the vault, relative to the note or to the top of the vault, into a
+// link to an entry or an attachment. Links to anything else are returned as they are.
+func (c *obsidianConverter) markdownLink(link string, image bool, text, destination string) string {
+	if strings.Contains(destination, ":") || strings.HasPrefix(destination, "#") {
+		return link
+	}
+
+	if unescaped, err := url.PathUnescape(destination); err == nil {
+		destination = unescaped
+	}
+
+	if i := strings.IndexByte(destination, '#'); i != -1 {
+		destination = destination[:i]
+	}
+
+	relative := path.Join(path.Dir(c.note.file), destination)
+
+	if path.Ext(strings.ToLower(destination)) == ".md" {
+		note := c.vault.notesByPath[strings.TrimSuffix(strings.ToLower(relative), ".md")]
+		if note == nil {
+			note = c.vault.resolveNote(destination)
+		}
+
+		if note == nil {
+			return link
+		}
+
+		return entryLink(note.path, text)
+	}
+
+	file, ok := c.vault.filesByPath[strings.ToLower(relative)]
+	if !ok {
+		file = c.vault.resolveFile(destination)
+	}
+
+	if file == "" {
+		return link
+	}
+
+	prefix := ""
+	if image {
+		prefix = "!"
+	}
+
+	return prefix + "[" + text + "](" + (&url.URL{Path: c.attach(file)}).String() + ")"
+}
+
+// entryLink returns a path link to the entry, using the name given if there is one and it can be used in a link.
+func entryLink(entryPath, name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.Contains(name, ")") {
+		return "{{" + entryPath + "}}"
+	}
+
+	return "{{" + entryPath + "}(" + name + ")}"
+}
+
+// attach adds the file at the path given within the vault to the attachments of the entry, returning its name in the
+// entry's folder. Files with the same name as one already attached have a number added to the end.
+func (c *obsidianConverter) attach(file string) string {
+	if name, ok := c.names[file]; ok {
+		return name
+	}
+
+	base := path.Base(file)
+	ext := path.Ext(base)
+
+	name := base
+	for i := 2; c.used[name] || name == "entry.md"; i++ {
+		name = strings.TrimSuffix(base, ext) + "-" + strconv.Itoa(i) + ext
+	}
+
+	c.used[name] = true
+	c.names[file] = name
+	c.attachments = append(c.attachments, obsidianAttachment{
+		source: filepath.Join(c.vault.root, filepath.FromSlash(file)),
+		name:   name,
+	})
+
+	return name
+}
+
+func init() {
+	ImportCmd.AddCommand(ImportObsidianCmd)
+
+	ImportObsidianCmd.Flags().StringP("path", "p", "", "path to import the notes under, like 'notes'")
+	ImportObsidianCmd.Flags().Bool("dry-run", false, "print the entries which would be created without changing the store")
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+// obsidianTestVault creates a vault containing the files given, returning its path.
+func obsidianTestVault(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "albatross-obsidian-test")
+	if err != nil {
+		t.Fatalf("not expecting error creating temporary directory: %s", err)
+	}
+
+	for name, contents := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+
+		err = os.MkdirAll(filepath.Dir(file), 0755)
+		if err != nil {
+			t.Fatalf("not expecting error creating folder for %s: %s", name, err)
+		}
+
+		err = ioutil.WriteFile(file, []byte(contents), 0644)
+		if err != nil {
+			t.Fatalf("not expecting error writing %s: %s", name, err)
+		}
+	}
+
+	return dir
+}
+
+func TestObsidianEntryPath(t *testing.T) {
+	Equal(t, "notes/food/pizza", obsidianEntryPath("notes", "Food/Pizza.md"))
+	Equal(t, "notes/food", obsidianEntryPath("notes/", "Food/Food.md"), "expecting folder notes to be used for the folder")
+	Equal(t, "cafe-ideas", obsidianEntryPath("", "Café Ideas.md"))
+	Equal(t, "untitled/x", obsidianEntryPath("", "!!!/X.md"))
+}
+
+func TestObsidianImport(t *testing.T) {
+	dir := obsidianTestVault(t, map[string]string{
+		".obsidian/app.json": "{}",
+		"Food.md":            "Food.",
+		"Food/Food.md":       "Another food.",
+		"Food/Pizza.md": `---
+tags: [food, food/italian]
+created: 2020-08-06 18:24
+source: book
+---
+
+# Pizza
+
+See [[Food]], [[Journal|today]] and [[Pizza#Toppings|toppings]] and [[Chips]] #recipe #1.
+
+![[pizza.jpg|300]] ![Photo](../photos/pizza.jpg) ![Web](https://example.com/a.jpg)
+
+` + "`#not-a-tag [[Food]]`" + `
+
+` + "```\n[[Food]] #code\n```",
+		"Journal.md":       "Ate [pizza](Food/Pizza.md).",
+		"pizza.jpg":        "jpg",
+		"photos/pizza.jpg": "another jpg",
+	})
+	defer os.RemoveAll(dir)
+
+	vault, err := readObsidianVault(dir)
+	if err != nil {
+		t.Fatalf("not expecting error reading vault: %s", err)
+	}
+
+	Len(t, vault.notes, 4, "expecting hidden folders to be skipped")
+
+	vault.assignPaths("notes", func(entryPath string) bool { return entryPath == "notes/journal" })
+
+	paths := map[string]string{}
+	for _, note := range vault.notes {
+		paths[note.file] = note.path
+	}
+
+	Equal(t, map[string]string{
+		"Food.md":       "notes/food",
+		"Food/Food.md":  "notes/food-2",
+		"Food/Pizza.md": "notes/food/pizza",
+		"Journal.md":    "notes/journal-2",
+	}, paths, "expecting collisions to be numbered")
+
+	pizza := vault.notesByPath["food/pizza"]
+	content, attachments, err := vault.convert(pizza, mustReadFile(t, filepath.Join(dir, "Food", "Pizza.md")))
+	NoError(t, err)
+
+	Equal(t, `---
+date: 2020-08-06 18:24
+source: book
+tags:
+- '@?food'
+- '@?food-italian'
+title: Pizza
+---
+
+# Pizza
+
+See {{notes/food}}, {{notes/journal-2}(today)} and {{notes/food/pizza}(toppings)} and [[Chips]] @?recipe #1.
+
+![](pizza-2.jpg) ![Photo](pizza.jpg) ![Web](https://example.com/a.jpg)
+
+`+"`#not-a-tag [[Food]]`"+`
+
+`+"```\n[[Food]] #code\n```", content)
+
+	Equal(t, []obsidianAttachment{
+		{source: filepath.Join(dir, "photos", "pizza.jpg"), name: "pizza.jpg"},
+		{source: filepath.Join(dir, "pizza.jpg"), name: "pizza-2.jpg"},
+	}, attachments, "expecting attachments with the same name to be renamed")
+
+	journal := vault.notesByPath["journal"]
+	journal.modified = time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	content, _, err = vault.convert(journal, "Ate [pizza](Food/Pizza.md).")
+	NoError(t, err)
+	Equal(t, "---\ndate: 2020-09-01 12:00\ntitle: Journal\n---\n\nAte {{notes/food/pizza}(pizza)}.", content, "expecting the date to come from the file")
+}
+
+func TestObsidianTags(t *testing.T) {
+	Equal(t, []string{"food", "italian"}, obsidianTags("#food, italian"))
+	Equal(t, []string{"food", "2020"}, obsidianTags([]interface{}{"food", 2020}))
+	Equal(t, "food-italian", obsidianTag("#food/italian"))
+}
+
+func mustReadFile(t *testing.T, file string) string {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("not expecting error reading %s: %s", file, err)
+	}
+
+	return string(data)
+}
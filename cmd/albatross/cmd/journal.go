@@ -0,0 +1,383 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/albatross-org/go-albatross/pkg/journal"
+	"github.com/spf13/cobra"
+)
+
+// JournalCmd represents the journal command.
+var JournalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "keep a journal with an entry for each day",
+	Long: `journal keeps a journal in the store, with an entry for each day at a path made from the date, like
+"journal/2021-02-17".
+
+	$ albatross journal today
+	$ albatross journal streak
+	Current streak: 12 days (2021-02-06 to 2021-02-17)
+	Longest streak: 31 days (2020-11-01 to 2020-12-01)
+	Days written:   204
+
+	$ albatross journal prompt
+	What surprised you today?
+
+	$ albatross journal gaps --since 30d
+	2021-01-20 to 2021-01-22  3 days
+
+Where entries go is set by 'journal.layout' in the store's config, which is a Go date format. The title of new entries
+is set by 'journal.title', also a Go date format, and prompts are read from the file given by 'journal.prompts', which
+is relative to the store:
+
+	journal:
+	  layout: "journal/2006/01/02"
+	  title: "Monday 2 January 2006"
+	  prompts: "prompts.txt"
+
+Any entry whose path matches the layout counts as a journal entry, so a journal which has been kept by hand can be used
+as it is.`,
+}
+
+// JournalTodayCmd represents the 'journal today' command.
+var JournalTodayCmd = &cobra.Command{
+	Use:   "today",
+	Short: "create or open today's journal entry",
+	Long: `today opens today's journal entry in an editor, creating it first if it doesn't exist yet.
+
+	$ albatross journal today
+
+New entries use the default template from 'albatross create', with the date as the title. Another template can be given
+using --template, like 'albatross create', and --prompt adds a random writing prompt to the start of the entry:
+
+	$ albatross journal today --template journal --prompt
+
+To open the entry for another day, use --date with a date like "2021-02-14" or "yesterday":
+
+	$ albatross journal today --date yesterday
+
+Like 'albatross create', the entry is checked for problems after the editor is closed. To skip the checks, use
+--no-check.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		editorName := getEditor("vim")
+		customEditor, err := cmd.Flags().GetString("editor")
+		checkArg(err)
+
+		if customEditor != "" {
+			editorName = customEditor
+		}
+
+		templateFile, err := cmd.Flags().GetString("template")
+		checkArg(err)
+
+		dateStr, err := cmd.Flags().GetString("date")
+		checkArg(err)
+
+		withPrompt, err := cmd.Flags().GetBool("prompt")
+		checkArg(err)
+
+		noCheck, err := cmd.Flags().GetBool("no-check")
+		checkArg(err)
+
+		date := time.Now()
+		if dateStr != "" {
+			date = parseJournalDate(dateStr)
+		}
+
+		defer decryptJournal()()
+
+		j := openJournal()
+
+		entry, err := j.Entry(date)
+		if err != nil {
+			log.Fatalf("Couldn't get journal entry: %s", err)
+		}
+
+		if entry != nil {
+			updateEntry(entry, editorName, !noCheck, false)
+			return
+		}
+
+		contents := getTemplateAt(templateFile, map[string]string{"title": date.Format(store.JournalTitle())}, date)
+
+		if withPrompt {
+			prompt := journalPrompt()
+			if prompt != "" {
+				contents = insertPrompt(contents, prompt)
+			}
+		}
+
+		path := j.Path(date)
+
+		contents, err = store.ApplyDefaultMetadata(path, contents)
+		if err != nil {
+			log.Fatal("Couldn't add default metadata to entry: ", err)
+		}
+
+		// Like create, the entry is made before it's edited so that problems come up before anything is written.
+		_, err = j.Create(date, contents)
+		if err != nil {
+			log.Fatal("Couldn't create journal entry: ", err)
+		}
+
+		content, err := editChecked(editorName, path, contents, !noCheck, false)
+		if err == errEditDiscarded {
+			err = store.Delete(path)
+			if err != nil {
+				log.Fatal("Couldn't remove discarded entry: ", err)
+			}
+
+			fmt.Println("Discarded new journal entry", path)
+			return
+		} else if err != nil {
+			log.Fatal("Couldn't get content from editor: ", err)
+		}
+
+		err = store.Update(path, content)
+		if err != nil {
+			saveRecovery(path, contents, content, "Error creating journal entry.")
+			os.Exit(1)
+		}
+
+		fmt.Println("Successfully created journal entry", path)
+	},
+}
+
+// JournalStreakCmd represents the 'journal streak' command.
+var JournalStreakCmd = &cobra.Command{
+	Use:   "streak",
+	Short: "show the current and longest streaks of days with a journal entry",
+	Long: `streak shows how many days in a row have a journal entry, both up to today and the longest ever, and the number of
+days which have an entry.
+
+	$ albatross journal streak
+	Current streak: 12 days (2021-02-06 to 2021-02-17)
+	Longest streak: 31 days (2020-11-01 to 2020-12-01)
+	Days written:   204
+
+If there's no entry for today yet, the current streak is the one which ends yesterday, since it can still be kept going.
+Use --json for the streaks as JSON.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		defer decryptJournal()()
+
+		days, err := openJournal().Days()
+		if err != nil {
+			log.Fatalf("Couldn't get journal entries: %s", err)
+		}
+
+		streaks := journal.FindStreaks(days, time.Now())
+
+		if outputJSON {
+			out, err := json.Marshal(streaks)
+			if err != nil {
+				fmt.Println("Error marshalling streaks:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		fmt.Println("Current streak:", formatStreak(streaks.Current))
+		fmt.Println("Longest streak:", formatStreak(streaks.Longest))
+		fmt.Println("Days written:  ", streaks.Total)
+	},
+}
+
+// JournalPromptCmd represents the 'journal prompt' command.
+var JournalPromptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "print a random writing prompt",
+	Long: `prompt prints a writing prompt picked at random from the prompts file, which is set by 'journal.prompts' in the
+store's config and is "prompts.txt" in the store by default. It has one prompt on each line, and blank lines and lines
+starting with "#" are skipped:
+
+	# prompts.txt
+	What surprised you today?
+	What are you looking forward to?
+
+	$ albatross journal prompt
+	What are you looking forward to?`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		prompt := journalPrompt()
+		if prompt == "" {
+			fmt.Println("There aren't any prompts in", store.JournalPrompts())
+			os.Exit(1)
+		}
+
+		fmt.Println(prompt)
+	},
+}
+
+// JournalGapsCmd represents the 'journal gaps' command.
+var JournalGapsCmd = &cobra.Command{
+	Use:   "gaps",
+	Short: "list the days without a journal entry",
+	Long: `gaps lists the runs of days without a journal entry, from the first entry up to yesterday:
+
+	$ albatross journal gaps
+	2021-01-20 to 2021-01-22  3 days
+	2021-02-03                1 day
+
+To only look at recent days, use --since with a duration like "30d" or a date like "2021-01-01". Use --json for the
+gaps as JSON.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		sinceStr, err := cmd.Flags().GetString("since")
+		checkArg(err)
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		defer decryptJournal()()
+
+		days, err := openJournal().Days()
+		if err != nil {
+			log.Fatalf("Couldn't get journal entries: %s", err)
+		}
+
+		var since time.Time
+		if sinceStr != "" {
+			since = parseJournalDate(sinceStr)
+		} else if len(days) != 0 {
+			since = days[0]
+		}
+
+		// Today isn't a gap yet, since there's still time to write it.
+		gaps := []journal.Gap{}
+		if !since.IsZero() {
+			gaps = journal.Gaps(days, since, time.Now().AddDate(0, 0, -1))
+		}
+
+		if outputJSON {
+			out, err := json.Marshal(gaps)
+			if err != nil {
+				fmt.Println("Error marshalling gaps:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		for _, gap := range gaps {
+			if gap.Days == 1 {
+				fmt.Printf("%-24s  1 day\n", gap.Start.Format("2006-01-02"))
+				continue
+			}
+
+			fmt.Printf("%-24s  %d days\n", gap.Start.Format("2006-01-02")+" to "+gap.End.Format("2006-01-02"), gap.Days)
+		}
+	},
+}
+
+// decryptJournal decrypts the store if it's encrypted, returning a function which encrypts it again unless
+// --leave-decrypted was given, to be deferred.
+func decryptJournal() func() {
+	encrypted, err := store.Encrypted()
+	if err != nil {
+		log.Fatal(err)
+	} else if !encrypted {
+		return func() {}
+	}
+
+	decryptStore()
+
+	if leaveDecrypted {
+		return func() {}
+	}
+
+	return encryptStore
+}
+
+// openJournal returns the journal in the store, using the layout from its config.
+func openJournal() *journal.Journal {
+	j, err := journal.New(store, store.JournalLayout())
+	if err != nil {
+		fmt.Println("Invalid journal.layout in the config:", err)
+		os.Exit(1)
+	}
+
+	return j
+}
+
+// parseJournalDate parses a date like "2021-02-14", "yesterday" or "7d", exiting if it's not valid.
+func parseJournalDate(value string) time.Time {
+	date, err := entries.ParseDate(value, "2006-01-02", time.Now())
+	if err != nil {
+		fmt.Printf("Invalid date %q: %s\n", value, err)
+		os.Exit(1)
+	}
+
+	return date.Start
+}
+
+// journalPrompt returns a random prompt from the prompts file, or "" if there aren't any.
+func journalPrompt() string {
+	prompts, err := journal.ReadPrompts(store.JournalPrompts())
+	if err != nil && !os.IsNotExist(err) {
+		log.Fatalf("Couldn't read prompts: %s", err)
+	}
+
+	return journal.RandomPrompt(prompts, rand.New(rand.NewSource(time.Now().UnixNano())))
+}
+
+// insertPrompt adds the prompt as a quote at the start of the entry, after its front matter.
+func insertPrompt(contents, prompt string) string {
+	quote := "> " + prompt + "\n\n"
+
+	if strings.HasPrefix(contents, "---\n") {
+		if end := strings.Index(contents[4:], "\n---\n"); end != -1 {
+			split := 4 + end + len("\n---\n")
+			return contents[:split] + "\n" + quote + strings.TrimLeft(contents[split:], "\n")
+		}
+	}
+
+	return quote + contents
+}
+
+// formatStreak formats a streak like "12 days (2021-02-06 to 2021-02-17)".
+func formatStreak(streak journal.Streak) string {
+	switch streak.Days {
+	case 0:
+		return "0 days"
+	case 1:
+		return fmt.Sprintf("1 day (%s)", streak.Start.Format("2006-01-02"))
+	}
+
+	return fmt.Sprintf("%d days (%s to %s)", streak.Days, streak.Start.Format("2006-01-02"), streak.End.Format("2006-01-02"))
+}
+
+func init() {
+	rootCmd.AddCommand(JournalCmd)
+
+	JournalCmd.AddCommand(JournalTodayCmd)
+	JournalCmd.AddCommand(JournalStreakCmd)
+	JournalCmd.AddCommand(JournalPromptCmd)
+	JournalCmd.AddCommand(JournalGapsCmd)
+
+	JournalTodayCmd.Flags().StringP("editor", "e", "", "Editor to use (defaults to $EDITOR, then vim)")
+	JournalTodayCmd.Flags().StringP("template", "t", "", "template to use for a new entry")
+	JournalTodayCmd.Flags().String("date", "", "open the entry for this day instead of today, like '2021-02-14' or 'yesterday'")
+	JournalTodayCmd.Flags().Bool("prompt", false, "add a random writing prompt to a new entry")
+	JournalTodayCmd.Flags().Bool("no-check", false, "don't check the entry for problems before saving it")
+
+	JournalStreakCmd.Flags().Bool("json", false, "output the streaks as JSON")
+
+	JournalGapsCmd.Flags().String("since", "", "only list gaps after this, a duration like '30d' or a date like '2021-01-01'")
+	JournalGapsCmd.Flags().Bool("json", false, "output the gaps as JSON")
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestInsertPrompt(t *testing.T) {
+	Equal(t,
+		"---\ntitle: \"Today\"\n---\n\n> What surprised you?\n\n",
+		insertPrompt("---\ntitle: \"Today\"\n---\n\n", "What surprised you?"),
+	)
+
+	Equal(t, "> What surprised you?\n\nNo front matter.", insertPrompt("No front matter.", "What surprised you?"))
+}
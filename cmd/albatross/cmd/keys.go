@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/albatross-org/go-albatross/encryption"
+	"github.com/spf13/cobra"
+)
+
+// KeysCmd represents the keys command.
+var KeysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "manage the passphrase saved in the system keyring",
+	Long: `keys manages the store's passphrase saved in the system keyring using 'albatross decrypt --save-passphrase'.
+
+	$ albatross keys forget
+	Removed saved passphrase for store 'default'.
+
+See 'albatross decrypt --help' for more information.`,
+}
+
+// KeysForgetCmd represents the keys forget command.
+var KeysForgetCmd = &cobra.Command{
+	Use:   "forget",
+	Short: "remove the passphrase saved in the system keyring",
+	Long: `forget removes the store's passphrase from the system keyring, so that it's asked for when decrypting the store.
+
+	$ albatross keys forget
+	$ albatross --store thesis keys forget`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := encryption.NewKeyring().Forget(keyringAccount())
+		if _, ok := err.(encryption.ErrPasswordNotSaved); ok {
+			fmt.Printf("No passphrase saved for store '%s'.\n", storeName)
+			os.Exit(1)
+		} else if err != nil {
+			fmt.Println("Couldn't remove passphrase from the system keyring:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Removed saved passphrase for store '%s'.\n", storeName)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(KeysCmd)
+	KeysCmd.AddCommand(KeysForgetCmd)
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// LintCmd represents the lint command.
+var LintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "check entries for problems",
+	Long: `lint checks the entries in the store for problems and lists them.
+
+	$ albatross lint
+	stray/photo  binary     entry file ".../stray/photo" looks like a binary file, skipping
+	work/log     truncated  entry is longer than entries.max-contents, so its contents have been truncated
+
+The checks are:
+
+	unreadable    The entry.md file couldn't be read.
+	parse-failed  The entry couldn't be parsed, such as because its front matter isn't valid YAML, or a folder's
+	              '_meta.yaml' file couldn't be parsed, see 'albatross get ls --help'.
+	too-large     The entry.md file is larger than 'entries.max-size' in the config, so it was skipped.
+	binary        The entry.md file looks like a binary file rather than text, so it was skipped.
+	truncated     The entry is longer than 'entries.max-contents' in the config, so only the start of it is used
+	              for searching, exporting and so on. The entry.md file itself isn't changed.
+	missing-metadata
+	              The entry doesn't set keys which 'default-metadata' in the config gives entries at its path,
+	              see 'albatross create --help'.
+	secret        The entry contains something which looks like a credential, such as an AWS key, a private key
+	              block or a long random token. Exports and publishing refuse to include these unless
+	              --allow-secrets is given. Add "albatross:allow-secret" to a line to stop it being reported.
+	invalid-reminder
+	              The entry's 'remind' or 'expires' date isn't in the format '2006-01-02' or '2006-01-02 15:04',
+	              see 'albatross reminders --help'.
+
+Entries which are skipped are also logged as warnings whenever the store is loaded. The size limit defaults to 16MB
+and there's no limit on the contents by default, since the contents of truncated entries are incomplete. They can be
+changed in the config, where a limit of 0 disables it:
+
+	entries:
+	  max-size: "16mb"
+	  max-contents: "1mb"
+
+If any problems are found, lint exits with a status of 1. To get the problems as JSON, use the --json flag.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		issues, err := store.Lint()
+		if err != nil {
+			log.Fatalf("Couldn't lint store: %s", err)
+		}
+
+		if outputJSON {
+			out, err := json.Marshal(issues)
+			if err != nil {
+				fmt.Println("Error marshalling lint issues:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+		} else {
+			pathWidth, checkWidth := 0, 0
+			for _, issue := range issues {
+				if len(issue.Path) > pathWidth {
+					pathWidth = len(issue.Path)
+				}
+
+				if len(issue.Check) > checkWidth {
+					checkWidth = len(issue.Check)
+				}
+			}
+
+			for _, issue := range issues {
+				fmt.Printf("%-*s  %-*s  %s\n", pathWidth, issue.Path, checkWidth, issue.Check, issue.Message)
+			}
+		}
+
+		if len(issues) > 0 {
+			if !leaveDecrypted && encrypted {
+				encryptStore()
+			}
+
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(LintCmd)
+
+	LintCmd.Flags().Bool("json", false, "output problems as JSON")
+}
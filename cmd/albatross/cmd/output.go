@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// colourMode is the value of --color: "auto", "always" or "never".
+var colourMode string
+
+// colourModes are the values --color can be given.
+var colourModes = []string{"auto", "always", "never"}
+
+// The terminal escape codes used to style output.
+const (
+	styleBold      = "1"
+	styleDim       = "2"
+	styleHighlight = "1;33"
+	styleTag       = "36"
+	styleWarning   = "33"
+	styleError     = "31"
+)
+
+// output writes text meant for people to read, such as tables and lists, to a terminal or a file. Text is only styled
+// with colour if it's going to a terminal, and long lines are only truncated to the width of the terminal, so output
+// which is piped into another program is left alone.
+type output struct {
+	w io.Writer
+
+	// colour is set if text should be styled using terminal escape codes.
+	colour bool
+
+	// width is the width of the terminal in columns, or 0 if the output isn't going to one and lines shouldn't be
+	// truncated.
+	width int
+}
+
+// newOutput returns an output for writing to w, using --color and the environment to decide whether to use colour.
+func newOutput(w io.Writer) *output {
+	tty := false
+	fd := -1
+
+	if f, ok := w.(*os.File); ok {
+		fd = int(f.Fd())
+		tty = terminal.IsTerminal(fd)
+	}
+
+	out := &output{w: w, colour: useColour(colourMode, tty)}
+
+	if tty {
+		out.width = terminalWidth(fd)
+	}
+
+	return out
+}
+
+// useColour decides whether to use colour given the value of --color and whether the output is a terminal. Colour is
+// never used automatically if NO_COLOR is set (see https://no-color.org) or the terminal is "dumb".
+func useColour(mode string, tty bool) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	return tty
+}
+
+// terminalWidth returns the width of the terminal, preferring $COLUMNS if it's set. If it can't be found, it returns 0.
+func terminalWidth(fd int) int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+
+	width, _, err := terminal.GetSize(fd)
+	if err != nil {
+		return 0
+	}
+
+	return width
+}
+
+// checkColourMode exits if --color isn't one of colourModes.
+func checkColourMode() {
+	for _, mode := range colourModes {
+		if colourMode == mode {
+			return
+		}
+	}
+
+	fmt.Printf("Invalid --color %q, expected '%s'\n", colourMode, strings.Join(colourModes, "', '"))
+	os.Exit(1)
+}
+
+// style returns the text styled using the terminal escape code given, like styleBold, or the text as it is if colour
+// isn't being used.
+func (o *output) style(code, text string) string {
+	if !o.colour || code == "" || text == "" {
+		return text
+	}
+
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
+
+// Println writes a line, truncated to the width of the terminal.
+func (o *output) Println(line string) {
+	fmt.Fprintln(o.w, o.fit(line))
+}
+
+// fit truncates the line to the width of the terminal, if there is one.
+func (o *output) fit(line string) string {
+	if o.width == 0 {
+		return line
+	}
+
+	return truncate(line, o.width)
+}
+
+// truncate shortens the text to at most width characters, ending it with "…" if anything was cut off. Terminal escape
+// codes in the text don't count towards its width and are kept, so styled text is still reset at the end.
+func truncate(text string, width int) string {
+	if width <= 0 || displayWidth(text) <= width {
+		return text
+	}
+
+	var out strings.Builder
+
+	shown := 0
+	escaped := false
+
+	for i := 0; i < len(text); {
+		if strings.HasPrefix(text[i:], "\x1b[") {
+			end := strings.IndexByte(text[i:], 'm')
+			if end == -1 {
+				break
+			}
+
+			out.WriteString(text[i : i+end+1])
+			escaped = true
+			i += end + 1
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if shown == width-1 {
+			out.WriteRune('…')
+			break
+		}
+
+		out.WriteRune(r)
+		shown++
+		i += size
+	}
+
+	if escaped {
+		out.WriteString("\x1b[0m")
+	}
+
+	return out.String()
+}
+
+// displayWidth returns the number of characters in the text, not counting terminal escape codes.
+func displayWidth(text string) int {
+	width := 0
+
+	for i := 0; i < len(text); {
+		if strings.HasPrefix(text[i:], "\x1b[") {
+			if end := strings.IndexByte(text[i:], 'm'); end != -1 {
+				i += end + 1
+				continue
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(text[i:])
+		width++
+		i += size
+	}
+
+	return width
+}
+
+// table lines up text in columns, like text/tabwriter, but knows about terminal escape codes and fits rows to the width
+// of the terminal by truncating the last column.
+type table struct {
+	out  *output
+	rows [][]string
+
+	// right are the columns which are aligned to the right, such as numbers.
+	right map[int]bool
+}
+
+// newTable returns a table written to the output given. Columns are aligned to the left apart from the ones given by
+// index in right.
+func newTable(out *output, right ...int) *table {
+	t := &table{out: out, right: map[int]bool{}}
+	for _, column := range right {
+		t.right[column] = true
+	}
+
+	return t
+}
+
+// Row adds a row to the table. Cells can be styled using output.style.
+func (t *table) Row(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// Flush writes the rows of the table, with two spaces between each column.
+func (t *table) Flush() {
+	widths := []int{}
+
+	for _, row := range t.rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+
+			if width := displayWidth(cell); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+
+	for _, row := range t.rows {
+		var line strings.Builder
+
+		for i, cell := range row {
+			padding := strings.Repeat(" ", widths[i]-displayWidth(cell))
+
+			switch {
+			case t.right[i]:
+				line.WriteString(padding + cell)
+			case i == len(row)-1:
+				// The last column isn't padded, so lines don't end in spaces.
+				line.WriteString(cell)
+			default:
+				line.WriteString(cell + padding)
+			}
+
+			if i != len(row)-1 {
+				line.WriteString("  ")
+			}
+		}
+
+		t.out.Println(line.String())
+	}
+
+	t.rows = nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestUseColour(t *testing.T) {
+	for _, key := range []string{"NO_COLOR", "TERM"} {
+		value, set := os.LookupEnv(key)
+		defer func(key string) {
+			if set {
+				os.Setenv(key, value)
+			} else {
+				os.Unsetenv(key)
+			}
+		}(key)
+	}
+
+	os.Unsetenv("NO_COLOR")
+	os.Setenv("TERM", "xterm")
+
+	True(t, useColour("auto", true))
+	False(t, useColour("auto", false), "expecting no colour when not writing to a terminal")
+	True(t, useColour("always", false))
+	False(t, useColour("never", true))
+
+	os.Setenv("NO_COLOR", "1")
+	False(t, useColour("auto", true), "expecting NO_COLOR to turn colour off")
+	True(t, useColour("always", true), "expecting --color=always to win over NO_COLOR")
+}
+
+func TestTruncate(t *testing.T) {
+	Equal(t, "short", truncate("short", 10))
+	Equal(t, "a long l…", truncate("a long line", 9))
+	Equal(t, "héllo…", truncate("héllo wörld", 6))
+	Equal(t, "\x1b[1mbold…\x1b[0m", truncate("\x1b[1mbold text\x1b[0m", 5), "expecting escape codes not to count and styles to be reset")
+	Equal(t, 9, displayWidth("\x1b[36m@?tag\x1b[0m and"))
+}
+
+func TestTable(t *testing.T) {
+	var buf bytes.Buffer
+	out := &output{w: &buf}
+
+	table := newTable(out, 1)
+	table.Row("Month", "Entries", "Notes")
+	table.Row("2020-08", "150", "a lot")
+	table.Row("2020-09", "7", "")
+	table.Flush()
+
+	Equal(t, "Month    Entries  Notes\n2020-08      150  a lot\n2020-09        7  \n", buf.String())
+
+	buf.Reset()
+	out.colour = true
+	out.width = 14
+
+	table = newTable(out)
+	table.Row(out.style(styleBold, "Tag"), "Description")
+	table.Row("@?food", "Entries about food")
+	table.Flush()
+
+	Equal(t, "\x1b[1mTag\x1b[0m     Descr…\x1b[0m\n@?food  Entri…\n", buf.String(), "expecting rows to be cut to the width of the terminal")
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	"github.com/albatross-org/go-albatross/publish"
+	"github.com/spf13/cobra"
+)
+
+// PublishCmd represents the publish command.
+var PublishCmd = &cobra.Command{
+	Use:   "publish <source> <target>",
+	Short: "upload exported files to a remote server",
+	Long: `publish uploads a file or folder, such as an exported site or a backup, to a remote target so that it can be put
+online without any other scripts.
+
+	$ albatross publish ~/exports/site sftp://me@example.com/var/www/site
+	Uploaded 132 files to sftp://me@example.com/var/www/site, 0 unchanged.
+
+Targets are given as URLs:
+
+	sftp://user@host:port/path    uploads using the 'sftp' command
+	rsync://user@host:port/path   uploads using 'rsync' over ssh
+	s3://bucket/prefix            uploads using the 'aws' command line tool
+	file:///path                  copies to a local folder
+
+For sftp and rsync, paths are absolute unless they start with "/~/", in which case they're relative to the home folder,
+like "sftp://me@example.com/~/public_html". The commands have to be installed and set up already, such as with ssh keys
+or an AWS profile.
+
+Only files which have changed since they were last published to the same target are uploaded. The checksums of files
+which have been uploaded are kept in the store's .albatross folder, so if publishing fails part of the way through, it
+carries on where it left off when run again. This means files changed on the target by something else won't be
+replaced unless they also change locally.
+
+Files which have been removed from the source since they were last published aren't deleted from the target unless
+--delete is given. To see what would be uploaded without uploading anything, use --dry-run.
+
+Text files are checked for anything which looks like a secret, such as AWS keys or private key blocks, before they're
+uploaded, and nothing is published if any are found unless --allow-secrets is given. Binary files aren't checked.
+
+Export profiles can publish their output after they run using the 'publish' key, see 'albatross export --help'.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		checkArg(err)
+
+		deleteRemoved, err := cmd.Flags().GetBool("delete")
+		checkArg(err)
+
+		batchSize, err := cmd.Flags().GetInt("batch-size")
+		checkArg(err)
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		allowSecrets, err := cmd.Flags().GetBool("allow-secrets")
+		checkArg(err)
+
+		if len(args) != 2 {
+			fmt.Println("Expecting exactly two arguments, the file or folder to publish and where to publish it:")
+			fmt.Println("")
+			fmt.Println("    $ albatross publish ~/exports/site sftp://me@example.com/var/www/site")
+			os.Exit(1)
+		}
+
+		publishSource(args[0], args[1], deleteRemoved, dryRun, allowSecrets, batchSize, outputJSON)
+	},
+}
+
+// publishSource publishes the file or folder source to the target, printing what was done.
+func publishSource(source, target string, deleteRemoved, dryRun, allowSecrets bool, batchSize int, outputJSON bool) {
+	source, err := filepath.Abs(source)
+	if err != nil {
+		log.Fatalf("Couldn't get path to %s: %s", source, err)
+	}
+
+	transport, err := publish.Open(target)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	publisher := publish.NewPublisher(transport, publishStatePath(source, target))
+	publisher.SetBatchSize(batchSize)
+
+	plan, err := publisher.Plan(source, deleteRemoved)
+	if err != nil {
+		log.Fatalf("Couldn't work out what to publish: %s", err)
+	}
+
+	if !allowSecrets {
+		refusePublishingSecrets(source, plan.Upload)
+	}
+
+	if outputJSON {
+		out, err := json.Marshal(plan)
+		if err != nil {
+			fmt.Println("Error marshalling publish plan:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		fmt.Println(string(out))
+	} else if dryRun {
+		for _, file := range plan.Upload {
+			fmt.Println("upload", file)
+		}
+
+		for _, file := range plan.Delete {
+			fmt.Println("delete", file)
+		}
+	}
+
+	if dryRun {
+		if !outputJSON {
+			fmt.Printf("Would upload %d files to %s and delete %d, %d unchanged.\n", len(plan.Upload), target, len(plan.Delete), plan.Unchanged)
+		}
+
+		return
+	}
+
+	err = publisher.Publish(source, plan)
+	if err != nil {
+		log.Fatalf("Couldn't publish %s: %s", source, err)
+	}
+
+	if !outputJSON {
+		fmt.Printf("Uploaded %d files to %s and deleted %d, %d unchanged.\n", len(plan.Upload), target, len(plan.Delete), plan.Unchanged)
+	}
+}
+
+// refusePublishingSecrets exits if any of the text files which are going to be uploaded contain something which looks
+// like a secret. Binary files, like EPUBs, can't be checked and are skipped.
+func refusePublishingSecrets(source string, files []string) {
+	dir := source
+	if info, err := os.Stat(source); err == nil && !info.IsDir() {
+		dir = filepath.Dir(source)
+	}
+
+	found := false
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			log.Fatalf("Couldn't check %s for secrets: %s", file, err)
+		}
+
+		if bytes.IndexByte(data, 0) != -1 {
+			continue
+		}
+
+		for _, secret := range albatross.FindSecrets(string(data)) {
+			if !found {
+				fmt.Fprintln(os.Stderr, "Not publishing because some files look like they contain secrets:")
+				fmt.Fprintln(os.Stderr, "")
+				found = true
+			}
+
+			fmt.Fprintf(os.Stderr, "    %s: %s\n", file, secret)
+		}
+	}
+
+	if found {
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Remove them, or use --allow-secrets to publish anyway.")
+		os.Exit(1)
+	}
+}
+
+// publishStatePath returns where the record of files published from the source to the target is kept.
+func publishStatePath(source, target string) string {
+	hash := sha256.Sum256([]byte(source + "\x00" + target))
+	return filepath.Join(storePath, ".albatross", "publish", hex.EncodeToString(hash[:8])+".json")
+}
+
+func init() {
+	rootCmd.AddCommand(PublishCmd)
+
+	PublishCmd.Flags().Bool("dry-run", false, "print what would be uploaded and deleted without doing it")
+	PublishCmd.Flags().Bool("delete", false, "delete files from the target which were published before but have since been removed")
+	PublishCmd.Flags().Int("batch-size", publish.DefaultBatchSize, "number of files to upload before saving progress")
+	PublishCmd.Flags().Bool("allow-secrets", false, "publish files even if they contain things which look like secrets")
+	PublishCmd.Flags().Bool("json", false, "output what is uploaded and deleted as JSON")
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+)
+
+// RecentCmd represents the recent command.
+var RecentCmd = &cobra.Command{
+	Use:   "recent",
+	Short: "list recently viewed entries",
+	Long: `recent lists the entries which have been viewed most recently, most recent first. Entries count as viewed when
+they're printed using 'contents', opened using 'update' or fetched from 'albatross get server --track-views':
+
+	$ albatross recent
+	2021-03-01 12:04  3h ago   school/physics/momentum  Momentum
+	2021-02-28 21:40  18h ago  food/pizza               Pizza
+
+To only list entries viewed within some amount of time, use --since with a duration like "12h", "2d" or "1w":
+
+	$ albatross recent --since 2d
+
+The get command can also be limited to the entries viewed most recently using --recent, which makes it easy to get
+back to something without remembering its path. For example, to open the entry viewed last again:
+
+	$ albatross get --recent 1 update
+
+Which entries have been viewed is kept in the store's '.albatross' folder, so viewing doesn't create changes to commit.
+Only the last 100 entries are remembered, which can be changed by setting 'recent.limit' in the store's config. To stop
+recording views, set 'recent.track' to false. To forget every entry which has been viewed, use --clear.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		number, err := cmd.Flags().GetInt("number")
+		checkArg(err)
+
+		since, err := cmd.Flags().GetString("since")
+		checkArg(err)
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		clear, err := cmd.Flags().GetBool("clear")
+		checkArg(err)
+
+		if clear {
+			err = store.ClearRecentViews()
+			if err != nil {
+				log.Fatalf("Couldn't clear recently viewed entries: %s", err)
+			}
+
+			fmt.Println("Cleared recently viewed entries.")
+			return
+		}
+
+		var after time.Time
+		if since != "" {
+			ago, err := parseDuration(since)
+			if err != nil {
+				fmt.Printf("Invalid --since %q: %s\n", since, err)
+				os.Exit(1)
+			}
+
+			after = time.Now().Add(-ago)
+		}
+
+		views, err := store.RecentViews()
+		if err != nil {
+			log.Fatalf("Couldn't get recently viewed entries: %s", err)
+		}
+
+		collection := recentCollection()
+
+		recent := []recentView{}
+		for _, view := range views {
+			if view.Time.Before(after) {
+				break
+			}
+
+			if number > 0 && len(recent) == number {
+				break
+			}
+
+			title := ""
+			if collection != nil {
+				entry := collection.ResolveLink(entries.Link{Path: view.Path, Type: entries.LinkPathNoName})
+				if entry == nil {
+					// The entry has been deleted since it was viewed.
+					continue
+				}
+
+				title = entry.Title
+			}
+
+			recent = append(recent, recentView{View: view, Title: title})
+		}
+
+		if outputJSON {
+			out, err := json.Marshal(recent)
+			if err != nil {
+				fmt.Println("Error marshalling recently viewed entries:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		pathWidth, agoWidth := 0, 0
+		for _, view := range recent {
+			if len(view.Path) > pathWidth {
+				pathWidth = len(view.Path)
+			}
+
+			if ago := len(viewedAgo(view.Time)); ago > agoWidth {
+				agoWidth = ago
+			}
+		}
+
+		for _, view := range recent {
+			fmt.Printf("%s  %-*s  %-*s  %s\n", view.Time.Format("2006-01-02 15:04"), agoWidth, viewedAgo(view.Time), pathWidth, view.Path, view.Title)
+		}
+	},
+}
+
+// recentView is a view along with the title of the entry, for printing.
+type recentView struct {
+	albatross.View
+	Title string `json:"title,omitempty"`
+}
+
+// recentCollection returns the collection used to look up the titles of recently viewed entries. Since a list of paths
+// isn't worth decrypting the whole store for, it returns nil if the store is encrypted and there isn't a daemon with it
+// decrypted.
+func recentCollection() *entries.Collection {
+	if daemonClient == nil {
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			return nil
+		}
+	}
+
+	collection, err := storeCollection()
+	if err != nil {
+		log.Fatalf("Couldn't get entries: %s", err)
+	}
+
+	return collection
+}
+
+// viewedAgo returns roughly how long ago a time was, such as "3h ago".
+func viewedAgo(t time.Time) string {
+	ago := time.Since(t)
+
+	switch {
+	case ago < time.Minute:
+		return "just now"
+	case ago < time.Hour:
+		return fmt.Sprintf("%dm ago", int(ago.Minutes()))
+	case ago < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(ago.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(ago.Hours()/24))
+	}
+}
+
+// recordView records the entries as having been viewed. Failing to record them isn't fatal since the entries have
+// already been shown.
+func recordView(list ...*entries.Entry) {
+	paths := []string{}
+	for _, entry := range list {
+		if pretendPaths[entry.Path] {
+			continue
+		}
+
+		paths = append(paths, entry.Path)
+	}
+
+	if len(paths) == 0 {
+		return
+	}
+
+	err := store.RecordView(paths...)
+	if err != nil {
+		log.Errorf("Couldn't record entries as viewed: %s", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(RecentCmd)
+
+	RecentCmd.Flags().IntP("number", "n", 10, "number of entries to list, 0 lists every entry remembered")
+	RecentCmd.Flags().String("since", "", "only list entries viewed within this long, like '12h' or '2d'")
+	RecentCmd.Flags().Bool("json", false, "output recently viewed entries as JSON")
+	RecentCmd.Flags().Bool("clear", false, "forget every entry which has been viewed")
+}
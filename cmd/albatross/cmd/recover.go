@@ -0,0 +1,446 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// recoveryPrefix is the start of the names of recovery files, which are saved in the temporary directory when an entry
+// which has been edited can't be saved to the store.
+const recoveryPrefix = "albatross-recover"
+
+// recoveryInfoExt is added to the name of a recovery file to get the name of the file describing it.
+const recoveryInfoExt = ".json"
+
+// The statuses of recovery files, see recoveryStatus.
+const (
+	recoveryReady        = "ready"
+	recoverySame         = "same as entry"
+	recoveryChanged      = "entry changed since"
+	recoveryDeleted      = "entry deleted since"
+	recoveryOtherStore   = "other store"
+	recoveryUnknownEntry = "unknown entry"
+)
+
+// recoveryInfo describes which entry a recovery file is for. It's saved as JSON next to the recovery file.
+type recoveryInfo struct {
+	// Store is the path to the store the entry is in.
+	Store string `json:"store"`
+
+	// Path is the path to the entry, such as "food/pizza".
+	Path string `json:"path"`
+
+	// Original is the hash of the entry's contents before it was edited, so that changes made since can be noticed.
+	Original string `json:"original"`
+
+	// Saved is when the recovery file was saved.
+	Saved time.Time `json:"saved"`
+}
+
+// recoveryFile is a recovery file found in the temporary directory.
+type recoveryFile struct {
+	// File is the path to the recovery file.
+	File string
+
+	// Info describes which entry the file is for. It's nil for files saved by older versions, which didn't record it.
+	Info *recoveryInfo
+
+	// Saved is when the file was saved.
+	Saved time.Time
+}
+
+// RecoverCmd represents the recover command.
+var RecoverCmd = &cobra.Command{
+	Use:   "recover",
+	Short: "find and apply entries which couldn't be saved",
+	Long: `recover finds the recovery files saved when an entry which has been edited using 'albatross create' or
+'albatross get update' can't be saved, and lets you apply or discard them.
+
+	$ albatross recover list
+	albatross-recover-123.md  food/pizza  2020-10-26 16:03  ready
+
+	$ albatross recover list --diff
+	$ albatross recover apply albatross-recover-123.md
+	$ albatross recover discard albatross-recover-123.md
+
+The status of each file is one of:
+
+	ready                 The entry hasn't changed since it was edited, so the file can be applied.
+	same as entry         The entry already has the contents of the file, so it can be discarded.
+	entry changed since   The entry has been changed since it was edited. Applying it would lose those changes.
+	entry deleted since   The entry has been deleted since it was edited. Applying it creates it again.
+	other store           The file is for an entry in another store. Use --store to apply it.
+	unknown entry         The file was saved by an older version which didn't record which entry it was for.
+
+Files which aren't ready are only applied if --force is given. Files for an unknown entry need --path to say which
+entry to apply them to. Recovery files are kept in the temporary directory, so they may be removed when the computer
+restarts.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		RecoverListCmd.Run(cmd, args)
+	},
+}
+
+// RecoverListCmd represents the recover list command.
+var RecoverListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list recovery files",
+	Long:  `list prints each recovery file, the entry it's for, when it was saved and its status, see 'albatross recover --help'.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		showDiff := false
+		if cmd.Flags().Lookup("diff") != nil {
+			var err error
+			showDiff, err = cmd.Flags().GetBool("diff")
+			checkArg(err)
+		}
+
+		files, err := findRecoveryFiles(os.TempDir())
+		if err != nil {
+			log.Fatalf("Couldn't find recovery files: %s", err)
+		}
+
+		if len(files) == 0 {
+			fmt.Println("No recovery files found.")
+			return
+		}
+
+		collection := recoveryCollection()
+
+		for _, file := range files {
+			entryPath := "?"
+			if file.Info != nil {
+				entryPath = file.Info.Path
+			}
+
+			fmt.Printf(
+				"%s  %s  %s  %s\n",
+				filepath.Base(file.File),
+				entryPath,
+				file.Saved.Format("2006-01-02 15:04"),
+				recoveryStatus(file, collection, storePath),
+			)
+
+			if !showDiff {
+				continue
+			}
+
+			contents, err := ioutil.ReadFile(file.File)
+			if err != nil {
+				log.Fatalf("Couldn't read recovery file: %s", err)
+			}
+
+			var current string
+			if file.Info != nil && collection != nil {
+				if entry := collection.Get(file.Info.Path); entry != nil {
+					current = entry.OriginalContents
+				}
+			}
+
+			fmt.Println()
+			fmt.Print(entries.UnifiedDiff("a/"+entryPath+"/entry.md", "b/"+entryPath+"/entry.md", current, string(contents), 3))
+			fmt.Println()
+		}
+	},
+}
+
+// RecoverApplyCmd represents the recover apply command.
+var RecoverApplyCmd = &cobra.Command{
+	Use:   "apply [file]",
+	Short: "save a recovery file to its entry",
+	Long: `apply saves the contents of a recovery file to the entry it's for, creating the entry if it doesn't exist, and
+then removes the recovery file. Files which aren't ready need --force, see 'albatross recover --help'.
+
+	$ albatross recover apply albatross-recover-123.md
+	$ albatross recover apply albatross-recover-456 --path food/pizza --force`,
+	Args: cobra.ExactArgs(1),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		entryPath, err := cmd.Flags().GetString("path")
+		checkArg(err)
+
+		force, err := cmd.Flags().GetBool("force")
+		checkArg(err)
+
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		file, err := findRecoveryFile(os.TempDir(), args[0])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		collection := recoveryCollection()
+		status := recoveryStatus(file, collection, storePath)
+
+		if entryPath == "" {
+			if file.Info == nil {
+				fmt.Println("The recovery file doesn't say which entry it's for. Use --path to give one.")
+				os.Exit(1)
+			}
+
+			entryPath = file.Info.Path
+		} else if status == recoveryUnknownEntry || status == recoveryOtherStore {
+			status = recoveryReady
+		}
+
+		if status != recoveryReady && status != recoverySame && !force {
+			fmt.Printf("Not applying %s because its status is '%s'. Use --force to apply it anyway.\n", filepath.Base(file.File), status)
+			os.Exit(1)
+		}
+
+		contents, err := ioutil.ReadFile(file.File)
+		if err != nil {
+			log.Fatalf("Couldn't read recovery file: %s", err)
+		}
+
+		if collection.Get(entryPath) != nil {
+			err = store.Update(entryPath, string(contents))
+		} else {
+			err = store.Create(entryPath, string(contents))
+		}
+		if err != nil {
+			log.Fatalf("Couldn't save entry %s: %s", entryPath, err)
+		}
+
+		err = removeRecoveryFile(file)
+		if err != nil {
+			log.Fatalf("Saved entry %s but couldn't remove the recovery file: %s", entryPath, err)
+		}
+
+		fmt.Printf("Recovered entry %s from %s.\n", entryPath, filepath.Base(file.File))
+	},
+}
+
+// RecoverDiscardCmd represents the recover discard command.
+var RecoverDiscardCmd = &cobra.Command{
+	Use:   "discard [files...]",
+	Short: "remove recovery files",
+	Long: `discard removes recovery files without applying them.
+
+	$ albatross recover discard albatross-recover-123.md`,
+	Args: cobra.MinimumNArgs(1),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, arg := range args {
+			file, err := findRecoveryFile(os.TempDir(), arg)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			err = removeRecoveryFile(file)
+			if err != nil {
+				log.Fatalf("Couldn't remove recovery file: %s", err)
+			}
+
+			fmt.Println("Discarded", filepath.Base(file.File))
+		}
+	},
+}
+
+// saveRecovery saves content written for the entry at the path given to a recovery file so that it isn't lost when it
+// can't be saved to the store, and prints where it is along with the reason given. original is what the entry contained
+// before it was edited.
+func saveRecovery(entryPath, original, content, reason string) {
+	file, err := writeRecoveryFile(os.TempDir(), recoveryInfo{
+		Store:    storePath,
+		Path:     entryPath,
+		Original: contentHash(original),
+		Saved:    time.Now(),
+	}, content)
+	if err != nil {
+		logrus.Fatal("Couldn't save recovery entry to a temporary file. You're on your own! ", err)
+	}
+
+	fmt.Println(reason, "A copy of the entry has been saved to:", file)
+	fmt.Println("Use 'albatross recover' to apply it later.")
+}
+
+// writeRecoveryFile saves content to a new recovery file in the folder given, along with the info describing it. It
+// returns the path to the recovery file.
+func writeRecoveryFile(dir string, info recoveryInfo, content string) (string, error) {
+	f, err := ioutil.TempFile(dir, recoveryPrefix+"-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	_, err = f.Write([]byte(content))
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return "", err
+	}
+
+	return f.Name(), ioutil.WriteFile(f.Name()+recoveryInfoExt, data, 0600)
+}
+
+// findRecoveryFiles returns the recovery files in the folder given, oldest first.
+func findRecoveryFiles(dir string) ([]recoveryFile, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []recoveryFile{}
+
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasPrefix(info.Name(), recoveryPrefix) || strings.HasSuffix(info.Name(), recoveryInfoExt) {
+			continue
+		}
+
+		file, err := readRecoveryFile(filepath.Join(dir, info.Name()), info)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Saved.Before(files[j].Saved) })
+
+	return files, nil
+}
+
+// findRecoveryFile returns the recovery file with the name given in the folder given. Only recovery files can be found,
+// so that other files can't be applied or removed by mistake.
+func findRecoveryFile(dir, name string) (recoveryFile, error) {
+	name = filepath.Base(name)
+
+	if !strings.HasPrefix(name, recoveryPrefix) || strings.HasSuffix(name, recoveryInfoExt) {
+		return recoveryFile{}, fmt.Errorf("%s isn't a recovery file, see 'albatross recover list'", name)
+	}
+
+	file := filepath.Join(dir, name)
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return recoveryFile{}, fmt.Errorf("no recovery file named %s, see 'albatross recover list'", name)
+	}
+
+	return readRecoveryFile(file, info)
+}
+
+// readRecoveryFile reads the info for the recovery file given, if there is any.
+func readRecoveryFile(file string, stat os.FileInfo) (recoveryFile, error) {
+	recovery := recoveryFile{File: file, Saved: stat.ModTime()}
+
+	data, err := ioutil.ReadFile(file + recoveryInfoExt)
+	if os.IsNotExist(err) {
+		return recovery, nil
+	} else if err != nil {
+		return recovery, err
+	}
+
+	var info recoveryInfo
+
+	err = json.Unmarshal(data, &info)
+	if err != nil {
+		return recovery, fmt.Errorf("couldn't parse %s: %w", file+recoveryInfoExt, err)
+	}
+
+	recovery.Info = &info
+	recovery.Saved = info.Saved
+
+	return recovery, nil
+}
+
+// removeRecoveryFile removes a recovery file along with its info.
+func removeRecoveryFile(file recoveryFile) error {
+	err := os.Remove(file.File + recoveryInfoExt)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return os.Remove(file.File)
+}
+
+// recoveryStatus returns the status of a recovery file compared to the entries in the collection, which are from the
+// store at the path given. See 'albatross recover --help' for what each status means.
+func recoveryStatus(file recoveryFile, collection *entries.Collection, store string) string {
+	if file.Info == nil {
+		return recoveryUnknownEntry
+	}
+
+	if !samePath(file.Info.Store, store) || collection == nil {
+		return recoveryOtherStore
+	}
+
+	entry := collection.Get(file.Info.Path)
+	if entry == nil {
+		return recoveryDeleted
+	}
+
+	contents, err := ioutil.ReadFile(file.File)
+	if err == nil && string(contents) == entry.OriginalContents {
+		return recoverySame
+	}
+
+	if contentHash(entry.OriginalContents) != file.Info.Original {
+		return recoveryChanged
+	}
+
+	return recoveryReady
+}
+
+// recoveryCollection returns the entries in the store, or nil if they can't be read, such as because the store is
+// encrypted.
+func recoveryCollection() *entries.Collection {
+	collection, err := storeCollection()
+	if err != nil {
+		log.Debugf("Couldn't read entries to compare recovery files to: %s", err)
+		return nil
+	}
+
+	return collection
+}
+
+// contentHash returns a hash of the content given, used to tell whether an entry has changed.
+func contentHash(content string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+}
+
+// samePath returns true if the two paths are to the same place.
+func samePath(a, b string) bool {
+	a, errA := filepath.Abs(a)
+	b, errB := filepath.Abs(b)
+
+	return errA == nil && errB == nil && a == b
+}
+
+func init() {
+	rootCmd.AddCommand(RecoverCmd)
+
+	RecoverCmd.AddCommand(RecoverListCmd)
+	RecoverCmd.AddCommand(RecoverApplyCmd)
+	RecoverCmd.AddCommand(RecoverDiscardCmd)
+
+	RecoverListCmd.Flags().Bool("diff", false, "show how each recovery file differs from its entry")
+
+	RecoverApplyCmd.Flags().String("path", "", "entry to apply the recovery file to, instead of the one it was saved for")
+	RecoverApplyCmd.Flags().Bool("force", false, "apply the recovery file even if it isn't ready")
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestRecoveryFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "albatross-recover-test")
+	if err != nil {
+		t.Fatalf("not expecting error creating temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	storeDir := filepath.Join(dir, "store")
+	original := "---\ntitle: Pizza\n---\n\nCheese."
+
+	collection := entries.NewCollection()
+
+	err = collection.Add(&entries.Entry{Path: "food/pizza", Title: "Pizza", OriginalContents: original})
+	if err != nil {
+		t.Fatalf("not expecting error adding entry: %s", err)
+	}
+
+	info := recoveryInfo{Store: storeDir, Path: "food/pizza", Original: contentHash(original), Saved: time.Now()}
+
+	ready, err := writeRecoveryFile(dir, info, "---\ntitle: Pizza\n---\n\nCheese and tomato.")
+	if err != nil {
+		t.Fatalf("not expecting error writing recovery file: %s", err)
+	}
+
+	same, err := writeRecoveryFile(dir, info, original)
+	if err != nil {
+		t.Fatalf("not expecting error writing recovery file: %s", err)
+	}
+
+	info.Original = contentHash("---\ntitle: Pizza\n---\n\nBread.")
+	info.Saved = info.Saved.Add(time.Second)
+
+	changed, err := writeRecoveryFile(dir, info, "---\ntitle: Pizza\n---\n\nBread and cheese.")
+	if err != nil {
+		t.Fatalf("not expecting error writing recovery file: %s", err)
+	}
+
+	info.Path = "food/chips"
+	info.Saved = info.Saved.Add(time.Second)
+
+	deleted, err := writeRecoveryFile(dir, info, "Chips.")
+	if err != nil {
+		t.Fatalf("not expecting error writing recovery file: %s", err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, "unrelated.md"), []byte("Not a recovery file."), 0644)
+	if err != nil {
+		t.Fatalf("not expecting error writing file: %s", err)
+	}
+
+	files, err := findRecoveryFiles(dir)
+	if err != nil {
+		t.Fatalf("not expecting error finding recovery files: %s", err)
+	}
+
+	if !Len(t, files, 4) {
+		return
+	}
+
+	statuses := map[string]string{}
+	for _, file := range files {
+		statuses[file.File] = recoveryStatus(file, collection, storeDir)
+	}
+
+	Equal(t, map[string]string{
+		ready:   recoveryReady,
+		same:    recoverySame,
+		changed: recoveryChanged,
+		deleted: recoveryDeleted,
+	}, statuses)
+
+	Equal(t, deleted, files[3].File, "expecting recovery files to be sorted by when they were saved")
+	Equal(t, recoveryOtherStore, recoveryStatus(files[0], collection, filepath.Join(dir, "other")))
+
+	file, err := findRecoveryFile(dir, filepath.Base(ready))
+	if NoError(t, err) {
+		Equal(t, "food/pizza", file.Info.Path)
+	}
+
+	_, err = findRecoveryFile(dir, "unrelated.md")
+	Error(t, err, "expecting only recovery files to be found")
+
+	_, err = findRecoveryFile(dir, filepath.Base(ready)+recoveryInfoExt)
+	Error(t, err, "expecting the info for recovery files not to be found")
+
+	NoError(t, removeRecoveryFile(file))
+	_, err = os.Stat(ready)
+	True(t, os.IsNotExist(err), "expecting recovery file to be removed")
+
+	_, err = os.Stat(ready + recoveryInfoExt)
+	True(t, os.IsNotExist(err), "expecting recovery file's info to be removed")
+
+	// Recovery files saved by older versions don't have any info.
+	old := filepath.Join(dir, recoveryPrefix+"123")
+
+	err = ioutil.WriteFile(old, []byte("Cheese."), 0600)
+	if err != nil {
+		t.Fatalf("not expecting error writing file: %s", err)
+	}
+
+	file, err = findRecoveryFile(dir, old)
+	if NoError(t, err) {
+		Nil(t, file.Info)
+		Equal(t, recoveryUnknownEntry, recoveryStatus(file, collection, storeDir))
+	}
+}
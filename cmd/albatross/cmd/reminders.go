@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+)
+
+// RemindersCmd represents the reminders command.
+var RemindersCmd = &cobra.Command{
+	Use:   "reminders",
+	Short: "list reminders which are due and entries which have expired",
+	Long: `reminders lists the entries with reminders which are due and the entries which have expired, using the 'remind'
+and 'expires' keys in their front matter:
+
+	---
+	title: Renew passport
+	remind: 2021-04-01
+	expires: "2022-01-01 09:00"
+	---
+
+	$ albatross reminders
+	2021-04-01 00:00  remind   admin/passport  Renew passport
+
+Dates are in local time and are either a date, which is due at midnight, or a date and time. A key can also be given
+a list of dates, like 'remind: [2021-04-01, 2021-05-01]'. Entries whose dates can't be parsed are reported by
+'albatross lint'.
+
+To also list reminders which will be due soon, use --within with a duration like "7d", "2w" or "12h":
+
+	$ albatross reminders --within 7d
+
+Notifications
+-------------
+
+Reminders can be sent as desktop notifications using --notify, or POSTed as JSON to a URL using --webhook, such as
+for a chat bot. Each reminder is only sent once, which is recorded in the store's '.albatross' folder, so these can be
+run regularly, such as from cron:
+
+	$ albatross reminders --notify --webhook https://example.com/hooks/albatross
+
+The body of the webhook request is a JSON array of reminders like those given by --json. Desktop notifications use
+notify-send on Linux and osascript on macOS.
+
+To keep checking without cron, use --every. Reminders are checked straight away and then at the interval given until
+the command is stopped. It's best run alongside 'albatross daemon start' so that the entries it checks are kept up to
+date, but without one the store is watched for changes itself:
+
+	$ albatross reminders --notify --every 10m`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		within, err := cmd.Flags().GetString("within")
+		checkArg(err)
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		notify, err := cmd.Flags().GetBool("notify")
+		checkArg(err)
+
+		webhook, err := cmd.Flags().GetString("webhook")
+		checkArg(err)
+
+		every, err := cmd.Flags().GetDuration("every")
+		checkArg(err)
+
+		if every != 0 && !notify && webhook == "" {
+			fmt.Println("--every needs --notify or --webhook, since reminders are only sent once.")
+			os.Exit(1)
+		}
+
+		var ahead time.Duration
+		if within != "" {
+			ahead, err = parseDuration(within)
+			if err != nil {
+				fmt.Printf("Invalid --within %q: %s\n", within, err)
+				os.Exit(1)
+			}
+		}
+
+		if daemonClient == nil {
+			encrypted, err := store.Encrypted()
+			if err != nil {
+				log.Fatal(err)
+			} else if encrypted && every != 0 {
+				fmt.Println("Can't use --every with an encrypted store. Start a daemon using 'albatross daemon start --keep-encrypted' first.")
+				os.Exit(1)
+			} else if encrypted {
+				decryptStore()
+
+				if !leaveDecrypted {
+					defer encryptStore()
+				}
+			}
+		}
+
+		check := func() {
+			collection, err := storeCollection()
+			if err != nil {
+				log.Fatalf("Couldn't get entries: %s", err)
+			}
+
+			now := time.Now()
+			due := []albatross.Reminder{}
+
+			for _, reminder := range albatross.Reminders(collection.List()) {
+				if reminder.IsDue(now.Add(ahead)) {
+					due = append(due, reminder)
+				}
+			}
+
+			if notify || webhook != "" {
+				err = sendReminders(due, notify, webhook)
+				if err != nil {
+					log.Errorf("Couldn't send reminders: %s", err)
+				}
+			}
+
+			if every != 0 {
+				return
+			}
+
+			if outputJSON {
+				out, err := json.Marshal(due)
+				if err != nil {
+					fmt.Println("Error marshalling reminders:")
+					fmt.Println(err)
+					os.Exit(1)
+				}
+
+				fmt.Println(string(out))
+				return
+			}
+
+			out := newOutput(os.Stdout)
+
+			table := newTable(out)
+			for _, reminder := range due {
+				kind := out.style(styleWarning, reminder.Kind)
+				if reminder.Kind == albatross.ReminderExpires {
+					kind = out.style(styleError, reminder.Kind)
+				}
+
+				table.Row(reminder.Due.Format("2006-01-02 15:04"), kind, reminder.Path, reminder.Title)
+			}
+
+			table.Flush()
+		}
+
+		check()
+
+		if every == 0 {
+			return
+		}
+
+		if daemonClient == nil {
+			go func() {
+				err := store.Watch(context.Background(), nil)
+				if err != nil {
+					log.Errorf("Stopped watching store for changes: %s", err)
+				}
+			}()
+		}
+
+		for range time.Tick(every) {
+			check()
+		}
+	},
+}
+
+// sendReminders sends the reminders given which haven't been sent before, as desktop notifications if notify is true and
+// to the webhook if it isn't blank. Reminders are only recorded as sent if sending them worked.
+func sendReminders(reminders []albatross.Reminder, notify bool, webhook string) error {
+	unsent, err := store.UnnotifiedReminders(reminders)
+	if err != nil {
+		return err
+	}
+
+	if len(unsent) == 0 {
+		return nil
+	}
+
+	if webhook != "" {
+		err = postReminders(webhook, unsent)
+		if err != nil {
+			return err
+		}
+	}
+
+	if notify {
+		for _, reminder := range unsent {
+			err = desktopNotification(reminderMessage(reminder), reminder.Path)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return store.MarkNotified(unsent...)
+}
+
+// reminderMessage returns the summary of a reminder shown in a notification.
+func reminderMessage(reminder albatross.Reminder) string {
+	title := reminder.Title
+	if title == "" {
+		title = reminder.Path
+	}
+
+	if reminder.Kind == albatross.ReminderExpires {
+		return "Expired: " + title
+	}
+
+	return "Reminder: " + title
+}
+
+// postReminders sends the reminders to the webhook as a JSON array.
+func postReminders(webhook string, reminders []albatross.Reminder) error {
+	body, err := json.Marshal(reminders)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	resp, err := client.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+
+	return nil
+}
+
+// desktopNotification shows a desktop notification with the summary and body given.
+func desktopNotification(summary, body string) error {
+	var command *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		command = exec.Command("osascript", "-e", fmt.Sprintf("display notification %s with title %s", strconv.Quote(body), strconv.Quote(summary)))
+	default:
+		command = exec.Command("notify-send", "--app-name=albatross", summary, body)
+	}
+
+	out, err := command.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("couldn't show notification using %s: %w: %s", command.Path, err, bytes.TrimSpace(out))
+	}
+
+	return nil
+}
+
+// reRelativeDuration matches durations with day and week units, like "7d" or "2w", which time.ParseDuration doesn't support.
+var reRelativeDuration = regexp.MustCompile(`^(\d+)([dw])$`)
+
+// parseDuration parses a duration like "7d", "2w" or "12h".
+func parseDuration(s string) (time.Duration, error) {
+	if match := reRelativeDuration.FindStringSubmatch(s); match != nil {
+		n, err := strconv.Atoi(match[1])
+		if err != nil {
+			return 0, err
+		}
+
+		if match[2] == "w" {
+			n *= 7
+		}
+
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	return time.ParseDuration(s)
+}
+
+func init() {
+	rootCmd.AddCommand(RemindersCmd)
+
+	RemindersCmd.Flags().String("within", "", "also list reminders due within this long, like '7d' or '12h'")
+	RemindersCmd.Flags().Bool("json", false, "output reminders as JSON")
+	RemindersCmd.Flags().Bool("notify", false, "show a desktop notification for each reminder which hasn't been sent before")
+	RemindersCmd.Flags().String("webhook", "", "POST reminders which haven't been sent before to this URL as JSON")
+	RemindersCmd.Flags().Duration("every", 0, "keep checking for reminders at this interval, like '10m'")
+}
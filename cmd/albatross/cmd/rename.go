@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// RenameCmd represents the rename command.
+var RenameCmd = &cobra.Command{
+	Use:     "rename <old path> <new path>",
+	Short:   "move an entry to a new path",
+	Aliases: []string{"move", "mv"},
+	Long: `rename moves an entry to a new path, along with its attachments and any entries nested inside it.
+
+	$ albatross rename food/pizza recipes/pizza
+	Moved food/pizza to recipes/pizza.
+
+Moving an entry's folder by hand breaks the path links which point to it, like {{food/pizza}}. Give --rewrite-links to
+change them to point to the new path:
+
+	$ albatross rename food/pizza recipes/pizza --rewrite-links
+	Moved food/pizza to recipes/pizza.
+	Rewrote links in food/ice-cream.
+
+Without --rewrite-links, the entries which still link to the old path are listed. If the store is using git, the move and
+the rewritten links are recorded in a single commit.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			fmt.Println("Expecting exactly two arguments: the path of the entry and the path to move it to")
+			fmt.Println("For example:")
+			fmt.Println("")
+			fmt.Println("$ albatross rename food/pizza recipes/pizza")
+			os.Exit(1)
+		}
+
+		rewriteLinks, err := cmd.Flags().GetBool("rewrite-links")
+		checkArg(err)
+
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		linking, err := store.Rename(args[0], args[1], rewriteLinks)
+		if err != nil {
+			log.Fatal("Couldn't move entry: ", err)
+		}
+
+		fmt.Printf("Moved %s to %s.\n", args[0], args[1])
+
+		for _, path := range linking {
+			if rewriteLinks {
+				fmt.Printf("Rewrote links in %s.\n", path)
+			} else {
+				fmt.Printf("%s still links to the old path, use --rewrite-links to fix it.\n", path)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(RenameCmd)
+
+	RenameCmd.Flags().Bool("rewrite-links", false, "change path links to the entry, and entries inside it, to point to the new path")
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -12,6 +13,8 @@ import (
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 
+	"github.com/albatross-org/go-albatross/daemon"
+	"github.com/albatross-org/go-albatross/entries"
 	albatross "github.com/albatross-org/go-albatross/pkg/core"
 )
 
@@ -19,11 +22,24 @@ var cfgFile string
 var logLvl string
 var leaveDecrypted bool
 var disableGit bool
+var noDaemon bool
 
 var storeName string
 var storePath string
 
 var store *albatross.Store
+
+// registry has the other stores in the config, for resolving links to them like {{work:notes/project}}.
+var registry *albatross.Registry
+
+// commandArgs are the arguments the program was run with, after any aliases have been expanded. See 'albatross alias'.
+var commandArgs = os.Args[1:]
+
+// daemonClient is the client for the daemon running for the store, or nil if there isn't one. See 'albatross daemon'.
+var daemonClient *daemon.Client
+
+// storeLoadTime is how long loading the store took, which includes parsing the entries if it isn't encrypted.
+var storeLoadTime time.Duration
 var log *logrus.Logger
 
 // rootCmd represents the base command when called without any subcommands
@@ -109,7 +125,7 @@ See the README: https://github.com/albatross-org/go-albatross/tree/master/cmd/al
 		} else {
 			fmt.Println("  Encrypted: no")
 
-			collection, err := store.Collection()
+			collection, err := storeCollection()
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -129,6 +145,20 @@ See the README: https://github.com/albatross-org/go-albatross/tree/master/cmd/al
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	aliases, err := loadAliases(configFlag(commandArgs))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	commandArgs, err = expandAliases(commandArgs, aliases)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	rootCmd.SetArgs(commandArgs)
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -136,7 +166,7 @@ func Execute() {
 }
 
 func init() {
-	cobra.OnInitialize(initLogging, initConfig, initStore)
+	cobra.OnInitialize(initLogging, checkColourMode, initConfig, initStore)
 
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
@@ -144,9 +174,11 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/albatross/config.yaml)")
 	rootCmd.PersistentFlags().StringVar(&logLvl, "level", "info", "logging level (trace, debug, info, warning, error, fatal, panic)")
-	rootCmd.PersistentFlags().StringVar(&storeName, "store", "default", "store to use, as defined in config file (e.g. default, thesis)")
+	rootCmd.PersistentFlags().StringVar(&storeName, "store", "default", "store to use, as defined in config file (e.g. default, thesis), see 'albatross stores --help' for the default")
 	rootCmd.PersistentFlags().BoolVarP(&leaveDecrypted, "leave-decrypted", "l", false, "whether to leave the store decrypted or encrypt it again after decrypting it")
 	rootCmd.PersistentFlags().BoolVarP(&disableGit, "disable-git", "d", false, "don't use git for version control (mainly used when you want to make commits by hand)")
+	rootCmd.PersistentFlags().BoolVar(&noDaemon, "no-daemon", false, "don't use the daemon even if one is running for the store")
+	rootCmd.PersistentFlags().StringVar(&colourMode, "color", "auto", "when to use colour in output: 'auto', 'always' or 'never', auto is off if NO_COLOR is set")
 }
 
 // getConfigDirectory gets the configuration directory that should be used for the program.
@@ -196,7 +228,13 @@ func initConfig() {
 
 // initStore sets the store using the configuration the program has.
 func initStore() {
-	storePath = viper.GetString(fmt.Sprintf("%s.path", storeName))
+	if storeNotNeeded() {
+		return
+	}
+
+	var reason string
+	storeName, storePath, reason = chooseStore(rootCmd.PersistentFlags().Changed("store"))
+
 	if storePath == "" {
 		fmt.Printf("Couldn't find path for store '%s'.\n", storeName)
 		fmt.Printf("Make sure you have an path in your config file for that store, something like:\n\n")
@@ -208,20 +246,58 @@ func initStore() {
 	}
 
 	log.Debugf(
-		"Using store named '%s', located at: %s",
+		"Using store named '%s' (from %s), located at: %s",
 		storeName,
+		reason,
 		storePath, // This really doesn't seem ideal.
 	)
 
 	var err error
-	store, err = albatross.Load(storePath)
+	start := time.Now()
+
+	if !noDaemon {
+		daemonClient, err = daemon.Dial(storePath)
+		if err != nil {
+			log.Debug(err)
+		}
+	}
+
+	// If there's a daemon, it already has the entries so there's no need to parse them.
+	if daemonClient != nil {
+		store, err = albatross.LoadLazy(storePath)
+	} else {
+		store, err = albatross.Load(storePath)
+	}
 	if err != nil {
 		logrus.Fatal(err)
 	}
 
+	storeLoadTime = time.Since(start)
+
 	if disableGit {
 		store.DisableGit()
 	}
+
+	registry = storeRegistry(storeName, store)
+	store.SetRegistry(registry)
+}
+
+// storeCollection returns the store's collection, from the daemon if one is running.
+func storeCollection() (*entries.Collection, error) {
+	if daemonClient != nil {
+		collection, err := daemonClient.Collection()
+		if err != nil {
+			return nil, err
+		}
+
+		if registry != nil {
+			collection.SetRegistry(registry)
+		}
+
+		return collection, nil
+	}
+
+	return store.Collection()
 }
 
 // initLogging initialises the logger.
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/spf13/cobra"
+)
+
+// SchemaCmd represents the schema command.
+var SchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "work with the schema of entry front matter",
+	Long: `schema lets you inspect the structure of the front matter used in a store.
+
+See the available subcommands for more information.`,
+}
+
+// SchemaGenerateCmd represents the 'schema generate' command.
+var SchemaGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "infer a JSON Schema from entry front matter",
+	Long: `generate infers a JSON Schema (draft-07) from the metadata actually used across entries in the store.
+
+	$ albatross schema generate > schema.json
+
+For every key in the front matter it records:
+
+	- The JSON types seen, such as "string" or "integer".
+	- How often the key is used, in the description and the "x-frequency" field.
+	- Candidate enum values if the key only ever takes a few distinct values that are repeated.
+
+Nested maps and lists are described too. Keys present in every entry are marked as required. This can be
+changed using --required-threshold, which takes a fraction between 0 and 1:
+
+	$ albatross schema generate --required-threshold 0.9
+	# Mark keys used in 90% of entries as required.
+
+The maximum number of distinct values for a key to be considered an enum can be changed with --enum-threshold.
+Setting it to 0 disables enum detection.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		enumThreshold, err := cmd.Flags().GetInt("enum-threshold")
+		checkArg(err)
+
+		requiredThreshold, err := cmd.Flags().GetFloat64("required-threshold")
+		checkArg(err)
+
+		outputDest, err := cmd.Flags().GetString("output")
+		checkArg(err)
+
+		collection, err := store.Collection()
+		if err != nil {
+			log.Fatalf("Couldn't parse Albatross store to collection: %s", err)
+		}
+
+		schema := entries.InferSchema(collection.List().Sort(entries.SortPath), entries.SchemaOptions{
+			EnumThreshold:     enumThreshold,
+			RequiredThreshold: requiredThreshold,
+		})
+
+		out, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			fmt.Println("Error marshalling schema:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if outputDest == "" {
+			fmt.Println(string(out))
+			return
+		}
+
+		err = ioutil.WriteFile(outputDest, out, 0644)
+		if err != nil {
+			fmt.Println("Couldn't write to output destination:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(SchemaCmd)
+	SchemaCmd.AddCommand(SchemaGenerateCmd)
+
+	SchemaGenerateCmd.Flags().Int("enum-threshold", entries.DefaultSchemaOptions.EnumThreshold, "maximum number of distinct values for a key to be considered an enum, 0 disables")
+	SchemaGenerateCmd.Flags().Float64("required-threshold", entries.DefaultSchemaOptions.RequiredThreshold, "fraction of entries a key must be present in to be required, 0 disables")
+	SchemaGenerateCmd.Flags().StringP("output", "o", "", "output location of the schema, by default it is printed to stdout")
+}
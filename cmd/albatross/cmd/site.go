@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/yuin/goldmark"
+)
+
+// maxDescriptionLength is the longest description given to an entry's page in its metadata, in characters.
+const maxDescriptionLength = 200
+
+// siteURLs builds the absolute URLs of the pages of an exported site, from the URL the site is published at given using
+// --base-url, like "https://notes.example.com/".
+type siteURLs struct {
+	base *url.URL
+}
+
+// newSiteURLs parses the base URL of a site. It must be an absolute http or https URL.
+func newSiteURLs(base string) (*siteURLs, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %w", base, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q, expected one like https://notes.example.com", base)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/"
+	u.RawQuery, u.Fragment = "", ""
+
+	return &siteURLs{base: u}, nil
+}
+
+// page returns the absolute URL of the page at the path given, relative to the root of the site, like
+// "food/pizza.html".
+func (s *siteURLs) page(rel string) string {
+	ref := &url.URL{Path: strings.TrimPrefix(rel, "/")}
+	return s.base.ResolveReference(ref).String()
+}
+
+// pageMetaHTML returns the tags to put in the <head> of an entry's page so that it's shared and indexed properly: a
+// canonical link and Open Graph metadata, using the absolute URL of the page.
+func pageMetaHTML(entry *entries.Entry, pageURL string) string {
+	var meta strings.Builder
+
+	fmt.Fprintf(&meta, "<link rel=\"canonical\" href=\"%s\">\n", html.EscapeString(pageURL))
+	fmt.Fprintf(&meta, "<meta property=\"og:type\" content=\"article\">\n")
+	fmt.Fprintf(&meta, "<meta property=\"og:title\" content=\"%s\">\n", html.EscapeString(entry.Title))
+	fmt.Fprintf(&meta, "<meta property=\"og:url\" content=\"%s\">\n", html.EscapeString(pageURL))
+
+	if description := entryDescription(entry); description != "" {
+		fmt.Fprintf(&meta, "<meta name=\"description\" content=\"%s\">\n", html.EscapeString(description))
+		fmt.Fprintf(&meta, "<meta property=\"og:description\" content=\"%s\">\n", html.EscapeString(description))
+	}
+
+	if !entry.Date.IsZero() {
+		fmt.Fprintf(&meta, "<meta property=\"article:published_time\" content=\"%s\">\n", entry.Date.Format("2006-01-02T15:04:05Z07:00"))
+	}
+
+	for _, tag := range entry.Tags {
+		fmt.Fprintf(&meta, "<meta property=\"article:tag\" content=\"%s\">\n", html.EscapeString(tag))
+	}
+
+	return meta.String()
+}
+
+// entryDescription returns a short description of the entry for its page's metadata, which is the start of its text
+// with the title left off if it's the first sentence.
+func entryDescription(entry *entries.Entry) string {
+	text := strings.Join(strings.Fields(entry.PlainText()), " ")
+	text = strings.TrimSpace(strings.TrimPrefix(text, entry.Title))
+
+	if utf8.RuneCountInString(text) <= maxDescriptionLength {
+		return text
+	}
+
+	runes := []rune(text)[:maxDescriptionLength]
+	if space := strings.LastIndex(string(runes), " "); space > 0 {
+		return string(runes)[:space] + "…"
+	}
+
+	return string(runes) + "…"
+}
+
+// entryHTML renders the entry as HTML, given its contents as returned by exportContents. Links to other entries in the
+// collection point to the URL returned by href, links to other stores point to the store's URL, see crossStoreURL, and
+// links to anything else are left as text. If the entry cites anything
+// in the bibliography, the references are added to the end.
+func entryHTML(md goldmark.Markdown, collection *entries.Collection, bib entries.Bibliography, entry *entries.Entry, contents string, href func(linked *entries.Entry) string) (string, error) {
+	expanded := *entry
+	expanded.Contents = contents
+
+	markdown, refs := renderCitations(&expanded, bib)
+
+	var buf bytes.Buffer
+
+	err := md.Convert([]byte(markdown), &buf)
+	if err != nil {
+		return "", fmt.Errorf("couldn't convert entry %s to HTML: %w", entry.Path, err)
+	}
+
+	body := buf.String()
+	replaced := map[string]bool{}
+
+	for _, link := range entry.OutboundLinks {
+		var target string
+
+		if link.Store != "" {
+			url, ok := crossStoreURL(link)
+			if !ok {
+				continue
+			}
+
+			target = url
+		} else {
+			linked := collection.ResolveLink(link)
+			if linked == nil {
+				continue
+			}
+
+			target = href(linked)
+		}
+
+		// Every occurrence of the text is replaced at once, so the same link appearing twice is only replaced once.
+		text := entry.Contents[link.Loc[0]:link.Loc[1]]
+		if replaced[text] {
+			continue
+		}
+
+		replaced[text] = true
+		body = strings.ReplaceAll(body, text, "<a href=\""+html.EscapeString(target)+"\">"+text+"</a>")
+	}
+
+	if len(refs) != 0 {
+		body += referencesHTML(refs)
+	}
+
+	return body, nil
+}
+
+// sitemapURL is a page listed in a sitemap.
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// sitemapDate formats a time for the lastmod of a page in a sitemap, or returns "" for the zero time so that it's left
+// out.
+func sitemapDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format("2006-01-02")
+}
+
+// sitemapXML returns a sitemap.xml file listing the pages given, in the order given.
+func sitemapXML(pages []sitemapURL) ([]byte, error) {
+	sitemap := struct {
+		XMLName xml.Name     `xml:"urlset"`
+		XMLNS   string       `xml:"xmlns,attr"`
+		URLs    []sitemapURL `xml:"url"`
+	}{
+		XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs:  pages,
+	}
+
+	var out bytes.Buffer
+	out.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&out)
+	enc.Indent("", "  ")
+
+	err := enc.Encode(sitemap)
+	if err != nil {
+		return nil, err
+	}
+
+	out.WriteString("\n")
+
+	return out.Bytes(), nil
+}
+
+// robotsTxt returns a robots.txt file which allows every page to be crawled, pointing to the sitemap if the site has a
+// base URL. Unlisted pages aren't disallowed, since crawlers have to fetch them to see that they shouldn't be indexed.
+func robotsTxt(urls *siteURLs) []byte {
+	robots := "User-agent: *\nAllow: /\n"
+
+	if urls != nil {
+		robots += "\nSitemap: " + urls.page("sitemap.xml") + "\n"
+	}
+
+	return []byte(robots)
+}
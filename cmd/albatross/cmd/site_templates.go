@@ -0,0 +1,358 @@
+package cmd
+
+import "html/template"
+
+// siteLayout is the layout shared by every page of a site made by 'export html', which the other templates fill in by
+// defining "content".
+const siteLayout = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if .Title}}{{.Title}} - {{end}}{{.SiteTitle}}</title>
+<link rel="stylesheet" href="{{.Root}}style.css">
+{{.Meta}}</head>
+<body>
+<header>
+<nav>
+<a class="site-title" href="{{.Root}}index.html">{{.SiteTitle}}</a>
+<a href="{{.Root}}timeline.html">Timeline</a>
+<a href="{{.Root}}tags/index.html">Tags</a>
+<a href="{{.Root}}graph.html">Graph</a>
+</nav>
+</header>
+<main>
+{{template "content" .}}
+</main>
+</body>
+</html>
+`
+
+// siteEntryTemplate is the page for a single entry.
+const siteEntryTemplate = `{{define "content"}}<article>
+<h1>{{.Title}}</h1>
+<p class="meta"><time datetime="{{.Date.Format "2006-01-02T15:04:05Z07:00"}}">{{.Date.Format "Monday 2 January 2006, 15:04"}}</time>
+{{- range .Tags}} {{if .Href}}<a class="tag" href="{{.Href}}">{{.Text}}</a>{{else}}<span class="tag">{{.Text}}</span>{{end}}{{end}}</p>
+{{.Body}}
+</article>
+{{if .Backlinks}}<aside>
+<h2>Links to this entry</h2>
+<ul>
+{{range .Backlinks}}<li><a href="{{.Href}}">{{.Text}}</a></li>
+{{end}}</ul>
+</aside>
+{{end}}{{if .GraphHref}}<p class="graph-link"><a href="{{.GraphHref}}">Show in graph</a></p>
+{{end}}{{end}}`
+
+// siteListTemplate is a page listing entries, used for the index and the page for each tag.
+const siteListTemplate = `{{define "content"}}<h1>{{if .Title}}{{.Title}}{{else}}{{.SiteTitle}}{{end}}</h1>
+<ul class="entries">
+{{range .Links}}<li><a href="{{.Href}}">{{.Text}}</a> <time>{{.Date.Format "2006-01-02"}}</time></li>
+{{end}}</ul>
+{{end}}`
+
+// siteTimelineTemplate is the page listing every entry by date, newest first, under a heading for each month.
+const siteTimelineTemplate = `{{define "content"}}<h1>Timeline</h1>
+{{range .Groups}}<h2>{{.Heading}}</h2>
+<ul class="entries">
+{{range .Links}}<li><time>{{.Date.Format "Mon 2"}}</time> <a href="{{.Href}}">{{.Text}}</a></li>
+{{end}}</ul>
+{{end}}{{end}}`
+
+// siteTagsTemplate is the page listing every tag.
+const siteTagsTemplate = `{{define "content"}}<h1>Tags</h1>
+<ul class="tags">
+{{range .Links}}<li><a class="tag" href="{{.Href}}">{{.Text}}</a> ({{.Count}})</li>
+{{end}}</ul>
+{{end}}`
+
+// siteGraphTemplate is the page showing the links between entries as a graph, drawn by graph.js from the JSON in the
+// page. The same JSON is written to graph.json.
+const siteGraphTemplate = `{{define "content"}}<h1>Graph</h1>
+<p class="meta" id="graph-focus" hidden>Showing the entries linked to and from <a id="graph-focus-entry"></a>. <a href="#">Show every entry</a></p>
+<svg id="graph" role="img" aria-label="Graph of the links between entries"></svg>
+<p class="meta">Drag to move around, scroll to zoom and click an entry to open it.</p>
+<script type="application/json" id="graph-data">{{.Graph}}</script>
+<script src="{{.Root}}graph.js"></script>
+{{end}}`
+
+// siteGraphScript is the graph.js file for a site, which lays out the graph on the graph page using a simple
+// force-directed layout, so that nothing needs to be loaded from elsewhere. If the page's URL has an entry's path after
+// the "#", only that entry and the entries it links to or from are shown.
+const siteGraphScript = `(function () {
+	"use strict";
+
+	var svgNS = "http://www.w3.org/2000/svg";
+	var data = JSON.parse(document.getElementById("graph-data").textContent);
+	var svg = document.getElementById("graph");
+
+	var view = { x: 0, y: 0, scale: 1 };
+	var viewport = document.createElementNS(svgNS, "g");
+	svg.appendChild(viewport);
+
+	function create(name, attrs, parent) {
+		var el = document.createElementNS(svgNS, name);
+		for (var key in attrs) {
+			el.setAttribute(key, attrs[key]);
+		}
+		parent.appendChild(el);
+		return el;
+	}
+
+	var byPath = {};
+	var nodes = data.nodes.map(function (node) {
+		var n = { node: node, x: 0, y: 0, vx: 0, vy: 0, neighbours: {} };
+		byPath[node.path] = n;
+		return n;
+	});
+
+	var edges = data.edges.filter(function (edge) {
+		return byPath[edge.from] && byPath[edge.to];
+	}).map(function (edge) {
+		var from = byPath[edge.from], to = byPath[edge.to];
+		from.neighbours[edge.to] = true;
+		to.neighbours[edge.from] = true;
+		return { from: from, to: to, weight: edge.weight };
+	});
+
+	var shown = [], shownEdges = [], alpha = 0, frame = null;
+
+	// render draws the nodes being shown, which is every node or only the focused node and its neighbours.
+	function render() {
+		var focus = byPath[decodeURIComponent(location.hash.slice(1))];
+
+		var notice = document.getElementById("graph-focus");
+		notice.hidden = !focus;
+		if (focus) {
+			var link = document.getElementById("graph-focus-entry");
+			link.textContent = focus.node.title;
+			link.href = focus.node.href;
+		}
+
+		shown = nodes.filter(function (n) {
+			return !focus || n === focus || focus.neighbours[n.node.path];
+		});
+
+		shownEdges = edges.filter(function (e) {
+			return shown.indexOf(e.from) !== -1 && shown.indexOf(e.to) !== -1;
+		});
+
+		while (viewport.firstChild) {
+			viewport.removeChild(viewport.firstChild);
+		}
+
+		// Nodes start on a spiral so that they spread out evenly.
+		shown.forEach(function (n, i) {
+			var angle = i * 2.4, radius = 12 * Math.sqrt(i + 1);
+			n.x = n === focus ? 0 : Math.cos(angle) * radius;
+			n.y = n === focus ? 0 : Math.sin(angle) * radius;
+			n.vx = n.vy = 0;
+		});
+
+		shownEdges.forEach(function (e) {
+			e.el = create("line", { "class": "graph-edge", "stroke-width": Math.min(1 + Math.log(e.weight), 4) }, viewport);
+		});
+
+		shown.forEach(function (n) {
+			var degree = n.node.inbound + n.node.outbound;
+
+			n.el = create("a", { "class": "graph-node", href: n.node.href }, viewport);
+			n.circle = create("circle", { r: 4 + Math.sqrt(degree) * 2, fill: n.node.colour || "#1a5fb4" }, n.el);
+			create("title", {}, n.circle).textContent = n.node.title + " (" + n.node.path + ")";
+			create("text", { dy: -8 - Math.sqrt(degree) * 2 }, n.el).textContent = n.node.title;
+
+			n.el.addEventListener("mouseenter", function () { highlight(n); });
+			n.el.addEventListener("mouseleave", function () { highlight(null); });
+		});
+
+		view = { x: 0, y: 0, scale: 1 };
+		alpha = 1;
+		if (!frame) {
+			frame = requestAnimationFrame(tick);
+		}
+	}
+
+	// highlight fades everything which isn't the node given or linked to it.
+	function highlight(node) {
+		shown.forEach(function (n) {
+			var faded = node && n !== node && !node.neighbours[n.node.path];
+			n.el.setAttribute("class", faded ? "graph-node faded" : "graph-node");
+		});
+
+		shownEdges.forEach(function (e) {
+			var faded = node && e.from !== node && e.to !== node;
+			e.el.setAttribute("class", faded ? "graph-edge faded" : "graph-edge");
+		});
+	}
+
+	// tick moves the nodes a step further towards their layout, where linked nodes are pulled together, every node pushes
+	// the others away and everything is pulled gently towards the middle.
+	function tick() {
+		for (var i = 0; i < shown.length; i++) {
+			for (var j = i + 1; j < shown.length; j++) {
+				var a = shown[i], b = shown[j];
+				var dx = b.x - a.x, dy = b.y - a.y;
+				var distance2 = Math.max(dx * dx + dy * dy, 1);
+				var force = 400 / distance2 * alpha;
+				a.vx -= dx * force; a.vy -= dy * force;
+				b.vx += dx * force; b.vy += dy * force;
+			}
+		}
+
+		shownEdges.forEach(function (e) {
+			var dx = e.to.x - e.from.x, dy = e.to.y - e.from.y;
+			var distance = Math.sqrt(dx * dx + dy * dy) || 1;
+			var force = (distance - 60) / distance * 0.05 * alpha;
+			e.from.vx += dx * force; e.from.vy += dy * force;
+			e.to.vx -= dx * force; e.to.vy -= dy * force;
+		});
+
+		shown.forEach(function (n) {
+			n.vx = (n.vx - n.x * 0.01 * alpha) * 0.6;
+			n.vy = (n.vy - n.y * 0.01 * alpha) * 0.6;
+			n.x += n.vx;
+			n.y += n.vy;
+		});
+
+		draw();
+
+		alpha *= 0.98;
+		frame = alpha > 0.01 ? requestAnimationFrame(tick) : null;
+	}
+
+	function draw() {
+		var width = svg.clientWidth, height = svg.clientHeight;
+		viewport.setAttribute("transform", "translate(" + (width / 2 + view.x) + "," + (height / 2 + view.y) + ") scale(" + view.scale + ")");
+
+		shownEdges.forEach(function (e) {
+			e.el.setAttribute("x1", e.from.x);
+			e.el.setAttribute("y1", e.from.y);
+			e.el.setAttribute("x2", e.to.x);
+			e.el.setAttribute("y2", e.to.y);
+		});
+
+		shown.forEach(function (n) {
+			n.el.setAttribute("transform", "translate(" + n.x + "," + n.y + ")");
+		});
+	}
+
+	var dragging = null;
+
+	svg.addEventListener("mousedown", function (event) {
+		dragging = { x: event.clientX - view.x, y: event.clientY - view.y };
+	});
+
+	window.addEventListener("mousemove", function (event) {
+		if (dragging) {
+			view.x = event.clientX - dragging.x;
+			view.y = event.clientY - dragging.y;
+			draw();
+		}
+	});
+
+	window.addEventListener("mouseup", function () {
+		dragging = null;
+	});
+
+	svg.addEventListener("wheel", function (event) {
+		event.preventDefault();
+		view.scale = Math.min(Math.max(view.scale * (event.deltaY < 0 ? 1.1 : 1 / 1.1), 0.1), 10);
+		draw();
+	});
+
+	window.addEventListener("hashchange", render);
+	render();
+})();
+`
+
+// siteStylesheet is the style.css file for a site.
+const siteStylesheet = `body {
+	font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif;
+	line-height: 1.6;
+	color: #222;
+	max-width: 46em;
+	margin: 0 auto;
+	padding: 0 1em 3em;
+}
+
+header nav {
+	display: flex;
+	gap: 1em;
+	padding: 1em 0;
+	border-bottom: 1px solid #ddd;
+}
+
+header .site-title {
+	font-weight: bold;
+	margin-right: auto;
+}
+
+a {
+	color: #1a5fb4;
+}
+
+img {
+	max-width: 100%;
+}
+
+pre {
+	overflow-x: auto;
+	padding: 0.75em;
+	background: #f6f6f6;
+}
+
+.meta, time {
+	color: #666;
+}
+
+.tag {
+	font-size: 0.9em;
+	margin-left: 0.5em;
+}
+
+ul.entries, ul.tags {
+	list-style: none;
+	padding: 0;
+}
+
+aside {
+	margin-top: 2em;
+	border-top: 1px solid #ddd;
+}
+
+#graph {
+	width: 100%;
+	height: 70vh;
+	border: 1px solid #ddd;
+	cursor: move;
+}
+
+.graph-edge {
+	stroke: #bbb;
+}
+
+.graph-node text {
+	font-size: 11px;
+	text-anchor: middle;
+	fill: #222;
+	pointer-events: none;
+}
+
+.graph-node.faded, .graph-edge.faded {
+	opacity: 0.15;
+}
+`
+
+// siteTemplates are the templates for each kind of page, each combined with the layout.
+var siteTemplates = map[string]*template.Template{
+	"entry":    parseSiteTemplate(siteEntryTemplate),
+	"list":     parseSiteTemplate(siteListTemplate),
+	"timeline": parseSiteTemplate(siteTimelineTemplate),
+	"tags":     parseSiteTemplate(siteTagsTemplate),
+	"graph":    parseSiteTemplate(siteGraphTemplate),
+}
+
+// parseSiteTemplate parses a page template along with the layout.
+func parseSiteTemplate(content string) *template.Template {
+	return template.Must(template.Must(template.New("layout").Parse(siteLayout)).Parse(content))
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestSiteURLs(t *testing.T) {
+	site, err := newSiteURLs("https://notes.example.com")
+	if err != nil {
+		t.Fatalf("not expecting error parsing base URL: %s", err)
+	}
+
+	Equal(t, "https://notes.example.com/food/pizza.html", site.page("food/pizza.html"))
+	Equal(t, "https://notes.example.com/sitemap.xml", site.page("/sitemap.xml"))
+
+	site, err = newSiteURLs("https://example.com/notes")
+	if err != nil {
+		t.Fatalf("not expecting error parsing base URL: %s", err)
+	}
+
+	Equal(t, "https://example.com/notes/food/pizza.html", site.page("food/pizza.html"), "expecting pages under the base path")
+	Equal(t, "https://example.com/notes/food/hot%20dog.html", site.page("food/hot dog.html"), "expecting paths to be escaped")
+
+	for _, invalid := range []string{"notes.example.com", "ftp://example.com", "https://", "/notes"} {
+		_, err = newSiteURLs(invalid)
+		Error(t, err, "expecting error for base URL %q", invalid)
+	}
+}
+
+func TestPageMetaHTML(t *testing.T) {
+	entry := &entries.Entry{
+		Path:     "food/pizza",
+		Title:    "Pizza & Chips",
+		Contents: "Pizza is great.",
+		Date:     time.Date(2020, 8, 6, 18, 24, 0, 0, time.UTC),
+		Tags:     []string{"@?food"},
+	}
+
+	meta := pageMetaHTML(entry, "https://notes.example.com/food/pizza.html")
+
+	Contains(t, meta, `<link rel="canonical" href="https://notes.example.com/food/pizza.html">`)
+	Contains(t, meta, `<meta property="og:title" content="Pizza &amp; Chips">`)
+	Contains(t, meta, `<meta property="og:description" content="Pizza is great.">`)
+	Contains(t, meta, `<meta property="article:published_time" content="2020-08-06T18:24:00Z">`)
+	Contains(t, meta, `<meta property="article:tag" content="@?food">`)
+}
+
+func TestEntryDescription(t *testing.T) {
+	entry := &entries.Entry{Title: "Long", Contents: strings.Repeat("word ", 100)}
+
+	description := entryDescription(entry)
+	True(t, strings.HasSuffix(description, "word…"), "expecting long descriptions to be cut at a word, got %q", description)
+	LessOrEqual(t, len([]rune(description)), maxDescriptionLength+1)
+}
+
+func TestSitemapXML(t *testing.T) {
+	sitemap, err := sitemapXML([]sitemapURL{
+		{Loc: "https://notes.example.com/food/pizza.html", LastMod: "2020-08-06"},
+		{Loc: "https://notes.example.com/moods/hunger.html"},
+	})
+	NoError(t, err)
+
+	Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://notes.example.com/food/pizza.html</loc>
+    <lastmod>2020-08-06</lastmod>
+  </url>
+  <url>
+    <loc>https://notes.example.com/moods/hunger.html</loc>
+  </url>
+</urlset>
+`, string(sitemap))
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// StatsCmd represents the stats command.
+var StatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "display statistics",
+	Long: `stats displays statistics about a store.
+
+See the available subcommands for more information.`,
+}
+
+// StatsStoreCmd represents the 'stats store' command.
+var StatsStoreCmd = &cobra.Command{
+	Use:   "store",
+	Short: "display the size and growth of the store",
+	Long: `store displays the number and size of entries and attachments in the store, as well as how the store has
+grown over time.
+
+	$ albatross stats store
+	Entries:      1204 (3.1 MiB)
+	Attachments:  87 (40.2 MiB)
+	Commits:      1533
+	Undated:      3
+
+	Month    Entries  Total  Commits
+	2020-07       12     12       30
+	2020-08      150    162      201
+	...
+
+Growth is calculated using the dates of entries, so entries dated in the past will count towards earlier months.
+Entries without a date aren't counted in any month, and the number of them is shown separately if there are any.
+The number of commits per month is taken from git, if the store is using it.
+
+To get the statistics as JSON, use the --json flag. The same information is available from the server at /stats.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		stats, err := store.Stats()
+		if err != nil {
+			log.Fatalf("Couldn't compute stats for store: %s", err)
+		}
+
+		if outputJSON {
+			out, err := json.Marshal(stats)
+			if err != nil {
+				fmt.Println("Error marshalling stats:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		out := newOutput(os.Stdout)
+
+		summary := newTable(out)
+		summary.Row(out.style(styleBold, "Entries:"), fmt.Sprintf("%d (%s)", stats.Entries, formatBytes(stats.EntriesSize)))
+		summary.Row(out.style(styleBold, "Attachments:"), fmt.Sprintf("%d (%s)", stats.Attachments, formatBytes(stats.AttachmentsSize)))
+		summary.Row(out.style(styleBold, "Commits:"), strconv.Itoa(stats.Commits))
+		if stats.Undated > 0 {
+			summary.Row(out.style(styleBold, "Undated:"), strconv.Itoa(stats.Undated))
+		}
+		summary.Flush()
+
+		fmt.Println("")
+
+		months := newTable(out, 1, 2, 3)
+		months.Row(out.style(styleBold, "Month"), out.style(styleBold, "Entries"), out.style(styleBold, "Total"), out.style(styleBold, "Commits"))
+
+		for _, month := range stats.Months {
+			months.Row(month.Month, strconv.Itoa(month.Entries), strconv.Itoa(month.Cumulative), strconv.Itoa(month.Commits))
+		}
+
+		months.Flush()
+	},
+}
+
+// formatBytes formats a number of bytes in a human readable way, such as "3.1 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %siB", float64(n)/float64(div), strings.Split("KMGTPE", "")[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(StatsCmd)
+	StatsCmd.AddCommand(StatsStoreCmd)
+
+	StatsStoreCmd.Flags().Bool("json", false, "output stats as JSON")
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// StatusCmd represents the status command.
+var StatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show the state of the store",
+	Long: `status displays the current state of a store, without decrypting it.
+
+	$ albatross status
+	Path:            /home/user/.local/share/albatross/default
+	Encrypted:       no
+	Last encrypted:  2020-11-01 14:02
+	Last decrypted:  2020-11-02 09:15
+	Using git:       yes
+	Last commit:     3f2a1c9 (go-albatross) Add food/pizza (2020-11-02 09:20)
+	Locked:          no
+	Uncommitted:     1
+	    food/pizza/entry.md
+
+	Warning: the store has changes which haven't been committed. Use 'albatross commit' to commit them.
+
+The times the store was last encrypted and decrypted come from the audit log (see 'albatross audit --help'). The store
+is locked if git's index is locked, which happens while another process is committing or if one was interrupted. If
+no other albatross or git process is running, you may need to remove '.git/index.lock' in the entries folder by hand.
+
+Scripts and editor plugins should use --porcelain, which gives a stable output of one tab-separated key and value per
+line. Times are in RFC 3339 format and are empty if unknown, and there is one 'uncommitted' line per file:
+
+	$ albatross status --porcelain
+	path	/home/user/.local/share/albatross/default
+	encrypted	false
+	last-encrypted	2020-11-01T14:02:10Z
+	last-decrypted	2020-11-02T09:15:43Z
+	using-git	true
+	last-commit	3f2a1c9d7e...
+	locked	false
+	uncommitted	food/pizza/entry.md
+
+To get the state as JSON, use the --json flag.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		porcelain, err := cmd.Flags().GetBool("porcelain")
+		checkArg(err)
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		state, err := store.State()
+		if err != nil {
+			log.Fatalf("Couldn't get state of store: %s", err)
+		}
+
+		if outputJSON {
+			out, err := json.Marshal(state)
+			if err != nil {
+				fmt.Println("Error marshalling state:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		if porcelain {
+			porcelainTime := func(t time.Time) string {
+				if t.IsZero() {
+					return ""
+				}
+
+				return t.Format(time.RFC3339)
+			}
+
+			lastCommit := ""
+			if state.LastCommit != nil {
+				lastCommit = state.LastCommit.Hash
+			}
+
+			fmt.Printf("path\t%s\n", state.Path)
+			fmt.Printf("encrypted\t%t\n", state.Encrypted)
+			fmt.Printf("last-encrypted\t%s\n", porcelainTime(state.LastEncrypted))
+			fmt.Printf("last-decrypted\t%s\n", porcelainTime(state.LastDecrypted))
+			fmt.Printf("using-git\t%t\n", state.UsingGit)
+			fmt.Printf("last-commit\t%s\n", lastCommit)
+			fmt.Printf("locked\t%t\n", state.Locked)
+
+			for _, path := range state.Uncommitted {
+				fmt.Printf("uncommitted\t%s\n", path)
+			}
+
+			return
+		}
+
+		yesNo := func(b bool) string {
+			if b {
+				return "yes"
+			}
+
+			return "no"
+		}
+
+		humanTime := func(t time.Time) string {
+			if t.IsZero() {
+				return "unknown"
+			}
+
+			return t.Local().Format("2006-01-02 15:04")
+		}
+
+		fmt.Printf("Path:            %s\n", state.Path)
+		fmt.Printf("Encrypted:       %s\n", yesNo(state.Encrypted))
+		fmt.Printf("Last encrypted:  %s\n", humanTime(state.LastEncrypted))
+		fmt.Printf("Last decrypted:  %s\n", humanTime(state.LastDecrypted))
+
+		if state.Encrypted {
+			return
+		}
+
+		fmt.Printf("Using git:       %s\n", yesNo(state.UsingGit))
+
+		if !state.UsingGit {
+			return
+		}
+
+		if state.LastCommit != nil {
+			fmt.Printf(
+				"Last commit:     %s %s (%s)\n",
+				state.LastCommit.Hash[:7],
+				strings.SplitN(strings.TrimSpace(state.LastCommit.Message), "\n", 2)[0],
+				humanTime(state.LastCommit.Time),
+			)
+		} else {
+			fmt.Printf("Last commit:     none\n")
+		}
+
+		fmt.Printf("Locked:          %s\n", yesNo(state.Locked))
+		fmt.Printf("Uncommitted:     %d\n", len(state.Uncommitted))
+
+		for _, path := range state.Uncommitted {
+			fmt.Printf("    %s\n", path)
+		}
+
+		if len(state.Uncommitted) > 0 {
+			fmt.Println("")
+			fmt.Println("Warning: the store has changes which haven't been committed. Use 'albatross commit' to commit them.")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(StatusCmd)
+
+	StatusCmd.Flags().Bool("porcelain", false, "give output in a stable, easy to parse format for scripts")
+	StatusCmd.Flags().Bool("json", false, "output state as JSON")
+}
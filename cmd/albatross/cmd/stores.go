@@ -0,0 +1,410 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+)
+
+// storeMarkerFile is the name of a file which, when put in a folder, makes commands run in it or any folder inside it use
+// the store it names. It's different from the '.albatross' folder stores keep their state in.
+const storeMarkerFile = ".albatross-store"
+
+// StoresCmd represents the stores command.
+var StoresCmd = &cobra.Command{
+	Use:   "stores",
+	Short: "list the stores in the config and switch between them",
+	Long: `stores lists the stores defined in the config and lets you choose which one is used by default.
+
+	$ albatross stores
+	* default   /home/me/notes
+	  thesis    /home/me/thesis
+
+The store used is chosen in this order:
+
+	1. The store given by --store.
+	2. The store the current folder is in, or the store named by a '.albatross-store' file in the current folder or any
+	   folder above it.
+	3. The store chosen using 'albatross stores switch', saved as 'current-store' in the config.
+	4. The store called 'default'.
+
+A folder is recognised as a store if it contains a 'config.yaml' file and an 'entries' folder or an 'entries.gpg' file,
+so running albatross anywhere inside a store uses it without having to give --store, even if the store isn't in the
+config. A '.albatross-store' file contains the name of a store from the config or a path to a store, which is useful for
+project folders which are kept separately from the notes about them:
+
+	$ echo thesis > ~/code/thesis/.albatross-store
+	$ cd ~/code/thesis && albatross get -p ideas
+
+The '*' marks the store which would be used in the current folder.
+
+Links between stores
+--------------------
+
+Entries can link to entries in other stores in the config by starting a path link with the store's name:
+
+	See the plan in {{work:notes/project}(the project notes)}.
+
+Links to other stores are resolved when they're needed, so other stores are only loaded if they're linked to. Since
+another store's entries aren't part of this one, exports can't include them. Instead, give the store a 'url' where an
+exported copy of it is published, and links to it are rendered as links to that site:
+
+	work:
+	  path: /home/me/work-notes
+	  url: https://wiki.example.com/{path}.html
+
+'{path}' is replaced with the path of the entry linked to. If the URL doesn't contain it, the path is added to the end.
+Links to stores without a URL are left as text.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		StoresListCmd.Run(cmd, args)
+	},
+}
+
+// StoresListCmd represents the stores list command.
+var StoresListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the stores in the config",
+	Long: `list prints the names and paths of the stores defined in the config. The '*' marks the store which would be
+used in the current folder, see 'albatross stores --help'.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		stores := configuredStores()
+
+		name, path, _ := chooseStore(false)
+		if _, ok := stores[name]; !ok {
+			stores[name] = path
+		}
+
+		names := []string{}
+		width := 0
+
+		for name := range stores {
+			names = append(names, name)
+
+			if len(name) > width {
+				width = len(name)
+			}
+		}
+
+		sort.Strings(names)
+
+		for _, storeName := range names {
+			marker := " "
+			if storeName == name {
+				marker = "*"
+			}
+
+			fmt.Printf("%s %-*s  %s\n", marker, width, storeName, stores[storeName])
+		}
+	},
+}
+
+// StoresSwitchCmd represents the stores switch command.
+var StoresSwitchCmd = &cobra.Command{
+	Use:   "switch [name]",
+	Short: "choose the store used by default",
+	Long: `switch sets the store used when --store isn't given and the current folder isn't inside a store, by saving it as
+'current-store' in the config. If no name is given, the store can be chosen from a list.
+
+	$ albatross stores switch thesis
+	Switched to store 'thesis'.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		stores := configuredStores()
+		if len(stores) == 0 {
+			fmt.Println("There are no stores in the config, see 'albatross --help'.")
+			os.Exit(1)
+		}
+
+		var name string
+
+		if len(args) > 0 {
+			name = args[0]
+		} else {
+			names := []string{}
+			for name := range stores {
+				names = append(names, name)
+			}
+
+			sort.Strings(names)
+
+			prompt := promptui.Select{
+				Label: "Select Store",
+				Items: names,
+			}
+
+			_, result, err := prompt.Run()
+			if err != nil {
+				log.Fatalf("Couldn't choose store: %s", err)
+			}
+
+			name = result
+		}
+
+		if _, ok := stores[name]; !ok {
+			fmt.Printf("No store named '%s' in the config, see 'albatross stores list'.\n", name)
+			os.Exit(1)
+		}
+
+		err := setCurrentStore(viper.ConfigFileUsed(), name)
+		if err != nil {
+			log.Fatalf("Couldn't save current store: %s", err)
+		}
+
+		fmt.Printf("Switched to store '%s'.\n", name)
+	},
+}
+
+// configuredStores returns the paths of the stores defined in the config, by name.
+func configuredStores() map[string]string {
+	stores := map[string]string{}
+
+	for key, value := range viper.AllSettings() {
+		settings, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if path, ok := settings["path"].(string); ok && path != "" {
+			stores[key] = path
+		}
+	}
+
+	return stores
+}
+
+// storeRegistry returns a registry of the stores in the config, with the store being used already loaded under its name.
+func storeRegistry(name string, current *albatross.Store) *albatross.Registry {
+	registry := albatross.NewRegistry()
+
+	for other, path := range configuredStores() {
+		registry.Register(other, path, viper.GetString(other+".url"))
+	}
+
+	registry.Add(name, current, viper.GetString(name+".url"))
+
+	return registry
+}
+
+// crossStoreURL returns the address of the entry a link to another store points to, if the store has a 'url' in the
+// config. Exports use it for links to other stores, since their entries can't be included.
+func crossStoreURL(link entries.Link) (string, bool) {
+	if registry == nil {
+		return "", false
+	}
+
+	return registry.URL(link)
+}
+
+// chooseStore returns the name and path of the store to use, and how it was chosen, in the order described by 'albatross
+// stores --help'. If flagGiven is true, the store named by --store is used. The path is blank if the store isn't in the
+// config.
+func chooseStore(flagGiven bool) (name, path, reason string) {
+	if flagGiven {
+		return storeName, viper.GetString(storeName + ".path"), "--store"
+	}
+
+	cwd, err := os.Getwd()
+	if err == nil {
+		name, path, err := detectStore(cwd, configuredStores())
+		if err != nil {
+			log.Warnf("Couldn't detect store from the current folder: %s", err)
+		} else if path != "" {
+			return name, path, "current folder"
+		}
+	}
+
+	if current := viper.GetString("current-store"); current != "" {
+		return current, viper.GetString(current + ".path"), "current-store in config"
+	}
+
+	return "default", viper.GetString("default.path"), "default"
+}
+
+// detectStore looks for the store the folder given is in, going up through the folders above it until it finds either a
+// store or a '.albatross-store' file naming one. It returns the name of the store from the config, or the name of its folder if
+// it isn't in the config. If no store is found, the path returned is blank.
+func detectStore(dir string, stores map[string]string) (name, path string, err error) {
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	for {
+		marker := filepath.Join(dir, storeMarkerFile)
+
+		info, err := os.Stat(marker)
+		if err != nil && !os.IsNotExist(err) {
+			return "", "", err
+		} else if err == nil && info.IsDir() {
+			return "", "", fmt.Errorf("%s is a folder, but should be a file naming a store", marker)
+		} else if err == nil {
+			target, err := readStoreMarker(marker)
+			if err != nil {
+				return "", "", err
+			}
+
+			if path, ok := stores[target]; ok {
+				return target, path, nil
+			}
+
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(dir, target)
+			}
+
+			if !isStore(target) {
+				return "", "", fmt.Errorf("%s names '%s', which isn't a store in the config or the path to a store", marker, target)
+			}
+
+			return storeNameForPath(target, stores), target, nil
+		}
+
+		if isStore(dir) {
+			return storeNameForPath(dir, stores), dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", nil
+		}
+
+		dir = parent
+	}
+}
+
+// readStoreMarker returns the first line of a '.albatross-store' file which isn't blank or a comment.
+func readStoreMarker(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return line, nil
+		}
+	}
+
+	if scanner.Err() != nil {
+		return "", scanner.Err()
+	}
+
+	return "", fmt.Errorf("%s is empty, expected the name of a store or a path to one", path)
+}
+
+// isStore returns true if the folder looks like an Albatross store, containing a config.yaml file and either an entries
+// folder or an encrypted entries.gpg file.
+func isStore(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, "config.yaml")); err != nil {
+		return false
+	}
+
+	if info, err := os.Stat(filepath.Join(dir, "entries")); err == nil && info.IsDir() {
+		return true
+	}
+
+	_, err := os.Stat(filepath.Join(dir, "entries.gpg"))
+	return err == nil
+}
+
+// storeNameForPath returns the name of the store in the config with the path given, or the name of the folder if there
+// isn't one.
+func storeNameForPath(path string, stores map[string]string) string {
+	names := []string{}
+	for name := range stores {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		configured, err := filepath.Abs(stores[name])
+		if err == nil && configured == filepath.Clean(path) {
+			return name
+		}
+	}
+
+	return filepath.Base(path)
+}
+
+// setCurrentStore saves the name of the current store as 'current-store' in the YAML config file given, replacing the
+// existing value if there is one. The rest of the file is left as it is, so comments aren't lost.
+func setCurrentStore(configFile, name string) error {
+	if configFile == "" {
+		configFile = filepath.Join(getConfigDirectory(), "config.yaml")
+	}
+
+	if ext := filepath.Ext(configFile); ext != ".yaml" && ext != ".yml" {
+		return fmt.Errorf("can only save the current store to a YAML config, not %s", configFile)
+	}
+
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	line := fmt.Sprintf("current-store: %q", name)
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	replaced := false
+
+	for i, existing := range lines {
+		if strings.HasPrefix(existing, "current-store:") {
+			lines[i] = line
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		if len(lines) == 1 && lines[0] == "" {
+			lines = []string{line}
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	err = os.MkdirAll(filepath.Dir(configFile), 0755)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(configFile, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// storeNotNeeded returns true if the command being run doesn't use the store, so that it still works if the store can't
+// be loaded.
+func storeNotNeeded() bool {
+	cmd, _, err := rootCmd.Find(commandArgs)
+	if err != nil {
+		return false
+	}
+
+	for ; cmd != nil; cmd = cmd.Parent() {
+		if cmd == StoresCmd || cmd == AliasCmd {
+			return true
+		}
+	}
+
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(StoresCmd)
+
+	StoresCmd.AddCommand(StoresListCmd)
+	StoresCmd.AddCommand(StoresSwitchCmd)
+}
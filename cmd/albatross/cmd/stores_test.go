@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestDetectStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "albatross-stores-test")
+	if err != nil {
+		t.Fatalf("couldn't create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	mkdir := func(path string) {
+		err := os.MkdirAll(filepath.Join(dir, path), 0755)
+		if err != nil {
+			t.Fatalf("couldn't create folder: %s", err)
+		}
+	}
+
+	write := func(path, content string) {
+		err := ioutil.WriteFile(filepath.Join(dir, path), []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("couldn't write file: %s", err)
+		}
+	}
+
+	mkdir("notes/entries/food/pizza")
+	write("notes/config.yaml", "")
+	mkdir("encrypted")
+	write("encrypted/config.yaml", "")
+	write("encrypted/entries.gpg", "")
+	mkdir("projects/thesis/src")
+	write("projects/thesis/.albatross-store", "# The thesis notes.\nthesis\n")
+	mkdir("projects/other")
+	write("projects/other/.albatross-store", "../../notes")
+	mkdir("projects/broken")
+	write("projects/broken/.albatross-store", "missing")
+	mkdir("projects/folder/.albatross-store")
+	mkdir("elsewhere")
+
+	// A store's state folder shouldn't be mistaken for a marker, including next to one.
+	mkdir("notes/.albatross")
+	write("notes/.albatross/recent.json", "[]")
+	mkdir("projects/thesis/.albatross")
+	write("projects/thesis/.albatross/audit.log", "")
+
+	stores := map[string]string{
+		"default": filepath.Join(dir, "notes"),
+		"thesis":  "/path/to/thesis",
+	}
+
+	cases := []struct {
+		dir  string
+		name string
+		path string
+	}{
+		{"notes/entries/food/pizza", "default", filepath.Join(dir, "notes")},
+		{"notes", "default", filepath.Join(dir, "notes")},
+		{"encrypted", "encrypted", filepath.Join(dir, "encrypted")},
+		{"projects/thesis/src", "thesis", "/path/to/thesis"},
+		{"projects/other", "default", filepath.Join(dir, "notes")},
+		{"elsewhere", "", ""},
+	}
+
+	for _, tc := range cases {
+		name, path, err := detectStore(filepath.Join(dir, tc.dir), stores)
+		NoError(t, err, "detecting store from %s", tc.dir)
+		Equal(t, tc.name, name, "name of store detected from %s", tc.dir)
+		Equal(t, tc.path, path, "path of store detected from %s", tc.dir)
+	}
+
+	_, _, err = detectStore(filepath.Join(dir, "projects/broken"), stores)
+	Error(t, err, "expecting error when .albatross-store names something which isn't a store")
+
+	_, _, err = detectStore(filepath.Join(dir, "projects/folder"), stores)
+	Error(t, err, "expecting error when .albatross-store is a folder")
+}
+
+func TestSetCurrentStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "albatross-stores-test")
+	if err != nil {
+		t.Fatalf("couldn't create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := filepath.Join(dir, "config.yaml")
+
+	err = ioutil.WriteFile(config, []byte("# Stores\ndefault:\n  path: /notes\n"), 0644)
+	if err != nil {
+		t.Fatalf("couldn't write config: %s", err)
+	}
+
+	NoError(t, setCurrentStore(config, "thesis"))
+	NoError(t, setCurrentStore(config, "default"))
+
+	data, err := ioutil.ReadFile(config)
+	NoError(t, err)
+	Equal(t, "# Stores\ndefault:\n  path: /notes\ncurrent-store: \"default\"\n", string(data), "expecting value to be replaced and comments kept")
+
+	Error(t, setCurrentStore(filepath.Join(dir, "config.toml"), "default"), "expecting error for config which isn't YAML")
+}
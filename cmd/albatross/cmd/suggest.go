@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/spf13/cobra"
+)
+
+// SuggestCmd represents the suggest command.
+var SuggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "suggest improvements to entries",
+	Long: `suggest looks for ways entries could be improved, such as missing links.
+
+See the available subcommands for more information.`,
+}
+
+// SuggestLinksCmd represents the 'suggest links' command.
+var SuggestLinksCmd = &cobra.Command{
+	Use:   "links",
+	Short: "suggest links between entries",
+	Long: `links finds places where matched entries mention the title of another entry without linking to it, and offers
+to turn them into links.
+
+	$ albatross suggest links -p journal
+	journal/2020-08-06: ...so I had pizza and ice cream...
+	    pizza -> [[Pizza](pizza)]
+	Link? [y]es, [n]o, [q]uit: y
+
+Entries can be matched using the same filters as 'albatross get'; by default every entry is checked. Mentions are
+matched as whole words ignoring case, and text which is already a link or is code is skipped. Each entry is only
+offered one link to each other entry, for its first mention.
+
+If an entry has an 'aliases' list in its front matter, mentions of those are found too. Since aliases and titles
+shared by more than one entry don't identify a single entry, these are suggested as path links like
+'{{food/pizza}(pizza)}' rather than title links.
+
+To link every mention which matches a title exactly, including case, and where that title is unique, without asking,
+use --auto:
+
+	$ albatross suggest links --auto
+
+To only list the suggestions without changing anything, use --dry-run. To get them as JSON, use --json.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		encrypted, err := store.Encrypted()
+		if err != nil {
+			log.Fatal(err)
+		} else if encrypted {
+			decryptStore()
+
+			if !leaveDecrypted {
+				defer encryptStore()
+			}
+		}
+
+		auto, err := cmd.Flags().GetBool("auto")
+		checkArg(err)
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		checkArg(err)
+
+		outputJSON, err := cmd.Flags().GetBool("json")
+		checkArg(err)
+
+		collection, _, list := getFromCommand(cmd)
+		suggestions := collection.SuggestLinks(list)
+
+		if outputJSON {
+			out, err := json.Marshal(suggestions)
+			if err != nil {
+				fmt.Println("Error marshalling suggestions:")
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		if len(suggestions) == 0 {
+			fmt.Println("No links to suggest.")
+			return
+		}
+
+		accepted := map[*entries.Entry][]entries.LinkSuggestion{}
+		order := []*entries.Entry{}
+		reader := bufio.NewReader(os.Stdin)
+
+	suggestions:
+		for _, suggestion := range suggestions {
+			if auto && !(suggestion.Exact && suggestion.Unique) {
+				continue
+			}
+
+			fmt.Printf("%s: %s\n", suggestion.Path, mentionContext(suggestion))
+			fmt.Printf("    %s -> %s\n", suggestion.Text, suggestion.Link())
+
+			if !suggestion.Unique && !suggestion.Alias {
+				fmt.Printf("    (%q is the title of more than one entry)\n", suggestion.Name)
+			}
+
+			if dryRun {
+				continue
+			}
+
+			if !auto {
+				switch promptSuggestion(reader) {
+				case "n":
+					continue
+				case "q":
+					break suggestions
+				}
+			}
+
+			if accepted[suggestion.Entry] == nil {
+				order = append(order, suggestion.Entry)
+			}
+
+			accepted[suggestion.Entry] = append(accepted[suggestion.Entry], suggestion)
+		}
+
+		for _, entry := range order {
+			content, err := entries.ApplyLinkSuggestions(entry, accepted[entry])
+			if err != nil {
+				log.Fatalf("Couldn't add links to %s: %s", entry.Path, err)
+			}
+
+			err = store.Update(entry.Path, content)
+			if err != nil {
+				log.Fatalf("Couldn't update entry %s: %s", entry.Path, err)
+			}
+
+			fmt.Printf("Added %d link(s) to %s\n", len(accepted[entry]), entry.Path)
+		}
+	},
+}
+
+// mentionContext returns the text around a mention on the same line, so it can be shown when asking whether to link it.
+func mentionContext(suggestion entries.LinkSuggestion) string {
+	const width = 30
+
+	contents := suggestion.Entry.Contents
+	start, end := suggestion.Loc[0], suggestion.Loc[1]
+
+	lineStart := strings.LastIndex(contents[:start], "\n") + 1
+	lineEnd := strings.Index(contents[end:], "\n")
+	if lineEnd == -1 {
+		lineEnd = len(contents)
+	} else {
+		lineEnd += end
+	}
+
+	before, after := contents[lineStart:start], contents[end:lineEnd]
+	prefix, suffix := "", ""
+
+	if len(before) > width {
+		before = strings.ToValidUTF8(before[len(before)-width:], "")
+		prefix = "..."
+	}
+
+	if len(after) > width {
+		after = strings.ToValidUTF8(after[:width], "")
+		suffix = "..."
+	}
+
+	return prefix + before + suggestion.Text + after + suffix
+}
+
+// promptSuggestion asks whether a suggested link should be added, returning "y", "n" or "q".
+func promptSuggestion(reader *bufio.Reader) string {
+	for {
+		fmt.Print("Link? [y]es, [n]o, [q]uit: ")
+
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			return "q"
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return "y"
+		case "n", "no":
+			return "n"
+		case "q", "quit":
+			return "q"
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(SuggestCmd)
+	SuggestCmd.AddCommand(SuggestLinksCmd)
+
+	addFilterFlags(SuggestLinksCmd)
+
+	SuggestLinksCmd.Flags().Bool("auto", false, "add links for exact, unique matches without asking")
+	SuggestLinksCmd.Flags().Bool("dry-run", false, "only list suggestions, don't change any entries")
+	SuggestLinksCmd.Flags().Bool("json", false, "output suggestions as JSON")
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"crypto/sha1"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -68,10 +67,3 @@ func edit(editor string, content string) (string, error) {
 
 	return string(newContent), nil
 }
-
-// hashString is a shorthand for a doing the SHA-1 hash of a string.
-func hashString(path string) string {
-	h := sha1.New()
-	_, _ = h.Write([]byte(path))
-	return fmt.Sprintf("%x.xhtml", h.Sum(nil))
-}
@@ -0,0 +1,122 @@
+package daemon
+
+import (
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// dialTimeout is how long a client waits to connect to the daemon.
+const dialTimeout = 500 * time.Millisecond
+
+// Client talks to a running daemon.
+type Client struct {
+	http *http.Client
+}
+
+// Dial connects to the daemon for the store at storePath. If there's no daemon running, it returns ErrNotRunning.
+func Dial(storePath string) (*Client, error) {
+	socketPath := SocketPath(storePath)
+
+	if _, err := os.Stat(socketPath); err != nil {
+		return nil, ErrNotRunning{Store: storePath}
+	}
+
+	c := &Client{
+		http: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					dialer := net.Dialer{Timeout: dialTimeout}
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+
+	_, err := c.Status()
+	if err != nil {
+		return nil, ErrNotRunning{Store: storePath}
+	}
+
+	return c, nil
+}
+
+// Status returns the status of the daemon.
+func (c *Client) Status() (Status, error) {
+	var status Status
+
+	resp, err := c.http.Get("http://daemon/status")
+	if err != nil {
+		return status, err
+	}
+	defer resp.Body.Close()
+
+	if err = checkResponse(resp); err != nil {
+		return status, err
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	return status, err
+}
+
+// Collection returns the collection held by the daemon.
+func (c *Client) Collection() (*entries.Collection, error) {
+	resp, err := c.http.Get("http://daemon/collection")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err = checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var decoded encodedCollection
+
+	err = gob.NewDecoder(resp.Body).Decode(&decoded)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode entries from daemon: %w", err)
+	}
+
+	return decodeCollection(decoded)
+}
+
+// Reload asks the daemon to read the entries again, such as after they've been changed by hand.
+func (c *Client) Reload() error {
+	return c.post("http://daemon/reload")
+}
+
+// Stop asks the daemon to stop.
+func (c *Client) Stop() error {
+	return c.post("http://daemon/stop")
+}
+
+// post makes a POST request to the daemon.
+func (c *Client) post(url string) error {
+	resp, err := c.http.Post(url, "text/plain", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp)
+}
+
+// checkResponse returns an error if the daemon responded with one.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	return fmt.Errorf("daemon responded with %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,310 @@
+// Package daemon keeps a parsed Albatross store in memory so that separate invocations of the command line tool don't
+// each have to parse, and possibly decrypt, every entry. The daemon serves the collection over a unix socket which
+// clients connect to.
+package daemon
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+)
+
+func init() {
+	// Front matter is unmarshalled into these types, which gob needs to know about to send them as interface{} values.
+	gob.Register(map[interface{}]interface{}{})
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+	gob.Register(time.Time{})
+}
+
+// SocketPath returns the path of the socket used by the daemon for the store at storePath.
+func SocketPath(storePath string) string {
+	return filepath.Join(storePath, ".albatross", "daemon.sock")
+}
+
+// Status describes a running daemon.
+type Status struct {
+	// Store is the path to the store.
+	Store string `json:"store"`
+
+	// Entries is the number of entries in memory.
+	Entries int `json:"entries"`
+
+	// Encrypted is true if the store was decrypted into memory and is still encrypted on disk.
+	Encrypted bool `json:"encrypted"`
+
+	// Started is when the daemon was started and Loaded is when the entries were last loaded.
+	Started time.Time `json:"started"`
+	Loaded  time.Time `json:"loaded"`
+
+	// PID is the process ID of the daemon.
+	PID int `json:"pid"`
+}
+
+// Daemon holds a store's collection in memory. It reloads the collection when the store's audit log shows it has changed,
+// see albatross.Store.Modified.
+type Daemon struct {
+	mu         sync.Mutex // guards collection, loaded and modified
+	collection *entries.Collection
+	loaded     time.Time
+	modified   time.Time
+
+	store     *albatross.Store
+	load      func() (*entries.Collection, error)
+	encrypted bool
+	started   time.Time
+
+	stop chan struct{}
+}
+
+// NewDaemon returns a new Daemon for the store. The entries are read using load, which could be store.Collection or
+// something which decrypts the entries into memory. If encrypted is true, the store is being kept encrypted on disk.
+func NewDaemon(store *albatross.Store, load func() (*entries.Collection, error), encrypted bool) (*Daemon, error) {
+	d := &Daemon{
+		store:     store,
+		load:      load,
+		encrypted: encrypted,
+		started:   time.Now(),
+		stop:      make(chan struct{}),
+	}
+
+	err := d.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Reload reads the entries again.
+func (d *Daemon) Reload() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.reload()
+}
+
+// reload reads the entries again. The caller must hold d.mu.
+func (d *Daemon) reload() error {
+	collection, err := d.load()
+	if err != nil {
+		return err
+	}
+
+	// This is checked after loading since decrypting the entries into memory is itself recorded in the audit log.
+	modified, err := d.store.Modified()
+	if err != nil {
+		return err
+	}
+
+	d.collection = collection
+	d.loaded = time.Now()
+	d.modified = modified
+
+	logrus.Infof("Loaded %d entries.", collection.Len())
+
+	return nil
+}
+
+// Collection returns the collection, reloading it first if the store has changed since it was last loaded.
+func (d *Daemon) Collection() (*entries.Collection, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	modified, err := d.store.Modified()
+	if err != nil {
+		return nil, err
+	}
+
+	if !modified.Equal(d.modified) {
+		err = d.reload()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return d.collection, nil
+}
+
+// Status returns the status of the daemon.
+func (d *Daemon) Status() Status {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return Status{
+		Store:     d.store.Path,
+		Entries:   d.collection.Len(),
+		Encrypted: d.encrypted,
+		Started:   d.started,
+		Loaded:    d.loaded,
+		PID:       os.Getpid(),
+	}
+}
+
+// Handler returns the http.Handler used to serve the daemon.
+func (d *Daemon) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(d.Status())
+	})
+
+	mux.HandleFunc("/collection", func(w http.ResponseWriter, r *http.Request) {
+		collection, err := d.Collection()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		err = gob.NewEncoder(w).Encode(encodeCollection(collection))
+		if err != nil {
+			logrus.Errorf("Couldn't send entries: %s", err)
+		}
+	})
+
+	mux.HandleFunc("/reload", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "expected POST", http.StatusMethodNotAllowed)
+			return
+		}
+
+		err := d.Reload()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	mux.HandleFunc("/stop", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "expected POST", http.StatusMethodNotAllowed)
+			return
+		}
+
+		d.Stop()
+	})
+
+	return mux
+}
+
+// Serve serves the daemon on the listener until it's asked to stop.
+func (d *Daemon) Serve(listener net.Listener) error {
+	server := &http.Server{Handler: d.Handler()}
+
+	errs := make(chan error, 1)
+	go func() { errs <- server.Serve(listener) }()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-d.stop:
+		return server.Close()
+	}
+}
+
+// Stop stops the daemon if it's serving.
+func (d *Daemon) Stop() {
+	select {
+	case <-d.stop:
+	default:
+		close(d.stop)
+	}
+}
+
+// Listen listens on the socket for the store at storePath. If there's a socket left behind by a daemon which is no
+// longer running, it's removed. If a daemon is already running, it returns ErrAlreadyRunning.
+func Listen(storePath string) (net.Listener, error) {
+	socketPath := SocketPath(storePath)
+
+	if _, err := os.Stat(socketPath); err == nil {
+		if _, err := Dial(storePath); err == nil {
+			return nil, ErrAlreadyRunning{Store: storePath}
+		}
+
+		err = os.Remove(socketPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := os.MkdirAll(filepath.Dir(socketPath), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the user running the daemon should be able to read their entries.
+	err = os.Chmod(socketPath, 0600)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	return listener, nil
+}
+
+// encodedCollection is a collection in a form which can be sent using gob.
+type encodedCollection struct {
+	Entries []*entries.Entry
+	Folders []*entries.Folder
+}
+
+// encodeCollection returns copies of the entries and folders in the collection which can be sent using gob. Links refer
+// back to the entry they're in, which gob can't send, so this is left out and filled in again by decodeCollection.
+func encodeCollection(collection *entries.Collection) encodedCollection {
+	list := collection.List().Slice()
+	encoded := encodedCollection{
+		Entries: make([]*entries.Entry, len(list)),
+		Folders: collection.Folders(),
+	}
+
+	for i, entry := range list {
+		copied := *entry
+		copied.OutboundLinks = make([]entries.Link, len(entry.OutboundLinks))
+
+		for j, link := range entry.OutboundLinks {
+			link.Parent = nil
+			copied.OutboundLinks[j] = link
+		}
+
+		encoded.Entries[i] = &copied
+	}
+
+	return encoded
+}
+
+// decodeCollection turns entries and folders received from the daemon back into a collection.
+func decodeCollection(decoded encodedCollection) (*entries.Collection, error) {
+	collection := entries.NewCollection()
+
+	for _, entry := range decoded.Entries {
+		for i := range entry.OutboundLinks {
+			entry.OutboundLinks[i].Parent = entry
+		}
+	}
+
+	err := collection.AddMany(decoded.Entries...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, folder := range decoded.Folders {
+		collection.AddFolder(folder)
+	}
+
+	return collection, nil
+}
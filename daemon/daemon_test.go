@@ -0,0 +1,115 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/otiai10/copy"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func tempTestStore(t *testing.T) (store *albatross.Store, cleanup func()) {
+	t.Helper()
+
+	tmpDir, err := ioutil.TempDir("", "albatross-daemon-test")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %s", err)
+	}
+
+	err = copy.Copy("../pkg/core/testdata/stores/testing.albatross", filepath.Join(tmpDir, "testing.albatross"))
+	if err != nil {
+		t.Fatalf("couldn't copy testdata: %s", err)
+	}
+
+	store, err = albatross.Load(filepath.Join(tmpDir, "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	return store, func() {
+		err = os.RemoveAll(tmpDir)
+		if err != nil {
+			t.Errorf("could not remove temporary directory: %s", err)
+		}
+	}
+}
+
+func TestDaemon(t *testing.T) {
+	store, cleanup := tempTestStore(t)
+	defer cleanup()
+
+	_, err := Dial(store.Path)
+	IsType(t, ErrNotRunning{}, err, "expecting ErrNotRunning before starting daemon")
+
+	d, err := NewDaemon(store, store.Collection, false)
+	if err != nil {
+		t.Fatalf("not expecting error creating daemon: %s", err)
+	}
+
+	listener, err := Listen(store.Path)
+	if err != nil {
+		t.Fatalf("not expecting error listening: %s", err)
+	}
+
+	served := make(chan error)
+	go func() { served <- d.Serve(listener) }()
+
+	_, err = Listen(store.Path)
+	IsType(t, ErrAlreadyRunning{}, err, "expecting ErrAlreadyRunning when daemon is running")
+
+	client, err := Dial(store.Path)
+	if err != nil {
+		t.Fatalf("not expecting error connecting to daemon: %s", err)
+	}
+
+	original, err := store.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error getting collection: %s", err)
+	}
+
+	collection, err := client.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error getting collection from daemon: %s", err)
+	}
+
+	Equal(t, original.Len(), collection.Len(), "expecting the same entries from the daemon")
+	Equal(t, original.Folders(), collection.Folders(), "expecting the same folders from the daemon")
+
+	pizza := collection.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: "food/pizza"})
+	if NotNil(t, pizza, "expecting pizza entry from daemon") {
+		Equal(t, "Pizza!", pizza.Title)
+
+		for _, link := range pizza.OutboundLinks {
+			Equal(t, pizza, link.Parent, "links should refer back to their entry")
+		}
+	}
+
+	err = store.Create("food/truffles", "---\ntitle: Truffles\nnested:\n  a: [1, 2, null]\n---\n\nTruffles are great.")
+	if err != nil {
+		t.Fatalf("not expecting error creating entry: %s", err)
+	}
+
+	collection, err = client.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error getting collection from daemon: %s", err)
+	}
+
+	Equal(t, original.Len()+1, collection.Len(), "daemon should reload after the store changes")
+
+	status, err := client.Status()
+	Nil(t, err, "not expecting error getting status")
+	Equal(t, collection.Len(), status.Entries)
+	Equal(t, store.Path, status.Store)
+
+	Nil(t, client.Stop(), "not expecting error stopping daemon")
+	Nil(t, <-served, "not expecting error from stopped daemon")
+
+	_, err = Dial(store.Path)
+	IsType(t, ErrNotRunning{}, err, "expecting ErrNotRunning after stopping daemon")
+}
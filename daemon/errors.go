@@ -0,0 +1,23 @@
+package daemon
+
+import "fmt"
+
+// ErrNotRunning is returned when connecting to a daemon for a store which doesn't have one running.
+type ErrNotRunning struct {
+	Store string
+}
+
+// Error returns the error message.
+func (e ErrNotRunning) Error() string {
+	return fmt.Sprintf("no daemon running for store %s", e.Store)
+}
+
+// ErrAlreadyRunning is returned when starting a daemon for a store which already has one running.
+type ErrAlreadyRunning struct {
+	Store string
+}
+
+// Error returns the error message.
+func (e ErrAlreadyRunning) Error() string {
+	return fmt.Sprintf("daemon already running for store %s", e.Store)
+}
@@ -1 +1,19 @@
+// Package albatross is the stable v1 API for Albatross stores, for programs which want to search, read and change the
+// entries in a store without going through the command line tool.
+//
+//	store, err := albatross.Load("/home/me/notes")
+//	if err != nil {
+//		// ...
+//	}
+//
+//	collection, err := store.Collection()
+//	if err != nil {
+//		// ...
+//	}
+//
+//	food, err := collection.Filter(entries.FilterPathsMatch("food/"))
+//
+// Everything here, along with the Reader interface and Filter constructors in the entries package, is kept compatible
+// within v1: nothing is removed and no signatures change. The Store itself comes from pkg/core, which the command line
+// tool uses and which has more than the stable API, but which can change between versions.
 package albatross
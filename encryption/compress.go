@@ -2,9 +2,9 @@ package encryption
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,9 +12,9 @@ import (
 
 // compress takes a source and variable writers and walks 'source' writing each file
 // found to the tar writer; the purpose for accepting multiple writers is to allow
-// for multiple outputs (for example a file, or md5 hash)
+// for multiple outputs (for example a file, or md5 hash). The tarball is compressed as configured.
 // Much of this code is courtesy of https://medium.com/@skdomino/taring-untaring-files-in-go-6b07cf56bc07.
-func compress(src string, writers ...io.Writer) error {
+func compress(src string, compression Compression, writers ...io.Writer) error {
 
 	// ensure the src actually exists before trying to tar it
 	if _, err := os.Stat(src); err != nil {
@@ -23,14 +23,15 @@ func compress(src string, writers ...io.Writer) error {
 
 	mw := io.MultiWriter(writers...)
 
-	gzw := gzip.NewWriter(mw)
-	defer gzw.Close()
+	cw, err := newCompressor(mw, compression)
+	if err != nil {
+		return err
+	}
 
-	tw := tar.NewWriter(gzw)
-	defer tw.Close()
+	tw := tar.NewWriter(cw)
 
 	// walk path
-	return filepath.Walk(src, func(file string, fi os.FileInfo, err error) error {
+	err = filepath.Walk(src, func(file string, fi os.FileInfo, err error) error {
 
 		// return on any error
 		if err != nil {
@@ -73,32 +74,46 @@ func compress(src string, writers ...io.Writer) error {
 
 		return nil
 	})
+	if err != nil {
+		tw.Close()
+		cw.Close()
+		return err
+	}
+
+	// the compressor may only write everything once it's closed, so errors closing matter here.
+	err = tw.Close()
+	if err != nil {
+		cw.Close()
+		return err
+	}
+
+	return cw.Close()
 }
 
 // uncompress takes a destination path and a reader; a tar reader loops over the tarfile
-// creating the file structure at 'dst' along the way, and writing any files
+// creating the file structure at 'dst' along the way, and writing any files. The compression is detected automatically.
 // Much of this code is courtesy of https://medium.com/@skdomino/taring-untaring-files-in-go-6b07cf56bc07
 func uncompress(r io.Reader, dst string) error {
 
-	gzr, err := gzip.NewReader(r)
+	dr, err := newDecompressor(r)
 	if err != nil {
 		return err
 	}
-	defer gzr.Close()
 
-	tr := tar.NewReader(gzr)
+	tr := tar.NewReader(dr)
 
 	for {
 		header, err := tr.Next()
 
 		switch {
 
-		// if no more files are found return
+		// if no more files are found, check the whole stream was valid
 		case err == io.EOF:
-			return nil
+			return closeDecompressor(dr)
 
 		// return any other error
 		case err != nil:
+			dr.Close()
 			return err
 
 		// if the header is nil, just skip it (not sure how this happens)
@@ -116,6 +131,7 @@ func uncompress(r io.Reader, dst string) error {
 		case tar.TypeDir:
 			if _, err := os.Stat(target); err != nil {
 				if err := os.MkdirAll(target, 0755); err != nil {
+					dr.Close()
 					return err
 				}
 			}
@@ -126,6 +142,7 @@ func uncompress(r io.Reader, dst string) error {
 			dir, _ := filepath.Split(target)
 			if _, err := os.Stat(dir); err != nil {
 				if err := os.MkdirAll(dir, 0755); err != nil {
+					dr.Close()
 					return err
 				}
 			}
@@ -133,11 +150,14 @@ func uncompress(r io.Reader, dst string) error {
 			// create the file
 			f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
 			if err != nil {
+				dr.Close()
 				return err
 			}
 
 			// copy over contents
 			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				dr.Close()
 				return err
 			}
 
@@ -147,3 +167,55 @@ func uncompress(r io.Reader, dst string) error {
 		}
 	}
 }
+
+// readFiles is like uncompress, but rather than creating the files it returns the ones for which keep returns true.
+func readFiles(r io.Reader, keep func(name string) bool) ([]DecryptedFile, error) {
+	dr, err := newDecompressor(r)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := tar.NewReader(dr)
+	files := []DecryptedFile{}
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			err = closeDecompressor(dr)
+			if err != nil {
+				return nil, err
+			}
+
+			return files, nil
+		} else if err != nil {
+			dr.Close()
+			return nil, err
+		}
+
+		name := filepath.ToSlash(header.Name)
+		if header.Typeflag != tar.TypeReg || !keep(name) {
+			continue
+		}
+
+		contents, err := ioutil.ReadAll(tr)
+		if err != nil {
+			dr.Close()
+			return nil, err
+		}
+
+		files = append(files, DecryptedFile{Name: name, ModTime: header.ModTime, Contents: contents})
+	}
+}
+
+// closeDecompressor closes a decompressor once everything needed has been read from it. Anything left, like the end of
+// the tar stream, is read first, since some problems such as a stream being cut short or failing its checksum are only
+// noticed at the very end. For zstd, closing is also when the exit status of the zstd command is checked.
+func closeDecompressor(dr io.ReadCloser) error {
+	_, err := io.Copy(ioutil.Discard, dr)
+	if err != nil {
+		dr.Close()
+		return fmt.Errorf("error decompressing: %w", err)
+	}
+
+	return dr.Close()
+}
@@ -0,0 +1,383 @@
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strconv"
+	"sync"
+)
+
+// The compression algorithms which can be used for encrypted directories.
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+	CompressionNone = "none"
+)
+
+// Compression configures how directories are compressed before they're encrypted.
+type Compression struct {
+	// Algorithm is one of CompressionGzip, CompressionZstd or CompressionNone. Zstandard requires the 'zstd' command to
+	// be installed, both to encrypt and to decrypt.
+	Algorithm string
+
+	// Level is the compression level. For gzip this is from 1 to 9 and for zstd from 1 to 22. Zero uses the default.
+	Level int
+
+	// Workers is the number of threads compressing in parallel. Zero or one compresses serially.
+	Workers int
+}
+
+// DefaultCompression is the compression used by EncryptDir, serial gzip.
+var DefaultCompression = Compression{Algorithm: CompressionGzip}
+
+// gzipChunkSize is the amount of data compressed by each worker when compressing gzip in parallel.
+const gzipChunkSize = 1 << 20
+
+// zstdMagic and gzipMagic are the bytes at the start of zstd and gzip streams, used to detect how an encrypted
+// directory was compressed.
+var (
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+// Validate returns an error if the algorithm isn't known, the level is out of range or, for zstd, the zstd command isn't
+// installed.
+func (c Compression) Validate() error {
+	switch c.Algorithm {
+	case CompressionGzip:
+		if c.Level < 0 || c.Level > gzip.BestCompression {
+			return fmt.Errorf("gzip compression level %d should be between 1 and 9", c.Level)
+		}
+	case CompressionZstd:
+		if c.Level < 0 || c.Level > 22 {
+			return fmt.Errorf("zstd compression level %d should be between 1 and 22", c.Level)
+		}
+
+		// Checking now means a missing zstd command is noticed before anything is encrypted.
+		if _, err := exec.LookPath("zstd"); err != nil {
+			return fmt.Errorf("zstd compression requires the zstd command to be installed: %w", err)
+		}
+	case CompressionNone:
+	default:
+		return fmt.Errorf("unknown compression %q, expected gzip, zstd or none", c.Algorithm)
+	}
+
+	if c.Workers < 0 {
+		return fmt.Errorf("number of compression workers %d can't be negative", c.Workers)
+	}
+
+	return nil
+}
+
+// newCompressor returns a writer which compresses what's written to it into w. Everything is only guaranteed to be
+// written once it has been closed.
+func newCompressor(w io.Writer, c Compression) (io.WriteCloser, error) {
+	err := c.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Algorithm {
+	case CompressionZstd:
+		return newZstdWriter(w, c.Level, c.Workers)
+	case CompressionNone:
+		return nopWriteCloser{w}, nil
+	}
+
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	if c.Workers > 1 {
+		return newParallelGzipWriter(w, level, c.Workers), nil
+	}
+
+	return gzip.NewWriterLevel(w, level)
+}
+
+// newDecompressor returns a reader which decompresses r, detecting whether it's gzip, zstd or not compressed at all.
+func newDecompressor(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, zstdMagic):
+		return newZstdReader(br)
+	}
+
+	return ioutil.NopCloser(br), nil
+}
+
+// nopWriteCloser is an io.Writer with a Close method that does nothing.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close does nothing.
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// parallelGzipWriter compresses data using multiple goroutines. The data is split into chunks which are each compressed
+// as separate gzip members, which are concatenated. Readers like gzip.Reader treat this as one stream.
+// Chunks are compressed as they fill up and written in order by another goroutine, so at most a chunk for each worker
+// is kept in memory at once.
+type parallelGzipWriter struct {
+	level int
+	chunk []byte
+	sent  bool
+
+	// pending are the results of the chunks being compressed, in the order they were written.
+	pending chan chan gzipChunk
+	done    chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// gzipChunk is the result of compressing a single chunk.
+type gzipChunk struct {
+	data []byte
+	err  error
+}
+
+// newParallelGzipWriter returns a parallelGzipWriter which writes to w.
+func newParallelGzipWriter(w io.Writer, level, workers int) *parallelGzipWriter {
+	p := &parallelGzipWriter{
+		level:   level,
+		pending: make(chan chan gzipChunk, workers-1),
+		done:    make(chan struct{}),
+	}
+
+	go p.writeChunks(w)
+
+	return p
+}
+
+// writeChunks writes each chunk to w once it has been compressed, until there are no more. After an error, the rest
+// of the chunks are discarded.
+func (p *parallelGzipWriter) writeChunks(w io.Writer) {
+	defer close(p.done)
+
+	for result := range p.pending {
+		chunk := <-result
+
+		err := chunk.err
+		if err == nil && p.failed() == nil {
+			_, err = w.Write(chunk.data)
+		}
+
+		if err != nil {
+			p.fail(err)
+		}
+	}
+}
+
+// Write adds data to the current chunk, starting to compress it once it's full.
+func (p *parallelGzipWriter) Write(data []byte) (int, error) {
+	written := 0
+
+	for len(data) > 0 {
+		if err := p.failed(); err != nil {
+			return written, err
+		}
+
+		if p.chunk == nil {
+			p.chunk = make([]byte, 0, gzipChunkSize)
+		}
+
+		n := gzipChunkSize - len(p.chunk)
+		if n > len(data) {
+			n = len(data)
+		}
+
+		p.chunk = append(p.chunk, data[:n]...)
+		data = data[n:]
+		written += n
+
+		if len(p.chunk) == gzipChunkSize {
+			p.send()
+		}
+	}
+
+	return written, nil
+}
+
+// send starts compressing the current chunk. It blocks while every worker is busy.
+func (p *parallelGzipWriter) send() {
+	chunk := p.chunk
+	p.chunk = nil
+	p.sent = true
+
+	result := make(chan gzipChunk, 1)
+	p.pending <- result
+
+	go func() {
+		var buf bytes.Buffer
+
+		gzw, err := gzip.NewWriterLevel(&buf, p.level)
+		if err != nil {
+			result <- gzipChunk{err: err}
+			return
+		}
+
+		_, err = gzw.Write(chunk)
+		if err == nil {
+			err = gzw.Close()
+		}
+
+		result <- gzipChunk{data: buf.Bytes(), err: err}
+	}()
+}
+
+// Close compresses what's left and waits for everything to be written.
+func (p *parallelGzipWriter) Close() error {
+	// Even without any data, there has to be one member for the output to be valid gzip.
+	if len(p.chunk) > 0 || !p.sent {
+		p.send()
+	}
+
+	close(p.pending)
+	<-p.done
+
+	return p.failed()
+}
+
+// fail records the first error compressing or writing a chunk.
+func (p *parallelGzipWriter) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// failed returns the first error compressing or writing a chunk, if there's been one.
+func (p *parallelGzipWriter) failed() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.err
+}
+
+// zstdWriter compresses data by piping it through the zstd command.
+type zstdWriter struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr bytes.Buffer
+}
+
+// newZstdWriter starts the zstd command, writing compressed data to w.
+func newZstdWriter(w io.Writer, level, workers int) (*zstdWriter, error) {
+	args := []string{"-q", "-c"}
+
+	if level > 19 {
+		args = append(args, "--ultra")
+	}
+
+	if level > 0 {
+		args = append(args, "-"+strconv.Itoa(level))
+	}
+
+	if workers > 1 {
+		args = append(args, "-T"+strconv.Itoa(workers))
+	}
+
+	z := &zstdWriter{cmd: exec.Command("zstd", args...)}
+	z.cmd.Stdout = w
+	z.cmd.Stderr = &z.stderr
+
+	stdin, err := z.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	z.stdin = stdin
+
+	err = z.cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("zstd compression requires the zstd command: %w", err)
+	}
+
+	return z, nil
+}
+
+// Write writes data to be compressed.
+func (z *zstdWriter) Write(data []byte) (int, error) {
+	return z.stdin.Write(data)
+}
+
+// Close waits for zstd to finish compressing.
+func (z *zstdWriter) Close() error {
+	err := z.stdin.Close()
+	if err != nil {
+		return err
+	}
+
+	err = z.cmd.Wait()
+	if err != nil {
+		return fmt.Errorf("error running zstd: %w: %s", err, z.stderr.String())
+	}
+
+	return nil
+}
+
+// zstdReader decompresses data by piping it through the zstd command.
+type zstdReader struct {
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	stderr bytes.Buffer
+}
+
+// newZstdReader starts the zstd command, decompressing r.
+func newZstdReader(r io.Reader) (*zstdReader, error) {
+	z := &zstdReader{cmd: exec.Command("zstd", "-d", "-q", "-c")}
+	z.cmd.Stdin = r
+	z.cmd.Stderr = &z.stderr
+
+	stdout, err := z.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	z.stdout = stdout
+
+	err = z.cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("decompressing zstd requires the zstd command: %w", err)
+	}
+
+	return z, nil
+}
+
+// Read reads decompressed data.
+func (z *zstdReader) Read(p []byte) (int, error) {
+	return z.stdout.Read(p)
+}
+
+// Close waits for zstd to finish decompressing.
+func (z *zstdReader) Close() error {
+	// Anything left unread has to be drained, otherwise zstd could block writing it.
+	_, err := io.Copy(ioutil.Discard, z.stdout)
+	if err != nil {
+		return err
+	}
+
+	err = z.cmd.Wait()
+	if err != nil {
+		return fmt.Errorf("error running zstd: %w: %s", err, z.stderr.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package encryption
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestEncryptDirCompressed(t *testing.T) {
+	compressions := map[string]Compression{
+		"gzip":          {Algorithm: CompressionGzip, Level: 9},
+		"gzip parallel": {Algorithm: CompressionGzip, Workers: 4},
+		"none":          {Algorithm: CompressionNone},
+		"zstd":          {Algorithm: CompressionZstd, Level: 3, Workers: 2},
+	}
+
+	for name, compression := range compressions {
+		t.Run(name, func(t *testing.T) {
+			if compression.Algorithm == CompressionZstd {
+				if _, err := exec.LookPath("zstd"); err != nil {
+					t.Skip("zstd isn't installed")
+				}
+			}
+
+			dir, cleanup := tempTestDir(t)
+			defer cleanup()
+
+			err := EncryptDirCompressed(
+				filepath.Join(dir, "testdata", "example"),
+				filepath.Join(dir, "testdata", "example.pgp"),
+				filepath.Join(dir, "testdata", "public.key"),
+				compression,
+			)
+			if err != nil {
+				t.Fatalf("wasn't expecting error when encrypting: %s", err)
+			}
+
+			err = DecryptDir(
+				filepath.Join(dir, "testdata", "example.pgp"),
+				filepath.Join(dir, "testdata", "example-new"),
+				filepath.Join(dir, "testdata", "public.key"),
+				filepath.Join(dir, "testdata", "private.key"),
+				"pa$$word",
+			)
+			if err != nil {
+				t.Fatalf("wasn't expecting error when decrypting: %s", err)
+			}
+
+			bs, err := ioutil.ReadFile(filepath.Join(dir, "testdata", "example-new", "text.txt"))
+			if err != nil {
+				t.Fatalf("wasn't expecting error when reading test data file: %s", err)
+			}
+
+			Equal(t, "Hello, I'm some text.", string(bs))
+		})
+	}
+}
+
+func TestParallelGzipWriter(t *testing.T) {
+	data := make([]byte, 3*gzipChunkSize+123)
+	rand.New(rand.NewSource(1)).Read(data[:gzipChunkSize])
+
+	var compressed bytes.Buffer
+
+	w, err := newCompressor(&compressed, Compression{Algorithm: CompressionGzip, Workers: 3})
+	if err != nil {
+		t.Fatalf("not expecting error creating compressor: %s", err)
+	}
+
+	_, err = w.Write(data)
+	Nil(t, err)
+	Nil(t, w.Close())
+
+	r, err := newDecompressor(&compressed)
+	if err != nil {
+		t.Fatalf("not expecting error creating decompressor: %s", err)
+	}
+
+	decompressed, err := ioutil.ReadAll(r)
+	Nil(t, err)
+	Nil(t, r.Close())
+
+	True(t, bytes.Equal(data, decompressed), "decompressed data should match the original")
+}
+
+func TestParallelGzipWriterSmallWrites(t *testing.T) {
+	for _, size := range []int{0, 10, gzipChunkSize, gzipChunkSize + 1} {
+		data := bytes.Repeat([]byte("pizza "), size/6+1)[:size]
+
+		var compressed bytes.Buffer
+
+		w := newParallelGzipWriter(&compressed, gzip.DefaultCompression, 2)
+
+		// Writing in uneven pieces means chunks are filled by more than one write.
+		for rest := data; len(rest) > 0; {
+			n := 4093
+			if n > len(rest) {
+				n = len(rest)
+			}
+
+			_, err := w.Write(rest[:n])
+			Nil(t, err)
+			rest = rest[n:]
+		}
+
+		Nil(t, w.Close())
+
+		r, err := gzip.NewReader(&compressed)
+		if !Nil(t, err, "expecting valid gzip for %d bytes", size) {
+			continue
+		}
+
+		decompressed, err := ioutil.ReadAll(r)
+		Nil(t, err)
+		True(t, bytes.Equal(data, decompressed), "decompressed data should match the original for %d bytes", size)
+	}
+}
+
+// failingWriter is an io.Writer which always fails.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestParallelGzipWriterError(t *testing.T) {
+	w := newParallelGzipWriter(failingWriter{}, gzip.DefaultCompression, 2)
+
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		_, err = w.Write(make([]byte, gzipChunkSize))
+	}
+
+	EqualError(t, w.Close(), "disk full", "expecting errors writing compressed chunks to be returned")
+}
+
+func TestCompressionValidateZstdMissing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "albatross-empty-path")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	os.Setenv("PATH", dir)
+
+	NotNil(t, Compression{Algorithm: CompressionZstd}.Validate(), "expecting zstd to need the zstd command")
+	Nil(t, Compression{Algorithm: CompressionGzip}.Validate())
+}
+
+func TestCompressionValidate(t *testing.T) {
+	Nil(t, DefaultCompression.Validate())
+	NotNil(t, Compression{Algorithm: "brotli"}.Validate())
+	NotNil(t, Compression{Algorithm: CompressionGzip, Level: 10}.Validate())
+	NotNil(t, Compression{Algorithm: CompressionZstd, Level: 23}.Validate())
+	NotNil(t, Compression{Algorithm: CompressionZstd, Workers: -1}.Validate())
+}
+
+func TestUncompressTruncated(t *testing.T) {
+	compressions := map[string]Compression{
+		"gzip parallel": {Algorithm: CompressionGzip, Workers: 2},
+		"zstd":          {Algorithm: CompressionZstd},
+	}
+
+	for name, compression := range compressions {
+		t.Run(name, func(t *testing.T) {
+			if compression.Algorithm == CompressionZstd {
+				if _, err := exec.LookPath("zstd"); err != nil {
+					t.Skip("zstd isn't installed")
+				}
+			}
+
+			dir, cleanup := tempTestDir(t)
+			defer cleanup()
+
+			var compressed bytes.Buffer
+
+			err := compress(filepath.Join(dir, "testdata", "example"), compression, &compressed)
+			if err != nil {
+				t.Fatalf("not expecting error compressing: %s", err)
+			}
+
+			keep := func(string) bool { return true }
+
+			files, err := readFiles(bytes.NewReader(compressed.Bytes()), keep)
+			Nil(t, err, "not expecting error reading the whole stream")
+			Len(t, files, 1)
+
+			// Cutting off just the end leaves every file intact, so only the checks at the end of the stream notice.
+			for _, cut := range []int{4, compressed.Len() / 2} {
+				truncated := compressed.Bytes()[:compressed.Len()-cut]
+
+				_, err = readFiles(bytes.NewReader(truncated), keep)
+				NotNil(t, err, "expecting error reading a stream missing its last %d bytes", cut)
+
+				err = uncompress(bytes.NewReader(truncated), filepath.Join(dir, "uncompressed"))
+				NotNil(t, err, "expecting error uncompressing a stream missing its last %d bytes", cut)
+			}
+		})
+	}
+}
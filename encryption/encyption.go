@@ -6,6 +6,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/albatross-org/go-pgp/pgp"
 )
@@ -14,9 +15,16 @@ import (
 // It will write out an encrypted file to newDirPath.
 //   gzip -> tar -> pgp
 func EncryptDir(dirPath, newDirPath, pathToPublicKey string) error {
+	return EncryptDirCompressed(dirPath, newDirPath, pathToPublicKey, DefaultCompression)
+}
+
+// EncryptDirCompressed is like EncryptDir but compresses the directory as configured, such as using zstd or compressing
+// gzip in parallel. DecryptDir detects the compression, so it doesn't need to be told.
+//   gzip/zstd/none -> tar -> pgp
+func EncryptDirCompressed(dirPath, newDirPath, pathToPublicKey string, compression Compression) error {
 	var buf bytes.Buffer
 
-	err := compress(dirPath, &buf)
+	err := compress(dirPath, compression, &buf)
 	if err != nil {
 		return fmt.Errorf("error compressing dir at path %s: %w", dirPath, err)
 	}
@@ -36,7 +44,7 @@ func EncryptDir(dirPath, newDirPath, pathToPublicKey string) error {
 
 // DecryptDir takes the path to an encrypted directory and decrypts it using the private key specified.
 // It will write the decrypted directory to newDirPath.
-//   pgp -> gzip -> tar
+//   pgp -> gzip/zstd/none -> tar
 func DecryptDir(dirPath, newDirPath, pathToPublicKey, pathToPrivateKey, password string) error {
 	f, err := os.Open(dirPath)
 	if err != nil {
@@ -63,6 +71,38 @@ func DecryptDir(dirPath, newDirPath, pathToPublicKey, pathToPrivateKey, password
 	return nil
 }
 
+// DecryptedFile is a file read from an encrypted directory by DecryptFiles.
+type DecryptedFile struct {
+	// Name is the path of the file within the directory, using forward slashes, like "food/pizza/entry.md".
+	Name string
+
+	ModTime  time.Time
+	Contents []byte
+}
+
+// DecryptFiles is like DecryptDir but rather than writing the decrypted directory out, it returns the files in it for
+// which keep returns true. The whole directory still has to be decrypted, but only the files kept are held in memory and
+// nothing is written to disk.
+func DecryptFiles(dirPath, pathToPublicKey, pathToPrivateKey, password string, keep func(name string) bool) ([]DecryptedFile, error) {
+	f, err := os.Open(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading encrypted directory %s: %w", dirPath, err)
+	}
+	defer f.Close()
+
+	decrypted, err := decrypt(pathToPublicKey, pathToPrivateKey, password, f)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := readFiles(bytes.NewReader(decrypted), keep)
+	if err != nil {
+		return nil, fmt.Errorf("error uncompressing decrypted directory %s: %w", dirPath, err)
+	}
+
+	return files, nil
+}
+
 func encrypt(publicKeyPath string, src io.Reader) ([]byte, error) {
 	publicKey, err := ioutil.ReadFile(publicKeyPath)
 	if err != nil {
@@ -13,3 +13,23 @@ type ErrPrivateKeyDecryptionFailed struct {
 func (e ErrPrivateKeyDecryptionFailed) Error() string {
 	return fmt.Sprintf("couldn't decrypt private key (%s): %s", e.PathToPrivateKey, e.Err)
 }
+
+// ErrPasswordNotSaved occurs when there isn't a password saved in the system keyring for an account.
+type ErrPasswordNotSaved struct {
+	Account string
+}
+
+// Error returns the error message.
+func (e ErrPasswordNotSaved) Error() string {
+	return fmt.Sprintf("no password saved in the keyring for %s", e.Account)
+}
+
+// ErrKeyringUnavailable occurs when the system keyring can't be used, such as because 'secret-tool' isn't installed.
+type ErrKeyringUnavailable struct {
+	Err error
+}
+
+// Error returns the error message.
+func (e ErrKeyringUnavailable) Error() string {
+	return fmt.Sprintf("system keyring unavailable: %s", e.Err)
+}
@@ -0,0 +1,164 @@
+package encryption
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// keyringService is the name passwords are saved under in the system keyring.
+const keyringService = "albatross"
+
+// Keyring saves passwords in the system keyring, so that stores can be decrypted without prompting for a password. It
+// uses the 'security' command for the Keychain on macOS, 'secret-tool' for libsecret on Linux and PowerShell for the
+// Windows Credential Manager.
+//
+// Passwords are saved for an account, which for stores is the path to the store.
+type Keyring struct {
+	goos string
+	run  func(stdin, name string, args ...string) (string, error)
+}
+
+// NewKeyring returns a Keyring for the current operating system.
+func NewKeyring() *Keyring {
+	return &Keyring{goos: runtime.GOOS, run: runKeyringCommand}
+}
+
+// Save saves the password for the account, replacing any password already saved.
+func (k *Keyring) Save(account, password string) error {
+	var err error
+
+	switch k.goos {
+	case "darwin":
+		// security can't read the password from stdin without prompting, so the whole command is given on stdin to
+		// its interactive mode instead, which keeps the password out of the arguments visible to other processes.
+		if strings.ContainsAny(password, "\r\n") {
+			return errors.New("couldn't save password: passwords saved in the Keychain can't contain newlines")
+		}
+
+		_, err = k.run(fmt.Sprintf(
+			"add-generic-password -U -s %s -a %s -w %s\n",
+			securityQuote(keyringService), securityQuote(account), securityQuote(password),
+		), "security", "-i")
+	case "windows":
+		_, err = k.run(password, "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsVault+fmt.Sprintf(
+			`$vault.Add((New-Object Windows.Security.Credentials.PasswordCredential(%s, %s, [Console]::In.ReadToEnd())))`,
+			powershellQuote(keyringService), powershellQuote(account),
+		))
+	default:
+		_, err = k.run(password, "secret-tool", "store", "--label", "Albatross passphrase for "+account, "service", keyringService, "account", account)
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrKeyringUnavailable{Err: err}
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("couldn't save password: %w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+
+	return fmt.Errorf("couldn't save password: %w", err)
+}
+
+// Load returns the password saved for the account. If no password has been saved, it returns ErrPasswordNotSaved.
+func (k *Keyring) Load(account string) (string, error) {
+	var out string
+	var err error
+
+	switch k.goos {
+	case "darwin":
+		out, err = k.run("", "security", "find-generic-password", "-s", keyringService, "-a", account, "-w")
+	case "windows":
+		out, err = k.run("", "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsVault+fmt.Sprintf(
+			`try { $c = $vault.Retrieve(%s, %s) } catch { exit 44 }; $c.RetrievePassword(); [Console]::Out.Write($c.Password)`,
+			powershellQuote(keyringService), powershellQuote(account),
+		))
+	default:
+		out, err = k.run("", "secret-tool", "lookup", "service", keyringService, "account", account)
+	}
+
+	if err != nil {
+		return "", k.wrap(account, err)
+	}
+
+	// secret-tool and security add a newline to the end of the password.
+	out = strings.TrimSuffix(strings.TrimSuffix(out, "\n"), "\r")
+	if out == "" {
+		return "", ErrPasswordNotSaved{Account: account}
+	}
+
+	return out, nil
+}
+
+// Forget removes the password saved for the account. If no password has been saved, it returns ErrPasswordNotSaved.
+func (k *Keyring) Forget(account string) error {
+	var err error
+
+	switch k.goos {
+	case "darwin":
+		_, err = k.run("", "security", "delete-generic-password", "-s", keyringService, "-a", account)
+	case "windows":
+		_, err = k.run("", "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsVault+fmt.Sprintf(
+			`try { $vault.Remove($vault.Retrieve(%s, %s)) } catch { exit 44 }`,
+			powershellQuote(keyringService), powershellQuote(account),
+		))
+	default:
+		// secret-tool clear succeeds even if there's nothing to clear, so check first.
+		_, err = k.Load(account)
+		if err != nil {
+			return err
+		}
+
+		_, err = k.run("", "secret-tool", "clear", "service", keyringService, "account", account)
+	}
+
+	return k.wrap(account, err)
+}
+
+// wrap converts errors from running the keyring commands when loading or forgetting a password. A command which ran but failed means there wasn't a password
+// saved, since that's how the commands report it, and a command which couldn't be run means there's no keyring.
+func (k *Keyring) wrap(account string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return ErrPasswordNotSaved{Account: account}
+	}
+
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrKeyringUnavailable{Err: err}
+	}
+
+	return err
+}
+
+// windowsVault loads the Windows Credential Manager into the variable $vault in PowerShell.
+const windowsVault = `[void][Windows.Security.Credentials.PasswordVault,Windows.Security.Credentials,ContentType=WindowsRuntime]; $vault = New-Object Windows.Security.Credentials.PasswordVault; `
+
+// powershellQuote quotes a string so it can be used in a PowerShell command.
+func powershellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// securityQuote quotes a string so it can be used in a command given to 'security -i'.
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// runKeyringCommand runs a command with the stdin given and returns what it output.
+func runKeyringCommand(stdin, name string, args ...string) (string, error) {
+	c := exec.Command(name, args...)
+	c.Stdin = strings.NewReader(stdin)
+
+	out, err := c.Output()
+	return string(out), err
+}
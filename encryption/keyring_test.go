@@ -0,0 +1,101 @@
+package encryption
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+// fakeSecretTool returns a Keyring which behaves like secret-tool on Linux, storing passwords in memory.
+func fakeSecretTool() (*Keyring, map[string]string) {
+	saved := map[string]string{}
+
+	run := func(stdin, name string, args ...string) (string, error) {
+		account := args[len(args)-1]
+
+		switch args[0] {
+		case "store":
+			saved[account] = stdin
+		case "lookup":
+			password, ok := saved[account]
+			if !ok {
+				// secret-tool exits with a status of 1 if there's no password.
+				return "", exec.Command("false").Run()
+			}
+
+			return password + "\n", nil
+		case "clear":
+			delete(saved, account)
+		}
+
+		return "", nil
+	}
+
+	return &Keyring{goos: "linux", run: run}, saved
+}
+
+func TestKeyring(t *testing.T) {
+	keyring, saved := fakeSecretTool()
+
+	_, err := keyring.Load("/stores/default")
+	IsType(t, ErrPasswordNotSaved{}, err, "expecting ErrPasswordNotSaved before saving password")
+
+	err = keyring.Save("/stores/default", "hunter2")
+	Nil(t, err, "not expecting error saving password")
+	Equal(t, "hunter2", saved["/stores/default"], "password should be given on stdin")
+
+	password, err := keyring.Load("/stores/default")
+	Nil(t, err, "not expecting error loading password")
+	Equal(t, "hunter2", password, "trailing newline should be removed")
+
+	err = keyring.Forget("/stores/default")
+	Nil(t, err, "not expecting error forgetting password")
+	Empty(t, saved, "password should be removed")
+
+	err = keyring.Forget("/stores/default")
+	IsType(t, ErrPasswordNotSaved{}, err, "expecting ErrPasswordNotSaved forgetting password twice")
+}
+
+func TestKeyringUnavailable(t *testing.T) {
+	keyring := &Keyring{goos: "linux", run: func(stdin, name string, args ...string) (string, error) {
+		return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+	}}
+
+	_, err := keyring.Load("/stores/default")
+	IsType(t, ErrKeyringUnavailable{}, err, "expecting ErrKeyringUnavailable if secret-tool isn't installed")
+}
+
+func TestKeyringSaveDarwin(t *testing.T) {
+	var gotStdin string
+	var gotArgs []string
+
+	keyring := &Keyring{goos: "darwin", run: func(stdin, name string, args ...string) (string, error) {
+		gotStdin, gotArgs = stdin, args
+		return "", nil
+	}}
+
+	err := keyring.Save("/stores/default", `hunter"2`)
+	Nil(t, err, "not expecting error saving password")
+	Equal(t, []string{"-i"}, gotArgs, "password shouldn't be passed as an argument")
+	Equal(t, "add-generic-password -U -s \"albatross\" -a \"/stores/default\" -w \"hunter\\\"2\"\n", gotStdin)
+
+	err = keyring.Save("/stores/default", "hunter\n2")
+	NotNil(t, err, "expecting error saving password containing a newline")
+}
+
+func TestKeyringSaveFailed(t *testing.T) {
+	keyring := &Keyring{goos: "linux", run: func(stdin, name string, args ...string) (string, error) {
+		return "", &exec.ExitError{Stderr: []byte("Cannot create an item in a locked collection\n")}
+	}}
+
+	err := keyring.Save("/stores/default", "hunter2")
+	NotNil(t, err, "expecting error saving password")
+	False(t, errors.As(err, &ErrPasswordNotSaved{}), "failing to save shouldn't be reported as no password saved")
+	Contains(t, err.Error(), "locked collection", "error should include what the command output")
+}
+
+func TestPowershellQuote(t *testing.T) {
+	Equal(t, `'C:\Users\o''brien\notes'`, powershellQuote(`C:\Users\o'brien\notes`))
+}
@@ -0,0 +1,54 @@
+package entries
+
+import "sort"
+
+// Aliases returns the alternative titles for the entry, given under the "aliases" key in the front matter as either a
+// single title or a list of them:
+//
+//	---
+//	title: "Further Maths"
+//	aliases:
+//	  - FM
+//	  - Further Mathematics
+//	---
+//
+// Title links like "[[FM]]" resolve to an entry with the alias, unless another entry has it as its title.
+func (e *Entry) Aliases() []string {
+	switch raw := e.Metadata["aliases"].(type) {
+	case string:
+		if raw == "" {
+			return nil
+		}
+
+		return []string{raw}
+	case []interface{}:
+		aliases := []string{}
+		for _, alias := range raw {
+			if str, ok := alias.(string); ok && str != "" {
+				aliases = append(aliases, str)
+			}
+		}
+
+		return aliases
+	}
+
+	return nil
+}
+
+// TitleMatches returns every entry a title link to the title given could mean: the entries with it as their title, in the
+// order ResolveLink picks from, followed by the entries with it as an alias, sorted by path. If there's more than one,
+// links to the title are ambiguous.
+func (collection *Collection) TitleMatches(title string) []*Entry {
+	matches := append([]*Entry{}, collection.titleMap[title]...)
+
+	aliased := []*Entry{}
+	for _, entry := range collection.aliasMap[title] {
+		if entry.Title != title {
+			aliased = append(aliased, entry)
+		}
+	}
+
+	sort.Slice(aliased, func(i, j int) bool { return aliased[i].Path < aliased[j].Path })
+
+	return append(matches, aliased...)
+}
@@ -0,0 +1,32 @@
+package entries
+
+// Reader is the read-only part of a Collection, for code which only searches and reads entries. It's part of the v1 API:
+// methods won't be removed from it or have their signatures changed, so it's safe to depend on outside of this module.
+// The same goes for Entry, Link, List, Filter and the Filter constructors, such as FilterAnd and FilterPathsMatch.
+type Reader interface {
+	// Len returns the number of entries.
+	Len() int
+
+	// Get returns the entry at the path given, or nil if there isn't one.
+	Get(path string) *Entry
+
+	// In returns true if the entry is in the collection.
+	In(entry *Entry) bool
+
+	// ResolveLink returns the entry a link points to, or nil if there isn't one.
+	ResolveLink(link Link) *Entry
+
+	// FindLinksTo returns the links to the entry from the other entries.
+	FindLinksTo(entry *Entry) []Link
+
+	// Backlinks returns the entries which link to the entry.
+	Backlinks(entry *Entry) []*Entry
+
+	// Filter returns a new collection of the entries matching all the filters.
+	Filter(filters ...Filter) (*Collection, error)
+
+	// List returns the entries as a List, which can be sorted.
+	List() List
+}
+
+var _ Reader = (*Collection)(nil)
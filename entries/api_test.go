@@ -0,0 +1,26 @@
+package entries
+
+import (
+	"regexp"
+	"time"
+)
+
+// These fail to compile if the signature of a Filter constructor changes, since they're part of the v1 API.
+var (
+	_ func(...Filter) Filter         = FilterAnd
+	_ func(...Filter) Filter         = FilterOr
+	_ func(...Filter) Filter         = FilterNot
+	_ func(...string) Filter         = FilterPathsMatch
+	_ func(...string) Filter         = FilterPathsExact
+	_ func(...*regexp.Regexp) Filter = FilterPathsRegex
+	_ func(...string) Filter         = FilterTitlesMatch
+	_ func(...string) Filter         = FilterTitlesExact
+	_ func(...*regexp.Regexp) Filter = FilterTitlesRegex
+	_ func(...string) Filter         = FilterTags
+	_ func(...string) Filter         = FilterContentsMatch
+	_ func(...string) Filter         = FilterContentsExact
+	_ func(...*regexp.Regexp) Filter = FilterContentsRegex
+	_ func(time.Time) Filter         = FilterFrom
+	_ func(time.Time) Filter         = FilterUntil
+	_ func(int) Filter               = FilterLength
+)
@@ -0,0 +1,479 @@
+package entries
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Bibliography is a set of sources which can be cited by entries, keyed by their citation key.
+type Bibliography map[string]Reference
+
+// Reference is a single source in a bibliography, such as a book or paper.
+type Reference struct {
+	// Key is the citation key of the source, such as "smith2020".
+	Key string `json:"key"`
+
+	// Type is the type of the source, such as "book" or "article".
+	Type string `json:"type"`
+
+	Title string `json:"title"`
+
+	// Authors are the authors of the source, in the form "Family, Given".
+	Authors []string `json:"authors"`
+
+	Year string `json:"year"`
+
+	// Container is the journal, book or website the source is part of.
+	Container string `json:"container"`
+
+	Publisher string `json:"publisher"`
+	URL       string `json:"url"`
+	DOI       string `json:"doi"`
+}
+
+// reBibTeXAnd matches the "and" separating names in BibTeX.
+var reBibTeXAnd = regexp.MustCompile(`\s+and\s+`)
+
+// reLatexCommand matches simple LaTeX commands which are sometimes used in BibTeX values, such as "\emph" or "\&".
+var reLatexCommand = regexp.MustCompile(`\\([a-zA-Z]+\s?|.)`)
+
+// LoadBibliography reads a bibliography from a BibTeX (.bib) or CSL-JSON (.json) file.
+func LoadBibliography(path string) (Bibliography, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".bib", ".bibtex":
+		return ParseBibTeX(string(contents))
+	case ".json":
+		return ParseCSLJSON(contents)
+	}
+
+	return nil, fmt.Errorf("unknown bibliography format for %s, expected a .bib or .json file", path)
+}
+
+// Label returns a short label for citing the reference in text, such as "Smith 2020", "Smith and Jones 2020" or
+// "Smith et al. 2020".
+func (r Reference) Label() string {
+	families := []string{}
+	for _, author := range r.Authors {
+		families = append(families, strings.TrimSpace(strings.SplitN(author, ",", 2)[0]))
+	}
+
+	var names string
+
+	switch len(families) {
+	case 0:
+		names = r.Title
+		if names == "" {
+			names = r.Key
+		}
+	case 1:
+		names = families[0]
+	case 2:
+		names = families[0] + " and " + families[1]
+	default:
+		names = families[0] + " et al."
+	}
+
+	if r.Year == "" {
+		return names
+	}
+
+	return names + " " + r.Year
+}
+
+// String returns the reference formatted for a list of references, such as
+// "Smith, John and Jones, Amy (2020). A Title. Journal. Publisher. https://doi.org/10.1000/xyz".
+func (r Reference) String() string {
+	parts := []string{}
+
+	authors := strings.Join(r.Authors, " and ")
+	if r.Year != "" {
+		authors = strings.TrimSpace(authors + " (" + r.Year + ")")
+	}
+
+	for _, part := range []string{authors, r.Title, r.Container, r.Publisher} {
+		if part != "" {
+			parts = append(parts, strings.TrimSuffix(part, "."))
+		}
+	}
+
+	out := strings.Join(parts, ". ")
+	if out != "" {
+		out += "."
+	}
+
+	switch {
+	case r.DOI != "":
+		out += " https://doi.org/" + r.DOI
+	case r.URL != "":
+		out += " " + r.URL
+	}
+
+	return strings.TrimSpace(out)
+}
+
+// ParseBibTeX parses a bibliography in the BibTeX format. Abbreviations defined with @string are expanded, while
+// @preamble and @comment entries and any text outside of entries are ignored.
+func ParseBibTeX(contents string) (Bibliography, error) {
+	bib := Bibliography{}
+	p := &bibtexParser{src: []rune(contents), macros: map[string]string{}}
+
+	for {
+		start := p.skipTo('@')
+		if start == -1 {
+			return bib, nil
+		}
+
+		p.pos++
+		entryType := strings.ToLower(p.readWhile(func(r rune) bool { return unicode.IsLetter(r) }))
+		p.skipSpace()
+
+		if p.pos >= len(p.src) || (p.src[p.pos] != '{' && p.src[p.pos] != '(') {
+			continue
+		}
+
+		if entryType == "string" {
+			closer := map[rune]rune{'{': '}', '(': ')'}[p.src[p.pos]]
+			p.pos++
+
+			name := strings.ToLower(strings.TrimSpace(p.readWhile(func(r rune) bool { return r != '=' && r != closer })))
+			if p.pos < len(p.src) && p.src[p.pos] == '=' {
+				p.pos++
+
+				value, err := p.readValue(closer)
+				if err != nil {
+					return nil, fmt.Errorf("bibtex: @string %q: %w", name, err)
+				}
+
+				p.macros[name] = value
+			}
+
+			p.skipTo(closer)
+			continue
+		}
+
+		if entryType == "preamble" || entryType == "comment" {
+			if _, err := p.readDelimited(); err != nil {
+				return nil, fmt.Errorf("bibtex: %w", err)
+			}
+
+			continue
+		}
+
+		closer := '}'
+		if p.src[p.pos] == '(' {
+			closer = ')'
+		}
+		p.pos++
+
+		key := strings.TrimSpace(p.readWhile(func(r rune) bool { return r != ',' && r != closer }))
+		if key == "" {
+			return nil, fmt.Errorf("bibtex: @%s entry at offset %d has no key", entryType, start)
+		}
+
+		ref := Reference{Key: key, Type: entryType}
+		fields := map[string]string{}
+
+		for {
+			p.skipSpace()
+			if p.pos >= len(p.src) {
+				return nil, fmt.Errorf("bibtex: unterminated entry %q", key)
+			}
+
+			if p.src[p.pos] == closer {
+				p.pos++
+				break
+			}
+
+			if p.src[p.pos] == ',' {
+				p.pos++
+				continue
+			}
+
+			name := strings.ToLower(strings.TrimSpace(p.readWhile(func(r rune) bool { return r != '=' && r != ',' && r != closer })))
+			if p.pos >= len(p.src) || p.src[p.pos] != '=' {
+				continue
+			}
+
+			p.pos++
+			value, err := p.readValue(closer)
+			if err != nil {
+				return nil, fmt.Errorf("bibtex: field %q of entry %q: %w", name, key, err)
+			}
+
+			fields[name] = value
+		}
+
+		for name, value := range fields {
+			if name == "url" || name == "doi" {
+				fields[name] = strings.NewReplacer("{", "", "}", "").Replace(value)
+			} else {
+				fields[name] = cleanBibTeX(value)
+			}
+		}
+
+		ref.Title = fields["title"]
+		ref.Year = fields["year"]
+		ref.Publisher = fields["publisher"]
+		ref.URL = fields["url"]
+		ref.DOI = fields["doi"]
+
+		for _, field := range []string{"journal", "booktitle", "journaltitle", "howpublished"} {
+			if fields[field] != "" {
+				ref.Container = fields[field]
+				break
+			}
+		}
+
+		if ref.Year == "" && len(fields["date"]) >= 4 {
+			ref.Year = fields["date"][:4]
+		}
+
+		names := fields["author"]
+		if names == "" {
+			names = fields["editor"]
+		}
+
+		for _, name := range reBibTeXAnd.Split(names, -1) {
+			if name = strings.TrimSpace(name); name != "" {
+				ref.Authors = append(ref.Authors, bibtexName(name))
+			}
+		}
+
+		bib[key] = ref
+	}
+}
+
+// bibtexName converts a BibTeX name into the form "Family, Given".
+func bibtexName(name string) string {
+	if strings.Contains(name, ",") {
+		return name
+	}
+
+	fields := strings.Fields(name)
+	if len(fields) < 2 {
+		return name
+	}
+
+	return fields[len(fields)-1] + ", " + strings.Join(fields[:len(fields)-1], " ")
+}
+
+// bibtexParser holds the state of ParseBibTeX.
+type bibtexParser struct {
+	src []rune
+	pos int
+
+	// macros are the abbreviations defined using @string.
+	macros map[string]string
+}
+
+// skipTo moves to the next occurrence of r, returning its position or -1 if there isn't one.
+func (p *bibtexParser) skipTo(r rune) int {
+	for ; p.pos < len(p.src); p.pos++ {
+		if p.src[p.pos] == r {
+			return p.pos
+		}
+	}
+
+	return -1
+}
+
+// skipSpace moves past any whitespace.
+func (p *bibtexParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// readWhile reads runes while f returns true.
+func (p *bibtexParser) readWhile(f func(rune) bool) string {
+	start := p.pos
+	for p.pos < len(p.src) && f(p.src[p.pos]) {
+		p.pos++
+	}
+
+	return string(p.src[start:p.pos])
+}
+
+// readDelimited reads a value delimited by braces, parentheses or quotes, allowing nested braces, and returns the text
+// inside the delimiters.
+func (p *bibtexParser) readDelimited() (string, error) {
+	open := p.src[p.pos]
+	closer := map[rune]rune{'{': '}', '(': ')', '"': '"'}[open]
+	start := p.pos + 1
+	depth := 0
+
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		switch r := p.src[p.pos]; {
+		case r == '\\':
+			p.pos++
+		case r == closer && depth == 0:
+			p.pos++
+			return string(p.src[start : p.pos-1]), nil
+		case r == '{':
+			depth++
+		case r == '}' && depth > 0:
+			depth--
+		}
+	}
+
+	return "", fmt.Errorf("unterminated %q", open)
+}
+
+// readValue reads a field value, which may be several braced or quoted strings and numbers joined with '#'. The value
+// is returned as it appears in the file, without the delimiters.
+func (p *bibtexParser) readValue(closer rune) (string, error) {
+	var out strings.Builder
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return "", fmt.Errorf("unexpected end of input")
+		}
+
+		switch p.src[p.pos] {
+		case '{', '"':
+			value, err := p.readDelimited()
+			if err != nil {
+				return "", err
+			}
+
+			out.WriteString(value)
+		default:
+			word := p.readWhile(func(r rune) bool {
+				return r != ',' && r != '#' && r != closer && !unicode.IsSpace(r)
+			})
+
+			if macro, ok := p.macros[strings.ToLower(word)]; ok {
+				word = macro
+			}
+
+			out.WriteString(word)
+		}
+
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == '#' {
+			p.pos++
+			continue
+		}
+
+		return out.String(), nil
+	}
+}
+
+// cleanBibTeX removes braces and simple LaTeX commands from a BibTeX value and collapses whitespace.
+func cleanBibTeX(value string) string {
+	value = reLatexCommand.ReplaceAllStringFunc(value, func(command string) string {
+		if len(command) == 2 && !unicode.IsLetter(rune(command[1])) {
+			// Accents like \"{o} are dropped, escaped characters like \& are kept.
+			if strings.ContainsRune("\"'`^~=.", rune(command[1])) {
+				return ""
+			}
+
+			return command[1:]
+		}
+
+		return ""
+	})
+
+	value = strings.NewReplacer("{", "", "}", "", "~", " ", "--", "–").Replace(value)
+
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// cslName is a name in CSL-JSON.
+type cslName struct {
+	Family  string `json:"family"`
+	Given   string `json:"given"`
+	Literal string `json:"literal"`
+}
+
+// cslDate is a date in CSL-JSON.
+type cslDate struct {
+	DateParts [][]interface{} `json:"date-parts"`
+	Raw       string          `json:"raw"`
+}
+
+// cslItem is a single item in a CSL-JSON bibliography.
+type cslItem struct {
+	ID             interface{} `json:"id"`
+	Type           string      `json:"type"`
+	Title          string      `json:"title"`
+	Author         []cslName   `json:"author"`
+	Editor         []cslName   `json:"editor"`
+	Issued         cslDate     `json:"issued"`
+	ContainerTitle string      `json:"container-title"`
+	Publisher      string      `json:"publisher"`
+	URL            string      `json:"URL"`
+	DOI            string      `json:"DOI"`
+}
+
+// ParseCSLJSON parses a bibliography in the CSL-JSON format, as exported by tools like Zotero.
+func ParseCSLJSON(contents []byte) (Bibliography, error) {
+	items := []cslItem{}
+
+	err := json.Unmarshal(contents, &items)
+	if err != nil {
+		return nil, fmt.Errorf("csl-json: %w", err)
+	}
+
+	bib := Bibliography{}
+
+	for i, item := range items {
+		key := fmt.Sprint(item.ID)
+		if item.ID == nil || key == "" {
+			return nil, fmt.Errorf("csl-json: item %d has no id", i)
+		}
+
+		ref := Reference{
+			Key:       key,
+			Type:      item.Type,
+			Title:     item.Title,
+			Container: item.ContainerTitle,
+			Publisher: item.Publisher,
+			URL:       item.URL,
+			DOI:       item.DOI,
+		}
+
+		names := item.Author
+		if len(names) == 0 {
+			names = item.Editor
+		}
+
+		for _, name := range names {
+			switch {
+			case name.Literal != "":
+				ref.Authors = append(ref.Authors, name.Literal)
+			case name.Given != "":
+				ref.Authors = append(ref.Authors, name.Family+", "+name.Given)
+			default:
+				ref.Authors = append(ref.Authors, name.Family)
+			}
+		}
+
+		if len(item.Issued.DateParts) > 0 && len(item.Issued.DateParts[0]) > 0 {
+			switch year := item.Issued.DateParts[0][0].(type) {
+			case float64:
+				ref.Year = strconv.Itoa(int(year))
+			case string:
+				ref.Year = year
+			}
+		} else if len(item.Issued.Raw) >= 4 {
+			ref.Year = item.Issued.Raw[:4]
+		}
+
+		bib[key] = ref
+	}
+
+	return bib, nil
+}
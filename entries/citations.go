@@ -0,0 +1,87 @@
+package entries
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// reCitation matches Pandoc-style citations, e.g. "[@smith2020]", "[@smith2020, p. 4]" or "[see @smith2020; @jones2019]".
+	// Group 1 is the text inside the brackets.
+	reCitation = regexp.MustCompile(`\[((?:[^\[\]]*[\s;])?@[^\[\]]+)\]`)
+
+	// reCitationKey matches a single cite within a citation. Group 1 is the prefix, group 2 is the key and group 3 is
+	// the locator.
+	reCitationKey = regexp.MustCompile(`^\s*(.*?)\s*@([\pL\pN_](?:[\pL\pN_:.#$%&\-+?<>~/]*[\pL\pN_])?)\s*,?\s*(.*?)\s*$`)
+)
+
+// Citation is a citation in an entry, which may cite several sources at once, such as "[@smith2020; @jones2019]".
+type Citation struct {
+	// Cites are the sources cited, in the order they were given.
+	Cites []Cite `json:"cites"`
+
+	// Text is the citation as it appears in the entry, including the brackets.
+	Text string `json:"text"`
+
+	// Loc is the location of the citation in the entry's contents, so the citation is at Contents[Loc[0]:Loc[1]].
+	Loc []int `json:"loc"`
+}
+
+// Cite is a single source cited in a citation.
+type Cite struct {
+	// Key is the citation key of the source in the bibliography, such as "smith2020".
+	Key string `json:"key"`
+
+	// Prefix is any text before the key, such as "see" in "[see @smith2020]".
+	Prefix string `json:"prefix"`
+
+	// Locator is any text after the key, such as "p. 4" in "[@smith2020, p. 4]".
+	Locator string `json:"locator"`
+}
+
+// Citations returns the citations in the entry, in the order they appear. Citations inside code are ignored.
+func (e *Entry) Citations() []Citation {
+	citations := []Citation{}
+	code := reCodeBlock.FindAllStringIndex(e.Contents, -1)
+
+	for _, match := range reCitation.FindAllStringSubmatchIndex(e.Contents, -1) {
+		loc := match[:2]
+		if overlaps(loc, code) {
+			continue
+		}
+
+		citation := Citation{Text: e.Contents[loc[0]:loc[1]], Loc: loc}
+
+		for _, part := range strings.Split(e.Contents[match[2]:match[3]], ";") {
+			cite := reCitationKey.FindStringSubmatch(part)
+			if cite == nil {
+				continue
+			}
+
+			citation.Cites = append(citation.Cites, Cite{Prefix: cite[1], Key: cite[2], Locator: cite[3]})
+		}
+
+		if len(citation.Cites) != 0 {
+			citations = append(citations, citation)
+		}
+	}
+
+	return citations
+}
+
+// CitedKeys returns the keys of every source cited in the entry, without duplicates, in the order they're first cited.
+func (e *Entry) CitedKeys() []string {
+	keys := []string{}
+	seen := map[string]bool{}
+
+	for _, citation := range e.Citations() {
+		for _, cite := range citation.Cites {
+			if !seen[cite.Key] {
+				keys = append(keys, cite.Key)
+				seen[cite.Key] = true
+			}
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,98 @@
+package entries
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestCitations(t *testing.T) {
+	entry := dummyEntry(
+		"thesis/intro",
+		"Introduction",
+		"As shown [@smith2020, p. 4], and [see @jones2019; @smith2020].\n\nEmail me [at me@example.com]. `[@code]` and [[Pizza]].",
+	)
+
+	citations := entry.Citations()
+	if !Len(t, citations, 2) {
+		return
+	}
+
+	Equal(t, "[@smith2020, p. 4]", citations[0].Text)
+	Equal(t, []Cite{{Key: "smith2020", Locator: "p. 4"}}, citations[0].Cites)
+	Equal(t, entry.Contents[citations[0].Loc[0]:citations[0].Loc[1]], citations[0].Text)
+
+	Equal(t, []Cite{{Key: "jones2019", Prefix: "see"}, {Key: "smith2020"}}, citations[1].Cites)
+
+	Equal(t, []string{"smith2020", "jones2019"}, entry.CitedKeys())
+}
+
+func TestParseBibTeX(t *testing.T) {
+	bib, err := ParseBibTeX(`
+@string{jfood = "Journal of Food"}
+
+This text is ignored.
+
+@article{smith2020,
+  author = {John Smith and Jones, Amy and {\"O}zil, Kaan},
+  title = {The {Pizza} Problem: {Crust} \& Cheese},
+  journal = jfood # " Science",
+  year = 2020,
+  doi = {10.1000/xyz},
+}
+
+@book(lee2019,
+  editor = "Lee, Sam",
+  title = "Ice Cream",
+  publisher = {Penguin},
+  url = {https://example.com/~lee},
+  date = {2019-05-01}
+)
+`)
+	if !Nil(t, err, "parsing bibtex, err should be nil") {
+		return
+	}
+
+	Equal(t, 2, len(bib))
+
+	smith := bib["smith2020"]
+	Equal(t, "article", smith.Type)
+	Equal(t, "The Pizza Problem: Crust & Cheese", smith.Title)
+	Equal(t, []string{"Smith, John", "Jones, Amy", "Ozil, Kaan"}, smith.Authors)
+	Equal(t, "2020", smith.Year)
+	Equal(t, "Smith et al. 2020", smith.Label())
+	Equal(t, "10.1000/xyz", smith.DOI)
+	Equal(t, "Journal of Food Science", smith.Container)
+
+	lee := bib["lee2019"]
+	Equal(t, "Lee 2019", lee.Label())
+	Equal(t, "https://example.com/~lee", lee.URL)
+	Equal(t, "Lee, Sam (2019). Ice Cream. Penguin. https://example.com/~lee", lee.String())
+}
+
+func TestParseCSLJSON(t *testing.T) {
+	bib, err := ParseCSLJSON([]byte(`[
+		{
+			"id": "smith2020",
+			"type": "article-journal",
+			"title": "The Pizza Problem",
+			"author": [{"family": "Smith", "given": "John"}, {"literal": "The Pizza Society"}],
+			"issued": {"date-parts": [[2020, 5]]},
+			"container-title": "Journal of Food"
+		}
+	]`))
+	if !Nil(t, err, "parsing csl-json, err should be nil") {
+		return
+	}
+
+	Equal(t, Reference{
+		Key:       "smith2020",
+		Type:      "article-journal",
+		Title:     "The Pizza Problem",
+		Authors:   []string{"Smith, John", "The Pizza Society"},
+		Year:      "2020",
+		Container: "Journal of Food",
+	}, bib["smith2020"])
+
+	Equal(t, "Smith and The Pizza Society 2020", bib["smith2020"].Label())
+}
@@ -2,6 +2,7 @@ package entries
 
 import (
 	"fmt"
+	"sort"
 )
 
 // Collection represents a searchable collection of entries.
@@ -9,13 +10,51 @@ import (
 type Collection struct {
 	titleMap map[string][]*Entry // entries can share titles
 	pathMap  map[string]*Entry   // paths are unique
+
+	// aliasMap holds the entries which have each alias, see Entry.Aliases. It's kept apart from titleMap so that going
+	// through titleMap gives every entry once.
+	aliasMap map[string][]*Entry
+
+	// linkMap is the reverse of every entry's outbound links, so that the links to an entry can be found without looking
+	// through every entry. It maps the target of a link, see linkTarget, to the path of the entry it's from, to the links.
+	linkMap map[string]map[string][]Link
+
+	// folderMap holds the descriptions of folders which have a FolderMetaFile, by path.
+	folderMap map[string]*Folder
+
+	// registry resolves links to entries in other stores, if it isn't nil.
+	registry Registry
+}
+
+// Registry finds the collections of other stores by name, so that links to them like "{{work:notes/project}}" can be
+// resolved. It's implemented by the core package's Registry.
+type Registry interface {
+	// Collection returns the collection of the store with the name given. It returns an error if there isn't a store
+	// with that name or it can't be read, such as because it's encrypted.
+	Collection(store string) (*Collection, error)
 }
 
 // NewCollection returns a new, initialised Collection.
 func NewCollection() *Collection {
 	return &Collection{
-		titleMap: make(map[string][]*Entry),
-		pathMap:  make(map[string]*Entry),
+		titleMap:  make(map[string][]*Entry),
+		pathMap:   make(map[string]*Entry),
+		aliasMap:  make(map[string][]*Entry),
+		linkMap:   make(map[string]map[string][]Link),
+		folderMap: make(map[string]*Folder),
+	}
+}
+
+// linkTarget returns the key in the collection's linkMap for the target of a link.
+func linkTarget(link Link) string {
+	switch {
+	case link.Store != "":
+		// Links to other stores aren't links to the entry with the same path in this one.
+		return "store:" + link.Store + ":" + link.Path
+	case link.Type == LinkPathNoName || link.Type == LinkPathWithName:
+		return "path:" + link.Path
+	default:
+		return "title:" + link.Title
 	}
 }
 
@@ -29,34 +68,113 @@ func (collection *Collection) In(entry *Entry) bool {
 	return collection.pathMap[entry.Path] != nil
 }
 
-// FindLinksTo returns a list of links present in the collection which link to the entry specified..
+// Get returns the entry at the path given, or nil if there isn't one in the collection.
+func (collection *Collection) Get(path string) *Entry {
+	return collection.pathMap[path]
+}
+
+// FindLinksTo returns a list of links present in the collection which link to the entry specified, ordered by the path of
+// the entry they're from.
 func (collection *Collection) FindLinksTo(entry *Entry) []Link {
 	links := []Link{}
 
-	for _, existingEntry := range collection.pathMap {
-		for _, link := range existingEntry.OutboundLinks {
-			if link.Path == entry.Path {
-				links = append(links, link)
-			} else if link.Title == entry.Title {
-				links = append(links, link)
-			}
+	for _, target := range collection.linkTargets(entry) {
+		for _, from := range collection.linkMap[target] {
+			links = append(links, from...)
 		}
 	}
 
+	sort.SliceStable(links, func(i, j int) bool { return links[i].Parent.Path < links[j].Parent.Path })
+
 	return links
 }
 
+// Backlinks returns the entries in the collection which link to the entry specified, sorted by path. Unlike FindLinksTo,
+// each entry is only returned once however many times it links to the entry.
+func (collection *Collection) Backlinks(entry *Entry) []*Entry {
+	seen := map[string]bool{}
+	backlinks := []*Entry{}
+
+	for _, target := range collection.linkTargets(entry) {
+		for path := range collection.linkMap[target] {
+			if seen[path] {
+				continue
+			}
+
+			seen[path] = true
+			backlinks = append(backlinks, collection.pathMap[path])
+		}
+	}
+
+	sort.Slice(backlinks, func(i, j int) bool { return backlinks[i].Path < backlinks[j].Path })
+
+	return backlinks
+}
+
+// linkTargets returns the keys in linkMap for links which point to the entry: its path, its title and any of its aliases
+// which aren't the title of another entry and which it's picked for by aliased.
+func (collection *Collection) linkTargets(entry *Entry) []string {
+	targets := []string{"path:" + entry.Path, "title:" + entry.Title}
+
+	for _, alias := range entry.Aliases() {
+		if alias == entry.Title || len(collection.titleMap[alias]) != 0 {
+			continue
+		}
+
+		if collection.aliased(alias) == entry {
+			targets = append(targets, "title:"+alias)
+		}
+	}
+
+	return targets
+}
+
+// aliased returns the entry with the alias given, or nil if there isn't one. If more than one entry has the alias, the
+// one with the first path in alphabetical order is picked, so that it doesn't depend on the order entries were added.
+func (collection *Collection) aliased(alias string) *Entry {
+	var picked *Entry
+
+	for _, entry := range collection.aliasMap[alias] {
+		if picked == nil || entry.Path < picked.Path {
+			picked = entry
+		}
+	}
+
+	return picked
+}
+
+// SetRegistry sets the registry used to resolve links to other stores. Without one, links to other stores don't
+// resolve to anything. Collections made from this one, such as by Filter, use the same registry.
+func (collection *Collection) SetRegistry(registry Registry) {
+	collection.registry = registry
+}
+
 // ResolveLink takes a link and returns the entry that this link points to.
 // TODO: come up with a better way of handling links which match multiple entries (because they share titles). At the moment it returns the first match.
-// If it can't find the matching entry, it will return nil.
+// If it can't find the matching entry, it will return nil. Links to other stores are resolved using the collection's
+// Registry, see SetRegistry.
 func (collection *Collection) ResolveLink(link Link) *Entry {
+	if link.Store != "" {
+		if collection.registry == nil {
+			return nil
+		}
+
+		other, err := collection.registry.Collection(link.Store)
+		if err != nil || other == nil {
+			return nil
+		}
+
+		return other.pathMap[link.Path]
+	}
+
 	switch link.Type {
 	case LinkPathNoName, LinkPathWithName:
 		return collection.pathMap[link.Path]
 	case LinkTitleNoName, LinkTitleWithName:
+		// Titles come before aliases, so giving an entry an alias never changes where an existing link goes.
 		matching := collection.titleMap[link.Title]
 		if len(matching) == 0 {
-			return nil
+			return collection.aliased(link.Title)
 		}
 
 		return matching[0]
@@ -87,6 +205,20 @@ func (collection *Collection) Add(entry *Entry) error {
 	}
 	collection.titleMap[entry.Title] = append(collection.titleMap[entry.Title], entry)
 
+	for _, alias := range entry.Aliases() {
+		collection.aliasMap[alias] = append(collection.aliasMap[alias], entry)
+	}
+
+	for _, link := range entry.OutboundLinks {
+		target := linkTarget(link)
+		if collection.linkMap[target] == nil {
+			collection.linkMap[target] = make(map[string][]Link)
+		}
+
+		link.Parent = entry
+		collection.linkMap[target][entry.Path] = append(collection.linkMap[target][entry.Path], link)
+	}
+
 	return nil
 }
 
@@ -102,10 +234,24 @@ func (collection *Collection) AddMany(entries ...*Entry) error {
 	return nil
 }
 
+// Update replaces the entry in the collection which has the same path as the one given, such as after the entry has been
+// edited and parsed again. If there isn't one, the entry is added.
+func (collection *Collection) Update(entry *Entry) error {
+	if old := collection.pathMap[entry.Path]; old != nil {
+		err := collection.Delete(old)
+		if err != nil {
+			return err
+		}
+	}
+
+	return collection.Add(entry)
+}
+
 // Delete removes an entry from the entry collection.
 // If doesn't exist, it will return an ErrEntryDoesntExist.
 func (collection *Collection) Delete(entry *Entry) error {
-	if collection.pathMap[entry.Path] == nil {
+	existing := collection.pathMap[entry.Path]
+	if existing == nil {
 		return ErrEntryDoesntExist{Path: entry.Path, Title: entry.Title}
 	}
 
@@ -129,12 +275,88 @@ func (collection *Collection) Delete(entry *Entry) error {
 	collection.titleMap[entry.Title] = removeEntry(collection.titleMap[entry.Title], titleMapIndex)
 	delete(collection.pathMap, entry.Path)
 
+	for _, alias := range existing.Aliases() {
+		for i, aliased := range collection.aliasMap[alias] {
+			if aliased.Path == entry.Path {
+				collection.aliasMap[alias] = removeEntry(collection.aliasMap[alias], i)
+				break
+			}
+		}
+
+		if len(collection.aliasMap[alias]) == 0 {
+			delete(collection.aliasMap, alias)
+		}
+	}
+
+	// The links are removed using the entry in the collection, in case the one given has different links.
+	for _, link := range existing.OutboundLinks {
+		target := linkTarget(link)
+
+		delete(collection.linkMap[target], entry.Path)
+		if len(collection.linkMap[target]) == 0 {
+			delete(collection.linkMap, target)
+		}
+	}
+
 	return nil
 }
 
+// Copy returns a copy of the collection, which can be changed using Add and Delete without changing the original. The
+// entries themselves are shared, so changing an entry changes it in both; use Snapshot if the entries are going to be
+// changed.
+func (collection *Collection) Copy() *Collection {
+	return collection.copy()
+}
+
+// Snapshot returns a copy of the collection where every entry is a Clone of the original, so that the entries can be
+// changed, such as by an action which edits them, without changing the original collection or any collection made from
+// it by Filter.
+func (collection *Collection) Snapshot() *Collection {
+	snapshot := NewCollection()
+	snapshot.registry = collection.registry
+
+	for path, folder := range collection.folderMap {
+		snapshot.folderMap[path] = folder
+	}
+
+	// Going through titleMap keeps entries which share a title in the same order, like Filter.
+	for _, entries := range collection.titleMap {
+		for _, entry := range entries {
+			// Entries come from a collection, so their paths are unique and adding them can't fail.
+			_ = snapshot.Add(entry.Clone())
+		}
+	}
+
+	return snapshot
+}
+
+// MergeFrom adds the entries and folders from another collection which aren't already in this one, such as to combine
+// the results of two calls to Filter. Entries are matched by path, and where both collections have an entry at the
+// same path the one already in this collection is kept. Like Filter, the entries themselves are shared with the other
+// collection.
+func (collection *Collection) MergeFrom(other *Collection) {
+	for path, folder := range other.folderMap {
+		if collection.folderMap[path] == nil {
+			collection.folderMap[path] = folder
+		}
+	}
+
+	for _, entries := range other.titleMap {
+		for _, entry := range entries {
+			if collection.pathMap[entry.Path] != nil {
+				continue
+			}
+
+			// The path isn't in the collection, so adding the entry can't fail.
+			_ = collection.Add(entry)
+		}
+	}
+}
+
 // copy returns a copy of the collection.
 func (collection *Collection) copy() *Collection {
 	newGraph := NewCollection()
+	newGraph.registry = collection.registry
 
 	for k, v := range collection.pathMap {
 		newGraph.pathMap[k] = v
@@ -145,71 +367,58 @@ func (collection *Collection) copy() *Collection {
 		newGraph.titleMap[title] = entries
 	}
 
+	for alias, existingEntries := range collection.aliasMap {
+		newGraph.aliasMap[alias] = append([]*Entry{}, existingEntries...)
+	}
+
+	// The slices of links aren't changed once they're added, only replaced, so they can be shared.
+	for target, from := range collection.linkMap {
+		newFrom := make(map[string][]Link, len(from))
+		for path, links := range from {
+			newFrom[path] = links
+		}
+
+		newGraph.linkMap[target] = newFrom
+	}
+
+	for path, folder := range collection.folderMap {
+		newGraph.folderMap[path] = folder
+	}
+
 	return newGraph
 }
 
-// Filter runs the filters specified on the entries collection. It returns a copy of the entries collection.
+// Filter runs the filters specified on the entries collection. It returns a copy of the entries collection. Like Copy,
+// the entries are shared with the original, so they shouldn't be changed unless the collection is a Snapshot.
 func (collection *Collection) Filter(filters ...Filter) (*Collection, error) {
-	curr := collection.copy()
+	curr := NewCollection()
+	curr.registry = collection.registry
 	filter := FilterAnd(filters...)
 
-	remove := []*Entry{}
-
-	for _, entry := range collection.pathMap {
-		if !filter(entry) {
-			remove = append(remove, entry)
-		}
+	// Folders aren't entries, so they're kept whatever the filters are.
+	for path, folder := range collection.folderMap {
+		curr.folderMap[path] = folder
 	}
 
-	for _, entry := range remove {
-		err := curr.Delete(entry)
-		if err != nil {
-			return nil, err
+	// The entries which are allowed are added to a new collection rather than removing the others from a copy, since it
+	// means the links of entries which aren't allowed don't need to be indexed and then removed. Going through titleMap
+	// keeps entries which share a title in the same order, so title links resolve to the same entry.
+	for _, entries := range collection.titleMap {
+		for _, entry := range entries {
+			if !filter(entry) {
+				continue
+			}
+
+			err := curr.Add(entry)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	return curr, nil
 }
 
-// // Graph gets the graphviz representation of the Collection, mainly for debugging and visualisation purposes.
-// func (collection *Collection) Graph() (*graphviz.Graphviz, *cgraph.Graph, error) {
-// 	g := graphviz.New()
-
-// 	viz, err := g.Graph(graphviz.Directed)
-// 	if err != nil {
-// 		return nil, nil, fmt.Errorf("couldn't create graphviz collection: %w", err)
-// 	}
-
-// 	entryNodeMap := make(map[*Entry]*cgraph.Node)
-
-// 	for path, entry := range collection.pathMap {
-// 		n, err := viz.CreateNode(fmt.Sprintf("%s (%s)", path, entry.Title))
-// 		if err != nil {
-// 			return nil, nil, fmt.Errorf("couldn't create graphviz node: %w", err)
-// 		}
-
-// 		entryNodeMap[entry] = n
-// 	}
-
-// 	for _, entry := range collection.pathMap {
-// 		links := collection.FindLinksTo(entry)
-
-// 		for _, link := range links {
-// 			_, err := viz.CreateEdge(
-// 				fmt.Sprintf("%s => %s", link.Parent.Path, entry.Path),
-// 				entryNodeMap[link.Parent],
-// 				entryNodeMap[entry],
-// 			)
-
-// 			if err != nil {
-// 				return nil, nil, fmt.Errorf("couldn't create graphviz edge: %w", err)
-// 			}
-// 		}
-// 	}
-
-// 	return g, viz, nil
-// }
-
 // List converts the collection into an List.
 func (collection *Collection) List() List {
 	l := []*Entry{}
@@ -1,6 +1,8 @@
 package entries
 
 import (
+	"fmt"
+	"regexp"
 	"testing"
 	"time"
 
@@ -31,6 +33,8 @@ func TestCollectionBasic(t *testing.T) {
 	True(t, collection.In(entry2), "entry2 should be in collection")
 
 	Equal(t, 2, collection.Len(), "there should be two entries in the collection")
+	Equal(t, entry1, collection.Get("food/pizza"), "getting entry1 by path should return it")
+	Nil(t, collection.Get("journal/2020-08-05"), "getting entry3 by path should return nil")
 
 	False(t, collection.In(entry3), "entry3 should not be in collection")
 
@@ -51,6 +55,29 @@ func TestCollectionBasic(t *testing.T) {
 	Equal(t, 0, collection.Len(), "there should be no entries in the collection")
 }
 
+func TestCollectionUpdate(t *testing.T) {
+	collection := NewCollection()
+
+	old := dummyEntry("food/pizza", "Pizza", "")
+	updated := dummyEntry("food/pizza", "Pizza Margherita", "")
+	other := dummyEntry("moods/hunger", "Hunger", "")
+
+	err := collection.Add(old)
+	Nil(t, err, "adding entry, err should be nil")
+
+	err = collection.Update(updated)
+	Nil(t, err, "updating entry, err should be nil")
+	Equal(t, 1, collection.Len(), "updating an entry shouldn't add another")
+	True(t, collection.In(updated), "updated entry should be in collection")
+
+	Nil(t, collection.ResolveLink(Link{Type: LinkTitleNoName, Title: "Pizza"}), "old title shouldn't resolve")
+	Equal(t, updated, collection.ResolveLink(Link{Type: LinkTitleNoName, Title: "Pizza Margherita"}), "new title should resolve")
+
+	err = collection.Update(other)
+	Nil(t, err, "updating an entry which isn't in the collection, err should be nil")
+	True(t, collection.In(other), "entry which wasn't in the collection should be added")
+}
+
 func TestCollectionLinks(t *testing.T) {
 	collection := NewCollection()
 
@@ -90,6 +117,105 @@ func TestCollectionLinks(t *testing.T) {
 	Equal(t, 0, len(collection.FindLinksTo(hungerEntry)), "hungerEntry should still have no inbound links after pizza entry was removed")
 }
 
+func TestCollectionBacklinks(t *testing.T) {
+	collection := NewCollection()
+
+	pizza := dummyEntry("food/pizza", "Pizza", "")
+	journal := dummyEntry("journal/2020-08-06", "Journal", "")
+	journal.OutboundLinks = []Link{
+		{Path: "food/pizza", Type: LinkPathNoName},
+		{Title: "Pizza", Type: LinkTitleNoName},
+	}
+	notes := dummyEntry("notes/italy", "Italy", "")
+	notes.OutboundLinks = []Link{{Title: "Pizza", Name: "pizza", Type: LinkTitleWithName}}
+
+	err := collection.AddMany(pizza, journal, notes)
+	Nil(t, err, "adding entries, err should be nil")
+
+	Equal(t, []*Entry{journal, notes}, collection.Backlinks(pizza), "expecting each linking entry once, sorted by path")
+	Len(t, collection.FindLinksTo(pizza), 3, "expecting every link to pizza")
+	Empty(t, collection.Backlinks(journal), "expecting no backlinks to journal")
+
+	filtered, err := collection.Filter(FilterPathsMatch("food", "notes"))
+	Nil(t, err, "filtering, err should be nil")
+	Equal(t, []*Entry{notes}, filtered.Backlinks(pizza), "expecting only links from entries in the filtered collection")
+
+	copied := collection.Copy()
+
+	edited := dummyEntry("notes/italy", "Italy", "")
+	err = copied.Update(edited)
+	Nil(t, err, "updating entry, err should be nil")
+
+	Equal(t, []*Entry{journal}, copied.Backlinks(pizza), "expecting links to be removed when the entry is updated")
+	Equal(t, []*Entry{journal, notes}, collection.Backlinks(pizza), "expecting original collection to be unchanged")
+}
+
+// testRegistry is a Registry of collections by store name.
+type testRegistry map[string]*Collection
+
+func (r testRegistry) Collection(store string) (*Collection, error) {
+	collection, ok := r[store]
+	if !ok {
+		return nil, fmt.Errorf("no store named %q", store)
+	}
+
+	return collection, nil
+}
+
+func TestCollectionCrossStoreLinks(t *testing.T) {
+	work := NewCollection()
+	project := dummyEntry("notes/project", "Project", "")
+	Nil(t, work.Add(project))
+
+	personal := NewCollection()
+	localProject := dummyEntry("notes/project", "My Project", "")
+	journal := dummyEntry("journal/2020-08-06", "Journal", "")
+	journal.OutboundLinks = []Link{
+		{Store: "work", Path: "notes/project", Type: LinkPathNoName},
+		{Store: "school", Path: "notes/project", Type: LinkPathNoName},
+	}
+	Nil(t, personal.AddMany(localProject, journal))
+
+	Nil(t, personal.ResolveLink(journal.OutboundLinks[0]), "expecting links to other stores not to resolve without a registry")
+	Empty(t, personal.Backlinks(localProject), "expecting links to other stores not to be links to the same path in this one")
+
+	personal.SetRegistry(testRegistry{"work": work})
+
+	Equal(t, project, personal.ResolveLink(journal.OutboundLinks[0]))
+	Nil(t, personal.ResolveLink(journal.OutboundLinks[1]), "expecting links to unknown stores not to resolve")
+
+	filtered, err := personal.Filter(FilterPathsMatch("journal"))
+	Nil(t, err)
+	Equal(t, project, filtered.ResolveLink(journal.OutboundLinks[0]), "expecting filtered collections to keep the registry")
+}
+
+func BenchmarkCollectionBacklinks(b *testing.B) {
+	collection := NewCollection()
+
+	for i := 0; i < 5000; i++ {
+		entry := dummyEntry(fmt.Sprintf("entries/%d", i), fmt.Sprintf("Entry %d", i), "")
+		entry.OutboundLinks = []Link{
+			{Title: fmt.Sprintf("Entry %d", (i+1)%5000), Type: LinkTitleNoName},
+			{Path: fmt.Sprintf("entries/%d", (i+2)%5000), Type: LinkPathNoName},
+		}
+
+		err := collection.Add(entry)
+		if err != nil {
+			b.Fatalf("couldn't add entry: %s", err)
+		}
+	}
+
+	list := collection.List().Slice()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, entry := range list {
+			collection.Backlinks(entry)
+		}
+	}
+}
+
 func TestCollectionFilterPaths(t *testing.T) {
 	collection := NewCollection()
 
@@ -149,6 +275,44 @@ func TestCollectionFilterTitles(t *testing.T) {
 	Equal(t, 6, collection.Len(), "there should be stll be 6 entries in the orignal collection after filter")
 }
 
+func TestCollectionFilterRegex(t *testing.T) {
+	collection := NewCollection()
+
+	entryFood1 := dummyEntry("food/pizza", "Pizza", "Pizza is food, and great.")
+	entryFood2 := dummyEntry("food/ice-cream", "Ice Cream", "Ice cream is food, and amazing.")
+	entryAnimals1 := dummyEntry("animals/tiger", "Tigers", "Love me some tigers.")
+	entryJournal1 := dummyEntry("journal/2020-08-06", "Thursday", "Ate some pizza.")
+
+	err := collection.AddMany(entryFood1, entryFood2, entryAnimals1, entryJournal1)
+	Nil(t, err, "adding all entries, err should be nil")
+
+	collectionDated, err := collection.Filter(FilterPathsRegex(regexp.MustCompile(`^journal/\d{4}-\d{2}-\d{2}$`)))
+	Nil(t, err)
+	Equal(t, 1, collectionDated.Len(), "there should be 1 entry with a dated path")
+
+	collectionTitles, err := collection.Filter(FilterTitlesRegex(regexp.MustCompile(`^(Pizza|Tigers)$`), regexp.MustCompile(`day$`)))
+	Nil(t, err)
+	Equal(t, 3, collectionTitles.Len(), "there should be 3 entries matching either title regex")
+
+	collectionContents, err := collection.Filter(FilterContentsRegex(regexp.MustCompile(`(?i)\bpizza\b`)))
+	Nil(t, err)
+	Equal(t, 2, collectionContents.Len(), "there should be 2 entries mentioning pizza")
+
+	query := Query{PathsRegex: [][]string{{"^food/"}}, TitlesRegex: [][]string{{"^I"}}}
+	Nil(t, query.Validate())
+
+	collectionQuery, err := collection.Filter(query.Filter())
+	Nil(t, err)
+	Equal(t, 1, collectionQuery.Len(), "there should be 1 entry matching both regexes")
+
+	query = Query{ContentsRegex: [][]string{{"(unclosed"}}}
+	NotNil(t, query.Validate(), "expecting error for invalid regex")
+
+	collectionInvalid, err := collection.Filter(query.Filter())
+	Nil(t, err)
+	Equal(t, 0, collectionInvalid.Len(), "expecting invalid regex not to match anything")
+}
+
 func TestCollectionFilterTags(t *testing.T) {
 	collection := NewCollection()
 
@@ -235,3 +399,116 @@ func TestCollectionFilterDates(t *testing.T) {
 	Equal(t, 3, collectionAngerToDepression.Len(), "there should be 3 entries in the anger to depression collection")
 	Equal(t, 5, collection.Len(), "there should be stll be 5 entries in the orignal collection after filter")
 }
+
+func TestCollectionSnapshot(t *testing.T) {
+	collection := NewCollection()
+
+	pizza := dummyEntry("food/pizza", "Pizza", "I feel {{moods/hunger}}.")
+	pizza.Tags = []string{"@?food"}
+	pizza.Metadata = map[string]interface{}{"toppings": []interface{}{"cheese"}}
+	pizza.OutboundLinks = []Link{{Path: "moods/hunger", Type: LinkPathNoName, Loc: []int{7, 23}}}
+	hunger := dummyEntry("moods/hunger", "Hunger", "")
+
+	err := collection.AddMany(pizza, hunger)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	snapshot := collection.Snapshot()
+	snapshotPizza := snapshot.Get("food/pizza")
+
+	if !NotNil(t, snapshotPizza, "expecting the snapshot to have every entry") {
+		return
+	}
+
+	False(t, snapshotPizza == pizza, "expecting entries in the snapshot to be copies")
+	Equal(t, pizza, snapshotPizza)
+
+	snapshotPizza.Title = "Pineapple Pizza"
+	snapshotPizza.Tags[0] = "@?fruit"
+	snapshotPizza.Metadata["toppings"].([]interface{})[0] = "pineapple"
+	snapshotPizza.OutboundLinks[0].Loc[0] = 0
+
+	Equal(t, "Pizza", pizza.Title, "expecting changes to the snapshot not to change the original")
+	Equal(t, []string{"@?food"}, pizza.Tags)
+	Equal(t, []interface{}{"cheese"}, pizza.Metadata["toppings"])
+	Equal(t, []int{7, 23}, pizza.OutboundLinks[0].Loc)
+
+	backlinks := snapshot.Backlinks(snapshot.Get("moods/hunger"))
+	if Len(t, backlinks, 1) {
+		True(t, backlinks[0] == snapshotPizza, "expecting links in the snapshot to be from its own entries")
+	}
+}
+
+func TestCollectionMergeFrom(t *testing.T) {
+	collection := NewCollection()
+
+	err := collection.AddMany(
+		dummyEntry("food/pizza", "Pizza", ""),
+		dummyEntry("food/chips", "Chips", ""),
+		dummyEntry("moods/hunger", "Hunger", ""),
+	)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	food, err := collection.Filter(FilterPathsMatch("food/pizza"))
+	Nil(t, err, "filtering, err should be nil")
+
+	moods, err := collection.Filter(FilterPathsMatch("moods", "food/pizza"))
+	Nil(t, err, "filtering, err should be nil")
+
+	snapshot := moods.Snapshot()
+	snapshot.Get("food/pizza").Title = "Changed"
+
+	food.MergeFrom(snapshot)
+
+	Equal(t, 2, food.Len(), "expecting entries to only be added once")
+	NotNil(t, food.Get("moods/hunger"))
+	Equal(t, "Pizza", food.Get("food/pizza").Title, "expecting entries already in the collection to be kept")
+	Equal(t, 3, collection.Len(), "expecting the original collection not to change")
+}
+
+func TestCollectionAliases(t *testing.T) {
+	collection := NewCollection()
+
+	maths := dummyEntry("school/further-maths", "Further Maths", "")
+	maths.Metadata = map[string]interface{}{"aliases": []interface{}{"FM", "Further Mathematics"}}
+	club := dummyEntry("school/fm-club", "Film Club", "")
+	club.Metadata = map[string]interface{}{"aliases": "FM"}
+	radio := dummyEntry("music/radio", "FM", "")
+	timetable := dummyEntry("school/timetable", "Timetable", "[[Further Mathematics]] and [[FM]]")
+	timetable.OutboundLinks = []Link{
+		{Title: "Further Mathematics", Type: LinkTitleNoName, Loc: []int{0, 23}},
+		{Title: "FM", Type: LinkTitleNoName, Loc: []int{28, 34}},
+	}
+
+	err := collection.AddMany(maths, club, timetable)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	Equal(t, []string{"FM"}, club.Aliases(), "expecting a single alias to be allowed")
+	Equal(t, maths, collection.ResolveLink(timetable.OutboundLinks[0]), "expecting links to resolve using aliases")
+	Equal(t, club, collection.ResolveLink(timetable.OutboundLinks[1]), "expecting a shared alias to resolve to the first path")
+	Equal(t, []*Entry{timetable}, collection.Backlinks(maths))
+	Equal(t, []*Entry{timetable}, collection.Backlinks(club))
+	Equal(t, []*Entry{club, maths}, collection.TitleMatches("FM"))
+
+	err = collection.Add(radio)
+	Nil(t, err, "adding entry, err should be nil")
+
+	Equal(t, radio, collection.ResolveLink(timetable.OutboundLinks[1]), "expecting titles to come before aliases")
+	Equal(t, []*Entry{timetable}, collection.Backlinks(radio))
+	Equal(t, []*Entry{timetable}, collection.Backlinks(maths), "expecting links by other aliases to still count")
+	Equal(t, []*Entry{}, collection.Backlinks(club))
+	Equal(t, []*Entry{radio, club, maths}, collection.TitleMatches("FM"))
+
+	err = collection.Delete(maths)
+	Nil(t, err, "deleting entry, err should be nil")
+	Nil(t, collection.ResolveLink(timetable.OutboundLinks[0]), "expecting aliases to be removed with the entry")
+
+	filtered, err := collection.Filter(FilterPathsMatch("school"))
+	Nil(t, err, "filtering, err should be nil")
+	Equal(t, club, filtered.ResolveLink(timetable.OutboundLinks[1]), "expecting aliases to be kept by Filter")
+}
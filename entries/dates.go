@@ -0,0 +1,149 @@
+package entries
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateRange is the span of time a date refers to, such as the whole of a day for "yesterday". Start and End are both
+// included, so for an exact time they're the same.
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// reRelativeDate matches amounts of time like "30d", "-2w" or "+6mo".
+var reRelativeDate = regexp.MustCompile(`^([+-]?)(\d+)(h|d|w|mo|y)$`)
+
+// rePartialDate matches a year, a month or a day, like "2021", "2021-03" or "2021-03-14".
+var rePartialDate = regexp.MustCompile(`^(\d{4})(?:-(\d{2}))?(?:-(\d{2}))?$`)
+
+// ParseDate parses a date given in the layout, which is an exact time, or one of these, relative to now:
+//
+//	today, yesterday, tomorrow          the whole day
+//	this week, last week, next week     Monday to Sunday
+//	this month, last month, next month  the whole month
+//	this year, last year, next year     the whole year
+//	2021, 2021-03, 2021-03-14           the whole year, month or day
+//	30d, -30d, 2w, 12h, 6mo, 1y         that long ago, or that long from now if it starts with "+"
+//	90m, 1h30m                          any duration accepted by time.ParseDuration, also that long ago
+//
+// Days, weeks, months and years are in the location of now, apart from dates like "2021-03" which, like dates parsed
+// using the layout, are in UTC. Dates in entries don't have a time zone and are parsed as UTC, so to compare against
+// them now should usually be ClockTime(time.Now()).
+func ParseDate(s, layout string, now time.Time) (DateRange, error) {
+	s = strings.TrimSpace(s)
+
+	if t, err := time.Parse(layout, s); err == nil {
+		return DateRange{Start: t, End: t}, nil
+	}
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	week := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	year := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+
+	span := func(start time.Time, years, months, days int) DateRange {
+		return DateRange{Start: start, End: start.AddDate(years, months, days).Add(-time.Nanosecond)}
+	}
+
+	switch strings.ToLower(strings.Join(strings.Fields(s), " ")) {
+	case "today":
+		return span(day, 0, 0, 1), nil
+	case "yesterday":
+		return span(day.AddDate(0, 0, -1), 0, 0, 1), nil
+	case "tomorrow":
+		return span(day.AddDate(0, 0, 1), 0, 0, 1), nil
+	case "this week":
+		return span(week, 0, 0, 7), nil
+	case "last week":
+		return span(week.AddDate(0, 0, -7), 0, 0, 7), nil
+	case "next week":
+		return span(week.AddDate(0, 0, 7), 0, 0, 7), nil
+	case "this month":
+		return span(month, 0, 1, 0), nil
+	case "last month":
+		return span(month.AddDate(0, -1, 0), 0, 1, 0), nil
+	case "next month":
+		return span(month.AddDate(0, 1, 0), 0, 1, 0), nil
+	case "this year":
+		return span(year, 1, 0, 0), nil
+	case "last year":
+		return span(year.AddDate(-1, 0, 0), 1, 0, 0), nil
+	case "next year":
+		return span(year.AddDate(1, 0, 0), 1, 0, 0), nil
+	}
+
+	if match := rePartialDate.FindStringSubmatch(s); match != nil {
+		switch {
+		case match[3] != "":
+			t, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				return DateRange{}, err
+			}
+
+			return span(t, 0, 0, 1), nil
+		case match[2] != "":
+			t, err := time.Parse("2006-01", s)
+			if err != nil {
+				return DateRange{}, err
+			}
+
+			return span(t, 0, 1, 0), nil
+		default:
+			t, err := time.Parse("2006", s)
+			if err != nil {
+				return DateRange{}, err
+			}
+
+			return span(t, 1, 0, 0), nil
+		}
+	}
+
+	if match := reRelativeDate.FindStringSubmatch(s); match != nil {
+		n, err := strconv.Atoi(match[2])
+		if err != nil {
+			return DateRange{}, err
+		}
+
+		if match[1] != "+" {
+			n = -n
+		}
+
+		var t time.Time
+		switch match[3] {
+		case "h":
+			t = now.Add(time.Duration(n) * time.Hour)
+		case "d":
+			t = now.AddDate(0, 0, n)
+		case "w":
+			t = now.AddDate(0, 0, 7*n)
+		case "mo":
+			t = now.AddDate(0, n, 0)
+		case "y":
+			t = now.AddDate(n, 0, 0)
+		}
+
+		return DateRange{Start: t, End: t}, nil
+	}
+
+	if duration, err := time.ParseDuration(strings.TrimLeft(s, "+-")); err == nil {
+		if !strings.HasPrefix(s, "+") {
+			duration = -duration
+		}
+
+		t := now.Add(duration)
+		return DateRange{Start: t, End: t}, nil
+	}
+
+	return DateRange{}, fmt.Errorf("can't parse date %q, expected a date in the format %q, a date like 2021-03, a day like \"yesterday\" or \"last week\", or an amount of time ago like 30d", s, layout)
+}
+
+// ClockTime returns the time as it would be shown on a clock in its location, but in UTC. Dates in entries don't have a
+// time zone and are parsed as UTC, so this is how a time compares to them.
+func ClockTime(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
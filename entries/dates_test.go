@@ -0,0 +1,74 @@
+package entries
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestParseDate(t *testing.T) {
+	// A Wednesday.
+	now := time.Date(2021, 3, 17, 15, 30, 0, 0, time.UTC)
+	layout := "2006-01-02 15:04"
+
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		s          string
+		start, end time.Time
+	}{
+		{"2021-03-01 12:00", time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC), time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{"today", day(2021, 3, 17), day(2021, 3, 18)},
+		{"Yesterday", day(2021, 3, 16), day(2021, 3, 17)},
+		{"tomorrow", day(2021, 3, 18), day(2021, 3, 19)},
+		{"this week", day(2021, 3, 15), day(2021, 3, 22)},
+		{"last  week", day(2021, 3, 8), day(2021, 3, 15)},
+		{"next week", day(2021, 3, 22), day(2021, 3, 29)},
+		{"this month", day(2021, 3, 1), day(2021, 4, 1)},
+		{"last month", day(2021, 2, 1), day(2021, 3, 1)},
+		{"next month", day(2021, 4, 1), day(2021, 5, 1)},
+		{"last year", day(2020, 1, 1), day(2021, 1, 1)},
+		{"2020", day(2020, 1, 1), day(2021, 1, 1)},
+		{"2021-02", day(2021, 2, 1), day(2021, 3, 1)},
+		{"2021-02-28", day(2021, 2, 28), day(2021, 3, 1)},
+		{"-30d", now.AddDate(0, 0, -30), now.AddDate(0, 0, -30).Add(time.Nanosecond)},
+		{"30d", now.AddDate(0, 0, -30), now.AddDate(0, 0, -30).Add(time.Nanosecond)},
+		{"2w", now.AddDate(0, 0, -14), now.AddDate(0, 0, -14).Add(time.Nanosecond)},
+		{"+3d", now.AddDate(0, 0, 3), now.AddDate(0, 0, 3).Add(time.Nanosecond)},
+		{"6mo", now.AddDate(0, -6, 0), now.AddDate(0, -6, 0).Add(time.Nanosecond)},
+		{"1y", now.AddDate(-1, 0, 0), now.AddDate(-1, 0, 0).Add(time.Nanosecond)},
+		{"12h", now.Add(-12 * time.Hour), now.Add(-12 * time.Hour).Add(time.Nanosecond)},
+		{"90m", now.Add(-90 * time.Minute), now.Add(-90 * time.Minute).Add(time.Nanosecond)},
+	}
+
+	for _, test := range tests {
+		date, err := ParseDate(test.s, layout, now)
+		if !NoError(t, err, "parsing %q", test.s) {
+			continue
+		}
+
+		Equal(t, test.start, date.Start, "start of %q", test.s)
+
+		// End is inclusive, so the end of a span is a nanosecond before the next one starts. Exact times end where they
+		// start.
+		end := test.end.Add(-time.Nanosecond)
+		if test.start.Equal(test.end) {
+			end = test.end
+		}
+
+		Equal(t, end, date.End, "end of %q", test.s)
+	}
+
+	for _, s := range []string{"", "someday", "2021-13", "3 days ago", "30x"} {
+		_, err := ParseDate(s, layout, now)
+		Error(t, err, "expecting %q not to parse", s)
+	}
+
+	// Weeks start on Monday, even on a Sunday.
+	date, err := ParseDate("this week", layout, day(2021, 3, 21))
+	NoError(t, err)
+	Equal(t, day(2021, 3, 15), date.Start)
+}
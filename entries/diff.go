@@ -0,0 +1,253 @@
+package entries
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// ChangeType is the way an entry changed between two collections.
+type ChangeType string
+
+// The ways an entry can change.
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "modified"
+)
+
+// Change describes how an entry changed between two collections.
+type Change struct {
+	// Type is whether the entry was added, removed or modified.
+	Type ChangeType `json:"type"`
+
+	// Path is the path to the entry, such as "food/pizza".
+	Path string `json:"path"`
+
+	// Old and New are the entry before and after the change. Old is nil if the entry was added and New is nil if it
+	// was removed.
+	Old *Entry `json:"-"`
+	New *Entry `json:"-"`
+
+	// Diff is a unified diff of the entry.md file. Added and removed entries are diffed against an empty file.
+	Diff string `json:"diff"`
+}
+
+// diffContext is the number of unchanged lines shown around the changes in a diff.
+const diffContext = 3
+
+// Diff returns the entries which were added, removed or modified between the old and the new collection, sorted by
+// path. An entry is modified if the contents of its entry.md file changed.
+func Diff(old, new *Collection) []Change {
+	changes := []Change{}
+
+	for path, oldEntry := range old.pathMap {
+		newEntry, ok := new.pathMap[path]
+
+		switch {
+		case !ok:
+			changes = append(changes, Change{Type: ChangeRemoved, Path: path, Old: oldEntry})
+		case oldEntry.OriginalContents != newEntry.OriginalContents:
+			changes = append(changes, Change{Type: ChangeModified, Path: path, Old: oldEntry, New: newEntry})
+		}
+	}
+
+	for path, newEntry := range new.pathMap {
+		if _, ok := old.pathMap[path]; !ok {
+			changes = append(changes, Change{Type: ChangeAdded, Path: path, New: newEntry})
+		}
+	}
+
+	for i, change := range changes {
+		var oldContents, newContents string
+		oldName, newName := "/dev/null", "/dev/null"
+
+		if change.Old != nil {
+			oldContents, oldName = change.Old.OriginalContents, "a/"+change.Path+"/entry.md"
+		}
+
+		if change.New != nil {
+			newContents, newName = change.New.OriginalContents, "b/"+change.Path+"/entry.md"
+		}
+
+		changes[i].Diff = UnifiedDiff(oldName, newName, oldContents, newContents, diffContext)
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+
+	return changes
+}
+
+// diffLine is a single line of a line-by-line diff. op is ' ' if the line is unchanged, '-' if it was removed and '+' if
+// it was added.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// diffLines returns a line-by-line diff of two texts.
+func diffLines(old, new string) []diffLine {
+	dmp := diffmatchpatch.New()
+
+	oldRunes, newRunes, lineArray := dmp.DiffLinesToRunes(old, new)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMainRunes(oldRunes, newRunes, false), lineArray)
+
+	lines := []diffLine{}
+
+	for _, diff := range diffs {
+		op := byte(' ')
+		switch diff.Type {
+		case diffmatchpatch.DiffDelete:
+			op = '-'
+		case diffmatchpatch.DiffInsert:
+			op = '+'
+		}
+
+		for _, text := range strings.SplitAfter(diff.Text, "\n") {
+			if text != "" {
+				lines = append(lines, diffLine{op: op, text: text})
+			}
+		}
+	}
+
+	return lines
+}
+
+// UnifiedDiff returns a diff of two texts in the unified format used by diff -u and git, showing the number of unchanged
+// lines given around each change. If the texts are the same, it returns an empty string.
+func UnifiedDiff(oldName, newName, old, new string, context int) string {
+	if old == new {
+		return ""
+	}
+
+	lines := diffLines(old, new)
+
+	// oldBefore[i] and newBefore[i] are the number of lines from each text which come before lines[i].
+	oldBefore := make([]int, len(lines)+1)
+	newBefore := make([]int, len(lines)+1)
+
+	for i, line := range lines {
+		oldBefore[i+1], newBefore[i+1] = oldBefore[i], newBefore[i]
+
+		if line.op != '+' {
+			oldBefore[i+1]++
+		}
+
+		if line.op != '-' {
+			newBefore[i+1]++
+		}
+	}
+
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	for i := 0; i < len(lines); i++ {
+		if lines[i].op == ' ' {
+			continue
+		}
+
+		// Find the end of the hunk, which is when there are more than twice the context lines without any changes.
+		start, end := i-context, i
+		if start < 0 {
+			start = 0
+		}
+		for j := i; j < len(lines) && j <= end+2*context; j++ {
+			if lines[j].op != ' ' {
+				end = j
+			}
+		}
+
+		end += context + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		oldStart, oldCount := oldBefore[start], oldBefore[end]-oldBefore[start]
+		newStart, newCount := newBefore[start], newBefore[end]-newBefore[start]
+
+		if oldCount > 0 {
+			oldStart++
+		}
+
+		if newCount > 0 {
+			newStart++
+		}
+
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+
+		for _, line := range lines[start:end] {
+			out.WriteByte(line.op)
+			out.WriteString(line.text)
+
+			if !strings.HasSuffix(line.text, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		i = end - 1
+	}
+
+	return out.String()
+}
+
+// WordDiffStat returns the number of words added and removed between two texts, where words are separated by whitespace.
+// It's like the line counts given by 'git diff --stat' but for prose, where a small edit to a long paragraph would
+// otherwise count as the whole line changing.
+func WordDiffStat(old, new string) (added, removed int) {
+	// Like DiffLinesToRunes, each distinct word is mapped to a rune so that the diff is done word by word.
+	runes := map[string]rune{}
+	toRunes := func(text string) []rune {
+		words := strings.Fields(text)
+		out := make([]rune, len(words))
+
+		for i, word := range words {
+			r, ok := runes[word]
+			if !ok {
+				r = rune(len(runes) + 1)
+				runes[word] = r
+			}
+
+			out[i] = r
+		}
+
+		return out
+	}
+
+	oldRunes, newRunes := toRunes(old), toRunes(new)
+
+	for _, diff := range diffmatchpatch.New().DiffMainRunes(oldRunes, newRunes, false) {
+		switch diff.Type {
+		case diffmatchpatch.DiffInsert:
+			added += len([]rune(diff.Text))
+		case diffmatchpatch.DiffDelete:
+			removed += len([]rune(diff.Text))
+		}
+	}
+
+	return added, removed
+}
+
+// ChangedMetadata returns the keys of the front matter which were added, removed or changed between old and new, sorted.
+func ChangedMetadata(old, new map[string]interface{}) []string {
+	changed := []string{}
+
+	for key, oldValue := range old {
+		newValue, ok := new[key]
+		if !ok || fmt.Sprint(oldValue) != fmt.Sprint(newValue) {
+			changed = append(changed, key)
+		}
+	}
+
+	for key := range new {
+		if _, ok := old[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed
+}
@@ -0,0 +1,105 @@
+package entries
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	Equal(t, "", UnifiedDiff("a", "b", "same\n", "same\n", 3), "diff of the same text should be empty")
+
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	new := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+
+	Equal(t, `--- a
++++ b
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`, UnifiedDiff("a", "b", old, new, 3))
+
+	Equal(t, `--- /dev/null
++++ b
+@@ -0,0 +1,2 @@
++new
++file
+\ No newline at end of file
+`, UnifiedDiff("/dev/null", "b", "", "new\nfile", 3))
+}
+
+func TestDiff(t *testing.T) {
+	withContents := func(path, contents string) *Entry {
+		entry := dummyEntry(path, path, contents)
+		entry.OriginalContents = contents
+		return entry
+	}
+
+	old := NewCollection()
+	err := old.AddMany(
+		withContents("food/pizza", "Pizza.\n"),
+		withContents("food/beans", "Beans.\n"),
+		withContents("food/ice-cream", "Ice cream.\n"),
+	)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	new := NewCollection()
+	err = new.AddMany(
+		withContents("food/pizza", "Pizza, with pineapple.\n"),
+		withContents("food/ice-cream", "Ice cream.\n"),
+		withContents("food/tacos", "Tacos.\n"),
+	)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	changes := Diff(old, new)
+	if !Len(t, changes, 3) {
+		return
+	}
+
+	Equal(t, ChangeRemoved, changes[0].Type)
+	Equal(t, "food/beans", changes[0].Path)
+	Equal(t, "--- a/food/beans/entry.md\n+++ /dev/null\n@@ -1,1 +0,0 @@\n-Beans.\n", changes[0].Diff)
+
+	Equal(t, ChangeModified, changes[1].Type)
+	Equal(t, "food/pizza", changes[1].Path)
+	Equal(t, "--- a/food/pizza/entry.md\n+++ b/food/pizza/entry.md\n@@ -1,1 +1,1 @@\n-Pizza.\n+Pizza, with pineapple.\n", changes[1].Diff)
+
+	Equal(t, ChangeAdded, changes[2].Type)
+	Equal(t, "food/tacos", changes[2].Path)
+	Nil(t, changes[2].Old)
+}
+
+func TestWordDiffStat(t *testing.T) {
+	added, removed := WordDiffStat("The pizza was good.\nWe ate it all.", "The pizza was really great.\nWe ate it all.\nThen we slept.")
+	Equal(t, 5, added, "expecting 'really great.' and 'Then we slept.' to be added")
+	Equal(t, 1, removed, "expecting 'good.' to be removed")
+
+	added, removed = WordDiffStat("", "Three new words")
+	Equal(t, 3, added)
+	Equal(t, 0, removed)
+
+	added, removed = WordDiffStat("Same   words\nhere", "Same words here")
+	Equal(t, 0, added+removed, "expecting whitespace changes not to count")
+}
+
+func TestChangedMetadata(t *testing.T) {
+	old := map[string]interface{}{"title": "Pizza", "tags": []interface{}{"food"}, "date": "2020-08-06"}
+	new := map[string]interface{}{"title": "Pizza", "tags": []interface{}{"food", "italian"}, "rating": 5}
+
+	Equal(t, []string{"date", "rating", "tags"}, ChangedMetadata(old, new))
+	Equal(t, []string{}, ChangedMetadata(old, old))
+}
@@ -0,0 +1,154 @@
+package entries
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// reHeading matches a Markdown heading, capturing the "#"s and the text of the heading.
+var reHeading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// Transclude returns the contents of the entry with each embed, like "{{!food/pizza}}", replaced by the contents of the
+// entry it points to. If the embed gives a section, like "{{!food/pizza#Toppings}}", only the text under that heading is
+// used. Embedded entries can embed other entries too. Embeds inside code are left alone.
+//
+// The contents of each entry are given by the contents func, so that they can be changed first, such as to expand
+// shortcodes, and embeds are found in what it returns. If it's nil, the entry's Contents are used.
+//
+// If an embedded entry or section doesn't exist it returns an ErrEmbedNotFound, and if an entry embeds itself it returns
+// an ErrEmbedCycle.
+func (collection *Collection) Transclude(entry *Entry, contents func(*Entry) (string, error)) (string, error) {
+	if contents == nil {
+		contents = func(e *Entry) (string, error) { return e.Contents, nil }
+	}
+
+	return collection.transclude(entry, contents, map[string]bool{})
+}
+
+func (collection *Collection) transclude(entry *Entry, contents func(*Entry) (string, error), embedding map[string]bool) (string, error) {
+	embedding[entry.Path] = true
+	defer delete(embedding, entry.Path)
+
+	out, err := contents(entry)
+	if err != nil {
+		return "", err
+	}
+
+	embeds := findEmbeds(out)
+
+	// Embeds are replaced from the end backwards so that the locations of the ones before don't change.
+	for i := len(embeds) - 1; i >= 0; i-- {
+		embed := embeds[i]
+
+		embedded := collection.ResolveLink(embed)
+		if embedded == nil {
+			return "", ErrEmbedNotFound{Path: entry.Path, Embed: embedTarget(embed)}
+		}
+
+		if embedding[embedded.Path] {
+			return "", ErrEmbedCycle{Path: embedded.Path}
+		}
+
+		text, err := collection.transclude(embedded, contents, embedding)
+		if err != nil {
+			return "", err
+		}
+
+		if embed.Section != "" {
+			var ok bool
+
+			text, ok = section(text, embed.Section)
+			if !ok {
+				return "", ErrEmbedNotFound{Path: entry.Path, Embed: embedTarget(embed)}
+			}
+		}
+
+		out = out[:embed.Loc[0]] + strings.Trim(text, "\n") + out[embed.Loc[1]:]
+	}
+
+	return out, nil
+}
+
+// findEmbeds returns the embeds in some text which aren't inside code, in the order they appear.
+func findEmbeds(text string) []Link {
+	_, links := newScanner(text, "", "").scan(false, true)
+	code := reCodeBlock.FindAllStringIndex(text, -1)
+
+	embeds := []Link{}
+
+	for _, link := range links {
+		if link.Embed && !insideAny(link.Loc, code) {
+			embeds = append(embeds, link)
+		}
+	}
+
+	sort.Slice(embeds, func(i, j int) bool { return embeds[i].Loc[0] < embeds[j].Loc[0] })
+
+	return embeds
+}
+
+// insideAny returns true if the location is inside any of the others.
+func insideAny(loc []int, others [][]int) bool {
+	for _, other := range others {
+		if loc[0] >= other[0] && loc[1] <= other[1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// embedTarget returns what an embed points to, like "food/pizza#Toppings".
+func embedTarget(embed Link) string {
+	target := embed.Path
+	if embed.Store != "" {
+		target = embed.Store + ":" + target
+	}
+
+	if embed.Section != "" {
+		target += "#" + embed.Section
+	}
+
+	return target
+}
+
+// section returns the text under the first heading in the contents with the text given, up to the next heading at the
+// same level or above. Headings are compared ignoring case. It returns false if there's no such heading.
+func section(contents, heading string) (string, bool) {
+	lines := strings.Split(contents, "\n")
+	level := 0
+	start := -1
+	fenced := false
+
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+			continue
+		}
+
+		match := reHeading.FindStringSubmatch(line)
+		if fenced || match == nil {
+			continue
+		}
+
+		if start == -1 {
+			if strings.EqualFold(match[2], heading) {
+				level = len(match[1])
+				start = i + 1
+			}
+
+			continue
+		}
+
+		if len(match[1]) <= level {
+			return strings.Join(lines[start:i], "\n"), true
+		}
+	}
+
+	if start == -1 {
+		return "", false
+	}
+
+	return strings.Join(lines[start:], "\n"), true
+}
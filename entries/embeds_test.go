@@ -0,0 +1,74 @@
+package entries
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestSplitEmbed(t *testing.T) {
+	cases := []struct {
+		target  string
+		embed   bool
+		section string
+		rest    string
+	}{
+		{"food/pizza", false, "", "food/pizza"},
+		{"!food/pizza", true, "", "food/pizza"},
+		{"!food/pizza#Toppings", true, "Toppings", "food/pizza"},
+		{"!work:notes#Plan", true, "Plan", "work:notes"},
+		{"!", false, "", "!"},
+		{"!#Toppings", false, "", "!#Toppings"},
+	}
+
+	for _, c := range cases {
+		embed, section, rest := splitEmbed(c.target)
+		Equal(t, c.embed, embed, "embed for %q", c.target)
+		Equal(t, c.section, section, "section for %q", c.target)
+		Equal(t, c.rest, rest, "rest for %q", c.target)
+	}
+}
+
+func TestTransclude(t *testing.T) {
+	parser, err := NewParser("2006-01-02 15:04", "@!", "@?")
+	if err != nil {
+		t.Fatalf("not expecting error creating parser: %s", err)
+	}
+
+	parse := func(path, content string) *Entry {
+		entry, err := parser.Parse(path, content)
+		if err != nil {
+			t.Fatalf("not expecting error parsing %s: %s", path, err)
+		}
+
+		entry.Path = path
+		return entry
+	}
+
+	pizza := parse("food/pizza", "---\ntitle: Pizza\n---\nPizza is great.\n\n## Toppings\n\nCheese and {{!food/tomato}}.\n\n### Extra\n\nOlives.\n\n## History\n\nOld.\n")
+	tomato := parse("food/tomato", "---\ntitle: Tomato\n---\ntomato\n")
+	menu := parse("menu", "---\ntitle: Menu\n---\n{{!food/pizza#toppings}}\n\n`{{!food/pizza}}` is how to embed it.")
+	loop := parse("loop", "---\ntitle: Loop\n---\nAgain: {{!loop}}")
+	missing := parse("missing", "---\ntitle: Missing\n---\n{{!food/pizza#Prices}}")
+
+	collection := NewCollection()
+	err = collection.AddMany(pizza, tomato, menu, loop, missing)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	if Len(t, pizza.OutboundLinks, 1) {
+		True(t, pizza.OutboundLinks[0].Embed)
+		Equal(t, "food/tomato", pizza.OutboundLinks[0].Path)
+	}
+
+	contents, err := collection.Transclude(menu, nil)
+	Nil(t, err)
+	Equal(t, "Cheese and tomato.\n\n### Extra\n\nOlives.\n\n`{{!food/pizza}}` is how to embed it.", contents)
+
+	_, err = collection.Transclude(loop, nil)
+	Equal(t, ErrEmbedCycle{Path: "loop"}, err)
+
+	_, err = collection.Transclude(missing, nil)
+	Equal(t, ErrEmbedNotFound{Path: "missing", Embed: "food/pizza#Prices"}, err)
+}
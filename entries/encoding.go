@@ -0,0 +1,55 @@
+package entries
+
+import "strings"
+
+// bom is the UTF-8 byte order mark, which some editors add to the start of files.
+const bom = "\ufeff"
+
+// Encoding describes how an entry's file is written on disk, so that it can be written back in the same way after it
+// has been normalised for parsing.
+type Encoding struct {
+	// CRLF is true if the lines of the file end with "\r\n" rather than "\n".
+	CRLF bool `json:"crlf"`
+
+	// BOM is true if the file starts with a UTF-8 byte order mark.
+	BOM bool `json:"bom"`
+}
+
+// DetectEncoding returns the encoding of the content. The line endings are CRLF if most of the lines in the content end
+// with "\r\n".
+func DetectEncoding(content string) Encoding {
+	crlf := strings.Count(content, "\r\n")
+	lf := strings.Count(content, "\n") - crlf
+
+	return Encoding{
+		CRLF: crlf > lf,
+		BOM:  strings.HasPrefix(content, bom),
+	}
+}
+
+// Normalise returns the content with any byte order mark removed and all "\r\n" line endings replaced with "\n", along
+// with the encoding it originally had.
+func Normalise(content string) (string, Encoding) {
+	encoding := DetectEncoding(content)
+
+	content = strings.TrimPrefix(content, bom)
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	return content, encoding
+}
+
+// Apply returns the content written with the encoding, converting its line endings and adding or removing a byte order
+// mark as needed.
+func (encoding Encoding) Apply(content string) string {
+	content, _ = Normalise(content)
+
+	if encoding.CRLF {
+		content = strings.ReplaceAll(content, "\n", "\r\n")
+	}
+
+	if encoding.BOM {
+		content = bom + content
+	}
+
+	return content
+}
@@ -0,0 +1,56 @@
+package entries
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestNormalise(t *testing.T) {
+	cases := []struct {
+		content    string
+		normalised string
+		encoding   Encoding
+	}{
+		{"a\nb\n", "a\nb\n", Encoding{}},
+		{"a\r\nb\r\n", "a\nb\n", Encoding{CRLF: true}},
+		{"\ufeffa\nb", "a\nb", Encoding{BOM: true}},
+		{"\ufeffa\r\nb\r\n", "a\nb\n", Encoding{CRLF: true, BOM: true}},
+		{"a\r\nb\nc\n", "a\nb\nc\n", Encoding{}},
+		{"a\rb", "a\rb", Encoding{}},
+	}
+
+	for _, tc := range cases {
+		normalised, encoding := Normalise(tc.content)
+
+		Equal(t, tc.normalised, normalised, "normalised content for %q", tc.content)
+		Equal(t, tc.encoding, encoding, "encoding for %q", tc.content)
+	}
+}
+
+func TestEncodingApply(t *testing.T) {
+	for _, content := range []string{"a\r\nb\r\n", "\ufeffa\nb\n", "\ufeffa\r\nb", "a\nb"} {
+		normalised, encoding := Normalise(content)
+		Equal(t, content, encoding.Apply(normalised), "applying the encoding should give back the original")
+	}
+
+	Equal(t, "a\r\nb", Encoding{CRLF: true}.Apply("a\r\nb"), "applying should not double up line endings")
+	Equal(t, "a\nb", Encoding{}.Apply("\ufeffa\r\nb"), "applying should remove line endings and marks not in the encoding")
+}
+
+func TestParseCRLF(t *testing.T) {
+	p := newTestParser(t)
+	content := "\ufeff---\r\ntitle: \"Windows\"\r\ndate: \"2020-08-05 11:58\"\r\n---\r\n\r\nSome [[Pizza]] and @!tag.\r\n"
+
+	entry := parseForTest(t, p, content)
+
+	Equal(t, "Windows", entry.Title)
+	Equal(t, "Some [[Pizza]] and @!tag.\n", entry.Contents)
+	Equal(t, Encoding{CRLF: true, BOM: true}, entry.Encoding)
+	Equal(t, []string{"@!tag"}, entry.Tags)
+
+	if Len(t, entry.OutboundLinks, 1) {
+		loc := entry.OutboundLinks[0].Loc
+		Equal(t, "[[Pizza]]", entry.Contents[loc[0]:loc[1]])
+	}
+}
@@ -16,9 +16,13 @@ type Entry struct {
 	// Contents is the contents of the file without front matter.
 	Contents string `json:"contents"`
 
-	// OriginalContents is the contents of the file with the front matter.
+	// OriginalContents is the contents of the file with the front matter. Like Contents, its line endings are normalised
+	// to "\n" and any byte order mark is removed, see Encoding.
 	OriginalContents string `json:"originalContents"`
 
+	// Encoding is the line ending style of the file and whether it had a byte order mark.
+	Encoding Encoding `json:"encoding"`
+
 	// Tags are all the tags present in the document. For example, "@!journal".
 	Tags []string `json:"tags"`
 
@@ -39,24 +43,72 @@ type Entry struct {
 
 	// Metadata is all the front-matter.
 	Metadata map[string]interface{} `json:"metadata"`
+
+	// Truncated is true if the entry was too long and its Contents were cut short, see Limits. OriginalContents is
+	// always the whole file.
+	Truncated bool `json:"truncated"`
 }
 
 // NewEntryFromFile returns a new Entry given a file system and a path to the `entry.md` file in that file system.
-// It will return an error if the entry cannot be read.
+// It will return an error if the entry cannot be read. Files larger than DefaultLimits allow give ErrEntryTooLarge and
+// files which look binary give ErrEntryBinary.
 func NewEntryFromFile(originalPath string) (*Entry, error) {
+	return NewEntryFromFileWithLimits(originalPath, DefaultLimits)
+}
+
+// NewEntryFromFileWithLimits is like NewEntryFromFile, but reads the entry using the limits given rather than
+// DefaultLimits.
+func NewEntryFromFileWithLimits(originalPath string, limits Limits) (*Entry, error) {
 	path := strings.TrimSuffix(originalPath, "/entry.md")
 
 	file, err := os.Open(originalPath)
 	if err != nil {
 		return nil, ErrEntryReadFailed{Path: path, Err: err}
 	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, ErrEntryReadFailed{Path: path, Err: fmt.Errorf("error getting file stat: %w", err)}
+	}
+
+	if limits.MaxSize > 0 && stat.Size() > limits.MaxSize {
+		return nil, ErrEntryTooLarge{Path: path, Size: stat.Size(), Limit: limits.MaxSize}
+	}
 
 	bytes, err := ioutil.ReadAll(file)
 	if err != nil {
 		return nil, ErrEntryReadFailed{Path: path, Err: err}
 	}
 
-	content := string(bytes)
+	entry, err := NewEntryFromContents(path, bytes, stat.ModTime(), limits)
+	if err != nil {
+		return nil, err
+	}
+
+	// Here we strip the path to the store itselft from the store.
+	// This means something like:
+	// "/home/user/.local/share/albatross/default/entries/journal/2020/04/10"
+	// becomes
+	// "journal/2020/04/10"
+	// Which is the format used by the rest of the program.
+	start := strings.Index(path, "entries")
+	if start != -1 {
+		path = path[start+8:]
+	}
+	entry.Path = path
+
+	return entry, nil
+}
+
+// NewEntryFromContents returns a new Entry given the path to it and the contents of its `entry.md` file, such as when
+// reading an entry from a past git commit rather than from disk. The date defaults to the modification time given.
+// Like NewEntryFromFile, contents which look binary give ErrEntryBinary, but size limits aren't checked since the
+// contents have already been read.
+func NewEntryFromContents(path string, contents []byte, modTime time.Time, limits Limits) (*Entry, error) {
+	if isBinary(contents) {
+		return nil, ErrEntryBinary{Path: path}
+	}
 
 	dateLayout := "2006-01-02 15:04" // TODO: get date format from config or something. Hard-coded for now.
 	builtinTagPrefix := "@!"         // TODO: get tag prefixes from config or something.
@@ -67,33 +119,75 @@ func NewEntryFromFile(originalPath string) (*Entry, error) {
 		return nil, err
 	}
 
-	entry, err := parser.Parse(path, content)
+	entry, err := parser.WithLimits(limits).Parse(path, string(contents))
 	if err != nil {
 		return nil, err
 	}
 
-	stat, err := file.Stat()
-	if err != nil {
-		return nil, ErrEntryReadFailed{Path: path, Err: fmt.Errorf("error getting file stat: %w", err)}
-	}
-
-	entry.ModTime = stat.ModTime()
+	entry.Path = path
+	entry.ModTime = modTime
 
 	if entry.Date == (time.Time{}) {
 		entry.Date = entry.ModTime
 	}
 
-	// Here we strip the path to the store itselft from the store.
-	// This means something like:
-	// "/home/user/.local/share/albatross/default/entries/journal/2020/04/10"
-	// becomes
-	// "journal/2020/04/10"
-	// Which is the format used by the rest of the program.
-	start := strings.Index(path, "entries")
-	if start != -1 {
-		path = path[start+8:]
+	return entry, nil
+}
+
+// Clone returns a deep copy of the entry, so that it can be changed without changing the original or any collection the
+// original is in. The front matter is copied too, including any maps and lists inside it.
+func (e *Entry) Clone() *Entry {
+	clone := *e
+
+	if e.Tags != nil {
+		clone.Tags = append([]string{}, e.Tags...)
 	}
-	entry.Path = path
 
-	return entry, nil
+	if e.OutboundLinks != nil {
+		clone.OutboundLinks = make([]Link, len(e.OutboundLinks))
+
+		for i, link := range e.OutboundLinks {
+			link.Loc = append([]int{}, link.Loc...)
+			if link.Parent == e {
+				link.Parent = &clone
+			}
+
+			clone.OutboundLinks[i] = link
+		}
+	}
+
+	if e.Metadata != nil {
+		clone.Metadata = cloneValue(e.Metadata).(map[string]interface{})
+	}
+
+	return &clone
+}
+
+// cloneValue returns a deep copy of a value from an entry's front matter.
+func cloneValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		clone := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			clone[k] = cloneValue(v)
+		}
+
+		return clone
+	case map[interface{}]interface{}:
+		clone := make(map[interface{}]interface{}, len(value))
+		for k, v := range value {
+			clone[k] = cloneValue(v)
+		}
+
+		return clone
+	case []interface{}:
+		clone := make([]interface{}, len(value))
+		for i, v := range value {
+			clone[i] = cloneValue(v)
+		}
+
+		return clone
+	}
+
+	return value
 }
@@ -30,6 +30,34 @@ func (e ErrEntryParseFailed) Error() string {
 // Unwrap returns the error embedded in the ErrEntryParseFailed.
 func (e ErrEntryParseFailed) Unwrap() error { return e.Err }
 
+// ErrFolderReadFailed is returned when a folder's FolderMetaFile cannot be read.
+type ErrFolderReadFailed struct {
+	Path string
+	Err  error
+}
+
+// Error returns a string representing the error.
+func (e ErrFolderReadFailed) Error() string {
+	return fmt.Sprintf("could not read folder metadata for %q: %s", e.Path, e.Err)
+}
+
+// Unwrap returns the error embedded in the ErrFolderReadFailed.
+func (e ErrFolderReadFailed) Unwrap() error { return e.Err }
+
+// ErrFolderParseFailed is returned when a folder's FolderMetaFile cannot be parsed.
+type ErrFolderParseFailed struct {
+	Path string
+	Err  error
+}
+
+// Error returns a string representing the error.
+func (e ErrFolderParseFailed) Error() string {
+	return fmt.Sprintf("could not parse folder metadata for %q: %s", e.Path, e.Err)
+}
+
+// Unwrap returns the error embedded in the ErrFolderParseFailed.
+func (e ErrFolderParseFailed) Unwrap() error { return e.Err }
+
 // ErrEntryAlreadyExists is returned by an EntryGraph when you attempt to add a duplicate entry.
 type ErrEntryAlreadyExists struct {
 	Path  string
@@ -63,3 +91,79 @@ type ErrListOutOfBounds struct {
 func (e ErrListOutOfBounds) Error() string {
 	return fmt.Sprintf("entry list out of bounds access %d with length %d", e.Index, e.Len)
 }
+
+// ErrIncludeNotFound is returned when an entry includes another entry which doesn't exist.
+type ErrIncludeNotFound struct {
+	Path    string
+	Include string
+}
+
+// Error returns a string representing the error.
+func (e ErrIncludeNotFound) Error() string {
+	return fmt.Sprintf("entry %q includes %q, which doesn't exist", e.Path, e.Include)
+}
+
+// ErrIncludeCycle is returned when an entry includes itself, either directly or through other entries.
+type ErrIncludeCycle struct {
+	Path string
+}
+
+// Error returns a string representing the error.
+func (e ErrIncludeCycle) Error() string {
+	return fmt.Sprintf("entry %q includes itself", e.Path)
+}
+
+// ErrEmbedNotFound is returned when an entry embeds another entry, or a section of one, which doesn't exist.
+type ErrEmbedNotFound struct {
+	Path  string
+	Embed string
+}
+
+// Error returns a string representing the error.
+func (e ErrEmbedNotFound) Error() string {
+	return fmt.Sprintf("entry %q embeds %q, which doesn't exist", e.Path, e.Embed)
+}
+
+// ErrEmbedCycle is returned when an entry embeds itself, either directly or through other entries.
+type ErrEmbedCycle struct {
+	Path string
+}
+
+// Error returns a string representing the error.
+func (e ErrEmbedCycle) Error() string {
+	return fmt.Sprintf("entry %q embeds itself", e.Path)
+}
+
+// ErrEntryTooLarge is returned when an entry.md file is larger than the maximum size allowed.
+type ErrEntryTooLarge struct {
+	Path  string
+	Size  int64
+	Limit int64
+}
+
+// Error returns a string representing the error.
+func (e ErrEntryTooLarge) Error() string {
+	return fmt.Sprintf("entry file %q is %d bytes, which is larger than the limit of %d bytes, skipping", e.Path, e.Size, e.Limit)
+}
+
+// ErrEntryBinary is returned when an entry.md file looks like it contains binary data rather than text.
+type ErrEntryBinary struct {
+	Path string
+}
+
+// Error returns a string representing the error.
+func (e ErrEntryBinary) Error() string {
+	return fmt.Sprintf("entry file %q looks like a binary file, skipping", e.Path)
+}
+
+// ErrQuerySyntax is returned when an expression in the query language can't be parsed.
+type ErrQuerySyntax struct {
+	Expression string
+	Pos        int
+	Msg        string
+}
+
+// Error returns a string representing the error.
+func (e ErrQuerySyntax) Error() string {
+	return fmt.Sprintf("invalid query %q at position %d: %s", e.Expression, e.Pos+1, e.Msg)
+}
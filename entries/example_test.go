@@ -0,0 +1,75 @@
+package entries_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+func ExampleCollection_Filter() {
+	collection := entries.NewCollection()
+
+	collection.AddMany(
+		&entries.Entry{Path: "food/pizza", Title: "Pizza", Tags: []string{"@?food"}},
+		&entries.Entry{Path: "food/chips", Title: "Chips", Tags: []string{"@?food"}},
+		&entries.Entry{Path: "moods/hunger", Title: "Hunger"},
+	)
+
+	food, err := collection.Filter(entries.FilterPathsMatch("food/"), entries.FilterTags("@?food"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, entry := range food.List().Sort(entries.SortPath).Slice() {
+		fmt.Println(entry.Path)
+	}
+
+	// Output:
+	// food/chips
+	// food/pizza
+}
+
+func ExampleFilterAnd() {
+	august := entries.FilterAnd(
+		entries.FilterFrom(time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)),
+		entries.FilterUntil(time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)),
+	)
+
+	fmt.Println(august(&entries.Entry{Date: time.Date(2020, 8, 6, 18, 24, 0, 0, time.UTC)}))
+	fmt.Println(august(&entries.Entry{Date: time.Date(2020, 9, 6, 18, 24, 0, 0, time.UTC)}))
+
+	// Output:
+	// true
+	// false
+}
+
+func ExampleCollection_ResolveLink() {
+	parser, err := entries.NewParser("2006-01-02 15:04", "@!", "@?")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	pizza, _ := parser.Parse("food/pizza", "---\ntitle: Pizza\n---\n\nPizza is great.")
+	pizza.Path = "food/pizza"
+
+	journal, _ := parser.Parse("journal/2020-08-06", "Ate [[Pizza]] and {{food/chips}}.")
+	journal.Path = "journal/2020-08-06"
+
+	collection := entries.NewCollection()
+	collection.AddMany(pizza, journal)
+
+	for _, link := range journal.OutboundLinks {
+		if linked := collection.ResolveLink(link); linked != nil {
+			fmt.Println("links to", linked.Path)
+		} else {
+			fmt.Println("broken link to", link.Path)
+		}
+	}
+
+	// Output:
+	// links to food/pizza
+	// broken link to food/chips
+}
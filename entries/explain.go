@@ -0,0 +1,54 @@
+package entries
+
+import "time"
+
+// FilterStep describes the effect of applying one filter to a collection, as part of Collection.Explain.
+type FilterStep struct {
+	// Name describes the filter, see NamedFilter.
+	Name string `json:"name"`
+
+	// Before and After are the number of entries before and after the filter was applied.
+	Before int `json:"before"`
+	After  int `json:"after"`
+
+	// Duration is how long the filter took to apply.
+	Duration time.Duration `json:"duration"`
+}
+
+// Eliminated returns the number of entries the filter removed.
+func (step FilterStep) Eliminated() int {
+	return step.Before - step.After
+}
+
+// Explain is like Filter, except the filters are applied one at a time and it also returns what each of them did. Since
+// each filter is only applied to the entries allowed by the ones before it, the order matters: a filter only counts as
+// eliminating an entry if none of the earlier filters did.
+func (collection *Collection) Explain(filters ...NamedFilter) (*Collection, []FilterStep, error) {
+	curr := collection
+	steps := []FilterStep{}
+
+	for _, filter := range filters {
+		start := time.Now()
+		before := curr.Len()
+
+		next, err := curr.Filter(filter.Filter)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		steps = append(steps, FilterStep{
+			Name:     filter.Name,
+			Before:   before,
+			After:    next.Len(),
+			Duration: time.Since(start),
+		})
+
+		curr = next
+	}
+
+	if curr == collection {
+		curr = collection.copy()
+	}
+
+	return curr, steps, nil
+}
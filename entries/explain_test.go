@@ -0,0 +1,57 @@
+package entries
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestCollectionExplain(t *testing.T) {
+	collection := NewCollection()
+
+	err := collection.AddMany(
+		dummyEntry("food/pizza", "Pizza", "Cheese and tomato."),
+		dummyEntry("food/ice-cream", "Ice Cream", "Cold."),
+		dummyEntry("moods/hunger", "Hunger", "Pizza would be nice."),
+	)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	query := Query{
+		PathsMatch:         [][]string{{"food", "moods"}},
+		TitlesMatchExclude: [][]string{{"Ice"}},
+		ContentsMatch:      [][]string{{"Pizza"}},
+	}
+
+	filters := query.Filters()
+	if !Len(t, filters, 3) {
+		return
+	}
+
+	Equal(t, `contents contain "Pizza"`, filters[0].Name)
+	Equal(t, `path contains "food" OR "moods"`, filters[1].Name)
+	Equal(t, `title doesn't contain "Ice"`, filters[2].Name)
+
+	filtered, steps, err := collection.Explain(filters...)
+	if err != nil {
+		t.Fatalf("not expecting error explaining query: %s", err)
+	}
+
+	Equal(t, 1, filtered.Len(), "only the hunger entry should match")
+	Equal(t, []int{2, 0, 0}, []int{steps[0].Eliminated(), steps[1].Eliminated(), steps[2].Eliminated()}, "only the first filter should eliminate entries")
+	Equal(t, 3, collection.Len(), "the original collection shouldn't be changed")
+
+	expected, err := collection.Filter(query.Filter())
+	if err != nil {
+		t.Fatalf("not expecting error filtering: %s", err)
+	}
+
+	Equal(t, expected.List().Sort(SortAlpha).Slice(), filtered.List().Sort(SortAlpha).Slice(), "explaining should match the same entries as filtering")
+}
+
+func TestQuoteAll(t *testing.T) {
+	Equal(t, "nothing", quoteAll(nil, " OR "))
+	Equal(t, `"a" OR "b"`, quoteAll([]string{"a", "b"}, " OR "))
+	Equal(t, `"1" OR "2" OR "3" OR "4" OR "5" OR 2 more`, quoteAll([]string{"1", "2", "3", "4", "5", "6", "7"}, " OR "))
+}
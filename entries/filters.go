@@ -1,6 +1,9 @@
 package entries
 
 import (
+	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -80,6 +83,14 @@ func FilterPathsExact(paths ...string) Filter {
 	})
 }
 
+// FilterPathsRegex will allow entries whose paths match any of the regular expressions. Unlike FilterPathsMatch, the
+// regular expressions aren't anchored to the start of the path, so "^" should be used to match only from the start.
+func FilterPathsRegex(regexes ...*regexp.Regexp) Filter {
+	return Filter(func(entry *Entry) bool {
+		return matchAny(regexes, entry.Path)
+	})
+}
+
 // FilterTitlesMatch which match the given titles.
 // This function will allow entries where the title given is a substring.
 func FilterTitlesMatch(titles ...string) Filter {
@@ -114,6 +125,13 @@ func FilterTitlesExact(titles ...string) Filter {
 	})
 }
 
+// FilterTitlesRegex will allow entries whose titles match any of the regular expressions.
+func FilterTitlesRegex(regexes ...*regexp.Regexp) Filter {
+	return Filter(func(entry *Entry) bool {
+		return matchAny(regexes, entry.Title)
+	})
+}
+
 // FilterTags only allows entries with the given tags.
 func FilterTags(tags ...string) Filter {
 	return Filter(func(entry *Entry) bool {
@@ -162,6 +180,24 @@ func FilterContentsExact(contents ...string) Filter {
 	})
 }
 
+// FilterContentsRegex will allow entries whose contents match any of the regular expressions.
+func FilterContentsRegex(regexes ...*regexp.Regexp) Filter {
+	return Filter(func(entry *Entry) bool {
+		return matchAny(regexes, entry.Contents)
+	})
+}
+
+// matchAny returns true if any of the regular expressions match the string.
+func matchAny(regexes []*regexp.Regexp, s string) bool {
+	for _, re := range regexes {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // FilterFrom will remove all entries before the given date.
 func FilterFrom(date time.Time) Filter {
 	return Filter(func(entry *Entry) bool {
@@ -216,74 +252,193 @@ type Query struct {
 	TitlesMatch        [][]string
 	TitlesExactExclude [][]string
 	TitlesMatchExclude [][]string
+
+	// ContentsRegex, PathsRegex and TitlesRegex are regular expressions, in the syntax of the regexp package. If one
+	// isn't valid, the filter it's part of doesn't allow any entries, so use Validate to check them first.
+	ContentsRegex [][]string
+	PathsRegex    [][]string
+	TitlesRegex   [][]string
+
+	// Expression is an expression in the query language, such as `path:school/* AND tag:@?physics`, which entries must
+	// also match. See ParseExpression for the syntax.
+	Expression string
+}
+
+// Validate checks the regular expressions and the expression in the query, returning an error for the first which isn't
+// valid.
+func (q *Query) Validate() error {
+	if q.Expression != "" {
+		_, err := ParseExpression(q.Expression)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, group := range [][][]string{q.ContentsRegex, q.PathsRegex, q.TitlesRegex} {
+		for _, patterns := range group {
+			_, err := compileAll(patterns)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
 }
 
 // Filter creates a entries.Filter type for a query.
 func (q *Query) Filter() Filter {
 	filters := []Filter{}
+	for _, named := range q.Filters() {
+		filters = append(filters, named.Filter)
+	}
+
+	return FilterAnd(filters...)
+}
+
+// NamedFilter is a filter along with a description of what it allows, such as `path matches "food" OR "drinks"`.
+type NamedFilter struct {
+	Name   string
+	Filter Filter
+}
+
+// Filters returns each of the filters the query is made up of, in the order they're applied.
+func (q *Query) Filters() []NamedFilter {
+	filters := []NamedFilter{}
+
+	add := func(filter Filter, format string, a ...interface{}) {
+		filters = append(filters, NamedFilter{Name: fmt.Sprintf(format, a...), Filter: filter})
+	}
 
 	if q.From != (time.Time{}) {
-		filters = append(filters, FilterFrom(q.From))
+		add(FilterFrom(q.From), "date from %s", q.From.Format(time.RFC3339))
 	}
 
 	if q.Until != (time.Time{}) {
-		filters = append(filters, FilterUntil(q.Until))
+		add(FilterUntil(q.Until), "date until %s", q.Until.Format(time.RFC3339))
 	}
 
 	if q.MinLength != 0 {
-		filters = append(filters, FilterLength(q.MinLength))
+		add(FilterLength(q.MinLength), "min length %d", q.MinLength)
 	}
 
 	if q.MaxLength != 0 {
-		filters = append(filters, FilterNot(FilterLength(q.MaxLength)))
+		add(FilterNot(FilterLength(q.MaxLength)), "max length %d", q.MaxLength)
 	}
 
 	if len(q.Tags) != 0 {
-		filters = append(filters, FilterTags(q.Tags...))
+		add(FilterTags(q.Tags...), "tags include %s", quoteAll(q.Tags, " AND "))
 	}
 
 	if len(q.TagsExclude) != 0 {
-		filters = append(filters, FilterNot(FilterTags(q.TagsExclude...)))
+		add(FilterNot(FilterTags(q.TagsExclude...)), "tags don't include %s", quoteAll(q.TagsExclude, " AND "))
 	}
 
 	for _, c := range q.ContentsMatch {
-		filters = append(filters, FilterContentsMatch(c...))
+		add(FilterContentsMatch(c...), "contents contain %s", quoteAll(c, " OR "))
 	}
 	for _, c := range q.ContentsExact {
-		filters = append(filters, FilterContentsExact(c...))
+		add(FilterContentsExact(c...), "contents are %s", quoteAll(c, " OR "))
 	}
 	for _, c := range q.ContentsMatchExclude {
-		filters = append(filters, FilterNot(FilterContentsMatch(c...)))
+		add(FilterNot(FilterContentsMatch(c...)), "contents don't contain %s", quoteAll(c, " OR "))
 	}
 	for _, c := range q.ContentsExactExclude {
-		filters = append(filters, FilterNot(FilterContentsExact(c...)))
+		add(FilterNot(FilterContentsExact(c...)), "contents aren't %s", quoteAll(c, " OR "))
 	}
 
 	for _, c := range q.PathsMatch {
-		filters = append(filters, FilterPathsMatch(c...))
+		add(FilterPathsMatch(c...), "path contains %s", quoteAll(c, " OR "))
 	}
 	for _, c := range q.PathsExact {
-		filters = append(filters, FilterPathsExact(c...))
+		add(FilterPathsExact(c...), "path is %s", quoteAll(c, " OR "))
 	}
 	for _, c := range q.PathsMatchExclude {
-		filters = append(filters, FilterNot(FilterPathsMatch(c...)))
+		add(FilterNot(FilterPathsMatch(c...)), "path doesn't contain %s", quoteAll(c, " OR "))
 	}
 	for _, c := range q.PathsExactExclude {
-		filters = append(filters, FilterNot(FilterPathsExact(c...)))
+		add(FilterNot(FilterPathsExact(c...)), "path isn't %s", quoteAll(c, " OR "))
 	}
 
 	for _, c := range q.TitlesMatch {
-		filters = append(filters, FilterTitlesMatch(c...))
+		add(FilterTitlesMatch(c...), "title contains %s", quoteAll(c, " OR "))
 	}
 	for _, c := range q.TitlesExact {
-		filters = append(filters, FilterTitlesExact(c...))
+		add(FilterTitlesExact(c...), "title is %s", quoteAll(c, " OR "))
 	}
 	for _, c := range q.TitlesMatchExclude {
-		filters = append(filters, FilterNot(FilterTitlesMatch(c...)))
+		add(FilterNot(FilterTitlesMatch(c...)), "title doesn't contain %s", quoteAll(c, " OR "))
 	}
 	for _, c := range q.TitlesExactExclude {
-		filters = append(filters, FilterNot(FilterTitlesExact(c...)))
+		add(FilterNot(FilterTitlesExact(c...)), "title isn't %s", quoteAll(c, " OR "))
 	}
 
-	return FilterAnd(filters...)
+	addRegex := func(filter func(...*regexp.Regexp) Filter, patterns []string, field string) {
+		regexes, err := compileAll(patterns)
+		if err != nil {
+			add(func(*Entry) bool { return false }, "%s matches invalid regex (%s)", field, err)
+			return
+		}
+
+		add(filter(regexes...), "%s matches %s", field, quoteAll(patterns, " OR "))
+	}
+
+	for _, c := range q.ContentsRegex {
+		addRegex(FilterContentsRegex, c, "contents")
+	}
+	for _, c := range q.PathsRegex {
+		addRegex(FilterPathsRegex, c, "path")
+	}
+	for _, c := range q.TitlesRegex {
+		addRegex(FilterTitlesRegex, c, "title")
+	}
+
+	if q.Expression != "" {
+		filter, err := ParseExpression(q.Expression)
+		if err != nil {
+			add(func(*Entry) bool { return false }, "invalid query (%s)", err)
+		} else {
+			add(filter, "query %q", q.Expression)
+		}
+	}
+
+	return filters
+}
+
+// compileAll compiles each of the regular expressions.
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	regexes := []*regexp.Regexp{}
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", pattern, err)
+		}
+
+		regexes = append(regexes, re)
+	}
+
+	return regexes, nil
+}
+
+// quoteAll quotes each of the strings and joins them with the separator. Long lists are shortened, since they usually
+// come from stdin.
+func quoteAll(strs []string, sep string) string {
+	const max = 5
+
+	quoted := []string{}
+	for i, str := range strs {
+		if i == max {
+			quoted = append(quoted, fmt.Sprintf("%d more", len(strs)-max))
+			break
+		}
+
+		quoted = append(quoted, strconv.Quote(str))
+	}
+
+	if len(quoted) == 0 {
+		return "nothing"
+	}
+
+	return strings.Join(quoted, sep)
 }
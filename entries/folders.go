@@ -0,0 +1,131 @@
+package entries
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// FolderMetaFile is the name of the file which describes a folder that isn't an entry itself, like "school/a-level" in a
+// store which only has entries under "school/a-level/further-maths". For example:
+//
+//	title: A-Level
+//	description: Notes from sixth form.
+//	weight: 1
+const FolderMetaFile = "_meta.yaml"
+
+// Folder describes a folder in a store which isn't an entry itself, read from its FolderMetaFile, so that it can be
+// shown as something nicer than its name.
+type Folder struct {
+	// Path is the path to the folder, such as "school/a-level".
+	Path string `json:"path" yaml:"-"`
+
+	// Title is the title of the folder, such as "A-Level".
+	Title string `json:"title" yaml:"title"`
+
+	// Description is a longer description of what's in the folder.
+	Description string `json:"description,omitempty" yaml:"description"`
+
+	// Weight orders the folder amongst the others in the same folder. Lighter folders come first, and folders with the
+	// same weight are ordered by name.
+	Weight int `json:"weight,omitempty" yaml:"weight"`
+}
+
+// NewFolderFromFile reads the FolderMetaFile at originalPath for the folder at path, which is relative to the entries
+// folder like the path of an entry. It returns ErrFolderReadFailed or ErrFolderParseFailed if it can't be read.
+func NewFolderFromFile(originalPath, path string) (*Folder, error) {
+	dir := filepath.Dir(originalPath)
+
+	data, err := ioutil.ReadFile(originalPath)
+	if err != nil {
+		return nil, ErrFolderReadFailed{Path: dir, Err: err}
+	}
+
+	folder := &Folder{}
+
+	err = yaml.UnmarshalStrict(data, folder)
+	if err != nil {
+		return nil, ErrFolderParseFailed{Path: dir, Err: err}
+	}
+
+	folder.Path = filepath.ToSlash(path)
+
+	return folder, nil
+}
+
+// AddFolder adds the description of a folder to the collection, replacing any there already for the same path.
+func (collection *Collection) AddFolder(folder *Folder) {
+	collection.folderMap[folder.Path] = folder
+}
+
+// DeleteFolder removes the description of the folder at the path given from the collection, if there is one.
+func (collection *Collection) DeleteFolder(path string) {
+	delete(collection.folderMap, path)
+}
+
+// Folder returns the description of the folder at the path given, or nil if it doesn't have one.
+func (collection *Collection) Folder(path string) *Folder {
+	return collection.folderMap[path]
+}
+
+// Folders returns the description of every folder which has one, sorted by path.
+func (collection *Collection) Folders() []*Folder {
+	folders := []*Folder{}
+	for _, folder := range collection.folderMap {
+		folders = append(folders, folder)
+	}
+
+	sort.Slice(folders, func(i, j int) bool { return folders[i].Path < folders[j].Path })
+
+	return folders
+}
+
+// FolderTitle returns how the folder at the path given should be labelled: the title of the entry there if there is one,
+// then the title from its FolderMetaFile, and otherwise just its name.
+func (collection *Collection) FolderTitle(path string) string {
+	if entry := collection.pathMap[path]; entry != nil {
+		return entry.Title
+	}
+
+	if folder := collection.folderMap[path]; folder != nil && folder.Title != "" {
+		return folder.Title
+	}
+
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// FolderTitles returns the titles of every folder leading to the path given, including the path itself, such as
+// ["School", "A-Level", "Further Maths"] for "school/a-level/further-maths". See FolderTitle.
+func (collection *Collection) FolderTitles(path string) []string {
+	titles := []string{}
+
+	parts := strings.Split(path, "/")
+	for i := range parts {
+		titles = append(titles, collection.FolderTitle(strings.Join(parts[:i+1], "/")))
+	}
+
+	return titles
+}
+
+// SortFolders sorts the paths of folders which are in the same folder by the weight given in their FolderMetaFile, and
+// then by name.
+func (collection *Collection) SortFolders(paths []string) {
+	weight := func(path string) int {
+		if folder := collection.folderMap[path]; folder != nil {
+			return folder.Weight
+		}
+
+		return 0
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		if wi, wj := weight(paths[i]), weight(paths[j]); wi != wj {
+			return wi < wj
+		}
+
+		return paths[i] < paths[j]
+	})
+}
@@ -0,0 +1,97 @@
+package entries
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestDirGraphFolders(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "albatross-folders-test")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Entries get their path from the part after "entries", like in a store.
+	dir := filepath.Join(tmpDir, "entries")
+
+	write := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatalf("could not create directory: %s", err)
+		}
+
+		err = ioutil.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("could not write file: %s", err)
+		}
+	}
+
+	write("school/a-level/_meta.yaml", "title: A-Level\ndescription: Notes from sixth form.\nweight: 2\n")
+	write("school/a-level/further-maths/_meta.yaml", "title: Further Maths\n")
+	write("school/a-level/further-maths/matrices/entry.md", dummyEntryWithContent("Matrices."))
+	write("school/gcse/_meta.yaml", "title: [GCSE\n")
+	write("school/gcse/physics/entry.md", dummyEntryWithContent("Physics."))
+	write("school/university/_meta.yaml", "weight: 1\n")
+
+	collection, entryErrs, err := DirGraph(dir)
+	if err != nil {
+		t.Fatalf("not expecting error reading directory: %s", err)
+	}
+
+	if Len(t, entryErrs, 1, "expecting the invalid _meta.yaml to be reported") {
+		IsType(t, ErrFolderParseFailed{}, entryErrs[0])
+	}
+
+	folder := collection.Folder("school/a-level")
+	if NotNil(t, folder) {
+		Equal(t, &Folder{Path: "school/a-level", Title: "A-Level", Description: "Notes from sixth form.", Weight: 2}, folder)
+	}
+
+	Nil(t, collection.Folder("school/gcse"), "expecting invalid folders not to be added")
+	Len(t, collection.Folders(), 3)
+
+	Equal(t, "A-Level", collection.FolderTitle("school/a-level"))
+	Equal(t, "gcse", collection.FolderTitle("school/gcse"), "expecting folders without a title to use their name")
+	Equal(t, "university", collection.FolderTitle("school/university"))
+	Equal(t, []string{"school", "A-Level", "Further Maths", "Dummy Entry"}, collection.FolderTitles("school/a-level/further-maths/matrices"))
+
+	paths := []string{"school/gcse", "school/a-level", "school/university", "school/btec"}
+	collection.SortFolders(paths)
+	Equal(t, []string{"school/btec", "school/gcse", "school/university", "school/a-level"}, paths, "expecting folders to be sorted by weight and then by name")
+
+	filtered, err := collection.Filter(FilterPathsMatch("school/gcse"))
+	Nil(t, err)
+	Equal(t, collection.Folders(), filtered.Folders(), "expecting filtering to keep every folder")
+
+	copied := collection.Copy()
+	copied.DeleteFolder("school/a-level")
+	NotNil(t, collection.Folder("school/a-level"), "expecting changes to a copy not to change the original")
+}
+
+func TestNewFolderFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "albatross-entries-test")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, FolderMetaFile)
+
+	_, err = NewFolderFromFile(file, "missing")
+	IsType(t, ErrFolderReadFailed{}, err)
+
+	err = ioutil.WriteFile(file, []byte("title: Notes\ncolour: blue\n"), 0644)
+	if err != nil {
+		t.Fatalf("could not write file: %s", err)
+	}
+
+	_, err = NewFolderFromFile(file, "notes")
+	IsType(t, ErrFolderParseFailed{}, err, "expecting unknown keys to be rejected, so typos aren't ignored")
+}
@@ -9,7 +9,15 @@ import (
 // DirGraph returns an Collection built from a directory.
 // It will return an Collection, a list of errors that occured while parsing entries and finally an error that occured
 // when processing the directory or adding an entry.
+// Entries which are too large or look binary are skipped, see DefaultLimits, and reported in the list of errors. Folders
+// with a FolderMetaFile are added to the collection too, and ones which can't be parsed are also reported in the list of
+// errors.
 func DirGraph(path string) (graph *Collection, entryErrs []error, err error) {
+	return DirGraphWithLimits(path, DefaultLimits)
+}
+
+// DirGraphWithLimits is like DirGraph, but reads entries using the limits given rather than DefaultLimits.
+func DirGraphWithLimits(path string, limits Limits) (graph *Collection, entryErrs []error, err error) {
 	graph = NewCollection()
 
 	err = filepath.Walk(path, func(subpath string, info os.FileInfo, err error) error {
@@ -17,11 +25,27 @@ func DirGraph(path string) (graph *Collection, entryErrs []error, err error) {
 			return err
 		}
 
+		if info.Name() == FolderMetaFile {
+			rel, err := filepath.Rel(path, filepath.Dir(subpath))
+			if err != nil {
+				return err
+			}
+
+			folder, folderErr := NewFolderFromFile(subpath, rel)
+			if folderErr != nil {
+				entryErrs = append(entryErrs, folderErr)
+				return nil
+			}
+
+			graph.AddFolder(folder)
+			return nil
+		}
+
 		if !strings.Contains(info.Name(), "entry.md") {
 			return nil
 		}
 
-		entry, entryErr := NewEntryFromFile(subpath)
+		entry, entryErr := NewEntryFromFileWithLimits(subpath, limits)
 		if entryErr != nil {
 			entryErrs = append(entryErrs, entryErr)
 			return nil
@@ -0,0 +1,59 @@
+//go:build go1.18
+// +build go1.18
+
+package entries
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzParse(f *testing.F) {
+	for _, content := range trickyContents {
+		f.Add(content)
+	}
+
+	p, err := NewParser(testDateLayout, testBuiltinTagPrefix, testCustomTagPrefix)
+	if err != nil {
+		f.Fatalf("couldn't create new parser: %s", err)
+	}
+
+	f.Fuzz(func(t *testing.T, content string) {
+		entry, err := p.Parse("test/entry", content)
+		if err != nil {
+			return
+		}
+
+		checkEntryInvariants(t, content, entry)
+	})
+}
+
+func FuzzExtractFrontMatter(f *testing.F) {
+	for _, content := range trickyContents {
+		f.Add(content)
+	}
+
+	p, err := NewParser(testDateLayout, testBuiltinTagPrefix, testCustomTagPrefix)
+	if err != nil {
+		f.Fatalf("couldn't create new parser: %s", err)
+	}
+
+	f.Fuzz(func(t *testing.T, content string) {
+		frontMatter, strippedContent, err := p.extractFrontMatter("test/entry", content)
+		if err != nil {
+			return
+		}
+
+		if !strings.HasSuffix(content, strippedContent) {
+			t.Errorf("stripped content %q isn't a suffix of %q", strippedContent, content)
+		}
+
+		if !strings.Contains(content, frontMatter) {
+			t.Errorf("front matter %q isn't in %q", frontMatter, content)
+		}
+
+		if frontMatter == "" && strippedContent != strings.TrimLeft(content, "\n") && !strings.HasPrefix(content, "---\n") {
+			t.Errorf("content %q without front matter shouldn't be changed", content)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package entries
+
+import (
+	"sort"
+	"strings"
+)
+
+// Graph is the graph of links between the entries in a collection, mainly for visualising a store.
+type Graph struct {
+	// Nodes are the entries in the collection, sorted by path.
+	Nodes []GraphNode `json:"nodes"`
+
+	// Edges are the links between them, sorted by the path of the entry they're from and then the path they're to.
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is an entry in a Graph.
+type GraphNode struct {
+	// Path is the path to the entry, such as "school/physics/forces".
+	Path string `json:"path"`
+
+	// Title is the title of the entry.
+	Title string `json:"title"`
+
+	// Group is the top-level folder the entry is in, such as "school" for "school/physics/forces".
+	Group string `json:"group"`
+
+	// Inbound and Outbound are the number of entries which link to the entry and which it links to.
+	Inbound  int `json:"inbound"`
+	Outbound int `json:"outbound"`
+}
+
+// GraphEdge is a link from one entry to another in a Graph. However many times one entry links to another, there's
+// only one edge between them.
+type GraphEdge struct {
+	// From and To are the paths of the entries linking and being linked to.
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	// Weight is the number of links from one entry to the other.
+	Weight int `json:"weight"`
+}
+
+// Graph returns the graph of links between the entries in the collection. Only links to entries in the collection are
+// included, so links to entries which don't exist, which were filtered out or which are in other stores are left out,
+// as are links from entries to themselves.
+func (collection *Collection) Graph() *Graph {
+	graph := &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+
+	weights := map[[2]string]int{}
+	for _, entry := range collection.pathMap {
+		for _, link := range entry.OutboundLinks {
+			if link.Store != "" {
+				continue
+			}
+
+			to := collection.ResolveLink(link)
+			if to == nil || to == entry {
+				continue
+			}
+
+			weights[[2]string{entry.Path, to.Path}]++
+		}
+	}
+
+	inbound, outbound := map[string]int{}, map[string]int{}
+	for edge, weight := range weights {
+		graph.Edges = append(graph.Edges, GraphEdge{From: edge[0], To: edge[1], Weight: weight})
+		outbound[edge[0]]++
+		inbound[edge[1]]++
+	}
+
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+
+	for path, entry := range collection.pathMap {
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			Path:     path,
+			Title:    entry.Title,
+			Group:    strings.SplitN(path, "/", 2)[0],
+			Inbound:  inbound[path],
+			Outbound: outbound[path],
+		})
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].Path < graph.Nodes[j].Path })
+
+	return graph
+}
+
+// WithoutOrphans returns a copy of the graph without the entries which don't link to or from any other entry.
+func (graph *Graph) WithoutOrphans() *Graph {
+	linked := &Graph{Nodes: []GraphNode{}, Edges: graph.Edges}
+
+	for _, node := range graph.Nodes {
+		if node.Inbound != 0 || node.Outbound != 0 {
+			linked.Nodes = append(linked.Nodes, node)
+		}
+	}
+
+	return linked
+}
+
+// Groups returns the groups of the nodes in the graph, sorted alphabetically.
+func (graph *Graph) Groups() []string {
+	seen := map[string]bool{}
+	groups := []string{}
+
+	for _, node := range graph.Nodes {
+		if !seen[node.Group] {
+			seen[node.Group] = true
+			groups = append(groups, node.Group)
+		}
+	}
+
+	sort.Strings(groups)
+
+	return groups
+}
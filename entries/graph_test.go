@@ -0,0 +1,51 @@
+package entries
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestCollectionGraph(t *testing.T) {
+	collection := NewCollection()
+
+	pizza := dummyEntry("food/pizza", "Pizza", "")
+	iceCream := dummyEntry("food/ice-cream", "Ice Cream", "")
+	hunger := dummyEntry("moods/hunger", "Hunger", "")
+	lonely := dummyEntry("journal/2020-08-05", "Lonely", "")
+
+	pizza.OutboundLinks = []Link{
+		{Parent: pizza, Type: LinkTitleNoName, Title: "Hunger"},
+		{Parent: pizza, Type: LinkPathNoName, Path: "moods/hunger"},
+		{Parent: pizza, Type: LinkTitleNoName, Title: "Pizza"},
+		{Parent: pizza, Type: LinkTitleNoName, Title: "Missing"},
+		{Parent: pizza, Type: LinkPathNoName, Store: "work", Path: "moods/hunger"},
+	}
+	iceCream.OutboundLinks = []Link{{Parent: iceCream, Type: LinkPathNoName, Path: "food/pizza"}}
+
+	err := collection.AddMany(pizza, iceCream, hunger, lonely)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	graph := collection.Graph()
+
+	Equal(t, []GraphEdge{
+		{From: "food/ice-cream", To: "food/pizza", Weight: 1},
+		{From: "food/pizza", To: "moods/hunger", Weight: 2},
+	}, graph.Edges, "expecting self-links, missing links and links to other stores to be left out")
+
+	Equal(t, []GraphNode{
+		{Path: "food/ice-cream", Title: "Ice Cream", Group: "food", Outbound: 1},
+		{Path: "food/pizza", Title: "Pizza", Group: "food", Inbound: 1, Outbound: 1},
+		{Path: "journal/2020-08-05", Title: "Lonely", Group: "journal"},
+		{Path: "moods/hunger", Title: "Hunger", Group: "moods", Inbound: 1},
+	}, graph.Nodes)
+
+	Equal(t, []string{"food", "journal", "moods"}, graph.Groups())
+
+	linked := graph.WithoutOrphans()
+	Len(t, linked.Nodes, 3)
+	Equal(t, []string{"food", "moods"}, linked.Groups())
+	Len(t, graph.Nodes, 4, "expecting the original graph not to change")
+}
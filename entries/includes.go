@@ -0,0 +1,112 @@
+package entries
+
+import (
+	"strings"
+)
+
+// Includes returns the paths of the entries listed under "include" in the entry's front matter, which can either be a
+// single path or a list of paths:
+//
+//	---
+//	title: "Physics Syllabus"
+//	include:
+//	  - school/physics/topic1
+//	  - school/physics/topic2
+//	---
+func (e *Entry) Includes() []string {
+	switch raw := e.Metadata["include"].(type) {
+	case string:
+		if raw == "" {
+			return nil
+		}
+
+		return []string{raw}
+	case []interface{}:
+		includes := []string{}
+		for _, include := range raw {
+			if str, ok := include.(string); ok && str != "" {
+				includes = append(includes, str)
+			}
+		}
+
+		return includes
+	}
+
+	return nil
+}
+
+// includeSections returns false if the entry's "include-style" is "concatenate", meaning included entries should be
+// added without a heading.
+func (e *Entry) includeSections() bool {
+	style, _ := e.Metadata["include-style"].(string)
+	return style != "concatenate"
+}
+
+// Composite returns the contents of the entry followed by the contents of the entries it includes, as given by
+// Includes. Included entries can include other entries too. By default, each included entry is added as a section,
+// under a heading with its title. If the entry's "include-style" is "concatenate", they're added without one.
+//
+// The contents of each entry are given by the contents func, so that they can be changed first, such as to expand
+// shortcodes. If it's nil, the entry's Contents are used.
+//
+// It also returns the entries that were included, in the order they were added. If an included entry isn't in the
+// collection it returns an ErrIncludeNotFound, and if an entry includes itself it returns an ErrIncludeCycle.
+func (collection *Collection) Composite(entry *Entry, contents func(*Entry) (string, error)) (string, []*Entry, error) {
+	if contents == nil {
+		contents = func(e *Entry) (string, error) { return e.Contents, nil }
+	}
+
+	included := []*Entry{}
+	composite, err := collection.composite(entry, contents, map[string]bool{}, 1, &included)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return composite, included, nil
+}
+
+func (collection *Collection) composite(entry *Entry, contents func(*Entry) (string, error), including map[string]bool, depth int, included *[]*Entry) (string, error) {
+	including[entry.Path] = true
+	defer delete(including, entry.Path)
+
+	out, err := contents(entry)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.TrimRight(out, "\n"))
+
+	for _, path := range entry.Includes() {
+		if including[path] {
+			return "", ErrIncludeCycle{Path: path}
+		}
+
+		includedEntry := collection.pathMap[path]
+		if includedEntry == nil {
+			return "", ErrIncludeNotFound{Path: entry.Path, Include: path}
+		}
+
+		*included = append(*included, includedEntry)
+
+		section, err := collection.composite(includedEntry, contents, including, depth+1, included)
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString("\n\n")
+
+		if entry.includeSections() {
+			level := depth + 1
+			if level > 6 {
+				level = 6
+			}
+
+			sb.WriteString(strings.Repeat("#", level) + " " + includedEntry.Title + "\n\n")
+		}
+
+		sb.WriteString(section)
+	}
+
+	return sb.String(), nil
+}
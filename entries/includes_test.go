@@ -0,0 +1,41 @@
+package entries
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestComposite(t *testing.T) {
+	syllabus := dummyEntry("school/physics/syllabus", "Physics Syllabus", "This year covers:\n")
+	syllabus.Metadata = map[string]interface{}{"include": []interface{}{"school/physics/topic1", "school/physics/topic2"}}
+
+	topic1 := dummyEntry("school/physics/topic1", "Forces", "Things push things.")
+	topic1.Metadata = map[string]interface{}{"include": "school/physics/topic1/friction"}
+
+	friction := dummyEntry("school/physics/topic1/friction", "Friction", "Things rub things.")
+	topic2 := dummyEntry("school/physics/topic2", "Waves", "Things wave.")
+
+	collection := NewCollection()
+	err := collection.AddMany(syllabus, topic1, friction, topic2)
+	Nil(t, err, "adding entries, err should be nil")
+
+	composite, included, err := collection.Composite(syllabus, nil)
+	Nil(t, err, "getting composite, err should be nil")
+	Equal(t, "This year covers:\n\n## Forces\n\nThings push things.\n\n### Friction\n\nThings rub things.\n\n## Waves\n\nThings wave.", composite)
+	Equal(t, []*Entry{topic1, friction, topic2}, included)
+
+	syllabus.Metadata["include-style"] = "concatenate"
+	composite, _, err = collection.Composite(syllabus, func(e *Entry) (string, error) { return strings.ToUpper(e.Contents), nil })
+	Nil(t, err, "getting composite, err should be nil")
+	Equal(t, "THIS YEAR COVERS:\n\nTHINGS PUSH THINGS.\n\n### Friction\n\nTHINGS RUB THINGS.\n\nTHINGS WAVE.", composite)
+
+	friction.Metadata = map[string]interface{}{"include": "school/physics/syllabus"}
+	_, _, err = collection.Composite(syllabus, nil)
+	Equal(t, ErrIncludeCycle{Path: "school/physics/syllabus"}, err)
+
+	friction.Metadata = map[string]interface{}{"include": "school/physics/missing"}
+	_, _, err = collection.Composite(syllabus, nil)
+	Equal(t, ErrIncludeNotFound{Path: "school/physics/topic1/friction", Include: "school/physics/missing"}, err)
+}
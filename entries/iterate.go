@@ -0,0 +1,83 @@
+package entries
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrStopIteration can be returned by the function given to ForEach or ForEachSorted to stop visiting entries early.
+// ForEach then returns nil rather than the error.
+var ErrStopIteration = errors.New("stop iteration")
+
+// ForEach calls fn for every entry in the collection which the filter allows, in no particular order. A nil filter allows
+// every entry. Unlike Filter and List, it doesn't copy the entries anywhere, so it's the cheapest way of visiting
+// entries in a large store when they don't need to be kept.
+//
+// If fn returns an error, no more entries are visited and ForEach returns it, unless it's ErrStopIteration, in which
+// case ForEach returns nil. Entries mustn't be added to or removed from the collection during iteration.
+func (collection *Collection) ForEach(filter Filter, fn func(*Entry) error) error {
+	for _, entry := range collection.pathMap {
+		if filter != nil && !filter(entry) {
+			continue
+		}
+
+		err := fn(entry)
+		if err == ErrStopIteration {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ForEachSorted is like ForEach, but visits the entries in the order given by the sort type, such as SortPath. The
+// entries the filter allows have to be gathered to be sorted, but nothing else is copied.
+func (collection *Collection) ForEachSorted(filter Filter, sortType SortType, fn func(*Entry) error) error {
+	matched := []*Entry{}
+	for _, entry := range collection.pathMap {
+		if filter == nil || filter(entry) {
+			matched = append(matched, entry)
+		}
+	}
+
+	sortEntries(matched, sortType)
+
+	return visitEntries(matched, fn)
+}
+
+// ForEachFunc is like ForEachSorted, but visits the entries in the order given by a function which returns true if the
+// entry a should come before b, such as one returned by ByMetadata.
+func (collection *Collection) ForEachFunc(filter Filter, less func(a, b *Entry) bool, fn func(*Entry) error) error {
+	matched := []*Entry{}
+	for _, entry := range collection.pathMap {
+		if filter == nil || filter(entry) {
+			matched = append(matched, entry)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool { return less(matched[i], matched[j]) })
+
+	return visitEntries(matched, fn)
+}
+
+// ForEach calls fn for each entry in the list in order, stopping like Collection.ForEach. Unlike ranging over Slice, the
+// entries aren't copied first, so it's the cheapest way of going through a long list such as when exporting.
+func (es List) ForEach(fn func(*Entry) error) error {
+	return visitEntries(es.list, fn)
+}
+
+// visitEntries calls fn for each of the entries in order, stopping like ForEach.
+func visitEntries(entries []*Entry, fn func(*Entry) error) error {
+	for _, entry := range entries {
+		err := fn(entry)
+		if err == ErrStopIteration {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
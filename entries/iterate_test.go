@@ -0,0 +1,120 @@
+package entries
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestCollectionForEach(t *testing.T) {
+	collection := NewCollection()
+
+	pizza := dummyEntry("food/pizza", "Pizza", "Pizza is great.")
+	iceCream := dummyEntry("food/ice-cream", "Ice Cream", "Ice cream is amazing, better than pizza.")
+	hunger := dummyEntry("moods/hunger", "Hunger", "Hungry.")
+
+	err := collection.AddMany(pizza, iceCream, hunger)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	visited := []*Entry{}
+	err = collection.ForEach(FilterPathsMatch("food"), func(entry *Entry) error {
+		visited = append(visited, entry)
+		return nil
+	})
+	Nil(t, err)
+	ElementsMatch(t, []*Entry{pizza, iceCream}, visited, "expecting only the entries the filter allows")
+
+	count := 0
+	err = collection.ForEach(nil, func(entry *Entry) error {
+		count++
+		return ErrStopIteration
+	})
+	Nil(t, err, "expecting ErrStopIteration not to be returned")
+	Equal(t, 1, count, "expecting iteration to stop after the first entry")
+
+	failed := errors.New("failed")
+	err = collection.ForEach(nil, func(entry *Entry) error { return failed })
+	Equal(t, failed, err, "expecting other errors to be returned")
+
+	paths := []string{}
+	err = collection.ForEachSorted(nil, SortPath, func(entry *Entry) error {
+		paths = append(paths, entry.Path)
+		return nil
+	})
+	Nil(t, err)
+	Equal(t, []string{"food/ice-cream", "food/pizza", "moods/hunger"}, paths)
+
+	paths = []string{}
+	err = collection.ForEachSorted(nil, SortLength, func(entry *Entry) error {
+		paths = append(paths, entry.Path)
+		if len(paths) == 2 {
+			return ErrStopIteration
+		}
+
+		return nil
+	})
+	Nil(t, err)
+	Equal(t, []string{"moods/hunger", "food/pizza"}, paths, "expecting sorted iteration to stop early too")
+
+	paths = []string{}
+	err = collection.ForEachFunc(FilterPathsMatch("food"), func(a, b *Entry) bool { return a.Title > b.Title }, func(entry *Entry) error {
+		paths = append(paths, entry.Path)
+		return nil
+	})
+	Nil(t, err)
+	Equal(t, []string{"food/pizza", "food/ice-cream"}, paths)
+}
+
+func TestListForEach(t *testing.T) {
+	pizza := dummyEntry("food/pizza", "Pizza", "")
+	iceCream := dummyEntry("food/ice-cream", "Ice Cream", "")
+	hunger := dummyEntry("moods/hunger", "Hunger", "")
+
+	list := List{[]*Entry{pizza, iceCream, hunger}}
+	Equal(t, 3, list.Len())
+
+	visited := []*Entry{}
+	err := list.ForEach(func(entry *Entry) error {
+		visited = append(visited, entry)
+		if entry == iceCream {
+			return ErrStopIteration
+		}
+
+		return nil
+	})
+	Nil(t, err)
+	Equal(t, []*Entry{pizza, iceCream}, visited, "expecting entries in order until iteration is stopped")
+}
+
+func BenchmarkCollectionForEach(b *testing.B) {
+	collection := NewCollection()
+	for i := 0; i < 50000; i++ {
+		err := collection.Add(dummyEntry(fmt.Sprintf("bench/%d", i), fmt.Sprintf("Entry %d", i), "Contents."))
+		if err != nil {
+			b.Fatalf("not expecting error adding entry: %s", err)
+		}
+	}
+
+	b.Run("ForEach", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			count := 0
+			_ = collection.ForEach(nil, func(entry *Entry) error {
+				count++
+				return nil
+			})
+		}
+	})
+
+	b.Run("List", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			count := 0
+			for range collection.List().Slice() {
+				count++
+			}
+		}
+	})
+}
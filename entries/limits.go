@@ -0,0 +1,62 @@
+package entries
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits controls how large entries are handled when they're read, so that a stray large or binary file named
+// entry.md can't hang loading a store, along with any transforms applied to entries before they're parsed.
+type Limits struct {
+	// MaxSize is the size in bytes above which an entry.md file is skipped without being read. Zero means no limit.
+	MaxSize int64
+
+	// MaxContents is the length in bytes above which the contents of an entry are truncated, with TruncationMarker
+	// added to the end. Zero means no limit.
+	MaxContents int
+
+	// Transforms are applied in order to the contents of each entry.md file before it's parsed, see Transform.
+	Transforms []Transform
+}
+
+// DefaultLimits are the limits used when none are configured, such as by DirGraph and NewEntryFromFile. Contents aren't
+// truncated by default, since anything which writes the contents of an entry back would lose the rest of it.
+var DefaultLimits = Limits{
+	MaxSize: 16 << 20,
+}
+
+// TruncationMarker is added to the end of the contents of entries which have been truncated.
+const TruncationMarker = "\n\n[... truncated ...]\n"
+
+// binarySniffLength is how much of the start of a file is checked for binary content. It's the same amount git checks.
+const binarySniffLength = 8000
+
+// isBinary returns true if the data looks like the contents of a binary file rather than text, which is when there's a
+// NUL byte near the start.
+func isBinary(data []byte) bool {
+	if len(data) > binarySniffLength {
+		data = data[:binarySniffLength]
+	}
+
+	return bytes.IndexByte(data, 0) != -1
+}
+
+// truncateContents cuts the contents down to at most max bytes and adds TruncationMarker. Where possible it cuts at the
+// end of a line, otherwise it cuts at the start of a character so no UTF-8 sequences are split.
+func truncateContents(contents string, max int) string {
+	if len(contents) <= max {
+		return contents
+	}
+
+	end := max
+	for end > 0 && !utf8.RuneStart(contents[end]) {
+		end--
+	}
+
+	if newline := strings.LastIndexByte(contents[:end], '\n'); newline > max/2 {
+		end = newline
+	}
+
+	return contents[:end] + TruncationMarker
+}
@@ -0,0 +1,78 @@
+package entries
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestTruncateContents(t *testing.T) {
+	Equal(t, "short", truncateContents("short", 10), "contents within the limit shouldn't be changed")
+
+	Equal(t, "line one\nline two"+TruncationMarker, truncateContents("line one\nline two\nline three", 20), "should cut at the end of a line")
+	Equal(t, "abcdefgh"+TruncationMarker, truncateContents("abcdefghijkl", 8), "should cut at the limit without newlines")
+	Equal(t, "ab"+TruncationMarker, truncateContents("abéfg", 3), "shouldn't split a character")
+}
+
+func TestIsBinary(t *testing.T) {
+	False(t, isBinary([]byte("Some text. ✓")))
+	True(t, isBinary([]byte("PNG\x00\x01\x02")))
+	False(t, isBinary([]byte(strings.Repeat("a", binarySniffLength)+"\x00")), "only the start of the file should be checked")
+}
+
+func TestNewEntryFromFileLimits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "albatross-entries-test")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name, "entry.md")
+
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatalf("could not create entry directory: %s", err)
+		}
+
+		err = ioutil.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("could not write entry: %s", err)
+		}
+
+		return path
+	}
+
+	limits := Limits{MaxSize: 1000, MaxContents: 100}
+
+	_, err = NewEntryFromFileWithLimits(write("large", dummyEntryWithContent(strings.Repeat("a", 2000))), limits)
+	IsType(t, ErrEntryTooLarge{}, err, "entries larger than the maximum size should be skipped")
+
+	_, err = NewEntryFromFileWithLimits(write("binary", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), limits)
+	IsType(t, ErrEntryBinary{}, err, "binary entries should be skipped")
+
+	entry, err := NewEntryFromFileWithLimits(write("long", dummyEntryWithContent(strings.Repeat("[[Link]] ", 50))), limits)
+	if NoError(t, err, "long entries should still be read") {
+		True(t, entry.Truncated, "long entries should be marked as truncated")
+		True(t, strings.HasSuffix(entry.Contents, TruncationMarker), "truncated contents should end with the marker")
+		LessOrEqual(t, len(entry.Contents), 100+len(TruncationMarker))
+		Len(t, entry.OutboundLinks, 11, "only links before the truncation should be found")
+	}
+
+	entry, err = NewEntryFromFileWithLimits(write("short", dummyEntryWithContent("Short.")), limits)
+	if NoError(t, err, "short entries should be read") {
+		False(t, entry.Truncated)
+	}
+
+	huge := strings.Repeat("A very long line.\n", 2<<20/18)
+
+	entry, err = NewEntryFromFile(write("huge", dummyEntryWithContent(huge)))
+	if NoError(t, err, "entries within the default size limit should be read") {
+		False(t, entry.Truncated, "contents shouldn't be truncated by default")
+		True(t, strings.HasSuffix(entry.Contents, huge))
+	}
+}
@@ -1,10 +1,17 @@
 package entries
 
+import "strings"
+
 // LinkType represents a type of link. This could be:
 // - A link by title (LinkTitleNoName), e.g. "[[Pizza]]"
 // - A link by title with a name (LinkTitleWithName), e.g. "[[Pizza](Alternate name)]"
 // - A link by path (LinkPathNoName), e.g. "{{food/pizza}}"
 // - A link by path with a name (LinkPathWithName), e.g. "{{food/pizza}(Altername name)"
+//
+// Path links can also point to an entry in another store by starting with the store's name, e.g. "{{work:notes/project}}".
+// A path link without a name which starts with "!", e.g. "{{!food/pizza}}" or "{{!food/pizza#Toppings}}", is an embed:
+// it's still a link, but when exporting the contents of the entry, or one of its sections, are put in its place. See
+// Collection.Transclude.
 type LinkType int
 
 const (
@@ -29,6 +36,10 @@ type Link struct {
 	// Path is the path to the entry being linked to. This is blank if it's a title link.
 	Path string `json:"path"`
 
+	// Store is the name of the store the entry being linked to is in, for links to other stores like
+	// "{{work:notes/project}}". It's blank for links within the same store. See Registry.
+	Store string `json:"store,omitempty"`
+
 	// Title is the title of the entry being linked to. This is blank if it's a path link.
 	Title string `json:"title"`
 
@@ -38,7 +49,54 @@ type Link struct {
 	// Type is the type of link.
 	Type LinkType `json:"type"`
 
+	// Embed is true if the link is an embed, like "{{!food/pizza}}", meaning the entry being linked to should be shown in
+	// its place.
+	Embed bool `json:"embed,omitempty"`
+
+	// Section is the heading of the section to embed, like "Toppings" in "{{!food/pizza#Toppings}}". It's blank if the
+	// whole entry should be embedded.
+	Section string `json:"section,omitempty"`
+
 	// Loc is the location of the link in the entry text, represented by a two-element slice of the start and and positions.
 	// The link text itself is at strippedContents[Loc[0]:Loc[1]]
 	Loc []int `json:"loc"`
 }
+
+// splitStore splits the target of a path link into the name of the store it's in and the path, such as "work" and
+// "notes/project" for "work:notes/project". The store is blank if the target doesn't start with the name of a store.
+// Store names are made up of letters, digits, "-", "_" and ".".
+func splitStore(target string) (store, path string) {
+	i := strings.IndexByte(target, ':')
+	if i <= 0 || i == len(target)-1 {
+		return "", target
+	}
+
+	for j := 0; j < i; j++ {
+		c := target[j]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return "", target
+		}
+	}
+
+	return target[:i], target[i+1:]
+}
+
+// splitEmbed checks whether the target of a path link is an embed, like "!food/pizza#Toppings", returning the section
+// given after the "#", if any, and the target without the "!" and section. If it isn't an embed, the target is returned
+// as it is.
+func splitEmbed(target string) (embed bool, section, rest string) {
+	if !strings.HasPrefix(target, "!") || len(target) == 1 {
+		return false, "", target
+	}
+
+	rest = target[1:]
+	if i := strings.IndexByte(rest, '#'); i != -1 {
+		rest, section = rest[:i], strings.TrimSpace(rest[i+1:])
+	}
+
+	if rest == "" {
+		return false, "", target
+	}
+
+	return true, section, rest
+}
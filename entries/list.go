@@ -1,7 +1,9 @@
 package entries
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 	"unicode"
 )
 
@@ -29,6 +31,27 @@ func (es List) FromOffset(offset, n int) (List, error) {
 	return List{es.list[offset : offset+n]}, nil
 }
 
+// Page returns n entries from a given offset, for paging through a list. Unlike FromOffset, an offset past the end of
+// the list gives an empty list rather than an error, and if n is zero or less every entry from the offset is returned.
+// The list should be sorted first in a way which doesn't leave any ties, see DeterministicSort, or else the same entry
+// can appear on more than one page.
+func (es List) Page(offset, n int) List {
+	if offset < 0 {
+		offset = 0
+	}
+
+	if offset >= len(es.list) {
+		return List{[]*Entry{}}
+	}
+
+	if n <= 0 {
+		n = len(es.list) - offset
+	}
+
+	page, _ := es.FromOffset(offset, n)
+	return page
+}
+
 // First returns the first N entries in the list.
 // If there's not N entries, it will return as many as possible.
 func (es List) First(n int) List {
@@ -51,6 +74,11 @@ func (es List) Last(n int) List {
 	return newList
 }
 
+// Len returns the number of entries in the list.
+func (es List) Len() int {
+	return len(es.list)
+}
+
 // Slice returns the entries as a slice of *Entry.
 func (es List) Slice() []*Entry {
 	return copyEntrySlice(es.list)
@@ -67,11 +95,20 @@ func (es List) Reverse() List {
 	return List{newList}
 }
 
-// Sort sorts an List.
+// Sort sorts an List. Sorting is stable, so entries which are equal keep the order they were in. This means sorts can be
+// chained to break ties, so list.Sort(SortDate).Sort(SortWeight) sorts by weight and then by date.
 func (es List) Sort(sortType SortType) List {
-	var sortable sort.Interface
 	var entries = copyEntrySlice(es.list)
 
+	sortEntries(entries, sortType)
+
+	return List{list: entries}
+}
+
+// sortEntries sorts a slice of entries in place, like List.Sort.
+func sortEntries(entries []*Entry, sortType SortType) {
+	var sortable sort.Interface
+
 	switch sortType {
 	case SortAlpha:
 		sortable = SortableByAlpha(entries)
@@ -79,11 +116,22 @@ func (es List) Sort(sortType SortType) List {
 		sortable = SortableByDate(entries)
 	case SortPath:
 		sortable = SortableByPathAlpha(entries)
-	}
+	case SortWeight:
+		sortable = SortableByWeight(entries)
+	case SortLength:
+		// Counting words is slow enough that it's worth only doing it once for each entry.
+		words := make(map[*Entry]int, len(entries))
+		for _, entry := range entries {
+			words[entry] = entry.WordCount()
+		}
 
-	sort.Sort(sortable)
+		sort.SliceStable(entries, func(i, j int) bool { return words[entries[i]] < words[entries[j]] })
+		return
+	case SortModTime:
+		sortable = SortableByModTime(entries)
+	}
 
-	return List{list: entries}
+	sort.Stable(sortable)
 }
 
 // SortType is the method used to sort an List.
@@ -98,8 +146,162 @@ const (
 
 	// SortPath uses alphabetical sorting for paths.
 	SortPath
+
+	// SortWeight sorts entries by the "weight" key in their front matter, lightest first. Entries without a weight come
+	// after all the entries with one.
+	SortWeight
+
+	// SortLength sorts entries by the number of words in their contents, shortest first.
+	SortLength
+
+	// SortModTime sorts entries by when their entry.md file was last modified, least recently modified first.
+	SortModTime
 )
 
+// sortTypes maps the names of sort types used by SortBy to the types.
+var sortTypes = map[string]SortType{
+	"alpha":  SortAlpha,
+	"date":   SortDate,
+	"path":   SortPath,
+	"weight": SortWeight,
+	"length": SortLength,
+	"mod":    SortModTime,
+}
+
+// sortMetadataPrefix is the prefix of names given to SortBy which sort by a key in the front matter, like "meta:author".
+const sortMetadataPrefix = "meta:"
+
+// SortBy sorts the list using sort types given by name, separated by commas, such as "weight,date". The first is used
+// to sort the list and the rest are used in order to break ties. The names are "alpha", "date", "path", "weight",
+// "length" and "mod", or "meta:" followed by a key in the front matter to sort by, see ByMetadata. An empty string
+// leaves the list as it is.
+func (es List) SortBy(names string) (List, error) {
+	if names == "" {
+		return es, nil
+	}
+
+	split := strings.Split(names, ",")
+	for i := len(split) - 1; i >= 0; i-- {
+		name := strings.TrimSpace(split[i])
+
+		if strings.HasPrefix(name, sortMetadataPrefix) {
+			key := strings.TrimPrefix(name, sortMetadataPrefix)
+			if key == "" {
+				return List{}, fmt.Errorf("expected a key to sort by after %q", sortMetadataPrefix)
+			}
+
+			es = es.SortFunc(ByMetadata(key))
+			continue
+		}
+
+		sortType, ok := sortTypes[name]
+		if !ok {
+			return List{}, fmt.Errorf("unknown sort %q, expected 'alpha', 'date', 'path', 'weight', 'length', 'mod' or 'meta:<key>'", split[i])
+		}
+
+		es = es.Sort(sortType)
+	}
+
+	return es, nil
+}
+
+// DeterministicSort returns sort names for SortBy which give the same order every time, by adding "path" to break any
+// ties that are left. Paths are unique, so no two entries are ever equal. If names is empty, entries are sorted by path.
+func DeterministicSort(names string) string {
+	if names == "" {
+		return "path"
+	}
+
+	split := strings.Split(names, ",")
+	if strings.TrimSpace(split[len(split)-1]) == "path" {
+		return names
+	}
+
+	return names + ",path"
+}
+
+// SortFunc sorts the list using a function which returns true if the entry a should come before b. Like Sort, sorting
+// is stable, so sorts can be chained to break ties.
+func (es List) SortFunc(less func(a, b *Entry) bool) List {
+	entries := copyEntrySlice(es.list)
+	sort.SliceStable(entries, func(i, j int) bool { return less(entries[i], entries[j]) })
+
+	return List{list: entries}
+}
+
+// ByMetadata returns a function for SortFunc which sorts entries by the value of a key in their front matter. Numbers
+// are sorted numerically and come before everything else, which is sorted alphabetically, so dates like "2021-03-14"
+// are in order. Entries without the key come after all the entries with it.
+func ByMetadata(key string) func(a, b *Entry) bool {
+	return func(a, b *Entry) bool {
+		aValue, aOk := a.Metadata[key]
+		bValue, bOk := b.Metadata[key]
+
+		aOk, bOk = aOk && aValue != nil, bOk && bValue != nil
+		if !aOk || !bOk {
+			return aOk && !bOk
+		}
+
+		aNumber, aIsNumber := metadataNumber(aValue)
+		bNumber, bIsNumber := metadataNumber(bValue)
+
+		switch {
+		case aIsNumber && bIsNumber:
+			return aNumber < bNumber
+		case aIsNumber != bIsNumber:
+			return aIsNumber
+		}
+
+		aString, bString := fmt.Sprint(aValue), fmt.Sprint(bValue)
+		if aLower, bLower := strings.ToLower(aString), strings.ToLower(bString); aLower != bLower {
+			return aLower < bLower
+		}
+
+		return aString < bString
+	}
+}
+
+// metadataNumber returns the value of a key in the front matter as a number, if it is one.
+func metadataNumber(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case int:
+		return float64(value), true
+	case float64:
+		return value, true
+	default:
+		return 0, false
+	}
+}
+
+// Weighted returns true if any of the entries in the list have a weight, see Entry.Weight.
+func (es List) Weighted() bool {
+	for _, entry := range es.list {
+		if _, ok := entry.Weight(); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Weight returns the number given by the "weight" key in the front matter, which is used to put entries in a specific
+// order rather than alphabetically or by date. It returns false if the entry doesn't have a weight.
+func (e *Entry) Weight() (float64, bool) {
+	switch weight := e.Metadata["weight"].(type) {
+	case int:
+		return float64(weight), true
+	case float64:
+		return weight, true
+	default:
+		return 0, false
+	}
+}
+
+// WordCount returns the number of words in the entry's contents.
+func (e *Entry) WordCount() int {
+	return len(strings.Fields(e.Contents))
+}
+
 // SortableByAlpha implements sort.Interface for []*Entry based on the alphabetical ordering of titles.
 // Courtesy of this StackOverflow answer: https://stackoverflow.com/questions/35076109/in-golang-how-can-i-sort-a-list-of-strings-alphabetically-without-completely-ig
 type SortableByAlpha []*Entry
@@ -167,7 +369,8 @@ func (es SortableByPathAlpha) Less(i, j int) bool {
 		}
 	}
 
-	return false
+	// One path starts with the other, like "food" and "food/pizza", so the shorter one comes first.
+	return len(iRunes) < len(jRunes)
 }
 
 // SortableByDate implements the sort.Interface for []*Entry based on entry dates.
@@ -176,3 +379,26 @@ type SortableByDate []*Entry
 func (es SortableByDate) Len() int           { return len(es) }
 func (es SortableByDate) Swap(i, j int)      { es[i], es[j] = es[j], es[i] }
 func (es SortableByDate) Less(i, j int) bool { return es[i].Date.Before(es[j].Date) }
+
+// SortableByWeight implements the sort.Interface for []*Entry based on entry weights, with entries without a weight last.
+type SortableByWeight []*Entry
+
+func (es SortableByWeight) Len() int      { return len(es) }
+func (es SortableByWeight) Swap(i, j int) { es[i], es[j] = es[j], es[i] }
+func (es SortableByWeight) Less(i, j int) bool {
+	iWeight, iOk := es[i].Weight()
+	jWeight, jOk := es[j].Weight()
+
+	if iOk != jOk {
+		return iOk
+	}
+
+	return iWeight < jWeight
+}
+
+// SortableByModTime implements the sort.Interface for []*Entry based on entry modification times.
+type SortableByModTime []*Entry
+
+func (es SortableByModTime) Len() int           { return len(es) }
+func (es SortableByModTime) Swap(i, j int)      { es[i], es[j] = es[j], es[i] }
+func (es SortableByModTime) Less(i, j int) bool { return es[i].ModTime.Before(es[j].ModTime) }
@@ -85,6 +85,49 @@ func TestListFirstLast(t *testing.T) {
 	Equal(t, entry5, newList.Slice()[1], "seconed entry in entry list should be entry5")
 }
 
+func TestListPage(t *testing.T) {
+	entry1 := dummyEntry("food/pizza", "Pizza", "Pizza is great.")
+	entry2 := dummyEntry("food/ice-cream", "Ice Cream", "Ice cream is amazing.")
+	entry3 := dummyEntry("food/beans", "BEANS!", "BEANS!!!")
+	entry4 := dummyEntry("animals/tiger", "Tigers", "Love me some tigers.")
+	entry5 := dummyEntry("animals/whale", "Whales", "Whales. Oh, Whales!")
+
+	list := List{[]*Entry{entry1, entry2, entry3, entry4, entry5}}
+
+	ts := []struct {
+		offset, n int
+		expected  []*Entry
+	}{
+		{0, 2, []*Entry{entry1, entry2}},
+		{2, 2, []*Entry{entry3, entry4}},
+		{4, 2, []*Entry{entry5}},
+		{5, 2, []*Entry{}},
+		{12, 2, []*Entry{}},
+		{3, 0, []*Entry{entry4, entry5}},
+		{-1, 1, []*Entry{entry1}},
+	}
+
+	for _, tc := range ts {
+		Equal(t, tc.expected, list.Page(tc.offset, tc.n).Slice(), "page with offset=%d, n=%d", tc.offset, tc.n)
+	}
+}
+
+func TestDeterministicSort(t *testing.T) {
+	Equal(t, "path", DeterministicSort(""))
+	Equal(t, "date,path", DeterministicSort("date"))
+	Equal(t, "weight,alpha,path", DeterministicSort("weight,alpha"))
+	Equal(t, "alpha, path", DeterministicSort("alpha, path"))
+
+	// Entries with the same title are still sorted the same way every time.
+	entry1 := dummyEntry("b", "Same", "")
+	entry2 := dummyEntry("a", "Same", "")
+	entry3 := dummyEntry("c", "Different", "")
+
+	sorted, err := List{[]*Entry{entry1, entry2, entry3}}.SortBy(DeterministicSort("alpha"))
+	Nil(t, err)
+	Equal(t, []*Entry{entry3, entry2, entry1}, sorted.Slice())
+}
+
 func TestListSortAlpha(t *testing.T) {
 	entry1 := dummyEntry("food/pizza", "Pizza", "Pizza is great.")               // 3
 	entry2 := dummyEntry("food/ice-cream", "Ice Cream", "Ice cream is amazing.") // 2
@@ -104,6 +147,19 @@ func TestListSortAlpha(t *testing.T) {
 	Equal(t, entry5, sortedList.Slice()[5], "alphabetical sort should have entry5 6th")
 }
 
+func TestListSortPath(t *testing.T) {
+	entry1 := dummyEntry("food/pizza", "Pizza", "Pizza is great.")
+	entry2 := dummyEntry("food", "Food", "Food is great.")
+	entry3 := dummyEntry("food/pizza/margherita", "Margherita", "Margherita is great.")
+	entry4 := dummyEntry("animals", "Animals", "Animals are great.")
+
+	list := List{[]*Entry{entry1, entry2, entry3, entry4}}
+	sortedList := list.Sort(SortPath)
+
+	Equal(t, []*Entry{entry4, entry2, entry1, entry3}, sortedList.Slice(), "path sort should put paths before paths inside them")
+	Equal(t, sortedList.Slice(), List{[]*Entry{entry3, entry1, entry4, entry2}}.Sort(SortPath).Slice(), "path sort shouldn't depend on the order")
+}
+
 func TestListSortDate(t *testing.T) {
 	entry1 := &Entry{Path: "food/pizza", Date: time.Date(2017, time.January, 0, 0, 0, 0, 0, &time.Location{})}        // 3
 	entry2 := &Entry{Path: "food/ice-cream", Date: time.Date(2016, time.January, 0, 0, 0, 0, 0, &time.Location{})}    // 2
@@ -123,6 +179,91 @@ func TestListSortDate(t *testing.T) {
 	Equal(t, entry4, sortedList.Slice()[5], "alphabetical sort should have entry5 6th")
 }
 
+func TestListSortWeight(t *testing.T) {
+	weighted := func(path, title string, weight interface{}) *Entry {
+		entry := dummyEntry(path, title, "")
+		entry.Metadata = map[string]interface{}{"weight": weight}
+		return entry
+	}
+
+	entry1 := weighted("syllabus/waves", "Waves", 2)
+	entry2 := weighted("syllabus/forces", "Forces", 1)
+	entry3 := weighted("syllabus/energy", "Energy", 1.5)
+	entry4 := weighted("syllabus/atoms", "Atoms", 2)
+	entry5 := dummyEntry("syllabus/appendix", "Appendix", "")
+	entry6 := weighted("syllabus/notes", "Notes", "not a number")
+
+	list := List{[]*Entry{entry1, entry2, entry3, entry4, entry5, entry6}}
+	True(t, list.Weighted(), "list should be weighted")
+	False(t, List{[]*Entry{entry5, entry6}}.Weighted(), "list without numeric weights shouldn't be weighted")
+
+	sortedList := list.Sort(SortWeight)
+	Equal(t, []*Entry{entry2, entry3, entry1, entry4, entry5, entry6}, sortedList.Slice(), "weight sort should be stable and put entries without weights last")
+
+	sortedList, err := list.SortBy("weight,alpha")
+	NoError(t, err, "not expecting error sorting by name")
+	Equal(t, []*Entry{entry2, entry3, entry4, entry1, entry5, entry6}, sortedList.Slice(), "ties should be broken alphabetically")
+
+	_, err = list.SortBy("weight,size")
+	Error(t, err, "expecting error for unknown sort")
+}
+
+func TestListSortLengthAndModTime(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	entry1 := dummyEntry("notes/long", "Long", "One two three four five.")
+	entry1.ModTime = now
+
+	entry2 := dummyEntry("notes/short", "Short", "One.")
+	entry2.ModTime = now.Add(time.Hour)
+
+	entry3 := dummyEntry("notes/medium", "Medium", "One two\nthree.")
+	entry3.ModTime = now.Add(-time.Hour)
+
+	list := List{[]*Entry{entry1, entry2, entry3}}
+
+	Equal(t, 5, entry1.WordCount())
+	Equal(t, []*Entry{entry2, entry3, entry1}, list.Sort(SortLength).Slice(), "length sort should put the shortest first")
+	Equal(t, []*Entry{entry3, entry1, entry2}, list.Sort(SortModTime).Slice(), "mod sort should put the least recently modified first")
+
+	sortedList, err := list.SortBy("mod")
+	NoError(t, err)
+	Equal(t, []*Entry{entry3, entry1, entry2}, sortedList.Slice())
+
+	sortedList, err = list.SortBy("length")
+	NoError(t, err)
+	Equal(t, []*Entry{entry2, entry3, entry1}, sortedList.Slice())
+}
+
+func TestListSortMetadata(t *testing.T) {
+	withMetadata := func(path string, metadata map[string]interface{}) *Entry {
+		entry := dummyEntry(path, path, "")
+		entry.Metadata = metadata
+		return entry
+	}
+
+	entry1 := withMetadata("books/dune", map[string]interface{}{"author": "Frank Herbert", "rating": 5})
+	entry2 := withMetadata("books/emma", map[string]interface{}{"author": "jane Austen", "rating": 3.5})
+	entry3 := withMetadata("books/ulysses", map[string]interface{}{"author": "James Joyce", "rating": "unrated"})
+	entry4 := withMetadata("books/unknown", map[string]interface{}{"rating": nil})
+	entry5 := withMetadata("books/beowulf", map[string]interface{}{"author": "Anonymous", "rating": 4})
+
+	list := List{[]*Entry{entry1, entry2, entry3, entry4, entry5}}
+
+	sortedList, err := list.SortBy("meta:author")
+	NoError(t, err)
+	Equal(t, []*Entry{entry5, entry1, entry3, entry2, entry4}, sortedList.Slice(), "strings should be sorted without case and entries without the key should come last")
+
+	sortedList, err = list.SortBy("meta:rating")
+	NoError(t, err)
+	Equal(t, []*Entry{entry2, entry5, entry1, entry3, entry4}, sortedList.Slice(), "numbers should be sorted numerically and come before other values")
+
+	Equal(t, sortedList.Slice(), list.SortFunc(ByMetadata("rating")).Slice())
+
+	_, err = list.SortBy("meta:")
+	Error(t, err, "expecting error for a missing key")
+}
+
 func TestListReverse(t *testing.T) {
 	entry1 := dummyEntry("food/pizza", "Pizza", "Pizza is great.")
 	entry2 := dummyEntry("food/ice-cream", "Ice Cream", "Ice cream is amazing.")
@@ -0,0 +1,162 @@
+package entries
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// snippetContext is the most bytes of text either side of a match which are kept in its snippet.
+const snippetContext = 40
+
+// Match is a place in an entry which a query's title or contents filters matched, used to show why the entry matched.
+type Match struct {
+	// Field is the part of the entry which matched, "title" or "contents".
+	Field string `json:"field"`
+
+	// Start and End are the byte offsets of the match within the field.
+	Start int `json:"start"`
+	End   int `json:"end"`
+
+	// Line is the line of the field the match starts on, counting from 1.
+	Line int `json:"line"`
+
+	// Snippet is the line the match is on, shortened to the text around it. SnippetStart and SnippetEnd are the byte
+	// offsets of the match within the snippet, so it can be highlighted. Matches which carry on over more than one line
+	// are cut off at the end of the first.
+	Snippet      string `json:"snippet"`
+	SnippetStart int    `json:"snippet_start"`
+	SnippetEnd   int    `json:"snippet_end"`
+}
+
+// Matches returns the places in the entry matched by the substrings and regular expressions in the query's TitlesMatch,
+// TitlesRegex, ContentsMatch and ContentsRegex, in order of field and then position. Other parts of the query, such as
+// tags and dates, don't match any particular place, so aren't included. Invalid regular expressions are skipped.
+func (q *Query) Matches(entry *Entry) []Match {
+	matches := []Match{}
+
+	matches = append(matches, findMatches("title", entry.Title, q.TitlesMatch, q.TitlesRegex)...)
+	matches = append(matches, findMatches("contents", entry.Contents, q.ContentsMatch, q.ContentsRegex)...)
+
+	return matches
+}
+
+// findMatches returns the matches of the substrings and regular expressions in the text of a field, sorted by position
+// and without duplicates.
+func findMatches(field, text string, substrings, patterns [][]string) []Match {
+	locs := [][]int{}
+
+	for _, group := range substrings {
+		for _, substring := range group {
+			if substring == "" {
+				continue
+			}
+
+			for offset := 0; offset <= len(text); {
+				i := strings.Index(text[offset:], substring)
+				if i == -1 {
+					break
+				}
+
+				locs = append(locs, []int{offset + i, offset + i + len(substring)})
+				offset += i + len(substring)
+			}
+		}
+	}
+
+	for _, group := range patterns {
+		for _, pattern := range group {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				continue
+			}
+
+			for _, loc := range re.FindAllStringIndex(text, -1) {
+				// Empty matches, like those of "a*", don't show anything useful.
+				if loc[0] != loc[1] {
+					locs = append(locs, loc)
+				}
+			}
+		}
+	}
+
+	sort.Slice(locs, func(i, j int) bool {
+		if locs[i][0] != locs[j][0] {
+			return locs[i][0] < locs[j][0]
+		}
+
+		return locs[i][1] < locs[j][1]
+	})
+
+	matches := []Match{}
+
+	for i, loc := range locs {
+		if i > 0 && loc[0] == locs[i-1][0] && loc[1] == locs[i-1][1] {
+			continue
+		}
+
+		matches = append(matches, newMatch(field, text, loc[0], loc[1]))
+	}
+
+	return matches
+}
+
+// newMatch returns the match between start and end in the text of a field, with its line and snippet filled in.
+func newMatch(field, text string, start, end int) Match {
+	lineStart := strings.LastIndexByte(text[:start], '\n') + 1
+
+	lineEnd := strings.IndexByte(text[start:], '\n')
+	if lineEnd == -1 {
+		lineEnd = len(text)
+	} else {
+		lineEnd += start
+	}
+
+	matchEnd := end
+	if matchEnd > lineEnd {
+		matchEnd = lineEnd
+	}
+
+	// Snippets which don't reach the start or end of the line are cut at a space if there is one, so that words aren't
+	// split, and otherwise at the start of a character.
+	snippetStart := start - snippetContext
+	if snippetStart <= lineStart {
+		snippetStart = lineStart
+	} else if space := strings.IndexByte(text[snippetStart:start], ' '); space != -1 {
+		snippetStart += space + 1
+	} else {
+		for snippetStart < start && !utf8.RuneStart(text[snippetStart]) {
+			snippetStart++
+		}
+	}
+
+	snippetEnd := matchEnd + snippetContext
+	if snippetEnd >= lineEnd {
+		snippetEnd = lineEnd
+	} else if space := strings.LastIndexByte(text[matchEnd:snippetEnd], ' '); space != -1 {
+		snippetEnd = matchEnd + space
+	} else {
+		for snippetEnd > matchEnd && !utf8.RuneStart(text[snippetEnd]) {
+			snippetEnd--
+		}
+	}
+
+	prefix, suffix := "", ""
+	if snippetStart > lineStart {
+		prefix = "..."
+	}
+	if snippetEnd < lineEnd {
+		suffix = "..."
+	}
+
+	return Match{
+		Field:        field,
+		Start:        start,
+		End:          end,
+		Line:         strings.Count(text[:start], "\n") + 1,
+		Snippet:      prefix + text[snippetStart:snippetEnd] + suffix,
+		SnippetStart: len(prefix) + start - snippetStart,
+		SnippetEnd:   len(prefix) + matchEnd - snippetStart,
+	}
+}
@@ -0,0 +1,62 @@
+package entries
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestQueryMatches(t *testing.T) {
+	entry := dummyEntry("food/pizza", "Pizza Pizza", "Pizza is great.\nI could eat pizza every day, especially with pineapple.")
+
+	query := Query{
+		TitlesMatch:   [][]string{{"Pizza"}},
+		ContentsMatch: [][]string{{"pizza", "great"}},
+		ContentsRegex: [][]string{{`pine\w+`, `great`, `(`}},
+		Tags:          []string{"@?food"},
+	}
+
+	matches := query.Matches(entry)
+
+	Equal(t, []Match{
+		{Field: "title", Start: 0, End: 5, Line: 1, Snippet: "Pizza Pizza", SnippetStart: 0, SnippetEnd: 5},
+		{Field: "title", Start: 6, End: 11, Line: 1, Snippet: "Pizza Pizza", SnippetStart: 6, SnippetEnd: 11},
+		{Field: "contents", Start: 9, End: 14, Line: 1, Snippet: "Pizza is great.", SnippetStart: 9, SnippetEnd: 14},
+		{Field: "contents", Start: 28, End: 33, Line: 2, Snippet: "I could eat pizza every day, especially with pineapple.", SnippetStart: 12, SnippetEnd: 17},
+		{Field: "contents", Start: 61, End: 70, Line: 2, Snippet: "...eat pizza every day, especially with pineapple.", SnippetStart: 40, SnippetEnd: 49},
+	}, matches, "expecting each match once, in order, with invalid regexes skipped")
+
+	for _, match := range matches {
+		text := entry.Contents
+		if match.Field == "title" {
+			text = entry.Title
+		}
+
+		Equal(t, text[match.Start:match.End], match.Snippet[match.SnippetStart:match.SnippetEnd])
+	}
+}
+
+func TestQueryMatchesSnippet(t *testing.T) {
+	long := strings.Repeat("é", 40) + " needle " + strings.Repeat("a", 60)
+	entry := dummyEntry("notes/long", "Long", "First line\n"+long+"\nLast line")
+
+	query := Query{ContentsRegex: [][]string{{`needle\s+a+\nLast`}}}
+
+	matches := query.Matches(entry)
+	if !Len(t, matches, 1) {
+		return
+	}
+
+	match := matches[0]
+	Equal(t, 2, match.Line)
+	True(t, strings.HasPrefix(match.Snippet, "..."), "expecting long lines to be shortened")
+	False(t, strings.HasSuffix(match.Snippet, "..."), "expecting the snippet to go to the end of the line the match is cut off at")
+	True(t, strings.HasPrefix(match.Snippet[match.SnippetStart:match.SnippetEnd], "needle a"))
+	True(t, strings.HasSuffix(match.Snippet, "aaaa"), "expecting multi-line matches to be cut off at the end of the line")
+	True(t, utf8Valid(match.Snippet), "expecting the snippet not to split characters")
+}
+
+func utf8Valid(s string) bool {
+	return strings.ToValidUTF8(s, "�") == s
+}
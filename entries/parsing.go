@@ -3,24 +3,17 @@ package entries
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"gopkg.in/yaml.v2"
 )
 
-// Regex hell. I'm sorry.
+// Entries are parsed using a scanner rather than these regexes, see scanner.go. They describe the syntax of links and
+// are still used for rewriting them, such as when converting entries to plain text.
 
 var (
-	// reFrontMatter matches the front matter of an entry.
-	reFrontMatter = regexp.MustCompile(`^---\n(?:\n|.)+---\n+`)
-
-	// reInitialNewlines matches to any newlines at the beginning of a string.
-	reInitialNewlines = regexp.MustCompile(`^\n+`)
-
-	// reInitialSentence matches to the first sentence in a string.
-	reInitialSentence = regexp.MustCompile(`^(.*?)[\.!\?\n](?:\s|$)`)
-
 	// reLinkTitleNoName matches to links which specify only the other entry's title, e.g. "[[Pizza]]" or "[[Ice Cream]]"
 	// Group 1 is the title of the entry that is being linked to.
 	reLinkTitleNoName = regexp.MustCompile(`\[\[([^\]]+)\]\]`)
@@ -51,29 +44,28 @@ type YAMLFrontMatter struct {
 type Parser struct {
 	dateLayout string
 
-	reBuiltinTag *regexp.Regexp
-	reCustomTag  *regexp.Regexp
+	builtinTagPrefix string
+	customTagPrefix  string
+
+	limits Limits
 }
 
 // NewParser returns a new parser.
 func NewParser(dateLayout, builtinTagPrefix, customTagPrefix string) (Parser, error) {
-	reBuiltinTag, err := regexp.Compile(regexp.QuoteMeta(builtinTagPrefix) + "[\\w|-]+")
-	if err != nil {
-		return Parser{}, fmt.Errorf("could not build custom tag regex: %w", err)
-	}
-
-	reCustomTag, err := regexp.Compile(regexp.QuoteMeta(customTagPrefix) + "[\\w|-]+")
-	if err != nil {
-		return Parser{}, fmt.Errorf("could not build custom tag regex: %w", err)
-	}
-
 	return Parser{
-		dateLayout:   dateLayout,
-		reBuiltinTag: reBuiltinTag,
-		reCustomTag:  reCustomTag,
+		dateLayout:       dateLayout,
+		builtinTagPrefix: builtinTagPrefix,
+		customTagPrefix:  customTagPrefix,
 	}, nil
 }
 
+// WithLimits returns a copy of the parser which truncates the contents of entries longer than the limits allow and
+// applies any transforms they give. Parsers returned by NewParser have no limits.
+func (p Parser) WithLimits(limits Limits) Parser {
+	p.limits = limits
+	return p
+}
+
 // err creates a new ErrEntryParseFailed with the default values filled in.
 func (p Parser) err(path string, format string, a ...interface{}) error {
 	return ErrEntryParseFailed{
@@ -83,20 +75,37 @@ func (p Parser) err(path string, format string, a ...interface{}) error {
 }
 
 // Parse the content of an `entry.md` file into an Entry struct.
-// It does this in 4 stages:
+// Before anything else, the content is normalised so that it uses "\n" line endings and has no byte order mark. The
+// original style is kept in the entry's Encoding. Any transforms given by the parser's limits are then applied.
+// It then does this in 4 stages:
 // 1. Parse the front matter and remove it from the entry's content.
 // 2. Gets a title and date value from the entry content if they weren't specified in the front-matter.
 // 3. Parse tags.
 // 4. Parse links.
+// Tags and links are found together in a single pass over the content.
 func (p Parser) Parse(path, content string) (*Entry, error) {
 	var entry = &Entry{}
 
+	content, entry.Encoding = Normalise(content)
+
+	// Transforms only change what's parsed, the original contents are still what's in the file.
+	transformed := content
+	for _, transform := range p.limits.Transforms {
+		transformed = transform(transformed)
+	}
+
 	// Extract the front matter text from the file and return the entry's content without the front matter present
-	frontMatter, strippedContent, err := p.extractFrontMatter(path, content)
+	frontMatter, strippedContent, err := p.extractFrontMatter(path, transformed)
 	if err != nil {
 		return nil, err
 	}
 
+	// Truncate long entries before anything is done with the contents, so they're quick to work with.
+	if p.limits.MaxContents > 0 && len(strippedContent) > p.limits.MaxContents {
+		strippedContent = truncateContents(strippedContent, p.limits.MaxContents)
+		entry.Truncated = true
+	}
+
 	// Attempt to parse the front matter into a YAMLFrontMatter struct. This is because we know the types of the Title,
 	// Tags and Date keys.
 	concrete, err := p.parseFrontMatterConcrete(path, frontMatter)
@@ -150,10 +159,7 @@ func (p Parser) Parse(path, content string) (*Entry, error) {
 		tagMap[tag] = true
 	}
 
-	tags, err := p.parseTags(path, strippedContent)
-	if err != nil {
-		return nil, err
-	}
+	tags, links := newScanner(strippedContent, p.builtinTagPrefix, p.customTagPrefix).scan(true, true)
 
 	for _, tag := range tags {
 		tagMap[tag] = true
@@ -164,7 +170,7 @@ func (p Parser) Parse(path, content string) (*Entry, error) {
 		entry.Tags = append(entry.Tags, tag)
 	}
 
-	entry.OutboundLinks = p.parseLinks(path, strippedContent)
+	entry.OutboundLinks = links
 	for i := range entry.OutboundLinks {
 		entry.OutboundLinks[i].Parent = entry
 	}
@@ -174,29 +180,131 @@ func (p Parser) Parse(path, content string) (*Entry, error) {
 
 // extractFrontMatter extracts the YAML front matter text from the entry and returns it, along with setting
 // the .strippedContent value to the original content without the front matter included.
+// The stripped content is always a suffix of the content, so offsets into it can be mapped back onto the file.
 func (p Parser) extractFrontMatter(path, content string) (frontMatter string, strippedContent string, err error) {
-	if !reFrontMatter.MatchString(content) {
+	start, end, ok := findFrontMatter(content)
+	if !ok {
 		// No front-matter in text.
-		strippedContent = reInitialNewlines.ReplaceAllString(content, "")
+		strippedContent = strings.TrimLeft(content, "\n")
 		return "", strippedContent, nil
 	}
 
-	startOffset := strings.Index(content, "---") + 4
-	endOffset := strings.Index(content[startOffset:], "---") + startOffset
+	frontMatter = strings.Trim(content[start:end], "\n")
 
-	if startOffset > endOffset {
-		return "", "", p.err(path, "could not find end offset of yaml front matter")
+	// Skip the closing "---" line.
+	rest := content[end:]
+	if newline := strings.IndexByte(rest, '\n'); newline != -1 {
+		rest = rest[newline+1:]
+	} else {
+		rest = ""
 	}
 
-	frontMatter = content[startOffset:endOffset]
-	frontMatter = strings.Trim(frontMatter, "\n")
-
-	strippedContent = strings.ReplaceAll(content, content[startOffset-4:endOffset+4], "")
-	strippedContent = strings.TrimLeft(strippedContent, "\n")
+	strippedContent = strings.TrimLeft(rest, "\n")
 
 	return frontMatter, strippedContent, nil
 }
 
+// findFrontMatter finds the front matter at the start of the content, which is between a first line of "---" and the
+// next line which is also "---", ignoring trailing spaces. It returns where the text between the two lines starts and
+// ends, and false if there's no front matter.
+func findFrontMatter(content string) (start, end int, ok bool) {
+	if !strings.HasPrefix(content, "---\n") {
+		return 0, 0, false
+	}
+
+	start = 4
+
+	for lineStart := start; lineStart < len(content); {
+		lineEnd := strings.IndexByte(content[lineStart:], '\n')
+		if lineEnd == -1 {
+			lineEnd = len(content)
+		} else {
+			lineEnd += lineStart
+		}
+
+		if strings.TrimRight(content[lineStart:lineEnd], " \t") == "---" {
+			return start, lineStart, true
+		}
+
+		lineStart = lineEnd + 1
+	}
+
+	return 0, 0, false
+}
+
+// SplitFrontMatter splits content into the YAML between the "---" lines of its front matter and the rest of the content
+// after them. If the content has no front matter, the front matter is blank and the rest is the whole content.
+// JoinFrontMatter puts the two back together.
+func SplitFrontMatter(content string) (frontMatter, rest string) {
+	start, end, ok := findFrontMatter(content)
+	if !ok {
+		return "", content
+	}
+
+	rest = content[end:]
+	if i := strings.IndexByte(rest, '\n'); i != -1 {
+		rest = rest[i+1:]
+	} else {
+		rest = ""
+	}
+
+	return content[start:end], rest
+}
+
+// JoinFrontMatter adds the front matter given to the start of the rest of the content, undoing SplitFrontMatter. If
+// the front matter is blank, the rest of the content is returned without any.
+func JoinFrontMatter(frontMatter, rest string) string {
+	if strings.TrimSpace(frontMatter) == "" {
+		return rest
+	}
+
+	if !strings.HasSuffix(frontMatter, "\n") {
+		frontMatter += "\n"
+	}
+
+	return "---\n" + frontMatter + "---\n" + rest
+}
+
+// AddMetadata adds the keys in metadata to the front matter of the content if they aren't already set, adding front
+// matter if there isn't any. Keys are added in sorted order after any existing keys, so the rest of the content is left
+// as it was.
+func AddMetadata(content string, metadata map[string]interface{}) (string, error) {
+	existing := make(map[string]interface{})
+	start, end, ok := findFrontMatter(content)
+
+	if ok {
+		err := yaml.Unmarshal([]byte(content[start:end]), &existing)
+		if err != nil {
+			return "", fmt.Errorf("couldn't unmarshal front matter: %w", err)
+		}
+	}
+
+	missing := yaml.MapSlice{}
+	for key, value := range metadata {
+		if _, ok := existing[key]; !ok {
+			missing = append(missing, yaml.MapItem{Key: key, Value: value})
+		}
+	}
+
+	if len(missing) == 0 {
+		return content, nil
+	}
+
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Key.(string) < missing[j].Key.(string) })
+
+	added, err := yaml.Marshal(missing)
+	if err != nil {
+		return "", err
+	}
+
+	if !ok {
+		return "---\n" + string(added) + "---\n\n" + content, nil
+	}
+
+	// The front matter always ends at the start of the closing "---" line, so the keys go on their own lines before it.
+	return content[:end] + string(added) + content[end:], nil
+}
+
 // parseFrontMatterConcrete takes the string of a YAML front matter and unmarshals it to a struct.
 func (p Parser) parseFrontMatterConcrete(path, frontMatter string) (YAMLFrontMatter, error) {
 	config := YAMLFrontMatter{}
@@ -225,7 +333,7 @@ func (p Parser) parseFrontMatterMap(path, frontMatter string) (map[string]interf
 //   "A Day At A Restaurant." => "A Day At A Restaurant", "A Day At A Restaurant!!" => "A Day At A Restaurant!!"
 // It will also remove trailing newlines.
 func (p Parser) getFirstSentence(path, strippedContent string) (string, error) {
-	initialSentence := reInitialSentence.FindString(strippedContent)
+	initialSentence := firstSentence(strippedContent)
 
 	initialSentence = strings.Trim(initialSentence, "\n")
 	initialSentence = strings.Trim(initialSentence, ".")
@@ -237,128 +345,43 @@ func (p Parser) getFirstSentence(path, strippedContent string) (string, error) {
 	return initialSentence, nil
 }
 
-// parseTags returns all the tags in the text. The prefixes are included.
-func (p Parser) parseTags(path, strippedContent string) ([]string, error) {
-	results := []string{}
-
-	builtinMatches := p.reBuiltinTag.FindAllString(strippedContent, -1)
-	if builtinMatches != nil {
-		results = append(results, builtinMatches...)
-	}
-
-	customMatches := p.reCustomTag.FindAllString(strippedContent, -1)
-	if customMatches != nil {
-		results = append(results, customMatches...)
-	}
-
-	return results, nil
-}
-
-// parseLinks returns all the links present in the text.
-func (p Parser) parseLinks(path, strippedContent string) []Link {
-	var links []Link
-
-	titleNoNameLinks := p.parseLinksTitleNoName(path, strippedContent)
-	if titleNoNameLinks != nil {
-		links = append(links, titleNoNameLinks...)
-	}
-
-	titleWithNameLinks := p.parseLinksTitleWithName(path, strippedContent)
-	if titleWithNameLinks != nil {
-		links = append(links, titleWithNameLinks...)
-	}
-
-	pathNoNameLinks := p.parseLinksPathNoName(path, strippedContent)
-	if pathNoNameLinks != nil {
-		links = append(links, pathNoNameLinks...)
-	}
-
-	pathWithNameLinks := p.parseLinksPathWithName(path, strippedContent)
-	if pathWithNameLinks != nil {
-		links = append(links, pathWithNameLinks...)
-	}
-
-	return links
-}
-
-func (p Parser) parseLinksTitleNoName(path, strippedContent string) []Link {
-	var links []Link
-	matches := reLinkTitleNoName.FindAllSubmatchIndex([]byte(strippedContent), -1)
-
-	// match is an []int
-	// [0] and [1] are the positions of the whole match.
-	// [2] and [3] are the positions of the title.
-	for _, match := range matches {
-		title := strippedContent[match[2]:match[3]]
-		links = append(links, Link{
-			Title: title,
-			Loc:   match[:2],
-			Type:  LinkTitleNoName,
-		})
-	}
-
-	return links
-}
-
-func (p Parser) parseLinksTitleWithName(path, strippedContent string) []Link {
-	var links []Link
-	matches := reLinkTitleWithName.FindAllSubmatchIndex([]byte(strippedContent), -1)
-
-	// match is an []int
-	// [0] and [1] are the positions of the whole match.
-	// [2] and [3] are the positions of the title.
-	// [4] and [5] are the positions of the name of the link
-	for _, match := range matches {
-		title := strippedContent[match[2]:match[3]]
-		name := strippedContent[match[4]:match[5]]
-		links = append(links, Link{
-			Title: title,
-			Name:  name,
-			Loc:   match[:2],
-			Type:  LinkTitleWithName,
-		})
+// firstSentence returns the text up to and including the first ".", "!", "?" or newline which is followed by
+// whitespace or the end of the text, along with the whitespace. If the first line doesn't contain one, it returns an
+// empty string.
+func firstSentence(text string) string {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '.', '!', '?', '\n':
+			if i+1 == len(text) {
+				return text
+			}
+
+			switch text[i+1] {
+			case ' ', '\t', '\n', '\f', '\r':
+				return text[:i+2]
+			}
+
+			if text[i] == '\n' {
+				return ""
+			}
+		}
 	}
 
-	return links
+	return ""
 }
 
-func (p Parser) parseLinksPathNoName(path, strippedContent string) []Link {
-	var links []Link
-	matches := reLinkPathNoName.FindAllSubmatchIndex([]byte(strippedContent), -1)
-
-	// match is an []int
-	// [0] and [1] are the positions of the whole match.
-	// [2] and [3] are the positions of the path.
-	for _, match := range matches {
-		path := strippedContent[match[2]:match[3]]
-		links = append(links, Link{
-			Path: path,
-			Loc:  match[:2],
-			Type: LinkPathNoName,
-		})
+// parseTags returns all the tags in the text. The prefixes are included.
+func (p Parser) parseTags(path, strippedContent string) ([]string, error) {
+	tags, _ := newScanner(strippedContent, p.builtinTagPrefix, p.customTagPrefix).scan(true, false)
+	if tags == nil {
+		tags = []string{}
 	}
 
-	return links
+	return tags, nil
 }
 
-func (p Parser) parseLinksPathWithName(path, strippedContent string) []Link {
-	var links []Link
-	matches := reLinkPathWithName.FindAllSubmatchIndex([]byte(strippedContent), -1)
-
-	// match is an []int
-	// [0] and [1] are the positions of the whole match.
-	// [2] and [3] are the positions of the path.
-	// [4] and [5] are the positions of the name of the link
-	for _, match := range matches {
-		path := strippedContent[match[2]:match[3]]
-		name := strippedContent[match[4]:match[5]]
-		links = append(links, Link{
-			Path: path,
-			Name: name,
-			Loc:  match[:2],
-			Type: LinkPathWithName,
-		})
-	}
-
+// parseLinks returns all the links present in the text.
+func (p Parser) parseLinks(path, strippedContent string) []Link {
+	_, links := newScanner(strippedContent, p.builtinTagPrefix, p.customTagPrefix).scan(false, true)
 	return links
 }
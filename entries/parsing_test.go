@@ -59,6 +59,50 @@ date: "2020-08-05 11:58"`
 	Equal(t, expectedStrippedContent, strippedContent)
 }
 
+func TestAddMetadata(t *testing.T) {
+	metadata := map[string]interface{}{"type": "journal", "project": "acme", "title": "Default"}
+
+	content := "---\ntitle: \"Dummy Entry\"\n---\n\nThis is some content.\n"
+	added, err := AddMetadata(content, metadata)
+	NoError(t, err)
+	Equal(t, "---\ntitle: \"Dummy Entry\"\nproject: acme\ntype: journal\n---\n\nThis is some content.\n", added, "expecting existing keys to be kept")
+
+	added, err = AddMetadata("This is some content.\n", map[string]interface{}{"tags": []interface{}{"a", "b"}})
+	NoError(t, err)
+	Equal(t, "---\ntags:\n- a\n- b\n---\n\nThis is some content.\n", added, "expecting front matter to be added")
+
+	added, err = AddMetadata("---\n---\n", map[string]interface{}{"type": "journal"})
+	NoError(t, err)
+	Equal(t, "---\ntype: journal\n---\n", added)
+
+	added, err = AddMetadata(content, map[string]interface{}{"title": "Default"})
+	NoError(t, err)
+	Equal(t, content, added, "expecting content to be unchanged if every key is set")
+
+	_, err = AddMetadata("---\n: [\n---\n", metadata)
+	Error(t, err, "expecting error for invalid front matter")
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	content := "---\ntitle: \"Dummy Entry\"\n---  \n\nThis is some content.\n"
+
+	frontMatter, rest := SplitFrontMatter(content)
+	Equal(t, "title: \"Dummy Entry\"\n", frontMatter)
+	Equal(t, "\nThis is some content.\n", rest)
+
+	Equal(t, "---\ntitle: \"Dummy Entry\"\n---\n\nThis is some content.\n", JoinFrontMatter(frontMatter, rest))
+	Equal(t, "---\ntitle: Other\n---\n\nThis is some content.\n", JoinFrontMatter("title: Other", rest), "expecting a newline to be added")
+	Equal(t, rest, JoinFrontMatter(" \n", rest), "expecting blank front matter to be left out")
+
+	frontMatter, rest = SplitFrontMatter("This is some content.\n")
+	Equal(t, "", frontMatter)
+	Equal(t, "This is some content.\n", rest)
+
+	frontMatter, rest = SplitFrontMatter("---\ntitle: Title\n---")
+	Equal(t, "title: Title\n", frontMatter)
+	Equal(t, "", rest)
+}
+
 func TestParseFrontMatterConcrete(t *testing.T) {
 	p := newTestParser(t)
 	content := `---
@@ -238,3 +282,29 @@ func TestParseLinksPathWithName(t *testing.T) {
 	Equal(t, "{{food/pizza}(name 1)}", content[links[0].Loc[0]:links[0].Loc[1]])
 	Equal(t, "{{moods/hungry}(name 2)}", content[links[1].Loc[0]:links[1].Loc[1]])
 }
+
+func TestParseLinksOtherStore(t *testing.T) {
+	p := newTestParser(t)
+	content := dummyEntryWithContent(
+		"See {{work:notes/project}} and {{school.2020:physics/waves}(waves)}, but not {{notes/a:b}} or {{:notes}}.",
+	)
+
+	links := p.parseLinks("test/entry", content)
+
+	if len(links) != 4 {
+		t.Fatalf("expected 4 links to be matched, got=%d", len(links))
+	}
+
+	Equal(t, "work", links[0].Store, "Stores should match")
+	Equal(t, "notes/project", links[0].Path, "Paths should match")
+
+	Equal(t, "", links[1].Store, "expecting colons after a slash not to name a store")
+	Equal(t, "notes/a:b", links[1].Path)
+
+	Equal(t, "", links[2].Store, "expecting a blank store name not to name a store")
+	Equal(t, ":notes", links[2].Path)
+
+	Equal(t, "school.2020", links[3].Store, "Stores should match")
+	Equal(t, "physics/waves", links[3].Path, "Paths should match")
+	Equal(t, "waves", links[3].Name, "Names should match")
+}
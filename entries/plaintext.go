@@ -0,0 +1,76 @@
+package entries
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// reBlankLines matches runs of blank lines.
+var reBlankLines = regexp.MustCompile(`\n{3,}`)
+
+// PlainText returns the contents of the entry as plain text, without any Markdown formatting. Links to other entries are
+// replaced by their name, or the title or path of the entry linked to if they don't have one. Code blocks, images and
+// raw HTML are left out, so the text is suitable for reading aloud or analysing.
+func (e *Entry) PlainText() string {
+	source := []byte(replaceLinks(e.Contents))
+
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	doc := md.Parser().Parse(text.NewReader(source))
+
+	var out strings.Builder
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		switch node := n.(type) {
+		case *ast.CodeBlock, *ast.FencedCodeBlock, *ast.HTMLBlock, *ast.RawHTML, *ast.Image:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			if entering {
+				out.Write(node.Segment.Value(source))
+
+				if node.HardLineBreak() || node.SoftLineBreak() {
+					out.WriteString("\n")
+				}
+			}
+		case *ast.String:
+			if entering {
+				out.Write(node.Value)
+			}
+		case *ast.CodeSpan:
+			if entering {
+				for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+					if t, ok := child.(*ast.Text); ok {
+						out.Write(t.Segment.Value(source))
+					}
+				}
+			}
+
+			return ast.WalkSkipChildren, nil
+		}
+
+		if !entering && n.Type() == ast.TypeBlock && n.Kind() != ast.KindDocument {
+			out.WriteString("\n\n")
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return strings.TrimSpace(reBlankLines.ReplaceAllString(out.String(), "\n\n"))
+}
+
+// replaceLinks replaces every link to another entry in some text by the text that would be shown for it.
+func replaceLinks(contents string) string {
+	contents = reLinkTitleWithName.ReplaceAllString(contents, "$2")
+	contents = reLinkPathWithName.ReplaceAllString(contents, "$2")
+	contents = reLinkTitleNoName.ReplaceAllString(contents, "$1")
+
+	return reLinkPathNoName.ReplaceAllStringFunc(contents, func(link string) string {
+		linkPath := reLinkPathNoName.FindStringSubmatch(link)[1]
+		return strings.ReplaceAll(path.Base(linkPath), "-", " ")
+	})
+}
@@ -0,0 +1,30 @@
+package entries
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestEntryPlainText(t *testing.T) {
+	entry := &Entry{Contents: `# Heading
+
+This is *great*, like {{food/ice-cream}} and [[Pizza](the pizza)].
+See [[Beans]] and {{food/beans}(some beans)} with ` + "`code`" + `.
+
+` + "```" + `
+skip me
+` + "```" + `
+
+- one
+- two
+
+![A picture](picture.png)
+`}
+
+	Equal(
+		t,
+		"Heading\n\nThis is great, like ice cream and the pizza.\nSee Beans and some beans with code.\n\none\n\ntwo",
+		entry.PlainText(),
+	)
+}
@@ -0,0 +1,155 @@
+package entries
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+// trickyContents are entries which have caught out front matter handling or location arithmetic in the past, or which
+// look like they might. They're also used as the seed corpus for the fuzz tests.
+var trickyContents = []string{
+	"",
+	"---",
+	"---\n",
+	"---\n---\n",
+	"---\n---",
+	"----\ntitle: Not Front Matter\n----\n",
+	"---\ntitle: Nested\n---\nSome [[Link]].\n---\ntitle: Again\n---\nAnd {{a/path}}.\n",
+	"---\ntitle: a---b\n---\n\nThe [[Title]] of {{the/entry}}.",
+	"---\ntitle: [unclosed\n---\n\nMalformed.",
+	"---\ntitle: Trailing Spaces\n---   \n\nText with @!tag.",
+	"---\r\ntitle: \"CRLF\"\r\n---\r\n\r\nWindows [[Line]] endings.\r\n",
+	"\ufeff---\ntitle: \"BOM\"\n---\n\nA byte order {{mark}}.",
+	"---\ntitle: \"Duplicate\"\n---\n\n---\ntitle: \"Duplicate\"\n---\n\nThe front matter again, then [[Link]].",
+	"No front matter. Just [[Title](name)] and {{< shortcode arg >}} and [@key].",
+	"---\ntitle: \"Huge\"\n---\n\n" + strings.Repeat("[[a](b)] {{c}} @!d ", 1<<12) + "\n",
+	strings.Repeat("x", 1<<16) + ". [[Link]]",
+}
+
+// checkEntryInvariants checks the properties which should hold for any entry parsed from the content, mainly that
+// every location found in the entry slices the content to the text it describes.
+func checkEntryInvariants(t *testing.T, content string, entry *Entry) bool {
+	t.Helper()
+
+	normalised, encoding := Normalise(content)
+	if !Equal(t, normalised, entry.OriginalContents, "original contents should be the normalised content") {
+		return false
+	}
+
+	if !Equal(t, encoding, entry.Encoding, "encoding should be recorded for %q", content) {
+		return false
+	}
+
+	if !True(t, strings.HasSuffix(normalised, entry.Contents), "contents should be a suffix of the content %q", content) {
+		return false
+	}
+
+	inBounds := func(loc []int) bool {
+		return len(loc) == 2 && loc[0] >= 0 && loc[0] <= loc[1] && loc[1] <= len(entry.Contents)
+	}
+
+	for _, link := range entry.OutboundLinks {
+		if !True(t, inBounds(link.Loc), "link location %v out of bounds in %q", link.Loc, content) {
+			return false
+		}
+
+		text := entry.Contents[link.Loc[0]:link.Loc[1]]
+		if !True(t, strings.HasPrefix(text, "[[") || strings.HasPrefix(text, "{{"), "link %q should start with brackets", text) {
+			return false
+		}
+
+		if !Contains(t, text, link.Title+link.Path, "link %q should contain its target", text) {
+			return false
+		}
+
+		if !Contains(t, text, link.Name, "link %q should contain its name", text) {
+			return false
+		}
+	}
+
+	for _, citation := range entry.Citations() {
+		if !True(t, inBounds(citation.Loc), "citation location %v out of bounds in %q", citation.Loc, content) {
+			return false
+		}
+
+		if !Equal(t, citation.Text, entry.Contents[citation.Loc[0]:citation.Loc[1]], "citation location should slice to its text") {
+			return false
+		}
+	}
+
+	for _, shortcode := range entry.Shortcodes() {
+		if !True(t, inBounds(shortcode.Loc), "shortcode location %v out of bounds in %q", shortcode.Loc, content) {
+			return false
+		}
+
+		if !Equal(t, shortcode.Text, entry.Contents[shortcode.Loc[0]:shortcode.Loc[1]], "shortcode location should slice to its text") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestExtractFrontMatterTricky(t *testing.T) {
+	p := newTestParser(t)
+
+	cases := []struct {
+		content         string
+		frontMatter     string
+		strippedContent string
+	}{
+		{"---\ntitle: a---b\n---\n\nText.", "title: a---b", "Text."},
+		{"---\ntitle: Spaces\n---  \nText.", "title: Spaces", "Text."},
+		{"---\ntitle: End\n---", "title: End", ""},
+		{"---\n---\nText.", "", "Text."},
+		{"----\ntitle: No\n----\nText.", "", "----\ntitle: No\n----\nText."},
+		{"---\ntitle: Unclosed\n\nText.", "", "---\ntitle: Unclosed\n\nText."},
+		{"Text.\n---\ntitle: Later\n---\n", "", "Text.\n---\ntitle: Later\n---\n"},
+		{"---\na: 1\n---\n\n---\na: 1\n---\n\nText.", "a: 1", "---\na: 1\n---\n\nText."},
+	}
+
+	for _, tc := range cases {
+		frontMatter, strippedContent, err := p.extractFrontMatter("/test/entry", tc.content)
+		NoError(t, err, "extractFrontMatter shouldn't return an error")
+
+		Equal(t, tc.frontMatter, frontMatter, "front matter for %q", tc.content)
+		Equal(t, tc.strippedContent, strippedContent, "stripped content for %q", tc.content)
+	}
+}
+
+func TestParseInvariantsTricky(t *testing.T) {
+	p := newTestParser(t)
+
+	for _, content := range trickyContents {
+		entry, err := p.Parse("test/entry", content)
+		if err != nil {
+			continue
+		}
+
+		checkEntryInvariants(t, content, entry)
+	}
+}
+
+func TestParseInvariantsRandom(t *testing.T) {
+	p := newTestParser(t)
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 5000; i++ {
+		content := randomContent(r)
+		if i%2 == 0 {
+			content = dummyEntryWithContent(content)
+		}
+
+		entry, err := p.Parse("test/entry", content)
+		if err != nil {
+			continue
+		}
+
+		if !checkEntryInvariants(t, content, entry) {
+			return
+		}
+	}
+}
@@ -0,0 +1,469 @@
+package entries
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseExpression parses an expression in the query language into a filter. Expressions are made up of terms like
+// `path:school/*`, `tag:@?physics` or `date>2021-01-01`, which can be combined with AND, OR, NOT and parentheses:
+//
+//	path:school/* AND (tag:@?physics OR title:~"momentum") AND date>2021-01-01
+//
+// Terms next to each other without an operator between them are AND-ed, and AND binds tighter than OR. A term can be
+// negated with NOT or by starting it with "-". A term without a field, such as `pizza` or `"deep dish"`, allows entries
+// whose contents contain it.
+//
+// The fields and the operators they support are:
+//
+//	path, title, contents
+//	  field:value     the path starts with the value, or the title or contents contain it
+//	  field:~value    the field matches the value as a regular expression
+//	  field=value     the field is exactly the value
+//
+//	tag
+//	  tag:value       the entry has the tag, tag=value is the same
+//
+//	date
+//	  date>value      the entry is dated after the value, >=, <, <= and = are also supported
+//
+//	length
+//	  length>value    the contents are longer than the value in bytes, >=, <, <= and = are also supported
+//
+// If a path, title or contents value given with ":" contains a "*", it's a pattern for the whole field where "*" stands
+// for any number of characters, so `path:school/*/momentum` matches "school/physics/momentum". Dates are given as
+// 2006-01-02, "2006-01-02 15:04", RFC 3339 or anything else accepted by ParseDate, like yesterday, "last week", 2021-03
+// or 30d. A date without a time stands for the whole day, so date<=2021-01-01 includes entries from any time on the 1st
+// of January.
+//
+// Values containing spaces or parentheses need to be quoted using double quotes, and quoted values can use the same
+// escapes as Go strings. The words AND, OR and NOT are only operators when they're in capitals.
+func ParseExpression(expr string) (Filter, error) {
+	tokens, err := lexExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{expr: expr, tokens: tokens}
+
+	if len(tokens) == 0 {
+		return nil, p.errorAt(0, "empty query")
+	}
+
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.done() {
+		return nil, p.errorAt(p.peek().pos, "unexpected %q", p.peek().text)
+	}
+
+	return filter, nil
+}
+
+// exprTokenKind is the kind of a token in a query language expression.
+type exprTokenKind int
+
+const (
+	exprWord exprTokenKind = iota
+	exprOpen
+	exprClose
+)
+
+// exprToken is a token in a query language expression. Words are the operators and terms, with any quoted parts left
+// quoted so that `"AND"` isn't mistaken for an operator.
+type exprToken struct {
+	kind exprTokenKind
+	text string
+	pos  int
+}
+
+// lexExpression splits an expression into words and parentheses.
+func lexExpression(expr string) ([]exprToken, error) {
+	tokens := []exprToken{}
+
+	i := 0
+	for i < len(expr) {
+		c := expr[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '(':
+			tokens = append(tokens, exprToken{kind: exprOpen, text: "(", pos: i})
+			i++
+
+		case c == ')':
+			tokens = append(tokens, exprToken{kind: exprClose, text: ")", pos: i})
+			i++
+
+		default:
+			start := i
+
+			for i < len(expr) && !strings.ContainsRune(" \t\n\r()", rune(expr[i])) {
+				if expr[i] != '"' {
+					i++
+					continue
+				}
+
+				end, ok := closingQuote(expr, i)
+				if !ok {
+					return nil, ErrQuerySyntax{Expression: expr, Pos: i, Msg: "unterminated quoted string"}
+				}
+
+				i = end + 1
+			}
+
+			tokens = append(tokens, exprToken{kind: exprWord, text: expr[start:i], pos: start})
+		}
+	}
+
+	return tokens, nil
+}
+
+// closingQuote returns the index of the quote which closes the quoted string starting at start.
+func closingQuote(expr string, start int) (int, bool) {
+	for i := start + 1; i < len(expr); i++ {
+		switch expr[i] {
+		case '\\':
+			i++
+		case '"':
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// exprParser is a recursive descent parser for the query language.
+type exprParser struct {
+	expr   string
+	tokens []exprToken
+	i      int
+}
+
+// done returns true if all of the tokens have been parsed.
+func (p *exprParser) done() bool {
+	return p.i >= len(p.tokens)
+}
+
+// peek returns the next token without parsing it.
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.i]
+}
+
+// peekWord returns true if the next token is the given word.
+func (p *exprParser) peekWord(word string) bool {
+	return !p.done() && p.peek().kind == exprWord && p.peek().text == word
+}
+
+// errorAt returns an ErrQuerySyntax for the position in the expression.
+func (p *exprParser) errorAt(pos int, format string, a ...interface{}) error {
+	return ErrQuerySyntax{Expression: p.expr, Pos: pos, Msg: fmt.Sprintf(format, a...)}
+}
+
+// parseOr parses terms separated by OR.
+func (p *exprParser) parseOr() (Filter, error) {
+	filters := []Filter{}
+
+	for {
+		filter, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, filter)
+
+		if !p.peekWord("OR") {
+			break
+		}
+
+		p.i++
+	}
+
+	if len(filters) == 1 {
+		return filters[0], nil
+	}
+
+	return FilterOr(filters...), nil
+}
+
+// parseAnd parses terms separated by AND, or by nothing at all.
+func (p *exprParser) parseAnd() (Filter, error) {
+	filters := []Filter{}
+
+	for {
+		filter, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, filter)
+
+		if p.peekWord("AND") {
+			p.i++
+			continue
+		}
+
+		if p.done() || p.peekWord("OR") || p.peek().kind == exprClose {
+			break
+		}
+	}
+
+	if len(filters) == 1 {
+		return filters[0], nil
+	}
+
+	return FilterAnd(filters...), nil
+}
+
+// parseNot parses a term which may be negated.
+func (p *exprParser) parseNot() (Filter, error) {
+	if p.peekWord("NOT") {
+		p.i++
+
+		filter, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return FilterNot(filter), nil
+	}
+
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a term or an expression in parentheses.
+func (p *exprParser) parsePrimary() (Filter, error) {
+	if p.done() {
+		return nil, p.errorAt(len(p.expr), "unexpected end of query")
+	}
+
+	tok := p.peek()
+
+	switch {
+	case tok.kind == exprOpen:
+		p.i++
+
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.done() || p.peek().kind != exprClose {
+			return nil, p.errorAt(tok.pos, "unclosed parenthesis")
+		}
+
+		p.i++
+		return filter, nil
+
+	case tok.kind == exprClose:
+		return nil, p.errorAt(tok.pos, "unexpected %q", tok.text)
+
+	case tok.text == "AND" || tok.text == "OR":
+		return nil, p.errorAt(tok.pos, "expected a term before %q", tok.text)
+	}
+
+	p.i++
+
+	if strings.HasPrefix(tok.text, "-") && len(tok.text) > 1 {
+		filter, err := p.parseTerm(exprToken{kind: exprWord, text: tok.text[1:], pos: tok.pos + 1})
+		if err != nil {
+			return nil, err
+		}
+
+		return FilterNot(filter), nil
+	}
+
+	return p.parseTerm(tok)
+}
+
+// termRegex splits a term into its field, operator and value.
+var termRegex = regexp.MustCompile(`^(path|title|contents|tag|date|length)(:~|:|>=|<=|=|>|<)(.*)$`)
+
+// parseTerm parses a single term, such as `tag:@?physics`, into a filter.
+func (p *exprParser) parseTerm(tok exprToken) (Filter, error) {
+	parts := termRegex.FindStringSubmatch(tok.text)
+	if parts == nil {
+		value, err := p.unquote(tok.text, tok.pos)
+		if err != nil {
+			return nil, err
+		}
+
+		return FilterContentsMatch(value), nil
+	}
+
+	field, op := parts[1], parts[2]
+	valuePos := tok.pos + len(field) + len(op)
+
+	if parts[3] == "" {
+		return nil, p.errorAt(valuePos, "expected a value after %q", field+op)
+	}
+
+	value, err := p.unquote(parts[3], valuePos)
+	if err != nil {
+		return nil, err
+	}
+
+	switch field {
+	case "path", "title", "contents":
+		return p.textTerm(field, op, value, tok.pos)
+	case "tag":
+		if op != ":" && op != "=" {
+			return nil, p.errorAt(tok.pos, "tag doesn't support %q, expected ':' or '='", op)
+		}
+
+		return FilterTags(value), nil
+	case "date":
+		return p.dateTerm(op, value, valuePos)
+	default:
+		return p.lengthTerm(op, value, valuePos)
+	}
+}
+
+// textTerm creates the filter for a path, title or contents term.
+func (p *exprParser) textTerm(field, op, value string, pos int) (Filter, error) {
+	get := map[string]func(*Entry) string{
+		"path":     func(e *Entry) string { return e.Path },
+		"title":    func(e *Entry) string { return e.Title },
+		"contents": func(e *Entry) string { return e.Contents },
+	}[field]
+
+	switch op {
+	case ":~":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, p.errorAt(pos, "invalid regex %q: %s", value, err)
+		}
+
+		return func(e *Entry) bool { return re.MatchString(get(e)) }, nil
+
+	case ":":
+		if strings.Contains(value, "*") {
+			re := globRegex(value)
+			return func(e *Entry) bool { return re.MatchString(get(e)) }, nil
+		}
+
+		switch field {
+		case "path":
+			return FilterPathsMatch(value), nil
+		case "title":
+			return FilterTitlesMatch(value), nil
+		default:
+			return FilterContentsMatch(value), nil
+		}
+
+	case "=":
+		return func(e *Entry) bool { return get(e) == value }, nil
+	}
+
+	return nil, p.errorAt(pos, "%s doesn't support %q, expected ':', ':~' or '='", field, op)
+}
+
+// globRegex converts a pattern where "*" stands for any number of characters into a regular expression matching the
+// whole of a string.
+func globRegex(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	return regexp.MustCompile(`(?s)^` + strings.Join(parts, ".*") + `$`)
+}
+
+// exprDateLayouts are the layouts dates can be given in, along with how long a date given in that layout lasts.
+var exprDateLayouts = []struct {
+	layout string
+	length time.Duration
+}{
+	{"2006-01-02", 24 * time.Hour},
+	{"2006-01-02 15:04", time.Minute},
+	{time.RFC3339, time.Second},
+	{time.RFC3339Nano, time.Nanosecond},
+}
+
+// dateTerm creates the filter for a date term. A date covers the span of time [start, end), so that a day can be
+// compared as a whole.
+func (p *exprParser) dateTerm(op, value string, pos int) (Filter, error) {
+	var start, end time.Time
+	parsed := false
+
+	for _, layout := range exprDateLayouts {
+		t, err := time.Parse(layout.layout, value)
+		if err == nil {
+			start, end = t, t.Add(layout.length)
+			parsed = true
+			break
+		}
+	}
+
+	if !parsed {
+		date, err := ParseDate(value, time.RFC3339, ClockTime(time.Now()))
+		if err != nil {
+			return nil, p.errorAt(pos, "can't parse date %q, expected 2006-01-02, \"2006-01-02 15:04\", RFC 3339 or something like \"yesterday\", \"last week\", 2021-03 or 30d", value)
+		}
+
+		start, end = date.Start, date.End.Add(time.Nanosecond)
+	}
+
+	switch op {
+	case ">":
+		return func(e *Entry) bool { return !e.Date.Before(end) }, nil
+	case ">=":
+		return func(e *Entry) bool { return !e.Date.Before(start) }, nil
+	case "<":
+		return func(e *Entry) bool { return e.Date.Before(start) }, nil
+	case "<=":
+		return func(e *Entry) bool { return e.Date.Before(end) }, nil
+	case "=", ":":
+		return func(e *Entry) bool { return !e.Date.Before(start) && e.Date.Before(end) }, nil
+	}
+
+	return nil, p.errorAt(pos, "date doesn't support %q", op)
+}
+
+// lengthTerm creates the filter for a length term.
+func (p *exprParser) lengthTerm(op, value string, pos int) (Filter, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return nil, p.errorAt(pos, "invalid length %q, expected a number", value)
+	}
+
+	switch op {
+	case ">":
+		return func(e *Entry) bool { return len(e.Contents) > n }, nil
+	case ">=":
+		return func(e *Entry) bool { return len(e.Contents) >= n }, nil
+	case "<":
+		return func(e *Entry) bool { return len(e.Contents) < n }, nil
+	case "<=":
+		return func(e *Entry) bool { return len(e.Contents) <= n }, nil
+	case "=", ":":
+		return func(e *Entry) bool { return len(e.Contents) == n }, nil
+	}
+
+	return nil, p.errorAt(pos, "length doesn't support %q", op)
+}
+
+// unquote removes the quotes from a value, if it's quoted.
+func (p *exprParser) unquote(value string, pos int) (string, error) {
+	if !strings.HasPrefix(value, `"`) {
+		if i := strings.IndexRune(value, '"'); i != -1 {
+			return "", p.errorAt(pos+i, "unexpected quote in the middle of %q", value)
+		}
+
+		return value, nil
+	}
+
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return "", p.errorAt(pos, "invalid quoted string %s", value)
+	}
+
+	return unquoted, nil
+}
@@ -0,0 +1,147 @@
+package entries
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func queryLangEntries() []*Entry {
+	momentum := dummyEntry("school/physics/momentum", "Momentum", "Momentum is mass times velocity.")
+	momentum.Tags = []string{"@?physics"}
+	momentum.Date = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	waves := dummyEntry("school/physics/waves", "Waves", "Waves carry energy (but not matter).")
+	waves.Tags = []string{"@?physics", "@?draft"}
+	waves.Date = time.Date(2021, 1, 1, 18, 30, 0, 0, time.UTC)
+
+	cells := dummyEntry("school/biology/cells", "Cells", "Cells are the building blocks of life.")
+	cells.Tags = []string{"@?biology"}
+	cells.Date = time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	pizza := dummyEntry("food/pizza", "Pizza", "Deep dish pizza.")
+	pizza.Date = time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	return []*Entry{momentum, waves, cells, pizza}
+}
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{`path:school/* AND (tag:@?physics OR title:~"momentum") AND date>2021-01-01`, []string{"school/physics/momentum"}},
+		{`path:school/*`, []string{"school/biology/cells", "school/physics/momentum", "school/physics/waves"}},
+		{`path:school/*/momentum`, []string{"school/physics/momentum"}},
+		{`path:school/phys`, []string{"school/physics/momentum", "school/physics/waves"}},
+		{`path=food`, []string{}},
+		{`path=food/pizza`, []string{"food/pizza"}},
+		{`tag:@?physics -tag:@?draft`, []string{"school/physics/momentum"}},
+		{`tag:@?physics AND NOT tag:@?draft`, []string{"school/physics/momentum"}},
+		{`tag:@?biology OR path:food`, []string{"food/pizza", "school/biology/cells"}},
+		{`tag:@?biology OR path:food tag:@?physics`, []string{"school/biology/cells"}},
+		{`(tag:@?biology OR path:food) title:Pizza`, []string{"food/pizza"}},
+		{`title:~"^(M|W)"`, []string{"school/physics/momentum", "school/physics/waves"}},
+		{`title:~(?i)pizza`, nil},
+		{`"Deep dish"`, []string{"food/pizza"}},
+		{`contents:"(but not matter)"`, []string{"school/physics/waves"}},
+		{`contents:~"mass\\s+times"`, []string{"school/physics/momentum"}},
+		{`title="Cells"`, []string{"school/biology/cells"}},
+		{`date<=2021-01-01`, []string{"school/biology/cells", "school/physics/waves"}},
+		{`date<2021-01-01`, []string{"school/biology/cells"}},
+		{`date=2021-01-01`, []string{"school/physics/waves"}},
+		{`date>="2021-01-01 18:30"`, []string{"food/pizza", "school/physics/momentum", "school/physics/waves"}},
+		{`date>2021-03-01T12:00:00Z`, []string{"food/pizza"}},
+		{`date=2021-03`, []string{"school/physics/momentum"}},
+		{`date<2021 OR date>=2021-05`, []string{"food/pizza", "school/biology/cells"}},
+		{`length<20`, []string{"food/pizza"}},
+		{`length>=32`, []string{"school/physics/momentum", "school/physics/waves", "school/biology/cells"}},
+		{`NOT NOT path:food`, []string{"food/pizza"}},
+		{`"AND"`, []string{}},
+	}
+
+	for _, test := range tests {
+		filter, err := ParseExpression(test.expr)
+		if test.want == nil {
+			Error(t, err, "expecting %s not to parse", test.expr)
+			continue
+		}
+
+		if !NoError(t, err, "parsing %s", test.expr) {
+			continue
+		}
+
+		got := []string{}
+		for _, entry := range queryLangEntries() {
+			if filter(entry) {
+				got = append(got, entry.Path)
+			}
+		}
+
+		sort.Strings(got)
+		sort.Strings(test.want)
+
+		Equal(t, test.want, got, "matching %s", test.expr)
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+	}{
+		{``, 0},
+		{`path:school AND`, 15},
+		{`OR path:school`, 0},
+		{`(path:school`, 0},
+		{`path:school)`, 11},
+		{`title:"unterminated`, 6},
+		{`title:~"("`, 0},
+		{`date>someday`, 5},
+		{`date:~2021-01-01`, 6},
+		{`length>lots`, 7},
+		{`tag>@?physics`, 0},
+		{`path:`, 5},
+		{`title:a"b"`, 7},
+	}
+
+	for _, test := range tests {
+		_, err := ParseExpression(test.expr)
+
+		var syntaxErr ErrQuerySyntax
+		if !True(t, errors.As(err, &syntaxErr), "expecting an ErrQuerySyntax for %q, got %v", test.expr, err) {
+			continue
+		}
+
+		Equal(t, test.pos, syntaxErr.Pos, "position of error for %q: %s", test.expr, err)
+	}
+}
+
+func TestQueryExpression(t *testing.T) {
+	collection := NewCollection()
+	for _, entry := range queryLangEntries() {
+		NoError(t, collection.Add(entry))
+	}
+
+	query := Query{
+		PathsMatch: [][]string{{"school"}},
+		Expression: `tag:@?physics date<2021-02-01`,
+	}
+
+	NoError(t, query.Validate())
+
+	filtered, err := collection.Filter(query.Filter())
+	NoError(t, err)
+	Equal(t, 1, filtered.Len())
+	NotNil(t, filtered.Get("school/physics/waves"))
+
+	invalid := Query{Expression: `path:school AND`}
+	Error(t, invalid.Validate())
+
+	filtered, err = collection.Filter(invalid.Filter())
+	NoError(t, err)
+	Equal(t, 0, filtered.Len(), "expecting an invalid expression not to allow any entries")
+}
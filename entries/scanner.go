@@ -0,0 +1,213 @@
+package entries
+
+import (
+	"strings"
+)
+
+// scanner finds the tags and links in the contents of an entry in a single pass. It gives exactly the same results as
+// matching each of the link regexes and tag regexes in turn, including the order of the results and their locations,
+// but without scanning the contents six times.
+//
+// Each kind of tag and link is matched independently, like a separate regex would, so that a match of one kind doesn't
+// hide an overlapping match of another.
+type scanner struct {
+	contents string
+
+	builtinPrefix, customPrefix string
+
+	// next is the position each kind of match can next start at, since matches of the same kind can't overlap.
+	next [6]int
+
+	// closing caches the positions of the next closing brackets, and names caches the positions of the next ")" after
+	// the target of each kind of link.
+	closing map[byte]*nextIndex
+	names   map[byte]*nextIndex
+}
+
+// The kinds of match, which index scanner.next.
+const (
+	scanTitleNoName = iota
+	scanTitleWithName
+	scanPathNoName
+	scanPathWithName
+	scanBuiltinTag
+	scanCustomTag
+)
+
+// nextIndex finds the next occurrence of a byte in a string. Since the contents are scanned from start to end, the
+// position searched from never decreases, so the last result can be reused until it's been passed. This keeps
+// scanning linear even for contents like "[[[[[[[[..." where every position starts a link which is never closed.
+type nextIndex struct {
+	s      string
+	c      byte
+	result int
+}
+
+// from returns the index of the first occurrence of the byte at or after pos, or -1 if there isn't one. pos must not be
+// less than it was in the previous call.
+func (n *nextIndex) from(pos int) int {
+	if pos > len(n.s) {
+		return -1
+	}
+
+	if n.result >= pos {
+		if n.result == len(n.s) {
+			return -1
+		}
+
+		return n.result
+	}
+
+	i := strings.IndexByte(n.s[pos:], n.c)
+	if i == -1 {
+		n.result = len(n.s)
+		return -1
+	}
+
+	n.result = pos + i
+	return n.result
+}
+
+// newScanner returns a new scanner for the contents of an entry.
+func newScanner(contents, builtinPrefix, customPrefix string) *scanner {
+	s := &scanner{
+		contents:      contents,
+		builtinPrefix: builtinPrefix,
+		customPrefix:  customPrefix,
+		closing:       map[byte]*nextIndex{},
+		names:         map[byte]*nextIndex{},
+	}
+
+	for _, c := range []byte{']', '}'} {
+		s.closing[c] = &nextIndex{s: contents, c: c, result: -1}
+		s.names[c] = &nextIndex{s: contents, c: ')', result: -1}
+	}
+
+	return s
+}
+
+// scan returns the tags and links in the contents. Tags are given with their prefixes, builtin tags first. Links are
+// given grouped by type, in the order title links without names, title links with names, path links without names and
+// then path links with names. Shortcodes aren't counted as path links.
+func (s *scanner) scan(scanTags, scanLinks bool) (tags []string, links []Link) {
+	var found [6][]Link
+	var builtinTags, customTags []string
+
+	for i := 0; i < len(s.contents); i++ {
+		if scanTags {
+			if end := s.matchTag(i, scanBuiltinTag, s.builtinPrefix); end != -1 {
+				builtinTags = append(builtinTags, s.contents[i:end])
+			}
+
+			if end := s.matchTag(i, scanCustomTag, s.customPrefix); end != -1 {
+				customTags = append(customTags, s.contents[i:end])
+			}
+		}
+
+		if !scanLinks || i+1 >= len(s.contents) {
+			continue
+		}
+
+		switch s.contents[i : i+2] {
+		case "[[":
+			s.matchLink(i, '[', ']', LinkTitleNoName, LinkTitleWithName, &found)
+		case "{{":
+			s.matchLink(i, '{', '}', LinkPathNoName, LinkPathWithName, &found)
+		}
+	}
+
+	tags = append(builtinTags, customTags...)
+
+	for _, kind := range []int{scanTitleNoName, scanTitleWithName, scanPathNoName, scanPathWithName} {
+		links = append(links, found[kind]...)
+	}
+
+	return tags, links
+}
+
+// matchTag returns the end of a tag with the given prefix starting at i, or -1 if there isn't one. A tag is the prefix
+// followed by one or more letters, digits, underscores, dashes or pipes.
+func (s *scanner) matchTag(i, kind int, prefix string) int {
+	if i < s.next[kind] || !strings.HasPrefix(s.contents[i:], prefix) {
+		return -1
+	}
+
+	end := i + len(prefix)
+	for end < len(s.contents) && isTagChar(s.contents[end]) {
+		end++
+	}
+
+	if end == i+len(prefix) {
+		return -1
+	}
+
+	s.next[kind] = end
+	return end
+}
+
+// isTagChar returns true if the byte can be part of a tag after the prefix.
+func isTagChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '|'
+}
+
+// matchLink matches the links starting at i, which is the start of an opening pair of brackets like "[[" or "{{". It
+// checks for both a link without a name, like "[[target]]", and a link with a name, like "[[target](name)]", adding
+// each to found.
+//
+// In both cases the target is everything up to the first closing bracket, which must come straight after the opening
+// pair, and the name is everything up to the first ")" after that.
+func (s *scanner) matchLink(i int, open, close byte, noName, withName LinkType, found *[6][]Link) {
+	noNameKind, withNameKind := scanTitleNoName, scanTitleWithName
+	if open == '{' {
+		noNameKind, withNameKind = scanPathNoName, scanPathWithName
+	}
+
+	if i < s.next[noNameKind] && i < s.next[withNameKind] {
+		return
+	}
+
+	targetStart := i + 2
+	targetEnd := s.closing[close].from(targetStart)
+	if targetEnd == -1 || targetEnd == targetStart || targetEnd+1 >= len(s.contents) {
+		return
+	}
+
+	target := s.contents[targetStart:targetEnd]
+
+	if i >= s.next[noNameKind] && s.contents[targetEnd+1] == close {
+		end := targetEnd + 2
+		s.next[noNameKind] = end
+
+		link := Link{Loc: []int{i, end}, Type: noName}
+		if noName == LinkPathNoName {
+			link.Embed, link.Section, target = splitEmbed(target)
+			link.Store, link.Path = splitStore(target)
+		} else {
+			link.Title = target
+		}
+
+		if !(noName == LinkPathNoName && isShortcode(target)) {
+			found[noNameKind] = append(found[noNameKind], link)
+		}
+	}
+
+	if i >= s.next[withNameKind] && s.contents[targetEnd+1] == '(' {
+		nameStart := targetEnd + 2
+		nameEnd := s.names[close].from(nameStart)
+		if nameEnd == -1 || nameEnd == nameStart || nameEnd+1 >= len(s.contents) || s.contents[nameEnd+1] != close {
+			return
+		}
+
+		end := nameEnd + 2
+		s.next[withNameKind] = end
+
+		link := Link{Name: s.contents[nameStart:nameEnd], Loc: []int{i, end}, Type: withName}
+		if withName == LinkPathWithName {
+			link.Store, link.Path = splitStore(target)
+		} else {
+			link.Title = target
+		}
+
+		found[withNameKind] = append(found[withNameKind], link)
+	}
+}
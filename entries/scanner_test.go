@@ -0,0 +1,128 @@
+package entries
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+// reTestInitialSentence is the regex the first sentence of an entry used to be found with.
+var reTestInitialSentence = regexp.MustCompile(`^(.*?)[\.!\?\n](?:\s|$)`)
+
+// regexTagsAndLinks finds tags and links the way entries used to be parsed, using regexes.
+func regexTagsAndLinks(content, builtinPrefix, customPrefix string) ([]string, []Link) {
+	tags := []string{}
+	tags = append(tags, regexp.MustCompile(regexp.QuoteMeta(builtinPrefix)+`[\w|-]+`).FindAllString(content, -1)...)
+	tags = append(tags, regexp.MustCompile(regexp.QuoteMeta(customPrefix)+`[\w|-]+`).FindAllString(content, -1)...)
+
+	var links []Link
+
+	for _, match := range reLinkTitleNoName.FindAllStringSubmatchIndex(content, -1) {
+		links = append(links, Link{Title: content[match[2]:match[3]], Loc: match[:2], Type: LinkTitleNoName})
+	}
+
+	for _, match := range reLinkTitleWithName.FindAllStringSubmatchIndex(content, -1) {
+		links = append(links, Link{Title: content[match[2]:match[3]], Name: content[match[4]:match[5]], Loc: match[:2], Type: LinkTitleWithName})
+	}
+
+	for _, match := range reLinkPathNoName.FindAllStringSubmatchIndex(content, -1) {
+		if !isShortcode(content[match[2]:match[3]]) {
+			embed, section, target := splitEmbed(content[match[2]:match[3]])
+			store, path := splitStore(target)
+			links = append(links, Link{Path: path, Store: store, Embed: embed, Section: section, Loc: match[:2], Type: LinkPathNoName})
+		}
+	}
+
+	for _, match := range reLinkPathWithName.FindAllStringSubmatchIndex(content, -1) {
+		store, path := splitStore(content[match[2]:match[3]])
+		links = append(links, Link{Path: path, Store: store, Name: content[match[4]:match[5]], Loc: match[:2], Type: LinkPathWithName})
+	}
+
+	return tags, links
+}
+
+// randomContent returns random content made up of pieces which are likely to form tags, links, front matter and
+// sentences, as well as multi-byte and invalid UTF-8.
+func randomContent(r *rand.Rand) string {
+	pieces := []string{
+		"[", "[[", "]", "]]", "{", "{{", "}", "}}", "(", ")", "<", ">", "@!", "@?", "@", "!", "?", ".", "-", "|", "_", ":",
+		"a", "b", "Z", "9", " ", "\t", "\n", "\r\n", "---\n", "é", "\xe2", "\x80",
+	}
+
+	var sb strings.Builder
+	n := r.Intn(40)
+
+	for i := 0; i < n; i++ {
+		sb.WriteString(pieces[r.Intn(len(pieces))])
+	}
+
+	return sb.String()
+}
+
+func TestScannerMatchesRegexes(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	prefixes := [][2]string{{"@!", "@?"}, {"#", "##"}, {"", "@"}}
+
+	for i := 0; i < 20000; i++ {
+		content := randomContent(r)
+		prefix := prefixes[i%len(prefixes)]
+
+		expectedTags, expectedLinks := regexTagsAndLinks(content, prefix[0], prefix[1])
+		tags, links := newScanner(content, prefix[0], prefix[1]).scan(true, true)
+		if tags == nil {
+			tags = []string{}
+		}
+
+		if !Equal(t, expectedTags, tags, "tags should match for %q", content) {
+			return
+		}
+
+		if !Equal(t, expectedLinks, links, "links should match for %q", content) {
+			return
+		}
+
+		if !Equal(t, reTestInitialSentence.FindString(content), firstSentence(content), "first sentence should match for %q", content) {
+			return
+		}
+	}
+}
+
+func TestScannerLinear(t *testing.T) {
+	contents := []string{
+		strings.Repeat("[[", 100000),
+		strings.Repeat("{{a}(", 100000),
+		strings.Repeat("[[a](", 50000) + strings.Repeat("{{", 50000),
+	}
+
+	for _, content := range contents {
+		start := time.Now()
+		newScanner(content, "@!", "@?").scan(true, true)
+
+		Less(t, int64(time.Since(start)), int64(time.Second), "scanning pathological contents should be quick")
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	p, err := NewParser(testDateLayout, testBuiltinTagPrefix, testCustomTagPrefix)
+	if err != nil {
+		b.Fatalf("couldn't create new parser: %s", err)
+	}
+
+	content := dummyEntryWithContent(strings.Repeat(
+		"I had [[Pizza]] with {{people/jane}(Jane)} today, which was @!great. See [[Ice Cream](dessert)] and {{food/ice-cream}}.\n\n",
+		200,
+	))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := p.Parse("test/entry", content)
+		if err != nil {
+			b.Fatalf("wasn't expecting parsing error: %s", err)
+		}
+	}
+}
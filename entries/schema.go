@@ -0,0 +1,238 @@
+package entries
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SchemaDraft is the JSON Schema draft that generated schemas conform to.
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON Schema describing the front matter of a set of entries. It's inferred from the metadata that is
+// actually used, rather than being specified up front, so it's a description of what the store looks like now.
+type Schema struct {
+	Schema      string                     `json:"$schema"`
+	Title       string                     `json:"title,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Type        string                     `json:"type"`
+	Properties  map[string]*SchemaProperty `json:"properties"`
+	Required    []string                   `json:"required,omitempty"`
+}
+
+// SchemaProperty describes a single key in the front matter. Nested maps and lists are described using Properties
+// and Items respectively.
+type SchemaProperty struct {
+	// Type is a list of all the JSON types seen for this key, such as "string" or "integer".
+	Type []string `json:"type"`
+
+	// Format is set to "date-time" if all the values seen were dates.
+	Format string `json:"format,omitempty"`
+
+	// Enum is a list of candidate values if the key only ever takes a small number of distinct values.
+	Enum []interface{} `json:"enum,omitempty"`
+
+	// Description is a human readable summary of how often the key is used.
+	Description string `json:"description,omitempty"`
+
+	// Items describes the values in a list.
+	Items *SchemaProperty `json:"items,omitempty"`
+
+	// Properties describes the keys in a map.
+	Properties map[string]*SchemaProperty `json:"properties,omitempty"`
+
+	// Frequency is the number of entries (or parent values) the key was present in.
+	Frequency int `json:"x-frequency,omitempty"`
+
+	types    map[string]bool
+	values   map[string]interface{}
+	children int
+	onlyTime bool
+}
+
+// SchemaOptions controls how a schema is inferred.
+type SchemaOptions struct {
+	// EnumThreshold is the maximum number of distinct values a key can take for it to be considered an enum. Values
+	// are only considered enum candidates if they've been repeated, so a key which is unique in every entry will never be
+	// an enum. Setting it to 0 disables enum detection.
+	EnumThreshold int
+
+	// RequiredThreshold is the fraction of entries a top-level key has to be present in for it to be marked as required,
+	// between 0 and 1. Setting it to 0 means nothing is ever required.
+	RequiredThreshold float64
+}
+
+// DefaultSchemaOptions are sensible defaults for inferring a schema.
+var DefaultSchemaOptions = SchemaOptions{
+	EnumThreshold:     10,
+	RequiredThreshold: 1,
+}
+
+// InferSchema builds a JSON Schema describing the metadata used across the entries in the list.
+func InferSchema(list List, opts SchemaOptions) *Schema {
+	root := newSchemaProperty()
+
+	for _, entry := range list.Slice() {
+		root.observeMap(stringKeys(entry.Metadata))
+	}
+
+	total := len(list.Slice())
+
+	// An empty store still has an object of properties, rather than "properties": null.
+	if root.Properties == nil {
+		root.Properties = make(map[string]*SchemaProperty)
+	}
+
+	schema := &Schema{
+		Schema:      SchemaDraft,
+		Title:       "Albatross entry front matter",
+		Description: fmt.Sprintf("Inferred from %d entries.", total),
+		Type:        "object",
+		Properties:  root.Properties,
+	}
+
+	for key, prop := range root.Properties {
+		if opts.RequiredThreshold > 0 && total > 0 && float64(prop.Frequency)/float64(total) >= opts.RequiredThreshold {
+			schema.Required = append(schema.Required, key)
+		}
+	}
+
+	sort.Strings(schema.Required)
+
+	for _, prop := range schema.Properties {
+		prop.finalise(total, opts)
+	}
+
+	return schema
+}
+
+// newSchemaProperty returns a new, initialised SchemaProperty.
+func newSchemaProperty() *SchemaProperty {
+	return &SchemaProperty{
+		types:    make(map[string]bool),
+		values:   make(map[string]interface{}),
+		onlyTime: true,
+	}
+}
+
+// observe records a single value seen for this property.
+func (p *SchemaProperty) observe(value interface{}) {
+	p.Frequency++
+
+	switch v := value.(type) {
+	case nil:
+		p.types["null"] = true
+		p.onlyTime = false
+	case string:
+		p.types["string"] = true
+		p.values[fmt.Sprintf("%T:%v", v, v)] = v
+		p.onlyTime = false
+	case bool:
+		p.types["boolean"] = true
+		p.values[fmt.Sprintf("%T:%v", v, v)] = v
+		p.onlyTime = false
+	case int, int64, uint64:
+		p.types["integer"] = true
+		p.values[fmt.Sprintf("%T:%v", v, v)] = v
+		p.onlyTime = false
+	case float64:
+		p.types["number"] = true
+		p.values[fmt.Sprintf("%T:%v", v, v)] = v
+		p.onlyTime = false
+	case time.Time:
+		p.types["string"] = true
+	case []interface{}:
+		p.types["array"] = true
+		p.onlyTime = false
+
+		if p.Items == nil {
+			p.Items = newSchemaProperty()
+		}
+
+		for _, item := range v {
+			p.Items.observe(item)
+		}
+	case map[interface{}]interface{}:
+		p.types["object"] = true
+		p.onlyTime = false
+		p.observeMap(stringKeys(v))
+	case map[string]interface{}:
+		p.types["object"] = true
+		p.onlyTime = false
+		p.observeMap(v)
+	default:
+		p.types["string"] = true
+		p.onlyTime = false
+	}
+}
+
+// observeMap records the keys and values of a map for this property.
+func (p *SchemaProperty) observeMap(m map[string]interface{}) {
+	if p.Properties == nil {
+		p.Properties = make(map[string]*SchemaProperty)
+	}
+
+	p.children++
+
+	for key, value := range m {
+		if p.Properties[key] == nil {
+			p.Properties[key] = newSchemaProperty()
+		}
+
+		p.Properties[key].observe(value)
+	}
+}
+
+// finalise converts the information gathered while observing values into the exported fields.
+// parentTotal is the number of times the parent of this property was seen.
+func (p *SchemaProperty) finalise(parentTotal int, opts SchemaOptions) {
+	for t := range p.types {
+		p.Type = append(p.Type, t)
+	}
+	sort.Strings(p.Type)
+
+	if p.onlyTime && p.Frequency > 0 {
+		p.Format = "date-time"
+	}
+
+	if parentTotal > 0 {
+		p.Description = fmt.Sprintf("Present in %d of %d (%.0f%%).", p.Frequency, parentTotal, 100*float64(p.Frequency)/float64(parentTotal))
+	}
+
+	if opts.EnumThreshold > 0 && len(p.values) > 0 && len(p.values) <= opts.EnumThreshold && len(p.values) < p.Frequency && !p.types["array"] && !p.types["object"] {
+		keys := []string{}
+		for key := range p.values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			p.Enum = append(p.Enum, p.values[key])
+		}
+	}
+
+	if p.Items != nil {
+		p.Items.finalise(0, opts)
+		p.Items.Frequency = 0
+	}
+
+	for _, child := range p.Properties {
+		child.finalise(p.children, opts)
+	}
+}
+
+// stringKeys converts a map with interface{} keys, as decoded by the YAML library, into a map with string keys.
+func stringKeys(m interface{}) map[string]interface{} {
+	switch v := m.(type) {
+	case map[string]interface{}:
+		return v
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{})
+		for key, value := range v {
+			out[fmt.Sprint(key)] = value
+		}
+		return out
+	}
+
+	return map[string]interface{}{}
+}
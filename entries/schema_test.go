@@ -0,0 +1,50 @@
+package entries
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestInferSchema(t *testing.T) {
+	entry1 := &Entry{Path: "food/pizza", Metadata: map[string]interface{}{"title": "Pizza", "rating": 5, "status": "done"}}
+	entry2 := &Entry{Path: "food/ice-cream", Metadata: map[string]interface{}{"title": "Ice Cream", "rating": 4.5, "status": "done"}}
+	entry3 := &Entry{Path: "food/beans", Metadata: map[string]interface{}{
+		"title":  "Beans",
+		"status": "draft",
+		"custom": map[interface{}]interface{}{"weight": "9 stone"},
+		"tags":   []interface{}{"@?food", "@?food"},
+	}}
+
+	schema := InferSchema(List{[]*Entry{entry1, entry2, entry3}}, DefaultSchemaOptions)
+
+	Equal(t, SchemaDraft, schema.Schema)
+	Equal(t, []string{"status", "title"}, schema.Required, "only keys present in every entry should be required")
+
+	Equal(t, []string{"string"}, schema.Properties["title"].Type)
+	Nil(t, schema.Properties["title"].Enum, "unique titles shouldn't be an enum")
+
+	Equal(t, []string{"integer", "number"}, schema.Properties["rating"].Type)
+	Equal(t, 2, schema.Properties["rating"].Frequency)
+
+	Equal(t, []interface{}{"done", "draft"}, schema.Properties["status"].Enum, "repeated values should be enum candidates")
+
+	Equal(t, []string{"object"}, schema.Properties["custom"].Type)
+	Equal(t, []string{"string"}, schema.Properties["custom"].Properties["weight"].Type)
+
+	Equal(t, []string{"array"}, schema.Properties["tags"].Type)
+	Equal(t, []string{"string"}, schema.Properties["tags"].Items.Type)
+}
+
+func TestInferSchemaEmpty(t *testing.T) {
+	schema := InferSchema(NewCollection().List(), DefaultSchemaOptions)
+
+	NotNil(t, schema.Properties, "expecting properties even without any entries")
+	Empty(t, schema.Required)
+
+	out, err := json.Marshal(schema)
+	Nil(t, err)
+	Contains(t, string(out), `"properties":{}`)
+	Contains(t, string(out), `"description":"Inferred from 0 entries."`)
+}
@@ -0,0 +1,142 @@
+package entries
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// reShortcode matches shortcodes, e.g. "{{< weather >}}" or "{{< contact name="Jane" >}}".
+// Group 1 is the name of the shortcode and group 2 is its arguments.
+var reShortcode = regexp.MustCompile(`{{<\s*([\w-]+)((?:\s+[^>]*?)?)\s*>}}`)
+
+// Shortcode is a shortcode in an entry, which stands in for a snippet defined elsewhere, such as "{{< weather >}}".
+// Shortcodes can be given arguments, either positionally or by name:
+//
+//	{{< contact "Jane Doe" phone="01234 567890" >}}
+type Shortcode struct {
+	// Name is the name of the shortcode, such as "contact".
+	Name string `json:"name"`
+
+	// Args are the positional arguments, such as "Jane Doe" above.
+	Args []string `json:"args"`
+
+	// Params are the named arguments, such as phone="01234 567890" above.
+	Params map[string]string `json:"params"`
+
+	// Text is the shortcode as it appears in the entry.
+	Text string `json:"text"`
+
+	// Loc is the location of the shortcode in the entry's contents, so the shortcode is at Contents[Loc[0]:Loc[1]].
+	Loc []int `json:"loc"`
+}
+
+// isShortcode returns true if the inside of a path link, like "food/pizza" in "{{food/pizza}}", is actually a
+// shortcode. This prevents shortcodes from being parsed as links.
+func isShortcode(inner string) bool {
+	return strings.HasPrefix(inner, "<") && strings.HasSuffix(inner, ">")
+}
+
+// Shortcodes returns the shortcodes in the entry, in the order they appear. Shortcodes inside code are ignored.
+func (e *Entry) Shortcodes() []Shortcode {
+	shortcodes := []Shortcode{}
+	code := reCodeBlock.FindAllStringIndex(e.Contents, -1)
+
+	for _, match := range reShortcode.FindAllStringSubmatchIndex(e.Contents, -1) {
+		loc := match[:2]
+		if overlaps(loc, code) {
+			continue
+		}
+
+		args, params := parseShortcodeArgs(e.Contents[match[4]:match[5]])
+
+		shortcodes = append(shortcodes, Shortcode{
+			Name:   e.Contents[match[2]:match[3]],
+			Args:   args,
+			Params: params,
+			Text:   e.Contents[loc[0]:loc[1]],
+			Loc:    loc,
+		})
+	}
+
+	return shortcodes
+}
+
+// ExpandShortcodes returns the contents of the entry with each shortcode replaced by the result of expand. The entry
+// itself isn't changed.
+func (e *Entry) ExpandShortcodes(expand func(shortcode Shortcode) (string, error)) (string, error) {
+	shortcodes := e.Shortcodes()
+	sort.Slice(shortcodes, func(i, j int) bool { return shortcodes[i].Loc[0] > shortcodes[j].Loc[0] })
+
+	contents := e.Contents
+
+	for _, shortcode := range shortcodes {
+		expanded, err := expand(shortcode)
+		if err != nil {
+			return "", err
+		}
+
+		contents = contents[:shortcode.Loc[0]] + expanded + contents[shortcode.Loc[1]:]
+	}
+
+	return contents, nil
+}
+
+// parseShortcodeArgs splits the arguments to a shortcode into positional and named arguments. Values can be quoted with
+// double quotes to include spaces.
+func parseShortcodeArgs(s string) (args []string, params map[string]string) {
+	args = []string{}
+	params = map[string]string{}
+
+	for _, field := range splitShortcodeArgs(s) {
+		eq := strings.Index(field, "=")
+		if eq > 0 && !strings.HasPrefix(field, `"`) {
+			params[field[:eq]] = unquoteShortcodeArg(field[eq+1:])
+		} else {
+			args = append(args, unquoteShortcodeArg(field))
+		}
+	}
+
+	return args, params
+}
+
+// splitShortcodeArgs splits a string on spaces which aren't inside double quotes.
+func splitShortcodeArgs(s string) []string {
+	fields := []string{}
+	var current strings.Builder
+	quoted, started := false, false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if started {
+				fields = append(fields, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			started = true
+			current.WriteRune(r)
+		}
+	}
+
+	if started {
+		fields = append(fields, current.String())
+	}
+
+	return fields
+}
+
+// unquoteShortcodeArg removes the double quotes around an argument, if there are any.
+func unquoteShortcodeArg(s string) string {
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		return s[1 : len(s)-1]
+	}
+
+	return s
+}
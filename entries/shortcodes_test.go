@@ -0,0 +1,47 @@
+package entries
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestShortcodes(t *testing.T) {
+	entry := dummyEntry(
+		"people/jane",
+		"Jane",
+		"{{< contact \"Jane Doe\" phone=\"01234 567890\" >}}\n\nIt was {{<weather>}} today, see {{food/pizza}}.\n\n`{{< code >}}`",
+	)
+
+	shortcodes := entry.Shortcodes()
+	if !Len(t, shortcodes, 2) {
+		return
+	}
+
+	Equal(t, "contact", shortcodes[0].Name)
+	Equal(t, []string{"Jane Doe"}, shortcodes[0].Args)
+	Equal(t, map[string]string{"phone": "01234 567890"}, shortcodes[0].Params)
+	Equal(t, entry.Contents[shortcodes[0].Loc[0]:shortcodes[0].Loc[1]], shortcodes[0].Text)
+
+	Equal(t, "weather", shortcodes[1].Name)
+	Equal(t, []string{}, shortcodes[1].Args)
+
+	expanded, err := entry.ExpandShortcodes(func(shortcode Shortcode) (string, error) {
+		return strings.ToUpper(shortcode.Name), nil
+	})
+	NoError(t, err)
+	Equal(t, "CONTACT\n\nIt was WEATHER today, see {{food/pizza}}.\n\n`{{< code >}}`", expanded)
+}
+
+func TestParseLinksIgnoresShortcodes(t *testing.T) {
+	p := newTestParser(t)
+	content := dummyEntryWithContent("{{< weather >}} and {{food/pizza}}.")
+
+	links := p.parseLinks("test/entry", content)
+	if !Len(t, links, 1) {
+		return
+	}
+
+	Equal(t, "food/pizza", links[0].Path)
+}
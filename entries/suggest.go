@@ -0,0 +1,264 @@
+package entries
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// minSuggestLength is the shortest title, in characters, that will be suggested as a link. Shorter titles match too many
+// ordinary words to be useful.
+const minSuggestLength = 3
+
+var (
+	// reCodeBlock matches fenced code blocks and inline code, which shouldn't contain links.
+	reCodeBlock = regexp.MustCompile("(?s)```.*?```|`[^`\n]+`")
+
+	// reMarkdownLink matches normal Markdown links and images, e.g. "[Pizza](https://example.com)".
+	reMarkdownLink = regexp.MustCompile(`!?\[[^\]]*\]\([^\)]*\)`)
+)
+
+// LinkSuggestion is a place where an entry mentions the title of another entry without linking to it.
+type LinkSuggestion struct {
+	// Entry is the entry containing the mention.
+	Entry *Entry `json:"-"`
+
+	// Target is the entry being mentioned.
+	Target *Entry `json:"-"`
+
+	// Path is the path of the entry containing the mention, and TargetPath is the path of the entry being mentioned.
+	Path       string `json:"path"`
+	TargetPath string `json:"targetPath"`
+
+	// Name is the title or alias of the target which was mentioned.
+	Name string `json:"name"`
+
+	// Text is the mention as it appears in the entry, which may differ from Name in case.
+	Text string `json:"text"`
+
+	// Loc is the location of the mention in the entry's contents, so the mention is at Contents[Loc[0]:Loc[1]].
+	Loc []int `json:"loc"`
+
+	// Exact is true if the text matches the name exactly, including case.
+	Exact bool `json:"exact"`
+
+	// Unique is true if the target is the only entry with this name, so a title link would always resolve to it.
+	Unique bool `json:"unique"`
+
+	// Alias is true if the name is one of the target's aliases rather than its title.
+	Alias bool `json:"alias"`
+}
+
+// Link returns the text the mention should be replaced with. Where possible this is a title link like "[[Pizza]]",
+// keeping the original text as the name of the link if it differs. If the name is an alias or is shared by several
+// entries, a path link like "{{food/pizza}(pizza)}" is used instead so the link resolves to the right entry.
+func (s LinkSuggestion) Link() string {
+	switch {
+	case s.Alias || !s.Unique:
+		return fmt.Sprintf("{{%s}(%s)}", s.TargetPath, s.Text)
+	case s.Exact:
+		return fmt.Sprintf("[[%s]]", s.Name)
+	default:
+		return fmt.Sprintf("[[%s](%s)]", s.Name, s.Text)
+	}
+}
+
+// linkCandidate is a name an entry could be linked by.
+type linkCandidate struct {
+	name   string
+	alias  bool
+	re     *regexp.Regexp
+	target []*Entry
+}
+
+// SuggestLinks finds places where the entries in the list mention the title or an alias of another entry in the
+// collection without linking to it. Mentions are matched as whole words, ignoring case, and text which is already a
+// link or is code is skipped. Each entry gets at most one suggestion per entry it mentions, for the first mention, and
+// none for entries it already links to. Where mentions overlap, the longest is suggested.
+//
+// Suggestions are returned in the order of the list, and then in the order they appear in each entry.
+func (collection *Collection) SuggestLinks(list List) []LinkSuggestion {
+	candidates := collection.linkCandidates()
+	suggestions := []LinkSuggestion{}
+
+	for _, entry := range list.Slice() {
+		excluded := excludedRanges(entry.Contents)
+		linked := map[string]bool{}
+
+		for _, link := range entry.OutboundLinks {
+			if link.Store != "" {
+				continue
+			}
+
+			if target := collection.ResolveLink(link); target != nil {
+				linked[target.Path] = true
+			}
+		}
+
+		found := []LinkSuggestion{}
+
+		for _, candidate := range candidates {
+			for _, target := range candidate.target {
+				if target.Path == entry.Path || linked[target.Path] {
+					continue
+				}
+
+				for _, match := range candidate.re.FindAllStringSubmatchIndex(entry.Contents, -1) {
+					loc := match[2:4]
+					if overlaps(loc, excluded) {
+						continue
+					}
+
+					text := entry.Contents[loc[0]:loc[1]]
+
+					found = append(found, LinkSuggestion{
+						Entry:      entry,
+						Target:     target,
+						Path:       entry.Path,
+						TargetPath: target.Path,
+						Name:       candidate.name,
+						Text:       text,
+						Loc:        loc,
+						Exact:      text == candidate.name,
+						Unique:     len(candidate.target) == 1 && !candidate.alias,
+						Alias:      candidate.alias,
+					})
+
+					break
+				}
+			}
+		}
+
+		// Prefer longer mentions, so "Ice Cream" is suggested instead of "Cream".
+		sort.SliceStable(found, func(i, j int) bool {
+			return found[i].Loc[1]-found[i].Loc[0] > found[j].Loc[1]-found[j].Loc[0]
+		})
+
+		kept := []LinkSuggestion{}
+		taken := [][]int{}
+		suggested := map[string]bool{}
+
+		for _, suggestion := range found {
+			if overlaps(suggestion.Loc, taken) || suggested[suggestion.TargetPath] {
+				continue
+			}
+
+			kept = append(kept, suggestion)
+			taken = append(taken, suggestion.Loc)
+			suggested[suggestion.TargetPath] = true
+		}
+
+		sort.SliceStable(kept, func(i, j int) bool { return kept[i].Loc[0] < kept[j].Loc[0] })
+		suggestions = append(suggestions, kept...)
+	}
+
+	return suggestions
+}
+
+// linkCandidates returns every title and alias in the collection which could be suggested as a link.
+func (collection *Collection) linkCandidates() []linkCandidate {
+	byName := map[string]*linkCandidate{}
+	names := []string{}
+
+	add := func(name string, alias bool, entry *Entry) {
+		name = strings.TrimSpace(name)
+		if utf8.RuneCountInString(name) < minSuggestLength || IsStopword(strings.ToLower(name)) {
+			return
+		}
+
+		key := fmt.Sprintf("%t:%s", alias, name)
+		candidate, ok := byName[key]
+		if !ok {
+			candidate = &linkCandidate{
+				name:  name,
+				alias: alias,
+				re:    regexp.MustCompile(`(?i)(?:^|[^\pL\pN_])(` + regexp.QuoteMeta(name) + `)(?:[^\pL\pN_]|$)`),
+			}
+
+			byName[key] = candidate
+			names = append(names, key)
+		}
+
+		candidate.target = append(candidate.target, entry)
+	}
+
+	for _, entry := range collection.pathMap {
+		add(entry.Title, false, entry)
+
+		for _, alias := range entry.Aliases() {
+			add(alias, true, entry)
+		}
+	}
+
+	// Sorted so that suggestions come out in the same order each time.
+	sort.Strings(names)
+
+	candidates := []linkCandidate{}
+	for _, name := range names {
+		candidate := byName[name]
+		sort.Slice(candidate.target, func(i, j int) bool { return candidate.target[i].Path < candidate.target[j].Path })
+
+		candidates = append(candidates, *candidate)
+	}
+
+	return candidates
+}
+
+// excludedRanges returns the locations of text in some contents which shouldn't be turned into links, because it's
+// already a link or it's code.
+func excludedRanges(contents string) [][]int {
+	excluded := [][]int{}
+
+	for _, re := range []*regexp.Regexp{
+		reCodeBlock, reMarkdownLink, reLinkTitleWithName, reLinkPathWithName, reLinkTitleNoName, reLinkPathNoName,
+	} {
+		excluded = append(excluded, re.FindAllStringIndex(contents, -1)...)
+	}
+
+	return excluded
+}
+
+// overlaps returns true if the location overlaps any of the ranges.
+func overlaps(loc []int, ranges [][]int) bool {
+	for _, r := range ranges {
+		if loc[0] < r[1] && r[0] < loc[1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ApplyLinkSuggestions replaces the mentions in an entry with links, returning the new contents of the entry.md file
+// including the front matter. Every suggestion should be for the entry given.
+func ApplyLinkSuggestions(entry *Entry, suggestions []LinkSuggestion) (string, error) {
+	if !strings.HasSuffix(entry.OriginalContents, entry.Contents) {
+		return "", fmt.Errorf("can't find contents of entry %s after front matter", entry.Path)
+	}
+
+	sorted := make([]LinkSuggestion, len(suggestions))
+	copy(sorted, suggestions)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Loc[0] > sorted[j].Loc[0] })
+
+	contents := entry.Contents
+	end := len(contents) + 1
+
+	for _, suggestion := range sorted {
+		if suggestion.Path != entry.Path {
+			return "", fmt.Errorf("suggestion for %s can't be applied to %s", suggestion.Path, entry.Path)
+		}
+
+		if suggestion.Loc[1] > end {
+			return "", fmt.Errorf("suggestions for %s overlap", entry.Path)
+		}
+
+		contents = contents[:suggestion.Loc[0]] + suggestion.Link() + contents[suggestion.Loc[1]:]
+		end = suggestion.Loc[0]
+	}
+
+	frontMatter := entry.OriginalContents[:len(entry.OriginalContents)-len(entry.Contents)]
+
+	return frontMatter + contents, nil
+}
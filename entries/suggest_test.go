@@ -0,0 +1,50 @@
+package entries
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestSuggestLinks(t *testing.T) {
+	collection := NewCollection()
+
+	pizza := dummyEntry("food/pizza", "Pizza", "")
+	iceCream := dummyEntry("food/ice-cream", "Ice Cream", "")
+	cream := dummyEntry("food/cream", "Cream", "")
+	hunger := dummyEntry("moods/hunger", "Hunger", "")
+	hunger.Metadata = map[string]interface{}{"aliases": []interface{}{"Hungry"}}
+
+	journal := &Entry{
+		Path:             "journal/2020-08-06",
+		Title:            "Journal",
+		Contents:         "I was hungry, so I had pizza and ice cream. More Pizza!\n\n`cream` and [[Cream]] aren't suggested.",
+		OriginalContents: "---\ntitle: Journal\n---\n\nI was hungry, so I had pizza and ice cream. More Pizza!\n\n`cream` and [[Cream]] aren't suggested.",
+	}
+	journal.OutboundLinks = []Link{{Title: "Cream", Type: LinkTitleNoName}}
+
+	err := collection.AddMany(pizza, iceCream, cream, hunger, journal)
+	Nil(t, err, "adding entries, err should be nil")
+
+	suggestions := collection.SuggestLinks(List{list: []*Entry{journal}})
+	if !Len(t, suggestions, 3, "there should be one suggestion for each entry mentioned") {
+		return
+	}
+
+	Equal(t, "moods/hunger", suggestions[0].TargetPath)
+	Equal(t, "{{moods/hunger}(hungry)}", suggestions[0].Link(), "aliases should be suggested as path links")
+
+	Equal(t, "food/pizza", suggestions[1].TargetPath)
+	Equal(t, "[[Pizza](pizza)]", suggestions[1].Link(), "the original text should be kept as the name")
+
+	Equal(t, "food/ice-cream", suggestions[2].TargetPath, "the longest mention should be suggested")
+	False(t, suggestions[2].Exact)
+
+	updated, err := ApplyLinkSuggestions(journal, suggestions)
+	Nil(t, err, "applying suggestions, err should be nil")
+	Equal(
+		t,
+		"---\ntitle: Journal\n---\n\nI was {{moods/hunger}(hungry)}, so I had [[Pizza](pizza)] and [[Ice Cream](ice cream)]. More Pizza!\n\n`cream` and [[Cream]] aren't suggested.",
+		updated,
+	)
+}
@@ -0,0 +1,73 @@
+package entries
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopwords are common English words which carry little meaning on their own, so are usually ignored when analysing
+// text.
+var stopwords = map[string]bool{}
+
+func init() {
+	for _, word := range strings.Fields(`
+		a about above after again against all also am an and any are aren't as at be because been before being below
+		between both but by can can't cannot could couldn't did didn't do does doesn't doing don't down during each few
+		for from further get got had hadn't has hasn't have haven't having he he'd he'll he's her here here's hers herself
+		him himself his how how's i i'd i'll i'm i've if in into is isn't it it's its itself just let's like me more most
+		much mustn't my myself no nor not now of off on once one only or other ought our ours ourselves out over own really
+		same shan't she she'd she'll she's should shouldn't so some still such than that that's the their theirs them
+		themselves then there there's these they they'd they'll they're they've this those through to too under until up
+		very was wasn't we we'd we'll we're we've were weren't what what's when when's where where's which while who who's
+		whom why why's will with won't would wouldn't you you'd you'll you're you've your yours yourself yourselves
+	`) {
+		stopwords[word] = true
+	}
+}
+
+// IsStopword returns true if the word, which should be lowercase, is a common English word like "the" or "and" which
+// is usually ignored when analysing text.
+func IsStopword(word string) bool {
+	return stopwords[word]
+}
+
+// Tokenize splits text into lowercase words. A word is a run of letters and digits, which may contain apostrophes
+// and hyphens between letters, such as "don't" or "ice-cream". Everything else, including punctuation and Markdown
+// syntax, separates words. Curly apostrophes are treated the same as straight ones.
+func Tokenize(text string) []string {
+	tokens := []string{}
+	runes := []rune(strings.ToLower(text))
+
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			tokens = append(tokens, string(current))
+			current = current[:0]
+		}
+	}
+
+	for i, r := range runes {
+		if r == '’' {
+			r = '\''
+		}
+
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			current = append(current, r)
+		case (r == '\'' || r == '-') && len(current) > 0 && i+1 < len(runes) && unicode.IsLetter(runes[i+1]):
+			current = append(current, r)
+		default:
+			flush()
+		}
+	}
+
+	flush()
+
+	return tokens
+}
+
+// Tokens returns the words in the entry's plain text, as split by Tokenize.
+func (e *Entry) Tokens() []string {
+	return Tokenize(e.PlainText())
+}
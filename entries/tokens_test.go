@@ -0,0 +1,24 @@
+package entries
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	Equal(
+		t,
+		[]string{"i", "don't", "like", "ice-cream", "it's", "cold", "in", "2020", "café"},
+		Tokenize("I don’t like *ice-cream* -- it's COLD in 2020... Café!"),
+	)
+
+	Equal(t, []string{}, Tokenize("  -- ... '' "), "punctuation on its own shouldn't give any tokens")
+	Equal(t, []string{"rock", "n", "roll"}, Tokenize("rock 'n' roll"), "apostrophes at the edges of words should be dropped")
+}
+
+func TestIsStopword(t *testing.T) {
+	True(t, IsStopword("the"))
+	True(t, IsStopword("don't"))
+	False(t, IsStopword("pizza"))
+}
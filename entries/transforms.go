@@ -0,0 +1,160 @@
+package entries
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Transform changes the contents of an entry.md file before it's parsed, so that entries written for other tools can be
+// read without rewriting the files. Transforms are given to the parser using Limits.Transforms, and only change what's
+// parsed: the entry's OriginalContents is still the file as it is on disk.
+type Transform func(content string) string
+
+// The names of the built-in transforms, as used in the "entries.transforms" list in the config.
+const (
+	// TransformObsidianComments removes Obsidian comments, which are surrounded by "%%" and can span many lines.
+	TransformObsidianComments = "obsidian-comments"
+
+	// TransformEmoji expands emoji shortcodes like ":tada:" into the emoji itself, see Emoji.
+	TransformEmoji = "emoji"
+
+	// TransformSmartQuotes replaces curly quotes like “ and ’ with straight quotes, so quoted values in the front matter
+	// are read properly.
+	TransformSmartQuotes = "smart-quotes"
+)
+
+// transformNames are the names of all the built-in transforms, in the order they're listed in errors.
+var transformNames = []string{TransformObsidianComments, TransformEmoji, TransformSmartQuotes}
+
+// NewTransforms returns the built-in transforms with the names given, in the same order, so that they're applied in the
+// order they're listed in the config. Shortcodes in custom are added to the ones in Emoji for TransformEmoji, replacing
+// any with the same name. An unknown name gives an error.
+func NewTransforms(names []string, custom map[string]string) ([]Transform, error) {
+	transforms := []Transform{}
+
+	for _, name := range names {
+		switch strings.TrimSpace(name) {
+		case TransformObsidianComments:
+			transforms = append(transforms, StripObsidianComments)
+		case TransformEmoji:
+			shortcodes := make(map[string]string, len(Emoji)+len(custom))
+			for shortcode, emoji := range Emoji {
+				shortcodes[shortcode] = emoji
+			}
+
+			for shortcode, emoji := range custom {
+				shortcodes[strings.Trim(shortcode, ":")] = emoji
+			}
+
+			transforms = append(transforms, ExpandEmoji(shortcodes))
+		case TransformSmartQuotes:
+			transforms = append(transforms, StraightenQuotes)
+		default:
+			return nil, fmt.Errorf("unknown transform %q, expected '%s'", name, strings.Join(transformNames, "', '"))
+		}
+	}
+
+	return transforms, nil
+}
+
+// reObsidianComment matches an Obsidian comment, like "%%todo: tidy this up%%".
+var reObsidianComment = regexp.MustCompile(`(?s)%%.*?%%`)
+
+// StripObsidianComments removes Obsidian comments from the content. A "%%" without another to close it is left alone.
+func StripObsidianComments(content string) string {
+	return reObsidianComment.ReplaceAllString(content, "")
+}
+
+// smartQuotes replaces curly quotes with straight ones.
+var smartQuotes = strings.NewReplacer(
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`,
+	"‘", "'", "’", "'", "‚", "'", "‛", "'",
+)
+
+// StraightenQuotes replaces curly single and double quotes in the content with straight ones.
+func StraightenQuotes(content string) string {
+	return smartQuotes.Replace(content)
+}
+
+// reEmoji matches an emoji shortcode like ":tada:" or ":+1:". Group 1 is its name.
+var reEmoji = regexp.MustCompile(`:([a-z0-9_+-]+):`)
+
+// ExpandEmoji returns a transform which replaces emoji shortcodes, like ":tada:", with the emoji given for them in the
+// map, which is by name without the colons. Shortcodes which aren't in the map are left alone.
+func ExpandEmoji(shortcodes map[string]string) Transform {
+	return func(content string) string {
+		return reEmoji.ReplaceAllStringFunc(content, func(match string) string {
+			if emoji, ok := shortcodes[match[1:len(match)-1]]; ok {
+				return emoji
+			}
+
+			return match
+		})
+	}
+}
+
+// Emoji are the shortcodes expanded by TransformEmoji by default, by name without the colons. They're a common subset of
+// the ones used by GitHub and Slack. More can be added using "entries.emoji" in the config.
+var Emoji = map[string]string{
+	"+1":               "👍",
+	"-1":               "👎",
+	"thumbsup":         "👍",
+	"thumbsdown":       "👎",
+	"smile":            "😄",
+	"smiley":           "😃",
+	"grin":             "😁",
+	"joy":              "😂",
+	"wink":             "😉",
+	"blush":            "😊",
+	"heart_eyes":       "😍",
+	"thinking":         "🤔",
+	"neutral_face":     "😐",
+	"confused":         "😕",
+	"cry":              "😢",
+	"sob":              "😭",
+	"angry":            "😠",
+	"scream":           "😱",
+	"sleeping":         "😴",
+	"sunglasses":       "😎",
+	"heart":            "❤️",
+	"broken_heart":     "💔",
+	"star":             "⭐",
+	"sparkles":         "✨",
+	"fire":             "🔥",
+	"tada":             "🎉",
+	"rocket":           "🚀",
+	"bulb":             "💡",
+	"memo":             "📝",
+	"book":             "📖",
+	"books":            "📚",
+	"calendar":         "📅",
+	"pushpin":          "📌",
+	"link":             "🔗",
+	"warning":          "⚠️",
+	"x":                "❌",
+	"white_check_mark": "✅",
+	"heavy_check_mark": "✔️",
+	"question":         "❓",
+	"exclamation":      "❗",
+	"eyes":             "👀",
+	"wave":             "👋",
+	"clap":             "👏",
+	"pray":             "🙏",
+	"muscle":           "💪",
+	"coffee":           "☕",
+	"pizza":            "🍕",
+	"sunny":            "☀️",
+	"cloud":            "☁️",
+	"umbrella":         "☔",
+	"snowflake":        "❄️",
+	"zap":              "⚡",
+	"bug":              "🐛",
+	"construction":     "🚧",
+	"lock":             "🔒",
+	"key":              "🔑",
+	"moneybag":         "💰",
+	"hourglass":        "⌛",
+	"alarm_clock":      "⏰",
+	"100":              "💯",
+}
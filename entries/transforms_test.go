@@ -0,0 +1,49 @@
+package entries
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestTransforms(t *testing.T) {
+	Equal(t, "Before  after.\nDone.", StripObsidianComments("Before %%a comment%% after.\n%%one\ntwo%%Done."))
+	Equal(t, "50% and 100%% not closed", StripObsidianComments("50% and 100%% not closed"))
+
+	Equal(t, `"Quoted" and 'single' isn't`, StraightenQuotes("“Quoted” and ‘single’ isn’t"))
+
+	expand := ExpandEmoji(map[string]string{"tada": "🎉", "+1": "👍"})
+	Equal(t, "Done 🎉 👍 :unknown: at 12:30:45", expand("Done :tada: :+1: :unknown: at 12:30:45"))
+
+	transforms, err := NewTransforms([]string{TransformSmartQuotes, TransformEmoji}, map[string]string{":shipit:": "🐿️", "tada": "🥳"})
+	if err != nil {
+		t.Fatalf("not expecting error creating transforms: %s", err)
+	}
+
+	if Len(t, transforms, 2) {
+		Equal(t, "'Yes'", transforms[0]("‘Yes’"))
+		Equal(t, "🐿️ 🥳 🔥", transforms[1](":shipit: :tada: :fire:"), "expecting custom shortcodes to be added to and replace the defaults")
+	}
+
+	_, err = NewTransforms([]string{"obsidian-comments", "roam-blocks"}, nil)
+	NotNil(t, err, "expecting error for an unknown transform")
+}
+
+func TestParseTransforms(t *testing.T) {
+	parser := newTestParser(t).WithLimits(Limits{Transforms: []Transform{StripObsidianComments, StraightenQuotes}})
+
+	content := `---
+title: “Pizza”
+---
+
+Pizza is great. %%Needs more on toppings.%%`
+
+	entry, err := parser.Parse("food/pizza", content)
+	if err != nil {
+		t.Fatalf("not expecting error parsing entry: %s", err)
+	}
+
+	Equal(t, "Pizza", entry.Title, "expecting quotes in the front matter to be straightened")
+	Equal(t, "Pizza is great. ", entry.Contents)
+	Equal(t, content, entry.OriginalContents, "expecting the original contents not to be transformed")
+}
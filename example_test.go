@@ -0,0 +1,157 @@
+package albatross_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	albatross "github.com/albatross-org/go-albatross"
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// exampleStore creates an empty store in a temporary folder, returning its path.
+func exampleStore() string {
+	dir, err := ioutil.TempDir("", "albatross-example")
+	if err != nil {
+		panic(err)
+	}
+
+	err = os.Mkdir(filepath.Join(dir, "entries"), 0755)
+	if err != nil {
+		panic(err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte("dates:\n  format: \"2006-01-02 15:04\"\n"), 0644)
+	if err != nil {
+		panic(err)
+	}
+
+	return dir
+}
+
+func ExampleLoad() {
+	dir := exampleStore()
+	defer os.RemoveAll(dir)
+
+	store, err := albatross.Load(dir)
+	if err != nil {
+		fmt.Println("Couldn't load store:", err)
+		return
+	}
+
+	collection, err := store.Collection()
+	if err != nil {
+		fmt.Println("Couldn't get entries:", err)
+		return
+	}
+
+	fmt.Println(collection.Len(), "entries")
+
+	// Output:
+	// 0 entries
+}
+
+func ExampleStore_Create() {
+	dir := exampleStore()
+	defer os.RemoveAll(dir)
+
+	store, err := albatross.Load(dir)
+	if err != nil {
+		fmt.Println("Couldn't load store:", err)
+		return
+	}
+
+	err = store.Create("food/pizza", "---\ntitle: Pizza\ndate: 2020-08-06 18:24\n---\n\nPizza is great. @?food")
+	if err != nil {
+		fmt.Println("Couldn't create entry:", err)
+		return
+	}
+
+	err = store.Create("food/pizza", "Pizza is still great.")
+	if _, ok := err.(albatross.ErrEntryAlreadyExists); ok {
+		fmt.Println("food/pizza already exists")
+	}
+
+	collection, err := store.Collection()
+	if err != nil {
+		fmt.Println("Couldn't get entries:", err)
+		return
+	}
+
+	pizza := collection.Get("food/pizza")
+	fmt.Println(pizza.Title, pizza.Date.Format("2006-01-02"), pizza.Tags)
+
+	// Output:
+	// food/pizza already exists
+	// Pizza 2020-08-06 [@?food]
+}
+
+func ExampleStore_Collection() {
+	dir := exampleStore()
+	defer os.RemoveAll(dir)
+
+	store, err := albatross.Load(dir)
+	if err != nil {
+		fmt.Println("Couldn't load store:", err)
+		return
+	}
+
+	store.Create("food/pizza", "---\ntitle: Pizza\n---\n\nPizza is great.")
+	store.Create("food/chips", "---\ntitle: Chips\n---\n\nChips are great.")
+	store.Create("moods/hunger", "---\ntitle: Hunger\n---\n\nI want {{food/pizza}}.")
+
+	collection, err := store.Collection()
+	if err != nil {
+		fmt.Println("Couldn't get entries:", err)
+		return
+	}
+
+	food, err := collection.Filter(entries.FilterPathsMatch("food/"))
+	if err != nil {
+		fmt.Println("Couldn't filter entries:", err)
+		return
+	}
+
+	for _, entry := range food.List().Sort(entries.SortAlpha).Slice() {
+		fmt.Println(entry.Title, len(collection.Backlinks(entry)))
+	}
+
+	// Output:
+	// Chips 0
+	// Pizza 1
+}
+
+// countEntries only needs to read the store, so it takes a Reader rather than a Store, which makes it easier to test.
+func countEntries(store albatross.Reader) (int, error) {
+	collection, err := store.Collection()
+	if err != nil {
+		return 0, err
+	}
+
+	return collection.Len(), nil
+}
+
+func ExampleReader() {
+	dir := exampleStore()
+	defer os.RemoveAll(dir)
+
+	store, err := albatross.Load(dir)
+	if err != nil {
+		fmt.Println("Couldn't load store:", err)
+		return
+	}
+
+	store.Create("food/pizza", "---\ntitle: Pizza\n---\n\nPizza is great.")
+
+	n, err := countEntries(store)
+	if err != nil {
+		fmt.Println("Couldn't count entries:", err)
+		return
+	}
+
+	fmt.Println(n, "entries")
+
+	// Output:
+	// 1 entries
+}
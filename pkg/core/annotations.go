@@ -0,0 +1,247 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"gopkg.in/yaml.v2"
+)
+
+// AnnotationsFile is the name of the file in an entry's folder which holds its annotations.
+const AnnotationsFile = "annotations.yaml"
+
+// Annotation is a note attached to an entry, or to some of its lines, without changing the entry itself. This is
+// useful for commenting on old entries, like journal entries, while leaving what was written at the time alone.
+type Annotation struct {
+	// ID identifies the annotation within the entry.
+	ID int `yaml:"id" json:"id"`
+
+	// Text is the annotation itself.
+	Text string `yaml:"text" json:"text"`
+
+	// StartLine and EndLine are the lines of the entry the annotation is about, counted from 1 at the start of the entry's
+	// contents, not including front matter. If they're both zero, the annotation is about the whole entry.
+	StartLine int `yaml:"start-line,omitempty" json:"startLine"`
+	EndLine   int `yaml:"end-line,omitempty" json:"endLine"`
+
+	// Created is when the annotation was made.
+	Created time.Time `yaml:"created" json:"created"`
+}
+
+// Lines describes the lines the annotation is about, such as "line 3" or "lines 3-5". It's blank if the annotation is
+// about the whole entry.
+func (a Annotation) Lines() string {
+	switch {
+	case a.StartLine == 0:
+		return ""
+	case a.StartLine == a.EndLine:
+		return fmt.Sprintf("line %d", a.StartLine)
+	default:
+		return fmt.Sprintf("lines %d-%d", a.StartLine, a.EndLine)
+	}
+}
+
+// Annotations returns the annotations on the entry at the path given, in the order they were made. If the store is
+// encrypted, it returns ErrStoreEncrypted.
+func (s *Store) Annotations(path string) ([]Annotation, error) {
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return nil, err
+	} else if encrypted {
+		return nil, ErrStoreEncrypted{Path: s.Path}
+	}
+
+	entryDir := filepath.Join(s.entriesPath, path)
+	if !exists(filepath.Join(entryDir, "entry.md")) {
+		return nil, ErrEntryDoesntExist{Path: entryDir}
+	}
+
+	return readAnnotations(entryDir)
+}
+
+// Annotate adds an annotation with the text given to the entry at the path given, about the lines from startLine to
+// endLine, or the whole entry if they're both zero. It returns the new annotation. If the store is encrypted, it
+// returns ErrStoreEncrypted.
+func (s *Store) Annotate(path, text string, startLine, endLine int) (annotation Annotation, err error) {
+	relPath := path
+	defer func() { s.recordAudit("annotate", err, relPath) }()
+
+	annotations, err := s.Annotations(path)
+	if err != nil {
+		return annotation, err
+	}
+
+	entryDir := filepath.Join(s.entriesPath, path)
+
+	if startLine != 0 || endLine != 0 {
+		contents, err := ioutil.ReadFile(filepath.Join(entryDir, "entry.md"))
+		if err != nil {
+			return annotation, err
+		}
+
+		entry, err := entries.NewEntryFromContents(path, contents, time.Now(), s.limits())
+		if err != nil {
+			return annotation, err
+		}
+
+		lines := strings.Count(entry.Contents, "\n") + 1
+		if startLine < 1 || endLine < startLine || endLine > lines {
+			return annotation, fmt.Errorf("invalid lines %d-%d, entry %s has %d lines", startLine, endLine, path, lines)
+		}
+	}
+
+	annotation = Annotation{
+		ID:        1,
+		Text:      text,
+		StartLine: startLine,
+		EndLine:   endLine,
+		Created:   time.Now(),
+	}
+
+	for _, existing := range annotations {
+		if existing.ID >= annotation.ID {
+			annotation.ID = existing.ID + 1
+		}
+	}
+
+	err = writeAnnotations(entryDir, append(annotations, annotation))
+	if err != nil {
+		return annotation, err
+	}
+
+	err = s.recordChange(relPath, "Annotate %s", relPath)
+	if err != nil {
+		return annotation, err
+	}
+
+	return annotation, nil
+}
+
+// RemoveAnnotation removes the annotation with the ID given from the entry at the path given. If there isn't one, it
+// returns ErrAnnotationDoesntExist. If the store is encrypted, it returns ErrStoreEncrypted.
+func (s *Store) RemoveAnnotation(path string, id int) (err error) {
+	relPath := path
+	defer func() { s.recordAudit("remove-annotation", err, relPath) }()
+
+	annotations, err := s.Annotations(path)
+	if err != nil {
+		return err
+	}
+
+	kept := []Annotation{}
+	for _, annotation := range annotations {
+		if annotation.ID != id {
+			kept = append(kept, annotation)
+		}
+	}
+
+	if len(kept) == len(annotations) {
+		return ErrAnnotationDoesntExist{Path: path, ID: id}
+	}
+
+	err = writeAnnotations(filepath.Join(s.entriesPath, path), kept)
+	if err != nil {
+		return err
+	}
+
+	return s.recordChange(relPath, "Remove annotation %d from %s", id, relPath)
+}
+
+// InsertAnnotations returns the contents of an entry with its annotations added as blockquotes. Annotations about some
+// lines are put after the last of those lines, and annotations about the whole entry are put at the end.
+func InsertAnnotations(contents string, annotations []Annotation) string {
+	if len(annotations) == 0 {
+		return contents
+	}
+
+	trimmed := strings.TrimRight(contents, "\n")
+	lines := strings.Split(trimmed, "\n")
+	after := make(map[int][]Annotation)
+
+	for _, annotation := range annotations {
+		line := annotation.EndLine
+		if line == 0 || line > len(lines) {
+			line = len(lines)
+		}
+
+		after[line] = append(after[line], annotation)
+	}
+
+	out := []string{}
+
+	for i, line := range lines {
+		out = append(out, line)
+
+		// Blockquotes need blank lines around them, otherwise the next line would be part of the quote.
+		for _, annotation := range after[i+1] {
+			if out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+
+			out = append(out, formatAnnotation(annotation), "")
+		}
+	}
+
+	if out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+
+	return strings.Join(out, "\n") + contents[len(trimmed):]
+}
+
+// formatAnnotation formats an annotation as a markdown blockquote.
+func formatAnnotation(annotation Annotation) string {
+	heading := "Annotation"
+	if lines := annotation.Lines(); lines != "" {
+		heading += " on " + lines
+	}
+
+	heading += ", " + annotation.Created.Format("2006-01-02")
+
+	quoted := []string{fmt.Sprintf("> **%s:**", heading)}
+	for _, line := range strings.Split(strings.TrimSpace(annotation.Text), "\n") {
+		quoted = append(quoted, strings.TrimRight("> "+line, " "))
+	}
+
+	return strings.Join(quoted, "\n")
+}
+
+// readAnnotations reads the annotations in an entry's folder. If there are none, it returns an empty slice.
+func readAnnotations(entryDir string) ([]Annotation, error) {
+	annotations := []Annotation{}
+
+	data, err := ioutil.ReadFile(filepath.Join(entryDir, AnnotationsFile))
+	if os.IsNotExist(err) {
+		return annotations, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(data, &annotations)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse annotations in %s: %w", entryDir, err)
+	}
+
+	return annotations, nil
+}
+
+// writeAnnotations writes the annotations to an entry's folder, removing the file if there are none left.
+func writeAnnotations(entryDir string, annotations []Annotation) error {
+	path := filepath.Join(entryDir, AnnotationsFile)
+
+	if len(annotations) == 0 {
+		return os.Remove(path)
+	}
+
+	data, err := yaml.Marshal(annotations)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreAnnotations(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	before, err := store.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error getting collection: %s", err)
+	}
+	count := before.Len()
+
+	annotations, err := store.Annotations("food/pizza")
+	Nil(t, err, "not expecting error getting annotations for entry without any")
+	Equal(t, 0, len(annotations))
+
+	_, err = store.Annotations("food/burger")
+	IsType(t, ErrEntryDoesntExist{}, err, "expecting error for entry which doesn't exist")
+
+	first, err := store.Annotate("food/pizza", "Still true.", 0, 0)
+	Nil(t, err, "not expecting error annotating whole entry")
+	Equal(t, 1, first.ID)
+
+	second, err := store.Annotate("food/pizza", "Which pizza was this?", 3, 3)
+	Nil(t, err, "not expecting error annotating a line")
+	Equal(t, 2, second.ID)
+	Equal(t, "line 3", second.Lines())
+
+	_, err = store.Annotate("food/pizza", "Out of range.", 2, 100)
+	NotNil(t, err, "expecting error annotating lines past the end of the entry")
+
+	annotations, err = store.Annotations("food/pizza")
+	Nil(t, err)
+	if Len(t, annotations, 2) {
+		Equal(t, "Still true.", annotations[0].Text)
+		Equal(t, 3, annotations[1].StartLine)
+	}
+
+	err = store.reload()
+	Nil(t, err)
+
+	collection, err := store.Collection()
+	Nil(t, err)
+	Equal(t, count, collection.Len(), "expecting annotations not to be parsed as entries")
+
+	err = store.RemoveAnnotation("food/pizza", 5)
+	IsType(t, ErrAnnotationDoesntExist{}, err, "expecting error removing annotation which doesn't exist")
+
+	err = store.RemoveAnnotation("food/pizza", 1)
+	Nil(t, err, "not expecting error removing annotation")
+
+	err = store.RemoveAnnotation("food/pizza", 2)
+	Nil(t, err, "not expecting error removing annotation")
+
+	_, err = os.Stat(filepath.Join(store.entriesPath, "food", "pizza", AnnotationsFile))
+	True(t, os.IsNotExist(err), "expecting annotations file to be removed once there are none left")
+
+	events, err := store.AuditLog(time.Time{})
+	Nil(t, err)
+	Equal(t, "annotate", events[0].Operation)
+	Equal(t, "remove-annotation", events[len(events)-1].Operation)
+}
+
+func TestInsertAnnotations(t *testing.T) {
+	created := time.Date(2020, 8, 6, 0, 0, 0, 0, time.UTC)
+
+	contents := "First line.\nSecond line.\nThird line.\n"
+	annotations := []Annotation{
+		{ID: 1, Text: "About everything.", Created: created},
+		{ID: 2, Text: "About the first two lines.\nOn two lines.", StartLine: 1, EndLine: 2, Created: created},
+		{ID: 3, Text: "About the second line.", StartLine: 2, EndLine: 2, Created: created},
+	}
+
+	expected := `First line.
+Second line.
+
+> **Annotation on lines 1-2, 2020-08-06:**
+> About the first two lines.
+> On two lines.
+
+> **Annotation on line 2, 2020-08-06:**
+> About the second line.
+
+Third line.
+
+> **Annotation, 2020-08-06:**
+> About everything.
+`
+
+	Equal(t, expected, InsertAnnotations(contents, annotations))
+	Equal(t, contents, InsertAnnotations(contents, nil), "expecting contents to be unchanged without annotations")
+}
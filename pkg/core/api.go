@@ -0,0 +1,48 @@
+package core
+
+import (
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// Reader is the part of a Store used to read entries and their attachments. It's part of the v1 API: methods won't be
+// removed from it or have their signatures changed.
+type Reader interface {
+	// Collection returns the entries in the store, or ErrStoreEncrypted if it's encrypted.
+	Collection() (*entries.Collection, error)
+
+	// Attachments returns the names of the files attached to the entry at the path given.
+	Attachments(path string) ([]string, error)
+
+	// LastModified returns when the entry at the path given was last changed according to git, or the zero time if
+	// that isn't known.
+	LastModified(path string) (time.Time, error)
+
+	// Encrypted returns true if the store is encrypted.
+	Encrypted() (bool, error)
+}
+
+// Writer is the part of a Store used to change entries. Each change is committed if the store uses git. It's part of
+// the v1 API: methods won't be removed from it or have their signatures changed.
+type Writer interface {
+	// Create creates an entry at the path given, or returns ErrEntryAlreadyExists if there already is one.
+	Create(path, content string) error
+
+	// Update replaces the contents of the entry at the path given, or returns ErrEntryDoesntExist if there isn't one.
+	Update(path, content string) error
+
+	// Attach copies the file at attachmentPath into the folder of the entry at the path given.
+	Attach(path, attachmentPath string) error
+
+	// Delete deletes the entry at the path given along with its attachments.
+	Delete(path string) error
+}
+
+// ReadWriter is a Reader and a Writer, which a Store is.
+type ReadWriter interface {
+	Reader
+	Writer
+}
+
+var _ ReadWriter = (*Store)(nil)
@@ -0,0 +1,258 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// Attachments returns the attachments of the entry at the path given, as slash-separated paths relative to the entry's
+// folder, such as "pizza.jpg" or "photos/slice.jpg". Folders containing other entries, hidden files and the annotations
+// file aren't included. If the store is encrypted it returns ErrStoreEncrypted, and if the entry doesn't exist it returns
+// ErrEntryDoesntExist.
+func (s *Store) Attachments(path string) ([]string, error) {
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return nil, err
+	} else if encrypted {
+		return nil, ErrStoreEncrypted{Path: s.Path}
+	}
+
+	dir := filepath.Join(s.entriesPath, filepath.FromSlash(path))
+	if !exists(filepath.Join(dir, "entry.md")) {
+		return nil, ErrEntryDoesntExist{Path: path}
+	}
+
+	attachments := []string{}
+
+	err = filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if file == dir {
+			return nil
+		}
+
+		if strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if info.IsDir() {
+			if exists(filepath.Join(file, "entry.md")) {
+				return filepath.SkipDir // A sub-entry, which has its own attachments.
+			}
+
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+		if rel == "entry.md" || rel == AnnotationsFile {
+			return nil
+		}
+
+		attachments = append(attachments, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(attachments)
+
+	return attachments, nil
+}
+
+// Ways of finding the date of a file when planning an attachment import, see PlanAttachmentImport.
+const (
+	// AttachByExifDate uses the date a photo was taken from its EXIF metadata, falling back to when the file was last
+	// modified for files without one.
+	AttachByExifDate = "exif-date"
+
+	// AttachByModTime uses when the file was last modified.
+	AttachByModTime = "mtime"
+)
+
+// PlannedAttachment is a file which PlanAttachmentImport has matched to an entry, or couldn't match to one.
+type PlannedAttachment struct {
+	// File is the path to the file.
+	File string `json:"file"`
+
+	// Date is the date of the file, as shown on a clock where it was taken or modified.
+	Date time.Time `json:"date"`
+
+	// DateSource is where Date came from, "exif" or "mtime".
+	DateSource string `json:"dateSource"`
+
+	// Entry is the path of the entry the file will be attached to, or blank if there isn't one.
+	Entry string `json:"entry,omitempty"`
+
+	// Skip is why the file won't be attached, such as there not being an entry on the same day. It's blank for files
+	// which will be.
+	Skip string `json:"skip,omitempty"`
+}
+
+// PlanAttachmentImport works out which entries in the list the files given should be attached to, by finding the entry
+// on the same day as each file, using by to decide the date of each file. If there's more than one entry on the same
+// day, the one closest in time is used. Dates are compared as they'd be shown on a clock, since photos don't usually
+// record the time zone they were taken in. Files are skipped if there isn't an entry on the same day, or if the entry
+// already has an attachment with the same name.
+func (s *Store) PlanAttachmentImport(list entries.List, files []string, by string) ([]PlannedAttachment, error) {
+	if by != AttachByExifDate && by != AttachByModTime {
+		return nil, fmt.Errorf("unknown way of dating files %q, expected %q or %q", by, AttachByExifDate, AttachByModTime)
+	}
+
+	days := map[string][]*entries.Entry{}
+	for _, entry := range list.Slice() {
+		if entry.Date.IsZero() {
+			continue
+		}
+
+		day := entry.Date.Format("2006-01-02")
+		days[day] = append(days[day], entry)
+	}
+
+	// names are the names of the attachments each entry has or will have, so that two files with the same name aren't
+	// both attached to the same entry.
+	names := map[string]map[string]bool{}
+
+	plan := []PlannedAttachment{}
+
+	for _, file := range files {
+		planned := PlannedAttachment{File: file}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+
+		planned.Date = entries.ClockTime(info.ModTime().Local())
+		planned.DateSource = "mtime"
+
+		if by == AttachByExifDate {
+			date, ok, err := ExifDate(file)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't read EXIF metadata from %s: %w", file, err)
+			} else if ok {
+				planned.Date = date
+				planned.DateSource = "exif"
+			}
+		}
+
+		candidates := days[planned.Date.Format("2006-01-02")]
+		if len(candidates) == 0 {
+			planned.Skip = "no entry on " + planned.Date.Format("2006-01-02")
+			plan = append(plan, planned)
+			continue
+		}
+
+		closest := candidates[0]
+		for _, entry := range candidates[1:] {
+			if absDuration(entries.ClockTime(entry.Date).Sub(planned.Date)) < absDuration(entries.ClockTime(closest.Date).Sub(planned.Date)) {
+				closest = entry
+			}
+		}
+
+		planned.Entry = closest.Path
+
+		if names[closest.Path] == nil {
+			names[closest.Path] = map[string]bool{}
+
+			existing, err := s.Attachments(closest.Path)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, name := range existing {
+				names[closest.Path][name] = true
+			}
+		}
+
+		name := filepath.Base(file)
+		if names[closest.Path][name] {
+			planned.Skip = fmt.Sprintf("%s already has an attachment called %s", closest.Path, name)
+		} else {
+			names[closest.Path][name] = true
+		}
+
+		plan = append(plan, planned)
+	}
+
+	return plan, nil
+}
+
+// ImportAttachments attaches the files in a plan from PlanAttachmentImport to their entries, skipping those which have
+// no entry or a reason to be skipped. Unlike Attach, all the changes are committed together. If the store is encrypted,
+// it returns ErrStoreEncrypted.
+func (s *Store) ImportAttachments(plan []PlannedAttachment) (err error) {
+	paths := []string{}
+	defer func() { s.recordAudit("attach", err, paths...) }()
+
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return err
+	} else if encrypted {
+		return ErrStoreEncrypted{Path: s.Path}
+	}
+
+	changed := map[string]bool{}
+	attached := 0
+
+	for _, planned := range plan {
+		if planned.Entry == "" || planned.Skip != "" {
+			continue
+		}
+
+		err = s.copyAttachment(planned.Entry, planned.File)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := changed[planned.Entry]; !ok {
+			changed[planned.Entry] = false
+			paths = append(paths, planned.Entry)
+		}
+
+		attached++
+	}
+
+	if attached == 0 {
+		return nil
+	}
+
+	sort.Strings(paths)
+
+	if s.repo != nil && !s.disableGit {
+		message := fmt.Sprintf("(go-albatross) Attach %d files to %d entries\n\nAttached files to:\n- %s\n", attached, len(paths), strings.Join(paths, "\n- "))
+
+		err = s.commitPaths(paths, message)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.refreshPaths(changed)
+}
+
+// absDuration returns the absolute value of a duration.
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreAttachments(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	err = store.Create("food/pizza/toppings", "Pineapple.")
+	if err != nil {
+		t.Fatalf("not expecting error when creating sub-entry: %s", err)
+	}
+
+	pizzaDir := filepath.Join(store.entriesPath, "food", "pizza")
+
+	err = os.MkdirAll(filepath.Join(pizzaDir, "photos"), 0755)
+	if err != nil {
+		t.Fatalf("not expecting error creating folder: %s", err)
+	}
+
+	for _, name := range []string{filepath.Join("photos", "slice.jpg"), ".hidden", filepath.Join("toppings", "pineapple.jpg")} {
+		err = ioutil.WriteFile(filepath.Join(pizzaDir, name), []byte("data"), 0644)
+		if err != nil {
+			t.Fatalf("not expecting error writing attachment: %s", err)
+		}
+	}
+
+	attachments, err := store.Attachments("food/pizza")
+	if err != nil {
+		t.Fatalf("not expecting error listing attachments: %s", err)
+	}
+
+	Equal(t, []string{"photos/slice.jpg", "pizza.jpg"}, attachments, "expecting sub-entries and hidden files to be left out")
+
+	attachments, err = store.Attachments("moods/hunger")
+	Nil(t, err)
+	Empty(t, attachments)
+
+	_, err = store.Attachments("food/nonexistent")
+	IsType(t, ErrEntryDoesntExist{}, err)
+}
+
+func TestStoreImportAttachments(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	photos := filepath.Join(dir, "photos")
+
+	err = os.MkdirAll(photos, 0755)
+	if err != nil {
+		t.Fatalf("not expecting error creating folder: %s", err)
+	}
+
+	files := map[string][]byte{
+		"dessert.jpg": exifJPEG("2020:08:06 18:30:00"), // Closer to food/ice-cream at 18:31 than food/pizza at 18:24.
+		"pizza.jpg":   exifJPEG("2020:08:06 18:20:00"), // food/pizza already has a pizza.jpg.
+		"evening.jpg": exifJPEG("2020:08:07 22:00:00"),
+		"later.jpg":   exifJPEG("2021:01:01 12:00:00"),
+		"notes.txt":   []byte("Breakfast."),
+	}
+
+	paths := []string{}
+	for name, data := range files {
+		path := filepath.Join(photos, name)
+		paths = append(paths, path)
+
+		err = ioutil.WriteFile(path, data, 0644)
+		if err != nil {
+			t.Fatalf("not expecting error writing %s: %s", name, err)
+		}
+	}
+
+	breakfast := time.Date(2020, 8, 8, 10, 0, 0, 0, time.Local)
+	err = os.Chtimes(filepath.Join(photos, "notes.txt"), breakfast, breakfast)
+	if err != nil {
+		t.Fatalf("not expecting error changing modification time: %s", err)
+	}
+
+	collection, err := store.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error getting collection: %s", err)
+	}
+
+	_, err = store.PlanAttachmentImport(collection.List(), paths, "size")
+	NotNil(t, err, "expecting an error for an unknown way of dating files")
+
+	plan, err := store.PlanAttachmentImport(collection.List(), paths, AttachByExifDate)
+	if err != nil {
+		t.Fatalf("not expecting error planning import: %s", err)
+	}
+
+	byName := map[string]PlannedAttachment{}
+	for _, planned := range plan {
+		byName[filepath.Base(planned.File)] = planned
+	}
+
+	Equal(t, "food/ice-cream", byName["dessert.jpg"].Entry, "expecting the closest entry on the same day")
+	Equal(t, "exif", byName["dessert.jpg"].DateSource)
+	Equal(t, "", byName["dessert.jpg"].Skip)
+	Equal(t, "food/pizza", byName["pizza.jpg"].Entry)
+	NotEqual(t, "", byName["pizza.jpg"].Skip, "expecting a file with the same name as an existing attachment to be skipped")
+	Equal(t, "journal/2020-08-07", byName["evening.jpg"].Entry)
+	Equal(t, "", byName["later.jpg"].Entry)
+	Equal(t, "no entry on 2021-01-01", byName["later.jpg"].Skip)
+	Equal(t, "journal/2020-08-08", byName["notes.txt"].Entry, "expecting files without EXIF metadata to use their modification time")
+	Equal(t, "mtime", byName["notes.txt"].DateSource)
+
+	plan, err = store.PlanAttachmentImport(collection.List(), paths, AttachByModTime)
+	if err != nil {
+		t.Fatalf("not expecting error planning import: %s", err)
+	}
+
+	for _, planned := range plan {
+		Equal(t, "mtime", planned.DateSource, "expecting %s to be dated by its modification time", planned.File)
+	}
+
+	plan, err = store.PlanAttachmentImport(collection.List(), paths, AttachByExifDate)
+	if err != nil {
+		t.Fatalf("not expecting error planning import: %s", err)
+	}
+
+	err = store.ImportAttachments(plan)
+	if err != nil {
+		t.Fatalf("not expecting error importing attachments: %s", err)
+	}
+
+	attachments, err := store.Attachments("food/ice-cream")
+	Nil(t, err)
+	Contains(t, attachments, "dessert.jpg")
+
+	attachments, err = store.Attachments("journal/2020-08-08")
+	Nil(t, err)
+	Contains(t, attachments, "notes.txt")
+
+	attachments, err = store.Attachments("food/pizza")
+	Nil(t, err)
+	Equal(t, []string{"pizza.jpg"}, attachments, "expecting the skipped file not to replace the existing attachment")
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AuditEvent is a single record in the audit log. One is written for every operation which attempts to modify the store,
+// whether or not it succeeds.
+type AuditEvent struct {
+	// Time is when the operation finished.
+	Time time.Time `json:"time"`
+
+	// Operation is the name of the operation, such as "create" or "encrypt".
+	Operation string `json:"operation"`
+
+	// Command is the command that caused the operation, by default the arguments the program was started with.
+	Command string `json:"command"`
+
+	// Paths are the entries affected by the operation, relative to the entries folder.
+	Paths []string `json:"paths"`
+
+	// Result is either "ok" or "error".
+	Result string `json:"result"`
+
+	// Error is the error message if Result is "error".
+	Error string `json:"error,omitempty"`
+}
+
+// auditLogPath returns the path to the audit log for the store.
+func (s *Store) auditLogPath() string {
+	return filepath.Join(s.Path, ".albatross", "audit.log")
+}
+
+// SetCommand sets the command recorded in the audit log for subsequent operations.
+func (s *Store) SetCommand(command string) {
+	s.command = command
+}
+
+// AuditLog returns all the events in the audit log which happened at or after the time given. A zero time returns
+// every event. If there is no audit log yet, it returns an empty slice.
+func (s *Store) AuditLog(since time.Time) ([]AuditEvent, error) {
+	events := []AuditEvent{}
+
+	f, err := os.Open(s.auditLogPath())
+	if os.IsNotExist(err) {
+		return events, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't open audit log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var event AuditEvent
+		err = json.Unmarshal(scanner.Bytes(), &event)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse line %d of audit log: %w", line, err)
+		}
+
+		if event.Time.Before(since) {
+			continue
+		}
+
+		events = append(events, event)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't read audit log: %w", err)
+	}
+
+	return events, nil
+}
+
+// recordAudit appends an event to the audit log. The log is append-only and is written regardless of whether git is
+// being used. Failing to write to the log doesn't cause the operation itself to fail, but a warning is logged.
+func (s *Store) recordAudit(operation string, opErr error, paths ...string) {
+	event := AuditEvent{
+		Time:      time.Now(),
+		Operation: operation,
+		Command:   s.command,
+		Paths:     paths,
+		Result:    "ok",
+	}
+
+	if opErr != nil {
+		event.Result = "error"
+		event.Error = opErr.Error()
+	}
+
+	err := appendAuditEvent(s.auditLogPath(), event)
+	if err != nil {
+		logrus.Warnf("Couldn't write to audit log: %s", err)
+	}
+}
+
+// appendAuditEvent writes a single event as a line of JSON to the end of the file at path, creating it if necessary.
+func appendAuditEvent(path string, event AuditEvent) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Modified returns when the store was last changed by an operation recorded in the audit log, such as creating an entry
+// or decrypting the store. It doesn't notice changes made to the entries by hand. If nothing has been recorded yet, it
+// returns the zero time.
+func (s *Store) Modified() (time.Time, error) {
+	stat, err := os.Stat(s.auditLogPath())
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+
+	return stat.ModTime(), nil
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreAuditLog(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	store.SetCommand("albatross create food/truffles")
+
+	events, err := store.AuditLog(time.Time{})
+	Nil(t, err, "not expecting error reading a non-existent audit log")
+	Equal(t, 0, len(events), "audit log should start empty")
+
+	start := time.Now()
+
+	err = store.Create("food/truffles", "Truffles are great.")
+	Nil(t, err, "not expecting error when creating truffles entry")
+
+	err = store.Create("food/truffles", "Truffles are great.")
+	NotNil(t, err, "expecting error when creating truffles entry twice")
+
+	events, err = store.AuditLog(time.Time{})
+	Nil(t, err, "not expecting error reading audit log")
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events in the audit log, got=%d", len(events))
+	}
+
+	Equal(t, "create", events[0].Operation)
+	Equal(t, "albatross create food/truffles", events[0].Command)
+	Equal(t, []string{"food/truffles"}, events[0].Paths)
+	Equal(t, "ok", events[0].Result)
+	Equal(t, "error", events[1].Result)
+	NotEqual(t, "", events[1].Error)
+
+	events, err = store.AuditLog(start.Add(time.Hour))
+	Nil(t, err, "not expecting error reading audit log")
+	Equal(t, 0, len(events), "no events should be after the time given")
+}
@@ -0,0 +1,458 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// The checks which can give a CheckProblem, on top of the ones from Lint for entries which couldn't be loaded, like
+// LintParseFailed.
+const (
+	// CheckBrokenLink is for links which don't point to any entry.
+	CheckBrokenLink = "broken-link"
+
+	// CheckDuplicateTitle is for entries with the same title as another entry, so title links to them are ambiguous.
+	CheckDuplicateTitle = "duplicate-title"
+
+	// CheckAliasCollision is for entries with an alias which is the title or an alias of another entry, so title links
+	// using it are ambiguous.
+	CheckAliasCollision = "alias-collision"
+
+	// CheckMissingAttachment is for Markdown links and images which point to a file that doesn't exist.
+	CheckMissingAttachment = "missing-attachment"
+
+	// CheckOrphanedAttachment is for files in an entry's "attachments/" folder which the entry never refers to.
+	CheckOrphanedAttachment = "orphaned-attachment"
+
+	// CheckDanglingSymlink is for symlinks in the entries folder which point to something that doesn't exist.
+	CheckDanglingSymlink = "dangling-symlink"
+)
+
+var (
+	// reCheckCode matches fenced code blocks and inline code, which shouldn't be checked for references to files.
+	reCheckCode = regexp.MustCompile("(?s)```.*?```|`[^`\n]+`")
+
+	// reFileReference matches the target of Markdown links and images, e.g. "photos/pizza.jpg" in
+	// "![Pizza](photos/pizza.jpg "A pizza")".
+	reFileReference = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+)
+
+// CheckProblem is a problem with the store found by Check.
+type CheckProblem struct {
+	// Path is the path to the entry with the problem, such as "food/pizza". It's blank if the problem isn't in an entry,
+	// like a symlink in a folder without an entry.md file.
+	Path string `json:"path"`
+
+	// Check is the check which found the problem, such as CheckBrokenLink.
+	Check string `json:"check"`
+
+	// Message describes the problem.
+	Message string `json:"message"`
+
+	// Fix describes how Fix would repair the problem. It's blank if the problem can't be repaired automatically.
+	Fix string `json:"fix,omitempty"`
+
+	// file is the file with the problem, relative to the entries folder, for problems which aren't about an entry's
+	// contents like dangling symlinks.
+	file string
+
+	// link and replacement are a link in the entry and the text to replace it with, for broken links which can be fixed.
+	link        *entries.Link
+	replacement string
+}
+
+// Check looks for problems with the integrity of the store, rather than with individual entries like Lint:
+//
+//   - Entries which couldn't be loaded, using the same checks as Lint such as LintParseFailed.
+//   - Links which don't point to any entry.
+//   - Entries which have the same title as another entry.
+//   - Entries with an alias which is the title or an alias of another entry.
+//   - Markdown links and images which point to files that don't exist.
+//   - Files in an entry's "attachments/" folder which the entry never refers to.
+//   - Symlinks which point to something that doesn't exist.
+//
+// Problems are sorted by path. Some of them can be repaired using Fix, which is described by their Fix field. If the
+// store is encrypted, it returns ErrStoreEncrypted.
+func (s *Store) Check() ([]CheckProblem, error) {
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return nil, err
+	} else if encrypted {
+		return nil, ErrStoreEncrypted{Path: s.Path}
+	}
+
+	collection, err := s.Collection()
+	if err != nil {
+		return nil, err
+	}
+
+	problems := []CheckProblem{}
+
+	s.collMu.RLock()
+	entryErrs := s.entryErrs
+	s.collMu.RUnlock()
+
+	for _, entryErr := range entryErrs {
+		issue := s.lintEntryErr(entryErr)
+		problems = append(problems, CheckProblem{Path: issue.Path, Check: issue.Check, Message: issue.Message})
+	}
+
+	list := collection.List().Slice()
+
+	problems = append(problems, checkLinks(collection, list)...)
+	problems = append(problems, checkDuplicateTitles(list)...)
+	problems = append(problems, checkAliasCollisions(collection, list)...)
+
+	for _, entry := range list {
+		attachmentProblems, err := s.checkAttachments(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		problems = append(problems, attachmentProblems...)
+	}
+
+	symlinkProblems, err := s.checkSymlinks()
+	if err != nil {
+		return nil, err
+	}
+
+	problems = append(problems, symlinkProblems...)
+
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Path < problems[j].Path })
+
+	return problems, nil
+}
+
+// Fix repairs the problems given which can be repaired, which are the ones with a Fix description. Broken links are fixed
+// when there's exactly one entry whose title or path only differs from the link in case, by changing the link to match
+// it, and dangling symlinks are removed. Each change is recorded like any other update to the store. It returns the
+// problems which were fixed.
+func (s *Store) Fix(problems []CheckProblem) ([]CheckProblem, error) {
+	collection, err := s.Collection()
+	if err != nil {
+		return nil, err
+	}
+
+	fixed := []CheckProblem{}
+	replacements := map[string][]CheckProblem{}
+	paths := []string{}
+
+	for _, problem := range problems {
+		switch {
+		case problem.link != nil:
+			if replacements[problem.Path] == nil {
+				paths = append(paths, problem.Path)
+			}
+
+			replacements[problem.Path] = append(replacements[problem.Path], problem)
+
+		case problem.Check == CheckDanglingSymlink && problem.file != "":
+			err = s.removeDanglingSymlink(problem.Path, problem.file)
+			if err != nil {
+				return fixed, err
+			}
+
+			fixed = append(fixed, problem)
+		}
+	}
+
+	// All the links in an entry are replaced at once, so fixing one doesn't undo another.
+	for _, path := range paths {
+		entry := collection.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: path})
+		if entry == nil {
+			continue
+		}
+
+		contents, ok := replaceLinks(entry, func(link entries.Link, text string) (string, bool) {
+			for _, problem := range replacements[path] {
+				if link.Loc[0] == problem.link.Loc[0] && link.Loc[1] == problem.link.Loc[1] {
+					return problem.replacement, true
+				}
+			}
+
+			return "", false
+		})
+		if !ok {
+			continue
+		}
+
+		err = s.Update(path, contents)
+		if err != nil {
+			return fixed, fmt.Errorf("couldn't fix links in %s: %w", path, err)
+		}
+
+		fixed = append(fixed, replacements[path]...)
+	}
+
+	return fixed, nil
+}
+
+// checkLinks finds links which don't point to any entry in the collection. If exactly one entry matches the link when
+// case is ignored, the problem can be fixed by changing the link to match it.
+func checkLinks(collection *entries.Collection, list []*entries.Entry) []CheckProblem {
+	titles := map[string][]string{}
+	paths := map[string][]string{}
+
+	for _, entry := range list {
+		titles[strings.ToLower(entry.Title)] = append(titles[strings.ToLower(entry.Title)], entry.Title)
+		paths[strings.ToLower(entry.Path)] = append(paths[strings.ToLower(entry.Path)], entry.Path)
+	}
+
+	problems := []CheckProblem{}
+
+	for _, entry := range list {
+		for _, link := range entry.OutboundLinks {
+			if collection.ResolveLink(link) != nil {
+				continue
+			}
+
+			link := link
+			text := entry.Contents[link.Loc[0]:link.Loc[1]]
+
+			problem := CheckProblem{
+				Path:    entry.Path,
+				Check:   CheckBrokenLink,
+				Message: fmt.Sprintf("link %s doesn't point to any entry", text),
+			}
+
+			target, candidates := link.Title, titles[strings.ToLower(link.Title)]
+			if link.Type == entries.LinkPathNoName || link.Type == entries.LinkPathWithName {
+				target, candidates = link.Path, paths[strings.ToLower(link.Path)]
+			}
+
+			if len(candidates) == 1 {
+				problem.link = &link
+				problem.replacement = strings.Replace(text, target, candidates[0], 1)
+				problem.Fix = fmt.Sprintf("change link to %s", problem.replacement)
+			}
+
+			problems = append(problems, problem)
+		}
+	}
+
+	return problems
+}
+
+// checkDuplicateTitles finds entries which have the same title as another entry.
+func checkDuplicateTitles(list []*entries.Entry) []CheckProblem {
+	byTitle := map[string][]string{}
+
+	for _, entry := range list {
+		byTitle[entry.Title] = append(byTitle[entry.Title], entry.Path)
+	}
+
+	problems := []CheckProblem{}
+
+	for title, paths := range byTitle {
+		if len(paths) < 2 {
+			continue
+		}
+
+		sort.Strings(paths)
+
+		for i, path := range paths {
+			others := append(append([]string{}, paths[:i]...), paths[i+1:]...)
+
+			problems = append(problems, CheckProblem{
+				Path:    path,
+				Check:   CheckDuplicateTitle,
+				Message: fmt.Sprintf("title %q is also used by %s, so links to it are ambiguous", title, strings.Join(others, ", ")),
+			})
+		}
+	}
+
+	return problems
+}
+
+// checkAliasCollisions finds entries with an alias which is also the title or an alias of another entry.
+func checkAliasCollisions(collection *entries.Collection, list []*entries.Entry) []CheckProblem {
+	problems := []CheckProblem{}
+
+	for _, entry := range list {
+		seen := map[string]bool{}
+
+		for _, alias := range entry.Aliases() {
+			if seen[alias] {
+				continue
+			}
+
+			seen[alias] = true
+
+			others := []string{}
+			for _, other := range collection.TitleMatches(alias) {
+				if other.Path != entry.Path {
+					others = append(others, other.Path)
+				}
+			}
+
+			if len(others) == 0 {
+				continue
+			}
+
+			message := fmt.Sprintf("alias %q is also the title or an alias of %s, so links to it are ambiguous", alias, strings.Join(others, ", "))
+			if linked := collection.ResolveLink(entries.Link{Title: alias, Type: entries.LinkTitleNoName}); linked != nil && linked.Path != entry.Path {
+				message += fmt.Sprintf(" ([[%s]] goes to %s)", alias, linked.Path)
+			}
+
+			problems = append(problems, CheckProblem{Path: entry.Path, Check: CheckAliasCollision, Message: message})
+		}
+	}
+
+	return problems
+}
+
+// checkAttachments finds Markdown links and images in the entry which point to files that don't exist, and files in the
+// entry's "attachments/" folder which it never refers to.
+func (s *Store) checkAttachments(entry *entries.Entry) ([]CheckProblem, error) {
+	problems := []CheckProblem{}
+	referenced := map[string]bool{}
+
+	folder := filepath.Join(s.entriesPath, filepath.FromSlash(entry.Path))
+
+	for _, reference := range fileReferences(entry.Contents) {
+		referenced[reference] = true
+
+		if exists(filepath.Join(folder, filepath.FromSlash(reference))) {
+			continue
+		}
+
+		problems = append(problems, CheckProblem{
+			Path:    entry.Path,
+			Check:   CheckMissingAttachment,
+			Message: fmt.Sprintf("%s is referred to but doesn't exist", reference),
+		})
+	}
+
+	attachments, err := s.Attachments(entry.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range attachments {
+		if !strings.HasPrefix(attachment, "attachments/") || referenced[attachment] {
+			continue
+		}
+
+		// Dangling symlinks are reported by checkSymlinks instead.
+		if !exists(filepath.Join(folder, filepath.FromSlash(attachment))) {
+			continue
+		}
+
+		problems = append(problems, CheckProblem{
+			Path:    entry.Path,
+			Check:   CheckOrphanedAttachment,
+			Message: fmt.Sprintf("%s isn't referred to by the entry", attachment),
+		})
+	}
+
+	return problems, nil
+}
+
+// fileReferences returns the local files which Markdown links and images in the contents point to, relative to the entry's
+// folder, like "attachments/pizza.jpg". Links to URLs, anchors and absolute paths aren't included.
+func fileReferences(contents string) []string {
+	contents = reCheckCode.ReplaceAllString(contents, "")
+
+	seen := map[string]bool{}
+	references := []string{}
+
+	for _, match := range reFileReference.FindAllStringSubmatch(contents, -1) {
+		u, err := url.Parse(match[1])
+		if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+			continue
+		}
+
+		reference := path.Clean(u.Path)
+		if !seen[reference] {
+			seen[reference] = true
+			references = append(references, reference)
+		}
+	}
+
+	return references
+}
+
+// checkSymlinks finds symlinks in the entries folder which point to something that doesn't exist.
+func (s *Store) checkSymlinks() ([]CheckProblem, error) {
+	problems := []CheckProblem{}
+
+	err := filepath.Walk(s.entriesPath, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		if info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+
+		if _, err := os.Stat(file); err == nil {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.entriesPath, file)
+		if err != nil {
+			return err
+		}
+
+		target, _ := os.Readlink(file)
+		rel = filepath.ToSlash(rel)
+
+		problems = append(problems, CheckProblem{
+			Path:    s.owningEntry(rel),
+			Check:   CheckDanglingSymlink,
+			Message: fmt.Sprintf("%s links to %s, which doesn't exist", rel, target),
+			Fix:     fmt.Sprintf("remove %s", rel),
+			file:    rel,
+		})
+
+		return nil
+	})
+
+	return problems, err
+}
+
+// owningEntry returns the path of the entry which the file, relative to the entries folder, belongs to. This is the
+// closest folder above it containing an entry.md file, or blank if there isn't one.
+func (s *Store) owningEntry(file string) string {
+	for dir := path.Dir(file); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if exists(filepath.Join(s.entriesPath, filepath.FromSlash(dir), "entry.md")) {
+			return dir
+		}
+	}
+
+	return ""
+}
+
+// removeDanglingSymlink removes the symlink at file, relative to the entries folder, which belongs to the entry at path.
+func (s *Store) removeDanglingSymlink(path, file string) (err error) {
+	defer func() { s.recordAudit("fix", err, file) }()
+
+	err = os.Remove(filepath.Join(s.entriesPath, filepath.FromSlash(file)))
+	if err != nil {
+		return err
+	}
+
+	err = s.recordChange(file, "Remove dangling symlink %s", file)
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		return nil
+	}
+
+	return s.refresh(path)
+}
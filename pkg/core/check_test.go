@@ -0,0 +1,127 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/albatross-org/go-albatross/entries"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreCheck(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	problems, err := store.Check()
+	Nil(t, err, "not expecting error when checking store")
+	if Len(t, problems, 1, "expecting only the broken link in the test store") {
+		Equal(t, "moods/hunger", problems[0].Path)
+		Equal(t, CheckBrokenLink, problems[0].Check)
+	}
+
+	err = store.Create("notes/broken", "---\ntitle: Broken\n---\n\nI like [[pizza!]] and {{food/burgers}}. ![Photo](attachments/missing.png) [Plan](attachments/plan.txt)")
+	if err != nil {
+		t.Fatalf("not expecting error creating entry: %s", err)
+	}
+
+	err = store.Create("notes/copy", "---\ntitle: Ice Cream\n---\n\nAnother entry about ice cream.")
+	if err != nil {
+		t.Fatalf("not expecting error creating entry: %s", err)
+	}
+
+	attachments := filepath.Join(store.entriesPath, "notes", "broken", "attachments")
+
+	err = os.MkdirAll(attachments, 0755)
+	if err != nil {
+		t.Fatalf("not expecting error creating attachments folder: %s", err)
+	}
+
+	for _, name := range []string{"plan.txt", "unused.txt"} {
+		err = ioutil.WriteFile(filepath.Join(attachments, name), []byte("data"), 0644)
+		if err != nil {
+			t.Fatalf("not expecting error writing attachment: %s", err)
+		}
+	}
+
+	err = os.Symlink(filepath.Join(dir, "nowhere"), filepath.Join(attachments, "link.txt"))
+	if err != nil {
+		t.Fatalf("not expecting error creating symlink: %s", err)
+	}
+
+	problems, err = store.Check()
+	Nil(t, err, "not expecting error when checking store")
+
+	found := map[string][]string{}
+	for _, problem := range problems {
+		found[problem.Check] = append(found[problem.Check], problem.Path)
+	}
+
+	Equal(t, map[string][]string{
+		CheckBrokenLink:         {"moods/hunger", "notes/broken", "notes/broken"},
+		CheckDuplicateTitle:     {"food/ice-cream", "notes/copy"},
+		CheckMissingAttachment:  {"notes/broken"},
+		CheckOrphanedAttachment: {"notes/broken"},
+		CheckDanglingSymlink:    {"notes/broken"},
+	}, found)
+
+	fixable := 0
+	for _, problem := range problems {
+		if problem.Fix != "" {
+			fixable++
+		}
+	}
+
+	Equal(t, 2, fixable, "expecting the [[pizza!]] link and the symlink to be fixable")
+
+	fixed, err := store.Fix(problems)
+	Nil(t, err, "not expecting error fixing problems")
+	Len(t, fixed, 2)
+
+	contents, err := ioutil.ReadFile(filepath.Join(store.entriesPath, "notes", "broken", "entry.md"))
+	Nil(t, err, "not expecting error reading fixed entry")
+	Contains(t, string(contents), "I like [[Pizza!]] and {{food/burgers}}.")
+
+	_, err = os.Lstat(filepath.Join(attachments, "link.txt"))
+	True(t, os.IsNotExist(err), "expecting dangling symlink to be removed")
+
+	problems, err = store.Check()
+	Nil(t, err, "not expecting error when checking store again")
+
+	for _, problem := range problems {
+		Empty(t, problem.Fix, "not expecting anything left to fix, got %s: %s", problem.Check, problem.Message)
+	}
+}
+
+func TestFileReferences(t *testing.T) {
+	contents := "![Pizza](pizza.jpg \"A pizza\") [Site](https://example.com) [Top](#top) [Plan](attachments/my%20plan.txt) " +
+		"`[Code](code.txt)` [Again](./pizza.jpg) [Root](/etc/passwd) [Mail](mailto:me@example.com)"
+
+	Equal(t, []string{"pizza.jpg", "attachments/my plan.txt"}, fileReferences(contents))
+}
+
+func TestCheckAliasCollisions(t *testing.T) {
+	collection := entries.NewCollection()
+
+	maths := &entries.Entry{Path: "school/further-maths", Title: "Further Maths", Metadata: map[string]interface{}{"aliases": []interface{}{"FM", "Maths"}}}
+	club := &entries.Entry{Path: "school/fm-club", Title: "Film Club", Metadata: map[string]interface{}{"aliases": []interface{}{"FM"}}}
+	radio := &entries.Entry{Path: "music/radio", Title: "Maths"}
+
+	err := collection.AddMany(maths, club, radio)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	problems := checkAliasCollisions(collection, []*entries.Entry{maths, club, radio})
+	Equal(t, []CheckProblem{
+		{Path: "school/further-maths", Check: CheckAliasCollision, Message: `alias "FM" is also the title or an alias of school/fm-club, so links to it are ambiguous ([[FM]] goes to school/fm-club)`},
+		{Path: "school/further-maths", Check: CheckAliasCollision, Message: `alias "Maths" is also the title or an alias of music/radio, so links to it are ambiguous ([[Maths]] goes to music/radio)`},
+		{Path: "school/fm-club", Check: CheckAliasCollision, Message: `alias "FM" is also the title or an alias of school/further-maths, so links to it are ambiguous`},
+	}, problems)
+}
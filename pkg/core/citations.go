@@ -0,0 +1,23 @@
+package core
+
+import (
+	"path/filepath"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// Bibliography returns the bibliography set by "citations.bibliography" in the store's config, which is a BibTeX (.bib)
+// or CSL-JSON (.json) file. A relative path is relative to the store directory. If no bibliography is set, it returns an
+// empty bibliography.
+func (s *Store) Bibliography() (entries.Bibliography, error) {
+	path := s.config.GetString("citations.bibliography")
+	if path == "" {
+		return entries.Bibliography{}, nil
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.Path, path)
+	}
+
+	return entries.LoadBibliography(path)
+}
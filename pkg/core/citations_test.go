@@ -0,0 +1,36 @@
+package core
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreBibliography(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+
+	store, err := Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	bib, err := store.Bibliography()
+	Nil(t, err, "getting bibliography without one configured, err should be nil")
+	Len(t, bib, 0, "bibliography should be empty when none is configured")
+
+	err = ioutil.WriteFile(filepath.Join(storePath, "refs.bib"), []byte("@book{smith2020, author = {John Smith}, title = {Pizza}, year = 2020}"), 0644)
+	if err != nil {
+		t.Fatalf("not expecting error writing bibliography: %s", err)
+	}
+
+	store = loadWithConfig(t, storePath, "citations:\n  bibliography: refs.bib\n")
+
+	bib, err = store.Bibliography()
+	Nil(t, err, "getting bibliography, err should be nil")
+	Equal(t, "Smith 2020", bib["smith2020"].Label())
+}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Dirty returns the paths of files, relative to the entries folder, which have changes that haven't been committed.
+// This happens when git was disabled using DisableGit or when entries were edited outside of albatross. If the store
+// isn't using git, it returns an empty slice. If the store is encrypted, it returns ErrStoreEncrypted.
+func (s *Store) Dirty() ([]string, error) {
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return nil, err
+	} else if encrypted {
+		return nil, ErrStoreEncrypted{Path: s.Path}
+	}
+
+	dirty := []string{}
+
+	if s.repo == nil {
+		return dirty, nil
+	}
+
+	status, err := s.worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	for path, fileStatus := range status {
+		if fileStatus.Worktree != git.Unmodified || fileStatus.Staging != git.Unmodified {
+			dirty = append(dirty, path)
+		}
+	}
+
+	sort.Strings(dirty)
+
+	return dirty, nil
+}
+
+// CommitChanges stages every uncommitted change in the store and commits them. The commit message is followed by a
+// list of the entries affected. If message is empty, a summary of the number of entries is used instead.
+// It returns the entries affected, which is empty if there was nothing to commit. If the store isn't using git, it
+// returns ErrNotUsingGit.
+func (s *Store) CommitChanges(message string) (affected []string, err error) {
+	defer func() { s.recordAudit("commit", err, affected...) }()
+
+	dirty, err := s.Dirty()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.repo == nil {
+		return nil, ErrNotUsingGit{Path: s.Path}
+	}
+
+	if len(dirty) == 0 {
+		return []string{}, nil
+	}
+
+	for _, file := range dirty {
+		_, err = s.worktree.Add(file)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't stage %s: %w", file, err)
+		}
+	}
+
+	affected = s.affectedEntries(dirty)
+
+	if message == "" {
+		message = fmt.Sprintf("Commit changes to %d entries", len(affected))
+	}
+
+	_, err = s.worktree.Commit(
+		fmt.Sprintf("(go-albatross) %s\n\nEntries:\n- %s\n", message, strings.Join(affected, "\n- ")),
+		&git.CommitOptions{
+			Author: &object.Signature{
+				Name: "go-albatross",
+				When: time.Now(),
+			},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.historyMu.Lock()
+	s.lastModified = nil
+	s.historyMu.Unlock()
+
+	return affected, nil
+}
+
+// affectedEntries converts a list of changed files into the entries they belong to. A file belongs to the closest
+// enclosing folder which contains, or used to contain, an entry.md file.
+func (s *Store) affectedEntries(files []string) []string {
+	entryDirs := make(map[string]bool)
+	for _, file := range files {
+		if path.Base(file) == "entry.md" {
+			entryDirs[path.Dir(file)] = true
+		}
+	}
+
+	// The lock is held while listing entries since SetRegistry modifies the collection in place.
+	s.collMu.RLock()
+	if s.coll != nil {
+		for _, entry := range s.coll.List().Slice() {
+			entryDirs[entry.Path] = true
+		}
+	}
+	s.collMu.RUnlock()
+
+	seen := make(map[string]bool)
+	affected := []string{}
+
+	for _, file := range files {
+		owner := path.Dir(file)
+		for owner != "." && !entryDirs[owner] {
+			owner = path.Dir(owner)
+		}
+
+		if !seen[owner] {
+			seen[owner] = true
+			affected = append(affected, owner)
+		}
+	}
+
+	sort.Strings(affected)
+
+	return affected
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreCommitChanges(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+
+	store, err := Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	_, err = store.CommitChanges("")
+	IsType(t, ErrNotUsingGit{}, err, "committing without git should give ErrNotUsingGit")
+
+	_, err = git.PlainInit(filepath.Join(storePath, "entries"), false)
+	if err != nil {
+		t.Fatalf("not expecting error when initialising git repository: %s", err)
+	}
+
+	store, err = Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	dirty, err := store.Dirty()
+	Nil(t, err, "not expecting error getting uncommitted changes")
+	Contains(t, dirty, "food/pizza/entry.md", "entries which were never committed should be dirty")
+
+	affected, err := store.CommitChanges("Initial commit")
+	Nil(t, err, "not expecting error committing changes")
+	Contains(t, affected, "food/pizza", "pizza entry should be affected")
+
+	dirty, err = store.Dirty()
+	Nil(t, err, "not expecting error getting uncommitted changes")
+	Empty(t, dirty, "there should be no uncommitted changes after committing")
+
+	// Edit an entry and remove another, as if done outside of albatross.
+	err = ioutil.WriteFile(filepath.Join(storePath, "entries", "food", "pizza", "entry.md"), []byte("Pizza."), 0644)
+	if err != nil {
+		t.Fatalf("not expecting error editing entry: %s", err)
+	}
+
+	err = os.RemoveAll(filepath.Join(storePath, "entries", "food", "ice-cream"))
+	if err != nil {
+		t.Fatalf("not expecting error removing entry: %s", err)
+	}
+
+	affected, err = store.CommitChanges("")
+	Nil(t, err, "not expecting error committing changes")
+	Equal(t, []string{"food/ice-cream", "food/pizza"}, affected, "edited and removed entries should be affected")
+
+	dirty, err = store.Dirty()
+	Nil(t, err, "not expecting error getting uncommitted changes")
+	Empty(t, dirty, "deletions should be committed too")
+
+	state, err := store.State()
+	Nil(t, err, "not expecting error getting state")
+	Contains(t, state.LastCommit.Message, "Commit changes to 2 entries", "default message should summarise the entries")
+}
+
+func TestStoreAffectedEntriesConcurrent(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// Reloading replaces the collection, which shouldn't race with working out affected entries.
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 5; i++ {
+			store.reload()
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			store.affectedEntries([]string{"food/pizza/entry.md", "food/pizza/photo.jpg"})
+		}
+	}()
+
+	wg.Wait()
+
+	Equal(t, []string{"food/pizza"}, store.affectedEntries([]string{"food/pizza/photo.jpg"}), "attachments should belong to their entry")
+}
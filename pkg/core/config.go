@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/albatross-org/go-albatross/encryption"
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 )
@@ -34,17 +35,42 @@ func parseConfigFile(path string) (*viper.Viper, error) {
 	v.SetDefault("tags.prefix-builtin", "@!")
 	v.SetDefault("tags.prefix-custom", "@?")
 
+	v.SetDefault("entries.max-size", "16MB")
+	v.SetDefault("entries.max-contents", 0)
+	v.SetDefault("entries.transforms", []string{})
+
+	v.SetDefault("attachments.large-threshold", "0")
+	v.SetDefault("attachments.large-storage", LargeStorageExternal)
+
+	v.SetDefault("read-tracking.automatic", true)
+
+	v.SetDefault("recent.track", true)
+	v.SetDefault("recent.limit", 100)
+
+	v.SetDefault("git.detailed-messages", false)
+
+	v.SetDefault("journal.layout", DefaultJournalLayout)
+	v.SetDefault("journal.title", DefaultJournalTitle)
+	v.SetDefault("journal.prompts", DefaultJournalPrompts)
+
 	defaultPublicKeyPath := filepath.Join(getConfigDir(), "albatross", "keys", "public.key")
 	defaultPrivateKeyPath := filepath.Join(getConfigDir(), "albatross", "keys", "private.key")
 
 	v.SetDefault("encryption.public-key", defaultPublicKeyPath)
 	v.SetDefault("encryption.private-key", defaultPrivateKeyPath)
 
+	v.SetDefault("encryption.compression", encryption.CompressionGzip)
+	v.SetDefault("encryption.compression-level", 0)
+	v.SetDefault("encryption.compression-workers", 1)
+
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 
+	// Without a type, viper can't tell how to parse the file and silently ignores it.
+	v.SetConfigType("yaml")
+
 	err = v.ReadConfig(f)
 	if err != nil {
 		return nil, err
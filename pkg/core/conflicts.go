@@ -0,0 +1,479 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// conflictInfix separates the path of the original entry from the host and date in the path of a conflict entry, such as
+// "food/pizza.conflict-laptop-20201101-150405".
+const conflictInfix = ".conflict-"
+
+// reConflictCopy matches the names of the copies file sync tools make when a file is changed in two places at once,
+// such as "entry.sync-conflict-20201101-150405-ABCDEFG.md" (Syncthing) or "entry (laptop's conflicted copy).md"
+// (Dropbox).
+var reConflictCopy = regexp.MustCompile(`^entry(\.sync-conflict-.*| \(.*conflicted copy.*\))\.md$`)
+
+// reHostUnsafe matches runs of characters which aren't safe to use in a path.
+var reHostUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Conflict is a version of an entry which conflicted with the version in the store, such as one changed on two machines
+// before they synced. The conflicting version is kept as a sibling entry of the original, named
+// "<path>.conflict-<host>-<date>", until it's resolved.
+type Conflict struct {
+	// Path is the path to the original entry, such as "food/pizza".
+	Path string `json:"path"`
+
+	// ConflictPath is the path to the entry holding the conflicting version, such as
+	// "food/pizza.conflict-laptop-20201101-150405".
+	ConflictPath string `json:"conflictPath"`
+}
+
+// CaptureConflicts finds entries with conflicting versions and moves each conflicting version into its own entry next
+// to the original, so that both versions are readable entries rather than a file full of conflict markers.
+//
+// Two kinds of conflict are found: entry.md files containing git conflict markers, such as after 'albatross git pull',
+// where the local version is kept in place and the incoming version is moved out; and conflicted copies made by file
+// sync tools such as Syncthing or Dropbox, which are moved out as they are. host is recorded in the name of the new
+// entries, usually the hostname of the machine capturing the conflicts.
+//
+// If a git merge was stopped by the conflicts, the captured entries are committed along with the rest of the merge as a
+// merge commit, so that the commits being merged aren't lost. If files other than entries still have conflicts, the
+// merge is left for git to finish and an error is returned.
+//
+// It returns the conflicts captured. If the store is encrypted, it returns ErrStoreEncrypted.
+func (s *Store) CaptureConflicts(host string) (captured []Conflict, err error) {
+	defer func() {
+		paths := []string{}
+		for _, conflict := range captured {
+			paths = append(paths, conflict.ConflictPath)
+		}
+
+		s.recordAudit("capture-conflicts", err, paths...)
+	}()
+
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return nil, err
+	} else if encrypted {
+		return nil, ErrStoreEncrypted{Path: s.Path}
+	}
+
+	mergeHeads, err := s.mergeHeads()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	captured = []Conflict{}
+
+	err = filepath.Walk(s.entriesPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.entriesPath, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		// Files directly inside the entries folder don't belong to an entry, so there's nowhere to put a conflict.
+		if rel == "." {
+			return nil
+		}
+
+		var contents string
+
+		switch {
+		case info.Name() == "entry.md":
+			raw, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			ours, theirs, ok := splitConflictMarkers(string(raw))
+			if !ok {
+				return nil
+			}
+
+			err = ioutil.WriteFile(path, []byte(ours), info.Mode())
+			if err != nil {
+				return err
+			}
+
+			contents = theirs
+
+		case reConflictCopy.MatchString(info.Name()):
+			raw, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			err = os.Remove(path)
+			if err != nil {
+				return err
+			}
+
+			contents = string(raw)
+
+		default:
+			return nil
+		}
+
+		conflictPath := s.conflictPath(rel, host, now)
+
+		err = os.MkdirAll(filepath.Join(s.entriesPath, conflictPath), 0755)
+		if err != nil {
+			return err
+		}
+
+		err = ioutil.WriteFile(filepath.Join(s.entriesPath, conflictPath, "entry.md"), []byte(contents), 0644)
+		if err != nil {
+			return err
+		}
+
+		captured = append(captured, Conflict{Path: rel, ConflictPath: conflictPath})
+		return nil
+	})
+	if err != nil {
+		return captured, err
+	}
+
+	if len(captured) == 0 {
+		return captured, nil
+	}
+
+	if len(mergeHeads) > 0 {
+		err = s.commitMerge(captured, mergeHeads)
+		if err != nil {
+			return captured, err
+		}
+
+		return captured, s.reload()
+	}
+
+	for _, conflict := range captured {
+		err = s.recordChange(conflict.Path, "Capture conflict in %s", conflict.Path)
+		if err != nil {
+			return captured, err
+		}
+
+		err = s.recordChange(conflict.ConflictPath, "Capture conflict in %s as %s", conflict.Path, conflict.ConflictPath)
+		if err != nil {
+			return captured, err
+		}
+	}
+
+	return captured, s.reload()
+}
+
+// mergeHeads returns the commits being merged if a git merge was stopped by conflicts, such as after 'albatross git
+// pull', by reading the MERGE_HEAD file git leaves behind. It returns nil if no merge is in progress or git isn't being
+// used.
+func (s *Store) mergeHeads() ([]plumbing.Hash, error) {
+	if s.repo == nil || s.disableGit {
+		return nil, nil
+	}
+
+	raw, err := ioutil.ReadFile(filepath.Join(s.entriesPath, ".git", "MERGE_HEAD"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	heads := []plumbing.Hash{}
+	for _, line := range strings.Fields(string(raw)) {
+		heads = append(heads, plumbing.NewHash(line))
+	}
+
+	return heads, nil
+}
+
+// commitMerge finishes a merge stopped by conflicts once they've been captured. The conflicting versions git left in the
+// index are replaced by the captured entries, which are committed with HEAD and the commits being merged as parents.
+func (s *Store) commitMerge(captured []Conflict, mergeHeads []plumbing.Hash) error {
+	idx, err := s.repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+
+	capturedFiles := map[string]bool{}
+	for _, conflict := range captured {
+		capturedFiles[conflict.Path+"/entry.md"] = true
+	}
+
+	merged := make([]*index.Entry, 0, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		if entry.Stage == 0 {
+			merged = append(merged, entry)
+			continue
+		}
+
+		if !capturedFiles[entry.Name] {
+			return fmt.Errorf("couldn't finish merge since %s still has conflicts, finish the merge using git", entry.Name)
+		}
+	}
+
+	idx.Entries = merged
+
+	err = s.repo.Storer.SetIndex(idx)
+	if err != nil {
+		return err
+	}
+
+	paths := []string{}
+	for _, conflict := range captured {
+		paths = append(paths, conflict.Path)
+
+		err = s.stage(conflict.Path)
+		if err != nil {
+			return err
+		}
+
+		err = s.stage(conflict.ConflictPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	head, err := s.repo.Head()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.worktree.Commit(
+		fmt.Sprintf("(go-albatross) Merge and capture conflicts in %d entries\n\nEntries:\n- %s\n", len(paths), strings.Join(paths, "\n- ")),
+		&git.CommitOptions{
+			Author: &object.Signature{
+				Name: "go-albatross",
+				When: time.Now(),
+			},
+			Parents: append([]plumbing.Hash{head.Hash()}, mergeHeads...),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range []string{"MERGE_HEAD", "MERGE_MSG", "MERGE_MODE"} {
+		err = os.Remove(filepath.Join(s.entriesPath, ".git", name))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// conflictPath returns an unused path for a conflicting version of the entry at entryPath. The conflict is named after
+// the entry and kept in the same folder, such as "food/pizza.conflict-laptop-20201101-150405" for "food/pizza".
+func (s *Store) conflictPath(entryPath, host string, now time.Time) string {
+	name := path.Base(entryPath) + conflictInfix + sanitiseHost(host) + "-" + now.Format("20060102-150405")
+	base := path.Join(path.Dir(entryPath), name)
+
+	candidate := base
+	for i := 2; exists(filepath.Join(s.entriesPath, candidate)); i++ {
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	return candidate
+}
+
+// sanitiseHost makes a hostname safe to use in a path.
+func sanitiseHost(host string) string {
+	host = strings.ToLower(host)
+	host = reHostUnsafe.ReplaceAllString(host, "-")
+	host = strings.Trim(host, "-")
+
+	if host == "" {
+		return "unknown"
+	}
+
+	return host
+}
+
+// Conflicts returns every conflict in the store which hasn't been resolved yet, sorted by path. If the store is encrypted,
+// it returns ErrStoreEncrypted.
+func (s *Store) Conflicts() ([]Conflict, error) {
+	collection, err := s.Collection()
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := []Conflict{}
+
+	for _, entry := range collection.List().Slice() {
+		i := strings.LastIndex(entry.Path, conflictInfix)
+		if i == -1 || strings.Contains(entry.Path[i:], "/") {
+			continue
+		}
+
+		conflicts = append(conflicts, Conflict{Path: entry.Path[:i], ConflictPath: entry.Path})
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].ConflictPath < conflicts[j].ConflictPath })
+
+	return conflicts, nil
+}
+
+// ResolveConflict resolves a conflict by setting the contents of the original entry to the contents given, which could
+// be either version or a combination of both, and then deleting the conflicting version. If the original entry no
+// longer exists, it's created. If the store is encrypted, it returns ErrStoreEncrypted.
+func (s *Store) ResolveConflict(conflict Conflict, contents string) (err error) {
+	defer func() { s.recordAudit("resolve-conflict", err, conflict.Path, conflict.ConflictPath) }()
+
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return err
+	} else if encrypted {
+		return ErrStoreEncrypted{Path: s.Path}
+	}
+
+	if !exists(filepath.Join(s.entriesPath, conflict.ConflictPath, "entry.md")) {
+		return ErrEntryDoesntExist{Path: conflict.ConflictPath}
+	}
+
+	err = os.MkdirAll(filepath.Join(s.entriesPath, conflict.Path), 0755)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(filepath.Join(s.entriesPath, conflict.Path, "entry.md"), []byte(contents), 0644)
+	if err != nil {
+		return err
+	}
+
+	// Any attachments which only exist in the conflicting version are moved across so they aren't lost.
+	files, err := ioutil.ReadDir(filepath.Join(s.entriesPath, conflict.ConflictPath))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || file.Name() == "entry.md" {
+			continue
+		}
+
+		dest := filepath.Join(s.entriesPath, conflict.Path, file.Name())
+		if exists(dest) {
+			continue
+		}
+
+		err = os.Rename(filepath.Join(s.entriesPath, conflict.ConflictPath, file.Name()), dest)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = os.RemoveAll(filepath.Join(s.entriesPath, conflict.ConflictPath))
+	if err != nil {
+		return err
+	}
+
+	err = s.recordChange(conflict.Path, "Resolve conflict in %s", conflict.Path)
+	if err != nil {
+		return err
+	}
+
+	err = s.recordChange(conflict.ConflictPath, "Resolve conflict in %s, removing %s", conflict.Path, conflict.ConflictPath)
+	if err != nil {
+		return err
+	}
+
+	return s.reload()
+}
+
+// ReadConflict returns the contents of the original entry and the conflicting version for a conflict. If the original
+// entry no longer exists, its contents are empty.
+func (s *Store) ReadConflict(conflict Conflict) (original, conflicting string, err error) {
+	conflictingBytes, err := ioutil.ReadFile(filepath.Join(s.entriesPath, conflict.ConflictPath, "entry.md"))
+	if err != nil {
+		return "", "", err
+	}
+
+	originalBytes, err := ioutil.ReadFile(filepath.Join(s.entriesPath, conflict.Path, "entry.md"))
+	if err != nil && !os.IsNotExist(err) {
+		return "", "", err
+	}
+
+	return string(originalBytes), string(conflictingBytes), nil
+}
+
+// splitConflictMarkers splits text containing git conflict markers into the two versions, ours and theirs. Text outside
+// of conflicts is in both versions, and the common ancestor section of diff3-style conflicts is discarded. ok is false
+// if the text doesn't contain any conflicts.
+func splitConflictMarkers(text string) (ours, theirs string, ok bool) {
+	const (
+		both = iota
+		inOurs
+		inBase
+		inTheirs
+	)
+
+	var oursB, theirsB strings.Builder
+	state := both
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 64*1024), len(text)+1)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "<<<<<<<") && state == both:
+			state = inOurs
+			ok = true
+			continue
+		case strings.HasPrefix(line, "|||||||") && state == inOurs:
+			state = inBase
+			continue
+		case line == "=======" && (state == inOurs || state == inBase):
+			state = inTheirs
+			continue
+		case strings.HasPrefix(line, ">>>>>>>") && state == inTheirs:
+			state = both
+			continue
+		}
+
+		switch state {
+		case both:
+			oursB.WriteString(line + "\n")
+			theirsB.WriteString(line + "\n")
+		case inOurs:
+			oursB.WriteString(line + "\n")
+		case inTheirs:
+			theirsB.WriteString(line + "\n")
+		}
+	}
+
+	if state != both {
+		// Unterminated conflict, so these probably aren't really conflict markers.
+		return "", "", false
+	}
+
+	return oursB.String(), theirsB.String(), ok
+}
@@ -0,0 +1,218 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestSplitConflictMarkers(t *testing.T) {
+	text := "---\ntitle: \"Pizza\"\n---\n\n<<<<<<< HEAD\nPizza is great.\n=======\nPizza is okay.\n>>>>>>> origin/master\n\nThe end.\n"
+
+	ours, theirs, ok := splitConflictMarkers(text)
+	True(t, ok, "text should contain conflicts")
+	Equal(t, "---\ntitle: \"Pizza\"\n---\n\nPizza is great.\n\nThe end.\n", ours)
+	Equal(t, "---\ntitle: \"Pizza\"\n---\n\nPizza is okay.\n\nThe end.\n", theirs)
+
+	diff3 := "<<<<<<< HEAD\nA\n||||||| base\nB\n=======\nC\n>>>>>>> other\n"
+	ours, theirs, ok = splitConflictMarkers(diff3)
+	True(t, ok, "diff3-style text should contain conflicts")
+	Equal(t, "A\n", ours, "common ancestor should be discarded")
+	Equal(t, "C\n", theirs, "common ancestor should be discarded")
+
+	_, _, ok = splitConflictMarkers("No conflicts here.\n=======\n")
+	False(t, ok, "text without conflict markers shouldn't contain conflicts")
+}
+
+func TestStoreConflicts(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+	entriesPath := filepath.Join(storePath, "entries")
+
+	_, err := git.PlainInit(entriesPath, false)
+	if err != nil {
+		t.Fatalf("not expecting error when initialising git repository: %s", err)
+	}
+
+	store, err := Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	_, err = store.CommitChanges("Initial commit")
+	if err != nil {
+		t.Fatalf("not expecting error committing: %s", err)
+	}
+
+	conflicted := "---\ntitle: \"Pizza\"\n---\n\n<<<<<<< HEAD\nMine.\n=======\nTheirs.\n>>>>>>> origin/master\n"
+	err = ioutil.WriteFile(filepath.Join(entriesPath, "food", "pizza", "entry.md"), []byte(conflicted), 0644)
+	if err != nil {
+		t.Fatalf("not expecting error writing conflicted entry: %s", err)
+	}
+
+	// A conflicted copy outside of any entry has nowhere to go, so it's left alone.
+	err = ioutil.WriteFile(filepath.Join(entriesPath, "entry.sync-conflict-20201101-150405-ABCDEFG.md"), []byte("Stray."), 0644)
+	if err != nil {
+		t.Fatalf("not expecting error writing conflicted copy: %s", err)
+	}
+
+	copied := "---\ntitle: \"Ice Cream\"\n---\n\nEdited elsewhere.\n"
+	err = ioutil.WriteFile(filepath.Join(entriesPath, "food", "ice-cream", "entry.sync-conflict-20201101-150405-ABCDEFG.md"), []byte(copied), 0644)
+	if err != nil {
+		t.Fatalf("not expecting error writing conflicted copy: %s", err)
+	}
+
+	captured, err := store.CaptureConflicts("My Laptop")
+	if err != nil {
+		t.Fatalf("not expecting error capturing conflicts: %s", err)
+	}
+
+	Len(t, captured, 2, "both conflicts should be captured")
+
+	conflicts, err := store.Conflicts()
+	Nil(t, err, "not expecting error listing conflicts")
+	Equal(t, captured, conflicts, "captured conflicts should be listed")
+
+	for _, conflict := range conflicts {
+		Contains(t, conflict.ConflictPath, conflict.Path+".conflict-my-laptop-", "conflict should be a sibling of the original entry")
+	}
+
+	var pizza Conflict
+	for _, conflict := range conflicts {
+		if conflict.Path == "food/pizza" {
+			pizza = conflict
+		}
+	}
+
+	original, conflicting, err := store.ReadConflict(pizza)
+	Nil(t, err, "not expecting error reading conflict")
+	Contains(t, original, "Mine.", "local version should be kept in place")
+	NotContains(t, original, "<<<<<<<", "conflict markers should be removed")
+	Contains(t, conflicting, "Theirs.", "incoming version should be in the conflict entry")
+
+	err = store.ResolveConflict(pizza, conflicting)
+	Nil(t, err, "not expecting error resolving conflict")
+
+	conflicts, err = store.Conflicts()
+	Nil(t, err, "not expecting error listing conflicts")
+	Len(t, conflicts, 1, "resolved conflict shouldn't be listed")
+
+	contents, err := ioutil.ReadFile(filepath.Join(entriesPath, "food", "pizza", "entry.md"))
+	Nil(t, err, "not expecting error reading resolved entry")
+	Contains(t, string(contents), "Theirs.", "resolved entry should have the contents given")
+
+	dirty, err := store.Dirty()
+	Nil(t, err, "not expecting error getting uncommitted changes")
+	for _, path := range dirty {
+		NotContains(t, path, "food/pizza", "resolving a conflict should be committed")
+	}
+}
+
+func TestStoreCaptureConflictsMerge(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+	entriesPath := filepath.Join(storePath, "entries")
+	pizzaPath := filepath.Join(entriesPath, "food", "pizza", "entry.md")
+
+	_, err := git.PlainInit(entriesPath, false)
+	if err != nil {
+		t.Fatalf("not expecting error when initialising git repository: %s", err)
+	}
+
+	store, err := Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	_, err = store.CommitChanges("Initial commit")
+	if err != nil {
+		t.Fatalf("not expecting error committing: %s", err)
+	}
+
+	runGit := func(args ...string) error {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = entriesPath
+		return cmd.Run()
+	}
+
+	editPizza := func(contents string) {
+		err := ioutil.WriteFile(pizzaPath, []byte(contents), 0644)
+		if err != nil {
+			t.Fatalf("not expecting error editing entry: %s", err)
+		}
+
+		err = runGit("commit", "-q", "-am", "Edit pizza")
+		if err != nil {
+			t.Fatalf("not expecting error committing: %s", err)
+		}
+	}
+
+	// Change the pizza entry differently on two branches and merge them, as if pulling from another machine.
+	err = runGit("checkout", "-q", "-b", "other")
+	if err != nil {
+		t.Fatalf("not expecting error creating branch: %s", err)
+	}
+
+	editPizza("Theirs.\n")
+
+	err = runGit("checkout", "-q", "-")
+	if err != nil {
+		t.Fatalf("not expecting error switching branch: %s", err)
+	}
+
+	editPizza("Mine.\n")
+
+	err = runGit("merge", "-q", "other")
+	NotNil(t, err, "expecting merge to conflict")
+
+	store, err = Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	captured, err := store.CaptureConflicts("laptop")
+	if err != nil {
+		t.Fatalf("not expecting error capturing conflicts: %s", err)
+	}
+
+	if !Len(t, captured, 1, "pizza conflict should be captured") {
+		return
+	}
+
+	_, err = os.Stat(filepath.Join(entriesPath, ".git", "MERGE_HEAD"))
+	True(t, os.IsNotExist(err), "merge should be finished")
+
+	head, err := store.repo.Head()
+	if err != nil {
+		t.Fatalf("not expecting error getting HEAD: %s", err)
+	}
+
+	commit, err := store.repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("not expecting error getting HEAD commit: %s", err)
+	}
+
+	Equal(t, 2, commit.NumParents(), "capture should be committed as a merge commit")
+
+	dirty, err := store.Dirty()
+	Nil(t, err, "not expecting error getting uncommitted changes")
+	Empty(t, dirty, "captured conflicts should be committed")
+
+	_, conflicting, err := store.ReadConflict(captured[0])
+	Nil(t, err, "not expecting error reading conflict")
+	Equal(t, "Theirs.\n", conflicting, "incoming version should be in the conflict entry")
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// MetadataDefault is a rule from the "default-metadata" section of the config which gives metadata to entries created
+// under a path, so that it doesn't have to be typed out each time:
+//
+//	default-metadata:
+//	  - path: work
+//	    metadata:
+//	      project: acme
+//	  - path: journal
+//	    metadata:
+//	      type: journal
+type MetadataDefault struct {
+	// Path is the path the rule applies to, such as "work". It applies to the entry at the path and every entry under it.
+	Path string `mapstructure:"path"`
+
+	// Metadata are the keys and values added to the front matter of entries under the path.
+	Metadata map[string]interface{} `mapstructure:"metadata"`
+}
+
+// matches returns true if the rule applies to the entry at the path given.
+func (d MetadataDefault) matches(path string) bool {
+	prefix := strings.Trim(d.Path, "/")
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// MetadataDefaults returns the rules in the "default-metadata" section of the config.
+func (s *Store) MetadataDefaults() ([]MetadataDefault, error) {
+	defaults := []MetadataDefault{}
+
+	err := s.config.UnmarshalKey("default-metadata", &defaults)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse default-metadata in config: %w", err)
+	}
+
+	return defaults, nil
+}
+
+// DefaultMetadata returns the metadata an entry created at the path given gets by default. Where more than one rule sets
+// the same key, the rule with the longest path wins, so rules for "work/acme" override those for "work".
+func (s *Store) DefaultMetadata(path string) (map[string]interface{}, error) {
+	defaults, err := s.MetadataDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(defaults, func(i, j int) bool {
+		return len(strings.Trim(defaults[i].Path, "/")) < len(strings.Trim(defaults[j].Path, "/"))
+	})
+
+	metadata := make(map[string]interface{})
+
+	for _, rule := range defaults {
+		if !rule.matches(path) {
+			continue
+		}
+
+		for key, value := range rule.Metadata {
+			metadata[key] = value
+		}
+	}
+
+	return metadata, nil
+}
+
+// ApplyDefaultMetadata adds the default metadata for the path to the front matter of the content, leaving keys which are
+// already set alone. Create does this itself, but it's useful for showing an entry as it will be created.
+func (s *Store) ApplyDefaultMetadata(path, content string) (string, error) {
+	metadata, err := s.DefaultMetadata(path)
+	if err != nil {
+		return "", err
+	}
+
+	if len(metadata) == 0 {
+		return content, nil
+	}
+
+	return entries.AddMetadata(content, metadata)
+}
+
+// lintDefaultMetadata returns issues for entries missing keys they'd have been given by default when created.
+func (s *Store) lintDefaultMetadata(list entries.List) ([]LintIssue, error) {
+	issues := []LintIssue{}
+
+	for _, entry := range list.Slice() {
+		metadata, err := s.DefaultMetadata(entry.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		missing := []string{}
+		for key := range metadata {
+			if _, ok := entry.Metadata[key]; !ok {
+				missing = append(missing, key)
+			}
+		}
+
+		if len(missing) == 0 {
+			continue
+		}
+
+		sort.Strings(missing)
+
+		issues = append(issues, LintIssue{
+			Path:    entry.Path,
+			Check:   LintMissingMetadata,
+			Message: fmt.Sprintf("entry is missing %s, which default-metadata gives entries at this path", strings.Join(missing, ", ")),
+		})
+	}
+
+	return issues, nil
+}
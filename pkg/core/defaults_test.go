@@ -0,0 +1,65 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/albatross-org/go-albatross/entries"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreDefaultMetadata(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	store.config.Set("default-metadata", []interface{}{
+		map[interface{}]interface{}{
+			"path":     "work/acme",
+			"metadata": map[interface{}]interface{}{"project": "acme"},
+		},
+		map[interface{}]interface{}{
+			"path":     "work",
+			"metadata": map[interface{}]interface{}{"project": "unknown", "type": "work"},
+		},
+		map[interface{}]interface{}{
+			"path":     "food",
+			"metadata": map[interface{}]interface{}{"cuisine": "italian"},
+		},
+	})
+
+	metadata, err := store.DefaultMetadata("work/acme/standup")
+	Nil(t, err)
+	Equal(t, map[string]interface{}{"project": "acme", "type": "work"}, metadata, "expecting longer paths to override shorter ones")
+
+	metadata, err = store.DefaultMetadata("workshop")
+	Nil(t, err)
+	Equal(t, 0, len(metadata), "expecting paths to match whole folders")
+
+	err = store.Create("work/acme/standup", "---\ntitle: \"Standup\"\ntype: meeting\n---\n\nNotes.\n")
+	Nil(t, err, "not expecting error creating entry")
+
+	collection, err := store.Collection()
+	Nil(t, err)
+
+	entry := collection.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: "work/acme/standup"})
+	if entry == nil {
+		t.Fatalf("expecting created entry to be in collection")
+	}
+
+	Equal(t, "acme", entry.Metadata["project"])
+	Equal(t, "meeting", entry.Metadata["type"], "expecting keys in the entry not to be replaced")
+
+	issues, err := store.Lint()
+	Nil(t, err)
+	NotEqual(t, 0, len(issues), "expecting entries under food to be missing metadata")
+	for _, issue := range issues {
+		Equal(t, LintMissingMetadata, issue.Check)
+		Contains(t, issue.Path, "food/")
+		Contains(t, issue.Message, "cuisine")
+	}
+}
@@ -0,0 +1,7 @@
+// Package core implements Albatross stores: loading them, searching and changing their entries, encryption and the
+// git history behind them. The command line tool is built on it, so as well as the stable API it has many things which
+// only the tool needs and which can change between versions.
+//
+// Deprecated: Programs outside of go-albatross should import github.com/albatross-org/go-albatross instead, which has
+// the same Store, Load and errors as part of the stable v1 API, so that they keep working as this package changes.
+package core
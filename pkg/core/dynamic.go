@@ -0,0 +1,128 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// IsDynamic returns true if the entry has "dynamic: true" in its front matter. The contents of a dynamic entry are a
+// template which is executed against the store whenever the entry is read, so that something like an index of entries
+// can keep itself up to date:
+//
+//	---
+//	title: "Projects"
+//	dynamic: true
+//	---
+//
+//	<(range .Tagged "@?project")>
+//	* <(link .)>
+//	<(end)>
+//
+// The file itself always keeps the template.
+func IsDynamic(entry *entries.Entry) bool {
+	dynamic, _ := entry.Metadata["dynamic"].(bool)
+	return dynamic
+}
+
+// DynamicContext is the context the contents of a dynamic entry are executed with.
+type DynamicContext struct {
+	// Entry is the dynamic entry itself.
+	Entry *entries.Entry
+
+	// Store holds values set for the whole store, such as .Store.Vars.
+	Store TemplateStore
+
+	collection *entries.Collection
+}
+
+// Entries returns every entry in the store apart from the dynamic entry itself, sorted by path.
+func (c DynamicContext) Entries() []*entries.Entry {
+	return c.matching(func(*entries.Entry) bool { return true })
+}
+
+// Tagged returns the entries with the tag given, like "@?project", sorted by path.
+func (c DynamicContext) Tagged(tag string) []*entries.Entry {
+	return c.matching(func(entry *entries.Entry) bool {
+		for _, entryTag := range entry.Tags {
+			if entryTag == tag {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// Under returns the entries whose path is inside the path given, like "school/physics", sorted by path.
+func (c DynamicContext) Under(path string) []*entries.Entry {
+	prefix := strings.TrimSuffix(path, "/") + "/"
+
+	return c.matching(func(entry *entries.Entry) bool {
+		return strings.HasPrefix(entry.Path, prefix)
+	})
+}
+
+// Backlinks returns the entries which link to the dynamic entry, sorted by path.
+func (c DynamicContext) Backlinks() []*entries.Entry {
+	backlinks := c.collection.Backlinks(c.Entry)
+	sort.Slice(backlinks, func(i, j int) bool { return backlinks[i].Path < backlinks[j].Path })
+
+	return backlinks
+}
+
+// Get returns the entry at the path given, or nil if there isn't one.
+func (c DynamicContext) Get(path string) *entries.Entry {
+	return c.collection.Get(path)
+}
+
+// matching returns the entries other than the dynamic entry for which match returns true, sorted by path.
+func (c DynamicContext) matching(match func(*entries.Entry) bool) []*entries.Entry {
+	matched := []*entries.Entry{}
+
+	for _, entry := range c.collection.List().Slice() {
+		if entry.Path != c.Entry.Path && match(entry) {
+			matched = append(matched, entry)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Path < matched[j].Path })
+
+	return matched
+}
+
+// dynamicFuncs are the functions available to dynamic entries on top of the sprig functions.
+var dynamicFuncs = template.FuncMap{
+	// link returns a link to an entry by its path, like "{{school/physics}}".
+	"link": func(entry *entries.Entry) string {
+		return "{{" + entry.Path + "}}"
+	},
+}
+
+// RenderDynamic returns the contents of a dynamic entry after executing them as a template against the collection,
+// which should be the whole store. Like other templates, they use "<(" and ")>" as delimiters. Entries which aren't
+// dynamic are returned as they are.
+func RenderDynamic(collection *entries.Collection, store TemplateStore, entry *entries.Entry) (string, error) {
+	if !IsDynamic(entry) {
+		return entry.Contents, nil
+	}
+
+	tmpl, err := template.New(entry.Path).Delims("<(", ")>").Funcs(sprig.TxtFuncMap()).Funcs(dynamicFuncs).Parse(entry.Contents)
+	if err != nil {
+		return "", fmt.Errorf("error parsing dynamic entry %s: %w", entry.Path, err)
+	}
+
+	var out bytes.Buffer
+
+	err = tmpl.Execute(&out, DynamicContext{Entry: entry, Store: store, collection: collection})
+	if err != nil {
+		return "", fmt.Errorf("error rendering dynamic entry %s: %w", entry.Path, err)
+	}
+
+	return out.String(), nil
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/albatross-org/go-albatross/entries"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestRenderDynamic(t *testing.T) {
+	index := &entries.Entry{
+		Path:     "projects",
+		Title:    "Projects",
+		Metadata: map[string]interface{}{"dynamic": true},
+		Tags:     []string{"@?project"},
+		Contents: "By <(.Store.Vars.author)>:\n<(range .Tagged \"@?project\")>* <(link .)> (<(.Title)>)\n<(end)><(len (.Under \"school\"))> in school.",
+	}
+
+	collection := entries.NewCollection()
+	err := collection.AddMany(
+		index,
+		&entries.Entry{Path: "work/website", Title: "Website", Tags: []string{"@?project"}},
+		&entries.Entry{Path: "school/robot", Title: "Robot", Tags: []string{"@?project"}},
+		&entries.Entry{Path: "school/notes", Title: "Notes"},
+	)
+	if err != nil {
+		t.Fatalf("not expecting error adding entries: %s", err)
+	}
+
+	rendered, err := RenderDynamic(collection, TemplateStore{Vars: map[string]interface{}{"author": "Jane Doe"}}, index)
+	Nil(t, err, "rendering dynamic entry, err should be nil")
+	Equal(t, "By Jane Doe:\n* {{school/robot}} (Robot)\n* {{work/website}} (Website)\n2 in school.", rendered)
+
+	plain := &entries.Entry{Path: "plain", Contents: "Not <(.Entry)>."}
+	False(t, IsDynamic(plain))
+
+	rendered, err = RenderDynamic(collection, TemplateStore{}, plain)
+	Nil(t, err, "rendering entry which isn't dynamic, err should be nil")
+	Equal(t, "Not <(.Entry)>.", rendered, "expecting entries which aren't dynamic to be left alone")
+
+	broken := &entries.Entry{Path: "broken", Metadata: map[string]interface{}{"dynamic": true}, Contents: "<(.Missing)>"}
+	_, err = RenderDynamic(collection, TemplateStore{}, broken)
+	NotNil(t, err, "expecting error rendering a template with a missing field")
+}
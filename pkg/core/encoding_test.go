@@ -0,0 +1,51 @@
+package core
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/albatross-org/go-albatross/entries"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreUpdatePreservesEncoding(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	err = store.Create("windows", "\ufeff---\r\ntitle: \"Windows\"\r\n---\r\n\r\nWritten on Windows.\r\n")
+	if err != nil {
+		t.Fatalf("not expecting error when creating entry: %s", err)
+	}
+
+	collection, err := store.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error when getting collection: %s", err)
+	}
+
+	filtered, err := collection.Filter(entries.FilterPathsExact("windows"))
+	if err != nil || filtered.Len() != 1 {
+		t.Fatalf("expecting entry to exist, err: %v", err)
+	}
+
+	entry := filtered.List().Slice()[0]
+
+	Equal(t, "Written on Windows.\n", entry.Contents, "contents should be normalised")
+
+	err = store.Update("windows", entry.OriginalContents+"Edited elsewhere.\n")
+	if err != nil {
+		t.Fatalf("not expecting error when updating entry: %s", err)
+	}
+
+	raw, err := ioutil.ReadFile(filepath.Join(store.entriesPath, "windows", "entry.md"))
+	if err != nil {
+		t.Fatalf("not expecting error when reading entry: %s", err)
+	}
+
+	Equal(t, "\ufeff---\r\ntitle: \"Windows\"\r\n---\r\n\r\nWritten on Windows.\r\nEdited elsewhere.\r\n", string(raw), "update should keep the original line endings and byte order mark")
+}
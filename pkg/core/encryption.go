@@ -3,8 +3,14 @@ package core
 import (
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
 
 	"github.com/albatross-org/go-albatross/encryption"
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/sirupsen/logrus"
 )
 
 // Encrypted returns true or false depending on whether the store is encrypted or decrypted.
@@ -24,7 +30,11 @@ func (s *Store) Encrypted() (bool, error) {
 }
 
 // Encrypt encrypts the store. If the store is already encrypted, it returns ErrStoreEncrypted.
-func (s *Store) Encrypt() error {
+// The entries are compressed as set by 'encryption.compression', 'encryption.compression-level' and
+// 'encryption.compression-workers' in the config, see encryption.Compression.
+func (s *Store) Encrypt() (err error) {
+	defer func() { s.recordAudit("encrypt", err) }()
+
 	encrypted, err := s.Encrypted()
 	if err != nil {
 		return err
@@ -32,10 +42,11 @@ func (s *Store) Encrypt() error {
 		return ErrStoreEncrypted{Path: s.Path}
 	}
 
-	err = encryption.EncryptDir(
+	err = encryption.EncryptDirCompressed(
 		s.entriesPath,
 		s.entriesPath+".gpg",
 		s.config.GetString("encryption.public-key"),
+		s.compression(),
 	)
 	if err != nil {
 		return err
@@ -47,7 +58,9 @@ func (s *Store) Encrypt() error {
 // Decrypt decrypts the store. If the store is already decrypted, it will return ErrStoreDecrypted.
 // It takes a password func, which is anything that returns a string and an error. This allows to specify the password
 // without having to hard code it in.
-func (s *Store) Decrypt(passwordFunc func() (string, error)) error {
+func (s *Store) Decrypt(passwordFunc func() (string, error)) (err error) {
+	defer func() { s.recordAudit("decrypt", err) }()
+
 	encrypted, err := s.Encrypted()
 	if err != nil {
 		return err
@@ -78,3 +91,192 @@ func (s *Store) Decrypt(passwordFunc func() (string, error)) error {
 
 	return os.RemoveAll(s.entriesPath + ".gpg")
 }
+
+// compression returns the compression used for encrypting the store set in the config. A number of workers of zero or
+// less uses one for each CPU.
+func (s *Store) compression() encryption.Compression {
+	workers := s.config.GetInt("encryption.compression-workers")
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	return encryption.Compression{
+		Algorithm: s.config.GetString("encryption.compression"),
+		Level:     s.config.GetInt("encryption.compression-level"),
+		Workers:   workers,
+	}
+}
+
+// DecryptedEntry is an entry decrypted by DecryptEntry without decrypting the rest of the store.
+type DecryptedEntry struct {
+	Entry *entries.Entry
+
+	// Attachments are the other files in the entry's folder, keyed by their path relative to the folder using forward
+	// slashes, like "photos/pizza.jpg". Files inside sub-entries aren't included.
+	Attachments map[string][]byte
+}
+
+// DecryptEntry decrypts a single entry and its attachments into memory, leaving the store encrypted. It takes a path
+// relative to the entries folder, such as "food/pizza", and a password func like Decrypt.
+// If the store isn't encrypted, it returns ErrStoreDecrypted and if the entry doesn't exist, it returns
+// ErrEntryDoesntExist.
+func (s *Store) DecryptEntry(entryPath string, passwordFunc func() (string, error)) (*DecryptedEntry, error) {
+	decrypted, err := s.DecryptEntries([]string{entryPath}, passwordFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	return decrypted[0], nil
+}
+
+// DecryptEntries is like DecryptEntry but decrypts multiple entries at once, returning them in the order given. The
+// whole encrypted archive has to be read each time, so this is quicker than calling DecryptEntry for each.
+func (s *Store) DecryptEntries(paths []string, passwordFunc func() (string, error)) (decrypted []*DecryptedEntry, err error) {
+	defer func() { s.recordAudit("decrypt-entry", err, paths...) }()
+
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return nil, err
+	} else if !encrypted {
+		return nil, ErrStoreDecrypted{Path: s.Path}
+	}
+
+	pass, err := passwordFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	prefixes := make([]string, len(paths))
+	for i, entryPath := range paths {
+		prefixes[i] = strings.Trim(filepath.ToSlash(entryPath), "/") + "/"
+	}
+
+	files, err := encryption.DecryptFiles(
+		s.entriesPath+".gpg",
+		s.config.GetString("encryption.public-key"),
+		s.config.GetString("encryption.private-key"),
+		pass,
+		func(name string) bool {
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(name, prefix) {
+					return true
+				}
+			}
+
+			return false
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, prefix := range prefixes {
+		entry, err := s.decryptedEntry(strings.TrimSuffix(prefix, "/"), files)
+		if err != nil {
+			return nil, err
+		} else if entry == nil {
+			return nil, ErrEntryDoesntExist{Path: paths[i]}
+		}
+
+		decrypted = append(decrypted, entry)
+	}
+
+	return decrypted, nil
+}
+
+// DecryptCollection decrypts all the entries into memory and returns them as a collection, leaving the store encrypted.
+// Attachments aren't decrypted. Entries which can't be parsed are skipped, like when loading the store.
+// If the store isn't encrypted, it returns ErrStoreDecrypted.
+func (s *Store) DecryptCollection(passwordFunc func() (string, error)) (collection *entries.Collection, err error) {
+	defer func() { s.recordAudit("decrypt-collection", err) }()
+
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return nil, err
+	} else if !encrypted {
+		return nil, ErrStoreDecrypted{Path: s.Path}
+	}
+
+	pass, err := passwordFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := encryption.DecryptFiles(
+		s.entriesPath+".gpg",
+		s.config.GetString("encryption.public-key"),
+		s.config.GetString("encryption.private-key"),
+		pass,
+		func(name string) bool { return path.Base(name) == "entry.md" },
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	collection = entries.NewCollection()
+
+	for _, file := range files {
+		entry, err := entries.NewEntryFromContents(path.Dir(file.Name), file.Contents, file.ModTime, s.limits())
+		if err != nil {
+			logrus.Warn(err)
+			continue
+		}
+
+		err = collection.Add(entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return collection, nil
+}
+
+// decryptedEntry builds the entry at entryPath from the decrypted files. It returns nil if there's no entry.md for it.
+func (s *Store) decryptedEntry(entryPath string, files []encryption.DecryptedFile) (*DecryptedEntry, error) {
+	prefix := entryPath + "/"
+
+	// Folders inside the entry which contain their own entry.md are sub-entries, and their files aren't attachments.
+	subEntries := []string{}
+	for _, file := range files {
+		if strings.HasPrefix(file.Name, prefix) && path.Base(file.Name) == "entry.md" && file.Name != prefix+"entry.md" {
+			subEntries = append(subEntries, path.Dir(file.Name)+"/")
+		}
+	}
+
+	var decrypted *DecryptedEntry
+	attachments := map[string][]byte{}
+
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name, prefix) {
+			continue
+		}
+
+		if file.Name == prefix+"entry.md" {
+			entry, err := entries.NewEntryFromContents(entryPath, file.Contents, file.ModTime, s.limits())
+			if err != nil {
+				return nil, err
+			}
+
+			decrypted = &DecryptedEntry{Entry: entry}
+			continue
+		}
+
+		inSubEntry := false
+		for _, subEntry := range subEntries {
+			if strings.HasPrefix(file.Name, subEntry) {
+				inSubEntry = true
+				break
+			}
+		}
+
+		if !inSubEntry {
+			attachments[strings.TrimPrefix(file.Name, prefix)] = file.Contents
+		}
+	}
+
+	if decrypted != nil {
+		decrypted.Attachments = attachments
+	}
+
+	return decrypted, nil
+}
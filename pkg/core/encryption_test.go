@@ -0,0 +1,78 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreDecryptEntry(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	store.config.Set("encryption.private-key", filepath.Join(dir, "testdata", "keys", "private.key"))
+	store.config.Set("encryption.public-key", filepath.Join(dir, "testdata", "keys", "public.key"))
+
+	_, err = store.DecryptEntry("food/pizza", staticPassword("pa$$word"))
+	IsType(t, ErrStoreDecrypted{}, err, "expecting ErrStoreDecrypted when store isn't encrypted")
+
+	err = store.Create("food/pizza/toppings", "Pineapple.")
+	if err != nil {
+		t.Fatalf("not expecting error when creating sub-entry: %s", err)
+	}
+
+	before, err := store.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error getting collection: %s", err)
+	}
+
+	count := before.Len()
+
+	err = store.Encrypt()
+	if err != nil {
+		t.Fatalf("not expecting error when encrypting store: %s", err)
+	}
+
+	decrypted, err := store.DecryptEntry("food/pizza", staticPassword("pa$$word"))
+	if err != nil {
+		t.Fatalf("not expecting error when decrypting entry: %s", err)
+	}
+
+	Equal(t, "food/pizza", decrypted.Entry.Path)
+	Equal(t, "Pizza!", decrypted.Entry.Title)
+	Contains(t, decrypted.Attachments, "pizza.jpg", "expecting attachment to be decrypted")
+	NotContains(t, decrypted.Attachments, "toppings/entry.md", "sub-entries aren't attachments")
+
+	encrypted, err := store.Encrypted()
+	Nil(t, err)
+	True(t, encrypted, "store should still be encrypted")
+
+	multiple, err := store.DecryptEntries([]string{"moods/hunger", "food/pizza/toppings"}, staticPassword("pa$$word"))
+	if err != nil {
+		t.Fatalf("not expecting error when decrypting entries: %s", err)
+	}
+
+	if Len(t, multiple, 2) {
+		Equal(t, "moods/hunger", multiple[0].Entry.Path)
+		Equal(t, "Pineapple.", multiple[1].Entry.Contents)
+	}
+
+	collection, err := store.DecryptCollection(staticPassword("pa$$word"))
+	if err != nil {
+		t.Fatalf("not expecting error when decrypting collection: %s", err)
+	}
+
+	Equal(t, count, collection.Len(), "expecting the same entries as before encrypting")
+
+	_, err = store.DecryptEntry("food/nothing", staticPassword("pa$$word"))
+	IsType(t, ErrEntryDoesntExist{}, err, "expecting ErrEntryDoesntExist for entry that doesn't exist")
+
+	_, err = store.DecryptEntry("food/pizza", staticPassword("wrong"))
+	NotNil(t, err, "expecting error with the wrong password")
+}
@@ -12,6 +12,16 @@ func (e ErrStoreEncrypted) Error() string {
 	return fmt.Sprintf("store %s is currently encrypted", e.Path)
 }
 
+// ErrUnknownStore is returned when a Registry doesn't have a store with the name requested.
+type ErrUnknownStore struct {
+	Name string
+}
+
+// Error returns the error message.
+func (e ErrUnknownStore) Error() string {
+	return fmt.Sprintf("no store named '%s'", e.Name)
+}
+
 // ErrStoreDecrypted is returned when a store is asked to be decrypted but it's already decrypted.
 type ErrStoreDecrypted struct {
 	Path string
@@ -41,3 +51,24 @@ type ErrEntryAlreadyExists struct {
 func (e ErrEntryAlreadyExists) Error() string {
 	return fmt.Sprintf("entry %s already exists", e.Path)
 }
+
+// ErrNotUsingGit is returned when an action requires git but the store's entries aren't in a git repository.
+type ErrNotUsingGit struct {
+	Path string
+}
+
+// Error returns the error message.
+func (e ErrNotUsingGit) Error() string {
+	return fmt.Sprintf("store %s isn't using git", e.Path)
+}
+
+// ErrAnnotationDoesntExist is returned when the annotation requested doesn't exist.
+type ErrAnnotationDoesntExist struct {
+	Path string
+	ID   int
+}
+
+// Error returns the error message.
+func (e ErrAnnotationDoesntExist) Error() string {
+	return fmt.Sprintf("entry %s has no annotation %d", e.Path, e.ID)
+}
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// EXIF tags which hold the date a photo was taken, in order of preference. DateTimeOriginal and DateTimeDigitized are
+// in the EXIF IFD, which the IFD0 tag exifIFDPointer points to, and DateTime is in IFD0 itself.
+const (
+	exifTagDateTime          = 0x0132
+	exifTagIFDPointer        = 0x8769
+	exifTagDateTimeOriginal  = 0x9003
+	exifTagDateTimeDigitized = 0x9004
+)
+
+// exifDateLayout is the layout of dates in EXIF metadata.
+const exifDateLayout = "2006:01:02 15:04:05"
+
+// exifMaxEntries is the most entries read from a single IFD, so that a corrupt file can't make ExifDate allocate too
+// much.
+const exifMaxEntries = 1024
+
+// errNoExif is returned by the EXIF readers when a file doesn't contain any EXIF metadata.
+var errNoExif = errors.New("no EXIF metadata")
+
+// ExifDate returns the date a photo was taken, from the EXIF metadata of a JPEG or TIFF file, such as most camera raw
+// formats. The date is the time shown on the camera's clock, given in UTC since EXIF doesn't usually say which time zone
+// it was in. If the file doesn't have a date, or isn't a JPEG or TIFF file, ok is false. An error is only returned if
+// the file can't be read.
+func ExifDate(path string) (date time.Time, ok bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 4)
+	_, err = io.ReadFull(f, header)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return time.Time{}, false, nil
+	} else if err != nil {
+		return time.Time{}, false, err
+	}
+
+	var tiff *io.SectionReader
+
+	switch {
+	case header[0] == 0xFF && header[1] == 0xD8:
+		tiff, err = jpegExif(f)
+	case bytes.Equal(header, []byte("II*\x00")) || bytes.Equal(header, []byte("MM\x00*")):
+		tiff = io.NewSectionReader(f, 0, 1<<62)
+	default:
+		return time.Time{}, false, nil
+	}
+
+	if err == errNoExif {
+		return time.Time{}, false, nil
+	} else if err != nil {
+		return time.Time{}, false, err
+	}
+
+	date, ok = tiffDate(tiff)
+	return date, ok, nil
+}
+
+// jpegExif finds the APP1 segment containing EXIF metadata in a JPEG file and returns the TIFF structure inside it.
+func jpegExif(f *os.File) (*io.SectionReader, error) {
+	offset := int64(2)
+	marker := make([]byte, 4)
+
+	for {
+		_, err := f.ReadAt(marker, offset)
+		if err == io.EOF {
+			return nil, errNoExif
+		} else if err != nil {
+			return nil, err
+		}
+
+		if marker[0] != 0xFF {
+			return nil, errNoExif
+		}
+
+		length := int64(binary.BigEndian.Uint16(marker[2:]))
+
+		switch marker[1] {
+		case 0xE1:
+			ident := make([]byte, 6)
+			_, err = f.ReadAt(ident, offset+4)
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+
+			if bytes.Equal(ident, []byte("Exif\x00\x00")) {
+				return io.NewSectionReader(f, offset+10, length-8), nil
+			}
+
+		case 0xDA, 0xD9:
+			// The image data or the end of the image, after which there's no more metadata.
+			return nil, errNoExif
+		}
+
+		offset += 2 + length
+	}
+}
+
+// tiffDate reads the date a photo was taken from a TIFF structure.
+func tiffDate(r io.ReaderAt) (time.Time, bool) {
+	header := make([]byte, 8)
+	_, err := r.ReadAt(header, 0)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var order binary.ByteOrder
+	switch string(header[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return time.Time{}, false
+	}
+
+	ifd0 := readIFD(r, order, int64(order.Uint32(header[4:])))
+
+	if pointer, ok := ifd0[exifTagIFDPointer]; ok {
+		exifIFD := readIFD(r, order, int64(order.Uint32(pointer.value)))
+
+		for _, tag := range []uint16{exifTagDateTimeOriginal, exifTagDateTimeDigitized} {
+			if date, ok := exifTime(r, order, exifIFD[tag]); ok {
+				return date, true
+			}
+		}
+	}
+
+	return exifTime(r, order, ifd0[exifTagDateTime])
+}
+
+// ifdEntry is an entry in a TIFF image file directory. value is the 4 bytes holding either the value itself, if it fits,
+// or the offset to it.
+type ifdEntry struct {
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+// readIFD reads the entries of the image file directory at the offset given, by tag. Entries which can't be read are
+// left out.
+func readIFD(r io.ReaderAt, order binary.ByteOrder, offset int64) map[uint16]ifdEntry {
+	ifd := map[uint16]ifdEntry{}
+
+	countBytes := make([]byte, 2)
+	_, err := r.ReadAt(countBytes, offset)
+	if err != nil {
+		return ifd
+	}
+
+	count := int(order.Uint16(countBytes))
+	if count > exifMaxEntries {
+		return ifd
+	}
+
+	data := make([]byte, count*12)
+	_, err = r.ReadAt(data, offset+2)
+	if err != nil {
+		return ifd
+	}
+
+	for i := 0; i < count; i++ {
+		entry := data[i*12 : (i+1)*12]
+
+		ifd[order.Uint16(entry)] = ifdEntry{
+			typ:   order.Uint16(entry[2:]),
+			count: order.Uint32(entry[4:]),
+			value: entry[8:12],
+		}
+	}
+
+	return ifd
+}
+
+// exifTime parses a date stored in an ASCII IFD entry.
+func exifTime(r io.ReaderAt, order binary.ByteOrder, entry ifdEntry) (time.Time, bool) {
+	const ascii = 2
+
+	if entry.typ != ascii || entry.count < uint32(len(exifDateLayout)) || entry.count > 64 {
+		return time.Time{}, false
+	}
+
+	// Dates are always longer than 4 bytes, so the value is the offset to them.
+	value := make([]byte, entry.count)
+	_, err := r.ReadAt(value, int64(order.Uint32(entry.value)))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	date, err := time.Parse(exifDateLayout, strings.TrimRight(string(value), "\x00 "))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return date, true
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+// exifTIFF returns a little-endian TIFF structure with the date given as its DateTimeOriginal, inside an EXIF IFD.
+func exifTIFF(date string) []byte {
+	var b bytes.Buffer
+	le := binary.LittleEndian
+
+	b.WriteString("II*\x00")
+	binary.Write(&b, le, uint32(8))
+
+	// IFD0, with a pointer to the EXIF IFD at 26.
+	binary.Write(&b, le, uint16(1))
+	binary.Write(&b, le, []uint16{exifTagIFDPointer, 4})
+	binary.Write(&b, le, []uint32{1, 26, 0})
+
+	// The EXIF IFD, with the date at 44.
+	binary.Write(&b, le, uint16(1))
+	binary.Write(&b, le, []uint16{exifTagDateTimeOriginal, 2})
+	binary.Write(&b, le, []uint32{uint32(len(date) + 1), 44, 0})
+
+	b.WriteString(date + "\x00")
+
+	return b.Bytes()
+}
+
+// exifJPEG returns the start of a JPEG file with the date given in its EXIF metadata.
+func exifJPEG(date string) []byte {
+	tiff := exifTIFF(date)
+
+	var b bytes.Buffer
+	b.Write([]byte{0xFF, 0xD8})
+
+	// An APP0 segment before the EXIF one, like most cameras write.
+	b.Write([]byte{0xFF, 0xE0, 0x00, 0x07})
+	b.WriteString("JFIF\x00")
+
+	b.Write([]byte{0xFF, 0xE1})
+	binary.Write(&b, binary.BigEndian, uint16(len(tiff)+8))
+	b.WriteString("Exif\x00\x00")
+	b.Write(tiff)
+
+	b.Write([]byte{0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9})
+
+	return b.Bytes()
+}
+
+func TestExifDate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "albatross-exif-test")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %s", err)
+	}
+
+	files := map[string][]byte{
+		"photo.jpg":     exifJPEG("2020:07:14 09:12:30"),
+		"photo.tiff":    exifTIFF("2020:07:15 18:40:00"),
+		"plain.jpg":     {0xFF, 0xD8, 0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9},
+		"notes.txt":     []byte("not a photo"),
+		"empty.jpg":     {},
+		"truncated.jpg": exifJPEG("2020:07:14 09:12:30")[:30],
+	}
+
+	for name, data := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, name), data, 0644)
+		if err != nil {
+			t.Fatalf("couldn't write %s: %s", name, err)
+		}
+	}
+
+	date, ok, err := ExifDate(filepath.Join(dir, "photo.jpg"))
+	Nil(t, err)
+	True(t, ok, "expecting a date from a JPEG with EXIF metadata")
+	Equal(t, time.Date(2020, 7, 14, 9, 12, 30, 0, time.UTC), date)
+
+	date, ok, err = ExifDate(filepath.Join(dir, "photo.tiff"))
+	Nil(t, err)
+	True(t, ok, "expecting a date from a TIFF file")
+	Equal(t, time.Date(2020, 7, 15, 18, 40, 0, 0, time.UTC), date)
+
+	for _, name := range []string{"plain.jpg", "notes.txt", "empty.jpg", "truncated.jpg"} {
+		_, ok, err = ExifDate(filepath.Join(dir, name))
+		Nil(t, err, "not expecting an error for %s", name)
+		False(t, ok, "not expecting a date for %s", name)
+	}
+
+	_, _, err = ExifDate(filepath.Join(dir, "missing.jpg"))
+	NotNil(t, err, "expecting an error for a file which doesn't exist")
+}
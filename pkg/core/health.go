@@ -0,0 +1,151 @@
+package core
+
+import (
+	"sort"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// HealthReport gathers everything known about the health of a store into one place, for a periodic review of its
+// hygiene. It's made by HealthReport.
+type HealthReport struct {
+	// Generated is when the report was made.
+	Generated time.Time `json:"generated"`
+
+	// LoadErrors are the entries which couldn't be loaded at all, such as because their front matter isn't valid YAML.
+	// Their checks are the ones from Lint like LintParseFailed.
+	LoadErrors []LintIssue `json:"loadErrors"`
+
+	// Lint are the problems with the contents of individual entries found by Lint, apart from LoadErrors.
+	Lint []LintIssue `json:"lint"`
+
+	// Links are the broken links and duplicate titles found by Check.
+	Links []CheckProblem `json:"links"`
+
+	// Attachments are the missing and orphaned attachments and dangling symlinks found by Check.
+	Attachments []CheckProblem `json:"attachments"`
+
+	// Orphans are the paths of entries which don't link to any entry and which no entry links to, sorted by path.
+	Orphans []string `json:"orphans"`
+
+	// State is the state of the store, including the last commit and any changes which haven't been committed.
+	State *State `json:"state"`
+
+	// Stats are statistics about the size and growth of the store.
+	Stats *Stats `json:"stats"`
+}
+
+// Problems returns the number of problems in the report, not counting orphaned entries or uncommitted changes which
+// aren't necessarily problems.
+func (r *HealthReport) Problems() int {
+	return len(r.LoadErrors) + len(r.Lint) + len(r.Links) + len(r.Attachments)
+}
+
+// HealthReport checks the store using both Lint and Check, looks for orphaned entries and gets its current state and
+// statistics. If the store is encrypted, it returns ErrStoreEncrypted.
+func (s *Store) HealthReport() (*HealthReport, error) {
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return nil, err
+	} else if encrypted {
+		return nil, ErrStoreEncrypted{Path: s.Path}
+	}
+
+	report := &HealthReport{
+		Generated:   time.Now(),
+		LoadErrors:  []LintIssue{},
+		Lint:        []LintIssue{},
+		Links:       []CheckProblem{},
+		Attachments: []CheckProblem{},
+	}
+
+	issues, err := s.Lint()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, issue := range issues {
+		switch issue.Check {
+		case LintUnreadable, LintParseFailed, LintTooLarge, LintBinary:
+			report.LoadErrors = append(report.LoadErrors, issue)
+		default:
+			report.Lint = append(report.Lint, issue)
+		}
+	}
+
+	problems, err := s.Check()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, problem := range problems {
+		switch problem.Check {
+		case CheckBrokenLink, CheckDuplicateTitle, CheckAliasCollision:
+			report.Links = append(report.Links, problem)
+		case CheckMissingAttachment, CheckOrphanedAttachment, CheckDanglingSymlink:
+			report.Attachments = append(report.Attachments, problem)
+		}
+
+		// Anything else is an entry which couldn't be loaded, which Lint has already found.
+	}
+
+	report.Orphans, err = s.orphans()
+	if err != nil {
+		return nil, err
+	}
+
+	report.State, err = s.State()
+	if err != nil {
+		return nil, err
+	}
+
+	report.Stats, err = s.Stats()
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// orphans returns the paths of entries which don't link to any entry and which no entry links to, sorted by path.
+func (s *Store) orphans() ([]string, error) {
+	collection, err := s.Collection()
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := []string{}
+
+	err = collection.ForEach(nil, func(entry *entries.Entry) error {
+		linked := false
+
+		// Entries which only link to themselves are still orphans.
+		for _, from := range collection.Backlinks(entry) {
+			if from != entry {
+				linked = true
+				break
+			}
+		}
+
+		for _, link := range entry.OutboundLinks {
+			if to := collection.ResolveLink(link); to != nil && to != entry {
+				linked = true
+				break
+			}
+		}
+
+		if !linked {
+			orphans = append(orphans, entry.Path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(orphans)
+
+	return orphans, nil
+}
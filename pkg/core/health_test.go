@@ -0,0 +1,57 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreHealthReport(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	err = store.Create("notes/alone", "---\ntitle: Alone\n---\n\nNobody links to me, and I only link to [[Alone]].")
+	if err != nil {
+		t.Fatalf("not expecting error creating entry: %s", err)
+	}
+
+	err = store.Create("notes/invalid", "---\ntitle: [Invalid\n---\n\nThis front matter isn't valid.")
+	if err != nil {
+		t.Fatalf("not expecting error creating entry: %s", err)
+	}
+
+	report, err := store.HealthReport()
+	if err != nil {
+		t.Fatalf("not expecting error making health report: %s", err)
+	}
+
+	if Len(t, report.LoadErrors, 1, "expecting the invalid entry not to load") {
+		Equal(t, "notes/invalid", report.LoadErrors[0].Path)
+		Equal(t, LintParseFailed, report.LoadErrors[0].Check)
+	}
+
+	if Len(t, report.Links, 1, "expecting only the broken link in the test store") {
+		Equal(t, CheckBrokenLink, report.Links[0].Check)
+	}
+
+	Empty(t, report.Attachments)
+	Contains(t, report.Orphans, "notes/alone", "expecting entries which only link to themselves to be orphans")
+	NotContains(t, report.Orphans, "food/pizza")
+	Equal(t, 2+len(report.Lint), report.Problems(), "expecting the entry which didn't load not to be counted twice")
+	NotNil(t, report.State)
+
+	collection, err := store.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error getting collection: %s", err)
+	}
+
+	if NotNil(t, report.Stats) {
+		Equal(t, collection.Len(), report.Stats.Entries)
+	}
+}
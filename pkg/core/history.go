@@ -0,0 +1,304 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sirupsen/logrus"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// LastModified returns the time of the most recent git commit which changed the entry at the path given, or one of
+// the attachments next to it. The path is relative to the entries folder, such as "food/pizza".
+// If the store isn't using git, or the entry has never been committed, it returns the zero time.
+//
+// The first call walks the whole of the git history, so it can be slow for large stores. The result is cached until the
+// store next changes.
+func (s *Store) LastModified(entryPath string) (time.Time, error) {
+	if s.repo == nil {
+		return time.Time{}, nil
+	}
+
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	if s.lastModified == nil {
+		lastModified, err := s.buildLastModified()
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		s.lastModified = lastModified
+	}
+
+	return s.lastModified[entryPath], nil
+}
+
+// buildLastModified walks the git history from HEAD and returns a map of folder paths to the time they were last
+// changed by a commit.
+func (s *Store) buildLastModified() (map[string]time.Time, error) {
+	lastModified := make(map[string]time.Time)
+
+	iter, err := s.repo.Log(&git.LogOptions{})
+	if err == plumbing.ErrReferenceNotFound {
+		return lastModified, nil // No commits yet.
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = iter.ForEach(func(commit *object.Commit) error {
+		tree, err := commit.Tree()
+		if err != nil {
+			return err
+		}
+
+		var parentTree *object.Tree
+
+		if commit.NumParents() > 0 {
+			parent, err := commit.Parent(0)
+			if err != nil {
+				return err
+			}
+
+			parentTree, err = parent.Tree()
+			if err != nil {
+				return err
+			}
+		}
+
+		changes, err := object.DiffTree(parentTree, tree)
+		if err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			for _, name := range []string{change.From.Name, change.To.Name} {
+				if name == "" {
+					continue
+				}
+
+				dir := path.Dir(name)
+				if when, ok := lastModified[dir]; !ok || commit.Committer.When.After(when) {
+					lastModified[dir] = commit.Committer.When
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return lastModified, nil
+}
+
+// CollectionAt returns the collection of entries as they were at a git revision, such as "HEAD~5", a branch or a commit
+// hash. Entries are parsed from the files in the commit rather than from disk, so the working tree isn't touched. Entries
+// which can't be parsed are skipped, like when loading the store.
+// If the store is encrypted, it returns ErrStoreEncrypted and if it isn't using git, it returns ErrNotUsingGit.
+func (s *Store) CollectionAt(revision string) (*entries.Collection, error) {
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return nil, err
+	} else if encrypted {
+		return nil, ErrStoreEncrypted{Path: s.Path}
+	}
+
+	if s.repo == nil {
+		return nil, ErrNotUsingGit{Path: s.Path}
+	}
+
+	hash, err := s.repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't resolve revision %q: %w", revision, err)
+	}
+
+	commit, err := s.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	limits := s.limits()
+	collection := entries.NewCollection()
+
+	err = tree.Files().ForEach(func(file *object.File) error {
+		if path.Base(file.Name) != "entry.md" {
+			return nil
+		}
+
+		entryPath := path.Dir(file.Name)
+
+		if limits.MaxSize > 0 && file.Size > limits.MaxSize {
+			logrus.Warn(entries.ErrEntryTooLarge{Path: entryPath, Size: file.Size, Limit: limits.MaxSize})
+			return nil
+		}
+
+		contents, err := file.Contents()
+		if err != nil {
+			return err
+		}
+
+		entry, err := entries.NewEntryFromContents(entryPath, []byte(contents), commit.Committer.When, limits)
+		if err != nil {
+			logrus.Warn(err)
+			return nil
+		}
+
+		return collection.Add(entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+// ChangeKind is the way a commit changed an entry.
+type ChangeKind string
+
+// The ways a commit can change an entry.
+const (
+	EntryCreated ChangeKind = "created"
+	EntryEdited  ChangeKind = "edited"
+	EntryDeleted ChangeKind = "deleted"
+)
+
+// EntryChange is a change to an entry.md file made by a git commit.
+type EntryChange struct {
+	// Path is the path to the entry, such as "food/pizza".
+	Path string `json:"path"`
+
+	// Kind is whether the entry was created, edited or deleted.
+	Kind ChangeKind `json:"kind"`
+
+	// Time is when the change was committed.
+	Time time.Time `json:"time"`
+
+	// Commit is the hash of the commit and Message is its message.
+	Commit  string `json:"commit"`
+	Message string `json:"message"`
+
+	// Additions and Deletions are the number of lines added to and removed from the entry.md file.
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+}
+
+// Changes returns the changes to entries made by git commits since the time given, oldest first. Only changes to entry.md
+// files are included, not to attachments. Merge commits are compared against their first parent.
+// If the store is encrypted, it returns ErrStoreEncrypted and if it isn't using git, it returns ErrNotUsingGit.
+func (s *Store) Changes(since time.Time) ([]EntryChange, error) {
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return nil, err
+	} else if encrypted {
+		return nil, ErrStoreEncrypted{Path: s.Path}
+	}
+
+	if s.repo == nil {
+		return nil, ErrNotUsingGit{Path: s.Path}
+	}
+
+	changes := []EntryChange{}
+
+	iter, err := s.repo.Log(&git.LogOptions{Since: &since})
+	if err == plumbing.ErrReferenceNotFound {
+		return changes, nil // No commits yet.
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = iter.ForEach(func(commit *object.Commit) error {
+		tree, err := commit.Tree()
+		if err != nil {
+			return err
+		}
+
+		var parentTree *object.Tree
+
+		if commit.NumParents() > 0 {
+			parent, err := commit.Parent(0)
+			if err != nil {
+				return err
+			}
+
+			parentTree, err = parent.Tree()
+			if err != nil {
+				return err
+			}
+		}
+
+		treeChanges, err := object.DiffTree(parentTree, tree)
+		if err != nil {
+			return err
+		}
+
+		commitChanges := []EntryChange{}
+
+		for _, treeChange := range treeChanges {
+			name := treeChange.To.Name
+			if name == "" {
+				name = treeChange.From.Name
+			}
+
+			if path.Base(name) != "entry.md" {
+				continue
+			}
+
+			change := EntryChange{
+				Path:    path.Dir(name),
+				Time:    commit.Committer.When,
+				Commit:  commit.Hash.String(),
+				Message: strings.TrimSpace(commit.Message),
+			}
+
+			switch {
+			case treeChange.From.Name == "":
+				change.Kind = EntryCreated
+			case treeChange.To.Name == "":
+				change.Kind = EntryDeleted
+			default:
+				change.Kind = EntryEdited
+			}
+
+			patch, err := treeChange.Patch()
+			if err != nil {
+				return err
+			}
+
+			for _, stat := range patch.Stats() {
+				change.Additions += stat.Addition
+				change.Deletions += stat.Deletion
+			}
+
+			commitChanges = append(commitChanges, change)
+		}
+
+		// The log goes from newest to oldest, so each commit's changes go before the ones already seen.
+		changes = append(commitChanges, changes...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Commits aren't always in order of time, such as after a rebase.
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Time.Before(changes[j].Time)
+	})
+
+	return changes, nil
+}
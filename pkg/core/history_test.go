@@ -0,0 +1,155 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+
+	"github.com/albatross-org/go-albatross/entries"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreLastModified(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+
+	_, err := git.PlainInit(filepath.Join(storePath, "entries"), false)
+	if err != nil {
+		t.Fatalf("not expecting error when initialising git repository: %s", err)
+	}
+
+	store, err := Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	modified, err := store.LastModified("food/truffles")
+	Nil(t, err, "not expecting error getting last modified time with no commits")
+	True(t, modified.IsZero(), "entry that doesn't exist should have a zero last modified time")
+
+	before := time.Now().Add(-time.Second)
+
+	err = store.Create("food/truffles", "Truffles are great.")
+	if err != nil {
+		t.Fatalf("not expecting error when creating truffles entry: %s", err)
+	}
+
+	modified, err = store.LastModified("food/truffles")
+	Nil(t, err, "not expecting error getting last modified time")
+	True(t, modified.After(before), "last modified time should be the time of the commit")
+
+	modified, err = store.LastModified("food/pizza")
+	Nil(t, err, "not expecting error getting last modified time")
+	True(t, modified.IsZero(), "uncommitted entry should have a zero last modified time")
+}
+
+func TestStoreCollectionAt(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+
+	store, err := Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	_, err = store.CollectionAt("HEAD")
+	IsType(t, ErrNotUsingGit{}, err, "expecting ErrNotUsingGit when store isn't using git")
+
+	_, err = git.PlainInit(filepath.Join(storePath, "entries"), false)
+	if err != nil {
+		t.Fatalf("not expecting error when initialising git repository: %s", err)
+	}
+
+	store, err = Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	err = store.Create("food/truffles", "Truffles are great.")
+	if err != nil {
+		t.Fatalf("not expecting error when creating truffles entry: %s", err)
+	}
+
+	err = store.Update("food/truffles", "Truffles are okay.")
+	if err != nil {
+		t.Fatalf("not expecting error when updating truffles entry: %s", err)
+	}
+
+	before, err := store.CollectionAt("HEAD~1")
+	if err != nil {
+		t.Fatalf("not expecting error getting collection at HEAD~1: %s", err)
+	}
+
+	Equal(t, 1, before.Len(), "only the truffles entry has been committed")
+
+	entry := before.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: "food/truffles"})
+	if NotNil(t, entry, "expecting truffles entry in past collection") {
+		Equal(t, "Truffles are great.", entry.Contents)
+	}
+
+	_, err = store.CollectionAt("not-a-revision")
+	NotNil(t, err, "expecting error for a revision that doesn't exist")
+}
+
+func TestStoreChanges(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+
+	_, err := git.PlainInit(filepath.Join(storePath, "entries"), false)
+	if err != nil {
+		t.Fatalf("not expecting error when initialising git repository: %s", err)
+	}
+
+	store, err := Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	changes, err := store.Changes(time.Time{})
+	Nil(t, err, "not expecting error getting changes with no commits")
+	Empty(t, changes, "expecting no changes with no commits")
+
+	err = store.Create("food/truffles", "Truffles are great.")
+	if err != nil {
+		t.Fatalf("not expecting error when creating truffles entry: %s", err)
+	}
+
+	err = store.Update("food/truffles", "Truffles are great.\nEspecially on pasta.\n")
+	if err != nil {
+		t.Fatalf("not expecting error when updating truffles entry: %s", err)
+	}
+
+	err = store.Delete("food/truffles")
+	if err != nil {
+		t.Fatalf("not expecting error when deleting truffles entry: %s", err)
+	}
+
+	changes, err = store.Changes(time.Time{})
+	if err != nil {
+		t.Fatalf("not expecting error getting changes: %s", err)
+	}
+
+	if Len(t, changes, 3, "expecting a change for each commit") {
+		Equal(t, EntryCreated, changes[0].Kind)
+		Equal(t, EntryEdited, changes[1].Kind)
+		Equal(t, EntryDeleted, changes[2].Kind)
+
+		Equal(t, "food/truffles", changes[1].Path)
+		Equal(t, 2, changes[1].Additions)
+		Equal(t, 1, changes[1].Deletions)
+		Equal(t, "(go-albatross) Update food/truffles", changes[1].Message)
+	}
+
+	changes, err = store.Changes(time.Now().Add(time.Hour))
+	Nil(t, err, "not expecting error getting future changes")
+	Empty(t, changes, "expecting no changes in the future")
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"path/filepath"
+)
+
+// The defaults for the "journal" section of the config, used by the journal package and 'albatross journal'.
+const (
+	// DefaultJournalLayout is the Go time layout of the paths of journal entries if "journal.layout" isn't set.
+	DefaultJournalLayout = "journal/2006-01-02"
+
+	// DefaultJournalTitle is the Go time layout of the titles of new journal entries if "journal.title" isn't set.
+	DefaultJournalTitle = "Monday 2 January 2006"
+
+	// DefaultJournalPrompts is the file writing prompts are read from, relative to the root of the store, if
+	// "journal.prompts" isn't set.
+	DefaultJournalPrompts = "prompts.txt"
+)
+
+// JournalLayout returns the Go time layout of the paths of journal entries, set by "journal.layout" in the config, such as
+// "journal/2006/01/02" for "journal/2021/02/17".
+func (s *Store) JournalLayout() string {
+	return s.config.GetString("journal.layout")
+}
+
+// JournalTitle returns the Go time layout of the titles given to new journal entries, set by "journal.title".
+func (s *Store) JournalTitle() string {
+	return s.config.GetString("journal.title")
+}
+
+// JournalPrompts returns the path to the file writing prompts are read from, set by "journal.prompts". Relative paths are
+// relative to the root of the store.
+func (s *Store) JournalPrompts() string {
+	prompts := s.config.GetString("journal.prompts")
+	if filepath.IsAbs(prompts) {
+		return prompts
+	}
+
+	return filepath.Join(s.Path, prompts)
+}
@@ -0,0 +1,261 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Large attachments are attachments above the size set by "attachments.large-threshold" in the store's config. Instead
+// of being copied into the entry's folder, the file is stored by the hash of its contents and a small pointer file is
+// left in its place, so that git doesn't have to store every version of it.
+//
+// The pointer files use the Git LFS format. Where the contents are stored depends on "attachments.large-storage":
+//
+//	lfs       The contents are stored in the git repository's LFS object directory and the attachment is added to
+//	          .gitattributes, so that running 'git lfs push' uploads them to an LFS server like any other LFS file.
+//	external  The contents are stored in the directory set by "attachments.external-path", by default "annex" in the
+//	          store directory, which can be synced separately or not at all.
+const (
+	LargeStorageLFS      = "lfs"
+	LargeStorageExternal = "external"
+)
+
+// lfsPointerVersion is the first line of a Git LFS pointer file.
+const lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+
+// maxPointerSize is the largest a valid pointer file can be, so larger files don't have to be read to check them.
+const maxPointerSize = 1024
+
+// ErrAttachmentMissing is returned when a large attachment's contents aren't available, such as when they haven't been
+// synced from another machine.
+type ErrAttachmentMissing struct {
+	Path string
+	OID  string
+}
+
+// Error returns the error message.
+func (e ErrAttachmentMissing) Error() string {
+	return fmt.Sprintf("contents of large attachment %s (sha256:%s) aren't available", e.Path, e.OID)
+}
+
+// largeThreshold returns the size in bytes above which attachments are stored as large attachments, or zero if large
+// attachments are disabled.
+func (s *Store) largeThreshold() int64 {
+	return int64(s.config.GetSizeInBytes("attachments.large-threshold"))
+}
+
+// largeStorage returns how large attachments are stored, either LargeStorageLFS or LargeStorageExternal.
+func (s *Store) largeStorage() (string, error) {
+	storage := s.config.GetString("attachments.large-storage")
+
+	switch storage {
+	case LargeStorageLFS, LargeStorageExternal:
+		return storage, nil
+	default:
+		return "", fmt.Errorf("unknown large attachment storage %q, must be %q or %q", storage, LargeStorageLFS, LargeStorageExternal)
+	}
+}
+
+// objectPath returns the path that the contents of a large attachment with the given SHA-256 hash are stored at.
+func (s *Store) objectPath(oid string) (string, error) {
+	if !validOID(oid) {
+		return "", fmt.Errorf("invalid large attachment hash %q", oid)
+	}
+
+	storage, err := s.largeStorage()
+	if err != nil {
+		return "", err
+	}
+
+	var dir string
+
+	switch storage {
+	case LargeStorageLFS:
+		dir = filepath.Join(s.entriesPath, ".git", "lfs", "objects")
+	case LargeStorageExternal:
+		dir = s.config.GetString("attachments.external-path")
+		if dir == "" {
+			dir = filepath.Join(s.Path, "annex")
+		}
+	}
+
+	return filepath.Join(dir, oid[0:2], oid[2:4], oid), nil
+}
+
+// attachLarge stores the file at src as a large attachment, writing a pointer file to dest. rel is the path of dest
+// relative to the entries folder.
+func (s *Store) attachLarge(src, dest, rel string) error {
+	oid, size, err := hashFile(src)
+	if err != nil {
+		return err
+	}
+
+	object, err := s.objectPath(oid)
+	if err != nil {
+		return err
+	}
+
+	if !exists(object) {
+		err = os.MkdirAll(filepath.Dir(object), 0755)
+		if err != nil {
+			return err
+		}
+
+		err = copyFile(src, object)
+		if err != nil {
+			return fmt.Errorf("cannot store large attachment %s: %w", src, err)
+		}
+	}
+
+	pointer := fmt.Sprintf("%s\noid sha256:%s\nsize %d\n", lfsPointerVersion, oid, size)
+
+	err = ioutil.WriteFile(dest, []byte(pointer), 0644)
+	if err != nil {
+		return err
+	}
+
+	storage, _ := s.largeStorage()
+	if storage == LargeStorageLFS {
+		return s.trackLFS(rel)
+	}
+
+	return nil
+}
+
+// trackLFS adds a path, relative to the entries folder, to the .gitattributes file so that Git LFS treats it as an LFS
+// file, and stages the change.
+func (s *Store) trackLFS(rel string) error {
+	attributesPath := filepath.Join(s.entriesPath, ".gitattributes")
+
+	// Spaces separate patterns from attributes, so they need escaping.
+	pattern := strings.ReplaceAll(filepath.ToSlash(rel), " ", "[[:space:]]")
+	line := pattern + " filter=lfs diff=lfs merge=lfs -text\n"
+
+	f, err := os.OpenFile(attributesPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(line)
+	if err != nil {
+		return err
+	}
+
+	if s.repo != nil && !s.disableGit {
+		_, err = s.worktree.Add(".gitattributes")
+		return err
+	}
+
+	return nil
+}
+
+// ResolveAttachment returns the path to the contents of the attachment at the path given, relative to the entries
+// folder, such as "food/pizza/photo.jpg". For most attachments this is the file itself, but for large attachments it's
+// where the contents are stored. If the contents of a large attachment aren't available, it returns
+// ErrAttachmentMissing.
+func (s *Store) ResolveAttachment(path string) (string, error) {
+	full := filepath.Join(s.entriesPath, path)
+
+	oid, _, ok, err := readPointer(full)
+	if err != nil {
+		return "", err
+	} else if !ok {
+		return full, nil
+	}
+
+	object, err := s.objectPath(oid)
+	if err != nil {
+		return "", err
+	}
+
+	if !exists(object) {
+		return "", ErrAttachmentMissing{Path: path, OID: oid}
+	}
+
+	return object, nil
+}
+
+// readPointer reads the Git LFS pointer file at path, returning the hash and size of the contents it points to. ok is
+// false if the file isn't a pointer file.
+func readPointer(path string) (oid string, size int64, ok bool, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, false, err
+	}
+
+	if info.IsDir() || info.Size() > maxPointerSize {
+		return "", 0, false, nil
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", 0, false, err
+	}
+
+	if !bytes.HasPrefix(contents, []byte(lfsPointerVersion+"\n")) {
+		return "", 0, false, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		key := strings.SplitN(scanner.Text(), " ", 2)
+		if len(key) != 2 {
+			continue
+		}
+
+		switch key[0] {
+		case "oid":
+			oid = strings.TrimPrefix(key[1], "sha256:")
+		case "size":
+			size, err = strconv.ParseInt(key[1], 10, 64)
+			if err != nil {
+				return "", 0, false, nil
+			}
+		}
+	}
+
+	// The oid becomes part of a path, so anything other than a hash could point outside the object store.
+	if !validOID(oid) {
+		return "", 0, false, nil
+	}
+
+	return oid, size, true, nil
+}
+
+// validOID returns true if oid is a lowercase hex-encoded SHA-256 hash.
+func validOID(oid string) bool {
+	if len(oid) != sha256.Size*2 || strings.ToLower(oid) != oid {
+		return false
+	}
+
+	_, err := hex.DecodeString(oid)
+	return err == nil
+}
+
+// hashFile returns the hex-encoded SHA-256 hash and size of the file at path.
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
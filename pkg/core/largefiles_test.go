@@ -0,0 +1,154 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+// loadWithConfig loads the test store after appending extra configuration to its config file.
+func loadWithConfig(t *testing.T, storePath, extra string) *Store {
+	t.Helper()
+
+	f, err := os.OpenFile(filepath.Join(storePath, "config.yaml"), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("not expecting error opening config file: %s", err)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString("\n" + extra)
+	if err != nil {
+		t.Fatalf("not expecting error writing config file: %s", err)
+	}
+
+	store, err := Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	return store
+}
+
+func TestStoreAttachLargeExternal(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+	store := loadWithConfig(t, storePath, "attachments:\n  large-threshold: 1kb\n  large-storage: external\n")
+
+	err := store.Attach("food/pizza", filepath.Join(dir, "testdata", "truffle.jpg"))
+	if err != nil {
+		t.Fatalf("not expecting error attaching large file: %s", err)
+	}
+
+	pointer, err := ioutil.ReadFile(filepath.Join(storePath, "entries", "food", "pizza", "truffle.jpg"))
+	Nil(t, err, "not expecting error reading pointer file")
+	Contains(t, string(pointer), lfsPointerVersion, "attachment should be replaced by a pointer file")
+
+	resolved, err := store.ResolveAttachment("food/pizza/truffle.jpg")
+	Nil(t, err, "not expecting error resolving attachment")
+	True(t, filepath.HasPrefix(resolved, filepath.Join(storePath, "annex")), "contents should be in the annex directory")
+
+	original, _ := ioutil.ReadFile(filepath.Join(dir, "testdata", "truffle.jpg"))
+	contents, _ := ioutil.ReadFile(resolved)
+	Equal(t, original, contents, "resolved attachment should have the original contents")
+
+	err = os.RemoveAll(filepath.Join(storePath, "annex"))
+	if err != nil {
+		t.Fatalf("not expecting error removing annex: %s", err)
+	}
+
+	_, err = store.ResolveAttachment("food/pizza/truffle.jpg")
+	IsType(t, ErrAttachmentMissing{}, err, "missing contents should give ErrAttachmentMissing")
+
+	resolved, err = store.ResolveAttachment("food/pizza/entry.md")
+	Nil(t, err, "not expecting error resolving ordinary file")
+	Equal(t, filepath.Join(storePath, "entries", "food", "pizza", "entry.md"), resolved, "ordinary files should resolve to themselves")
+}
+
+func TestStoreAttachLargeLFS(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+
+	_, err := git.PlainInit(filepath.Join(storePath, "entries"), false)
+	if err != nil {
+		t.Fatalf("not expecting error when initialising git repository: %s", err)
+	}
+
+	store := loadWithConfig(t, storePath, "attachments:\n  large-threshold: 1kb\n  large-storage: lfs\n")
+
+	err = store.Attach("food/pizza", filepath.Join(dir, "testdata", "truffle.jpg"))
+	if err != nil {
+		t.Fatalf("not expecting error attaching large file: %s", err)
+	}
+
+	resolved, err := store.ResolveAttachment("food/pizza/truffle.jpg")
+	Nil(t, err, "not expecting error resolving attachment")
+	True(t, filepath.HasPrefix(resolved, filepath.Join(storePath, "entries", ".git", "lfs", "objects")), "contents should be in the LFS object directory")
+
+	attributes, err := ioutil.ReadFile(filepath.Join(storePath, "entries", ".gitattributes"))
+	Nil(t, err, "not expecting error reading .gitattributes")
+	Contains(t, string(attributes), "food/pizza/truffle.jpg filter=lfs", "attachment should be tracked by LFS")
+
+	dirty, err := store.Dirty()
+	Nil(t, err, "not expecting error getting uncommitted changes")
+	NotContains(t, dirty, ".gitattributes", ".gitattributes should be committed with the attachment")
+}
+
+func TestReadPointerInvalidOID(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+	store := loadWithConfig(t, storePath, "attachments:\n  large-storage: external\n")
+
+	oids := []string{
+		strings.Repeat("../", 21) + "a",
+		"../../" + strings.Repeat("a", 58),
+		strings.Repeat("A", 64),
+		strings.Repeat("g", 64),
+		strings.Repeat("a", 63),
+	}
+
+	for _, oid := range oids {
+		path := filepath.Join(storePath, "entries", "food", "pizza", "evil.bin")
+
+		err := ioutil.WriteFile(path, []byte(lfsPointerVersion+"\noid sha256:"+oid+"\nsize 10\n"), 0644)
+		if err != nil {
+			t.Fatalf("not expecting error writing pointer file: %s", err)
+		}
+
+		_, _, ok, err := readPointer(path)
+		Nil(t, err)
+		False(t, ok, "expecting %q not to be accepted as an oid", oid)
+
+		resolved, err := store.ResolveAttachment("food/pizza/evil.bin")
+		Nil(t, err)
+		Equal(t, path, resolved, "expecting a pointer with an invalid oid to resolve to itself")
+	}
+
+	_, err := store.objectPath("../../etc/passwd")
+	NotNil(t, err, "expecting objectPath to reject oids which aren't hashes")
+
+	valid := strings.Repeat("0123456789abcdef", 4)
+	path := filepath.Join(storePath, "entries", "food", "pizza", "pointer.bin")
+
+	err = ioutil.WriteFile(path, []byte(lfsPointerVersion+"\noid sha256:"+valid+"\nsize 10\n"), 0644)
+	if err != nil {
+		t.Fatalf("not expecting error writing pointer file: %s", err)
+	}
+
+	oid, size, ok, err := readPointer(path)
+	Nil(t, err)
+	True(t, ok)
+	Equal(t, valid, oid)
+	Equal(t, int64(10), size)
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// The checks which can give a LintIssue.
+const (
+	// LintUnreadable is for entries, or the entries.FolderMetaFile of folders, which couldn't be read.
+	LintUnreadable = "unreadable"
+
+	// LintParseFailed is for entries which couldn't be parsed, such as because of invalid front matter, and for folders
+	// whose entries.FolderMetaFile couldn't be parsed.
+	LintParseFailed = "parse-failed"
+
+	// LintTooLarge is for entries which were skipped because they're larger than "entries.max-size".
+	LintTooLarge = "too-large"
+
+	// LintBinary is for entries which were skipped because they look like binary files.
+	LintBinary = "binary"
+
+	// LintTruncated is for entries whose contents were truncated because they're longer than "entries.max-contents".
+	LintTruncated = "truncated"
+
+	// LintMissingMetadata is for entries without keys which "default-metadata" gives entries at their path.
+	LintMissingMetadata = "missing-metadata"
+
+	// LintSecret is for entries which contain something that looks like a credential, such as an AWS key, see FindSecrets.
+	LintSecret = "secret"
+
+	// LintInvalidReminder is for entries whose "remind" or "expires" dates can't be parsed, see EntryReminders.
+	LintInvalidReminder = "invalid-reminder"
+)
+
+// LintIssue is a problem with an entry found by Lint.
+type LintIssue struct {
+	// Path is the path to the entry, such as "food/pizza".
+	Path string `json:"path"`
+
+	// Check is the check which found the problem, such as LintTooLarge.
+	Check string `json:"check"`
+
+	// Message describes the problem.
+	Message string `json:"message"`
+}
+
+// Lint checks the entries in the store for problems, such as entries which couldn't be loaded or were truncated. Issues
+// are sorted by path. If the store is encrypted, it returns ErrStoreEncrypted.
+func (s *Store) Lint() ([]LintIssue, error) {
+	collection, err := s.Collection()
+	if err != nil {
+		return nil, err
+	}
+
+	issues := []LintIssue{}
+
+	s.collMu.RLock()
+	entryErrs := s.entryErrs
+	s.collMu.RUnlock()
+
+	for _, entryErr := range entryErrs {
+		issues = append(issues, s.lintEntryErr(entryErr))
+	}
+
+	err = collection.ForEach(nil, func(entry *entries.Entry) error {
+		if entry.Truncated {
+			issues = append(issues, LintIssue{
+				Path:    entry.Path,
+				Check:   LintTruncated,
+				Message: "entry is longer than entries.max-contents, so its contents have been truncated",
+			})
+		}
+
+		_, err := EntryReminders(entry)
+		if err != nil {
+			issues = append(issues, LintIssue{
+				Path:    entry.Path,
+				Check:   LintInvalidReminder,
+				Message: err.Error(),
+			})
+		}
+
+		for _, secret := range FindSecrets(entry.OriginalContents) {
+			issues = append(issues, LintIssue{
+				Path:    entry.Path,
+				Check:   LintSecret,
+				Message: secret.String(),
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metadataIssues, err := s.lintDefaultMetadata(collection.List())
+	if err != nil {
+		return nil, err
+	}
+
+	issues = append(issues, metadataIssues...)
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+
+	return issues, nil
+}
+
+// lintEntryErr returns the issue for an error which happened while loading an entry.
+func (s *Store) lintEntryErr(entryErr error) LintIssue {
+	var (
+		tooLarge    entries.ErrEntryTooLarge
+		binary      entries.ErrEntryBinary
+		parseFailed entries.ErrEntryParseFailed
+		readFailed  entries.ErrEntryReadFailed
+
+		folderParseFailed entries.ErrFolderParseFailed
+		folderReadFailed  entries.ErrFolderReadFailed
+	)
+
+	issue := LintIssue{Check: LintUnreadable, Message: entryErr.Error()}
+
+	switch {
+	case errors.As(entryErr, &tooLarge):
+		issue.Path, issue.Check = tooLarge.Path, LintTooLarge
+	case errors.As(entryErr, &binary):
+		issue.Path, issue.Check = binary.Path, LintBinary
+	case errors.As(entryErr, &parseFailed):
+		issue.Path, issue.Check = parseFailed.Path, LintParseFailed
+	case errors.As(entryErr, &readFailed):
+		issue.Path = readFailed.Path
+	case errors.As(entryErr, &folderParseFailed):
+		issue.Path, issue.Check = folderParseFailed.Path, LintParseFailed
+	case errors.As(entryErr, &folderReadFailed):
+		issue.Path = folderReadFailed.Path
+	}
+
+	if rel, err := filepath.Rel(s.entriesPath, issue.Path); err == nil && filepath.IsAbs(issue.Path) {
+		issue.Path = filepath.ToSlash(rel)
+	}
+
+	return issue
+}
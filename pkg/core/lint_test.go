@@ -0,0 +1,73 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreLint(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	issues, err := store.Lint()
+	Nil(t, err, "not expecting error when linting store")
+	Len(t, issues, 0, "test store shouldn't have any issues")
+
+	store.config.Set("entries.max-size", "2KB")
+	store.config.Set("entries.max-contents", "1KB")
+
+	write := func(path, content string) {
+		err := os.MkdirAll(filepath.Join(store.entriesPath, path), 0755)
+		if err != nil {
+			t.Fatalf("not expecting error creating entry directory: %s", err)
+		}
+
+		err = ioutil.WriteFile(filepath.Join(store.entriesPath, path, "entry.md"), []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("not expecting error writing entry: %s", err)
+		}
+	}
+
+	write("stray/binary", "\x00\x01\x02\x03")
+	write("stray/huge", strings.Repeat("a", 4096))
+	write("stray/long", "---\ntitle: Long\n---\n\n"+strings.Repeat("word ", 300))
+
+	err = store.reload()
+	if err != nil {
+		t.Fatalf("not expecting error reloading store: %s", err)
+	}
+
+	issues, err = store.Lint()
+	Nil(t, err, "not expecting error when linting store")
+
+	Equal(t, []string{"stray/binary", "stray/huge", "stray/long"}, lintPaths(issues))
+	Equal(t, []string{LintBinary, LintTooLarge, LintTruncated}, lintChecks(issues))
+}
+
+func lintPaths(issues []LintIssue) []string {
+	paths := []string{}
+	for _, issue := range issues {
+		paths = append(paths, issue.Path)
+	}
+
+	return paths
+}
+
+func lintChecks(issues []LintIssue) []string {
+	checks := []string{}
+	for _, issue := range issues {
+		checks = append(checks, issue.Check)
+	}
+
+	return checks
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// commitMessage returns the message for a commit recording a change to the entry at path. If "git.detailed-messages" is
+// set in the config, the message also describes the change, see describeChange.
+func (s *Store) commitMessage(path, message string) string {
+	message = "(go-albatross) " + message
+
+	if !s.config.GetBool("git.detailed-messages") {
+		return message
+	}
+
+	summary, body := s.describeChange(path)
+	if summary != "" {
+		message += " " + summary
+	}
+
+	if body != "" {
+		message += "\n\n" + body
+	}
+
+	return message
+}
+
+// describeChange compares the entry at path with how it was in the last commit. The summary gives the entry's title and
+// how many words were added and removed, like `"Pizza" (+120/-3 words)`, and the body lists the metadata keys which
+// changed. Both are blank if the entry can't be read, since the commit should still be made.
+func (s *Store) describeChange(entryPath string) (summary, body string) {
+	oldEntry := s.committedEntry(entryPath)
+
+	var newEntry *entries.Entry
+	contents, err := ioutil.ReadFile(filepath.Join(s.entriesPath, filepath.FromSlash(entryPath), "entry.md"))
+	if err == nil {
+		newEntry, _ = entries.NewEntryFromContents(entryPath, contents, time.Now(), s.limits())
+	}
+
+	if oldEntry == nil && newEntry == nil {
+		return "", ""
+	}
+
+	var title, oldContents, newContents string
+	var oldMetadata, newMetadata map[string]interface{}
+
+	if oldEntry != nil {
+		title, oldContents, oldMetadata = oldEntry.Title, oldEntry.Contents, oldEntry.Metadata
+	}
+
+	if newEntry != nil {
+		title, newContents, newMetadata = newEntry.Title, newEntry.Contents, newEntry.Metadata
+	}
+
+	added, removed := entries.WordDiffStat(oldContents, newContents)
+	summary = fmt.Sprintf("%q (+%d/-%d words)", title, added, removed)
+
+	if changed := entries.ChangedMetadata(oldMetadata, newMetadata); len(changed) != 0 {
+		body = "Metadata changed: " + strings.Join(changed, ", ")
+	}
+
+	return summary, body
+}
+
+// committedEntry returns the entry at path as it was in the last commit, or nil if it wasn't in it.
+func (s *Store) committedEntry(entryPath string) *entries.Entry {
+	head, err := s.repo.Head()
+	if err != nil {
+		return nil // There are no commits yet.
+	}
+
+	commit, err := s.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil
+	}
+
+	file, err := commit.File(path.Join(entryPath, "entry.md"))
+	if err != nil {
+		return nil
+	}
+
+	contents, err := file.Contents()
+	if err != nil {
+		return nil
+	}
+
+	entry, err := entries.NewEntryFromContents(entryPath, []byte(contents), commit.Committer.When, s.limits())
+	if err != nil {
+		return nil
+	}
+
+	return entry
+}
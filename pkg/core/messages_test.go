@@ -0,0 +1,62 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreDetailedCommitMessages(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+
+	_, err := git.PlainInit(filepath.Join(storePath, "entries"), false)
+	if err != nil {
+		t.Fatalf("not expecting error when initialising git repository: %s", err)
+	}
+
+	store, err := Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	_, err = store.CommitChanges("Initial commit")
+	Nil(t, err)
+
+	lastMessage := func() string {
+		head, err := store.repo.Head()
+		if err != nil {
+			t.Fatalf("not expecting error getting HEAD: %s", err)
+		}
+
+		commit, err := store.repo.CommitObject(head.Hash())
+		if err != nil {
+			t.Fatalf("not expecting error getting commit: %s", err)
+		}
+
+		return commit.Message
+	}
+
+	err = store.Update("food/pizza", "---\ntitle: \"Pizza!\"\n---\n\nPizza is great.\n")
+	Nil(t, err)
+	Equal(t, "(go-albatross) Update food/pizza", lastMessage(), "expecting plain messages by default")
+
+	store.config.Set("git.detailed-messages", true)
+
+	err = store.Update("food/pizza", "---\ntitle: \"Pizza!\"\ntags: [food]\n---\n\nPizza is really great.\n")
+	Nil(t, err)
+	Equal(t, "(go-albatross) Update food/pizza \"Pizza!\" (+1/-0 words)\n\nMetadata changed: tags", lastMessage())
+
+	err = store.Create("food/pasta", "---\ntitle: \"Pasta\"\n---\n\nPasta is fine too.\n")
+	Nil(t, err)
+	Equal(t, "(go-albatross) Add food/pasta \"Pasta\" (+4/-0 words)\n\nMetadata changed: title", lastMessage())
+
+	err = store.Delete("food/pasta")
+	Nil(t, err)
+	Equal(t, "(go-albatross) Delete food/pasta \"Pasta\" (+0/-4 words)\n\nMetadata changed: title", lastMessage())
+}
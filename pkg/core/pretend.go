@@ -0,0 +1,108 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// PretendEntry parses content as if it were the entry.md file of an entry at the path given, without it being added to
+// the store. Pretend entries can be given to a query using QueryOptions.Pretend, such as to see how a draft would be
+// matched or linked before creating it.
+func (s *Store) PretendEntry(path, content string) (*entries.Entry, error) {
+	return entries.NewEntryFromContents(strings.Trim(path, "/"), []byte(content), time.Now(), s.limits())
+}
+
+// PretendEntriesFromFile parses a file as a pretend entry at the path given, like PretendEntry. If the file is a folder,
+// every Markdown file in it is parsed as an entry under the path: "entry.md" files give entries at the path of the folder
+// they're in, and other files give entries named after the file without its ".md" extension. So a folder containing
+// "idea.md" and "plans/entry.md" parsed at "drafts" gives the entries "drafts/idea" and "drafts/plans".
+func (s *Store) PretendEntriesFromFile(file, path string) ([]*entries.Entry, error) {
+	path = strings.Trim(path, "/")
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		entry, err := s.pretendEntryFromFile(file, path)
+		if err != nil {
+			return nil, err
+		}
+
+		return []*entries.Entry{entry}, nil
+	}
+
+	pretend := []*entries.Entry{}
+
+	err = filepath.Walk(file, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(info.Name(), ".") && name != file {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if info.IsDir() || filepath.Ext(name) != ".md" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(file, name)
+		if err != nil {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+		if info.Name() == "entry.md" {
+			rel = filepath.ToSlash(filepath.Dir(rel))
+		} else {
+			rel = strings.TrimSuffix(rel, ".md")
+		}
+
+		entryPath := path
+		if rel != "." {
+			entryPath = strings.Trim(path+"/"+rel, "/")
+		}
+
+		entry, err := s.pretendEntryFromFile(name, entryPath)
+		if err != nil {
+			return err
+		}
+
+		pretend = append(pretend, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(pretend, func(i, j int) bool { return pretend[i].Path < pretend[j].Path })
+
+	return pretend, nil
+}
+
+// pretendEntryFromFile parses a single file as a pretend entry.
+func (s *Store) pretendEntryFromFile(file, path string) (*entries.Entry, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries.NewEntryFromContents(path, contents, info.ModTime(), s.limits())
+}
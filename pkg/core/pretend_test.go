@@ -0,0 +1,91 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/albatross-org/go-albatross/entries"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStorePretendEntries(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	drafts := filepath.Join(dir, "drafts")
+
+	files := map[string]string{
+		"idea.md":             "---\ntitle: Idea\n---\n\nAn idea about {{food/pizza}}.",
+		"plans/entry.md":      "Plans for [[Idea]].",
+		"plans/notes.txt":     "Not an entry.",
+		".hidden/entry.md":    "Hidden.",
+		"plans/later/soon.md": "Soon.",
+	}
+
+	for name, content := range files {
+		err = os.MkdirAll(filepath.Dir(filepath.Join(drafts, name)), 0755)
+		if err != nil {
+			t.Fatalf("not expecting error creating folder: %s", err)
+		}
+
+		err = ioutil.WriteFile(filepath.Join(drafts, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("not expecting error writing draft: %s", err)
+		}
+	}
+
+	pretend, err := store.PretendEntriesFromFile(drafts, "ideas/")
+	if err != nil {
+		t.Fatalf("not expecting error parsing drafts: %s", err)
+	}
+
+	paths := []string{}
+	for _, entry := range pretend {
+		paths = append(paths, entry.Path)
+	}
+
+	Equal(t, []string{"ideas/idea", "ideas/plans", "ideas/plans/later/soon"}, paths)
+
+	single, err := store.PretendEntriesFromFile(filepath.Join(drafts, "idea.md"), "ideas/single")
+	if err != nil {
+		t.Fatalf("not expecting error parsing draft: %s", err)
+	}
+
+	Len(t, single, 1)
+	Equal(t, "Idea", single[0].Title)
+
+	entry, err := store.PretendEntry("food/pizza", "---\ntitle: Better Pizza\n---\n\nPizza, but better.")
+	if err != nil {
+		t.Fatalf("not expecting error parsing pretend entry: %s", err)
+	}
+
+	result, err := NewQueryRunner(store).Run(QueryOptions{
+		Query:   entries.Query{PathsMatch: [][]string{{"food/pizza", "ideas"}}},
+		Pretend: append(pretend, entry),
+	})
+	if err != nil {
+		t.Fatalf("not expecting error running query: %s", err)
+	}
+
+	titles := []string{}
+	for _, entry := range result.List.Slice() {
+		titles = append(titles, entry.Title)
+	}
+
+	ElementsMatch(t, []string{"Better Pizza", "Idea", "Plans for [[Idea]]", "Soon"}, titles, "expecting pretend entries to be matched and replace the entry with the same path")
+
+	collection, err := store.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error getting collection: %s", err)
+	}
+
+	Nil(t, collection.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: "ideas/idea"}), "pretend entries shouldn't be added to the store")
+	Equal(t, "Pizza!", collection.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: "food/pizza"}).Title)
+}
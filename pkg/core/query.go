@@ -0,0 +1,235 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// QueryOptions are the options for running a query using a QueryRunner. They correspond to the flags of the 'get'
+// command.
+type QueryOptions struct {
+	// Query is the query used to filter the entries.
+	Query entries.Query
+
+	// Unread only allows entries which haven't been read, or have changed since they were read.
+	Unread bool
+
+	// Recent only allows the Recent most recently viewed entries, see Store.RecentViews. Zero doesn't filter the entries.
+	Recent int
+
+	// Sort is how the entries are sorted, in the format taken by entries.List.SortBy, such as "alpha" or "weight,date".
+	// If it's blank, the entries are in a random order.
+	Sort string
+
+	// Rev reverses the order of the entries.
+	Rev bool
+
+	// Number is the most entries returned. If it's zero or less, all the entries which match are returned.
+	Number int
+
+	// Offset is the number of entries skipped after sorting, before Number is applied, for paging through the entries
+	// which match. Sort should then give the same order every time, see entries.DeterministicSort.
+	Offset int
+
+	// Explain records what each filter did in QueryResult.Steps. It's slower, since the filters are applied one at a time.
+	Explain bool
+
+	// Password is used to decrypt the store into memory if it's encrypted. The store itself is left encrypted. If it's
+	// nil, running a query against an encrypted store returns ErrStoreEncrypted.
+	Password func() (string, error)
+
+	// Selective only decrypts the entries given by Query.PathsExact if the store is encrypted, rather than all of them.
+	Selective bool
+
+	// Pretend are entries which aren't in the store but are added to the collection before the query is run, such as
+	// ones from PretendEntry. They replace any entries in the store with the same path.
+	Pretend []*entries.Entry
+}
+
+// QueryResult is the result of running a query.
+type QueryResult struct {
+	// Collection is every entry the query was run against.
+	Collection *entries.Collection
+
+	// Filtered are the entries which matched the query.
+	Filtered *entries.Collection
+
+	// List are the entries which matched the query, sorted and limited by QueryOptions.Offset and QueryOptions.Number.
+	List entries.List
+
+	// Filter is the filter the query was turned into, so that a collection can be filtered again later, such as after the
+	// store changes.
+	Filter entries.Filter
+
+	// Steps are what each filter did. It's only set if QueryOptions.Explain is true.
+	Steps []entries.FilterStep
+
+	// LoadTime, FilterTime and SortTime are how long each part of running the query took.
+	LoadTime, FilterTime, SortTime time.Duration
+}
+
+// QueryRunner runs queries against a store the same way the 'get' command does, so that other programs can find entries
+// exactly like the command line does.
+type QueryRunner struct {
+	store  *Store
+	source func() (*entries.Collection, error)
+}
+
+// NewQueryRunner returns a new QueryRunner for the store.
+func NewQueryRunner(store *Store) *QueryRunner {
+	return &QueryRunner{store: store}
+}
+
+// SetSource changes where the collection queries are run against comes from, such as a daemon which already has the store
+// loaded. When a source is set, QueryOptions.Password and QueryOptions.Selective are ignored.
+func (r *QueryRunner) SetSource(source func() (*entries.Collection, error)) {
+	r.source = source
+}
+
+// Run runs a query. If the query isn't valid, such as because it contains an invalid regular expression, the error from
+// entries.Query.Validate is returned.
+func (r *QueryRunner) Run(opts QueryOptions) (*QueryResult, error) {
+	err := opts.Query.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &QueryResult{}
+
+	loadStart := time.Now()
+
+	result.Collection, err = r.collection(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opts.Pretend) != 0 {
+		// The collection is copied so that the store's own collection isn't changed.
+		result.Collection = result.Collection.Copy()
+
+		for _, entry := range opts.Pretend {
+			err = result.Collection.Update(entry)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result.LoadTime = time.Since(loadStart)
+
+	filters := opts.Query.Filters()
+
+	if opts.Unread {
+		unreadFilter, err := r.store.UnreadFilter()
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, entries.NamedFilter{Name: "unread", Filter: unreadFilter})
+	}
+
+	if opts.Recent > 0 {
+		recentFilter, err := r.store.RecentFilter(opts.Recent)
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, entries.NamedFilter{Name: fmt.Sprintf("%d most recently viewed", opts.Recent), Filter: recentFilter})
+	}
+
+	plain := make([]entries.Filter, len(filters))
+	for i, filter := range filters {
+		plain[i] = filter.Filter
+	}
+
+	result.Filter = entries.FilterAnd(plain...)
+
+	filterStart := time.Now()
+
+	if opts.Explain {
+		result.Filtered, result.Steps, err = result.Collection.Explain(filters...)
+	} else {
+		result.Filtered, err = result.Collection.Filter(result.Filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.FilterTime = time.Since(filterStart)
+
+	sortStart := time.Now()
+
+	result.List, err = result.Filtered.List().SortBy(opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Rev {
+		result.List = result.List.Reverse()
+	}
+
+	if opts.Offset > 0 {
+		result.List = result.List.Page(opts.Offset, opts.Number)
+	} else if opts.Number > 0 {
+		result.List = result.List.First(opts.Number)
+	}
+
+	result.SortTime = time.Since(sortStart)
+
+	return result, nil
+}
+
+// collection returns the collection a query is run against, decrypting it into memory if the store is encrypted.
+func (r *QueryRunner) collection(opts QueryOptions) (*entries.Collection, error) {
+	if r.source != nil {
+		return r.source()
+	}
+
+	encrypted, err := r.store.Encrypted()
+	if err != nil {
+		return nil, err
+	} else if !encrypted {
+		return r.store.Collection()
+	}
+
+	if opts.Password == nil {
+		return nil, ErrStoreEncrypted{Path: r.store.Path}
+	}
+
+	if !opts.Selective {
+		return r.store.DecryptCollection(opts.Password)
+	}
+
+	paths := []string{}
+	for _, group := range opts.Query.PathsExact {
+		paths = append(paths, group...)
+	}
+
+	if len(paths) == 0 {
+		return nil, errors.New("only decrypting some entries needs the paths of the entries to decrypt, given by Query.PathsExact")
+	}
+
+	decrypted, err := r.store.DecryptEntries(paths, opts.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := entries.NewCollection()
+
+	for _, entry := range decrypted {
+		// The same path could be given more than once.
+		if collection.In(entry.Entry) {
+			continue
+		}
+
+		err = collection.Add(entry.Entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return collection, nil
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/albatross-org/go-albatross/entries"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestQueryRunner(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	runner := NewQueryRunner(store)
+
+	result, err := runner.Run(QueryOptions{
+		Query: entries.Query{PathsMatch: [][]string{{"food"}}},
+		Sort:  "path",
+	})
+	if err != nil {
+		t.Fatalf("not expecting error running query: %s", err)
+	}
+
+	paths := []string{}
+	for _, entry := range result.List.Slice() {
+		paths = append(paths, entry.Path)
+	}
+
+	True(t, len(paths) > 1, "expecting more than one food entry")
+	True(t, sort.StringsAreSorted(paths), "expecting entries to be sorted by path")
+	Equal(t, result.Filtered.Len(), len(paths), "expecting every matching entry without a limit")
+	Nil(t, result.Steps, "steps should only be recorded when explaining")
+
+	for _, path := range paths {
+		Regexp(t, regexp.MustCompile("^food"), path)
+	}
+
+	limited, err := runner.Run(QueryOptions{
+		Query:   entries.Query{PathsMatch: [][]string{{"food"}}},
+		Sort:    "path",
+		Rev:     true,
+		Number:  1,
+		Explain: true,
+	})
+	if err != nil {
+		t.Fatalf("not expecting error running query: %s", err)
+	}
+
+	Len(t, limited.List.Slice(), 1, "expecting list to be limited")
+	Equal(t, paths[len(paths)-1], limited.List.Slice()[0].Path, "expecting list to be reversed")
+	Len(t, limited.Steps, 1, "expecting one step for the one filter")
+
+	paged, err := runner.Run(QueryOptions{
+		Query:  entries.Query{PathsMatch: [][]string{{"food"}}},
+		Sort:   "path",
+		Offset: 1,
+		Number: 1,
+	})
+	if err != nil {
+		t.Fatalf("not expecting error running query: %s", err)
+	}
+
+	Len(t, paged.List.Slice(), 1, "expecting page to be limited")
+	Equal(t, paths[1], paged.List.Slice()[0].Path, "expecting page to start after the offset")
+
+	_, err = runner.Run(QueryOptions{Query: entries.Query{TitlesRegex: [][]string{{"("}}}})
+	NotNil(t, err, "expecting error for invalid regular expression")
+
+	runner.SetSource(func() (*entries.Collection, error) {
+		collection := entries.NewCollection()
+		err := collection.Add(&entries.Entry{Path: "food/other", Title: "Other"})
+		return collection, err
+	})
+
+	sourced, err := runner.Run(QueryOptions{Query: entries.Query{PathsMatch: [][]string{{"food"}}}})
+	if err != nil {
+		t.Fatalf("not expecting error running query: %s", err)
+	}
+
+	Equal(t, 1, sourced.Collection.Len(), "expecting collection to come from the source")
+}
+
+func TestQueryRunnerEncrypted(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	store.config.Set("encryption.private-key", filepath.Join(dir, "testdata", "keys", "private.key"))
+	store.config.Set("encryption.public-key", filepath.Join(dir, "testdata", "keys", "public.key"))
+
+	err = store.Encrypt()
+	if err != nil {
+		t.Fatalf("not expecting error when encrypting store: %s", err)
+	}
+
+	runner := NewQueryRunner(store)
+	query := entries.Query{PathsExact: [][]string{{"food/pizza"}}}
+
+	_, err = runner.Run(QueryOptions{Query: query})
+	IsType(t, ErrStoreEncrypted{}, err, "expecting ErrStoreEncrypted without a password")
+
+	result, err := runner.Run(QueryOptions{Query: query, Password: staticPassword("pa$$word"), Selective: true})
+	if err != nil {
+		t.Fatalf("not expecting error running query: %s", err)
+	}
+
+	Equal(t, 1, result.Collection.Len(), "expecting only the entry asked for to be decrypted")
+	Len(t, result.List.Slice(), 1)
+
+	encrypted, err := store.Encrypted()
+	Nil(t, err)
+	True(t, encrypted, "store should still be encrypted")
+}
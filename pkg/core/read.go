@@ -0,0 +1,108 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// readStatePath returns the path to the file recording which entries have been read. It's kept outside the entries
+// folder so that reading entries doesn't create changes to commit.
+func (s *Store) readStatePath() string {
+	return filepath.Join(s.Path, ".albatross", "read.json")
+}
+
+// ReadTimes returns when each entry was last read, by path. Entries which have never been read, or were marked as
+// unread, aren't included. If nothing has been read yet, it returns an empty map.
+func (s *Store) ReadTimes() (map[string]time.Time, error) {
+	times := map[string]time.Time{}
+
+	data, err := ioutil.ReadFile(s.readStatePath())
+	if os.IsNotExist(err) {
+		return times, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't read read state: %w", err)
+	}
+
+	err = json.Unmarshal(data, &times)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse read state %s: %w", s.readStatePath(), err)
+	}
+
+	return times, nil
+}
+
+// MarkRead records the entries at the paths given as having been read now.
+func (s *Store) MarkRead(paths ...string) error {
+	now := time.Now()
+
+	return s.updateReadTimes(func(times map[string]time.Time) {
+		for _, path := range paths {
+			times[path] = now
+		}
+	})
+}
+
+// MarkUnread forgets that the entries at the paths given have been read.
+func (s *Store) MarkUnread(paths ...string) error {
+	return s.updateReadTimes(func(times map[string]time.Time) {
+		for _, path := range paths {
+			delete(times, path)
+		}
+	})
+}
+
+// MarkReadAutomatically returns true if commands which show entries, like 'albatross get contents', should mark them
+// as read. This is set by "read-tracking.automatic" in the config, and is true by default.
+func (s *Store) MarkReadAutomatically() bool {
+	return s.config.GetBool("read-tracking.automatic")
+}
+
+// UnreadFilter returns a filter which only allows entries that haven't been read, or that have been modified since they
+// were last read.
+func (s *Store) UnreadFilter() (entries.Filter, error) {
+	times, err := s.ReadTimes()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(entry *entries.Entry) bool {
+		read, ok := times[entry.Path]
+		return !ok || entry.ModTime.After(read)
+	}, nil
+}
+
+// updateReadTimes reads the read state, changes it using update and then writes it back. The new state is written to a
+// temporary file first so that it isn't lost if writing is interrupted.
+func (s *Store) updateReadTimes(update func(times map[string]time.Time)) error {
+	times, err := s.ReadTimes()
+	if err != nil {
+		return err
+	}
+
+	update(times)
+
+	data, err := json.Marshal(times)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.readStatePath()), 0755)
+	if err != nil {
+		return fmt.Errorf("couldn't create directory for read state: %w", err)
+	}
+
+	tmpPath := s.readStatePath() + ".tmp"
+
+	err = ioutil.WriteFile(tmpPath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("couldn't write read state: %w", err)
+	}
+
+	return os.Rename(tmpPath, s.readStatePath())
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreReadTracking(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	True(t, store.MarkReadAutomatically(), "expecting entries to be marked as read automatically by default")
+
+	times, err := store.ReadTimes()
+	Nil(t, err, "not expecting error reading non-existent read state")
+	Equal(t, 0, len(times), "expecting nothing to be read at first")
+
+	collection, err := store.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error getting collection: %s", err)
+	}
+
+	unread := func() *entries.Collection {
+		t.Helper()
+
+		filter, err := store.UnreadFilter()
+		if err != nil {
+			t.Fatalf("not expecting error getting unread filter: %s", err)
+		}
+
+		filtered, err := collection.Filter(filter)
+		if err != nil {
+			t.Fatalf("not expecting error filtering: %s", err)
+		}
+
+		return filtered
+	}
+
+	Equal(t, collection.Len(), unread().Len(), "expecting every entry to be unread at first")
+
+	err = store.MarkRead("food/pizza", "food/ice-cream")
+	Nil(t, err, "not expecting error marking entries as read")
+
+	times, err = store.ReadTimes()
+	Nil(t, err, "not expecting error reading read state")
+	Contains(t, times, "food/pizza")
+	Contains(t, times, "food/ice-cream")
+	Equal(t, collection.Len()-2, unread().Len(), "expecting read entries to be filtered out")
+
+	err = store.MarkUnread("food/pizza")
+	Nil(t, err, "not expecting error marking entry as unread")
+	Equal(t, collection.Len()-1, unread().Len(), "expecting entry marked as unread to be allowed again")
+
+	// An entry modified after it was read should count as unread again.
+	err = store.MarkRead("food/pizza")
+	Nil(t, err)
+
+	pizza := collection.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: "food/pizza"})
+	if pizza == nil {
+		t.Fatalf("expecting food/pizza to be in the collection")
+	}
+	pizza.ModTime = time.Now().Add(time.Hour)
+
+	Equal(t, collection.Len()-1, unread().Len(), "expecting entry modified since it was read to be unread")
+}
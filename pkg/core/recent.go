@@ -0,0 +1,163 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// View records when an entry was last viewed, such as by printing it using 'albatross get contents', opening it using
+// 'albatross get update' or fetching it from the server.
+type View struct {
+	// Path is the path to the entry.
+	Path string `json:"path"`
+
+	// Time is when the entry was last viewed.
+	Time time.Time `json:"time"`
+
+	// Count is how many times the entry has been viewed since it was first recorded.
+	Count int `json:"count"`
+}
+
+// recentStatePath returns the path to the file recording which entries have been viewed recently. Like the read state,
+// it's kept outside the entries folder so that viewing entries doesn't create changes to commit.
+func (s *Store) recentStatePath() string {
+	return filepath.Join(s.Path, ".albatross", "recent.json")
+}
+
+// RecentViews returns the entries which have been viewed recently, most recent first. There is at most one view for each
+// path, and at most "recent.limit" views are kept. If nothing has been viewed yet, it returns an empty slice.
+func (s *Store) RecentViews() ([]View, error) {
+	views := []View{}
+
+	data, err := ioutil.ReadFile(s.recentStatePath())
+	if os.IsNotExist(err) {
+		return views, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't read recently viewed entries: %w", err)
+	}
+
+	err = json.Unmarshal(data, &views)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse recently viewed entries %s: %w", s.recentStatePath(), err)
+	}
+
+	return views, nil
+}
+
+// RecordView records the entries at the paths given as having been viewed now. If more than one path is given, the
+// first is treated as the most recent. It does nothing if "recent.track" is false in the config.
+func (s *Store) RecordView(paths ...string) error {
+	if !s.config.GetBool("recent.track") {
+		return nil
+	}
+
+	now := time.Now()
+
+	return s.updateRecentViews(func(views []View) []View {
+		for i := len(paths) - 1; i >= 0; i-- {
+			view := View{Path: paths[i], Time: now, Count: 1}
+
+			for j, old := range views {
+				if old.Path == view.Path {
+					view.Count += old.Count
+					views = append(views[:j], views[j+1:]...)
+					break
+				}
+			}
+
+			views = append([]View{view}, views...)
+		}
+
+		return views
+	})
+}
+
+// ClearRecentViews forgets every entry which has been viewed.
+func (s *Store) ClearRecentViews() error {
+	return s.updateRecentViews(func(views []View) []View {
+		return []View{}
+	})
+}
+
+// RecentFilter returns a filter which only allows the n most recently viewed entries. If n is zero or less, it allows
+// every entry which has been viewed.
+func (s *Store) RecentFilter(n int) (entries.Filter, error) {
+	views, err := s.RecentViews()
+	if err != nil {
+		return nil, err
+	}
+
+	if n > 0 && n < len(views) {
+		views = views[:n]
+	}
+
+	recent := map[string]bool{}
+	for _, view := range views {
+		recent[view.Path] = true
+	}
+
+	return func(entry *entries.Entry) bool {
+		return recent[entry.Path]
+	}, nil
+}
+
+// updateRecentViews reads the recently viewed entries, changes them using update and then writes them back, keeping
+// only the most recent "recent.limit" views. Updates are made one at a time, and the new state is written to a
+// temporary file first so that it isn't lost if writing is interrupted.
+func (s *Store) updateRecentViews(update func(views []View) []View) error {
+	s.recentMu.Lock()
+	defer s.recentMu.Unlock()
+
+	views, err := s.RecentViews()
+	if err != nil {
+		return err
+	}
+
+	views = update(views)
+
+	limit := s.config.GetInt("recent.limit")
+	if limit > 0 && len(views) > limit {
+		views = views[:limit]
+	}
+
+	data, err := json.Marshal(views)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(s.recentStatePath())
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("couldn't create directory for recently viewed entries: %w", err)
+	}
+
+	// Each update uses its own temporary file, so that another process updating at the same time can't rename it
+	// half-written.
+	tmp, err := ioutil.TempFile(dir, "recent-*.json.tmp")
+	if err != nil {
+		return fmt.Errorf("couldn't write recently viewed entries: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("couldn't write recently viewed entries: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), s.recentStatePath())
+}
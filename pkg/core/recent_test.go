@@ -0,0 +1,128 @@
+package core
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreRecentViews(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	views, err := store.RecentViews()
+	Nil(t, err, "not expecting error reading non-existent recent views")
+	Equal(t, 0, len(views), "expecting nothing to be viewed at first")
+
+	err = store.RecordView("food/pizza")
+	Nil(t, err)
+
+	err = store.RecordView("food/ice-cream", "school/gcse/physics")
+	Nil(t, err)
+
+	err = store.RecordView("food/pizza")
+	Nil(t, err)
+
+	views, err = store.RecentViews()
+	Nil(t, err)
+
+	paths := []string{}
+	for _, view := range views {
+		paths = append(paths, view.Path)
+	}
+
+	Equal(t, []string{"food/pizza", "food/ice-cream", "school/gcse/physics"}, paths, "expecting most recent first, with the first path given treated as most recent")
+	Equal(t, 2, views[0].Count, "expecting views of the same entry to be counted")
+
+	collection, err := store.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error getting collection: %s", err)
+	}
+
+	filter, err := store.RecentFilter(2)
+	Nil(t, err)
+
+	filtered, err := collection.Filter(filter)
+	Nil(t, err)
+	Equal(t, 2, filtered.Len(), "expecting only the two most recently viewed entries")
+
+	result, err := NewQueryRunner(store).Run(QueryOptions{Recent: 1})
+	Nil(t, err)
+	if Len(t, result.List.Slice(), 1) {
+		Equal(t, "food/pizza", result.List.Slice()[0].Path)
+	}
+
+	store.config.Set("recent.limit", 2)
+
+	err = store.RecordView("food/ice-cream")
+	Nil(t, err)
+
+	views, err = store.RecentViews()
+	Nil(t, err)
+	Len(t, views, 2, "expecting only recent.limit views to be kept")
+
+	store.config.Set("recent.track", false)
+
+	err = store.RecordView("school/gcse/physics")
+	Nil(t, err)
+
+	views, err = store.RecentViews()
+	Nil(t, err)
+	Equal(t, "food/ice-cream", views[0].Path, "expecting views not to be recorded when recent.track is false")
+
+	err = store.ClearRecentViews()
+	Nil(t, err)
+
+	views, err = store.RecentViews()
+	Nil(t, err)
+	Empty(t, views)
+}
+
+func TestStoreRecordViewConcurrent(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 20)
+
+	for i := range errs {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = store.RecordView("food/pizza")
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		Nil(t, err, "not expecting error recording views at the same time")
+	}
+
+	views, err := store.RecentViews()
+	Nil(t, err)
+	if Len(t, views, 1) {
+		Equal(t, len(errs), views[0].Count, "expecting no views to be lost")
+	}
+
+	files, err := ioutil.ReadDir(filepath.Dir(store.recentStatePath()))
+	Nil(t, err)
+	for _, file := range files {
+		False(t, strings.HasSuffix(file.Name(), ".tmp"), "expecting temporary file %s to be removed", file.Name())
+	}
+}
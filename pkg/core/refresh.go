@@ -0,0 +1,212 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// refresh updates the collection after the store has changed the entry at path, parsing only that entry rather than
+// reloading the whole store. The cached history is discarded, since the change may have been committed.
+func (s *Store) refresh(path string) error {
+	return s.refreshPaths(map[string]bool{path: false})
+}
+
+// refreshPaths is like refresh but for changes to more than one entry, such as a rename. Paths which map to true are
+// trees, like in updateEntries.
+func (s *Store) refreshPaths(paths map[string]bool) error {
+	s.historyMu.Lock()
+	s.lastModified = nil
+	s.historyMu.Unlock()
+
+	_, err := s.updateEntries(paths)
+	return err
+}
+
+// updateEntries parses the entries at the paths given again and swaps them into the collection, rather than reloading the
+// whole store. Paths which map to true are trees, where every entry under the path is checked too. Entries which no longer
+// exist are removed. The descriptions of folders in their entries.FolderMetaFile are read again in the same way. It
+// returns the paths of the entries and folders which were updated, added or removed.
+func (s *Store) updateEntries(paths map[string]bool) ([]string, error) {
+	s.collMu.Lock()
+	defer s.collMu.Unlock()
+
+	if s.coll == nil {
+		return nil, nil // Nothing is loaded, so the changes will be picked up when it is.
+	}
+
+	// The collection is copied rather than changed in place, since it might be being read at the same time.
+	coll := s.coll.Copy()
+	affected := map[string]bool{}
+	limits := s.limits()
+
+	under := func(entryPath, path string, tree bool) bool {
+		return entryPath == path || (tree && (path == "." || strings.HasPrefix(entryPath, path+"/")))
+	}
+
+	entryErrs := []error{}
+	for _, entryErr := range s.entryErrs {
+		keep := true
+		for path, tree := range paths {
+			if under(s.lintEntryErr(entryErr).Path, path, tree) {
+				keep = false
+				break
+			}
+		}
+
+		if keep {
+			entryErrs = append(entryErrs, entryErr)
+		}
+	}
+
+	// parse parses the entry.md file given, recording the error if it can't be.
+	parse := func(file string) *entries.Entry {
+		entry, err := entries.NewEntryFromFileWithLimits(file, limits)
+		if err != nil {
+			entryErrs = append(entryErrs, err)
+			return nil
+		}
+
+		return entry
+	}
+
+	// parseFolder parses the entries.FolderMetaFile given for the folder at path and adds it to the collection,
+	// recording the error if it can't be.
+	parseFolder := func(file, path string) {
+		folder, err := entries.NewFolderFromFile(file, path)
+		if err != nil {
+			entryErrs = append(entryErrs, err)
+			return
+		}
+
+		coll.AddFolder(folder)
+		affected[path] = true
+	}
+
+	trees := map[string]bool{}
+
+	for path, tree := range paths {
+		if tree {
+			trees[path] = true
+			continue
+		}
+
+		// A single entry can be looked up by its path, so there's no need to look through the whole collection.
+		if old := coll.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: path}); old != nil {
+			err := coll.Delete(old)
+			if err != nil {
+				return nil, err
+			}
+
+			affected[path] = true
+		}
+
+		dir := filepath.Join(s.entriesPath, filepath.FromSlash(path))
+
+		// The folder's description is read again too, since changing it can change how the entries in it are shown.
+		if coll.Folder(path) != nil {
+			coll.DeleteFolder(path)
+			affected[path] = true
+		}
+
+		if metaFile := filepath.Join(dir, entries.FolderMetaFile); exists(metaFile) {
+			parseFolder(metaFile, path)
+		}
+
+		file := filepath.Join(dir, "entry.md")
+		if !exists(file) {
+			continue
+		}
+
+		if entry := parse(file); entry != nil {
+			err := coll.Add(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			affected[entry.Path] = true
+		}
+	}
+
+	if len(trees) != 0 {
+		for _, folder := range coll.Folders() {
+			for path := range trees {
+				if under(folder.Path, path, true) {
+					coll.DeleteFolder(folder.Path)
+					affected[folder.Path] = true
+					break
+				}
+			}
+		}
+
+		for _, entry := range coll.List().Slice() {
+			for path := range trees {
+				if under(entry.Path, path, true) {
+					err := coll.Delete(entry)
+					if err != nil {
+						return nil, err
+					}
+
+					affected[entry.Path] = true
+					break
+				}
+			}
+		}
+	}
+
+	for path := range trees {
+		dir := filepath.Join(s.entriesPath, filepath.FromSlash(path))
+
+		err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+			if os.IsNotExist(err) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			if info.IsDir() && strings.HasPrefix(info.Name(), ".") && file != dir {
+				return filepath.SkipDir
+			}
+
+			if !info.IsDir() && info.Name() == entries.FolderMetaFile {
+				rel, err := filepath.Rel(s.entriesPath, filepath.Dir(file))
+				if err != nil {
+					return err
+				}
+
+				parseFolder(file, filepath.ToSlash(rel))
+				return nil
+			}
+
+			if info.IsDir() || info.Name() != "entry.md" {
+				return nil
+			}
+
+			entry := parse(file)
+			if entry == nil {
+				return nil
+			}
+
+			affected[entry.Path] = true
+			return coll.Update(entry)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.coll = coll
+	s.entryErrs = entryErrs
+
+	updated := []string{}
+	for path := range affected {
+		updated = append(updated, path)
+	}
+
+	sort.Strings(updated)
+
+	return updated, nil
+}
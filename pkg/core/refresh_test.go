@@ -0,0 +1,76 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// benchmarkStoreSize is the number of entries in the store generated by benchmarkStore.
+const benchmarkStoreSize = 2000
+
+// benchmarkStore creates a store containing benchmarkStoreSize entries, which link to each other.
+func benchmarkStore(b *testing.B) (store *Store, cleanup func()) {
+	b.Helper()
+
+	dir, cleanup := tempTestDir(b)
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+
+	for i := 0; i < benchmarkStoreSize; i++ {
+		entryDir := filepath.Join(storePath, "entries", "bench", fmt.Sprintf("%d", i/100), fmt.Sprintf("entry-%d", i))
+
+		err := os.MkdirAll(entryDir, 0755)
+		if err != nil {
+			b.Fatalf("couldn't create entry folder: %s", err)
+		}
+
+		content := fmt.Sprintf("---\ntitle: Entry %d\ndate: 2020-10-01 10:00\n---\n\nThis links to [[Entry %d]] and {{food/pizza}}. @?bench\n", i, (i+1)%benchmarkStoreSize)
+
+		err = ioutil.WriteFile(filepath.Join(entryDir, "entry.md"), []byte(content), 0644)
+		if err != nil {
+			b.Fatalf("couldn't write entry: %s", err)
+		}
+	}
+
+	store, err := Load(storePath)
+	if err != nil {
+		b.Fatalf("couldn't load store: %s", err)
+	}
+
+	_, err = store.Collection()
+	if err != nil {
+		b.Fatalf("couldn't parse store: %s", err)
+	}
+
+	return store, cleanup
+}
+
+func BenchmarkStoreReload(b *testing.B) {
+	store, cleanup := benchmarkStore(b)
+	defer cleanup()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := store.reload()
+		if err != nil {
+			b.Fatalf("wasn't expecting error reloading store: %s", err)
+		}
+	}
+}
+
+func BenchmarkStoreRefresh(b *testing.B) {
+	store, cleanup := benchmarkStore(b)
+	defer cleanup()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := store.refresh("bench/0/entry-0")
+		if err != nil {
+			b.Fatalf("wasn't expecting error refreshing entry: %s", err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// Registry gives access to stores by name, such as the stores in the config file, so that links from one store to
+// another like "{{work:notes/project}}" can be resolved. Stores are only loaded when they're first needed. It implements
+// entries.Registry and is safe for concurrent use.
+type Registry struct {
+	mu     sync.Mutex
+	stores map[string]*registeredStore
+}
+
+// registeredStore is a store in a Registry. The store is nil until it's loaded.
+type registeredStore struct {
+	path  string
+	url   string
+	store *Store
+}
+
+// NewRegistry returns a new, empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{stores: make(map[string]*registeredStore)}
+}
+
+// Register adds the store at the path given to the registry under a name. url is the address an exported copy of the
+// store is published at, which is used to link to its entries when exporting; it can be blank. See URL.
+func (r *Registry) Register(name, path, url string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stores[name] = &registeredStore{path: path, url: url}
+}
+
+// Add adds a store which has already been loaded to the registry under a name, such as the store currently being used.
+func (r *Registry) Add(name string, store *Store, url string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stores[name] = &registeredStore{path: store.Path, url: url, store: store}
+}
+
+// Store returns the store with the name given, loading it if it hasn't been loaded yet. If there isn't a store with
+// that name, it returns ErrUnknownStore.
+func (r *Registry) Store(name string) (*Store, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	registered, ok := r.stores[name]
+	if !ok {
+		return nil, ErrUnknownStore{Name: name}
+	}
+
+	if registered.store == nil {
+		store, err := Load(registered.path)
+		if err != nil {
+			return nil, err
+		}
+
+		registered.store = store
+	}
+
+	return registered.store, nil
+}
+
+// Collection returns the collection of the store with the name given. It gives an error if there isn't a store with
+// that name or it can't be loaded, such as ErrStoreEncrypted if it's encrypted.
+func (r *Registry) Collection(name string) (*entries.Collection, error) {
+	store, err := r.Store(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.Collection()
+}
+
+// URL returns the address of the entry a link to another store points to, using the URL the store was registered
+// with. If the URL contains "{path}", it's replaced with the path of the entry, such as
+// "https://wiki.example.com/{path}.html". Otherwise the path is added to the end. It returns false if the link isn't to
+// another store or the store doesn't have a URL.
+func (r *Registry) URL(link entries.Link) (string, bool) {
+	if link.Store == "" {
+		return "", false
+	}
+
+	r.mu.Lock()
+	registered, ok := r.stores[link.Store]
+	r.mu.Unlock()
+
+	if !ok || registered.url == "" {
+		return "", false
+	}
+
+	if strings.Contains(registered.url, "{path}") {
+		return strings.ReplaceAll(registered.url, "{path}", link.Path), true
+	}
+
+	return strings.TrimSuffix(registered.url, "/") + "/" + link.Path, true
+}
+
+// SetRegistry sets the registry used to resolve links from the store's entries to other stores, see Registry.
+func (s *Store) SetRegistry(registry entries.Registry) {
+	s.collMu.Lock()
+	defer s.collMu.Unlock()
+
+	s.registry = registry
+
+	if s.coll != nil {
+		s.coll.SetRegistry(registry)
+	}
+}
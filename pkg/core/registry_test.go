@@ -0,0 +1,64 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/albatross-org/go-albatross/entries"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+
+	store, err := Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	registry := NewRegistry()
+	registry.Add("personal", store, "")
+	registry.Register("work", storePath, "https://work.example.com/wiki/")
+	registry.Register("school", filepath.Join(dir, "missing"), "https://school.example.com/{path}.html")
+
+	store.SetRegistry(registry)
+
+	err = store.Create("notes/project", "---\ntitle: Project\n---\n\nSee {{work:food/pizza}} and {{school:physics}}.")
+	if err != nil {
+		t.Fatalf("not expecting error creating entry: %s", err)
+	}
+
+	collection, err := store.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error getting collection: %s", err)
+	}
+
+	links := collection.Get("notes/project").OutboundLinks
+	if !Len(t, links, 2) {
+		return
+	}
+
+	linked := collection.ResolveLink(links[0])
+	if NotNil(t, linked, "expecting links to other stores to be resolved using the registry") {
+		Equal(t, "Pizza!", linked.Title)
+	}
+
+	Nil(t, collection.ResolveLink(links[1]), "expecting links to stores which can't be loaded not to resolve")
+
+	url, ok := registry.URL(links[0])
+	True(t, ok)
+	Equal(t, "https://work.example.com/wiki/food/pizza", url)
+
+	url, ok = registry.URL(links[1])
+	True(t, ok)
+	Equal(t, "https://school.example.com/physics.html", url)
+
+	_, ok = registry.URL(entries.Link{Store: "personal", Path: "food/pizza"})
+	False(t, ok, "expecting no URL for stores registered without one")
+
+	_, err = registry.Store("thesis")
+	Equal(t, ErrUnknownStore{Name: "thesis"}, err)
+}
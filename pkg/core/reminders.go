@@ -0,0 +1,201 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// The front matter keys which give an entry a Reminder.
+const (
+	// ReminderRemind is for entries which should be brought up again on a date, such as "remind: 2021-04-01".
+	ReminderRemind = "remind"
+
+	// ReminderExpires is for entries which stop being relevant after a date, such as "expires: 2022-01-01".
+	ReminderExpires = "expires"
+)
+
+// reminderLayouts are the layouts tried when parsing the date of a reminder. Dates without a time are due at midnight.
+var reminderLayouts = []string{"2006-01-02 15:04", "2006-01-02"}
+
+// Reminder is a date set in an entry's front matter using the "remind" or "expires" keys:
+//
+//	---
+//	title: Renew passport
+//	remind: 2021-04-01
+//	expires: "2022-01-01 09:00"
+//	---
+//
+// A key can also be given a list of dates. Dates are in local time.
+type Reminder struct {
+	// Path is the path to the entry, such as "admin/passport".
+	Path string `json:"path"`
+
+	// Title is the title of the entry.
+	Title string `json:"title"`
+
+	// Kind is the key the date was given by, ReminderRemind or ReminderExpires.
+	Kind string `json:"kind"`
+
+	// Due is when the reminder is due, or when the entry expires.
+	Due time.Time `json:"due"`
+}
+
+// key identifies the reminder in the record of reminders which have been notified, so that changing the date of a
+// reminder makes it notify again.
+func (r Reminder) key() string {
+	return r.Kind + " " + r.Path + " " + r.Due.UTC().Format(time.RFC3339)
+}
+
+// IsDue returns true if the reminder is due at the time given, or for ReminderExpires, if the entry has expired.
+func (r Reminder) IsDue(now time.Time) bool {
+	return !r.Due.After(now)
+}
+
+// EntryReminders returns the reminders set in the entry's front matter. If a date can't be parsed, it returns an error.
+func EntryReminders(entry *entries.Entry) ([]Reminder, error) {
+	reminders := []Reminder{}
+
+	for _, kind := range []string{ReminderRemind, ReminderExpires} {
+		value, ok := entry.Metadata[kind]
+		if !ok || value == nil {
+			continue
+		}
+
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+
+		for _, value := range values {
+			due, err := parseReminderDate(value)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't parse '%s' in %s: %w", kind, entry.Path, err)
+			}
+
+			reminders = append(reminders, Reminder{Path: entry.Path, Title: entry.Title, Kind: kind, Due: due})
+		}
+	}
+
+	return reminders, nil
+}
+
+// parseReminderDate parses the value of a "remind" or "expires" key.
+func parseReminderDate(value interface{}) (time.Time, error) {
+	switch value := value.(type) {
+	case time.Time:
+		return value, nil
+	case string:
+		for _, layout := range reminderLayouts {
+			due, err := time.ParseInLocation(layout, value, time.Local)
+			if err == nil {
+				return due, nil
+			}
+		}
+
+		return time.Time{}, fmt.Errorf("date '%s' isn't in the format '2006-01-02' or '2006-01-02 15:04'", value)
+	default:
+		return time.Time{}, fmt.Errorf("%v isn't a date", value)
+	}
+}
+
+// Reminders returns the reminders set by the entries in the list, sorted by when they're due. Entries whose dates can't
+// be parsed are left out, see Lint.
+func Reminders(list entries.List) []Reminder {
+	reminders := []Reminder{}
+
+	for _, entry := range list.Slice() {
+		entryReminders, err := EntryReminders(entry)
+		if err != nil {
+			continue
+		}
+
+		reminders = append(reminders, entryReminders...)
+	}
+
+	sort.SliceStable(reminders, func(i, j int) bool { return reminders[i].Due.Before(reminders[j].Due) })
+
+	return reminders
+}
+
+// remindersStatePath returns the path to the file recording which reminders have been notified. Like the read state,
+// it's kept outside the entries folder.
+func (s *Store) remindersStatePath() string {
+	return filepath.Join(s.Path, ".albatross", "reminders.json")
+}
+
+// notifiedReminders returns when each reminder was notified, by key.
+func (s *Store) notifiedReminders() (map[string]time.Time, error) {
+	notified := map[string]time.Time{}
+
+	data, err := ioutil.ReadFile(s.remindersStatePath())
+	if os.IsNotExist(err) {
+		return notified, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't read reminders state: %w", err)
+	}
+
+	err = json.Unmarshal(data, &notified)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse reminders state %s: %w", s.remindersStatePath(), err)
+	}
+
+	return notified, nil
+}
+
+// UnnotifiedReminders returns the reminders given which haven't been passed to MarkNotified, so that notifications for
+// a reminder are only sent once.
+func (s *Store) UnnotifiedReminders(reminders []Reminder) ([]Reminder, error) {
+	notified, err := s.notifiedReminders()
+	if err != nil {
+		return nil, err
+	}
+
+	unnotified := []Reminder{}
+
+	for _, reminder := range reminders {
+		if _, ok := notified[reminder.key()]; !ok {
+			unnotified = append(unnotified, reminder)
+		}
+	}
+
+	return unnotified, nil
+}
+
+// MarkNotified records that notifications have been sent for the reminders given.
+func (s *Store) MarkNotified(reminders ...Reminder) error {
+	notified, err := s.notifiedReminders()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, reminder := range reminders {
+		notified[reminder.key()] = now
+	}
+
+	data, err := json.Marshal(notified)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.remindersStatePath()), 0755)
+	if err != nil {
+		return fmt.Errorf("couldn't create directory for reminders state: %w", err)
+	}
+
+	tmpPath := s.remindersStatePath() + ".tmp"
+
+	err = ioutil.WriteFile(tmpPath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("couldn't write reminders state: %w", err)
+	}
+
+	return os.Rename(tmpPath, s.remindersStatePath())
+}
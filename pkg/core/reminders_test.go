@@ -0,0 +1,88 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestEntryReminders(t *testing.T) {
+	entry := &entries.Entry{
+		Path:  "admin/passport",
+		Title: "Passport",
+		Metadata: map[string]interface{}{
+			"remind":  []interface{}{"2021-04-01", "2021-05-01 09:30"},
+			"expires": "2022-01-01",
+		},
+	}
+
+	reminders, err := EntryReminders(entry)
+	if err != nil {
+		t.Fatalf("not expecting error getting reminders: %s", err)
+	}
+
+	Equal(t, []Reminder{
+		{Path: "admin/passport", Title: "Passport", Kind: ReminderRemind, Due: time.Date(2021, 4, 1, 0, 0, 0, 0, time.Local)},
+		{Path: "admin/passport", Title: "Passport", Kind: ReminderRemind, Due: time.Date(2021, 5, 1, 9, 30, 0, 0, time.Local)},
+		{Path: "admin/passport", Title: "Passport", Kind: ReminderExpires, Due: time.Date(2022, 1, 1, 0, 0, 0, 0, time.Local)},
+	}, reminders)
+
+	True(t, reminders[0].IsDue(time.Date(2021, 4, 1, 0, 0, 0, 0, time.Local)))
+	False(t, reminders[1].IsDue(time.Date(2021, 5, 1, 9, 0, 0, 0, time.Local)))
+
+	_, err = EntryReminders(&entries.Entry{Path: "bad", Metadata: map[string]interface{}{"remind": "01/04/2021"}})
+	Error(t, err, "expecting error for a date in the wrong format")
+
+	collection := entries.NewCollection()
+
+	for _, entry := range []*entries.Entry{
+		entry,
+		{Path: "bad", Title: "Bad", Metadata: map[string]interface{}{"expires": 12}},
+		{Path: "early", Title: "Early", Metadata: map[string]interface{}{"remind": "2020-01-01"}},
+	} {
+		err = collection.Add(entry)
+		if err != nil {
+			t.Fatalf("not expecting error adding entry: %s", err)
+		}
+	}
+
+	reminders = Reminders(collection.List())
+	if Len(t, reminders, 4, "expecting entries with invalid dates to be left out") {
+		Equal(t, "early", reminders[0].Path, "expecting reminders to be sorted by when they're due")
+	}
+}
+
+func TestStoreNotifiedReminders(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	passport := Reminder{Path: "admin/passport", Kind: ReminderRemind, Due: time.Date(2021, 4, 1, 0, 0, 0, 0, time.Local)}
+	licence := Reminder{Path: "admin/licence", Kind: ReminderExpires, Due: time.Date(2021, 4, 1, 0, 0, 0, 0, time.Local)}
+
+	unnotified, err := store.UnnotifiedReminders([]Reminder{passport, licence})
+	Nil(t, err)
+	Equal(t, []Reminder{passport, licence}, unnotified)
+
+	err = store.MarkNotified(passport)
+	if err != nil {
+		t.Fatalf("not expecting error marking reminder as notified: %s", err)
+	}
+
+	unnotified, err = store.UnnotifiedReminders([]Reminder{passport, licence})
+	Nil(t, err)
+	Equal(t, []Reminder{licence}, unnotified)
+
+	passport.Due = passport.Due.AddDate(1, 0, 0)
+
+	unnotified, err = store.UnnotifiedReminders([]Reminder{passport})
+	Nil(t, err)
+	Equal(t, []Reminder{passport}, unnotified, "expecting a reminder to notify again when its date changes")
+}
@@ -0,0 +1,244 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// Rename moves the entry at oldPath to newPath, along with its attachments and any entries nested inside it, and records
+// the move in a single commit. Moving entries by hand breaks the path links which point to them, so if rewriteLinks is
+// true, links like "{{food/pizza}}" in other entries are changed to point to the new path. Links to entries nested inside
+// the entry are rewritten too.
+//
+// It returns the paths of the entries whose links were rewritten, after the move. If rewriteLinks is false, it returns the
+// paths of the entries which still link to the old path, since their links are now broken.
+func (s *Store) Rename(oldPath, newPath string, rewriteLinks bool) (linking []string, err error) {
+	oldPath, newPath = strings.Trim(oldPath, "/"), strings.Trim(newPath, "/")
+	defer func() { s.recordAudit("rename", err, oldPath, newPath) }()
+
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return nil, err
+	} else if encrypted {
+		return nil, ErrStoreEncrypted{Path: s.Path}
+	}
+
+	oldFolder := filepath.Join(s.entriesPath, filepath.FromSlash(oldPath))
+	newFolder := filepath.Join(s.entriesPath, filepath.FromSlash(newPath))
+
+	if !exists(filepath.Join(oldFolder, "entry.md")) {
+		return nil, ErrEntryDoesntExist{oldFolder}
+	}
+
+	if newPath == "" {
+		return nil, fmt.Errorf("can't move %s to the root of the store", oldPath)
+	} else if newPath == oldPath || isUnderPath(newPath, oldPath) {
+		return nil, fmt.Errorf("can't move %s inside itself to %s", oldPath, newPath)
+	}
+
+	if exists(filepath.Join(newFolder, "entry.md")) {
+		return nil, ErrEntryAlreadyExists{newFolder}
+	} else if exists(newFolder) {
+		return nil, fmt.Errorf("can't move %s to %s, something is already there", oldPath, newPath)
+	}
+
+	coll, err := s.Collection()
+	if err != nil {
+		return nil, err
+	}
+
+	// The new contents of the linking entries are worked out before anything is moved, so that an entry which can't be
+	// rewritten doesn't leave the store half changed.
+	rewritten := map[string]string{}
+
+	for _, entry := range coll.List().Slice() {
+		contents, ok, err := rewritePathLinks(entry, oldPath, newPath)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't rewrite links in %s: %w", entry.Path, err)
+		} else if !ok {
+			continue
+		}
+
+		// Entries inside the one being moved link to each other from their new location.
+		entryPath := movedPath(entry.Path, oldPath, newPath)
+		linking = append(linking, entryPath)
+		rewritten[entryPath] = entry.Encoding.Apply(contents)
+	}
+
+	sort.Strings(linking)
+
+	err = os.MkdirAll(filepath.Dir(newFolder), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.Rename(oldFolder, newFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := map[string]bool{oldPath: true, newPath: true}
+
+	for _, entryPath := range linking {
+		if !rewriteLinks {
+			break
+		}
+
+		err = ioutil.WriteFile(filepath.Join(s.entriesPath, filepath.FromSlash(entryPath), "entry.md"), []byte(rewritten[entryPath]), 0644)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't rewrite links in %s: %w", entryPath, err)
+		}
+
+		if !changed[entryPath] {
+			changed[entryPath] = false
+		}
+	}
+
+	err = s.updateReadTimes(func(times map[string]time.Time) {
+		for path, read := range times {
+			if moved := movedPath(path, oldPath, newPath); moved != path {
+				delete(times, path)
+				times[moved] = read
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.updateRecentViews(func(views []View) []View {
+		for i, view := range views {
+			views[i].Path = movedPath(view.Path, oldPath, newPath)
+		}
+
+		return views
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.repo != nil && !s.disableGit {
+		paths := []string{oldPath, newPath}
+		message := fmt.Sprintf("(go-albatross) Rename %s to %s", oldPath, newPath)
+
+		if rewriteLinks && len(linking) != 0 {
+			paths = append(paths, linking...)
+			message += fmt.Sprintf("\n\nRewrote links in:\n- %s\n", strings.Join(linking, "\n- "))
+		}
+
+		err = s.commitPaths(paths, message)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.refreshPaths(changed)
+	if err != nil {
+		return nil, err
+	}
+
+	return linking, nil
+}
+
+// rewritePathLinks returns the contents of the entry's file with the path links to oldPath, or to entries inside it,
+// changed to point to newPath instead. Links to other stores are left alone. It returns false if the entry doesn't
+// contain any links which need changing.
+//
+// Entries which were truncated or transformed when they were parsed are parsed again from their whole file first, so
+// that links which weren't in their Contents are changed too.
+func rewritePathLinks(entry *entries.Entry, oldPath, newPath string) (string, bool, error) {
+	if !linksMatchFile(entry) {
+		reparsed, err := entries.NewEntryFromContents(entry.Path, []byte(entry.OriginalContents), entry.ModTime, entries.Limits{})
+		if err != nil {
+			return "", false, err
+		}
+
+		if !linksMatchFile(reparsed) {
+			return "", false, fmt.Errorf("couldn't find where the links in %s are", entry.Path)
+		}
+
+		entry = reparsed
+	}
+
+	contents, ok := replaceLinks(entry, func(link entries.Link, text string) (string, bool) {
+		if link.Type != entries.LinkPathNoName && link.Type != entries.LinkPathWithName || link.Store != "" {
+			return "", false
+		}
+
+		target := strings.Trim(link.Path, "/")
+		if target != oldPath && !isUnderPath(target, oldPath) {
+			return "", false
+		}
+
+		return strings.Replace(text, link.Path, movedPath(target, oldPath, newPath), 1), true
+	})
+
+	return contents, ok, nil
+}
+
+// linksMatchFile returns true if the locations of the entry's links can be used to find them in its OriginalContents.
+// Link locations are relative to the entry's contents without its front matter, which is always the end of the file
+// unless the entry was too long and had to be truncated, or was changed by a transform.
+func linksMatchFile(entry *entries.Entry) bool {
+	return !entry.Truncated && strings.HasSuffix(entry.OriginalContents, entry.Contents)
+}
+
+// replaceLinks returns the contents of the entry's file with the text of some of its links replaced. The function given is
+// called with each link and its text, like "{{food/pizza}}", and returns the text to replace it with and true, or false to
+// leave it alone. It returns false if no links were replaced, or if the links can't be found in the file, see
+// linksMatchFile.
+func replaceLinks(entry *entries.Entry, replace func(link entries.Link, text string) (string, bool)) (string, bool) {
+	if !linksMatchFile(entry) {
+		return "", false
+	}
+
+	offset := len(entry.OriginalContents) - len(entry.Contents)
+	contents := entry.OriginalContents
+	replaced := false
+
+	links := make([]entries.Link, len(entry.OutboundLinks))
+	copy(links, entry.OutboundLinks)
+
+	// Links are replaced from the end of the entry backwards so that the locations of the ones before don't change.
+	sort.Slice(links, func(i, j int) bool { return links[i].Loc[0] > links[j].Loc[0] })
+
+	for _, link := range links {
+		start, end := offset+link.Loc[0], offset+link.Loc[1]
+
+		text, ok := replace(link, contents[start:end])
+		if !ok {
+			continue
+		}
+
+		contents = contents[:start] + text + contents[end:]
+		replaced = true
+	}
+
+	return contents, replaced
+}
+
+// movedPath returns where path ends up when the entry at oldPath is moved to newPath. Paths which aren't oldPath or
+// inside it are returned unchanged.
+func movedPath(path, oldPath, newPath string) string {
+	if path == oldPath {
+		return newPath
+	}
+
+	if isUnderPath(path, oldPath) {
+		return newPath + strings.TrimPrefix(path, oldPath)
+	}
+
+	return path
+}
+
+// isUnderPath returns true if path is an entry nested inside the entry at parent, like "food/pizza" inside "food".
+func isUnderPath(path, parent string) bool {
+	return strings.HasPrefix(path, parent+"/")
+}
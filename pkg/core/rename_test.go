@@ -0,0 +1,136 @@
+package core
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/albatross-org/go-albatross/entries"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreRename(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	err = store.Create("food/pizza/toppings", "Pineapple, like {{food/pizza}}.")
+	if err != nil {
+		t.Fatalf("not expecting error when creating sub-entry: %s", err)
+	}
+
+	err = store.MarkRead("food/pizza")
+	if err != nil {
+		t.Fatalf("not expecting error when marking entry as read: %s", err)
+	}
+
+	linking, err := store.Rename("food/pizza", "recipes/pizza", true)
+	Nil(t, err, "not expecting error when renaming entry")
+	Equal(t, []string{"food/ice-cream", "recipes/pizza/toppings"}, linking)
+
+	False(t, exists(filepath.Join(store.entriesPath, "food", "pizza")), "expecting old folder to be removed")
+	True(t, exists(filepath.Join(store.entriesPath, "recipes", "pizza", "pizza.jpg")), "expecting attachments to be moved")
+
+	contents, err := ioutil.ReadFile(filepath.Join(store.entriesPath, "food", "ice-cream", "entry.md"))
+	Nil(t, err, "not expecting error reading linking entry")
+	Contains(t, string(contents), "Like {{recipes/pizza}}, they are")
+
+	collection, err := store.Collection()
+	Nil(t, err, "not expecting error getting collection")
+
+	Nil(t, collection.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: "food/pizza"}))
+	Nil(t, collection.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: "food/pizza/toppings"}))
+
+	pizza := collection.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: "recipes/pizza"})
+	if NotNil(t, pizza, "expecting entry at new path") {
+		Equal(t, "Pizza!", pizza.Title)
+	}
+
+	toppings := collection.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: "recipes/pizza/toppings"})
+	if NotNil(t, toppings, "expecting sub-entry to be moved") {
+		Equal(t, "Pineapple, like {{recipes/pizza}}.", toppings.Contents)
+	}
+
+	times, err := store.ReadTimes()
+	Nil(t, err, "not expecting error getting read times")
+	Contains(t, times, "recipes/pizza")
+	NotContains(t, times, "food/pizza")
+}
+
+func TestStoreRenameWithoutRewriting(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	linking, err := store.Rename("moods/hunger", "feelings/hunger", false)
+	Nil(t, err, "not expecting error when renaming entry")
+	Equal(t, []string{"food/ice-cream", "food/pizza", "journal/2020-08-06"}, linking)
+
+	contents, err := ioutil.ReadFile(filepath.Join(store.entriesPath, "food", "pizza", "entry.md"))
+	Nil(t, err, "not expecting error reading linking entry")
+	Contains(t, string(contents), "{{moods/hunger}(Hungry)}")
+}
+
+func TestStoreRenameTruncated(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	store.config.Set("entries.max-contents", "1KB")
+
+	long := "---\ntitle: Long\n---\n\n" + strings.Repeat("word ", 300) + "\n\nSee {{food/pizza}}.\n"
+
+	err = ioutil.WriteFile(filepath.Join(store.entriesPath, "journal", "2020-08-07", "entry.md"), []byte(long), 0644)
+	if err != nil {
+		t.Fatalf("not expecting error writing long entry: %s", err)
+	}
+
+	err = store.reload()
+	if err != nil {
+		t.Fatalf("not expecting error reloading store: %s", err)
+	}
+
+	linking, err := store.Rename("food/pizza", "recipes/pizza", true)
+	Nil(t, err, "not expecting error when renaming entry")
+	Contains(t, linking, "journal/2020-08-07", "expecting links past the end of truncated entries to be found")
+
+	contents, err := ioutil.ReadFile(filepath.Join(store.entriesPath, "journal", "2020-08-07", "entry.md"))
+	Nil(t, err, "not expecting error reading long entry")
+	Equal(t, strings.Replace(long, "{{food/pizza}}", "{{recipes/pizza}}", 1), string(contents), "expecting the whole of truncated entries to be kept")
+}
+
+func TestStoreRenameErrors(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	_, err = store.Rename("food/burgers", "food/sandwiches", true)
+	IsType(t, ErrEntryDoesntExist{}, err)
+
+	_, err = store.Rename("food/pizza", "food/ice-cream", true)
+	IsType(t, ErrEntryAlreadyExists{}, err)
+
+	_, err = store.Rename("food/pizza", "food/pizza/margherita", true)
+	NotNil(t, err, "expecting error moving entry inside itself")
+
+	_, err = store.Rename("food/pizza", "moods", true)
+	NotNil(t, err, "expecting error moving entry onto an existing folder")
+}
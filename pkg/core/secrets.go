@@ -0,0 +1,131 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// AllowSecretMarker can be put on a line to stop anything on it being reported as a secret, for things which look like
+// secrets but aren't, like example keys in documentation.
+const AllowSecretMarker = "albatross:allow-secret"
+
+// Secret is something in an entry which looks like a credential, found by FindSecrets.
+type Secret struct {
+	// Kind describes what the secret looks like, such as "AWS access key".
+	Kind string `json:"kind"`
+
+	// Line is the line it's on, counted from 1.
+	Line int `json:"line"`
+
+	// Redacted is the start of the secret, so that it can be found without printing all of it.
+	Redacted string `json:"redacted"`
+}
+
+// String describes the secret, such as `line 3 looks like an AWS access key ("AKIA...")`.
+func (s Secret) String() string {
+	return fmt.Sprintf("line %d looks like %s (%q)", s.Line, s.Kind, s.Redacted)
+}
+
+// secretPatterns are the regular expressions for secrets in well-known formats, by what they look like.
+var secretPatterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"a private key", regexp.MustCompile(`-----BEGIN ((RSA|DSA|EC|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`)},
+	{"an AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"an AWS secret key", regexp.MustCompile(`(?i)aws.{0,20}(secret|key).{0,20}?['"=:\s]([0-9a-zA-Z/+]{40})\b`)},
+	{"a GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{"a Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{"a password in a URL", regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@]+:[^/\s:@]+@`)},
+}
+
+// reToken matches the runs of characters which are checked for being high-entropy tokens.
+var reToken = regexp.MustCompile(`[A-Za-z0-9+/=_-]{32,}`)
+
+// minTokenEntropy is the Shannon entropy, in bits per character, above which a long token is considered random enough to
+// be a secret. Words and paths are well below it, random base64 is above it.
+const minTokenEntropy = 4.0
+
+// FindSecrets returns anything in the text which looks like a secret, such as AWS keys, private key blocks and long
+// random-looking tokens. Lines containing AllowSecretMarker are skipped.
+func FindSecrets(text string) []Secret {
+	secrets := []Secret{}
+
+	for i, line := range strings.Split(text, "\n") {
+		if strings.Contains(line, AllowSecretMarker) {
+			continue
+		}
+
+		found := false
+
+		for _, pattern := range secretPatterns {
+			if match := pattern.re.FindString(line); match != "" {
+				secrets = append(secrets, Secret{Kind: pattern.kind, Line: i + 1, Redacted: redactSecret(match)})
+				found = true
+			}
+		}
+
+		// A line with a known kind of secret doesn't need to be reported again as a high-entropy token.
+		if found {
+			continue
+		}
+
+		for _, token := range reToken.FindAllString(line, -1) {
+			if looksRandom(token) {
+				secrets = append(secrets, Secret{Kind: "a high-entropy token", Line: i + 1, Redacted: redactSecret(token)})
+			}
+		}
+	}
+
+	return secrets
+}
+
+// looksRandom returns true if a token has a mix of upper case letters, lower case letters and digits and a high entropy,
+// like an API key, rather than being something like a long word or a hex hash.
+func looksRandom(token string) bool {
+	var upper, lower, digit bool
+
+	for _, r := range token {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+
+	return upper && lower && digit && shannonEntropy(token) >= minTokenEntropy
+}
+
+// shannonEntropy returns the Shannon entropy of the string in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+
+	entropy := 0.0
+	length := float64(len(s))
+
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+// redactSecret returns the first few characters of a secret followed by "...".
+func redactSecret(secret string) string {
+	const shown = 8
+
+	if len(secret) <= shown {
+		return secret
+	}
+
+	return secret[:shown] + "..."
+}
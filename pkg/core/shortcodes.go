@@ -0,0 +1,116 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// Shortcodes holds the templates for the shortcodes defined in a store, by name.
+type Shortcodes map[string]*ShortcodeTemplate
+
+// ShortcodeTemplate is the template for a shortcode, along with the store it was defined in.
+type ShortcodeTemplate struct {
+	*template.Template
+
+	// Store is what the template sees as .Store.
+	Store TemplateStore
+}
+
+// ShortcodeContext is the context a shortcode's template is executed with.
+type ShortcodeContext struct {
+	// Name is the name of the shortcode.
+	Name string
+
+	// Args are the positional arguments given to the shortcode.
+	Args []string
+
+	// Params are the named arguments given to the shortcode.
+	Params map[string]string
+
+	// Entry is the entry the shortcode is being expanded in.
+	Entry *entries.Entry
+
+	// Store holds values set for the whole store, such as .Store.Vars.
+	Store TemplateStore
+}
+
+// Arg returns the positional argument at index i, or an empty string if there isn't one.
+func (c ShortcodeContext) Arg(i int) string {
+	if i < 0 || i >= len(c.Args) {
+		return ""
+	}
+
+	return c.Args[i]
+}
+
+// Shortcodes returns the shortcodes defined in the "templates/shortcodes/" directory of the store. Each file is a
+// template for the shortcode with the same name as the file, without the extension. Like other templates, they use
+// "<(" and ")>" as delimiters. If the directory doesn't exist, no shortcodes are returned.
+func (s *Store) Shortcodes() (Shortcodes, error) {
+	dir := filepath.Join(s.Path, "templates", "shortcodes")
+	shortcodes := Shortcodes{}
+	store := s.TemplateStore()
+
+	infos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return shortcodes, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+
+		name := strings.TrimSuffix(info.Name(), filepath.Ext(info.Name()))
+
+		contents, err := ioutil.ReadFile(filepath.Join(dir, info.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		tmpl, err := template.New(name).Delims("<(", ")>").Funcs(sprig.TxtFuncMap()).Parse(strings.TrimSuffix(string(contents), "\n"))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing shortcode %q: %w", name, err)
+		}
+
+		shortcodes[name] = &ShortcodeTemplate{Template: tmpl, Store: store}
+	}
+
+	return shortcodes, nil
+}
+
+// Expand returns the contents of the entry with its shortcodes expanded. Shortcodes which aren't defined are left as
+// they are.
+func (sc Shortcodes) Expand(entry *entries.Entry) (string, error) {
+	return entry.ExpandShortcodes(func(shortcode entries.Shortcode) (string, error) {
+		tmpl, ok := sc[shortcode.Name]
+		if !ok {
+			return shortcode.Text, nil
+		}
+
+		var out bytes.Buffer
+
+		err := tmpl.Execute(&out, ShortcodeContext{
+			Name:   shortcode.Name,
+			Args:   shortcode.Args,
+			Params: shortcode.Params,
+			Entry:  entry,
+			Store:  tmpl.Store,
+		})
+		if err != nil {
+			return "", fmt.Errorf("error expanding shortcode %q in %s: %w", shortcode.Name, entry.Path, err)
+		}
+
+		return out.String(), nil
+	})
+}
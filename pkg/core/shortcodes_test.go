@@ -0,0 +1,48 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/albatross-org/go-albatross/entries"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreShortcodes(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+
+	store := loadWithConfig(t, storePath, "variables:\n  author: Jane Doe\n")
+
+	shortcodes, err := store.Shortcodes()
+	Nil(t, err, "getting shortcodes without a shortcodes directory, err should be nil")
+	Len(t, shortcodes, 0, "there should be no shortcodes when there's no directory")
+
+	shortcodesPath := filepath.Join(storePath, "templates", "shortcodes")
+
+	err = os.MkdirAll(shortcodesPath, 0755)
+	if err != nil {
+		t.Fatalf("not expecting error creating shortcodes directory: %s", err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(shortcodesPath, "contact.md"), []byte("**<(.Arg 0)>** (<(.Params.phone | default \"no phone\")>) in <(.Entry.Title)> by <(.Store.Vars.author)>\n"), 0644)
+	if err != nil {
+		t.Fatalf("not expecting error writing shortcode: %s", err)
+	}
+
+	shortcodes, err = store.Shortcodes()
+	Nil(t, err, "getting shortcodes, err should be nil")
+
+	entry := &entries.Entry{
+		Title:    "People",
+		Contents: "Call {{< contact \"Jane\" phone=\"0123\" >}} or {{< contact Joe >}}, not {{< unknown >}}.",
+	}
+
+	expanded, err := shortcodes.Expand(entry)
+	Nil(t, err, "expanding shortcodes, err should be nil")
+	Equal(t, "Call **Jane** (0123) in People by Jane Doe or **Joe** (no phone) in People by Jane Doe, not {{< unknown >}}.", expanded)
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/Masterminds/sprig"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultPathPattern is the pattern used by PathFromTitle if "create.path-pattern" isn't set in the config.
+const DefaultPathPattern = "<(.slug)>"
+
+// maxPathSuffix is the highest number PathFromTitle adds to the end of a path to stop it colliding with an existing entry.
+const maxPathSuffix = 1000
+
+// Slugify converts a title into something which can be used as part of a path, like "my-great-idea" for "My Great Idea!".
+// Letters are lower-cased and have their accents removed, apostrophes are dropped and everything else which isn't a letter
+// or a digit becomes a single hyphen.
+func Slugify(title string) string {
+	var slug strings.Builder
+
+	hyphen := false
+
+	for _, r := range norm.NFD.String(title) {
+		switch {
+		case unicode.Is(unicode.Mn, r), r == '\'', r == '’':
+			// Accents are split from their letters by NFD, so removing them leaves the plain letter.
+			continue
+
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if hyphen && slug.Len() != 0 {
+				slug.WriteRune('-')
+			}
+
+			hyphen = false
+			slug.WriteRune(unicode.ToLower(r))
+
+		default:
+			hyphen = true
+		}
+	}
+
+	return norm.NFC.String(slug.String())
+}
+
+// PathPattern returns the pattern for the paths of entries created from a title, set by "create.path-pattern" in the
+// config, or DefaultPathPattern if it isn't set.
+func (s *Store) PathPattern() string {
+	pattern := s.config.GetString("create.path-pattern")
+	if pattern == "" {
+		return DefaultPathPattern
+	}
+
+	return pattern
+}
+
+// PathFromTitle returns a path for a new entry with the title given, such as "ideas/2020/my-great-idea", so that one
+// doesn't have to be made up. The pattern is a template using the same "<(" and ")>" delimiters and Sprig functions as
+// entry templates, with .title, .slug and .date set, like:
+//
+//	ideas/<(.date | date "2006")>/<(.slug)>
+//
+// If the pattern is blank, PathPattern is used. If something already exists at the path, "-2", "-3" and so on are added
+// to the end until it doesn't.
+func (s *Store) PathFromTitle(pattern, title string, date time.Time) (string, error) {
+	if pattern == "" {
+		pattern = s.PathPattern()
+	}
+
+	slug := Slugify(title)
+	if slug == "" {
+		return "", fmt.Errorf("title %q doesn't contain any letters or digits to make a path from", title)
+	}
+
+	tmpl, err := template.New("path").Delims("<(", ")>").Funcs(sprig.TxtFuncMap()).Parse(pattern)
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse path pattern %q: %w", pattern, err)
+	}
+
+	var out bytes.Buffer
+
+	err = tmpl.Execute(&out, map[string]interface{}{
+		"title": title,
+		"slug":  slug,
+		"date":  date,
+	})
+	if err != nil {
+		return "", fmt.Errorf("couldn't execute path pattern %q: %w", pattern, err)
+	}
+
+	entryPath := strings.Trim(path.Clean("/"+strings.TrimSpace(out.String())), "/")
+	if entryPath == "" {
+		return "", fmt.Errorf("path pattern %q gave an empty path", pattern)
+	}
+
+	if !exists(filepath.Join(s.entriesPath, filepath.FromSlash(entryPath))) {
+		return entryPath, nil
+	}
+
+	for i := 2; i <= maxPathSuffix; i++ {
+		candidate := entryPath + "-" + strconv.Itoa(i)
+		if !exists(filepath.Join(s.entriesPath, filepath.FromSlash(candidate))) {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("couldn't find a free path like %s, there are already %d of them", entryPath, maxPathSuffix)
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"My Great Idea":            "my-great-idea",
+		"  Don't Panic!  ":         "dont-panic",
+		"Café au lait":             "cafe-au-lait",
+		"GCSE Physics -- Topic 7":  "gcse-physics-topic-7",
+		"Über/Straße":              "uber-straße",
+		"!!!":                      "",
+		"日本語 notes":                "日本語-notes",
+		"Multiple   spaces\tand\n": "multiple-spaces-and",
+	}
+
+	for title, expected := range cases {
+		Equal(t, expected, Slugify(title), "slug of %q", title)
+	}
+}
+
+func TestStorePathFromTitle(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	date := time.Date(2020, 8, 6, 0, 0, 0, 0, time.UTC)
+
+	path, err := store.PathFromTitle("", "My Great Idea", date)
+	Nil(t, err)
+	Equal(t, "my-great-idea", path, "expecting default pattern to be the slug")
+
+	store.config.Set("create.path-pattern", `ideas/<(.date | date "2006")>/<(.slug)>`)
+
+	path, err = store.PathFromTitle("", "My Great Idea", date)
+	Nil(t, err)
+	Equal(t, "ideas/2020/my-great-idea", path, "expecting pattern from config to be used")
+
+	path, err = store.PathFromTitle("food/<(.slug)>", "Pizza", date)
+	Nil(t, err)
+	Equal(t, "food/pizza-2", path, "expecting suffix when an entry already exists")
+
+	err = store.Create("food/pizza-2", "Another pizza.")
+	if err != nil {
+		t.Fatalf("not expecting error creating entry: %s", err)
+	}
+
+	path, err = store.PathFromTitle("food/<(.slug)>", "Pizza", date)
+	Nil(t, err)
+	Equal(t, "food/pizza-3", path, "expecting next suffix when the first is taken")
+
+	path, err = store.PathFromTitle("/../<(.slug)>/", "Escape", date)
+	Nil(t, err)
+	Equal(t, "escape", path, "expecting path to stay inside the store")
+
+	_, err = store.PathFromTitle("", "!!!", date)
+	NotNil(t, err, "expecting error for title without any letters")
+
+	_, err = store.PathFromTitle("<(.missing", "Title", date)
+	NotNil(t, err, "expecting error for invalid pattern")
+}
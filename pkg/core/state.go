@@ -0,0 +1,126 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// State describes the current state of a store, such as whether it's encrypted and whether there are changes which
+// haven't been committed. It's intended for scripts and editor plugins which need to know what they can do with a store.
+type State struct {
+	// Path is the path to the store.
+	Path string `json:"path"`
+
+	// Encrypted is true if the store is currently encrypted.
+	Encrypted bool `json:"encrypted"`
+
+	// LastEncrypted is when the store was last encrypted. It's taken from the audit log, falling back to the modification
+	// time of the encrypted archive if the store is encrypted. It's the zero time if unknown.
+	LastEncrypted time.Time `json:"lastEncrypted"`
+
+	// LastDecrypted is when the store was last decrypted, taken from the audit log. It's the zero time if unknown.
+	LastDecrypted time.Time `json:"lastDecrypted"`
+
+	// UsingGit is true if the store's entries are in a git repository. It's always false while the store is encrypted,
+	// since the repository is encrypted along with the entries.
+	UsingGit bool `json:"usingGit"`
+
+	// LastCommit is the most recent git commit, or nil if there isn't one or the store isn't using git.
+	LastCommit *Commit `json:"lastCommit"`
+
+	// Uncommitted are the paths of files, relative to the entries folder, which have changes that haven't been committed.
+	Uncommitted []string `json:"uncommitted"`
+
+	// Locked is true if the git repository is locked, meaning another process is in the middle of changing it or one was
+	// interrupted while doing so. Changes can't be committed while the store is locked.
+	Locked bool `json:"locked"`
+}
+
+// Commit is a summary of a git commit.
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// State returns the current state of the store. Unlike most methods, it works while the store is encrypted.
+func (s *Store) State() (*State, error) {
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &State{
+		Path:        s.Path,
+		Encrypted:   encrypted,
+		Uncommitted: []string{},
+	}
+
+	events, err := s.AuditLog(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		if event.Result != "ok" {
+			continue
+		}
+
+		switch event.Operation {
+		case "encrypt":
+			state.LastEncrypted = event.Time
+		case "decrypt":
+			state.LastDecrypted = event.Time
+		}
+	}
+
+	if encrypted {
+		if state.LastEncrypted.IsZero() {
+			info, err := os.Stat(s.entriesPath + ".gpg")
+			if err != nil {
+				return nil, err
+			}
+
+			state.LastEncrypted = info.ModTime()
+		}
+
+		return state, nil
+	}
+
+	if s.repo == nil {
+		return state, nil
+	}
+
+	state.UsingGit = true
+
+	_, err = os.Stat(filepath.Join(s.entriesPath, ".git", "index.lock"))
+	state.Locked = err == nil
+
+	head, err := s.repo.Head()
+	if err != nil && err != plumbing.ErrReferenceNotFound {
+		return nil, err
+	} else if err == nil {
+		commit, err := s.repo.CommitObject(head.Hash())
+		if err != nil {
+			return nil, err
+		}
+
+		state.LastCommit = &Commit{
+			Hash:    commit.Hash.String(),
+			Author:  commit.Author.Name,
+			Message: commit.Message,
+			Time:    commit.Committer.When,
+		}
+	}
+
+	state.Uncommitted, err = s.Dirty()
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreState(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+
+	store, err := Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	state, err := store.State()
+	if err != nil {
+		t.Fatalf("not expecting error when getting state: %s", err)
+	}
+
+	False(t, state.Encrypted, "test store shouldn't be encrypted")
+	False(t, state.UsingGit, "test store isn't using git")
+	Nil(t, state.LastCommit, "store without git should have no last commit")
+
+	_, err = git.PlainInit(filepath.Join(storePath, "entries"), false)
+	if err != nil {
+		t.Fatalf("not expecting error when initialising git repository: %s", err)
+	}
+
+	store, err = Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	err = store.Create("food/truffles", "Truffles are great.")
+	if err != nil {
+		t.Fatalf("not expecting error when creating truffles entry: %s", err)
+	}
+
+	state, err = store.State()
+	if err != nil {
+		t.Fatalf("not expecting error when getting state: %s", err)
+	}
+
+	True(t, state.UsingGit, "store should be using git")
+	False(t, state.Locked, "store shouldn't be locked")
+	NotNil(t, state.LastCommit, "store should have a last commit")
+	Contains(t, state.LastCommit.Message, "food/truffles", "last commit should be for the new entry")
+	Contains(t, state.Uncommitted, "food/pizza/entry.md", "entries which were never committed should be uncommitted")
+	NotContains(t, state.Uncommitted, "food/truffles/entry.md", "committed entry shouldn't be uncommitted")
+}
@@ -0,0 +1,253 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// Stats holds statistics about the entries in a store.
+type Stats struct {
+	// Entries is the number of entries.
+	Entries int `json:"entries"`
+
+	// EntriesSize is the total size of all the entry.md files, in bytes.
+	EntriesSize int64 `json:"entriesSize"`
+
+	// Attachments is the number of attachments, i.e. files that belong to an entry which aren't the entry.md file.
+	Attachments int `json:"attachments"`
+
+	// AttachmentsSize is the total size of all the attachments, in bytes.
+	AttachmentsSize int64 `json:"attachmentsSize"`
+
+	// Commits is the total number of git commits in the store. It is zero if the store isn't using git.
+	Commits int `json:"commits"`
+
+	// Undated is the number of entries without a date, which aren't counted in any month.
+	Undated int `json:"undated"`
+
+	// Months is the growth of the store over time, in chronological order. Only months where an entry is dated or a
+	// commit was made are present.
+	Months []MonthStats `json:"months"`
+}
+
+// CommitStats holds statistics about the git history of a store, which can be computed once using Store.CommitStats and
+// reused for many calls to Store.CollectionStatsWithCommits since reading the history is slow for large stores.
+type CommitStats struct {
+	// Commits is the total number of git commits in the store.
+	Commits int
+
+	// Months is the number of commits made in each month, keyed by the month in the format "2006-01".
+	Months map[string]int
+}
+
+// MonthStats holds statistics about the growth of a store in a single month.
+type MonthStats struct {
+	// Month is the month in the format "2006-01".
+	Month string `json:"month"`
+
+	// Entries is the number of entries dated in this month.
+	Entries int `json:"entries"`
+
+	// Cumulative is the number of entries dated in this month or before. Undated entries are never included.
+	Cumulative int `json:"cumulative"`
+
+	// Commits is the number of git commits made in this month.
+	Commits int `json:"commits"`
+}
+
+// Stats computes statistics about every entry in the store. If the store is encrypted, it returns ErrStoreEncrypted.
+func (s *Store) Stats() (*Stats, error) {
+	collection, err := s.Collection()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.CollectionStats(collection)
+}
+
+// CollectionStats computes statistics about the entries in the collection given, which should be a subset of the
+// store's entries, such as one returned by filtering. Since git history can't be split by entry, the commit counts
+// always describe the whole store. If the store is encrypted, it returns ErrStoreEncrypted.
+func (s *Store) CollectionStats(collection *entries.Collection) (*Stats, error) {
+	commits, err := s.CommitStats()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.CollectionStatsWithCommits(collection, commits)
+}
+
+// CommitStats counts the commits in the store's git repository, in total and by month. If the store isn't using git,
+// the counts are zero. If the store is encrypted, it returns ErrStoreEncrypted.
+func (s *Store) CommitStats() (*CommitStats, error) {
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return nil, err
+	} else if encrypted {
+		return nil, ErrStoreEncrypted{Path: s.Path}
+	}
+
+	commits := &CommitStats{Months: make(map[string]int)}
+
+	err = s.commitsByMonth(func(key string) {
+		commits.Commits++
+		commits.Months[key]++
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return commits, nil
+}
+
+// CollectionStatsWithCommits is like CollectionStats, but uses commit statistics from an earlier call to CommitStats
+// rather than reading the git history again.
+func (s *Store) CollectionStatsWithCommits(collection *entries.Collection, commits *CommitStats) (*Stats, error) {
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return nil, err
+	} else if encrypted {
+		return nil, ErrStoreEncrypted{Path: s.Path}
+	}
+
+	stats := &Stats{Commits: commits.Commits}
+	months := make(map[string]*MonthStats)
+
+	month := func(key string) *MonthStats {
+		if months[key] == nil {
+			months[key] = &MonthStats{Month: key}
+		}
+
+		return months[key]
+	}
+
+	included := make(map[string]bool, collection.Len())
+	err = collection.ForEach(nil, func(entry *entries.Entry) error {
+		included[entry.Path] = true
+
+		if entry.Date.IsZero() {
+			stats.Undated++
+		} else {
+			month(entry.Date.Format("2006-01")).Entries++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.sizes(included, stats)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, count := range commits.Months {
+		month(key).Commits += count
+	}
+
+	for _, m := range months {
+		stats.Months = append(stats.Months, *m)
+	}
+
+	sort.Slice(stats.Months, func(i, j int) bool { return stats.Months[i].Month < stats.Months[j].Month })
+
+	cumulative := 0
+	for i := range stats.Months {
+		cumulative += stats.Months[i].Entries
+		stats.Months[i].Cumulative = cumulative
+	}
+
+	return stats, nil
+}
+
+// sizes walks the entries folder and counts the entry files and attachments belonging to the entries in included,
+// a set of entry paths. An attachment belongs to the closest enclosing folder containing an entry.md file.
+func (s *Store) sizes(included map[string]bool, stats *Stats) error {
+	entryDirs := make(map[string]bool)
+	files := make(map[string]int64)
+
+	err := filepath.Walk(s.entriesPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.entriesPath, path)
+		if err != nil {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		if info.Name() == "entry.md" {
+			entryDirs[strings.TrimSuffix(strings.TrimSuffix(rel, "entry.md"), "/")] = true
+		} else if info.Name() == AnnotationsFile || info.Name() == entries.FolderMetaFile {
+			return nil
+		}
+
+		files[rel] = info.Size()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for rel, size := range files {
+		if strings.HasSuffix(rel, "/entry.md") || rel == "entry.md" {
+			if included[strings.TrimSuffix(rel, "/entry.md")] {
+				stats.Entries++
+				stats.EntriesSize += size
+			}
+
+			continue
+		}
+
+		owner := filepath.ToSlash(filepath.Dir(rel))
+		for owner != "." && !entryDirs[owner] {
+			owner = filepath.ToSlash(filepath.Dir(owner))
+		}
+
+		if included[owner] {
+			stats.Attachments++
+			stats.AttachmentsSize += size
+		}
+	}
+
+	return nil
+}
+
+// commitsByMonth calls the function given with the month, in the format "2006-01", of every commit in the store's git
+// repository. If the store isn't using git, it does nothing.
+func (s *Store) commitsByMonth(f func(month string)) error {
+	if s.repo == nil {
+		return nil
+	}
+
+	iter, err := s.repo.Log(&git.LogOptions{})
+	if err == plumbing.ErrReferenceNotFound {
+		return nil // No commits yet.
+	} else if err != nil {
+		return err
+	}
+
+	return iter.ForEach(func(commit *object.Commit) error {
+		f(commit.Author.When.Format("2006-01"))
+		return nil
+	})
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/albatross-org/go-albatross/entries"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreStats(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	stats, err := store.Stats()
+	if err != nil {
+		t.Fatalf("not expecting error when getting stats: %s", err)
+	}
+
+	Equal(t, 7, stats.Entries, "there should be 7 entries in the test store")
+	Equal(t, 1, stats.Attachments, "there should be 1 attachment in the test store")
+	True(t, stats.AttachmentsSize > 0, "attachments should have a size")
+	Equal(t, 7, stats.Months[len(stats.Months)-1].Cumulative, "cumulative count should include every entry")
+
+	collection, err := store.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error when getting collection: %s", err)
+	}
+
+	journal, err := collection.Filter(entries.FilterPathsMatch("journal"))
+	if err != nil {
+		t.Fatalf("not expecting error when filtering collection: %s", err)
+	}
+
+	stats, err = store.CollectionStats(journal)
+	if err != nil {
+		t.Fatalf("not expecting error when getting stats: %s", err)
+	}
+
+	Equal(t, 3, stats.Entries, "there should be 3 journal entries")
+	Equal(t, 0, stats.Attachments, "journal entries have no attachments")
+
+	undated := entries.NewCollection()
+	err = undated.Add(&entries.Entry{Path: "food/pizza", Title: "Pizza"})
+	if err != nil {
+		t.Fatalf("not expecting error when adding entry: %s", err)
+	}
+
+	stats, err = store.CollectionStats(undated)
+	if err != nil {
+		t.Fatalf("not expecting error when getting stats: %s", err)
+	}
+
+	Equal(t, 1, stats.Undated, "entry without a date should be counted as undated")
+	for _, month := range stats.Months {
+		Zero(t, month.Entries, "undated entries shouldn't be counted in month %s", month.Month)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-git/go-git/v5"
@@ -23,17 +25,54 @@ type Store struct {
 	entriesPath string
 	configPath  string
 
+	collMu     sync.RWMutex // guards coll and entryErrs, which Watch replaces while the store is in use
 	coll       *entries.Collection
+	entryErrs  []error
 	repo       *git.Repository
 	worktree   *git.Worktree
 	disableGit bool
 
+	command string
+
+	historyMu    sync.Mutex // guards lastModified
+	lastModified map[string]time.Time
+
+	recentMu sync.Mutex // guards the file recording recently viewed entries, which the server can update concurrently
+
 	config *viper.Viper
+
+	// transforms are applied to entries before they're parsed, given by "entries.transforms" in the config.
+	transforms []entries.Transform
+
+	registry entries.Registry
 }
 
 // Load returns a new Albatross store representation.
 func Load(path string) (*Store, error) {
-	var s = &Store{Path: path, disableGit: false}
+	s, err := LoadLazy(path)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return nil, err
+	}
+
+	if !encrypted {
+		err = s.load()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// LoadLazy is like Load but doesn't parse the entries until they're first needed, such as by Collection. This is useful
+// when the entries come from somewhere else, like the daemon.
+func LoadLazy(path string) (*Store, error) {
+	var s = &Store{Path: path, disableGit: false, command: strings.Join(os.Args, " ")}
 
 	s.entriesPath = filepath.Join(path, "entries")
 	s.configPath = filepath.Join(path, "config.yaml")
@@ -45,13 +84,18 @@ func Load(path string) (*Store, error) {
 
 	s.config = config
 
+	s.transforms, err = entries.NewTransforms(config.GetStringSlice("entries.transforms"), config.GetStringMapString("entries.emoji"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid entries.transforms in config file %s: %w", s.configPath, err)
+	}
+
 	encrypted, err := s.Encrypted()
 	if err != nil {
 		return nil, err
 	}
 
 	if !encrypted {
-		err = s.load()
+		err = s.loadGit()
 		if err != nil {
 			return nil, err
 		}
@@ -69,19 +113,31 @@ func (s *Store) Collection() (*entries.Collection, error) {
 		return nil, ErrStoreEncrypted{s.Path}
 	}
 
-	if s.coll == nil {
+	s.collMu.RLock()
+	coll := s.coll
+	s.collMu.RUnlock()
+
+	if coll == nil {
 		err = s.load()
 		if err != nil {
 			return nil, err
 		}
+
+		s.collMu.RLock()
+		coll = s.coll
+		s.collMu.RUnlock()
 	}
 
-	return s.coll, nil
+	return coll, nil
 }
 
 // Create creates a new entry in the store. If the store is encrypted, it returns ErrStoreEncrypted.
 // It takes a path relative to the entries folder, such as "food/pizza" and it will create intermediate directories.
-func (s *Store) Create(path, content string) error {
+// Any keys "default-metadata" gives entries at the path which aren't in the content's front matter are added to it.
+func (s *Store) Create(path, content string) (err error) {
+	relPath := path
+	defer func() { s.recordAudit("create", err, relPath) }()
+
 	encrypted, err := s.Encrypted()
 	if err != nil {
 		return err
@@ -89,7 +145,11 @@ func (s *Store) Create(path, content string) error {
 		return ErrStoreEncrypted{Path: s.Path}
 	}
 
-	relPath := path
+	content, err = s.ApplyDefaultMetadata(relPath, content)
+	if err != nil {
+		return err
+	}
+
 	path = filepath.Join(s.entriesPath, path)
 
 	entryPath := filepath.Join(path, "entry.md")
@@ -115,7 +175,7 @@ func (s *Store) Create(path, content string) error {
 		return err
 	}
 
-	err = s.reload()
+	err = s.refresh(relPath)
 	if err != nil {
 		return err
 	}
@@ -124,7 +184,12 @@ func (s *Store) Create(path, content string) error {
 }
 
 // Update updates the given entry. If the store is encrypted, it returns ErrStoreEncrypted.
-func (s *Store) Update(path, content string) error {
+// The entry keeps the line endings and byte order mark it had before, so entries written on Windows stay that way even
+// if the new content uses "\n" line endings.
+func (s *Store) Update(path, content string) (err error) {
+	relPath := path
+	defer func() { s.recordAudit("update", err, relPath) }()
+
 	encrypted, err := s.Encrypted()
 	if err != nil {
 		return err
@@ -132,7 +197,6 @@ func (s *Store) Update(path, content string) error {
 		return ErrStoreEncrypted{Path: s.Path}
 	}
 
-	relPath := path
 	path = filepath.Join(s.entriesPath, path)
 
 	entryPath := filepath.Join(path, "entry.md")
@@ -140,6 +204,13 @@ func (s *Store) Update(path, content string) error {
 		return ErrEntryDoesntExist{path}
 	}
 
+	existing, err := ioutil.ReadFile(entryPath)
+	if err != nil {
+		return err
+	}
+
+	content = entries.DetectEncoding(string(existing)).Apply(content)
+
 	err = ioutil.WriteFile(entryPath, []byte(content), 0644)
 	if err != nil {
 		return err
@@ -150,7 +221,7 @@ func (s *Store) Update(path, content string) error {
 		return err
 	}
 
-	err = s.reload()
+	err = s.refresh(relPath)
 	if err != nil {
 		return err
 	}
@@ -160,7 +231,11 @@ func (s *Store) Update(path, content string) error {
 
 // Attach attaches a file to an entry by copying it into the entry's folder from the location specified. If the store is encrypted, it
 // will return ErrStoreEncrypted.
-func (s *Store) Attach(path, attachmentPath string) error {
+// Files larger than the store's large attachment threshold are replaced by a pointer file, see ResolveAttachment.
+func (s *Store) Attach(path, attachmentPath string) (err error) {
+	relPath := path
+	defer func() { s.recordAudit("attach", err, relPath) }()
+
 	encrypted, err := s.Encrypted()
 	if err != nil {
 		return err
@@ -168,8 +243,28 @@ func (s *Store) Attach(path, attachmentPath string) error {
 		return ErrStoreEncrypted{Path: s.Path}
 	}
 
-	relPath := path
-	path = filepath.Join(s.entriesPath, path)
+	err = s.copyAttachment(relPath, attachmentPath)
+	if err != nil {
+		return err
+	}
+
+	err = s.recordChange(relPath, "Attach %s to %s", attachmentPath, relPath)
+	if err != nil {
+		return err
+	}
+
+	err = s.refresh(relPath)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// copyAttachment copies a file into the folder of the entry at the path given, without committing the change, or
+// stores it as a large attachment if it's larger than the store's threshold.
+func (s *Store) copyAttachment(relPath, attachmentPath string) error {
+	path := filepath.Join(s.entriesPath, relPath)
 
 	entryPath := filepath.Join(path, "entry.md")
 	if !exists(entryPath) {
@@ -186,21 +281,18 @@ func (s *Store) Attach(path, attachmentPath string) error {
 		return fmt.Errorf("cannot attach file %s to %s, file already exists", attachmentPath, attachmentDestinationPath)
 	}
 
-	err = copyFile(attachmentPath, attachmentDestinationPath)
-	if err != nil {
-		fmt.Fprintln(os.Stdout, attachmentPath)
-		fmt.Fprintln(os.Stdout, attachmentDestinationPath)
-		return fmt.Errorf("cannot copy attachment from %s to %s: %w", attachmentPath, attachmentDestinationPath, err)
-	}
-
-	err = s.recordChange(relPath, "Attach %s to %s", attachmentPath, relPath)
-	if err != nil {
-		return err
-	}
-
-	err = s.reload()
-	if err != nil {
-		return err
+	if threshold := s.largeThreshold(); threshold > 0 && stat.Size() > threshold {
+		err = s.attachLarge(attachmentPath, attachmentDestinationPath, filepath.Join(relPath, stat.Name()))
+		if err != nil {
+			return fmt.Errorf("cannot attach large file %s to %s: %w", attachmentPath, relPath, err)
+		}
+	} else {
+		err = copyFile(attachmentPath, attachmentDestinationPath)
+		if err != nil {
+			fmt.Fprintln(os.Stdout, attachmentPath)
+			fmt.Fprintln(os.Stdout, attachmentDestinationPath)
+			return fmt.Errorf("cannot copy attachment from %s to %s: %w", attachmentPath, attachmentDestinationPath, err)
+		}
 	}
 
 	return nil
@@ -212,7 +304,10 @@ func (s *Store) Attach(path, attachmentPath string) error {
 // If the entry given has subdirectories of entries itself, those subdirectories will be left intact.
 // BUG(ollybritton): This code won't delete attachments if they're in a folder next to the entry. The code needs to recursively search
 // all subdirectories to determine if they're folders or not.
-func (s *Store) Delete(path string) error {
+func (s *Store) Delete(path string) (err error) {
+	relPath := path
+	defer func() { s.recordAudit("delete", err, relPath) }()
+
 	encrypted, err := s.Encrypted()
 	if err != nil {
 		return err
@@ -220,7 +315,6 @@ func (s *Store) Delete(path string) error {
 		return ErrStoreEncrypted{Path: s.Path}
 	}
 
-	relPath := path
 	path = filepath.Join(s.entriesPath, path)
 
 	entryPath := filepath.Join(path, "entry.md")
@@ -260,7 +354,7 @@ func (s *Store) Delete(path string) error {
 		return err
 	}
 
-	err = s.reload()
+	err = s.refresh(relPath)
 	if err != nil {
 		return err
 	}
@@ -284,7 +378,7 @@ func (s *Store) DisableGit() {
 
 // load loads the Collection and in-memory git repository contained within the Store.
 func (s *Store) load() error {
-	collection, entryErrs, err := entries.DirGraph(s.entriesPath)
+	collection, entryErrs, err := entries.DirGraphWithLimits(s.entriesPath, s.limits())
 	if err != nil {
 		return err
 	}
@@ -293,7 +387,13 @@ func (s *Store) load() error {
 		logrus.Warn(entryErr)
 	}
 
+	s.collMu.Lock()
+	if s.registry != nil {
+		collection.SetRegistry(s.registry)
+	}
 	s.coll = collection
+	s.entryErrs = entryErrs
+	s.collMu.Unlock()
 
 	err = s.loadGit()
 	if err != nil {
@@ -303,6 +403,16 @@ func (s *Store) load() error {
 	return nil
 }
 
+// limits returns the limits on the size of entries set in the config, along with the transforms applied to them. A limit
+// of zero disables it.
+func (s *Store) limits() entries.Limits {
+	return entries.Limits{
+		MaxSize:     int64(s.config.GetSizeInBytes("entries.max-size")),
+		MaxContents: int(s.config.GetSizeInBytes("entries.max-contents")),
+		Transforms:  s.transforms,
+	}
+}
+
 // loadGit loads git
 func (s *Store) loadGit() error {
 	repo, err := git.PlainOpen(s.entriesPath)
@@ -324,9 +434,17 @@ func (s *Store) loadGit() error {
 
 // unload unloads the Collection contained within the Store.
 func (s *Store) unload() {
+	s.collMu.Lock()
 	s.coll = nil
+	s.entryErrs = nil
+	s.collMu.Unlock()
+
 	s.repo = nil
 	s.worktree = nil
+
+	s.historyMu.Lock()
+	s.lastModified = nil
+	s.historyMu.Unlock()
 }
 
 // reload is an unload followed by a load. It means changes made are reflected in the store's internal collection.
@@ -346,13 +464,21 @@ func (s *Store) recordChange(path, message string, a ...interface{}) error {
 		return nil // If git has been disabled, also don't do anything
 	}
 
-	_, err := s.worktree.Add(path)
-	if err != nil {
-		return err
+	return s.commitPaths([]string{path}, s.commitMessage(path, fmt.Sprintf(message, a...)))
+}
+
+// commitPaths stages the changes to the files or folders at the paths given, relative to the entries folder, and commits
+// them together with the message given. Unlike recordChange, it doesn't check whether git is being used.
+func (s *Store) commitPaths(paths []string, message string) error {
+	for _, path := range paths {
+		err := s.stage(path)
+		if err != nil {
+			return err
+		}
 	}
 
-	_, err = s.worktree.Commit(
-		fmt.Sprintf("(go-albatross) %s", fmt.Sprintf(message, a...)),
+	_, err := s.worktree.Commit(
+		message,
 		&git.CommitOptions{
 			Author: &object.Signature{
 				Name: "go-albatross",
@@ -366,3 +492,30 @@ func (s *Store) recordChange(path, message string, a ...interface{}) error {
 
 	return nil
 }
+
+// stage adds the changes to the file or folder at path, relative to the entries folder, to the git index. Unlike
+// worktree.Add, this works for folders which have been removed.
+func (s *Store) stage(path string) error {
+	if exists(filepath.Join(s.entriesPath, path)) {
+		_, err := s.worktree.Add(path)
+		return err
+	}
+
+	idx, err := s.repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+
+	prefix := filepath.ToSlash(path) + "/"
+
+	for _, entry := range idx.Entries {
+		if entry.Name == filepath.ToSlash(path) || strings.HasPrefix(entry.Name, prefix) {
+			_, err = s.worktree.Add(entry.Name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -11,7 +11,7 @@ import (
 	. "github.com/stretchr/testify/assert"
 )
 
-func tempTestDir(t *testing.T) (path string, cleanup func()) {
+func tempTestDir(t testing.TB) (path string, cleanup func()) {
 	t.Helper()
 
 	tmpDir, err := ioutil.TempDir("", "albatross-core-test")
@@ -117,3 +117,42 @@ Actually, I've changed my mind about truffles now.`)
 		t.Fatalf("not expecting error when deleting truffles sub entry: %s", err)
 	}
 }
+
+func TestStoreTransforms(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+	content := "---\ntitle: Migrated\n---\n\nFrom Obsidian :tada: %%private note%%and :shipit:."
+
+	err := os.MkdirAll(filepath.Join(storePath, "entries", "migrated"), 0755)
+	if err != nil {
+		t.Fatalf("not expecting error creating entry folder: %s", err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(storePath, "entries", "migrated", "entry.md"), []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("not expecting error writing entry: %s", err)
+	}
+
+	store := loadWithConfig(t, storePath, "entries:\n  transforms: [obsidian-comments, emoji]\n  emoji:\n    shipit: \"🐿️\"\n")
+
+	collection, err := store.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error getting collection: %s", err)
+	}
+
+	entry := collection.Get("migrated")
+	if NotNil(t, entry) {
+		Equal(t, "From Obsidian 🎉 and 🐿️.", entry.Contents)
+		Equal(t, content, entry.OriginalContents, "expecting the file itself not to be transformed")
+	}
+
+	err = ioutil.WriteFile(filepath.Join(storePath, "config.yaml"), []byte("entries:\n  transforms: [roam-blocks]\n"), 0644)
+	if err != nil {
+		t.Fatalf("not expecting error writing config file: %s", err)
+	}
+
+	_, err = Load(storePath)
+	NotNil(t, err, "expecting error for an unknown transform")
+}
@@ -0,0 +1,25 @@
+package core
+
+// TemplateStore is what templates see as .Store, such as entry templates, shortcodes and the template action. It holds
+// values set for the whole store so that they don't have to be written out in every template.
+type TemplateStore struct {
+	// Vars are the values from the "variables" section of the config:
+	//
+	//	variables:
+	//	  author: Jane Doe
+	//	  school-year: 12
+	//
+	// Like the rest of the config, keys are case-insensitive and are given in lower case, so the second value above
+	// is used in a template as <(index .Store.Vars "school-year")>.
+	Vars map[string]interface{}
+}
+
+// Variables returns the values from the "variables" section of the config. If there isn't one, the map is empty.
+func (s *Store) Variables() map[string]interface{} {
+	return s.config.GetStringMap("variables")
+}
+
+// TemplateStore returns what templates see as .Store.
+func (s *Store) TemplateStore() TemplateStore {
+	return TemplateStore{Vars: s.Variables()}
+}
@@ -0,0 +1,26 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreVariables(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	storePath := filepath.Join(dir, "testdata", "stores", "testing.albatross")
+
+	store, err := Load(storePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	Empty(t, store.Variables(), "expecting no variables when the config doesn't set any")
+
+	store = loadWithConfig(t, storePath, "variables:\n  author: Jane Doe\n  School-Year: 12\n")
+
+	Equal(t, TemplateStore{Vars: map[string]interface{}{"author": "Jane Doe", "school-year": 12}}, store.TemplateStore())
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// watchDebounce is how long Watch waits after a change for more changes before updating the collection, since editors
+// often write a file in several steps.
+const watchDebounce = 100 * time.Millisecond
+
+// Watch watches the entries folder for changes made outside of the store, such as by an editor, and updates the
+// collection as they happen. Only the entries which changed are parsed again rather than the whole store. After each
+// batch of changes, onChange is called with the paths of the entries that were updated, added or removed. It can be nil.
+//
+// Watch blocks until the context is cancelled or watching fails. If the store is encrypted, it returns
+// ErrStoreEncrypted.
+func (s *Store) Watch(ctx context.Context, onChange func(paths []string)) error {
+	_, err := s.Collection()
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	// fsnotify doesn't watch folders recursively, so every folder is watched, and new ones as they're created.
+	err = watchTree(watcher, s.entriesPath)
+	if err != nil {
+		return err
+	}
+
+	changed := map[string]bool{}
+	var flush <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			return err
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			path, tree := s.watchedPath(event.Name)
+			if path == "" {
+				continue
+			}
+
+			if tree && event.Op&fsnotify.Create != 0 {
+				err = watchTree(watcher, event.Name)
+				if err != nil && !os.IsNotExist(err) {
+					return err
+				}
+			}
+
+			changed[path] = changed[path] || tree
+			flush = time.After(watchDebounce)
+
+		case <-flush:
+			paths, err := s.updateEntries(changed)
+			if err != nil {
+				return err
+			}
+
+			changed = map[string]bool{}
+			flush = nil
+
+			if onChange != nil && len(paths) != 0 {
+				onChange(paths)
+			}
+		}
+	}
+}
+
+// watchedPath converts the name of a file or folder from a watch event into the path of the entry it affects, relative to
+// the entries folder. tree is true if the name isn't an entry.md or entries.FolderMetaFile file, such as a folder which
+// was created or removed, so that everything under it needs checking. The path is blank for changes which don't affect
+// entries, like attachments or changes inside .git.
+func (s *Store) watchedPath(name string) (path string, tree bool) {
+	rel, err := filepath.Rel(s.entriesPath, name)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+
+	rel = filepath.ToSlash(rel)
+
+	for _, part := range strings.Split(rel, "/") {
+		if strings.HasPrefix(part, ".") {
+			return "", false
+		}
+	}
+
+	if filepath.Base(rel) == "entry.md" || filepath.Base(rel) == entries.FolderMetaFile {
+		return filepath.ToSlash(filepath.Dir(rel)), false
+	}
+
+	// Attachments and other files in entry folders don't change the entry. Folders, and things which have just been
+	// removed so can't be checked, might contain entries.
+	info, err := os.Stat(name)
+	if err == nil && !info.IsDir() {
+		return "", false
+	}
+
+	return rel, true
+}
+
+// watchTree adds the folder and every folder inside it to the watcher, skipping hidden folders like .git.
+func watchTree(watcher *fsnotify.Watcher, root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		if strings.HasPrefix(info.Name(), ".") && path != root {
+			return filepath.SkipDir
+		}
+
+		return watcher.Add(path)
+	})
+}
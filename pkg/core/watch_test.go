@@ -0,0 +1,160 @@
+package core
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStoreUpdateEntries(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	before, err := store.Collection()
+	Nil(t, err)
+	count := before.Len()
+
+	err = ioutil.WriteFile(filepath.Join(store.entriesPath, "food", "pizza", "entry.md"), []byte("---\ntitle: \"Better Pizza\"\n---\n\nUpdated.\n"), 0644)
+	Nil(t, err)
+
+	err = os.MkdirAll(filepath.Join(store.entriesPath, "drinks", "tea"), 0755)
+	Nil(t, err)
+	err = ioutil.WriteFile(filepath.Join(store.entriesPath, "drinks", "tea", "entry.md"), []byte("---\ntitle: \"Tea\"\n---\n\nHot.\n"), 0644)
+	Nil(t, err)
+
+	paths, err := store.updateEntries(map[string]bool{"food/pizza": false, "drinks": true})
+	Nil(t, err)
+	Equal(t, []string{"drinks/tea", "food/pizza"}, paths)
+
+	after, err := store.Collection()
+	Nil(t, err)
+	Equal(t, count+1, after.Len())
+	Equal(t, count, before.Len(), "expecting the old collection not to be changed")
+
+	pizza := after.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: "food/pizza"})
+	if NotNil(t, pizza) {
+		Equal(t, "Better Pizza", pizza.Title)
+	}
+
+	NotNil(t, after.ResolveLink(entries.Link{Type: entries.LinkTitleNoName, Title: "Better Pizza"}), "expecting title lookups to use the new title")
+
+	err = os.RemoveAll(filepath.Join(store.entriesPath, "food"))
+	Nil(t, err)
+
+	paths, err = store.updateEntries(map[string]bool{"food": true})
+	Nil(t, err)
+	Contains(t, paths, "food/pizza")
+
+	after, err = store.Collection()
+	Nil(t, err)
+	Nil(t, after.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: "food/pizza"}), "expecting removed entries to be removed")
+}
+
+func TestStoreUpdateFolders(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	collection, err := store.Collection()
+	Nil(t, err)
+	Equal(t, "Food & Drink", collection.FolderTitle("food"))
+
+	path, tree := store.watchedPath(filepath.Join(store.entriesPath, "food", entries.FolderMetaFile))
+	Equal(t, "food", path)
+	False(t, tree, "expecting changes to a folder's description to only affect that folder")
+
+	err = ioutil.WriteFile(filepath.Join(store.entriesPath, "food", entries.FolderMetaFile), []byte("title: Snacks\n"), 0644)
+	Nil(t, err)
+
+	err = ioutil.WriteFile(filepath.Join(store.entriesPath, "moods", entries.FolderMetaFile), []byte("title: [Moods\n"), 0644)
+	Nil(t, err)
+
+	paths, err := store.updateEntries(map[string]bool{"food": false, "moods": true})
+	Nil(t, err)
+	Contains(t, paths, "food")
+
+	collection, err = store.Collection()
+	Nil(t, err)
+	Equal(t, "Snacks", collection.FolderTitle("food"))
+	Nil(t, collection.Folder("moods"))
+
+	issues, err := store.Lint()
+	Nil(t, err)
+	if Len(t, issues, 1, "expecting the invalid folder description to be reported") {
+		Equal(t, "moods", issues[0].Path)
+		Equal(t, LintParseFailed, issues[0].Check)
+	}
+
+	err = os.Remove(filepath.Join(store.entriesPath, "food", entries.FolderMetaFile))
+	Nil(t, err)
+
+	_, err = store.updateEntries(map[string]bool{"food": false})
+	Nil(t, err)
+
+	collection, err = store.Collection()
+	Nil(t, err)
+	Equal(t, "food", collection.FolderTitle("food"), "expecting removed descriptions to be removed")
+}
+
+func TestStoreWatch(t *testing.T) {
+	dir, cleanup := tempTestDir(t)
+	defer cleanup()
+
+	store, err := Load(filepath.Join(dir, "testdata", "stores", "testing.albatross"))
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan []string, 10)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- store.Watch(ctx, func(paths []string) { changes <- paths })
+	}()
+
+	// Give the watcher time to start watching before making changes.
+	time.Sleep(100 * time.Millisecond)
+
+	err = os.MkdirAll(filepath.Join(store.entriesPath, "drinks", "coffee"), 0755)
+	Nil(t, err)
+	err = ioutil.WriteFile(filepath.Join(store.entriesPath, "drinks", "coffee", "entry.md"), []byte("---\ntitle: \"Coffee\"\n---\n\nStrong.\n"), 0644)
+	Nil(t, err)
+
+	deadline := time.After(5 * time.Second)
+
+	for {
+		collection, err := store.Collection()
+		Nil(t, err)
+
+		if collection.ResolveLink(entries.Link{Type: entries.LinkPathNoName, Path: "drinks/coffee"}) != nil {
+			break
+		}
+
+		select {
+		case <-changes:
+		case <-deadline:
+			t.Fatal("expecting new entry to be picked up by Watch")
+		}
+	}
+
+	cancel()
+	Nil(t, <-done)
+}
@@ -0,0 +1,105 @@
+// Package journal implements the conventions for keeping a journal in an Albatross store: one entry per day, at a path
+// made from the date such as "journal/2021-02-17". It finds the entry for a day, creates it, works out writing streaks
+// and the days which were missed, and picks writing prompts. It's what 'albatross journal' is built on.
+//
+// Days are given as times, but only their year, month and day are used. The days returned by this package are at
+// midnight UTC so that adding a day to one is never thrown off by daylight saving.
+package journal
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+)
+
+// layoutCheck is the date used to check that a layout contains the year, month and day.
+var layoutCheck = time.Date(2021, 2, 17, 0, 0, 0, 0, time.UTC)
+
+// Journal is a journal kept in a store, with an entry for each day at a path given by a Go time layout.
+type Journal struct {
+	store  albatross.ReadWriter
+	layout string
+}
+
+// New returns the journal in the store given, where the path of each day's entry is the day formatted using the layout,
+// such as "journal/2006-01-02" or "journal/2006/01/02". It returns an error if the layout doesn't contain the year, month
+// and day, since then paths can't be turned back into days.
+func New(store albatross.ReadWriter, layout string) (*Journal, error) {
+	parsed, err := time.Parse(layout, layoutCheck.Format(layout))
+	if err != nil || !parsed.Equal(layoutCheck) {
+		return nil, fmt.Errorf("journal layout %q needs the year, month and day, like %q", layout, albatross.DefaultJournalLayout)
+	}
+
+	return &Journal{store: store, layout: layout}, nil
+}
+
+// Day returns the day given as midnight UTC, dropping the time and location.
+func Day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Path returns the path of the entry for the day given.
+func (j *Journal) Path(day time.Time) string {
+	return Day(day).Format(j.layout)
+}
+
+// DayOf returns the day the entry at the path given is for, or false if it isn't a journal entry.
+func (j *Journal) DayOf(path string) (time.Time, bool) {
+	day, err := time.Parse(j.layout, path)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return day, true
+}
+
+// Entry returns the entry for the day given, or nil if there isn't one.
+func (j *Journal) Entry(day time.Time) (*entries.Entry, error) {
+	collection, err := j.store.Collection()
+	if err != nil {
+		return nil, err
+	}
+
+	return collection.Get(j.Path(day)), nil
+}
+
+// Create creates the entry for the day given with the content given, returning its path. If there's already an entry
+// for the day, it returns albatross.ErrEntryAlreadyExists.
+func (j *Journal) Create(day time.Time, content string) (string, error) {
+	path := j.Path(day)
+
+	err := j.store.Create(path, content)
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// Days returns the days which have an entry, sorted from earliest to latest.
+func (j *Journal) Days() ([]time.Time, error) {
+	collection, err := j.store.Collection()
+	if err != nil {
+		return nil, err
+	}
+
+	days := []time.Time{}
+
+	err = collection.ForEach(nil, func(entry *entries.Entry) error {
+		if day, ok := j.DayOf(entry.Path); ok {
+			days = append(days, day)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	return days, nil
+}
@@ -0,0 +1,120 @@
+package journal
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testStore is a store which only keeps entries in a collection.
+type testStore struct {
+	collection *entries.Collection
+}
+
+func (s *testStore) Collection() (*entries.Collection, error)    { return s.collection, nil }
+func (s *testStore) Attachments(path string) ([]string, error)   { return nil, nil }
+func (s *testStore) LastModified(path string) (time.Time, error) { return time.Time{}, nil }
+func (s *testStore) Encrypted() (bool, error)                    { return false, nil }
+func (s *testStore) Update(path, content string) error           { return nil }
+func (s *testStore) Attach(path, attachmentPath string) error    { return nil }
+func (s *testStore) Delete(path string) error                    { return nil }
+func (s *testStore) Create(path, content string) error {
+	if s.collection.Get(path) != nil {
+		return albatross.ErrEntryAlreadyExists{Path: path}
+	}
+
+	return s.collection.Add(&entries.Entry{Path: path, Contents: content})
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&testStore{entries.NewCollection()}, "journal/2006/01")
+	assert.NotNil(t, err, "expecting a layout without the day to be rejected")
+
+	j, err := New(&testStore{entries.NewCollection()}, "journal/2006/01/02")
+	assert.Nil(t, err)
+
+	assert.Equal(t, "journal/2021/02/17", j.Path(time.Date(2021, 2, 17, 23, 30, 0, 0, time.Local)))
+
+	day, ok := j.DayOf("journal/2021/02/17")
+	assert.True(t, ok)
+	assert.Equal(t, date(2021, 2, 17), day)
+
+	_, ok = j.DayOf("journal/2021/02/17/photos")
+	assert.False(t, ok, "expecting paths which don't match the layout not to be journal entries")
+}
+
+func TestJournalDays(t *testing.T) {
+	store := &testStore{entries.NewCollection()}
+
+	j, err := New(store, albatross.DefaultJournalLayout)
+	assert.Nil(t, err)
+
+	for _, day := range []time.Time{date(2021, 2, 17), date(2021, 2, 15)} {
+		_, err = j.Create(day, "Dear diary.")
+		assert.Nil(t, err)
+	}
+
+	_, err = j.Create(date(2021, 2, 17), "Again.")
+	assert.IsType(t, albatross.ErrEntryAlreadyExists{}, err)
+
+	assert.Nil(t, store.collection.Add(&entries.Entry{Path: "food/pizza"}))
+
+	days, err := j.Days()
+	assert.Nil(t, err)
+	assert.Equal(t, []time.Time{date(2021, 2, 15), date(2021, 2, 17)}, days)
+
+	entry, err := j.Entry(date(2021, 2, 17))
+	assert.Nil(t, err)
+	assert.Equal(t, "journal/2021-02-17", entry.Path)
+
+	entry, err = j.Entry(date(2021, 2, 16))
+	assert.Nil(t, err)
+	assert.Nil(t, entry)
+}
+
+func TestFindStreaks(t *testing.T) {
+	days := []time.Time{
+		date(2021, 1, 1), date(2021, 1, 2), date(2021, 1, 3),
+		date(2021, 1, 10),
+		date(2021, 2, 15), date(2021, 2, 16), date(2021, 2, 16),
+	}
+
+	streaks := FindStreaks(days, date(2021, 2, 17))
+	assert.Equal(t, Streak{Start: date(2021, 2, 15), End: date(2021, 2, 16), Days: 2}, streaks.Current, "expecting a streak ending yesterday to still be current")
+	assert.Equal(t, Streak{Start: date(2021, 1, 1), End: date(2021, 1, 3), Days: 3}, streaks.Longest)
+	assert.Equal(t, 6, streaks.Total, "expecting days with more than one entry to be counted once")
+
+	streaks = FindStreaks(days, date(2021, 2, 18))
+	assert.Equal(t, 0, streaks.Current.Days)
+
+	assert.Equal(t, Streaks{}, FindStreaks(nil, date(2021, 2, 18)))
+}
+
+func TestGaps(t *testing.T) {
+	days := []time.Time{date(2021, 1, 1), date(2021, 1, 4), date(2021, 1, 6)}
+
+	assert.Equal(t, []Gap{
+		{Start: date(2021, 1, 2), End: date(2021, 1, 3), Days: 2},
+		{Start: date(2021, 1, 5), End: date(2021, 1, 5), Days: 1},
+		{Start: date(2021, 1, 7), End: date(2021, 1, 8), Days: 2},
+	}, Gaps(days, date(2021, 1, 1), date(2021, 1, 8)))
+
+	assert.Equal(t, []Gap{}, Gaps(days, date(2021, 1, 4), date(2021, 1, 4)))
+}
+
+func TestPrompts(t *testing.T) {
+	prompts := ParsePrompts("# Prompts\nWhat surprised you today?\n\n  What are you looking forward to?  \n")
+	assert.Equal(t, []string{"What surprised you today?", "What are you looking forward to?"}, prompts)
+
+	assert.Contains(t, prompts, RandomPrompt(prompts, rand.New(rand.NewSource(1))))
+	assert.Equal(t, "", RandomPrompt(nil, rand.New(rand.NewSource(1))))
+}
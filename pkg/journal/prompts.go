@@ -0,0 +1,44 @@
+package journal
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"strings"
+)
+
+// ReadPrompts reads writing prompts from the file at the path given, which has one prompt on each line. Blank lines and
+// lines starting with "#" are skipped.
+func ReadPrompts(path string) ([]string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePrompts(string(content)), nil
+}
+
+// ParsePrompts returns the writing prompts in the content given, one on each line. Blank lines and lines starting with
+// "#" are skipped.
+func ParsePrompts(content string) []string {
+	prompts := []string{}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		prompts = append(prompts, line)
+	}
+
+	return prompts
+}
+
+// RandomPrompt picks one of the prompts at random using the source given, or returns "" if there aren't any.
+func RandomPrompt(prompts []string, rnd *rand.Rand) string {
+	if len(prompts) == 0 {
+		return ""
+	}
+
+	return prompts[rnd.Intn(len(prompts))]
+}
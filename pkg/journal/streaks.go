@@ -0,0 +1,99 @@
+package journal
+
+import "time"
+
+// Streak is a run of consecutive days which all have an entry.
+type Streak struct {
+	// Start and End are the first and last days of the streak.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// Days is the number of days in the streak, or 0 if there isn't one.
+	Days int `json:"days"`
+}
+
+// Streaks are the current and longest streaks in a journal, returned by FindStreaks.
+type Streaks struct {
+	// Current is the streak which ends today. If there's no entry for today yet, it's the streak which ends yesterday,
+	// since it can still be kept going.
+	Current Streak `json:"current"`
+
+	// Longest is the longest streak. If there's more than one, it's the latest.
+	Longest Streak `json:"longest"`
+
+	// Total is the number of days with an entry.
+	Total int `json:"total"`
+}
+
+// FindStreaks works out the current and longest streaks from the days which have an entry, such as from Journal.Days, as
+// of the day given as today. Days can be in any order and can repeat.
+func FindStreaks(days []time.Time, today time.Time) Streaks {
+	written := map[time.Time]bool{}
+	for _, day := range days {
+		written[Day(day)] = true
+	}
+
+	streaks := Streaks{Total: len(written)}
+
+	for day := range written {
+		// Only count from the first day of each streak.
+		if written[day.AddDate(0, 0, -1)] {
+			continue
+		}
+
+		streak := Streak{Start: day, End: day, Days: 1}
+		for written[streak.End.AddDate(0, 0, 1)] {
+			streak.End = streak.End.AddDate(0, 0, 1)
+			streak.Days++
+		}
+
+		if streak.Days > streaks.Longest.Days || (streak.Days == streaks.Longest.Days && streak.End.After(streaks.Longest.End)) {
+			streaks.Longest = streak
+		}
+
+		today := Day(today)
+		if streak.End.Equal(today) || streak.End.Equal(today.AddDate(0, 0, -1)) {
+			streaks.Current = streak
+		}
+	}
+
+	return streaks
+}
+
+// Gap is a run of consecutive days without an entry.
+type Gap struct {
+	// Start and End are the first and last days missed.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// Days is the number of days missed.
+	Days int `json:"days"`
+}
+
+// Gaps returns the runs of days between from and to, including both, which don't have an entry, from earliest to latest.
+func Gaps(days []time.Time, from, to time.Time) []Gap {
+	written := map[time.Time]bool{}
+	for _, day := range days {
+		written[Day(day)] = true
+	}
+
+	gaps := []Gap{}
+
+	var gap *Gap
+	for day := Day(from); !day.After(Day(to)); day = day.AddDate(0, 0, 1) {
+		if written[day] {
+			gap = nil
+			continue
+		}
+
+		if gap == nil {
+			gaps = append(gaps, Gap{Start: day})
+			gap = &gaps[len(gaps)-1]
+		}
+
+		gap.End = day
+		gap.Days++
+	}
+
+	return gaps
+}
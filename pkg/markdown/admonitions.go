@@ -0,0 +1,242 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Admonitions are blocks which call something out, like a note or a warning. They can be written like in
+// Python-Markdown, with the contents indented:
+//
+//	!!! warning "Be careful"
+//	    Don't touch the hot pan.
+//
+// Or like callouts in Obsidian and GitHub, as a blockquote:
+//
+//	> [!WARNING] Be careful
+//	> Don't touch the hot pan.
+//
+// The title is optional and defaults to the type of admonition, like "Warning".
+
+var (
+	// reAdmonition matches the first line of a Python-Markdown admonition. Group 1 is the type and group 2 is the title.
+	reAdmonition = regexp.MustCompile(`^!!!\s+([\w-]+)(?:\s+"(.*)")?\s*$`)
+
+	// reCallout matches the first line of a callout. Group 1 is the type and group 2 is the title.
+	reCallout = regexp.MustCompile(`^\[!([\w-]+)\][-+]?(?:[ \t]+(.*?))?\s*$`)
+)
+
+// KindAdmonition is the ast.NodeKind for Admonition nodes.
+var KindAdmonition = ast.NewNodeKind("Admonition")
+
+// Admonition is a block calling something out, like a note or a warning. Its children are the contents.
+type Admonition struct {
+	ast.BaseBlock
+
+	// AdmonitionType is the type of admonition in lower case, such as "note" or "warning".
+	AdmonitionType string
+
+	// Title is the title of the admonition, which is the type in title case unless one was given.
+	Title string
+}
+
+// NewAdmonition returns a new Admonition of the given type, using the default title if title is empty.
+func NewAdmonition(typ, title string) *Admonition {
+	typ = strings.ToLower(typ)
+
+	if title == "" {
+		title = strings.ToUpper(typ[:1]) + typ[1:]
+	}
+
+	return &Admonition{AdmonitionType: typ, Title: title}
+}
+
+// Kind implements ast.Node.Kind.
+func (n *Admonition) Kind() ast.NodeKind {
+	return KindAdmonition
+}
+
+// Dump implements ast.Node.Dump.
+func (n *Admonition) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"AdmonitionType": n.AdmonitionType, "Title": n.Title}, nil)
+}
+
+// admonitionParser parses Python-Markdown admonitions.
+type admonitionParser struct{}
+
+// Trigger implements parser.BlockParser.Trigger.
+func (p *admonitionParser) Trigger() []byte {
+	return []byte{'!'}
+}
+
+// Open implements parser.BlockParser.Open.
+func (p *admonitionParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+
+	match := reAdmonition.FindSubmatch(line[pos:])
+	if match == nil {
+		return nil, parser.NoChildren
+	}
+
+	reader.Advance(segment.Len() - util.TrimRightSpaceLength(line))
+
+	return NewAdmonition(string(match[1]), string(match[2])), parser.HasChildren
+}
+
+// Continue implements parser.BlockParser.Continue.
+func (p *admonitionParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, _ := reader.PeekLine()
+	if util.IsBlank(line) {
+		return parser.Continue | parser.HasChildren
+	}
+
+	indent, _ := util.IndentWidth(line, reader.LineOffset())
+	if indent < 4 {
+		return parser.Close
+	}
+
+	pos, padding := util.IndentPosition(line, reader.LineOffset(), 4)
+	reader.AdvanceAndSetPadding(pos, padding)
+
+	return parser.Continue | parser.HasChildren
+}
+
+// Close implements parser.BlockParser.Close.
+func (p *admonitionParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser.CanInterruptParagraph.
+func (p *admonitionParser) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.CanAcceptIndentedLine.
+func (p *admonitionParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// calloutTransformer replaces blockquotes starting with a line like "[!NOTE]" with Admonition nodes.
+type calloutTransformer struct{}
+
+// Transform implements parser.ASTTransformer.Transform.
+func (t *calloutTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	quotes := []*ast.Blockquote{}
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if quote, ok := n.(*ast.Blockquote); ok && entering {
+			quotes = append(quotes, quote)
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	for _, quote := range quotes {
+		paragraph, ok := quote.FirstChild().(*ast.Paragraph)
+		if !ok || paragraph.Lines().Len() == 0 {
+			continue
+		}
+
+		first := paragraph.Lines().At(0)
+		match := reCallout.FindSubmatch(first.Value(source))
+		if match == nil {
+			continue
+		}
+
+		admonition := NewAdmonition(string(match[1]), string(match[2]))
+
+		// Remove the first line from the paragraph, and the paragraph itself if that was all it had.
+		for child := paragraph.FirstChild(); child != nil; {
+			if inlineStart(child) >= first.Stop {
+				break
+			}
+
+			next := child.NextSibling()
+			paragraph.RemoveChild(paragraph, child)
+
+			if t, ok := child.(*ast.Text); ok && (t.SoftLineBreak() || t.HardLineBreak()) {
+				break
+			}
+
+			child = next
+		}
+
+		if paragraph.ChildCount() == 0 {
+			quote.RemoveChild(quote, paragraph)
+		}
+
+		for child := quote.FirstChild(); child != nil; {
+			next := child.NextSibling()
+			admonition.AppendChild(admonition, child)
+			child = next
+		}
+
+		quote.Parent().ReplaceChild(quote.Parent(), quote, admonition)
+	}
+}
+
+// inlineStart returns where an inline node starts in the source, or -1 if it can't be found.
+func inlineStart(n ast.Node) int {
+	if t, ok := n.(*ast.Text); ok {
+		return t.Segment.Start
+	}
+
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if start := inlineStart(child); start != -1 {
+			return start
+		}
+	}
+
+	return -1
+}
+
+// admonitionHTMLRenderer renders Admonition nodes as HTML.
+type admonitionHTMLRenderer struct{}
+
+// RegisterFuncs implements renderer.NodeRenderer.RegisterFuncs.
+func (r *admonitionHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindAdmonition, r.renderAdmonition)
+}
+
+func (r *admonitionHTMLRenderer) renderAdmonition(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		_, _ = w.WriteString("</div>\n")
+		return ast.WalkContinue, nil
+	}
+
+	node := n.(*Admonition)
+
+	_, _ = w.WriteString(`<div class="admonition ` + string(util.EscapeHTML([]byte(node.AdmonitionType))) + `">` + "\n")
+	_, _ = w.WriteString(`<p class="admonition-title">`)
+	_, _ = w.Write(util.EscapeHTML(bytes.TrimSpace([]byte(node.Title))))
+	_, _ = w.WriteString("</p>\n")
+
+	return ast.WalkContinue, nil
+}
+
+// admonitionExtension is a goldmark.Extender which adds admonitions.
+type admonitionExtension struct{}
+
+// Admonitions is a goldmark extension which adds admonitions and callouts.
+var Admonitions = &admonitionExtension{}
+
+// Extend implements goldmark.Extender.Extend.
+func (e *admonitionExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(util.Prioritized(&admonitionParser{}, 750)),
+		parser.WithASTTransformers(util.Prioritized(&calloutTransformer{}, 100)),
+	)
+
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&admonitionHTMLRenderer{}, 500)))
+}
@@ -0,0 +1,45 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdmonitions(t *testing.T) {
+	md := New()
+
+	tcs := []struct {
+		name, in, out string
+	}{
+		{
+			"python-markdown",
+			"!!! note\n    Some *notes*.\n\n    More notes.\n\nAfter",
+			"<div class=\"admonition note\">\n<p class=\"admonition-title\">Note</p>\n<p>Some <em>notes</em>.</p>\n<p>More notes.</p>\n</div>\n<p>After</p>\n",
+		},
+		{
+			"python-markdown title",
+			"!!! warning \"Hot pan\"\n    Do not touch it.",
+			"<div class=\"admonition warning\">\n<p class=\"admonition-title\">Hot pan</p>\n<p>Do not touch it.</p>\n</div>\n",
+		},
+		{
+			"callout",
+			"> [!WARNING]\n> Never touch the *hot* pan.",
+			"<div class=\"admonition warning\">\n<p class=\"admonition-title\">Warning</p>\n<p>Never touch the <em>hot</em> pan.</p>\n</div>\n",
+		},
+		{
+			"callout title",
+			"> [!tip]- Cooking tip\n>\n> Use oil.",
+			"<div class=\"admonition tip\">\n<p class=\"admonition-title\">Cooking tip</p>\n<p>Use oil.</p>\n</div>\n",
+		},
+		{
+			"blockquote",
+			"> Just a quote.",
+			"<blockquote>\n<p>Just a quote.</p>\n</blockquote>\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		assert.Equal(t, tc.out, render(t, md, tc.in), tc.name)
+	}
+}
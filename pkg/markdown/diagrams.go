@@ -0,0 +1,324 @@
+package markdown
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Diagrams are written in entries as code blocks in a diagram language, like:
+//
+//	```mermaid
+//	graph LR
+//	    A --> B
+//	```
+//
+// The Diagrams extension renders these to SVG using an external program, such as mermaid-cli's "mmdc" or "plantuml".
+// If the program isn't installed or fails, the diagram is left as a code block so nothing is lost.
+
+// KindDiagram is the ast.NodeKind for Diagram nodes.
+var KindDiagram = ast.NewNodeKind("Diagram")
+
+// DefaultDiagramCommands are the commands used to render each diagram language when no others are given.
+var DefaultDiagramCommands = map[string]string{
+	"mermaid":  "mmdc --quiet --input {input} --output {output}",
+	"plantuml": "plantuml -tsvg -pipe",
+}
+
+// Diagram is a code block containing a diagram, like "```mermaid". Its lines are the source of the diagram.
+type Diagram struct {
+	ast.BaseBlock
+
+	// Language is the language the diagram is written in, such as "mermaid" or "plantuml".
+	Language string
+}
+
+// Kind implements ast.Node.Kind.
+func (n *Diagram) Kind() ast.NodeKind {
+	return KindDiagram
+}
+
+// IsRaw implements ast.Node.IsRaw.
+func (n *Diagram) IsRaw() bool {
+	return true
+}
+
+// Dump implements ast.Node.Dump.
+func (n *Diagram) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Language": n.Language}, nil)
+}
+
+// DiagramRenderer converts the source of a diagram into SVG.
+type DiagramRenderer func(source []byte) ([]byte, error)
+
+// CommandDiagramRenderer returns a DiagramRenderer which runs a command. If the command contains "{input}" and
+// "{output}", they're replaced with the paths of temporary files holding the diagram source and where the SVG should be
+// written. Otherwise, the source is given on stdin and the SVG is read from stdout.
+func CommandDiagramRenderer(command string) DiagramRenderer {
+	return func(source []byte) ([]byte, error) {
+		args := strings.Fields(command)
+		if len(args) == 0 {
+			return nil, errors.New("no diagram command given")
+		}
+
+		usesFiles := strings.Contains(command, "{input}") || strings.Contains(command, "{output}")
+		var input, output string
+
+		if usesFiles {
+			dir, err := ioutil.TempDir("", "albatross-diagram")
+			if err != nil {
+				return nil, err
+			}
+			defer os.RemoveAll(dir)
+
+			input, output = filepath.Join(dir, "diagram.txt"), filepath.Join(dir, "diagram.svg")
+
+			err = ioutil.WriteFile(input, source, 0644)
+			if err != nil {
+				return nil, err
+			}
+
+			for i, arg := range args {
+				args[i] = strings.NewReplacer("{input}", input, "{output}", output).Replace(arg)
+			}
+		}
+
+		var stdin []byte
+		if !usesFiles {
+			stdin = source
+		}
+
+		svg, err := runCommand(command, args, stdin)
+		if err != nil {
+			return nil, err
+		}
+
+		if !usesFiles {
+			return svg, nil
+		}
+
+		return ioutil.ReadFile(output)
+	}
+}
+
+// commandTimeout is how long a command is given to render a single diagram or code block.
+const commandTimeout = 30 * time.Second
+
+// runCommand runs a command split into args, giving it stdin and returning what it writes to stdout. The command is only
+// used in error messages.
+func runCommand(command string, args []string, stdin []byte) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("no command given")
+	}
+
+	_, err := exec.LookPath(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	c := exec.CommandContext(ctx, args[0], args[1:]...)
+	c.Stdin = bytes.NewReader(stdin)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	err = c.Run()
+	if err != nil {
+		return nil, fmt.Errorf("running %q: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// plantUMLRenderer wraps a DiagramRenderer so that PlantUML diagrams without "@startuml" and "@enduml" still render.
+func plantUMLRenderer(r DiagramRenderer) DiagramRenderer {
+	return func(source []byte) ([]byte, error) {
+		if !bytes.Contains(source, []byte("@start")) {
+			source = append(append([]byte("@startuml\n"), source...), "@enduml\n"...)
+		}
+
+		return r(source)
+	}
+}
+
+// diagramTransformer replaces fenced code blocks in a diagram language with Diagram nodes.
+type diagramTransformer struct {
+	languages map[string]DiagramRenderer
+}
+
+// Transform implements parser.ASTTransformer.Transform.
+func (t *diagramTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	blocks := []*ast.FencedCodeBlock{}
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if block, ok := n.(*ast.FencedCodeBlock); ok && entering {
+			if t.languages[string(block.Language(source))] != nil {
+				blocks = append(blocks, block)
+			}
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	for _, block := range blocks {
+		diagram := &Diagram{Language: string(block.Language(source))}
+		diagram.SetLines(block.Lines())
+		block.Parent().ReplaceChild(block.Parent(), block, diagram)
+	}
+}
+
+// diagramHTMLRenderer renders Diagram nodes as inline SVG, or as a code block if they can't be rendered.
+type diagramHTMLRenderer struct {
+	languages map[string]DiagramRenderer
+
+	// cache holds the SVG for diagrams which have already been rendered, since the same diagram can be rendered many
+	// times during an export.
+	cache map[string][]byte
+	mu    sync.Mutex
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.RegisterFuncs.
+func (r *diagramHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindDiagram, r.renderDiagram)
+}
+
+func (r *diagramHTMLRenderer) renderDiagram(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	node := n.(*Diagram)
+
+	var diagram bytes.Buffer
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		diagram.Write(segment.Value(source))
+	}
+
+	svg, err := r.render(node.Language, diagram.Bytes())
+	if err != nil {
+		_, _ = w.WriteString(`<pre><code class="language-` + node.Language + `">`)
+		_, _ = w.Write(util.EscapeHTML(diagram.Bytes()))
+		_, _ = w.WriteString("</code></pre>\n")
+
+		return ast.WalkSkipChildren, nil
+	}
+
+	_, _ = w.WriteString(`<div class="diagram diagram-` + node.Language + `">`)
+	_, _ = w.Write(svg)
+	_, _ = w.WriteString("</div>\n")
+
+	return ast.WalkSkipChildren, nil
+}
+
+// render returns the SVG for a diagram, without anything before the "<svg" tag such as an XML declaration so that it can
+// be included in a page.
+func (r *diagramHTMLRenderer) render(language string, diagram []byte) ([]byte, error) {
+	key := language + "\x00" + string(diagram)
+
+	r.mu.Lock()
+	svg, ok := r.cache[key]
+	r.mu.Unlock()
+
+	if ok {
+		return svg, nil
+	}
+
+	svg, err := r.languages[language](diagram)
+	if err != nil {
+		return nil, err
+	}
+
+	start := bytes.Index(svg, []byte("<svg"))
+	if start == -1 {
+		return nil, fmt.Errorf("%s diagram output wasn't SVG", language)
+	}
+
+	svg = bytes.TrimSpace(svg[start:])
+
+	r.mu.Lock()
+	r.cache[key] = svg
+	r.mu.Unlock()
+
+	return svg, nil
+}
+
+// DiagramOption configures the Diagrams extension.
+type DiagramOption func(*diagramExtension)
+
+// WithDiagramRenderer sets how diagrams in a language are rendered, replacing the default for the language if there is
+// one. A nil DiagramRenderer means code blocks in the language are left alone.
+func WithDiagramRenderer(language string, r DiagramRenderer) DiagramOption {
+	return func(e *diagramExtension) {
+		if r == nil {
+			delete(e.languages, language)
+			return
+		}
+
+		if language == "plantuml" {
+			r = plantUMLRenderer(r)
+		}
+
+		e.languages[language] = r
+	}
+}
+
+// WithDiagramCommand sets the command used to render diagrams in a language, as described by CommandDiagramRenderer.
+func WithDiagramCommand(language, command string) DiagramOption {
+	return WithDiagramRenderer(language, CommandDiagramRenderer(command))
+}
+
+// diagramExtension is a goldmark.Extender which adds diagram support.
+type diagramExtension struct {
+	languages map[string]DiagramRenderer
+}
+
+// NewDiagrams returns a goldmark extension which renders diagrams to SVG, using DefaultDiagramCommands unless
+// configured otherwise by the options.
+func NewDiagrams(options ...DiagramOption) goldmark.Extender {
+	e := &diagramExtension{languages: map[string]DiagramRenderer{}}
+
+	for language, command := range DefaultDiagramCommands {
+		WithDiagramCommand(language, command)(e)
+	}
+
+	for _, option := range options {
+		option(e)
+	}
+
+	return e
+}
+
+// Diagrams is a goldmark extension which renders diagrams to SVG using DefaultDiagramCommands.
+var Diagrams = NewDiagrams()
+
+// Extend implements goldmark.Extender.Extend.
+func (e *diagramExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(&diagramTransformer{languages: e.languages}, 100)))
+
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&diagramHTMLRenderer{
+		languages: e.languages,
+		cache:     map[string][]byte{},
+	}, 500)))
+}
@@ -0,0 +1,48 @@
+package markdown
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+)
+
+func TestDiagrams(t *testing.T) {
+	rendered := 0
+	fake := func(source []byte) ([]byte, error) {
+		rendered++
+		return append([]byte(`<?xml version="1.0"?>`+"\n<svg>"), append(source, "</svg>"...)...), nil
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(NewDiagrams(
+		WithDiagramRenderer("mermaid", fake),
+		WithDiagramRenderer("plantuml", func(source []byte) ([]byte, error) { return nil, errors.New("not installed") }),
+	)))
+
+	source := "```mermaid\nA --> B\n```\n\n```plantuml\nA -> B\n```\n\n```go\nfmt.Println()\n```\n"
+
+	assert.Equal(
+		t,
+		"<div class=\"diagram diagram-mermaid\"><svg>A --> B\n</svg></div>\n"+
+			"<pre><code class=\"language-plantuml\">A -&gt; B\n</code></pre>\n"+
+			"<pre><code class=\"language-go\">fmt.Println()\n</code></pre>\n",
+		render(t, md, source),
+	)
+
+	render(t, md, source)
+	assert.Equal(t, 1, rendered, "expected diagrams to only be rendered once")
+}
+
+func TestCommandDiagramRenderer(t *testing.T) {
+	svg, err := CommandDiagramRenderer("cat")([]byte("<svg></svg>"))
+	assert.NoError(t, err)
+	assert.Equal(t, "<svg></svg>", string(svg))
+
+	svg, err = CommandDiagramRenderer("cp {input} {output}")([]byte("<svg></svg>"))
+	assert.NoError(t, err)
+	assert.Equal(t, "<svg></svg>", string(svg))
+
+	_, err = CommandDiagramRenderer("albatross-not-a-real-command")([]byte("A -> B"))
+	assert.Error(t, err)
+}
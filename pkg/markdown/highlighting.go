@@ -0,0 +1,148 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// The Highlighting extension highlights the syntax of code blocks which have a language, like "```go". By default it
+// uses chroma (https://github.com/alecthomas/chroma), run as a command so that it can be installed separately, and
+// code blocks are left as they are if it isn't available.
+
+// reLanguage matches the languages which are passed to highlighting commands, so that something like "--help" can't be.
+var reLanguage = regexp.MustCompile(`^[\w+#][\w+#.-]*$`)
+
+// DefaultHighlightCommand is the command used to highlight code when no other is given. "{language}" and "{style}" are
+// replaced with the language of the code block and the style, and the code is given on stdin.
+const DefaultHighlightCommand = "chroma --html --html-only --html-inline-styles --fail --lexer {language} --style {style}"
+
+// DefaultHighlightStyle is the style used to highlight code when no other is given.
+const DefaultHighlightStyle = "github"
+
+// Highlighter converts code in a language into highlighted HTML, including the surrounding "<pre>" tags.
+type Highlighter func(language string, code []byte) ([]byte, error)
+
+// CommandHighlighter returns a Highlighter which runs a command, as described by DefaultHighlightCommand.
+func CommandHighlighter(command, style string) Highlighter {
+	return func(language string, code []byte) ([]byte, error) {
+		if !reLanguage.MatchString(language) {
+			return nil, fmt.Errorf("invalid language %q", language)
+		}
+
+		replacer := strings.NewReplacer("{language}", language, "{style}", style)
+		args := []string{}
+
+		for _, arg := range strings.Fields(command) {
+			args = append(args, replacer.Replace(arg))
+		}
+
+		return runCommand(strings.Join(args, " "), args, code)
+	}
+}
+
+// highlightingHTMLRenderer renders fenced code blocks with their syntax highlighted.
+type highlightingHTMLRenderer struct {
+	highlighter Highlighter
+
+	// cache holds the HTML for code which has already been highlighted.
+	cache map[string][]byte
+	mu    sync.Mutex
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.RegisterFuncs.
+func (r *highlightingHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (r *highlightingHTMLRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	node := n.(*ast.FencedCodeBlock)
+	language := string(node.Language(source))
+
+	var code bytes.Buffer
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		code.Write(segment.Value(source))
+	}
+
+	if language != "" {
+		highlighted, err := r.highlight(language, code.Bytes())
+		if err == nil {
+			_, _ = w.Write(highlighted)
+			_ = w.WriteByte('\n')
+			return ast.WalkSkipChildren, nil
+		}
+	}
+
+	// This is the same as goldmark's own rendering of code blocks.
+	_, _ = w.WriteString("<pre><code")
+	if language != "" {
+		_, _ = w.WriteString(` class="language-` + string(util.EscapeHTML([]byte(language))) + `"`)
+	}
+	_ = w.WriteByte('>')
+	_, _ = w.Write(util.EscapeHTML(code.Bytes()))
+	_, _ = w.WriteString("</code></pre>\n")
+
+	return ast.WalkSkipChildren, nil
+}
+
+// highlight returns the highlighted HTML for some code.
+func (r *highlightingHTMLRenderer) highlight(language string, code []byte) ([]byte, error) {
+	key := language + "\x00" + string(code)
+
+	r.mu.Lock()
+	highlighted, ok := r.cache[key]
+	r.mu.Unlock()
+
+	if ok {
+		return highlighted, nil
+	}
+
+	highlighted, err := r.highlighter(language, code)
+	if err != nil {
+		return nil, err
+	}
+
+	highlighted = bytes.TrimSpace(highlighted)
+
+	r.mu.Lock()
+	r.cache[key] = highlighted
+	r.mu.Unlock()
+
+	return highlighted, nil
+}
+
+// highlightingExtension is a goldmark.Extender which adds syntax highlighting.
+type highlightingExtension struct {
+	highlighter Highlighter
+}
+
+// NewHighlighting returns a goldmark extension which highlights code blocks using the given Highlighter. If it's nil,
+// DefaultHighlightCommand is used with DefaultHighlightStyle.
+func NewHighlighting(highlighter Highlighter) goldmark.Extender {
+	if highlighter == nil {
+		highlighter = CommandHighlighter(DefaultHighlightCommand, DefaultHighlightStyle)
+	}
+
+	return &highlightingExtension{highlighter: highlighter}
+}
+
+// Extend implements goldmark.Extender.Extend.
+func (e *highlightingExtension) Extend(m goldmark.Markdown) {
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&highlightingHTMLRenderer{
+		highlighter: e.highlighter,
+		cache:       map[string][]byte{},
+	}, 200)))
+}
@@ -0,0 +1,39 @@
+package markdown
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+)
+
+func TestHighlighting(t *testing.T) {
+	highlighter := func(language string, code []byte) ([]byte, error) {
+		if language != "go" {
+			return nil, errors.New("unknown language")
+		}
+
+		return append([]byte(`<pre class="chroma">`), append(code, "</pre>"...)...), nil
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(NewHighlighting(highlighter)))
+	source := "```go\nfmt.Println()\n```\n\n```brainfuck\n<>\n```\n\n```\nplain\n```\n"
+
+	assert.Equal(
+		t,
+		"<pre class=\"chroma\">fmt.Println()\n</pre>\n"+
+			"<pre><code class=\"language-brainfuck\">&lt;&gt;\n</code></pre>\n"+
+			"<pre><code>plain\n</code></pre>\n",
+		render(t, md, source),
+	)
+}
+
+func TestCommandHighlighter(t *testing.T) {
+	out, err := CommandHighlighter("echo {language} {style}", "monokai")("go", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "go monokai\n", string(out))
+
+	_, err = CommandHighlighter("echo {language}", "monokai")("--help", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,21 @@
+// Package markdown contains the goldmark extensions used when rendering entries as HTML, such as when exporting them,
+// so that every export renders entries in the same way.
+package markdown
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// New returns a goldmark.Markdown with the extensions used for rendering entries: GitHub Flavored Markdown, typographic
+// punctuation, math and admonitions. Any options given are applied afterwards, such as renderer options.
+//
+// Diagrams and syntax highlighting aren't included by default since they mean running external programs, but can be by
+// adding NewDiagrams and NewHighlighting as extensions.
+func New(options ...goldmark.Option) goldmark.Markdown {
+	defaults := []goldmark.Option{
+		goldmark.WithExtensions(extension.GFM, extension.Typographer, Math, Admonitions),
+	}
+
+	return goldmark.New(append(defaults, options...)...)
+}
@@ -0,0 +1,356 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Math is written in entries using LaTeX, either inline like "$x^2$" or "\(x^2\)", or displayed on its own like
+// "$$x^2$$" or "\[x^2\]". Without special handling, Markdown would treat characters like '\', '_' and '*' in LaTeX as
+// formatting, so "\\" would become "\" and "a_1 + b_1" would become italic.
+//
+// The Math extension parses these regions before anything else can, and renders them untouched apart from escaping
+// HTML, wrapped in delimiters for a renderer like MathJax or KaTeX. The delimiters default to the ones MathJax
+// recognises, "\(...\)" and "\[...\]".
+
+// KindMath is the ast.NodeKind for MathNode nodes.
+var KindMath = ast.NewNodeKind("Math")
+
+// KindMathBlock is the ast.NodeKind for MathBlock nodes.
+var KindMathBlock = ast.NewNodeKind("MathBlock")
+
+// MathNode is an inline math expression, like "$x^2$". Its children are the raw text of the expression.
+type MathNode struct {
+	ast.BaseInline
+
+	// Display is true for display math written inline, like "$$x^2$$".
+	Display bool
+
+	// Opener and Closer are the delimiters used in the source, like "$" or "\[".
+	Opener, Closer string
+}
+
+// Kind implements ast.Node.Kind.
+func (n *MathNode) Kind() ast.NodeKind {
+	return KindMath
+}
+
+// Text implements ast.Node.Text, returning the expression including its delimiters so that it's kept intact when
+// the text of a node containing it is used, such as a heading.
+func (n *MathNode) Text(source []byte) []byte {
+	return append(append([]byte(n.Opener), n.BaseInline.Text(source)...), n.Closer...)
+}
+
+// Dump implements ast.Node.Dump.
+func (n *MathNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// MathBlock is a math expression on its own lines, like:
+//
+//	$$
+//	x^2
+//	$$
+type MathBlock struct {
+	ast.BaseBlock
+
+	// closer is the delimiter which ends the block.
+	closer []byte
+}
+
+// Kind implements ast.Node.Kind.
+func (n *MathBlock) Kind() ast.NodeKind {
+	return KindMathBlock
+}
+
+// IsRaw implements ast.Node.IsRaw, since the contents of a math block shouldn't be parsed as Markdown.
+func (n *MathBlock) IsRaw() bool {
+	return true
+}
+
+// Dump implements ast.Node.Dump.
+func (n *MathBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// mathDelimiters maps the start of each kind of math expression to the end.
+var mathDelimiters = []struct {
+	opener, closer string
+	display        bool
+}{
+	{"$$", "$$", true},
+	{`\[`, `\]`, true},
+	{`\(`, `\)`, false},
+	{"$", "$", false},
+}
+
+// mathInlineParser parses inline math expressions.
+type mathInlineParser struct{}
+
+// Trigger implements parser.InlineParser.Trigger.
+func (p *mathInlineParser) Trigger() []byte {
+	return []byte{'$', '\\'}
+}
+
+// Parse implements parser.InlineParser.Parse.
+func (p *mathInlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+
+	for _, delim := range mathDelimiters {
+		if !bytes.HasPrefix(line, []byte(delim.opener)) {
+			continue
+		}
+
+		// Like Pandoc, "$" math can't start with a space, so that prices like "$5 and $10" aren't math.
+		if delim.opener == "$" && (len(line) < 2 || util.IsSpace(line[1])) {
+			return nil
+		}
+
+		node := &MathNode{Display: delim.display, Opener: delim.opener, Closer: delim.closer}
+		if !parseMathContents(node, block, delim.opener, delim.closer) {
+			return nil
+		}
+
+		return node
+	}
+
+	return nil
+}
+
+// parseMathContents reads the contents of a math expression up to the closer, which may be on a later line, adding it
+// to the node. It returns false if there's no closer or the expression is empty.
+func parseMathContents(node ast.Node, block text.Reader, opener, closer string) bool {
+	block.Advance(len(opener))
+
+	for {
+		line, segment := block.PeekLine()
+		if line == nil {
+			return false
+		}
+
+		for i := 0; i < len(line); i++ {
+			if line[i] == '\\' && closer[0] == '$' {
+				// "\$" is a literal dollar sign in LaTeX.
+				i++
+				continue
+			}
+
+			if !bytes.HasPrefix(line[i:], []byte(closer)) {
+				continue
+			}
+
+			if closer == "$" {
+				// A single "$" can't end with a space or be followed by a digit or another "$".
+				if i == 0 || util.IsSpace(line[i-1]) {
+					continue
+				}
+
+				if i+1 < len(line) && (util.IsNumeric(line[i+1]) || line[i+1] == '$') {
+					continue
+				}
+			}
+
+			if i != 0 {
+				node.AppendChild(node, ast.NewRawTextSegment(segment.WithStop(segment.Start+i)))
+			}
+
+			if node.ChildCount() == 0 {
+				return false
+			}
+
+			block.Advance(i + len(closer))
+			return true
+		}
+
+		node.AppendChild(node, ast.NewRawTextSegment(segment))
+		block.AdvanceLine()
+	}
+}
+
+// mathBlockParser parses math blocks.
+type mathBlockParser struct{}
+
+// Trigger implements parser.BlockParser.Trigger.
+func (p *mathBlockParser) Trigger() []byte {
+	return []byte{'$', '\\'}
+}
+
+// Open implements parser.BlockParser.Open.
+func (p *mathBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+
+	for _, delim := range mathDelimiters[:2] {
+		if !bytes.HasPrefix(line[pos:], []byte(delim.opener)) {
+			continue
+		}
+
+		rest := line[pos+len(delim.opener):]
+
+		// Expressions which close on the same line are left to the inline parser.
+		if bytes.Contains(rest, []byte(delim.closer)) {
+			return nil, parser.NoChildren
+		}
+
+		node := &MathBlock{closer: []byte(delim.closer)}
+
+		if !util.IsBlank(rest) {
+			start := segment.Start + pos + len(delim.opener)
+			node.Lines().Append(text.NewSegment(start, segment.Stop))
+		}
+
+		return node, parser.NoChildren
+	}
+
+	return nil, parser.NoChildren
+}
+
+// Continue implements parser.BlockParser.Continue.
+func (p *mathBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	closer := node.(*MathBlock).closer
+
+	i := bytes.Index(line, closer)
+	if i == -1 {
+		node.Lines().Append(segment)
+		reader.Advance(segment.Len() - 1)
+		return parser.Continue | parser.NoChildren
+	}
+
+	if !util.IsBlank(line[:i]) {
+		node.Lines().Append(text.NewSegment(segment.Start, segment.Start+i))
+	}
+
+	newline := 1
+	if line[len(line)-1] != '\n' {
+		newline = 0
+	}
+
+	reader.Advance(segment.Len() - newline)
+	return parser.Close
+}
+
+// Close implements parser.BlockParser.Close.
+func (p *mathBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser.CanInterruptParagraph.
+func (p *mathBlockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.CanAcceptIndentedLine.
+func (p *mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// mathHTMLRenderer renders Math and MathBlock nodes as HTML.
+type mathHTMLRenderer struct {
+	inlineOpen, inlineClose   string
+	displayOpen, displayClose string
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.RegisterFuncs.
+func (r *mathHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindMath, r.renderMath)
+	reg.Register(KindMathBlock, r.renderMathBlock)
+}
+
+func (r *mathHTMLRenderer) renderMath(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	node := n.(*MathNode)
+	open, close, class := r.inlineOpen, r.inlineClose, "math inline"
+	if node.Display {
+		open, close, class = r.displayOpen, r.displayClose, "math display"
+	}
+
+	_, _ = w.WriteString(`<span class="` + class + `">` + open)
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		_, _ = w.Write(util.EscapeHTML(child.(*ast.Text).Segment.Value(source)))
+	}
+	_, _ = w.WriteString(close + `</span>`)
+
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *mathHTMLRenderer) renderMathBlock(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	_, _ = w.WriteString(`<div class="math display">` + r.displayOpen)
+
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		_, _ = w.Write(util.EscapeHTML(segment.Value(source)))
+	}
+
+	_, _ = w.WriteString(r.displayClose + "</div>\n")
+
+	return ast.WalkSkipChildren, nil
+}
+
+// MathOption configures the Math extension.
+type MathOption func(*mathExtension)
+
+// WithInlineDelimiters sets the delimiters inline math is rendered with, by default "\(" and "\)".
+func WithInlineDelimiters(open, close string) MathOption {
+	return func(e *mathExtension) {
+		e.renderer.inlineOpen, e.renderer.inlineClose = open, close
+	}
+}
+
+// WithDisplayDelimiters sets the delimiters display math is rendered with, by default "\[" and "\]".
+func WithDisplayDelimiters(open, close string) MathOption {
+	return func(e *mathExtension) {
+		e.renderer.displayOpen, e.renderer.displayClose = open, close
+	}
+}
+
+// mathExtension is a goldmark.Extender which adds math support.
+type mathExtension struct {
+	renderer *mathHTMLRenderer
+}
+
+// NewMath returns a goldmark extension which passes math expressions through untouched, rendered with the delimiters
+// given by the options.
+func NewMath(options ...MathOption) goldmark.Extender {
+	e := &mathExtension{renderer: &mathHTMLRenderer{
+		inlineOpen:   `\(`,
+		inlineClose:  `\)`,
+		displayOpen:  `\[`,
+		displayClose: `\]`,
+	}}
+
+	for _, option := range options {
+		option(e)
+	}
+
+	return e
+}
+
+// Math is a goldmark extension which passes math expressions through untouched, rendered with the delimiters used by
+// MathJax.
+var Math = NewMath()
+
+// Extend implements goldmark.Extender.Extend.
+func (e *mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(util.Prioritized(&mathBlockParser{}, 750)),
+		parser.WithInlineParsers(util.Prioritized(&mathInlineParser{}, 50)),
+	)
+
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(e.renderer, 500)))
+}
@@ -0,0 +1,80 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+)
+
+func render(t *testing.T, md goldmark.Markdown, source string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	err := md.Convert([]byte(source), &buf)
+	if err != nil {
+		t.Fatalf("not expecting error converting markdown: %s", err)
+	}
+
+	return buf.String()
+}
+
+func TestMath(t *testing.T) {
+	md := New()
+
+	tcs := []struct {
+		name, in, out string
+	}{
+		{
+			"inline",
+			`What is $a_1 * b_1 \\ c$?`,
+			`<p>What is <span class="math inline">\(a_1 * b_1 \\ c\)</span>?</p>` + "\n",
+		},
+		{
+			"inline brackets",
+			`So \(x < y\) and \[z\].`,
+			`<p>So <span class="math inline">\(x &lt; y\)</span> and <span class="math display">\[z\]</span>.</p>` + "\n",
+		},
+		{
+			"inline display",
+			`$$\frac{a}{b}$$`,
+			`<p><span class="math display">\[\frac{a}{b}\]</span></p>` + "\n",
+		},
+		{
+			"prices",
+			`It costs $5 and $10, or \$3.`,
+			`<p>It costs $5 and $10, or $3.</p>` + "\n",
+		},
+		{
+			"escaped dollar",
+			`$\$5 + x$`,
+			`<p><span class="math inline">\(\$5 + x\)</span></p>` + "\n",
+		},
+		{
+			"block",
+			"Before\n\n$$\n\\begin{matrix} 3 & 3 \\\\\n\n3 & 3 \\end{matrix}\n$$\n\nAfter",
+			"<p>Before</p>\n<div class=\"math display\">\\[\\begin{matrix} 3 &amp; 3 \\\\\n\n3 &amp; 3 \\end{matrix}\n\\]</div>\n<p>After</p>\n",
+		},
+		{
+			"code",
+			"`$x_1$`",
+			"<p><code>$x_1$</code></p>\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		assert.Equal(t, tc.out, render(t, md, tc.in), tc.name)
+	}
+}
+
+func TestMathDelimiters(t *testing.T) {
+	md := goldmark.New(goldmark.WithExtensions(NewMath(WithInlineDelimiters("[$]", "[/$]"), WithDisplayDelimiters("[$$]", "[/$$]"))))
+
+	assert.Equal(
+		t,
+		`<p><span class="math inline">[$]x[/$]</span> <span class="math display">[$$]y[/$$]</span></p>`+"\n",
+		render(t, md, `$x$ $$y$$`),
+	)
+}
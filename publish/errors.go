@@ -0,0 +1,13 @@
+package publish
+
+import "fmt"
+
+// ErrInvalidTarget is returned when a target URL can't be used.
+type ErrInvalidTarget struct {
+	Target string
+	Reason string
+}
+
+func (e ErrInvalidTarget) Error() string {
+	return fmt.Sprintf("invalid publish target %q: %s", e.Target, e.Reason)
+}
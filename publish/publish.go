@@ -0,0 +1,275 @@
+// Package publish uploads generated files, such as exported sites and backups, to remote targets. Files are uploaded
+// incrementally: a record of the checksum of every file already uploaded is kept locally, so that only new and changed
+// files are uploaded and an interrupted publish carries on where it left off when run again.
+//
+// Targets are given as URLs:
+//
+//	sftp://user@host:port/path    uploads using the 'sftp' command
+//	rsync://user@host:port/path   uploads using 'rsync' over ssh
+//	s3://bucket/prefix            uploads using the 'aws' command line tool
+//	file:///path                  copies to a local folder
+//
+// For sftp and rsync targets, paths are absolute unless they start with "/~/", in which case they're relative to the
+// home folder on the host, like "sftp://me@example.com/~/public_html".
+package publish
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DefaultBatchSize is how many files are uploaded before the record of uploaded files is saved.
+const DefaultBatchSize = 25
+
+// Transport uploads files to a target.
+type Transport interface {
+	// Upload uploads the files, given as slash-separated paths relative to dir, to the same paths on the target.
+	Upload(dir string, files []string) error
+
+	// Delete removes the files, given as slash-separated paths, from the target.
+	Delete(files []string) error
+
+	// String returns the target URL.
+	String() string
+}
+
+// Plan is what a publish will do.
+type Plan struct {
+	// Upload are the files which are new or have changed since they were last uploaded.
+	Upload []string `json:"upload"`
+
+	// Delete are the files which were uploaded before but no longer exist locally. It's only set if deleting was asked
+	// for.
+	Delete []string `json:"delete"`
+
+	// Unchanged is the number of files which are already uploaded.
+	Unchanged int `json:"unchanged"`
+
+	checksums map[string]string
+}
+
+// Empty returns true if the plan doesn't upload or delete anything.
+func (p Plan) Empty() bool {
+	return len(p.Upload) == 0 && len(p.Delete) == 0
+}
+
+// Publisher publishes files using a Transport, keeping the checksums of the files it has uploaded in a state file.
+type Publisher struct {
+	transport Transport
+	statePath string
+	batchSize int
+}
+
+// NewPublisher returns a new Publisher which uploads using the transport given and keeps its record of uploaded files
+// at statePath. Separate sources or targets should use separate state files.
+func NewPublisher(transport Transport, statePath string) *Publisher {
+	return &Publisher{transport: transport, statePath: statePath, batchSize: DefaultBatchSize}
+}
+
+// SetBatchSize sets how many files are uploaded before the record of uploaded files is saved. Smaller batches mean less
+// is uploaded again if a publish is interrupted.
+func (p *Publisher) SetBatchSize(size int) {
+	if size < 1 {
+		size = 1
+	}
+
+	p.batchSize = size
+}
+
+// Plan works out what publishing the source, a file or a folder, would upload. If deleteRemoved is true, files which were
+// uploaded before but have since been removed from the source are deleted from the target.
+func (p *Publisher) Plan(source string, deleteRemoved bool) (Plan, error) {
+	dir, files, err := sourceFiles(source)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	uploaded, err := p.readState()
+	if err != nil {
+		return Plan{}, err
+	}
+
+	plan := Plan{Upload: []string{}, Delete: []string{}, checksums: map[string]string{}}
+
+	for _, file := range files {
+		checksum, err := fileChecksum(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			return Plan{}, err
+		}
+
+		plan.checksums[file] = checksum
+
+		if uploaded[file] == checksum {
+			plan.Unchanged++
+		} else {
+			plan.Upload = append(plan.Upload, file)
+		}
+	}
+
+	if deleteRemoved {
+		for file := range uploaded {
+			if _, ok := plan.checksums[file]; !ok {
+				plan.Delete = append(plan.Delete, file)
+			}
+		}
+
+		sort.Strings(plan.Delete)
+	}
+
+	return plan, nil
+}
+
+// Publish carries out a plan made by Plan for the same source. After each batch of files is uploaded the record of
+// uploaded files is saved, so if it fails part of the way through only the rest will be uploaded next time.
+func (p *Publisher) Publish(source string, plan Plan) error {
+	dir, _, err := sourceFiles(source)
+	if err != nil {
+		return err
+	}
+
+	uploaded, err := p.readState()
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(plan.Upload); start += p.batchSize {
+		end := start + p.batchSize
+		if end > len(plan.Upload) {
+			end = len(plan.Upload)
+		}
+
+		batch := plan.Upload[start:end]
+
+		err = p.transport.Upload(dir, batch)
+		if err != nil {
+			return fmt.Errorf("couldn't upload to %s: %w", p.transport, err)
+		}
+
+		for _, file := range batch {
+			uploaded[file] = plan.checksums[file]
+		}
+
+		err = p.writeState(uploaded)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(plan.Delete) != 0 {
+		err = p.transport.Delete(plan.Delete)
+		if err != nil {
+			return fmt.Errorf("couldn't delete from %s: %w", p.transport, err)
+		}
+
+		for _, file := range plan.Delete {
+			delete(uploaded, file)
+		}
+
+		err = p.writeState(uploaded)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readState reads the checksums of the files which have been uploaded, by path.
+func (p *Publisher) readState() (map[string]string, error) {
+	uploaded := map[string]string{}
+
+	data, err := ioutil.ReadFile(p.statePath)
+	if os.IsNotExist(err) {
+		return uploaded, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &uploaded)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse publish state %s: %w", p.statePath, err)
+	}
+
+	return uploaded, nil
+}
+
+// writeState saves the checksums of the files which have been uploaded. It writes to a temporary file first so that the
+// state isn't lost if it's interrupted.
+func (p *Publisher) writeState(uploaded map[string]string) error {
+	data, err := json.MarshalIndent(uploaded, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(p.statePath), 0755)
+	if err != nil {
+		return err
+	}
+
+	tmp := p.statePath + ".tmp"
+
+	err = ioutil.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, p.statePath)
+}
+
+// sourceFiles returns the folder the source is in and the files in it as slash-separated paths relative to the folder.
+// If the source is a single file, the folder is the one containing it.
+func sourceFiles(source string) (dir string, files []string, err error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !info.IsDir() {
+		return filepath.Dir(source), []string{filepath.Base(source)}, nil
+	}
+
+	err = filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+
+	return source, files, err
+}
+
+// fileChecksum returns the hex-encoded SHA-256 checksum of the file.
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,152 @@
+package publish
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+// failingTransport uploads to a local folder but fails after a number of uploads.
+type failingTransport struct {
+	*fileTransport
+	remaining int
+}
+
+func (t *failingTransport) Upload(dir string, files []string) error {
+	if t.remaining == 0 {
+		return errors.New("connection reset")
+	}
+
+	t.remaining--
+	return t.fileTransport.Upload(dir, files)
+}
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ioutil.WriteFile(path, []byte(contents), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	dir, err := ioutil.TempDir("", "albatross-publish")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "site")
+	target := filepath.Join(dir, "target")
+	state := filepath.Join(dir, "state.json")
+
+	writeTestFiles(t, source, map[string]string{
+		"index.html":            "index",
+		"entries/a/index.html":  "a",
+		"entries/b/index.html":  "b",
+		"entries/b/picture.png": "png",
+	})
+
+	transport, err := Open("file://" + filepath.ToSlash(target))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failing := &failingTransport{fileTransport: transport.(*fileTransport), remaining: 1}
+	publisher := NewPublisher(failing, state)
+	publisher.SetBatchSize(2)
+
+	plan, err := publisher.Plan(source, false)
+	Nil(t, err)
+	Equal(t, 4, len(plan.Upload))
+	Equal(t, 0, plan.Unchanged)
+
+	err = publisher.Publish(source, plan)
+	NotNil(t, err, "expecting error when transport fails part of the way through")
+
+	plan, err = publisher.Plan(source, false)
+	Nil(t, err)
+	Equal(t, 2, len(plan.Upload), "expecting only files which weren't uploaded to be uploaded when resuming")
+	Equal(t, 2, plan.Unchanged)
+
+	failing.remaining = -1
+	err = publisher.Publish(source, plan)
+	Nil(t, err)
+
+	data, err := ioutil.ReadFile(filepath.Join(target, "entries", "b", "picture.png"))
+	Nil(t, err)
+	Equal(t, "png", string(data))
+
+	plan, err = publisher.Plan(source, true)
+	Nil(t, err)
+	True(t, plan.Empty(), "expecting nothing to do once everything has been uploaded")
+
+	writeTestFiles(t, source, map[string]string{"index.html": "changed"})
+	os.Remove(filepath.Join(source, "entries", "a", "index.html"))
+
+	plan, err = publisher.Plan(source, false)
+	Nil(t, err)
+	Equal(t, []string{"index.html"}, plan.Upload)
+	Equal(t, []string{}, plan.Delete, "expecting removed files not to be deleted unless asked")
+
+	plan, err = publisher.Plan(source, true)
+	Nil(t, err)
+	Equal(t, []string{"entries/a/index.html"}, plan.Delete)
+
+	err = publisher.Publish(source, plan)
+	Nil(t, err)
+
+	_, err = os.Stat(filepath.Join(target, "entries", "a", "index.html"))
+	True(t, os.IsNotExist(err), "expecting removed file to be deleted from target")
+
+	data, err = ioutil.ReadFile(filepath.Join(target, "index.html"))
+	Nil(t, err)
+	Equal(t, "changed", string(data))
+}
+
+func TestOpen(t *testing.T) {
+	transport, err := Open("sftp://me@example.com:2222/var/www/site")
+	Nil(t, err)
+
+	var commands []string
+	var stdins []string
+	sftp := transport.(*sftpTransport)
+	sftp.run = func(stdin, name string, args ...string) error {
+		commands = append(commands, name+" "+strings.Join(args, " "))
+		stdins = append(stdins, stdin)
+		return nil
+	}
+
+	err = sftp.Upload("/tmp/site", []string{"index.html", "entries/a/index.html"})
+	Nil(t, err)
+	Equal(t, []string{"sftp -b - -P 2222 me@example.com"}, commands)
+	Equal(t, "-mkdir \"/var/www/site/entries\"\n-mkdir \"/var/www/site/entries/a\"\n"+
+		"put \"/tmp/site/index.html\" \"/var/www/site/index.html\"\n"+
+		"put \"/tmp/site/entries/a/index.html\" \"/var/www/site/entries/a/index.html\"\n", stdins[0])
+
+	transport, err = Open("s3://bucket/backups/")
+	Nil(t, err)
+	Equal(t, "s3://bucket/backups/store.tar", transport.(*s3Transport).url("store.tar"))
+
+	transport, err = Open("rsync://example.com/~/backups")
+	Nil(t, err)
+	Equal(t, "backups", transport.(*rsyncTransport).path, "expecting path starting with ~ to be relative to home folder")
+
+	for _, target := range []string{"/just/a/path", "ftp://example.com/path", "s3:///prefix", "sftp:///path"} {
+		_, err = Open(target)
+		IsType(t, ErrInvalidTarget{}, err, "expecting error for target %q", target)
+	}
+}
@@ -0,0 +1,312 @@
+package publish
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// runFunc runs a command with the stdin given.
+type runFunc func(stdin, name string, args ...string) error
+
+// Open returns the Transport for a target URL, see the package documentation for the targets which are supported.
+func Open(target string) (Transport, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, ErrInvalidTarget{Target: target, Reason: err.Error()}
+	}
+
+	switch u.Scheme {
+	case "file":
+		if u.Path == "" {
+			return nil, ErrInvalidTarget{Target: target, Reason: "no path given"}
+		}
+
+		return &fileTransport{target: target, root: filepath.FromSlash(u.Path)}, nil
+
+	case "sftp", "rsync":
+		if u.Host == "" {
+			return nil, ErrInvalidTarget{Target: target, Reason: "no host given"}
+		}
+
+		host := u.Hostname()
+		if u.User != nil {
+			host = u.User.Username() + "@" + host
+		}
+
+		// Paths are absolute, unless they start with "/~/" when they're relative to the home folder.
+		remote := u.Path
+		if remote == "" || remote == "/~" {
+			remote = "."
+		} else if strings.HasPrefix(remote, "/~/") {
+			remote = strings.TrimPrefix(remote, "/~/")
+		}
+
+		if u.Scheme == "sftp" {
+			return &sftpTransport{target: target, host: host, port: u.Port(), path: remote, run: runCommand}, nil
+		}
+
+		return &rsyncTransport{target: target, host: host, port: u.Port(), path: remote, run: runCommand}, nil
+
+	case "s3":
+		if u.Host == "" {
+			return nil, ErrInvalidTarget{Target: target, Reason: "no bucket given"}
+		}
+
+		return &s3Transport{target: target, bucket: u.Host, prefix: strings.Trim(u.Path, "/"), run: runCommand}, nil
+
+	case "":
+		return nil, ErrInvalidTarget{Target: target, Reason: "no scheme given, like sftp://, rsync://, s3:// or file://"}
+
+	default:
+		return nil, ErrInvalidTarget{Target: target, Reason: fmt.Sprintf("unsupported scheme %q", u.Scheme)}
+	}
+}
+
+// fileTransport copies files to a local folder.
+type fileTransport struct {
+	target string
+	root   string
+}
+
+func (t *fileTransport) Upload(dir string, files []string) error {
+	for _, file := range files {
+		dst := filepath.Join(t.root, filepath.FromSlash(file))
+
+		err := os.MkdirAll(filepath.Dir(dst), 0755)
+		if err != nil {
+			return err
+		}
+
+		err = copyFile(filepath.Join(dir, filepath.FromSlash(file)), dst)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *fileTransport) Delete(files []string) error {
+	for _, file := range files {
+		err := os.Remove(filepath.Join(t.root, filepath.FromSlash(file)))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *fileTransport) String() string {
+	return t.target
+}
+
+// sftpTransport uploads files using the 'sftp' command, giving it a batch of commands on stdin.
+type sftpTransport struct {
+	target string
+	host   string
+	port   string
+	path   string
+	run    runFunc
+}
+
+func (t *sftpTransport) Upload(dir string, files []string) error {
+	var batch strings.Builder
+
+	// Commands starting with "-" don't stop the batch if they fail, which mkdir does if the folder already exists.
+	for _, remoteDir := range parentDirs(files) {
+		fmt.Fprintf(&batch, "-mkdir %s\n", sftpQuote(path.Join(t.path, remoteDir)))
+	}
+
+	for _, file := range files {
+		fmt.Fprintf(&batch, "put %s %s\n", sftpQuote(filepath.Join(dir, filepath.FromSlash(file))), sftpQuote(path.Join(t.path, file)))
+	}
+
+	return t.run(batch.String(), "sftp", t.args()...)
+}
+
+func (t *sftpTransport) Delete(files []string) error {
+	var batch strings.Builder
+
+	for _, file := range files {
+		fmt.Fprintf(&batch, "-rm %s\n", sftpQuote(path.Join(t.path, file)))
+	}
+
+	return t.run(batch.String(), "sftp", t.args()...)
+}
+
+func (t *sftpTransport) args() []string {
+	args := []string{"-b", "-"}
+	if t.port != "" {
+		args = append(args, "-P", t.port)
+	}
+
+	return append(args, t.host)
+}
+
+func (t *sftpTransport) String() string {
+	return t.target
+}
+
+// rsyncTransport uploads files using 'rsync' over ssh.
+type rsyncTransport struct {
+	target string
+	host   string
+	port   string
+	path   string
+	run    runFunc
+}
+
+func (t *rsyncTransport) Upload(dir string, files []string) error {
+	// --files-from keeps the paths relative to dir and creates the folders they're in.
+	return t.run(strings.Join(files, "\n")+"\n", "rsync",
+		"--times", "--files-from=-", "-e", t.ssh(),
+		strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator),
+		t.host+":"+t.path+"/",
+	)
+}
+
+func (t *rsyncTransport) Delete(files []string) error {
+	args := []string{}
+	if t.port != "" {
+		args = append(args, "-p", t.port)
+	}
+
+	remove := []string{"rm", "-f", "--"}
+	for _, file := range files {
+		remove = append(remove, shellQuote(path.Join(t.path, file)))
+	}
+
+	return t.run("", "ssh", append(args, t.host, strings.Join(remove, " "))...)
+}
+
+func (t *rsyncTransport) ssh() string {
+	if t.port != "" {
+		return "ssh -p " + t.port
+	}
+
+	return "ssh"
+}
+
+func (t *rsyncTransport) String() string {
+	return t.target
+}
+
+// s3Transport uploads files using the 'aws' command line tool, which is configured as usual for credentials and regions.
+type s3Transport struct {
+	target string
+	bucket string
+	prefix string
+	run    runFunc
+}
+
+func (t *s3Transport) Upload(dir string, files []string) error {
+	for _, file := range files {
+		err := t.run("", "aws", "s3", "cp", "--only-show-errors", filepath.Join(dir, filepath.FromSlash(file)), t.url(file))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *s3Transport) Delete(files []string) error {
+	for _, file := range files {
+		err := t.run("", "aws", "s3", "rm", "--only-show-errors", t.url(file))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *s3Transport) url(file string) string {
+	return "s3://" + path.Join(t.bucket, t.prefix, file)
+}
+
+func (t *s3Transport) String() string {
+	return t.target
+}
+
+// parentDirs returns every folder the files are in, parents first, not including the top level.
+func parentDirs(files []string) []string {
+	seen := map[string]bool{}
+
+	for _, file := range files {
+		for dir := path.Dir(file); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			seen[dir] = true
+		}
+	}
+
+	dirs := []string{}
+	for dir := range seen {
+		dirs = append(dirs, dir)
+	}
+
+	// Sorting puts a folder before the folders inside it.
+	sort.Strings(dirs)
+
+	return dirs
+}
+
+// sftpQuote quotes a path for an sftp batch file.
+func sftpQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// shellQuote quotes an argument for a remote shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// copyFile copies the file at src to dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// runCommand runs a command, including what it wrote to stderr in the error if it fails.
+func runCommand(stdin, name string, args ...string) error {
+	var stderr bytes.Buffer
+
+	c := exec.Command(name, args...)
+	c.Stdin = strings.NewReader(stdin)
+	c.Stderr = &stderr
+
+	err := c.Run()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil
+}
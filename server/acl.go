@@ -0,0 +1,164 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/gin-gonic/gin"
+)
+
+// grantKey is the key the grant for a request is stored under in the gin context.
+const grantKey = "albatross.grant"
+
+// Grant gives the holder of an access token access to part of the store being served, such as a partner who can read
+// recipes/ but not journal/.
+type Grant struct {
+	// Name identifies who the grant is for. It's only used in error messages.
+	Name string `mapstructure:"name"`
+
+	// Token is the secret access token, given as a bearer token in the Authorization header or the "token" query
+	// parameter.
+	Token string `mapstructure:"token"`
+
+	// Paths are the path prefixes the token can access, such as "recipes" or "school/physics". A prefix only matches
+	// whole path components, so "recipes" doesn't give access to "recipes-old". An empty prefix gives access to every
+	// entry.
+	Paths []string `mapstructure:"paths"`
+
+	// Write is true if the token can modify entries as well as read them.
+	Write bool `mapstructure:"write"`
+}
+
+// allows returns true if the grant gives access to the entry at the path given.
+func (g *Grant) allows(path string) bool {
+	path = strings.Trim(path, "/")
+
+	for _, prefix := range g.Paths {
+		prefix = strings.Trim(prefix, "/")
+
+		if prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filter returns a filter allowing only the entries the grant gives access to.
+func (g *Grant) filter() entries.Filter {
+	return func(entry *entries.Entry) bool {
+		return g.allows(entry.Path)
+	}
+}
+
+// SetACL restricts access to the server to requests which give the token of one of the grants, and only to the entries
+// those grants allow. It should be called before Serve. By default, or if no grants are given, anyone can read every
+// entry being served.
+func (s *Server) SetACL(grants []Grant) error {
+	seen := make(map[string]bool)
+
+	for i, grant := range grants {
+		if grant.Token == "" {
+			return fmt.Errorf("grant %d (%q) has no token", i, grant.Name)
+		}
+
+		if seen[grant.Token] {
+			return fmt.Errorf("grant %d (%q) uses the same token as another grant", i, grant.Name)
+		}
+
+		seen[grant.Token] = true
+	}
+
+	s.grants = grants
+	return nil
+}
+
+// findGrant returns the grant with the token given, or nil if there isn't one.
+func (s *Server) findGrant(token string) *Grant {
+	var found *Grant
+
+	// Every grant is compared in constant time so that the time taken doesn't give away anything about the tokens.
+	for i := range s.grants {
+		if subtle.ConstantTimeCompare([]byte(s.grants[i].Token), []byte(token)) == 1 {
+			found = &s.grants[i]
+		}
+	}
+
+	return found
+}
+
+// authorize returns middleware which checks that a request is allowed to use a route. If the server has an ACL, the
+// request must give a valid token, and routes which modify entries need a grant with write access. The grant is stored
+// in the context for handlers to restrict which entries are used.
+func (s *Server) authorize(r route) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if r.Write && !s.checkWritable(c) {
+			return
+		}
+
+		// Routes which modify entries always need a token, even without an ACL, since otherwise any web page could use
+		// them through the browser of someone running the server.
+		if len(s.grants) == 0 && !r.Write {
+			return
+		}
+
+		token := requestToken(c)
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error_type": "unauthorized",
+				"error":      "an access token is required",
+			})
+			return
+		}
+
+		grant := s.findGrant(token)
+		if grant == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error_type": "unauthorized",
+				"error":      "invalid access token",
+			})
+			return
+		}
+
+		if r.Write && !grant.Write {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error_type": "forbidden",
+				"error":      "access token is read-only",
+			})
+			return
+		}
+
+		c.Set(grantKey, grant)
+	}
+}
+
+// requestGrant returns the grant for a request, or nil if the server has no ACL.
+func requestGrant(c *gin.Context) *Grant {
+	grant, ok := c.Get(grantKey)
+	if !ok {
+		return nil
+	}
+
+	return grant.(*Grant)
+}
+
+// allowed returns true if the request can access the entry at the path given.
+func allowed(c *gin.Context, path string) bool {
+	grant := requestGrant(c)
+	return grant == nil || grant.allows(path)
+}
+
+// requestCollection returns the collection being served, restricted to the entries the request can access.
+func (s *Server) requestCollection(c *gin.Context) (*entries.Collection, error) {
+	collection := s.getCollection()
+
+	grant := requestGrant(c)
+	if grant == nil {
+		return collection, nil
+	}
+
+	return collection.Filter(grant.filter())
+}
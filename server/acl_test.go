@@ -0,0 +1,184 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestGrantAllows(t *testing.T) {
+	tests := []struct {
+		paths   []string
+		path    string
+		allowed bool
+	}{
+		{[]string{"school"}, "school", true},
+		{[]string{"school"}, "school/physics", true},
+		{[]string{"school"}, "schoolwork", false},
+		{[]string{"school"}, "schoolwork/essay", false},
+		{[]string{"school/"}, "school/physics", true},
+		{[]string{"/school/physics"}, "school/physics/waves", true},
+		{[]string{"school/physics"}, "school", false},
+		{[]string{"school/physics"}, "school/physics-old", false},
+		{[]string{"food", "school"}, "school/physics", true},
+		{[]string{""}, "journal/2020-08-06", true},
+		{nil, "journal/2020-08-06", false},
+	}
+
+	for _, test := range tests {
+		grant := Grant{Paths: test.paths}
+		Equal(t, test.allowed, grant.allows(test.path), "expecting %v to allow %q: %t", test.paths, test.path, test.allowed)
+	}
+}
+
+func TestSetACL(t *testing.T) {
+	s := testServer(t, nil)
+
+	Error(t, s.SetACL([]Grant{{Name: "empty", Paths: []string{""}}}), "expecting grants without a token to be rejected")
+	Error(t, s.SetACL([]Grant{
+		{Name: "a", Token: "secret", Paths: []string{""}},
+		{Name: "b", Token: "secret", Paths: []string{"food"}},
+	}), "expecting grants with the same token to be rejected")
+}
+
+func TestAuthorize(t *testing.T) {
+	s, cleanup := writableTestServer(t, func(s *Server) {
+		setACL(t, s,
+			Grant{Name: "reader", Token: "reader", Paths: []string{""}},
+			Grant{Name: "writer", Token: "writer", Paths: []string{""}, Write: true},
+		)
+	})
+	defer cleanup()
+
+	w := testRequest{Path: "/entries/food/pizza"}.do(s)
+	Equal(t, http.StatusUnauthorized, w.Code, "expecting requests without a token to be unauthorized")
+
+	w = testRequest{Path: "/entries/food/pizza", Token: "unknown"}.do(s)
+	Equal(t, http.StatusUnauthorized, w.Code, "expecting requests with an unknown token to be unauthorized")
+
+	w = testRequest{Path: "/entries/food/pizza?token=unknown"}.do(s)
+	Equal(t, http.StatusUnauthorized, w.Code, "expecting unknown tokens given as a query parameter to be unauthorized")
+
+	w = testRequest{Path: "/entries/food/pizza", Token: "reader"}.do(s)
+	Equal(t, http.StatusOK, w.Code)
+
+	w = testRequest{Path: "/entries/food/pizza?token=reader"}.do(s)
+	Equal(t, http.StatusOK, w.Code, "expecting tokens to be accepted as a query parameter")
+
+	writes := []testRequest{
+		{Method: "POST", Path: "/entries/food/burger", ContentType: "application/json", Body: `{"contents": "A burger."}`},
+		{Method: "PUT", Path: "/entries/food/pizza", ContentType: "application/json", Body: `{"contents": "A pizza."}`, Header: map[string]string{"If-Match": "*"}},
+		{Method: "DELETE", Path: "/entries/food/pizza"},
+		{Method: "POST", Path: "/attachments/food/pizza", ContentType: "multipart/form-data; boundary=x", Body: "--x--"},
+	}
+
+	for _, req := range writes {
+		req.Token = "reader"
+		w = req.do(s)
+		Equal(t, http.StatusForbidden, w.Code, "expecting read-only grants to be forbidden from %s %s", req.Method, req.Path)
+
+		req.Token = ""
+		w = req.do(s)
+		Equal(t, http.StatusUnauthorized, w.Code, "expecting %s %s without a token to be unauthorized", req.Method, req.Path)
+	}
+}
+
+func TestACLHidesEntries(t *testing.T) {
+	s := testServer(t, func(s *Server) {
+		s.SetGraphQL(true)
+		setACL(t, s,
+			Grant{Name: "all", Token: "all", Paths: []string{""}},
+			Grant{Name: "food", Token: "food", Paths: []string{"food"}},
+			Grant{Name: "journal", Token: "journal", Paths: []string{"journal"}},
+		)
+	})
+
+	w := testRequest{Path: "/search?sort=path", Token: "food"}.do(s)
+	if Equal(t, http.StatusOK, w.Code) {
+		var result searchResponse
+		NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+
+		Equal(t, 2, result.Matched)
+		for _, entry := range result.Entries {
+			True(t, strings.HasPrefix(entry.Path, "food/"), "expecting %s not to be searched", entry.Path)
+		}
+	}
+
+	w = testRequest{Path: "/entries/journal/2020-08-06", Token: "food"}.do(s)
+	Equal(t, http.StatusNotFound, w.Code, "expecting entries outside the grant not to be found")
+
+	w = testRequest{Path: "/attachments/journal/2020-08-06", Token: "food"}.do(s)
+	Equal(t, http.StatusNotFound, w.Code, "expecting attachments of entries outside the grant not to be found")
+
+	w = testRequest{Path: "/folders", Token: "journal"}.do(s)
+	if Equal(t, http.StatusOK, w.Code) {
+		var result foldersResponse
+		NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		Empty(t, result.Folders, "expecting folders without any entries the grant can see not to be listed")
+	}
+
+	w = testRequest{Path: "/folders", Token: "food"}.do(s)
+	if Equal(t, http.StatusOK, w.Code) {
+		var result foldersResponse
+		NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		Len(t, result.Folders, 1)
+	}
+
+	w = testRequest{Path: "/stats", Token: "food"}.do(s)
+	if Equal(t, http.StatusOK, w.Code) {
+		var result struct{ Entries int }
+		NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		Equal(t, 2, result.Entries, "expecting stats to only count entries the grant can see")
+	}
+
+	query := `{"query": "{ search { matched entries { path } } journal: entry(path: \"journal/2020-08-06\") { title } }"}`
+	w = testRequest{Method: "POST", Path: "/graphql", Token: "food", ContentType: "application/json", Body: query}.do(s)
+	if Equal(t, http.StatusOK, w.Code) {
+		var result struct {
+			Data struct {
+				Search struct {
+					Matched int
+					Entries []struct{ Path string }
+				}
+				Journal *struct{ Title string }
+			}
+		}
+		NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+
+		Equal(t, 2, result.Data.Search.Matched)
+		for _, entry := range result.Data.Search.Entries {
+			True(t, strings.HasPrefix(entry.Path, "food/"), "expecting %s not to be searched using GraphQL", entry.Path)
+		}
+		Nil(t, result.Data.Journal, "expecting entries outside the grant not to be found using GraphQL")
+	}
+}
+
+func TestACLSearchCache(t *testing.T) {
+	s := testServer(t, func(s *Server) {
+		setACL(t, s,
+			Grant{Name: "all", Token: "all", Paths: []string{""}},
+			Grant{Name: "food", Token: "food", Paths: []string{"food"}},
+		)
+	})
+
+	matched := func(req testRequest) int {
+		w := req.do(s)
+		if !Equal(t, http.StatusOK, w.Code) {
+			return -1
+		}
+
+		var result searchResponse
+		NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		return result.Matched
+	}
+
+	all := matched(testRequest{Path: "/search?sort=path", Token: "all"})
+	Greater(t, all, 2)
+
+	Equal(t, 2, matched(testRequest{Path: "/search?sort=path", Token: "food"}), "expecting grants not to be given cached responses for other grants")
+	Equal(t, 2, matched(testRequest{Path: "/search?sort=path&token=food"}), "expecting tokens given as a query parameter not to share cached responses")
+	Equal(t, all, matched(testRequest{Path: "/search?sort=path", Token: "all"}))
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+)
+
+// reAttachmentHash matches the hashes attachments are downloaded by.
+var reAttachmentHash = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// attachment describes a file attached to an entry.
+type attachment struct {
+	// Name is the path of the file relative to the entry's folder, such as "pizza.jpg" or "photos/slice.jpg".
+	Name string `json:"name"`
+
+	// Size is the size of the file in bytes.
+	Size int64 `json:"size"`
+
+	// Hash is the SHA-256 hash of the file's contents, in hex.
+	Hash string `json:"hash"`
+
+	// URL is where the file can be downloaded from, relative to the server.
+	URL string `json:"url"`
+}
+
+// attachmentsResponse is the response listing the files attached to an entry.
+type attachmentsResponse struct {
+	// Path is the path of the entry.
+	Path string `json:"path"`
+
+	// Attachments are the files attached to the entry, sorted by name.
+	Attachments []attachment `json:"attachments"`
+}
+
+// hashCache remembers the hashes of files so that they don't need to be read again unless they change. It is safe for
+// concurrent use.
+type hashCache struct {
+	mu     sync.Mutex
+	hashes map[string]cachedHash
+}
+
+// cachedHash is the hash of a file when it had the size and modification time given.
+type cachedHash struct {
+	size    int64
+	modTime time.Time
+	hash    string
+}
+
+// newHashCache returns a new, initialised hashCache.
+func newHashCache() *hashCache {
+	return &hashCache{hashes: make(map[string]cachedHash)}
+}
+
+// hash returns the SHA-256 hash of the file given, in hex, and its size.
+func (h *hashCache) hash(file string) (string, int64, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return "", 0, err
+	}
+
+	h.mu.Lock()
+	cached, ok := h.hashes[file]
+	h.mu.Unlock()
+
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.hash, cached.size, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	sum := sha256.New()
+
+	_, err = io.Copy(sum, f)
+	if err != nil {
+		return "", 0, err
+	}
+
+	hash := fmt.Sprintf("%x", sum.Sum(nil))
+
+	h.mu.Lock()
+	h.hashes[file] = cachedHash{size: info.Size(), modTime: info.ModTime(), hash: hash}
+	h.mu.Unlock()
+
+	return hash, info.Size(), nil
+}
+
+// attachments returns the files attached to the entry at the path given, along with where their contents are. Large
+// attachments whose contents aren't available are left out.
+func (s *Server) attachments(entryPath string) ([]attachment, []string, error) {
+	names, err := s.store.Attachments(entryPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attachments := []attachment{}
+	files := []string{}
+
+	for _, name := range names {
+		file, err := s.store.ResolveAttachment(filepath.FromSlash(path.Join(entryPath, name)))
+
+		var missing albatross.ErrAttachmentMissing
+		if errors.As(err, &missing) {
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+
+		hash, size, err := s.hashes.hash(file)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		attachments = append(attachments, attachment{Name: name, Size: size, Hash: hash, URL: "/files/" + hash})
+		files = append(files, file)
+	}
+
+	return attachments, files, nil
+}
+
+// checkAttachmentsAvailable returns true if the server can read attachments. Otherwise, it aborts the request.
+func (s *Server) checkAttachmentsAvailable(c *gin.Context) bool {
+	if s.store == nil {
+		c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{
+			"error_type": "attachments unavailable",
+			"error":      "server was not started with access to a store",
+		})
+		return false
+	}
+
+	return true
+}
+
+// attachmentsHandler handles requests for the list of files attached to an entry, such as GET /attachments/food/pizza.
+func (s *Server) attachmentsHandler(c *gin.Context) {
+	if !s.checkAttachmentsAvailable(c) {
+		return
+	}
+
+	entryPath := strings.Trim(c.Param("path"), "/")
+
+	entry := s.getCollection().Get(entryPath)
+	if entry == nil || !allowed(c, entry.Path) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error_type": "entry not found",
+			"error":      "no entry with path " + entryPath,
+		})
+		return
+	}
+
+	attachments, _, err := s.attachments(entry.Path)
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attachmentsResponse{Path: entry.Path, Attachments: attachments})
+}
+
+// fileHandler handles requests to download an attached file by the hash of its contents, such as
+// GET /files/9f86d08.... Only files attached to entries the request can access are found. Since the contents of a file
+// can't change without its hash changing, responses can be cached forever.
+func (s *Server) fileHandler(c *gin.Context) {
+	if !s.checkAttachmentsAvailable(c) {
+		return
+	}
+
+	hash := strings.ToLower(c.Param("hash"))
+	if !reAttachmentHash.MatchString(hash) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error_type": "invalid hash",
+			"error":      fmt.Sprintf("%q isn't a SHA-256 hash", c.Param("hash")),
+		})
+		return
+	}
+
+	collection, err := s.requestCollection(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error_type": "error filtering collection",
+			"error":      err.Error(),
+		})
+		return
+	}
+
+	for _, entry := range collection.List().Slice() {
+		attachments, files, err := s.attachments(entry.Path)
+		if err != nil {
+			storeError(c, err)
+			return
+		}
+
+		for i, attachment := range attachments {
+			if attachment.Hash != hash {
+				continue
+			}
+
+			c.Header("ETag", `"`+hash+`"`)
+			c.Header("Cache-Control", "private, max-age=31536000, immutable")
+			c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": path.Base(attachment.Name)}))
+
+			// http.ServeContent, which this uses, handles range and conditional requests.
+			c.File(files[i])
+			return
+		}
+	}
+
+	c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+		"error_type": "file not found",
+		"error":      "no attachment with hash " + hash,
+	})
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"container/list"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// cachedResponse is a response stored in the cache.
+type cachedResponse struct {
+	status int
+	body   interface{}
+
+	etag         string
+	lastModified time.Time
+}
+
+// cacheItem is an item in the responseCache's eviction list.
+type cacheItem struct {
+	key      string
+	response cachedResponse
+}
+
+// responseCache is a fixed-size, least-recently-used cache of responses. It is safe for concurrent use.
+// A cache with a size of zero stores nothing.
+type responseCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+// newResponseCache returns a new, initialised responseCache which holds at most size responses.
+func newResponseCache(size int) *responseCache {
+	return &responseCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// get returns the response stored for the key, and whether one was found.
+func (c *responseCache) get(key string) (cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return cachedResponse{}, false
+	}
+
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheItem).response, true
+}
+
+// put stores a response for the key, evicting the least recently used response if the cache is full.
+func (c *responseCache) put(key string, response cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.size <= 0 {
+		return
+	}
+
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*cacheItem).response = response
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&cacheItem{key: key, response: response})
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheItem).key)
+	}
+}
+
+// resize changes the maximum number of responses held, purging the cache.
+func (c *responseCache) resize(size int) {
+	c.mu.Lock()
+	c.size = size
+	c.mu.Unlock()
+
+	c.purge()
+}
+
+// purge removes every response from the cache.
+func (c *responseCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+// normaliseQuery converts query parameters into a cache key. Parameters are sorted by name and the values for each
+// parameter are also sorted, since all the search filters are combined using AND and so their order doesn't matter.
+// Parameters in ignore, such as access tokens, are left out of the key.
+func normaliseQuery(values url.Values, ignore ...string) string {
+	normalised := url.Values{}
+
+	for key, vals := range values {
+		normalised[key] = append([]string{}, vals...)
+		sort.Strings(normalised[key])
+	}
+
+	for _, key := range ignore {
+		normalised.Del(key)
+	}
+
+	// url.Values.Encode sorts by key.
+	return normalised.Encode()
+}
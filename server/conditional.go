@@ -0,0 +1,96 @@
+package server
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/gin-gonic/gin"
+)
+
+// entryHash returns a hash of an entry's path and contents.
+func entryHash(entry *entries.Entry) string {
+	h := sha1.New()
+	_, _ = h.Write([]byte(entry.Path))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(entry.OriginalContents))
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// entryETag returns a strong ETag for a single entry, derived from its content hash.
+func entryETag(entry *entries.Entry) string {
+	return `"` + entryHash(entry) + `"`
+}
+
+// listETag returns a strong ETag for a list of entries. It changes if any of the entries change, or if the order of
+// the entries or the total number matched changes.
+func listETag(list []*entries.Entry, matched int) string {
+	h := sha1.New()
+	_, _ = fmt.Fprintf(h, "%d", matched)
+
+	for _, entry := range list {
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(entryHash(entry)))
+	}
+
+	return fmt.Sprintf(`"%x"`, h.Sum(nil))
+}
+
+// lastModified returns the most recent modification time of the entries given. If the server has access to a store
+// using git, this is the time of the last commit changing the entry. Otherwise it is the modification time of the file.
+func (s *Server) lastModified(list ...*entries.Entry) time.Time {
+	var latest time.Time
+
+	for _, entry := range list {
+		modified := entry.ModTime
+
+		if s.store != nil {
+			committed, err := s.store.LastModified(entry.Path)
+			if err == nil && !committed.IsZero() {
+				modified = committed
+			}
+		}
+
+		if modified.After(latest) {
+			latest = modified
+		}
+	}
+
+	return latest
+}
+
+// notModified sets the ETag and Last-Modified headers for the response and returns true if the request's conditional
+// headers (If-None-Match or If-Modified-Since) mean the client already has the current version. In that case a
+// 304 Not Modified response has already been written.
+func notModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	c.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since, see RFC 7232 section 6.
+	if inm := c.GetHeader("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				c.AbortWithStatus(http.StatusNotModified)
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if ims := c.GetHeader("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err == nil && !lastModified.Truncate(time.Second).After(since) {
+			c.AbortWithStatus(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultCORSOrigins are the origins allowed to make cross-origin requests to a server by default.
+var DefaultCORSOrigins = []string{"https://cdpn.io"}
+
+// CORSMiddleware returns middleware which allows cross-origin requests from the origins given. Origins must include
+// the scheme, like "https://example.com", and may contain a single wildcard, like "https://*.example.com". The special
+// origin "*" allows requests from anywhere. If no origins are given, no CORS headers are sent.
+func CORSMiddleware(origins []string) (gin.HandlerFunc, error) {
+	if len(origins) == 0 {
+		return func(c *gin.Context) {}, nil
+	}
+
+	config := cors.Config{
+		AllowOrigins:  origins,
+		AllowWildcard: true,
+		AllowMethods:  []string{"GET", "HEAD", "OPTIONS", "POST", "PUT", "DELETE"},
+		AllowHeaders:  []string{"Authorization", "Content-Type", "If-Match", "If-None-Match", "If-Modified-Since"},
+		ExposeHeaders: []string{"ETag", "Last-Modified"},
+	}
+
+	for _, origin := range origins {
+		if origin == "*" {
+			// cors doesn't allow a list of origins as well as allowing every origin.
+			config.AllowOrigins = nil
+			config.AllowAllOrigins = true
+		}
+	}
+
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return cors.New(config), nil
+}
+
+// EmbedMiddleware returns middleware which controls which pages may embed responses, such as in an iframe, using the
+// Content-Security-Policy frame-ancestors directive. Ancestors are sources like "https://example.com" or
+// "https://*.example.com", and "*" allows embedding anywhere. If no ancestors are given, embedding is denied.
+func EmbedMiddleware(ancestors []string) gin.HandlerFunc {
+	policy := "frame-ancestors " + frameAncestors(ancestors)
+
+	return func(c *gin.Context) {
+		c.Header("Content-Security-Policy", policy)
+
+		if len(ancestors) == 0 {
+			// Older browsers don't understand frame-ancestors.
+			c.Header("X-Frame-Options", "DENY")
+		}
+	}
+}
+
+// frameAncestors returns the source list for a frame-ancestors directive allowing the ancestors given.
+func frameAncestors(ancestors []string) string {
+	if len(ancestors) == 0 {
+		return "'none'"
+	}
+
+	for _, ancestor := range ancestors {
+		if ancestor == "*" {
+			return "*"
+		}
+	}
+
+	return "'self' " + strings.Join(ancestors, " ")
+}
+
+// corsMiddleware applies the server's current CORS configuration.
+func (s *Server) corsMiddleware(c *gin.Context) {
+	s.cors(c)
+}
+
+// embedMiddleware applies the server's current embedding configuration.
+func (s *Server) embedMiddleware(c *gin.Context) {
+	s.embed(c)
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	s := testServer(t, nil)
+
+	w := testRequest{Path: "/stats", Header: map[string]string{"Origin": "https://cdpn.io"}}.do(s)
+	Equal(t, "https://cdpn.io", w.Header().Get("Access-Control-Allow-Origin"), "expecting the default origins to be allowed")
+
+	w = testRequest{Path: "/stats", Header: map[string]string{"Origin": "https://evil.example.com"}}.do(s)
+	Empty(t, w.Header().Get("Access-Control-Allow-Origin"), "expecting other origins not to be allowed")
+
+	NoError(t, s.SetCORS([]string{"https://*.example.com"}))
+
+	w = testRequest{Path: "/stats", Header: map[string]string{"Origin": "https://dash.example.com"}}.do(s)
+	Equal(t, "https://dash.example.com", w.Header().Get("Access-Control-Allow-Origin"), "expecting wildcard origins to match")
+
+	w = testRequest{Path: "/stats", Header: map[string]string{"Origin": "https://cdpn.io"}}.do(s)
+	Empty(t, w.Header().Get("Access-Control-Allow-Origin"), "expecting the default origins to be replaced")
+
+	w = testRequest{Method: "OPTIONS", Path: "/entries/food/pizza", Header: map[string]string{
+		"Origin":                         "https://dash.example.com",
+		"Access-Control-Request-Method":  "PUT",
+		"Access-Control-Request-Headers": "Authorization, If-Match",
+	}}.do(s)
+	Equal(t, "https://dash.example.com", w.Header().Get("Access-Control-Allow-Origin"), "expecting preflight requests to be allowed")
+	Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "PUT")
+
+	NoError(t, s.SetCORS([]string{"*"}))
+
+	w = testRequest{Path: "/stats", Header: map[string]string{"Origin": "https://anywhere.example.org"}}.do(s)
+	Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"), "expecting '*' to allow every origin")
+
+	NoError(t, s.SetCORS(nil))
+
+	w = testRequest{Path: "/stats", Header: map[string]string{"Origin": "https://cdpn.io"}}.do(s)
+	Empty(t, w.Header().Get("Access-Control-Allow-Origin"), "expecting no origins to disable CORS")
+
+	Error(t, s.SetCORS([]string{"example.com"}), "expecting origins without a scheme to be rejected")
+}
+
+func TestAllowEmbed(t *testing.T) {
+	s := testServer(t, nil)
+
+	w := testRequest{Path: "/stats"}.do(s)
+	Equal(t, "frame-ancestors 'none'", w.Header().Get("Content-Security-Policy"), "expecting embedding to be denied by default")
+	Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+
+	s.SetAllowEmbed([]string{"https://home.example.com", "https://*.example.org"})
+
+	w = testRequest{Path: "/stats"}.do(s)
+	Equal(t, "frame-ancestors 'self' https://home.example.com https://*.example.org", w.Header().Get("Content-Security-Policy"))
+	Empty(t, w.Header().Get("X-Frame-Options"))
+
+	s.SetAllowEmbed([]string{"https://home.example.com", "*"})
+
+	w = testRequest{Path: "/stats"}.do(s)
+	Equal(t, "frame-ancestors *", w.Header().Get("Content-Security-Policy"), "expecting '*' to allow embedding anywhere")
+}
+
+func TestSiteHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "albatross-site-test")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte("<h1>Notes</h1>"), 0644)
+	if err != nil {
+		t.Fatalf("couldn't write index.html: %s", err)
+	}
+
+	_, err = SiteHandler(dir, []string{"example.com"}, nil)
+	Error(t, err, "expecting origins without a scheme to be rejected")
+
+	handler, err := SiteHandler(dir, []string{"https://dash.example.com"}, []string{"https://dash.example.com"})
+	if !NoError(t, err) {
+		return
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Origin", "https://dash.example.com")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	Equal(t, http.StatusOK, w.Code)
+	Equal(t, "<h1>Notes</h1>", w.Body.String())
+	Equal(t, "https://dash.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	Equal(t, "frame-ancestors 'self' https://dash.example.com", w.Header().Get("Content-Security-Policy"))
+
+	req = httptest.NewRequest("GET", "/missing.html", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	Equal(t, http.StatusNotFound, w.Code)
+	Equal(t, "frame-ancestors 'self' https://dash.example.com", w.Header().Get("Content-Security-Policy"))
+
+	handler, err = SiteHandler(dir, nil, nil)
+	if !NoError(t, err) {
+		return
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Origin", "https://dash.example.com")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	Equal(t, http.StatusOK, w.Code)
+	Empty(t, w.Header().Get("Access-Control-Allow-Origin"), "expecting cross-origin requests not to be allowed by default")
+	Equal(t, "frame-ancestors 'none'", w.Header().Get("Content-Security-Policy"))
+}
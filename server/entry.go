@@ -0,0 +1,37 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/gin-gonic/gin"
+)
+
+// entryHandler handles requests for a single entry by its path, such as /entries/food/pizza.
+func (s *Server) entryHandler(c *gin.Context) {
+	path := strings.Trim(c.Param("path"), "/")
+
+	entry := s.getCollection().ResolveLink(entries.Link{Path: path, Type: entries.LinkPathNoName})
+	if entry == nil || !allowed(c, entry.Path) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error_type": "entry not found",
+			"error":      "no entry with path " + path,
+		})
+		return
+	}
+
+	if s.trackViews && s.store != nil {
+		err := s.store.RecordView(entry.Path)
+		if err != nil {
+			// Not being able to record the view isn't worth failing the request over, but it's logged by gin.
+			_ = c.Error(err)
+		}
+	}
+
+	if notModified(c, entryETag(entry), s.lastModified(entry)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
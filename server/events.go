@@ -0,0 +1,186 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/gin-gonic/gin"
+)
+
+// The types of change an event can describe.
+const (
+	eventCreated = "created"
+	eventUpdated = "updated"
+	eventDeleted = "deleted"
+)
+
+// eventsHeartbeat is how often a comment is sent to clients listening for events when nothing has changed, so that
+// proxies don't close the connection for being idle.
+const eventsHeartbeat = 30 * time.Second
+
+// eventsBuffer is the number of events which can be waiting to be sent to a client. Clients which fall further behind
+// than this are disconnected, and should read the entries again when they reconnect.
+const eventsBuffer = 64
+
+// entryEvent describes a change to an entry being served. It is sent as the data of a server-sent event whose event
+// name is the type of the change.
+type entryEvent struct {
+	// Type is the type of change, "created", "updated" or "deleted".
+	Type string `json:"type"`
+
+	// Path is the path of the entry which changed.
+	Path string `json:"path"`
+
+	// ETag is the entry's ETag after the change, so that clients can tell whether they already have it. It's blank for
+	// deleted entries.
+	ETag string `json:"etag,omitempty"`
+
+	id uint64
+}
+
+// eventHub passes events to the clients listening for them. It is safe for concurrent use.
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[chan entryEvent]bool
+	lastID      uint64
+}
+
+// newEventHub returns a new, initialised eventHub.
+func newEventHub() *eventHub {
+	return &eventHub{subscribers: make(map[chan entryEvent]bool)}
+}
+
+// subscribe returns a channel which receives every event published from now on. It is closed if the subscriber falls
+// too far behind.
+func (h *eventHub) subscribe() chan entryEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	events := make(chan entryEvent, eventsBuffer)
+	h.subscribers[events] = true
+
+	return events
+}
+
+// unsubscribe stops sending events to a channel returned by subscribe.
+func (h *eventHub) unsubscribe(events chan entryEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscribers[events] {
+		delete(h.subscribers, events)
+		close(events)
+	}
+}
+
+// listening returns true if anything is subscribed, so that working out what changed can be skipped if not.
+func (h *eventHub) listening() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.subscribers) != 0
+}
+
+// publish sends events to every subscriber, giving each one an ID. Subscribers which can't keep up are dropped rather
+// than holding up the others.
+func (h *eventHub) publish(events []entryEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, event := range events {
+		h.lastID++
+		event.id = h.lastID
+
+		for subscriber := range h.subscribers {
+			select {
+			case subscriber <- event:
+			default:
+				delete(h.subscribers, subscriber)
+				close(subscriber)
+			}
+		}
+	}
+}
+
+// collectionEvents returns events for the changes between two versions of the collection being served, in order of
+// path. Entries which have left the collection, such as because they no longer match the server's filter, are deleted
+// as far as clients are concerned.
+func collectionEvents(old, new *entries.Collection) []entryEvent {
+	events := []entryEvent{}
+
+	for _, entry := range new.List().Slice() {
+		previous := old.Get(entry.Path)
+
+		switch {
+		case previous == nil:
+			events = append(events, entryEvent{Type: eventCreated, Path: entry.Path, ETag: entryETag(entry)})
+		case previous.OriginalContents != entry.OriginalContents:
+			events = append(events, entryEvent{Type: eventUpdated, Path: entry.Path, ETag: entryETag(entry)})
+		}
+	}
+
+	for _, entry := range old.List().Slice() {
+		if new.Get(entry.Path) == nil {
+			events = append(events, entryEvent{Type: eventDeleted, Path: entry.Path})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Path < events[j].Path })
+
+	return events
+}
+
+// eventsHandler handles requests to listen for changes to entries, streaming them as server-sent events until the
+// client disconnects. Each event is named after the type of change and its data is JSON such as
+// {"type": "updated", "path": "food/pizza", "etag": "..."}. Only changes to entries the request can access are sent.
+//
+// Events are only sent for changes the server knows about, so it needs to be watching the store or allow writes.
+func (s *Server) eventsHandler(c *gin.Context) {
+	events := s.events.subscribe()
+	defer s.events.unsubscribe(events)
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Sending something straight away lets clients know they're connected before anything changes.
+	fmt.Fprint(c.Writer, ": listening for changes\n\n")
+	c.Writer.Flush()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			if !allowed(c, event.Path) {
+				continue
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				return
+			}
+
+			fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.id, event.Type, data)
+		}
+
+		c.Writer.Flush()
+	}
+}
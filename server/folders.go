@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/gin-gonic/gin"
+)
+
+// foldersResponse is the response given by /folders.
+type foldersResponse struct {
+	// Folders are the folders with a _meta.yaml file which contain at least one of the entries being served, sorted by
+	// path.
+	Folders []folder `json:"folders"`
+}
+
+// folder is a folder described by its _meta.yaml file.
+type folder struct {
+	entries.Folder
+
+	// Titles are the titles of every folder leading to this one, including itself, such as ["School", "A-Level"] for
+	// "school/a-level". Folders are titled by the entry at their path, then their _meta.yaml file, then their name.
+	Titles []string `json:"titles"`
+}
+
+// foldersHandler handles requests for the folders which are described by a _meta.yaml file, so that sections of the
+// store can be labelled using their titles rather than their names. Folders which don't contain any of the entries a
+// request can see aren't listed, so that their names aren't given away.
+func (s *Server) foldersHandler(c *gin.Context) {
+	collection, err := s.requestCollection(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error_type": "error filtering collection",
+			"error":      err.Error(),
+		})
+		return
+	}
+
+	nonEmpty := map[string]bool{}
+	for _, entry := range collection.List().Slice() {
+		parts := strings.Split(entry.Path, "/")
+		for i := 1; i < len(parts); i++ {
+			nonEmpty[strings.Join(parts[:i], "/")] = true
+		}
+	}
+
+	response := foldersResponse{Folders: []folder{}}
+	for _, f := range collection.Folders() {
+		if !nonEmpty[f.Path] {
+			continue
+		}
+
+		response.Folders = append(response.Folders, folder{Folder: *f, Titles: collection.FolderTitles(f.Path)})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,893 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// graphQLMaxDepth is the deepest a query's fields can be nested, so that a query like
+// "{ entry { backlinks { backlinks { ... } } } }" can't make the server do an unbounded amount of work.
+const graphQLMaxDepth = 12
+
+// graphQLRequest is the body of a request to /graphql, as described by https://graphql.org/learn/serving-over-http/.
+type graphQLRequest struct {
+	// Query is the GraphQL document.
+	Query string `json:"query"`
+
+	// OperationName is the name of the operation to run, if the document contains more than one.
+	OperationName string `json:"operationName,omitempty"`
+
+	// Variables are the values of the variables used by the operation.
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// graphQLResponse is the response to a GraphQL request.
+type graphQLResponse struct {
+	// Data is the result of the operation. It's nil if the request couldn't be run at all.
+	Data interface{} `json:"data,omitempty"`
+
+	// Errors are the errors which happened, if any. Fields which gave an error are null in Data.
+	Errors []*gqlError `json:"errors,omitempty"`
+}
+
+// gqlObject is an object type in a GraphQL schema, such as Entry.
+type gqlObject struct {
+	Name        string
+	Description string
+	Fields      []*gqlField
+}
+
+// field returns the field with the name given, or nil if there isn't one.
+func (o *gqlObject) field(name string) *gqlField {
+	for _, field := range o.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+
+	return nil
+}
+
+// gqlField is a field of an object type.
+type gqlField struct {
+	Name        string
+	Description string
+	Arguments   []gqlInputValue
+	Type        *gqlTypeRef
+
+	// Resolve returns the value of the field for the parent value given, which is the value of the object the field
+	// belongs to. For the fields of Query, the parent is nil. Arguments have been coerced to the types of the field's
+	// arguments, with defaults filled in.
+	Resolve func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error)
+}
+
+// gqlInputValue is an argument to a field.
+type gqlInputValue struct {
+	Name        string
+	Description string
+	Type        *gqlTypeRef
+
+	// Default is the value used if the argument isn't given. It's only used if HasDefault is true.
+	Default    interface{}
+	HasDefault bool
+}
+
+// gqlSchema is a GraphQL schema. Only queries are supported.
+type gqlSchema struct {
+	Query   *gqlObject
+	Objects map[string]*gqlObject
+
+	// Scalars are the scalar types, along with the description of each.
+	Scalars map[string]string
+}
+
+// gqlContext is the context a request is executed in.
+type gqlContext struct {
+	// Gin is the context of the HTTP request.
+	Gin *gin.Context
+
+	// Server is the server handling the request.
+	Server *Server
+
+	schema    *gqlSchema
+	doc       *gqlDocument
+	operation *gqlOperation
+	variables map[string]interface{}
+	errors    []*gqlError
+
+	// Values can be used by resolvers to keep things they need for the whole request, such as the collection of
+	// entries the request can access.
+	Values map[string]interface{}
+}
+
+// gqlOrderedMap is a JSON object whose keys are kept in the order they were added, so that fields are given in the
+// order they were requested.
+type gqlOrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// set adds a key to the map, keeping its original position if it was already there.
+func (m *gqlOrderedMap) set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+
+	m.values[key] = value
+}
+
+// MarshalJSON encodes the map as a JSON object.
+func (m *gqlOrderedMap) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+
+	for i, key := range m.keys {
+		if i != 0 {
+			b.WriteByte(',')
+		}
+
+		keyJSON, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		valueJSON, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(keyJSON)
+		b.WriteByte(':')
+		b.Write(valueJSON)
+	}
+
+	b.WriteByte('}')
+
+	return []byte(b.String()), nil
+}
+
+// executeGraphQL parses and runs a GraphQL request against the schema. Errors in the request itself, such as syntax
+// errors or fields which don't exist, are returned as the error, in which case nothing is run. Errors while running the
+// request are given in the response instead.
+func executeGraphQL(schema *gqlSchema, ctx *gqlContext, req graphQLRequest) (graphQLResponse, error) {
+	doc, err := parseGraphQL(req.Query)
+	if err != nil {
+		return graphQLResponse{}, err
+	}
+
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return graphQLResponse{}, err
+	}
+
+	if op.Type != "query" {
+		return graphQLResponse{}, newGQLError(op.line, op.col, "only queries are supported, not %ss", op.Type)
+	}
+
+	ctx.schema = schema
+	ctx.doc = doc
+	ctx.operation = op
+
+	ctx.variables, err = coerceVariables(schema, op, req.Variables)
+	if err != nil {
+		return graphQLResponse{}, err
+	}
+
+	err = ctx.validate(op.Selections, schema.Query, 1, map[string]bool{})
+	if err != nil {
+		return graphQLResponse{}, err
+	}
+
+	resp := graphQLResponse{}
+
+	// If a non-null field of Query was null, the whole of the data is null.
+	data, ok := ctx.executeSelections(schema.Query, nil, op.Selections, []interface{}{})
+	if ok {
+		resp.Data = data
+	}
+	resp.Errors = ctx.errors
+
+	return resp, nil
+}
+
+// selectOperation returns the operation in the document with the name given. The name can be blank if there's only
+// one operation.
+func selectOperation(doc *gqlDocument, name string) (*gqlOperation, error) {
+	if name == "" {
+		if len(doc.Operations) != 1 {
+			return nil, newGQLError(0, 0, "operationName is required when a document contains more than one operation")
+		}
+
+		return doc.Operations[0], nil
+	}
+
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+
+	return nil, newGQLError(0, 0, "there's no operation named %q", name)
+}
+
+// coerceVariables checks the values given for the operation's variables against their types, filling in defaults.
+func coerceVariables(schema *gqlSchema, op *gqlOperation, values map[string]interface{}) (map[string]interface{}, error) {
+	coerced := map[string]interface{}{}
+
+	for _, definition := range op.Variables {
+		if !schema.isInputType(definition.Type) {
+			return nil, newGQLError(op.line, op.col, "variable $%s has type %s, which isn't an input type", definition.Name, definition.Type)
+		}
+
+		value, ok := values[definition.Name]
+
+		switch {
+		case !ok && definition.HasDefault:
+			value, err := coerceInput(definition.Type, definition.Default, nil)
+			if err != nil {
+				return nil, newGQLError(op.line, op.col, "default value of $%s: %s", definition.Name, err)
+			}
+
+			coerced[definition.Name] = value
+
+		case (!ok || value == nil) && definition.Type.NonNull:
+			return nil, newGQLError(op.line, op.col, "variable $%s of type %s is required", definition.Name, definition.Type)
+
+		case ok:
+			value, err := coerceInput(definition.Type, value, nil)
+			if err != nil {
+				return nil, newGQLError(op.line, op.col, "variable $%s: %s", definition.Name, err)
+			}
+
+			coerced[definition.Name] = value
+		}
+	}
+
+	return coerced, nil
+}
+
+// isInputType returns true if the type can be used for arguments and variables, which is only true of scalars and lists
+// of them.
+func (s *gqlSchema) isInputType(t *gqlTypeRef) bool {
+	if t.List != nil {
+		return s.isInputType(t.List)
+	}
+
+	_, ok := s.Scalars[t.Name]
+
+	return ok
+}
+
+// coerceInput converts a value given for an argument or variable to the type given, as described in the "Input
+// Coercion" sections of the specification. Variables in the value are replaced using the variables given.
+func coerceInput(t *gqlTypeRef, value interface{}, variables map[string]interface{}) (interface{}, error) {
+	if variable, ok := value.(gqlVariable); ok {
+		value, ok = variables[string(variable)]
+		if !ok {
+			value = nil
+		}
+	}
+
+	if value == nil {
+		if t.NonNull {
+			return nil, fmt.Errorf("expected a value of type %s, not null", t)
+		}
+
+		return nil, nil
+	}
+
+	if t.List != nil {
+		list, ok := value.([]interface{})
+		if !ok {
+			// A single value is allowed where a list is expected, and is treated as a list containing just that value.
+			list = []interface{}{value}
+		}
+
+		coerced := []interface{}{}
+
+		for _, item := range list {
+			item, err := coerceInput(t.List, item, variables)
+			if err != nil {
+				return nil, err
+			}
+
+			coerced = append(coerced, item)
+		}
+
+		return coerced, nil
+	}
+
+	switch t.Name {
+	case "String", "ID":
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+
+	case "Int":
+		switch n := value.(type) {
+		case int:
+			if n >= math.MinInt32 && n <= math.MaxInt32 {
+				return n, nil
+			}
+		case float64:
+			// Numbers in JSON variables are decoded as floats.
+			if n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32 {
+				return int(n), nil
+			}
+		}
+
+	case "Float":
+		switch n := value.(type) {
+		case int:
+			return float64(n), nil
+		case float64:
+			return n, nil
+		}
+
+	case "Boolean":
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	}
+
+	return nil, fmt.Errorf("expected a value of type %s, not %s", t, describeInput(value))
+}
+
+// describeInput describes a value given as input in an error message.
+func describeInput(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", value)
+	case gqlEnum:
+		return string(value)
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "an object"
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// validate checks the selections made on the object given before anything is run, such as that the fields exist and
+// their arguments are known. depth is how deeply nested the selections are.
+func (ctx *gqlContext) validate(selections []gqlSelection, object *gqlObject, depth int, fragments map[string]bool) error {
+	if depth > graphQLMaxDepth {
+		return newGQLError(selections[0].line, selections[0].col, "query is nested more than %d levels deep", graphQLMaxDepth)
+	}
+
+	for _, selection := range selections {
+		for _, directive := range selection.Directives {
+			if directive.Name != "include" && directive.Name != "skip" {
+				return newGQLError(directive.line, directive.col, "unknown directive @%s", directive.Name)
+			}
+
+			err := ctx.validateArguments(directive.Arguments, []gqlInputValue{{Name: "if", Type: &gqlTypeRef{Name: "Boolean", NonNull: true}}}, directive.line, directive.col)
+			if err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case selection.Fragment != "":
+			fragment := ctx.doc.Fragments[selection.Fragment]
+			if fragment == nil {
+				return newGQLError(selection.line, selection.col, "there's no fragment named %q", selection.Fragment)
+			}
+
+			if fragments[fragment.Name] {
+				return newGQLError(selection.line, selection.col, "fragment %q includes itself", fragment.Name)
+			}
+
+			err := ctx.validateTypeCondition(fragment.TypeCondition, object, selection.line, selection.col)
+			if err != nil {
+				return err
+			}
+
+			fragments[fragment.Name] = true
+			err = ctx.validate(fragment.Selections, object, depth, fragments)
+			delete(fragments, fragment.Name)
+
+			if err != nil {
+				return err
+			}
+
+		case selection.Inline:
+			err := ctx.validateTypeCondition(selection.TypeCondition, object, selection.line, selection.col)
+			if err != nil {
+				return err
+			}
+
+			err = ctx.validate(selection.Selections, object, depth, fragments)
+			if err != nil {
+				return err
+			}
+
+		case selection.Name == "__typename":
+			if len(selection.Selections) != 0 {
+				return newGQLError(selection.line, selection.col, "field \"__typename\" can't have a selection set")
+			}
+
+		default:
+			field := object.field(selection.Name)
+			if field == nil {
+				return newGQLError(selection.line, selection.col, "type %s doesn't have a field named %q", object.Name, selection.Name)
+			}
+
+			err := ctx.validateArguments(selection.Arguments, field.Arguments, selection.line, selection.col)
+			if err != nil {
+				return err
+			}
+
+			fieldObject := ctx.schema.Objects[namedType(field.Type).Name]
+
+			switch {
+			case fieldObject == nil && len(selection.Selections) != 0:
+				return newGQLError(selection.line, selection.col, "field %q has type %s, so can't have a selection set", selection.Name, field.Type)
+			case fieldObject != nil && len(selection.Selections) == 0:
+				return newGQLError(selection.line, selection.col, "field %q has type %s, so needs a selection set like { ... }", selection.Name, field.Type)
+			case fieldObject != nil:
+				err = ctx.validate(selection.Selections, fieldObject, depth+1, fragments)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateTypeCondition checks that a fragment's type condition is the type of the object it's used on, since there are
+// no interfaces or unions which would let it be anything else.
+func (ctx *gqlContext) validateTypeCondition(condition string, object *gqlObject, line, col int) error {
+	if condition != "" && condition != object.Name {
+		return newGQLError(line, col, "fragment on type %s can't be used on type %s", condition, object.Name)
+	}
+
+	return nil
+}
+
+// validateArguments checks that the arguments given are accepted and that required arguments have been given. Values
+// are checked when the field is run, since they may depend on variables.
+func (ctx *gqlContext) validateArguments(given []gqlArgument, accepted []gqlInputValue, line, col int) error {
+	for _, argument := range given {
+		found := false
+
+		for _, input := range accepted {
+			if input.Name == argument.Name {
+				found = true
+			}
+		}
+
+		if !found {
+			return newGQLError(line, col, "unknown argument %q", argument.Name)
+		}
+
+		err := ctx.validateVariables(argument.Value, line, col)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, input := range accepted {
+		if !input.Type.NonNull || input.HasDefault {
+			continue
+		}
+
+		found := false
+
+		for _, argument := range given {
+			if argument.Name == input.Name {
+				found = true
+			}
+		}
+
+		if !found {
+			return newGQLError(line, col, "argument %q of type %s is required", input.Name, input.Type)
+		}
+	}
+
+	return nil
+}
+
+// validateVariables checks that the variables used in a value have been defined by the operation being run.
+func (ctx *gqlContext) validateVariables(value interface{}, line, col int) error {
+	switch value := value.(type) {
+	case gqlVariable:
+		for _, definition := range ctx.operation.Variables {
+			if definition.Name == string(value) {
+				return nil
+			}
+		}
+
+		return newGQLError(line, col, "variable $%s isn't defined", value)
+
+	case []interface{}:
+		for _, item := range value {
+			err := ctx.validateVariables(item, line, col)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// namedType returns the type a list type is ultimately a list of, such as Entry for [[Entry!]].
+func namedType(t *gqlTypeRef) *gqlTypeRef {
+	for t.List != nil {
+		t = t.List
+	}
+
+	return t
+}
+
+// included returns false if a selection's @skip or @include directives mean it should be left out.
+func (ctx *gqlContext) included(selection gqlSelection) bool {
+	for _, directive := range selection.Directives {
+		value, err := coerceInput(&gqlTypeRef{Name: "Boolean", NonNull: true}, directive.Arguments[0].Value, ctx.variables)
+		if err != nil {
+			ctx.addError(newGQLError(directive.line, directive.col, "@%s: %s", directive.Name, err), nil)
+			return false
+		}
+
+		if (directive.Name == "skip") == value.(bool) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// collectFields returns the fields selected on an object by response key, in the order they should be given, including
+// those selected by fragments. Fields with the same response key have their selection sets merged.
+func (ctx *gqlContext) collectFields(selections []gqlSelection, keys *[]string, fields map[string][]gqlSelection) {
+	for _, selection := range selections {
+		if !ctx.included(selection) {
+			continue
+		}
+
+		switch {
+		case selection.Fragment != "":
+			ctx.collectFields(ctx.doc.Fragments[selection.Fragment].Selections, keys, fields)
+		case selection.Inline:
+			ctx.collectFields(selection.Selections, keys, fields)
+		default:
+			key := selection.responseKey()
+			if _, ok := fields[key]; !ok {
+				*keys = append(*keys, key)
+			}
+
+			fields[key] = append(fields[key], selection)
+		}
+	}
+}
+
+// executeSelections runs the selections on an object whose value is parent. It returns false if a non-null field was
+// null, in which case the object itself must be null.
+func (ctx *gqlContext) executeSelections(object *gqlObject, parent interface{}, selections []gqlSelection, path []interface{}) (*gqlOrderedMap, bool) {
+	keys := []string{}
+	fields := map[string][]gqlSelection{}
+	ctx.collectFields(selections, &keys, fields)
+
+	result := &gqlOrderedMap{values: map[string]interface{}{}}
+
+	for _, key := range keys {
+		selection := fields[key][0]
+		fieldPath := append(append([]interface{}{}, path...), key)
+
+		if selection.Name == "__typename" {
+			result.set(key, object.Name)
+			continue
+		}
+
+		field := object.field(selection.Name)
+
+		subSelections := []gqlSelection{}
+		for _, merged := range fields[key] {
+			subSelections = append(subSelections, merged.Selections...)
+		}
+
+		value, ok := ctx.executeField(field, parent, selection, subSelections, fieldPath)
+		if !ok {
+			return nil, false
+		}
+
+		result.set(key, value)
+	}
+
+	return result, true
+}
+
+// executeField resolves a field and completes its value. It returns false if the field is non-null but its value is
+// null.
+func (ctx *gqlContext) executeField(field *gqlField, parent interface{}, selection gqlSelection, subSelections []gqlSelection, path []interface{}) (interface{}, bool) {
+	args := map[string]interface{}{}
+
+	for _, input := range field.Arguments {
+		var given interface{}
+		found := false
+
+		for _, argument := range selection.Arguments {
+			if argument.Name == input.Name {
+				given, found = argument.Value, true
+			}
+		}
+
+		if variable, ok := given.(gqlVariable); ok {
+			_, found = ctx.variables[string(variable)]
+		}
+
+		if !found {
+			if input.HasDefault {
+				args[input.Name] = input.Default
+			}
+
+			continue
+		}
+
+		value, err := coerceInput(input.Type, given, ctx.variables)
+		if err != nil {
+			ctx.addError(newGQLError(selection.line, selection.col, "argument %q: %s", input.Name, err), path)
+			return nil, !field.Type.NonNull
+		}
+
+		args[input.Name] = value
+	}
+
+	value, err := field.Resolve(ctx, parent, args)
+	if err != nil {
+		ctx.addError(newGQLError(selection.line, selection.col, "%s", err), path)
+		return nil, !field.Type.NonNull
+	}
+
+	return ctx.completeValue(field.Type, value, selection, subSelections, path)
+}
+
+// completeValue converts the value returned by a resolver into a value for the response, running the selections on it
+// if it's an object. It returns false if the type is non-null but the value is null.
+func (ctx *gqlContext) completeValue(t *gqlTypeRef, value interface{}, selection gqlSelection, subSelections []gqlSelection, path []interface{}) (interface{}, bool) {
+	if t.NonNull {
+		completed, ok := ctx.completeValue(t.nullable(), value, selection, subSelections, path)
+		if !ok {
+			return nil, false
+		}
+
+		if completed == nil {
+			if isNil(value) {
+				ctx.addError(newGQLError(selection.line, selection.col, "field %q of type %s is null", selection.Name, t), path)
+			}
+
+			return nil, false
+		}
+
+		return completed, true
+	}
+
+	if t.List != nil && value != nil && reflect.ValueOf(value).Kind() == reflect.Slice && reflect.ValueOf(value).IsNil() {
+		// Nil slices, such as the tags of an entry without any, are empty lists rather than null.
+		return []interface{}{}, true
+	}
+
+	if isNil(value) {
+		return nil, true
+	}
+
+	if t.List != nil {
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Slice {
+			ctx.addError(newGQLError(selection.line, selection.col, "field %q should be a list", selection.Name), path)
+			return nil, true
+		}
+
+		list := make([]interface{}, v.Len())
+
+		for i := 0; i < v.Len(); i++ {
+			item, ok := ctx.completeValue(t.List, v.Index(i).Interface(), selection, subSelections, append(append([]interface{}{}, path...), i))
+			if !ok {
+				return nil, true
+			}
+
+			list[i] = item
+		}
+
+		return list, true
+	}
+
+	object := ctx.schema.Objects[t.Name]
+	if object == nil {
+		return value, true
+	}
+
+	result, ok := ctx.executeSelections(object, value, subSelections, path)
+	if !ok {
+		return nil, true
+	}
+
+	return result, true
+}
+
+// isNil returns true if a value is nil, including nil pointers, slices and maps.
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+// addError records an error which happened while running the field at the path given.
+func (ctx *gqlContext) addError(err *gqlError, path []interface{}) {
+	err.Path = path
+	ctx.errors = append(ctx.errors, err)
+}
+
+// SDL returns the schema in the GraphQL schema definition language, for documentation.
+func (s *gqlSchema) SDL() string {
+	var b strings.Builder
+
+	description := func(indent, text string) {
+		if text != "" {
+			b.WriteString(indent + `"""` + text + `"""` + "\n")
+		}
+	}
+
+	scalars := []string{}
+	for name := range s.Scalars {
+		scalars = append(scalars, name)
+	}
+	sort.Strings(scalars)
+
+	for _, name := range scalars {
+		if name == "String" || name == "Int" || name == "Float" || name == "Boolean" || name == "ID" {
+			continue
+		}
+
+		description("", s.Scalars[name])
+		b.WriteString("scalar " + name + "\n\n")
+	}
+
+	names := []string{}
+	for name := range s.Objects {
+		if name != s.Query.Name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range append([]string{s.Query.Name}, names...) {
+		object := s.Objects[name]
+
+		description("", object.Description)
+		b.WriteString("type " + object.Name + " {\n")
+
+		for _, field := range object.Fields {
+			description("  ", field.Description)
+			b.WriteString("  " + field.Name)
+
+			if len(field.Arguments) != 0 {
+				b.WriteString("(\n")
+
+				for _, argument := range field.Arguments {
+					description("    ", argument.Description)
+					b.WriteString("    " + argument.Name + ": " + argument.Type.String())
+
+					if argument.HasDefault {
+						def, _ := json.Marshal(argument.Default)
+						b.WriteString(" = " + string(def))
+					}
+
+					b.WriteString("\n")
+				}
+
+				b.WriteString("  )")
+			}
+
+			b.WriteString(": " + field.Type.String() + "\n")
+		}
+
+		b.WriteString("}\n\n")
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// SetGraphQL enables the GraphQL endpoint at /graphql, which lets clients request exactly the fields they need. It
+// should be called before Serve. By default, it's disabled.
+func (s *Server) SetGraphQL(enabled bool) {
+	s.graphQL = enabled
+}
+
+// checkGraphQL returns true if the GraphQL endpoint is enabled. Otherwise, it aborts the request.
+func (s *Server) checkGraphQL(c *gin.Context) bool {
+	if !s.graphQL {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error_type": "graphql disabled",
+			"error":      "server doesn't have the GraphQL endpoint enabled",
+		})
+		return false
+	}
+
+	return true
+}
+
+// graphQLHandler handles GraphQL requests. They can be made using GET with the "query", "operationName" and "variables"
+// query parameters, or using POST with either a JSON body or, with the Content-Type application/graphql, just the
+// query as the body.
+func (s *Server) graphQLHandler(c *gin.Context) {
+	if !s.checkGraphQL(c) {
+		return
+	}
+
+	var req graphQLRequest
+
+	switch {
+	case c.Request.Method == http.MethodGet:
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+
+		if variables := c.Query("variables"); variables != "" {
+			err := json.Unmarshal([]byte(variables), &req.Variables)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, graphQLResponse{Errors: []*gqlError{{Message: "couldn't parse variables: " + err.Error()}}})
+				return
+			}
+		}
+
+	case strings.HasPrefix(c.ContentType(), "application/graphql"):
+		body, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, graphQLResponse{Errors: []*gqlError{{Message: "couldn't read body: " + err.Error()}}})
+			return
+		}
+
+		req.Query = string(body)
+
+	default:
+		err := c.ShouldBindJSON(&req)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, graphQLResponse{Errors: []*gqlError{{Message: "couldn't parse body: " + err.Error()}}})
+			return
+		}
+	}
+
+	ctx := &gqlContext{Gin: c, Server: s, Values: map[string]interface{}{}}
+
+	resp, err := executeGraphQL(graphQLSchema, ctx, req)
+	if err != nil {
+		gqlErr, ok := err.(*gqlError)
+		if !ok {
+			gqlErr = &gqlError{Message: err.Error()}
+		}
+
+		c.AbortWithStatusJSON(http.StatusBadRequest, graphQLResponse{Errors: []*gqlError{gqlErr}})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// graphQLSchemaHandler serves the GraphQL schema in the schema definition language.
+func (s *Server) graphQLSchemaHandler(c *gin.Context) {
+	if !s.checkGraphQL(c) {
+		return
+	}
+
+	c.String(http.StatusOK, graphQLSchema.SDL())
+}
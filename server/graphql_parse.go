@@ -0,0 +1,1053 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// This file contains a parser for GraphQL documents, as described by https://spec.graphql.org/June2018/. It supports
+// everything needed to write queries: variables, aliases, fragments, inline fragments and directives. Type system
+// definitions, like "type Entry { ... }", aren't supported since the schema is defined in Go.
+
+// gqlTokenKind is the kind of a token in a GraphQL document.
+type gqlTokenKind int
+
+const (
+	gqlEOF gqlTokenKind = iota
+	gqlPunctuator
+	gqlName
+	gqlInt
+	gqlFloat
+	gqlString
+)
+
+// gqlToken is a token in a GraphQL document.
+type gqlToken struct {
+	kind  gqlTokenKind
+	value string
+	line  int
+	col   int
+}
+
+// gqlTypeRef is a reference to a type, such as "String", "[Entry!]!" or "[[String!]]".
+type gqlTypeRef struct {
+	// Name is the name of the type, if it isn't a list.
+	Name string
+
+	// List is the type of the elements, if it's a list.
+	List *gqlTypeRef
+
+	// NonNull is true if values of the type can't be null.
+	NonNull bool
+}
+
+// String returns the type as it would be written in a document.
+func (t *gqlTypeRef) String() string {
+	s := t.Name
+	if t.List != nil {
+		s = "[" + t.List.String() + "]"
+	}
+
+	if t.NonNull {
+		s += "!"
+	}
+
+	return s
+}
+
+// nullable returns the type without its non-null modifier.
+func (t *gqlTypeRef) nullable() *gqlTypeRef {
+	copied := *t
+	copied.NonNull = false
+
+	return &copied
+}
+
+// gqlVariable is a reference to a variable in a value, such as "$path".
+type gqlVariable string
+
+// gqlEnum is an enum value, such as ALPHA. They're kept distinct from strings because strings can't be given where an
+// enum is expected.
+type gqlEnum string
+
+// gqlArgument is an argument given to a field or directive.
+type gqlArgument struct {
+	Name  string
+	Value interface{}
+}
+
+// gqlDirective is a directive on a selection, such as "@include(if: $withContents)".
+type gqlDirective struct {
+	Name      string
+	Arguments []gqlArgument
+	line, col int
+}
+
+// gqlSelection is a field, fragment spread or inline fragment in a selection set.
+type gqlSelection struct {
+	// Alias and Name are set for fields. The alias is blank if there isn't one.
+	Alias string
+	Name  string
+
+	// Arguments are the arguments given to a field.
+	Arguments []gqlArgument
+
+	// Fragment is the name of the fragment for fragment spreads, such as "entryFields" in "...entryFields".
+	Fragment string
+
+	// TypeCondition is the type an inline fragment applies to, such as "Entry" in "... on Entry { path }". It can be
+	// blank.
+	TypeCondition string
+
+	// Inline is true for inline fragments.
+	Inline bool
+
+	Directives []gqlDirective
+
+	// Selections is the selection set of a field or inline fragment.
+	Selections []gqlSelection
+
+	line, col int
+}
+
+// responseKey returns the key the field is given in the response, which is its alias if it has one.
+func (s gqlSelection) responseKey() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+
+	return s.Name
+}
+
+// gqlVariableDefinition is the definition of a variable accepted by an operation, such as "$path: String = "food"".
+type gqlVariableDefinition struct {
+	Name       string
+	Type       *gqlTypeRef
+	Default    interface{}
+	HasDefault bool
+}
+
+// gqlOperation is an operation in a document, such as "query Recipes { ... }".
+type gqlOperation struct {
+	// Type is the type of the operation, "query", "mutation" or "subscription".
+	Type string
+
+	// Name is the name of the operation, which is blank for anonymous operations.
+	Name string
+
+	Variables  []gqlVariableDefinition
+	Directives []gqlDirective
+	Selections []gqlSelection
+
+	line, col int
+}
+
+// gqlFragment is a fragment definition, such as "fragment entryFields on Entry { path title }".
+type gqlFragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []gqlDirective
+	Selections    []gqlSelection
+
+	line, col int
+}
+
+// gqlDocument is a parsed GraphQL document.
+type gqlDocument struct {
+	Operations []*gqlOperation
+	Fragments  map[string]*gqlFragment
+}
+
+// gqlError is an error in a GraphQL request, in the format given in the "errors" list of a response.
+type gqlError struct {
+	Message   string        `json:"message"`
+	Locations []gqlLocation `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+// gqlLocation is where in the document an error happened.
+type gqlLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error returns the error message along with where it happened.
+func (e *gqlError) Error() string {
+	if len(e.Locations) == 0 {
+		return e.Message
+	}
+
+	return fmt.Sprintf("%s (line %d, column %d)", e.Message, e.Locations[0].Line, e.Locations[0].Column)
+}
+
+// newGQLError returns a *gqlError for something at the line and column given.
+func newGQLError(line, col int, format string, a ...interface{}) *gqlError {
+	err := &gqlError{Message: fmt.Sprintf(format, a...)}
+	if line != 0 {
+		err.Locations = []gqlLocation{{Line: line, Column: col}}
+	}
+
+	return err
+}
+
+// gqlLexer splits a GraphQL document into tokens.
+type gqlLexer struct {
+	src  string
+	pos  int
+	line int
+	col  int
+}
+
+// next returns the next token in the document.
+func (l *gqlLexer) next() (gqlToken, error) {
+	l.skipIgnored()
+
+	tok := gqlToken{line: l.line, col: l.col}
+
+	if l.pos >= len(l.src) {
+		tok.kind = gqlEOF
+		return tok, nil
+	}
+
+	c := l.src[l.pos]
+
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		tok.kind, tok.value = gqlPunctuator, "..."
+		l.advance(3)
+	case strings.IndexByte("!$()=:@[]{}|&", c) != -1:
+		tok.kind, tok.value = gqlPunctuator, string(c)
+		l.advance(1)
+	case c == '_' || isGQLLetter(c):
+		start := l.pos
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isGQLLetter(l.src[l.pos]) || isGQLDigit(l.src[l.pos])) {
+			l.advance(1)
+		}
+		tok.kind, tok.value = gqlName, l.src[start:l.pos]
+	case c == '-' || isGQLDigit(c):
+		return l.number(tok)
+	case strings.HasPrefix(l.src[l.pos:], `"""`):
+		return l.blockString(tok)
+	case c == '"':
+		return l.string(tok)
+	default:
+		r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+		return tok, newGQLError(tok.line, tok.col, "unexpected character %q", r)
+	}
+
+	return tok, nil
+}
+
+// advance moves forward n bytes, none of which are newlines.
+func (l *gqlLexer) advance(n int) {
+	l.pos += n
+	l.col += n
+}
+
+// skipIgnored skips whitespace, commas and comments, which have no meaning in GraphQL.
+func (l *gqlLexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == '\n':
+			l.pos++
+			l.line++
+			l.col = 1
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			l.advance(1)
+		case strings.HasPrefix(l.src[l.pos:], "\ufeff"):
+			l.pos += len("\ufeff")
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.advance(1)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// number lexes an integer or float, such as "-12" or "1.5e3".
+func (l *gqlLexer) number(tok gqlToken) (gqlToken, error) {
+	start := l.pos
+	tok.kind = gqlInt
+
+	if l.src[l.pos] == '-' {
+		l.advance(1)
+	}
+
+	digits := func() int {
+		n := 0
+		for l.pos < len(l.src) && isGQLDigit(l.src[l.pos]) {
+			l.advance(1)
+			n++
+		}
+		return n
+	}
+
+	if digits() == 0 {
+		return tok, newGQLError(tok.line, tok.col, "invalid number %q", l.src[start:l.pos])
+	}
+
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		tok.kind = gqlFloat
+		l.advance(1)
+
+		if digits() == 0 {
+			return tok, newGQLError(tok.line, tok.col, "invalid number %q", l.src[start:l.pos])
+		}
+	}
+
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		tok.kind = gqlFloat
+		l.advance(1)
+
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.advance(1)
+		}
+
+		if digits() == 0 {
+			return tok, newGQLError(tok.line, tok.col, "invalid number %q", l.src[start:l.pos])
+		}
+	}
+
+	tok.value = l.src[start:l.pos]
+
+	return tok, nil
+}
+
+// string lexes a string in double quotes, such as "food/pizza".
+func (l *gqlLexer) string(tok gqlToken) (gqlToken, error) {
+	tok.kind = gqlString
+	l.advance(1)
+
+	var b strings.Builder
+
+	for {
+		if l.pos >= len(l.src) || l.src[l.pos] == '\n' {
+			return tok, newGQLError(tok.line, tok.col, "unterminated string")
+		}
+
+		c := l.src[l.pos]
+
+		switch c {
+		case '"':
+			l.advance(1)
+			tok.value = b.String()
+			return tok, nil
+
+		case '\\':
+			if l.pos+1 >= len(l.src) {
+				return tok, newGQLError(tok.line, tok.col, "unterminated string")
+			}
+
+			escape := l.src[l.pos+1]
+			l.advance(2)
+
+			switch escape {
+			case '"', '\\', '/':
+				b.WriteByte(escape)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.pos+4 > len(l.src) {
+					return tok, newGQLError(l.line, l.col, "invalid unicode escape")
+				}
+
+				code, err := strconv.ParseUint(l.src[l.pos:l.pos+4], 16, 32)
+				if err != nil {
+					return tok, newGQLError(l.line, l.col, "invalid unicode escape \\u%s", l.src[l.pos:l.pos+4])
+				}
+
+				b.WriteRune(rune(code))
+				l.advance(4)
+			default:
+				return tok, newGQLError(l.line, l.col-2, "invalid escape \\%c", escape)
+			}
+
+		default:
+			_, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			b.WriteString(l.src[l.pos : l.pos+size])
+			l.advance(size)
+		}
+	}
+}
+
+// blockString lexes a block string in triple quotes, removing the indentation common to all its lines as described in
+// the specification.
+func (l *gqlLexer) blockString(tok gqlToken) (gqlToken, error) {
+	tok.kind = gqlString
+	l.advance(3)
+
+	var raw strings.Builder
+
+	for {
+		if l.pos >= len(l.src) {
+			return tok, newGQLError(tok.line, tok.col, "unterminated block string")
+		}
+
+		switch {
+		case strings.HasPrefix(l.src[l.pos:], `"""`):
+			l.advance(3)
+			tok.value = blockStringValue(raw.String())
+			return tok, nil
+		case strings.HasPrefix(l.src[l.pos:], `\"""`):
+			raw.WriteString(`"""`)
+			l.advance(4)
+		case l.src[l.pos] == '\n':
+			raw.WriteByte('\n')
+			l.pos++
+			l.line++
+			l.col = 1
+		default:
+			raw.WriteByte(l.src[l.pos])
+			l.advance(1)
+		}
+	}
+}
+
+// blockStringValue removes the common indentation and blank leading and trailing lines from a block string.
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+
+	common := -1
+	for _, line := range lines[1:] {
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < len(line) && (common == -1 || indent < common) {
+			common = indent
+		}
+	}
+
+	if common > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= common {
+				lines[i] = lines[i][common:]
+			} else {
+				lines[i] = ""
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func isGQLLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isGQLDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// gqlParser parses a GraphQL document from the tokens given by a gqlLexer.
+type gqlParser struct {
+	lexer *gqlLexer
+	tok   gqlToken
+}
+
+// parseGraphQL parses a GraphQL document.
+func parseGraphQL(src string) (*gqlDocument, error) {
+	p := &gqlParser{lexer: &gqlLexer{src: src, line: 1, col: 1}}
+
+	err := p.advance()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &gqlDocument{Fragments: map[string]*gqlFragment{}}
+
+	for p.tok.kind != gqlEOF {
+		switch {
+		case p.peek(gqlPunctuator, "{"), p.peek(gqlName, "query"), p.peek(gqlName, "mutation"), p.peek(gqlName, "subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+
+			doc.Operations = append(doc.Operations, op)
+
+		case p.peek(gqlName, "fragment"):
+			fragment, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+
+			if doc.Fragments[fragment.Name] != nil {
+				return nil, newGQLError(fragment.line, fragment.col, "there's more than one fragment named %q", fragment.Name)
+			}
+
+			doc.Fragments[fragment.Name] = fragment
+
+		default:
+			return nil, p.unexpected()
+		}
+	}
+
+	if len(doc.Operations) == 0 {
+		return nil, newGQLError(0, 0, "document doesn't contain any operations")
+	}
+
+	return doc, nil
+}
+
+// advance moves to the next token.
+func (p *gqlParser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+
+	p.tok = tok
+
+	return nil
+}
+
+// peek returns true if the current token is of the kind and value given.
+func (p *gqlParser) peek(kind gqlTokenKind, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+// skip moves past the current token if it's the punctuator given, returning true if it was.
+func (p *gqlParser) skip(punctuator string) (bool, error) {
+	if !p.peek(gqlPunctuator, punctuator) {
+		return false, nil
+	}
+
+	return true, p.advance()
+}
+
+// expect moves past the current token, returning an error if it isn't the punctuator given.
+func (p *gqlParser) expect(punctuator string) error {
+	if !p.peek(gqlPunctuator, punctuator) {
+		return p.unexpected()
+	}
+
+	return p.advance()
+}
+
+// name returns the current token if it's a name, moving past it.
+func (p *gqlParser) name() (string, error) {
+	if p.tok.kind != gqlName {
+		return "", p.unexpected()
+	}
+
+	name := p.tok.value
+
+	return name, p.advance()
+}
+
+// unexpected returns an error for the current token.
+func (p *gqlParser) unexpected() error {
+	if p.tok.kind == gqlEOF {
+		return newGQLError(p.tok.line, p.tok.col, "unexpected end of document")
+	}
+
+	return newGQLError(p.tok.line, p.tok.col, "unexpected %q", p.tok.value)
+}
+
+// operation parses an operation, either a selection set on its own or one with a type, name and variables.
+func (p *gqlParser) operation() (*gqlOperation, error) {
+	op := &gqlOperation{Type: "query", line: p.tok.line, col: p.tok.col}
+
+	if p.tok.kind == gqlName {
+		op.Type = p.tok.value
+
+		err := p.advance()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.tok.kind == gqlName {
+			op.Name, err = p.name()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if p.peek(gqlPunctuator, "(") {
+			op.Variables, err = p.variableDefinitions()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		op.Directives, err = p.directives()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+
+	op.Selections, err = p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// variableDefinitions parses the variables accepted by an operation, such as "($path: String!, $n: Int = 10)".
+func (p *gqlParser) variableDefinitions() ([]gqlVariableDefinition, error) {
+	err := p.expect("(")
+	if err != nil {
+		return nil, err
+	}
+
+	definitions := []gqlVariableDefinition{}
+
+	for {
+		done, err := p.skip(")")
+		if err != nil {
+			return nil, err
+		} else if done {
+			return definitions, nil
+		}
+
+		err = p.expect("$")
+		if err != nil {
+			return nil, err
+		}
+
+		var definition gqlVariableDefinition
+
+		definition.Name, err = p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		err = p.expect(":")
+		if err != nil {
+			return nil, err
+		}
+
+		definition.Type, err = p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+
+		hasDefault, err := p.skip("=")
+		if err != nil {
+			return nil, err
+		}
+
+		if hasDefault {
+			definition.HasDefault = true
+
+			definition.Default, err = p.value(true)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		definitions = append(definitions, definition)
+	}
+}
+
+// typeRef parses a reference to a type, such as "[String!]!".
+func (p *gqlParser) typeRef() (*gqlTypeRef, error) {
+	t := &gqlTypeRef{}
+
+	isList, err := p.skip("[")
+	if err != nil {
+		return nil, err
+	}
+
+	if isList {
+		t.List, err = p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+
+		err = p.expect("]")
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		t.Name, err = p.name()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	t.NonNull, err = p.skip("!")
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// fragment parses a fragment definition.
+func (p *gqlParser) fragment() (*gqlFragment, error) {
+	fragment := &gqlFragment{line: p.tok.line, col: p.tok.col}
+
+	err := p.advance()
+	if err != nil {
+		return nil, err
+	}
+
+	fragment.Name, err = p.name()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.peek(gqlName, "on") {
+		return nil, p.unexpected()
+	}
+
+	err = p.advance()
+	if err != nil {
+		return nil, err
+	}
+
+	fragment.TypeCondition, err = p.name()
+	if err != nil {
+		return nil, err
+	}
+
+	fragment.Directives, err = p.directives()
+	if err != nil {
+		return nil, err
+	}
+
+	fragment.Selections, err = p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+
+	return fragment, nil
+}
+
+// selectionSet parses a selection set in braces.
+func (p *gqlParser) selectionSet() ([]gqlSelection, error) {
+	err := p.expect("{")
+	if err != nil {
+		return nil, err
+	}
+
+	selections := []gqlSelection{}
+
+	for {
+		done, err := p.skip("}")
+		if err != nil {
+			return nil, err
+		} else if done {
+			break
+		}
+
+		selection, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+
+		selections = append(selections, selection)
+	}
+
+	if len(selections) == 0 {
+		return nil, newGQLError(p.tok.line, p.tok.col, "selection sets can't be empty")
+	}
+
+	return selections, nil
+}
+
+// selection parses a field, fragment spread or inline fragment.
+func (p *gqlParser) selection() (gqlSelection, error) {
+	selection := gqlSelection{line: p.tok.line, col: p.tok.col}
+
+	isFragment, err := p.skip("...")
+	if err != nil {
+		return selection, err
+	}
+
+	if isFragment {
+		switch {
+		case p.peek(gqlName, "on"):
+			selection.Inline = true
+
+			err = p.advance()
+			if err != nil {
+				return selection, err
+			}
+
+			selection.TypeCondition, err = p.name()
+			if err != nil {
+				return selection, err
+			}
+		case p.tok.kind == gqlName:
+			selection.Fragment, err = p.name()
+			if err != nil {
+				return selection, err
+			}
+		default:
+			selection.Inline = true
+		}
+
+		selection.Directives, err = p.directives()
+		if err != nil {
+			return selection, err
+		}
+
+		if selection.Inline {
+			selection.Selections, err = p.selectionSet()
+		}
+
+		return selection, err
+	}
+
+	selection.Name, err = p.name()
+	if err != nil {
+		return selection, err
+	}
+
+	isAlias, err := p.skip(":")
+	if err != nil {
+		return selection, err
+	}
+
+	if isAlias {
+		selection.Alias = selection.Name
+
+		selection.Name, err = p.name()
+		if err != nil {
+			return selection, err
+		}
+	}
+
+	if p.peek(gqlPunctuator, "(") {
+		selection.Arguments, err = p.arguments(false)
+		if err != nil {
+			return selection, err
+		}
+	}
+
+	selection.Directives, err = p.directives()
+	if err != nil {
+		return selection, err
+	}
+
+	if p.peek(gqlPunctuator, "{") {
+		selection.Selections, err = p.selectionSet()
+	}
+
+	return selection, err
+}
+
+// arguments parses arguments in parentheses, such as "(path: "food", first: 10)". If constant is true, variables
+// aren't allowed.
+func (p *gqlParser) arguments(constant bool) ([]gqlArgument, error) {
+	err := p.expect("(")
+	if err != nil {
+		return nil, err
+	}
+
+	arguments := []gqlArgument{}
+
+	for {
+		done, err := p.skip(")")
+		if err != nil {
+			return nil, err
+		} else if done {
+			break
+		}
+
+		line, col := p.tok.line, p.tok.col
+
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, argument := range arguments {
+			if argument.Name == name {
+				return nil, newGQLError(line, col, "argument %q is given more than once", name)
+			}
+		}
+
+		err = p.expect(":")
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := p.value(constant)
+		if err != nil {
+			return nil, err
+		}
+
+		arguments = append(arguments, gqlArgument{Name: name, Value: value})
+	}
+
+	if len(arguments) == 0 {
+		return nil, newGQLError(p.tok.line, p.tok.col, "arguments can't be empty")
+	}
+
+	return arguments, nil
+}
+
+// directives parses any directives, such as "@skip(if: true)".
+func (p *gqlParser) directives() ([]gqlDirective, error) {
+	directives := []gqlDirective{}
+
+	for p.peek(gqlPunctuator, "@") {
+		directive := gqlDirective{line: p.tok.line, col: p.tok.col}
+
+		err := p.advance()
+		if err != nil {
+			return nil, err
+		}
+
+		directive.Name, err = p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek(gqlPunctuator, "(") {
+			directive.Arguments, err = p.arguments(false)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		directives = append(directives, directive)
+	}
+
+	return directives, nil
+}
+
+// value parses a value. Strings, integers, floats, booleans and null become string, int, float64, bool and nil.
+// Variables become a gqlVariable, enums a gqlEnum, lists a []interface{} and objects a map[string]interface{}. If
+// constant is true, variables aren't allowed.
+func (p *gqlParser) value(constant bool) (interface{}, error) {
+	tok := p.tok
+
+	switch tok.kind {
+	case gqlInt:
+		n, err := strconv.Atoi(tok.value)
+		if err != nil {
+			return nil, newGQLError(tok.line, tok.col, "integer %s is too large", tok.value)
+		}
+
+		return n, p.advance()
+
+	case gqlFloat:
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, newGQLError(tok.line, tok.col, "invalid float %s", tok.value)
+		}
+
+		return f, p.advance()
+
+	case gqlString:
+		return tok.value, p.advance()
+
+	case gqlName:
+		var value interface{}
+
+		switch tok.value {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			value = gqlEnum(tok.value)
+		}
+
+		return value, p.advance()
+	}
+
+	switch {
+	case p.peek(gqlPunctuator, "$"):
+		if constant {
+			return nil, newGQLError(tok.line, tok.col, "variables can't be used here")
+		}
+
+		err := p.advance()
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		return gqlVariable(name), nil
+
+	case p.peek(gqlPunctuator, "["):
+		err := p.advance()
+		if err != nil {
+			return nil, err
+		}
+
+		list := []interface{}{}
+
+		for {
+			done, err := p.skip("]")
+			if err != nil {
+				return nil, err
+			} else if done {
+				return list, nil
+			}
+
+			value, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+
+			list = append(list, value)
+		}
+
+	case p.peek(gqlPunctuator, "{"):
+		err := p.advance()
+		if err != nil {
+			return nil, err
+		}
+
+		object := map[string]interface{}{}
+
+		for {
+			done, err := p.skip("}")
+			if err != nil {
+				return nil, err
+			} else if done {
+				return object, nil
+			}
+
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+
+			err = p.expect(":")
+			if err != nil {
+				return nil, err
+			}
+
+			object[name], err = p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return nil, p.unexpected()
+}
@@ -0,0 +1,588 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/albatross-org/go-albatross/entries"
+)
+
+// graphQLSchema is the schema served at /graphql. It exposes the same entries as the rest of the API, restricted to
+// those the request can access.
+var graphQLSchema = newGraphQLSchema()
+
+// gqlSearchResult is the value of the SearchResult type.
+type gqlSearchResult struct {
+	matched int
+	entries []*entries.Entry
+}
+
+// gqlTagCount is the value of the TagCount type.
+type gqlTagCount struct {
+	tag   string
+	count int
+}
+
+// gqlCollectionKey is the key in gqlContext.Values for the collection the request can access.
+const gqlCollectionKey = "collection"
+
+// graphQLCollection returns the collection the request can access, which is only worked out once per request.
+func graphQLCollection(ctx *gqlContext) (*entries.Collection, error) {
+	if collection, ok := ctx.Values[gqlCollectionKey]; ok {
+		return collection.(*entries.Collection), nil
+	}
+
+	collection, err := ctx.Server.requestCollection(ctx.Gin)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Values[gqlCollectionKey] = collection
+
+	return collection, nil
+}
+
+// gqlType parses a type reference such as "[Entry!]!". It panics if the type isn't valid, so should only be used for
+// types in the schema.
+func gqlType(t string) *gqlTypeRef {
+	doc, err := parseGraphQL("query($v: " + t + ") { __typename }")
+	if err != nil {
+		panic(fmt.Sprintf("invalid type %q: %s", t, err))
+	}
+
+	return doc.Operations[0].Variables[0].Type
+}
+
+// stringArg returns the string argument with the name given, or a blank string if it wasn't given.
+func stringArg(args map[string]interface{}, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+// stringListArg returns the [String!] argument with the name given.
+func stringListArg(args map[string]interface{}, name string) []string {
+	list, _ := args[name].([]interface{})
+
+	strs := []string{}
+	for _, item := range list {
+		strs = append(strs, item.(string))
+	}
+
+	return strs
+}
+
+// stringListsArg returns the [[String!]!] argument with the name given. Like the flags of `albatross get`, each inner
+// list is OR-ed together and the lists are AND-ed.
+func stringListsArg(args map[string]interface{}, name string) [][]string {
+	list, _ := args[name].([]interface{})
+
+	lists := [][]string{}
+	for _, item := range list {
+		lists = append(lists, stringListArg(map[string]interface{}{name: item}, name))
+	}
+
+	return lists
+}
+
+// formatTime formats a time using the layout given, or RFC 3339 if it's blank. Zero times are null.
+func formatTime(t time.Time, layout string) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	return t.Format(layout)
+}
+
+// gqlLists are the arguments to search which are lists of lists of strings, along with the field of entries.Query
+// each sets.
+var gqlLists = []struct {
+	name        string
+	description string
+	set         func(q *entries.Query, value [][]string)
+}{
+	{"contentsExact", "contents to allow, exact", func(q *entries.Query, v [][]string) { q.ContentsExact = v }},
+	{"contentsMatch", "contents to allow, substring", func(q *entries.Query, v [][]string) { q.ContentsMatch = v }},
+	{"contentsExactExclude", "contents to disallow, exact", func(q *entries.Query, v [][]string) { q.ContentsExactExclude = v }},
+	{"contentsMatchExclude", "contents to disallow, substring", func(q *entries.Query, v [][]string) { q.ContentsMatchExclude = v }},
+	{"pathsExact", "paths to allow, exact", func(q *entries.Query, v [][]string) { q.PathsExact = v }},
+	{"pathsMatch", "paths to allow, including the entries inside them", func(q *entries.Query, v [][]string) { q.PathsMatch = v }},
+	{"pathsExactExclude", "paths to disallow, exact", func(q *entries.Query, v [][]string) { q.PathsExactExclude = v }},
+	{"pathsMatchExclude", "paths to disallow, including the entries inside them", func(q *entries.Query, v [][]string) { q.PathsMatchExclude = v }},
+	{"titlesExact", "titles to allow, exact", func(q *entries.Query, v [][]string) { q.TitlesExact = v }},
+	{"titlesMatch", "titles to allow, substring", func(q *entries.Query, v [][]string) { q.TitlesMatch = v }},
+	{"titlesExactExclude", "titles to disallow, exact", func(q *entries.Query, v [][]string) { q.TitlesExactExclude = v }},
+	{"titlesMatchExclude", "titles to disallow, substring", func(q *entries.Query, v [][]string) { q.TitlesMatchExclude = v }},
+	{"contentsRegex", "regular expressions the contents must match", func(q *entries.Query, v [][]string) { q.ContentsRegex = v }},
+	{"pathsRegex", "regular expressions the path must match", func(q *entries.Query, v [][]string) { q.PathsRegex = v }},
+	{"titlesRegex", "regular expressions the title must match", func(q *entries.Query, v [][]string) { q.TitlesRegex = v }},
+}
+
+// searchArguments returns the arguments accepted by Query.search. They mirror the fields of entries.Query.
+func searchArguments() []gqlInputValue {
+	args := []gqlInputValue{
+		{Name: "from", Type: gqlType("String"), Description: "only allow entries with dates after this, a date or something like 'yesterday', 'last week', '2021-03' or '30d'"},
+		{Name: "until", Type: gqlType("String"), Description: "only allow entries with dates before this, a date or something like 'yesterday', 'last week', '2021-03' or '30d'"},
+		{Name: "dateFormat", Type: gqlType("String!"), Default: "2006-01-02 15:04", HasDefault: true, Description: "date format (Go syntax) for parsing from and until"},
+		{Name: "minLength", Type: gqlType("Int"), Description: "minimum length to allow"},
+		{Name: "maxLength", Type: gqlType("Int"), Description: "maximum length to allow"},
+		{Name: "tags", Type: gqlType("[String!]"), Description: "tags to allow"},
+		{Name: "tagsExclude", Type: gqlType("[String!]"), Description: "tags to disallow"},
+	}
+
+	for _, list := range gqlLists {
+		args = append(args, gqlInputValue{
+			Name:        list.name,
+			Type:        gqlType("[[String!]!]"),
+			Description: list.description + ", each inner list is OR-ed together",
+		})
+	}
+
+	return append(args,
+		gqlInputValue{Name: "query", Type: gqlType("String"), Description: "query language expression entries must match, like 'path:school/* AND tag:@?physics'"},
+		gqlInputValue{Name: "sort", Type: gqlType("String"), Description: "sorting scheme, such as 'alpha', 'date', 'path', 'weight', 'length', 'mod', 'meta:author' or 'weight,date'"},
+		gqlInputValue{Name: "rev", Type: gqlType("Boolean!"), Default: false, HasDefault: true, Description: "reverse the entries returned"},
+		gqlInputValue{Name: "first", Type: gqlType("Int"), Description: "number of entries to return"},
+		gqlInputValue{Name: "offset", Type: gqlType("Int!"), Default: 0, HasDefault: true, Description: "number of entries to skip before those returned"},
+	)
+}
+
+// resolveSearch resolves Query.search.
+func resolveSearch(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+	query := entries.Query{
+		Tags:        stringListArg(args, "tags"),
+		TagsExclude: stringListArg(args, "tagsExclude"),
+		Expression:  stringArg(args, "query"),
+	}
+
+	var err error
+	dateFormat := stringArg(args, "dateFormat")
+
+	now := entries.ClockTime(time.Now())
+
+	if from := stringArg(args, "from"); from != "" {
+		date, err := entries.ParseDate(from, dateFormat, now)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing from: %w", err)
+		}
+
+		query.From = date.Start
+	}
+
+	if until := stringArg(args, "until"); until != "" {
+		date, err := entries.ParseDate(until, dateFormat, now)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing until: %w", err)
+		}
+
+		query.Until = date.End
+	}
+
+	query.MinLength, _ = args["minLength"].(int)
+	query.MaxLength, _ = args["maxLength"].(int)
+
+	for _, list := range gqlLists {
+		list.set(&query, stringListsArg(args, list.name))
+	}
+
+	err = query.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := graphQLCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := collection.Filter(query.Filter())
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := filtered.List().SortBy(stringArg(args, "sort"))
+	if err != nil {
+		return nil, err
+	}
+
+	if args["rev"].(bool) {
+		list = list.Reverse()
+	}
+
+	matched := list.Slice()
+
+	offset := args["offset"].(int)
+	if offset < 0 {
+		return nil, fmt.Errorf("offset can't be negative")
+	} else if offset > len(matched) {
+		offset = len(matched)
+	}
+
+	matched = matched[offset:]
+
+	if first, ok := args["first"].(int); ok && first < len(matched) {
+		if first < 0 {
+			return nil, fmt.Errorf("first can't be negative")
+		}
+
+		matched = matched[:first]
+	}
+
+	return gqlSearchResult{matched: filtered.Len(), entries: matched}, nil
+}
+
+// resolveTags resolves Query.tags, giving the tags used by the entries along with how many entries use each, most used
+// first.
+func resolveTags(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+	collection, err := graphQLCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, entry := range collection.List().Slice() {
+		for _, tag := range entry.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := []gqlTagCount{}
+	for tag, count := range counts {
+		tags = append(tags, gqlTagCount{tag: tag, count: count})
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].count != tags[j].count {
+			return tags[i].count > tags[j].count
+		}
+
+		return tags[i].tag < tags[j].tag
+	})
+
+	return tags, nil
+}
+
+// entryField returns a field of the Entry type which is resolved using the function given.
+func entryField(name, t, description string, resolve func(entry *entries.Entry) interface{}) *gqlField {
+	return &gqlField{
+		Name:        name,
+		Type:        gqlType(t),
+		Description: description,
+		Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+			return resolve(parent.(*entries.Entry)), nil
+		},
+	}
+}
+
+// newGraphQLSchema returns the schema for the GraphQL endpoint.
+func newGraphQLSchema() *gqlSchema {
+	formatArg := []gqlInputValue{{Name: "format", Type: gqlType("String"), Description: "date format (Go syntax), default RFC 3339"}}
+
+	query := &gqlObject{
+		Name: "Query",
+		Fields: []*gqlField{
+			{
+				Name:        "search",
+				Description: "Search entries, like /search",
+				Arguments:   searchArguments(),
+				Type:        gqlType("SearchResult"),
+				Resolve:     resolveSearch,
+			},
+			{
+				Name:        "entry",
+				Description: "Get a single entry by its path, or null if it doesn't exist",
+				Arguments:   []gqlInputValue{{Name: "path", Type: gqlType("String!"), Description: "path to the entry, such as food/pizza"}},
+				Type:        gqlType("Entry"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					collection, err := graphQLCollection(ctx)
+					if err != nil {
+						return nil, err
+					}
+
+					return collection.Get(stringArg(args, "path")), nil
+				},
+			},
+			{
+				Name:        "tags",
+				Description: "The tags used by entries and how many entries use each, most used first",
+				Type:        gqlType("[TagCount!]!"),
+				Resolve:     resolveTags,
+			},
+		},
+	}
+
+	searchResult := &gqlObject{
+		Name:        "SearchResult",
+		Description: "The entries which matched a search",
+		Fields: []*gqlField{
+			{
+				Name:        "matched",
+				Description: "The number of entries that matched, before first and offset were applied",
+				Type:        gqlType("Int!"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					return parent.(gqlSearchResult).matched, nil
+				},
+			},
+			{
+				Name:        "entries",
+				Description: "The entries that matched",
+				Type:        gqlType("[Entry!]!"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					return parent.(gqlSearchResult).entries, nil
+				},
+			},
+		},
+	}
+
+	entry := &gqlObject{
+		Name:        "Entry",
+		Description: "An entry in the store",
+		Fields: []*gqlField{
+			entryField("path", "String!", "Path to the entry, such as food/pizza", func(e *entries.Entry) interface{} { return e.Path }),
+			entryField("title", "String!", "Title of the entry", func(e *entries.Entry) interface{} { return e.Title }),
+			{
+				Name:        "date",
+				Description: "Date of the entry, or null if it doesn't have one",
+				Arguments:   formatArg,
+				Type:        gqlType("String"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					return formatTime(parent.(*entries.Entry).Date, stringArg(args, "format")), nil
+				},
+			},
+			{
+				Name:        "modTime",
+				Description: "Modification time of the entry's file",
+				Arguments:   formatArg,
+				Type:        gqlType("String"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					return formatTime(parent.(*entries.Entry).ModTime, stringArg(args, "format")), nil
+				},
+			},
+			entryField("contents", "String!", "Contents of the entry without front matter", func(e *entries.Entry) interface{} { return e.Contents }),
+			entryField("originalContents", "String!", "Contents of the entry's file, including front matter", func(e *entries.Entry) interface{} { return e.OriginalContents }),
+			{
+				Name:        "transcludedContents",
+				Description: "Contents of the entry with the entries it embeds, like {{!food/pizza}}, put in place of the embeds",
+				Type:        gqlType("String!"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					collection, err := graphQLCollection(ctx)
+					if err != nil {
+						return nil, err
+					}
+
+					return collection.Transclude(parent.(*entries.Entry), nil)
+				},
+			},
+			entryField("tags", "[String!]!", "Tags in the entry, such as @?pizza", func(e *entries.Entry) interface{} { return e.Tags }),
+			entryField("metadata", "JSON", "The entry's front matter", func(e *entries.Entry) interface{} { return jsonMetadata(e.Metadata) }),
+			entryField("truncated", "Boolean!", "Whether the contents were cut short because the entry was too long", func(e *entries.Entry) interface{} { return e.Truncated }),
+			entryField("etag", "String!", "The entry's ETag, for use with If-Match when updating it", func(e *entries.Entry) interface{} { return entryETag(e) }),
+			entryField("outboundLinks", "[Link!]!", "Links from this entry to others", func(e *entries.Entry) interface{} { return e.OutboundLinks }),
+			{
+				Name:        "backlinks",
+				Description: "Entries which link to this one, sorted by path",
+				Type:        gqlType("[Entry!]!"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					collection, err := graphQLCollection(ctx)
+					if err != nil {
+						return nil, err
+					}
+
+					return collection.Backlinks(parent.(*entries.Entry)), nil
+				},
+			},
+			{
+				Name:        "attachments",
+				Description: "Files attached to the entry, sorted by name",
+				Type:        gqlType("[Attachment!]!"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					if ctx.Server.store == nil {
+						return nil, fmt.Errorf("server was not started with access to a store")
+					}
+
+					attachments, _, err := ctx.Server.attachments(parent.(*entries.Entry).Path)
+					return attachments, err
+				},
+			},
+		},
+	}
+
+	link := &gqlObject{
+		Name:        "Link",
+		Description: "A link from one entry to another",
+		Fields: []*gqlField{
+			{
+				Name:        "path",
+				Description: "Path linked to, or null if it's a title link",
+				Type:        gqlType("String"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					return nonBlank(parent.(entries.Link).Path), nil
+				},
+			},
+			{
+				Name:        "title",
+				Description: "Title linked to, or null if it's a path link",
+				Type:        gqlType("String"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					return nonBlank(parent.(entries.Link).Title), nil
+				},
+			},
+			{
+				Name:        "store",
+				Description: "Name of the store linked to, for links to other stores like {{work:notes/project}}",
+				Type:        gqlType("String"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					return nonBlank(parent.(entries.Link).Store), nil
+				},
+			},
+			{
+				Name:        "name",
+				Description: "Name the link was given, or null if it wasn't given one",
+				Type:        gqlType("String"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					return nonBlank(parent.(entries.Link).Name), nil
+				},
+			},
+			{
+				Name:        "embed",
+				Description: "Whether the link is an embed, like {{!food/pizza}}",
+				Type:        gqlType("Boolean!"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					return parent.(entries.Link).Embed, nil
+				},
+			},
+			{
+				Name:        "section",
+				Description: "Heading of the section embedded, like Toppings in {{!food/pizza#Toppings}}, or null if there isn't one",
+				Type:        gqlType("String"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					return nonBlank(parent.(entries.Link).Section), nil
+				},
+			},
+			{
+				Name:        "entry",
+				Description: "The entry linked to, or null if it doesn't exist or is in another store",
+				Type:        gqlType("Entry"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					collection, err := graphQLCollection(ctx)
+					if err != nil {
+						return nil, err
+					}
+
+					// Entries in other stores aren't being served, so links to them aren't resolved.
+					link := parent.(entries.Link)
+					if link.Store != "" {
+						return nil, nil
+					}
+
+					return collection.ResolveLink(link), nil
+				},
+			},
+		},
+	}
+
+	attachmentField := func(name, t, description string, resolve func(a attachment) interface{}) *gqlField {
+		return &gqlField{
+			Name:        name,
+			Type:        gqlType(t),
+			Description: description,
+			Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+				return resolve(parent.(attachment)), nil
+			},
+		}
+	}
+
+	attachmentObject := &gqlObject{
+		Name:        "Attachment",
+		Description: "A file attached to an entry",
+		Fields: []*gqlField{
+			attachmentField("name", "String!", "Path of the file relative to the entry's folder", func(a attachment) interface{} { return a.Name }),
+			attachmentField("size", "Int!", "Size of the file in bytes", func(a attachment) interface{} { return a.Size }),
+			attachmentField("hash", "String!", "SHA-256 hash of the file's contents, in hex", func(a attachment) interface{} { return a.Hash }),
+			attachmentField("url", "String!", "Where the file can be downloaded from, relative to the server", func(a attachment) interface{} { return a.URL }),
+		},
+	}
+
+	tagCount := &gqlObject{
+		Name:        "TagCount",
+		Description: "A tag and the number of entries which use it",
+		Fields: []*gqlField{
+			{
+				Name: "tag",
+				Type: gqlType("String!"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					return parent.(gqlTagCount).tag, nil
+				},
+			},
+			{
+				Name: "count",
+				Type: gqlType("Int!"),
+				Resolve: func(ctx *gqlContext, parent interface{}, args map[string]interface{}) (interface{}, error) {
+					return parent.(gqlTagCount).count, nil
+				},
+			},
+		},
+	}
+
+	objects := map[string]*gqlObject{}
+	for _, object := range []*gqlObject{query, searchResult, entry, link, attachmentObject, tagCount} {
+		objects[object.Name] = object
+	}
+
+	return &gqlSchema{
+		Query:   query,
+		Objects: objects,
+		Scalars: map[string]string{
+			"String":  "",
+			"Int":     "",
+			"Float":   "",
+			"Boolean": "",
+			"JSON":    "Any JSON value, used for front matter",
+		},
+	}
+}
+
+// nonBlank returns nil for blank strings, so that they're given as null.
+func nonBlank(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+// jsonMetadata converts front matter into values which can be encoded as JSON. YAML allows maps with keys which aren't
+// strings, which encoding/json can't handle.
+func jsonMetadata(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		converted := map[string]interface{}{}
+		for k, v := range value {
+			converted[k] = jsonMetadata(v)
+		}
+
+		return converted
+
+	case map[interface{}]interface{}:
+		converted := map[string]interface{}{}
+		for k, v := range value {
+			converted[fmt.Sprint(k)] = jsonMetadata(v)
+		}
+
+		return converted
+
+	case []interface{}:
+		converted := make([]interface{}, len(value))
+		for i, v := range value {
+			converted[i] = jsonMetadata(v)
+		}
+
+		return converted
+	}
+
+	return value
+}
@@ -0,0 +1,289 @@
+package server
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	goalbatross "github.com/albatross-org/go-albatross"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification that OpenAPI documents conform to.
+const OpenAPIVersion = "3.0.3"
+
+// OpenAPI returns an OpenAPI 3 document describing the server's endpoints. It is built from the server's routes, and
+// the schemas for responses are derived from the Go types using reflection, so it's always in sync with the server.
+// The document can be marshalled to JSON and used to generate clients in other languages. The endpoints which modify
+// entries are only included if writable is true, like a server using SetWritable.
+func OpenAPI(writable bool) map[string]interface{} {
+	// The handlers aren't used, so the routes from any server will do.
+	s := &Server{}
+
+	gen := &schemaGenerator{components: make(map[string]interface{})}
+	paths := make(map[string]interface{})
+
+	errorSchema := gen.schema(reflect.TypeOf(errorResponse{}))
+
+	for _, r := range s.routes() {
+		if r.Write && !writable {
+			continue
+		}
+
+		params := []interface{}{}
+		for _, p := range r.Parameters {
+			params = append(params, p.openAPI())
+		}
+
+		headers := map[string]interface{}{}
+		if r.Conditional {
+			headers["ETag"] = map[string]interface{}{
+				"description": "A hash of the content of the response.",
+				"schema":      map[string]interface{}{"type": "string"},
+			}
+			headers["Last-Modified"] = map[string]interface{}{
+				"description": "When the entries in the response were last changed.",
+				"schema":      map[string]interface{}{"type": "string"},
+			}
+
+			params = append(params,
+				map[string]interface{}{"name": "If-None-Match", "in": "header", "schema": map[string]interface{}{"type": "string"}},
+				map[string]interface{}{"name": "If-Modified-Since", "in": "header", "schema": map[string]interface{}{"type": "string"}},
+			)
+		}
+
+		status := r.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		contentType := "application/json"
+		if r.Stream {
+			contentType = "text/event-stream"
+		} else if r.Text {
+			contentType = "text/plain"
+		}
+
+		responses := map[string]interface{}{
+			strconv.Itoa(status): map[string]interface{}{
+				"description": "Success.",
+				"headers":     headers,
+				"content": map[string]interface{}{
+					contentType: map[string]interface{}{"schema": gen.schema(reflect.TypeOf(r.Response))},
+				},
+			},
+			"default": map[string]interface{}{
+				"description": "An error.",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": errorSchema},
+				},
+			},
+		}
+
+		if r.Conditional {
+			responses["304"] = map[string]interface{}{"description": "Not modified."}
+		}
+
+		operation := map[string]interface{}{
+			"operationId": r.OperationID,
+			"summary":     r.Summary,
+			"parameters":  params,
+			"responses":   responses,
+		}
+
+		if r.Body != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": gen.schema(reflect.TypeOf(r.Body))},
+				},
+			}
+		} else if r.Upload != "" {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"multipart/form-data": map[string]interface{}{
+						"schema": map[string]interface{}{
+							"type":       "object",
+							"required":   []string{r.Upload},
+							"properties": map[string]interface{}{r.Upload: map[string]interface{}{"type": "string", "format": "binary"}},
+						},
+					},
+				},
+			}
+		}
+
+		path := openAPIPath(r.Path)
+		if paths[path] == nil {
+			paths[path] = make(map[string]interface{})
+		}
+		paths[path].(map[string]interface{})[strings.ToLower(r.Method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": OpenAPIVersion,
+		"info": map[string]interface{}{
+			"title":   "Albatross",
+			"version": goalbatross.Version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": gen.components,
+		},
+	}
+}
+
+// openAPIHandler serves the OpenAPI document for the server, which only includes the endpoints which modify entries if
+// they're allowed.
+func (s *Server) openAPIHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, OpenAPI(s.writable))
+}
+
+// openAPIPath converts a path in gin's syntax, like "/entries/*path" or "/entries/:id", into OpenAPI's syntax, like
+// "/entries/{path}".
+func openAPIPath(path string) string {
+	parts := strings.Split(path, "/")
+
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") || strings.HasPrefix(part, "*") {
+			parts[i] = "{" + part[1:] + "}"
+		}
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// openAPI returns the OpenAPI representation of a parameter.
+func (p parameter) openAPI() map[string]interface{} {
+	schema := map[string]interface{}{"type": p.Type}
+	if p.Array {
+		schema = map[string]interface{}{"type": "array", "items": schema}
+	}
+
+	return map[string]interface{}{
+		"name":        p.Name,
+		"in":          p.In,
+		"description": p.Description,
+		"required":    p.Required || p.In == "path",
+		"schema":      schema,
+	}
+}
+
+// schemaGenerator converts Go types into OpenAPI schemas. Named struct types are placed in components and referenced.
+type schemaGenerator struct {
+	components map[string]interface{}
+}
+
+// schema returns the OpenAPI schema for a type.
+func (g *schemaGenerator) schema(t reflect.Type) map[string]interface{} {
+	if t == nil {
+		return map[string]interface{}{}
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schema(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+
+	// Interfaces and anything else can be any value.
+	return map[string]interface{}{}
+}
+
+// structSchema returns a reference to the schema for a struct, adding it to the components if it isn't there already.
+// Anonymous structs are described inline.
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	if t.Name() == "" {
+		return g.structFields(t)
+	}
+
+	name := componentName(t)
+	ref := map[string]interface{}{"$ref": "#/components/schemas/" + name}
+
+	if _, ok := g.components[name]; ok {
+		return ref
+	}
+
+	// Placeholder, so that recursive types don't recurse forever.
+	g.components[name] = nil
+	g.components[name] = g.structFields(t)
+
+	return ref
+}
+
+// structFields returns the schema for a struct, describing each of its exported fields.
+func (g *schemaGenerator) structFields(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // Unexported.
+		}
+
+		name := field.Name
+		omitEmpty := false
+
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			opts := strings.Split(tag, ",")
+			if opts[0] == "-" {
+				continue
+			} else if opts[0] != "" {
+				name = opts[0]
+			}
+
+			for _, opt := range opts[1:] {
+				if opt == "omitempty" {
+					omitEmpty = true
+				}
+			}
+		}
+
+		properties[name] = g.schema(field.Type)
+		if !omitEmpty {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// componentName returns the name used for a struct type in the components section, such as "entriesEntry" for
+// entries.Entry.
+func componentName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+
+	return pkg + strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPIPaths(t *testing.T) {
+	s := &Server{}
+
+	for _, writable := range []bool{false, true} {
+		paths, ok := OpenAPI(writable)["paths"].(map[string]interface{})
+		if !True(t, ok, "expecting the document to have paths") {
+			return
+		}
+
+		operations := 0
+
+		for _, r := range s.routes() {
+			path := openAPIPath(r.Path)
+			method := strings.ToLower(r.Method)
+
+			var operation map[string]interface{}
+			if methods, ok := paths[path].(map[string]interface{}); ok {
+				operation, _ = methods[method].(map[string]interface{})
+			}
+
+			if r.Write && !writable {
+				Nil(t, operation, "expecting %s %s not to be documented if writes aren't allowed", r.Method, r.Path)
+				continue
+			}
+
+			if NotNil(t, operation, "expecting %s %s to be documented (writable: %t)", r.Method, r.Path, writable) {
+				Equal(t, r.OperationID, operation["operationId"])
+				operations++
+			}
+		}
+
+		documented := 0
+		for _, methods := range paths {
+			documented += len(methods.(map[string]interface{}))
+		}
+
+		Equal(t, operations, documented, "expecting only the server's routes to be documented (writable: %t)", writable)
+	}
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	Equal(t, "/entries/{path}", openAPIPath("/entries/*path"))
+	Equal(t, "/files/{hash}", openAPIPath("/files/:hash"))
+	Equal(t, "/search", openAPIPath("/search"))
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	for _, writable := range []bool{false, true} {
+		s := testServer(t, func(s *Server) {
+			if writable {
+				s.SetWritable(nil)
+			}
+		})
+
+		w := testRequest{Path: "/openapi.json"}.do(s)
+		if !Equal(t, http.StatusOK, w.Code) {
+			continue
+		}
+
+		var doc struct {
+			Paths map[string]map[string]interface{}
+		}
+		NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+
+		_, ok := doc.Paths["/entries/{path}"]["put"]
+		Equal(t, writable, ok, "expecting PUT /entries/{path} to be served only if writes are allowed")
+	}
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tokenBucket is a single client's allowance of requests.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter limits the number of requests each client can make using a token bucket per client. A client is
+// identified by its access token if it gives a valid one, and its IP address otherwise. It is safe for concurrent use.
+// A rateLimiter with a rate of zero allows every request.
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // tokens added per second
+	burst   float64 // maximum tokens in a bucket
+	buckets map[string]*tokenBucket
+}
+
+// newRateLimiter returns a new rateLimiter allowing perMinute requests a minute per client, with bursts of up to burst
+// requests at once.
+func newRateLimiter(perMinute, burst int) *rateLimiter {
+	r := &rateLimiter{}
+	r.set(perMinute, burst)
+	return r
+}
+
+// set changes the limits of the rateLimiter, resetting every client's allowance.
+func (r *rateLimiter) set(perMinute, burst int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	r.rate = float64(perMinute) / 60
+	r.burst = float64(burst)
+	r.buckets = make(map[string]*tokenBucket)
+}
+
+// allow returns true if the client identified by key can make a request now, using up one of its tokens.
+func (r *rateLimiter) allow(key string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rate == 0 {
+		return true
+	}
+
+	bucket, ok := r.buckets[key]
+	if !ok {
+		r.prune(now)
+		bucket = &tokenBucket{tokens: r.burst, last: now}
+		r.buckets[key] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.last).Seconds() * r.rate
+	if bucket.tokens > r.burst {
+		bucket.tokens = r.burst
+	}
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+
+	bucket.tokens--
+	return true
+}
+
+// prune removes buckets which have refilled completely, since they're equivalent to a new bucket. This stops the
+// number of buckets growing forever when lots of different clients make requests.
+func (r *rateLimiter) prune(now time.Time) {
+	for key, bucket := range r.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*r.rate >= r.burst {
+			delete(r.buckets, key)
+		}
+	}
+}
+
+// requestToken returns the access token given in a request, either as a bearer token in the Authorization header or
+// as the "token" query parameter. It returns an empty string if there isn't one.
+func requestToken(c *gin.Context) string {
+	auth := c.GetHeader("Authorization")
+	if strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+
+	return c.Query("token")
+}
+
+// rateLimitMiddleware aborts requests from clients which have exceeded their rate limit. Only tokens which belong to a
+// grant are used to identify clients, since otherwise a client could get a new allowance by making up a new token.
+func (s *Server) rateLimitMiddleware(c *gin.Context) {
+	key := "ip:" + c.ClientIP()
+	if token := requestToken(c); token != "" && s.findGrant(token) != nil {
+		key = "token:" + token
+	}
+
+	if !s.limiter.allow(key, time.Now()) {
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"error_type": "rate limited",
+			"error":      "too many requests, try again later",
+		})
+		return
+	}
+
+	c.Next()
+}
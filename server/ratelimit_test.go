@@ -0,0 +1,54 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	r := newRateLimiter(60, 2)
+	now := time.Now()
+
+	True(t, r.allow("a", now))
+	True(t, r.allow("a", now))
+	False(t, r.allow("a", now), "expecting requests over the burst to be refused")
+	True(t, r.allow("b", now), "expecting clients to have separate allowances")
+
+	True(t, r.allow("a", now.Add(time.Second)), "expecting a token to be added each second")
+	False(t, r.allow("a", now.Add(time.Second)))
+
+	r.set(0, 0)
+	for i := 0; i < 10; i++ {
+		True(t, r.allow("a", now), "expecting a rate of zero to allow every request")
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	s := testServer(t, func(s *Server) {
+		s.SetRateLimit(1, 2)
+		setACL(t, s, Grant{Name: "me", Token: "secret", Paths: []string{""}})
+	})
+
+	for i := 0; i < 2; i++ {
+		w := testRequest{Path: "/stats", Token: "secret"}.do(s)
+		Equal(t, http.StatusOK, w.Code)
+	}
+
+	w := testRequest{Path: "/stats", Token: "secret"}.do(s)
+	Equal(t, http.StatusTooManyRequests, w.Code, "expecting requests over the burst to be rate limited")
+
+	w = testRequest{Path: "/stats", Token: "invalid-1"}.do(s)
+	Equal(t, http.StatusUnauthorized, w.Code, "expecting the IP's allowance to be separate from the token's")
+
+	w = testRequest{Path: "/stats", Token: "invalid-2"}.do(s)
+	Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = testRequest{Path: "/stats", Token: "invalid-3"}.do(s)
+	Equal(t, http.StatusTooManyRequests, w.Code, "expecting invalid tokens to share the allowance of their IP")
+
+	w = testRequest{Path: "/stats"}.do(s)
+	Equal(t, http.StatusTooManyRequests, w.Code, "expecting requests without a token to share the allowance of their IP")
+}
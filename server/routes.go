@@ -1,11 +1,272 @@
 package server
 
-import "github.com/gin-contrib/cors"
+import (
+	"net/http"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/gin-gonic/gin"
+
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+)
+
+// route describes an endpoint on the server. Routes are used both to register handlers and to generate the OpenAPI
+// specification served at /openapi.json, so the description of an endpoint can't drift from what is registered.
+type route struct {
+	Method      string
+	Path        string // Path in gin's syntax, such as "/entries/*path".
+	OperationID string
+	Summary     string
+	Parameters  []parameter
+
+	// Body is a value of the type of the JSON request body, if the endpoint takes one. Upload is the name of the form
+	// field for endpoints which instead take a file as a multipart form.
+	Body   interface{}
+	Upload string
+
+	// Response is a value of the type returned on success, used to describe the response using reflection. Status is
+	// the status code of a successful response, if it isn't 200 OK.
+	Response interface{}
+	Status   int
+
+	// Stream is true if the response is a stream of server-sent events rather than JSON, in which case Response is the
+	// type of the data of each event. Text is true if the response is plain text.
+	Stream bool
+	Text   bool
+
+	// Conditional is true if the endpoint supports ETag and Last-Modified headers.
+	Conditional bool
+
+	// Write is true if the endpoint modifies entries, so needs a grant with write access when the server has an ACL.
+	Write bool
+
+	handler gin.HandlerFunc
+}
+
+// parameter describes a parameter for a route.
+type parameter struct {
+	Name        string
+	In          string // "query", "path" or "header"
+	Description string
+	Type        string // JSON Schema type, such as "string" or "integer"
+	Array       bool   // Whether the parameter can be given multiple times.
+	Required    bool
+}
+
+// errorResponse is the response given when a request fails.
+type errorResponse struct {
+	// ErrorType is a short description of what went wrong, such as "error parsing date".
+	ErrorType string `json:"error_type"`
+
+	// Error is the error message.
+	Error string `json:"error"`
+}
+
+// entryPathParameter is the path parameter for endpoints which use a single entry.
+var entryPathParameter = parameter{Name: "path", In: "path", Type: "string", Required: true, Description: "path to the entry, such as food/pizza"}
+
+// searchParameters are the query parameters accepted by /search. They mirror the flags of `albatross get`.
+var searchParameters = []parameter{
+	{Name: "from", In: "query", Type: "string", Description: "only allow entries with dates after this, a date or something like 'yesterday', 'last week', '2021-03' or '30d'"},
+	{Name: "until", In: "query", Type: "string", Description: "only allow entries with dates before this, a date or something like 'yesterday', 'last week', '2021-03' or '30d'"},
+	{Name: "date-format", In: "query", Type: "string", Description: "date format (Go syntax) for parsing from and until, default '2006-01-02 15:04'"},
+	{Name: "min-length", In: "query", Type: "integer", Description: "minimum length to allow"},
+	{Name: "max-length", In: "query", Type: "integer", Description: "maximum length to allow"},
+	{Name: "tag", In: "query", Type: "string", Array: true, Description: "tags to allow"},
+	{Name: "tag-not", In: "query", Type: "string", Array: true, Description: "tags to disallow"},
+	{Name: "path", In: "query", Type: "string", Array: true, Description: "paths to allow, substring"},
+	{Name: "path-exact", In: "query", Type: "string", Array: true, Description: "paths to allow, exact"},
+	{Name: "path-not", In: "query", Type: "string", Array: true, Description: "paths to disallow, substring"},
+	{Name: "path-exact-not", In: "query", Type: "string", Array: true, Description: "paths to disallow, exact"},
+	{Name: "title", In: "query", Type: "string", Array: true, Description: "titles to allow, substring"},
+	{Name: "title-exact", In: "query", Type: "string", Array: true, Description: "titles to allow, exact"},
+	{Name: "title-not", In: "query", Type: "string", Array: true, Description: "titles to disallow, substring"},
+	{Name: "title-exact-not", In: "query", Type: "string", Array: true, Description: "titles to disallow, exact"},
+	{Name: "contents", In: "query", Type: "string", Array: true, Description: "contents to allow, substring"},
+	{Name: "contents-exact", In: "query", Type: "string", Array: true, Description: "contents to allow, exact"},
+	{Name: "contents-not", In: "query", Type: "string", Array: true, Description: "contents to disallow, substring"},
+	{Name: "contents-exact-not", In: "query", Type: "string", Array: true, Description: "contents to disallow, exact"},
+	{Name: "q", In: "query", Type: "string", Description: "query language expression entries must match, like 'path:school/* AND tag:@?physics', see 'albatross get --help'"},
+	{Name: "delimeter", In: "query", Type: "string", Description: "delimeter for OR-ing values within a single parameter, default ' OR '"},
+	{Name: "sort", In: "query", Type: "string", Description: "sorting scheme, 'alpha', 'date', 'path', 'weight', 'length', 'mod' or 'meta:<key>', more can be given separated by commas to break ties"},
+	{Name: "rev", In: "query", Type: "boolean", Description: "reverse the entries returned"},
+	{Name: "number", In: "query", Type: "integer", Description: "number of entries to return, the size of each page when paging through entries"},
+	{Name: "offset", In: "query", Type: "integer", Description: "number of entries to skip after sorting, for paging through entries. When number or offset is given, ties left by sort are broken by path so each page carries on from the last, and the response gives the offset of the next page"},
+	{Name: "show", In: "query", Type: "string", Description: "'matches' to also return where the title and contents parameters matched each entry"},
+}
+
+// graphQLParameters are the query parameters accepted by GET /graphql.
+var graphQLParameters = []parameter{
+	{Name: "query", In: "query", Type: "string", Required: true, Description: "GraphQL document, see /graphql/schema for the schema"},
+	{Name: "operationName", In: "query", Type: "string", Description: "name of the operation to run, if the document has more than one"},
+	{Name: "variables", In: "query", Type: "string", Description: "values of the operation's variables, as a JSON object"},
+}
+
+// routes returns all the routes served by the server.
+func (s *Server) routes() []route {
+	return []route{
+		{
+			Method:      "GET",
+			Path:        "/search",
+			OperationID: "search",
+			Summary:     "Search entries",
+			Parameters:  searchParameters,
+			Response:    searchResponse{},
+			Conditional: true,
+			handler:     s.searchHandler,
+		},
+		{
+			Method:      "GET",
+			Path:        "/entries/*path",
+			OperationID: "getEntry",
+			Summary:     "Get a single entry by its path",
+			Parameters:  []parameter{entryPathParameter},
+			Response:    entries.Entry{},
+			Conditional: true,
+			handler:     s.entryHandler,
+		},
+		{
+			Method:      "POST",
+			Path:        "/entries/*path",
+			OperationID: "createEntry",
+			Summary:     "Create a new entry",
+			Parameters:  []parameter{entryPathParameter},
+			Body:        writeRequest{},
+			Response:    entries.Entry{},
+			Status:      http.StatusCreated,
+			Write:       true,
+			handler:     s.createHandler,
+		},
+		{
+			Method:      "PUT",
+			Path:        "/entries/*path",
+			OperationID: "updateEntry",
+			Summary:     "Replace the contents of an entry, if it hasn't changed since it was read",
+			Parameters: []parameter{
+				entryPathParameter,
+				{Name: "If-Match", In: "header", Type: "string", Required: true, Description: "ETag of the entry when it was read"},
+			},
+			Body:     writeRequest{},
+			Response: entries.Entry{},
+			Write:    true,
+			handler:  s.updateHandler,
+		},
+		{
+			Method:      "DELETE",
+			Path:        "/entries/*path",
+			OperationID: "deleteEntry",
+			Summary:     "Delete an entry and its attachments",
+			Parameters: []parameter{
+				entryPathParameter,
+				{Name: "If-Match", In: "header", Type: "string", Description: "only delete the entry if it still has this ETag"},
+			},
+			Response: deleteResponse{},
+			Write:    true,
+			handler:  s.deleteHandler,
+		},
+		{
+			Method:      "GET",
+			Path:        "/attachments/*path",
+			OperationID: "listAttachments",
+			Summary:     "List the files attached to an entry",
+			Parameters:  []parameter{entryPathParameter},
+			Response:    attachmentsResponse{},
+			handler:     s.attachmentsHandler,
+		},
+		{
+			Method:      "GET",
+			Path:        "/files/:hash",
+			OperationID: "getFile",
+			Summary:     "Download an attached file by the SHA-256 hash of its contents",
+			Parameters: []parameter{
+				{Name: "hash", In: "path", Type: "string", Required: true, Description: "SHA-256 hash of the file, as given by listAttachments"},
+			},
+			Response: "",
+			handler:  s.fileHandler,
+		},
+		{
+			Method:      "POST",
+			Path:        "/attachments/*path",
+			OperationID: "attachFile",
+			Summary:     "Attach a file to an entry",
+			Parameters: []parameter{
+				entryPathParameter,
+				{Name: "If-Match", In: "header", Type: "string", Description: "only attach the file if the entry still has this ETag"},
+			},
+			Upload:   "file",
+			Response: attachmentsResponse{},
+			Write:    true,
+			handler:  s.attachHandler,
+		},
+		{
+			Method:      "GET",
+			Path:        "/events",
+			OperationID: "listenForChanges",
+			Summary:     "Stream changes to entries as server-sent events named created, updated or deleted",
+			Response:    entryEvent{},
+			Stream:      true,
+			handler:     s.eventsHandler,
+		},
+		{
+			Method:      "GET",
+			Path:        "/graphql",
+			OperationID: "graphQLQuery",
+			Summary:     "Run a GraphQL query given by the query, operationName and variables parameters, if the server has GraphQL enabled",
+			Parameters:  graphQLParameters,
+			Response:    graphQLResponse{},
+			handler:     s.graphQLHandler,
+		},
+		{
+			Method:      "POST",
+			Path:        "/graphql",
+			OperationID: "graphQLQueryPost",
+			Summary:     "Run a GraphQL query, if the server has GraphQL enabled. The body can also be just the query, with the Content-Type application/graphql",
+			Body:        graphQLRequest{},
+			Response:    graphQLResponse{},
+			handler:     s.graphQLHandler,
+		},
+		{
+			Method:      "GET",
+			Path:        "/graphql/schema",
+			OperationID: "getGraphQLSchema",
+			Summary:     "Get the GraphQL schema in the schema definition language, if the server has GraphQL enabled",
+			Response:    "",
+			Text:        true,
+			handler:     s.graphQLSchemaHandler,
+		},
+		{
+			Method:      "GET",
+			Path:        "/folders",
+			OperationID: "listFolders",
+			Summary:     "List the folders described by a _meta.yaml file, for labelling sections of the store",
+			Response:    foldersResponse{},
+			handler:     s.foldersHandler,
+		},
+		{
+			Method:      "GET",
+			Path:        "/stats",
+			OperationID: "getStats",
+			Summary:     "Get statistics about the entries being served",
+			Response:    albatross.Stats{},
+			handler:     s.statsHandler,
+		},
+		{
+			Method:      "GET",
+			Path:        "/openapi.json",
+			OperationID: "getOpenAPI",
+			Summary:     "Get the OpenAPI specification for the server",
+			Response:    map[string]interface{}{},
+			handler:     s.openAPIHandler,
+		},
+	}
+}
 
 // initRoutes sets up the required routes for the server.
 func (s *Server) initRoutes() {
-	s.router.Use(cors.New(cors.Config{
-		AllowOrigins: []string{"https://cdpn.io"},
-	}))
-	s.router.GET("/search", s.searchHandler)
+	s.router.Use(s.corsMiddleware)
+	s.router.Use(s.embedMiddleware)
+	s.router.Use(s.rateLimitMiddleware)
+
+	for _, r := range s.routes() {
+		s.router.Handle(r.Method, r.Path, s.authorize(r), r.handler)
+	}
 }
@@ -11,6 +11,25 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// searchResponse is the response to a search request.
+type searchResponse struct {
+	// Matched is the number of entries that matched the search, before the offset and number parameters were applied.
+	Matched int `json:"matched"`
+
+	// Offset is the number of entries skipped before the entries returned, given by the offset parameter.
+	Offset int `json:"offset"`
+
+	// Next is the offset of the next page of entries, if there are any entries after the ones returned.
+	Next int `json:"next,omitempty"`
+
+	// Entries are the entries that matched.
+	Entries []*entries.Entry `json:"entries"`
+
+	// Matches are where the title and contents parameters matched each entry, by its path. They're only given if the
+	// show parameter is "matches".
+	Matches map[string][]entries.Match `json:"matches,omitempty"`
+}
+
 // multiSplit is like strings.Split except it splits a slice of strings into a slice of slices.
 func multiSplit(strs []string, delimeter string) [][]string {
 	res := [][]string{}
@@ -54,12 +73,15 @@ func requestToCollectionQuery(c *gin.Context) entries.Query {
 	contentsExact := c.QueryArray("contents-exact")
 	contentsMatchNot := c.QueryArray("contents-not")
 	contentsExactNot := c.QueryArray("contents-exact-not")
+	expression := c.Query("q")
 
 	var from, until time.Time
 	var err error
 
+	now := entries.ClockTime(time.Now())
+
 	if fromStr != "" {
-		from, err = time.Parse(dateFormat, fromStr)
+		fromRange, err := entries.ParseDate(fromStr, dateFormat, now)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 				"error_type": "error parsing date",
@@ -67,10 +89,12 @@ func requestToCollectionQuery(c *gin.Context) entries.Query {
 			})
 			return entries.Query{}
 		}
+
+		from = fromRange.Start
 	}
 
 	if untilStr != "" {
-		until, err = time.Parse(dateFormat, untilStr)
+		untilRange, err := entries.ParseDate(untilStr, dateFormat, now)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 				"error_type": "error parsing date",
@@ -78,6 +102,8 @@ func requestToCollectionQuery(c *gin.Context) entries.Query {
 			})
 			return entries.Query{}
 		}
+
+		until = untilRange.End
 	}
 
 	var minLength, maxLength int
@@ -104,6 +130,17 @@ func requestToCollectionQuery(c *gin.Context) entries.Query {
 		}
 	}
 
+	if expression != "" {
+		_, err = entries.ParseExpression(expression)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error_type": "error parsing query",
+				"error":      err.Error(),
+			})
+			return entries.Query{}
+		}
+	}
+
 	return entries.Query{
 		From:  from,
 		Until: until,
@@ -128,21 +165,51 @@ func requestToCollectionQuery(c *gin.Context) entries.Query {
 		TitlesMatch:        multiSplit(titlesMatch, delimeter),
 		TitlesExactExclude: multiSplit(titlesExactNot, delimeter),
 		TitlesMatchExclude: multiSplit(titlesMatchNot, delimeter),
+
+		Expression: expression,
 	}
 }
 
-// searchHandler handles requests for searching.
+// searchHandler handles requests for searching. Successful responses are cached by their query parameters.
 func (s *Server) searchHandler(c *gin.Context) {
+	key := normaliseQuery(c.Request.URL.Query(), "token")
+	if grant := requestGrant(c); grant != nil {
+		// Different grants see different entries, so can't share responses.
+		key = grant.Token + "|" + key
+	}
+	if cached, ok := s.cache.get(key); ok {
+		s.respond(c, cached)
+		return
+	}
+
 	query := requestToCollectionQuery(c)
 	if c.IsAborted() {
 		return
 	}
 
+	show := c.Query("show")
+	if show != "" && show != "matches" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error_type": "error parsing show",
+			"error":      fmt.Sprintf("invalid show %q, expected 'matches'", show),
+		})
+		return
+	}
+
 	fmt.Println(query)
 
 	filter := query.Filter()
 
-	filtered, err := s.collection.Filter(filter)
+	collection, err := s.requestCollection(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error_type": "error filtering collection",
+			"error":      err.Error(),
+		})
+		return
+	}
+
+	filtered, err := collection.Filter(filter)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 			"error_type": "error filtering collection",
@@ -152,46 +219,112 @@ func (s *Server) searchHandler(c *gin.Context) {
 	}
 
 	if filtered.Len() == 0 {
-		c.JSON(http.StatusNotFound, gin.H{
-			"matched": 0,
-			"entries": []string{},
+		s.respondCached(c, key, cachedResponse{
+			status: http.StatusNotFound,
+			body: searchResponse{
+				Matched: 0,
+				Entries: []*entries.Entry{},
+			},
+			etag: listETag(nil, 0),
 		})
 
 		return
 	}
 
 	number := c.Query("number")
+	offset := c.Query("offset")
 	rev := c.Query("rev")
 	sort := c.Query("sort")
 
-	list := filtered.List()
+	var num, off int
 
-	switch sort {
-	case "alpha":
-		list = list.Sort(entries.SortAlpha)
-	case "date":
-		list = list.Sort(entries.SortDate)
+	if number != "" {
+		num, err = strconv.Atoi(number)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error_type": "error parsing number",
+				"error":      err.Error(),
+			})
+			return
+		}
 	}
 
-	if rev == "true" {
-		list = list.Reverse()
-	}
+	if offset != "" {
+		off, err = strconv.Atoi(offset)
+		if err == nil && off < 0 {
+			err = fmt.Errorf("offset can't be negative, got %d", off)
+		}
 
-	if number != "" {
-		num, err := strconv.Atoi(number)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-				"error_type": "error parsing number",
+				"error_type": "error parsing offset",
 				"error":      err.Error(),
 			})
 			return
 		}
+	}
 
-		list = list.First(num)
+	if number != "" || offset != "" {
+		// Each page has to be in the same order for it to carry on from the last.
+		sort = entries.DeterministicSort(sort)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"matched": filtered.Len(),
-		"entries": list.Slice(),
+	list, err := filtered.List().SortBy(sort)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error_type": "error parsing sort",
+			"error":      err.Error(),
+		})
+		return
+	}
+
+	if rev == "true" {
+		list = list.Reverse()
+	}
+
+	if number != "" || offset != "" {
+		list = list.Page(off, num)
+	}
+
+	var next int
+	if end := off + list.Len(); end < filtered.Len() {
+		next = end
+	}
+
+	var matches map[string][]entries.Match
+	if show == "matches" {
+		matches = make(map[string][]entries.Match)
+
+		for _, entry := range list.Slice() {
+			matches[entry.Path] = query.Matches(entry)
+		}
+	}
+
+	s.respondCached(c, key, cachedResponse{
+		status: http.StatusOK,
+		body: searchResponse{
+			Matched: filtered.Len(),
+			Offset:  off,
+			Next:    next,
+			Entries: list.Slice(),
+			Matches: matches,
+		},
+		etag:         listETag(list.Slice(), filtered.Len()),
+		lastModified: s.lastModified(list.Slice()...),
 	})
 }
+
+// respondCached stores a response in the cache under the key given and then writes it.
+func (s *Server) respondCached(c *gin.Context, key string, response cachedResponse) {
+	s.cache.put(key, response)
+	s.respond(c, response)
+}
+
+// respond writes a response, or 304 Not Modified if the client already has it.
+func (s *Server) respond(c *gin.Context, response cachedResponse) {
+	if notModified(c, response.etag, response.lastModified) {
+		return
+	}
+
+	c.JSON(response.status, response.body)
+}
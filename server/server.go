@@ -2,32 +2,138 @@ package server
 
 import (
 	"fmt"
+	"net/http"
+	"sync"
 
 	"github.com/albatross-org/go-albatross/entries"
 	"github.com/gin-gonic/gin"
+
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
 )
 
-// Server allows the viewing and querying (and in the future, editing) of entries over HTTP.
+// Server allows the viewing, querying and editing of entries over HTTP.
 // It wraps a *entries.Collection, meaning "filtered" servers can be made, which only render a subset
 // of a larger Albatross store.
 // Servers can be started using the command line tool, running `albatross get server`.
 type Server struct {
-	collection *entries.Collection
-	router     *gin.Engine
+	mu          sync.RWMutex // guards collection and commitStats
+	collection  *entries.Collection
+	commitStats *albatross.CommitStats // cached for /stats until the collection changes
+	store       *albatross.Store
+	router      *gin.Engine
+
+	cache   *responseCache
+	hashes  *hashCache
+	events  *eventHub
+	limiter *rateLimiter
+	cors    gin.HandlerFunc
+	embed   gin.HandlerFunc
+	grants  []Grant
+
+	writeMu  sync.Mutex // serialises changes to the store, so that If-Match checks can't race
+	writable bool
+	filter   entries.Filter
+
+	graphQL    bool
+	trackViews bool
 }
 
+// DefaultCacheSize is the number of search responses a server caches by default.
+const DefaultCacheSize = 128
+
 // NewServer returns a new server struct from an *entries.Collection.
 func NewServer(collection *entries.Collection) *Server {
 	server := &Server{
 		collection: collection,
 		router:     gin.Default(),
+		cache:      newResponseCache(DefaultCacheSize),
+		hashes:     newHashCache(),
+		events:     newEventHub(),
+		limiter:    newRateLimiter(0, 0),
+		embed:      EmbedMiddleware(nil),
 	}
 
+	server.cors, _ = CORSMiddleware(DefaultCORSOrigins)
+
 	server.initRoutes()
 
 	return server
 }
 
+// SetStore sets the store the server's collection came from. This is needed for endpoints which access the store itself
+// rather than just the entries, such as /stats.
+func (s *Server) SetStore(store *albatross.Store) {
+	s.store = store
+}
+
+// SetTrackViews sets whether entries fetched from /entries are recorded as viewed in the store, so that they're listed
+// by 'albatross recent'. It needs a store set using SetStore. By default, views aren't recorded.
+func (s *Server) SetTrackViews(enabled bool) {
+	s.trackViews = enabled
+}
+
+// SetCollection replaces the collection being served, such as after the store has changed. Any cached responses are
+// discarded, and clients listening to /events are told which entries changed.
+func (s *Server) SetCollection(collection *entries.Collection) {
+	s.mu.Lock()
+	old := s.collection
+	s.collection = collection
+	s.commitStats = nil
+	s.mu.Unlock()
+
+	s.cache.purge()
+
+	if s.events.listening() {
+		s.events.publish(collectionEvents(old, collection))
+	}
+}
+
+// getCollection returns the collection currently being served.
+func (s *Server) getCollection() *entries.Collection {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.collection
+}
+
+// SetCacheSize sets the number of search responses that are cached, discarding any that are currently cached.
+// Responses are cached by their normalised query parameters. A size of zero disables caching.
+func (s *Server) SetCacheSize(size int) {
+	s.cache.resize(size)
+}
+
+// SetRateLimit limits each client to perMinute requests a minute, allowing bursts of up to burst requests at once.
+// Clients are identified by their access token, given as a bearer token or the "token" query parameter, or by their IP
+// address if they don't give one which belongs to a grant. A perMinute of zero disables rate limiting, which is the default.
+func (s *Server) SetRateLimit(perMinute, burst int) {
+	s.limiter.set(perMinute, burst)
+}
+
+// SetCORS sets the origins which are allowed to make cross-origin requests to the server, such as a personal dashboard
+// which fetches entries using JavaScript. See CORSMiddleware for the accepted syntax. It should be called before Serve.
+// By default, only DefaultCORSOrigins are allowed.
+func (s *Server) SetCORS(origins []string) error {
+	handler, err := CORSMiddleware(origins)
+	if err != nil {
+		return err
+	}
+
+	s.cors = handler
+	return nil
+}
+
+// SetAllowEmbed sets the pages which are allowed to embed responses from the server, such as in an iframe on a
+// homepage. See EmbedMiddleware for the accepted syntax. It should be called before Serve. By default, embedding is
+// denied.
+func (s *Server) SetAllowEmbed(ancestors []string) {
+	s.embed = EmbedMiddleware(ancestors)
+}
+
+// Handler returns the http.Handler for the server, such as for serving it using an httptest.Server.
+func (s *Server) Handler() http.Handler {
+	return s.router
+}
+
 // Serve begins accepting requests on the given port.
 func (s *Server) Serve(port int) error {
 	return s.router.Run(":" + fmt.Sprint(port))
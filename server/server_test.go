@@ -0,0 +1,133 @@
+package server
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/otiai10/copy"
+
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+)
+
+const testStorePath = "../pkg/core/testdata/stores/testing.albatross"
+
+// testServer returns a server for the testing store, configured by setup if it isn't nil.
+func testServer(t *testing.T, setup func(s *Server)) *Server {
+	t.Helper()
+
+	store, err := albatross.Load(testStorePath)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	return serveStore(t, store, setup)
+}
+
+// writableTestServer returns a server which allows writes for a copy of the testing store, configured by setup if it
+// isn't nil. The copy is removed by the cleanup func returned.
+func writableTestServer(t *testing.T, setup func(s *Server)) (*Server, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "albatross-server-test")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %s", err)
+	}
+
+	err = copy.Copy(testStorePath, dir)
+	if err != nil {
+		t.Fatalf("couldn't copy test store: %s", err)
+	}
+
+	store, err := albatross.Load(dir)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	s := serveStore(t, store, func(s *Server) {
+		s.SetWritable(nil)
+
+		if setup != nil {
+			setup(s)
+		}
+	})
+
+	return s, func() { os.RemoveAll(dir) }
+}
+
+// serveStore returns a server for the store given.
+func serveStore(t *testing.T, store *albatross.Store, setup func(s *Server)) *Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	collection, err := store.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error getting collection: %s", err)
+	}
+
+	s := NewServer(collection)
+	s.SetStore(store)
+
+	if setup != nil {
+		setup(s)
+	}
+
+	return s
+}
+
+// testRequest is a request made to a server in a test.
+type testRequest struct {
+	Method      string
+	Path        string
+	Token       string
+	ContentType string
+	Body        string
+	Header      map[string]string
+}
+
+// do makes the request to the server, returning the response.
+func (r testRequest) do(s *Server) *httptest.ResponseRecorder {
+	method := r.Method
+	if method == "" {
+		method = "GET"
+	}
+
+	var body io.Reader
+	if r.Body != "" {
+		body = strings.NewReader(r.Body)
+	}
+
+	req := httptest.NewRequest(method, r.Path, body)
+	req.RemoteAddr = "192.0.2.1:1234"
+
+	if r.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.Token)
+	}
+
+	if r.ContentType != "" {
+		req.Header.Set("Content-Type", r.ContentType)
+	}
+
+	for key, value := range r.Header {
+		req.Header.Set(key, value)
+	}
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, req)
+
+	return w
+}
+
+// setACL sets the grants for a server, failing the test if they aren't valid.
+func setACL(t *testing.T, s *Server, grants ...Grant) {
+	t.Helper()
+
+	err := s.SetACL(grants)
+	if err != nil {
+		t.Fatalf("not expecting error setting ACL: %s", err)
+	}
+}
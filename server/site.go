@@ -0,0 +1,32 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SiteHandler returns a handler which serves the files in dir, such as a site written by 'export html', allowing
+// cross-origin requests from corsOrigins and embedding by ancestors in the same way as SetCORS and SetAllowEmbed. It
+// returns an error if the origins aren't valid.
+func SiteHandler(dir string, corsOrigins, ancestors []string) (http.Handler, error) {
+	cors, err := CORSMiddleware(corsOrigins)
+	if err != nil {
+		return nil, err
+	}
+
+	router := gin.New()
+	router.Use(gin.Logger(), gin.Recovery(), cors, EmbedMiddleware(ancestors))
+
+	files := http.FileServer(http.Dir(dir))
+	router.NoRoute(func(c *gin.Context) {
+		if c.Request.Method != "GET" && c.Request.Method != "HEAD" {
+			c.AbortWithStatus(http.StatusMethodNotAllowed)
+			return
+		}
+
+		files.ServeHTTP(c.Writer, c.Request)
+	})
+
+	return router, nil
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+)
+
+// statsHandler handles requests for statistics about the entries being served. The statistics only cover the entries
+// in the server's collection, though git commit counts always describe the whole store. The commit counts are cached
+// until the collection changes, since reading the git history is slow for large stores.
+func (s *Server) statsHandler(c *gin.Context) {
+	if s.store == nil {
+		c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{
+			"error_type": "stats unavailable",
+			"error":      "server was not started with access to a store",
+		})
+		return
+	}
+
+	collection, err := s.requestCollection(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error_type": "error filtering collection",
+			"error":      err.Error(),
+		})
+		return
+	}
+
+	commits, err := s.getCommitStats()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error_type": "error computing stats",
+			"error":      err.Error(),
+		})
+		return
+	}
+
+	stats, err := s.store.CollectionStatsWithCommits(collection, commits)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error_type": "error computing stats",
+			"error":      err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// getCommitStats returns the commit statistics for the store, reading the git history if they haven't been cached since
+// the collection last changed.
+func (s *Server) getCommitStats() (*albatross.CommitStats, error) {
+	s.mu.RLock()
+	commits, collection := s.commitStats, s.collection
+	s.mu.RUnlock()
+
+	if commits != nil {
+		return commits, nil
+	}
+
+	commits, err := s.store.CommitStats()
+	if err != nil {
+		return nil, err
+	}
+
+	// The collection may have changed while the history was being read, in which case the counts could be out of date.
+	s.mu.Lock()
+	if s.collection == collection {
+		s.commitStats = commits
+	}
+	s.mu.Unlock()
+
+	return commits, nil
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/otiai10/copy"
+
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStatsCommitsCached(t *testing.T) {
+	dir, err := ioutil.TempDir("", "albatross-server-test")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = copy.Copy(testStorePath, dir)
+	if err != nil {
+		t.Fatalf("couldn't copy test store: %s", err)
+	}
+
+	_, err = git.PlainInit(filepath.Join(dir, "entries"), false)
+	if err != nil {
+		t.Fatalf("not expecting error when initialising git repository: %s", err)
+	}
+
+	store, err := albatross.Load(dir)
+	if err != nil {
+		t.Fatalf("not expecting error when loading test store: %s", err)
+	}
+
+	_, err = store.CommitChanges("Initial commit")
+	if err != nil {
+		t.Fatalf("not expecting error committing: %s", err)
+	}
+
+	s := serveStore(t, store, nil)
+
+	commits := func() int {
+		w := testRequest{Path: "/stats"}.do(s)
+		Equal(t, http.StatusOK, w.Code, "expecting stats to be returned")
+
+		var stats albatross.Stats
+		err := json.Unmarshal(w.Body.Bytes(), &stats)
+		if err != nil {
+			t.Fatalf("not expecting error decoding stats: %s", err)
+		}
+
+		return stats.Commits
+	}
+
+	Equal(t, 1, commits(), "expecting the initial commit to be counted")
+
+	err = ioutil.WriteFile(filepath.Join(dir, "entries", "food", "pizza", "notes.txt"), []byte("Notes."), 0644)
+	if err != nil {
+		t.Fatalf("not expecting error writing attachment: %s", err)
+	}
+
+	_, err = store.CommitChanges("")
+	if err != nil {
+		t.Fatalf("not expecting error committing: %s", err)
+	}
+
+	Equal(t, 1, commits(), "expecting commit counts to be cached")
+
+	collection, err := store.Collection()
+	if err != nil {
+		t.Fatalf("not expecting error getting collection: %s", err)
+	}
+
+	s.SetCollection(collection)
+	Equal(t, 2, commits(), "expecting setting the collection to discard cached commit counts")
+}
@@ -0,0 +1,402 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/albatross-org/go-albatross/entries"
+	"github.com/gin-gonic/gin"
+
+	albatross "github.com/albatross-org/go-albatross/pkg/core"
+)
+
+// writeRequest is the body of a request which creates or updates an entry.
+type writeRequest struct {
+	// Contents is the whole of the entry's file, including any front matter.
+	Contents string `json:"contents"`
+}
+
+// deleteResponse is the response given when an entry is deleted.
+type deleteResponse struct {
+	// Path is the path of the entry which was deleted.
+	Path string `json:"path"`
+}
+
+// SetWritable allows requests to create, update and delete entries and attach files to them, using the store given to
+// SetStore. After every change, the store's entries are filtered again using filter and served, so it should be the
+// filter used to make the collection given to NewServer, or nil if every entry is served. It should be called before
+// Serve. By default, the server is read-only.
+//
+// Requests which modify entries need the token of a grant with Write set, so at least one should be given to SetACL.
+func (s *Server) SetWritable(filter entries.Filter) {
+	s.writable = true
+	s.filter = filter
+}
+
+// checkWritable returns true if the server allows entries to be modified. Otherwise, it aborts the request. It's checked
+// by authorize for every route which modifies entries.
+func (s *Server) checkWritable(c *gin.Context) bool {
+	if !s.writable || s.store == nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error_type": "server is read-only",
+			"error":      "server doesn't allow entries to be modified",
+		})
+		return false
+	}
+
+	return true
+}
+
+// writePath returns the path of the entry a request modifies, or false if it isn't valid or the request's grant doesn't
+// cover it, in which case the request has been aborted.
+func writePath(c *gin.Context) (string, bool) {
+	entryPath := strings.Trim(c.Param("path"), "/")
+
+	if entryPath == "" || path.Clean(entryPath) != entryPath || entryPath == ".." || strings.HasPrefix(entryPath, "../") {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error_type": "invalid path",
+			"error":      fmt.Sprintf("%q isn't a valid path for an entry", entryPath),
+		})
+		return "", false
+	}
+
+	if !allowed(c, entryPath) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error_type": "forbidden",
+			"error":      "access token can't modify " + entryPath,
+		})
+		return "", false
+	}
+
+	return entryPath, true
+}
+
+// servedEntry returns the entry at the path given if it's being served and is in the store. Otherwise, it aborts the
+// request with 404 Not Found. The entry is taken from the store rather than the collection being served, so that it's
+// current even if the server isn't watching for changes made in other programs.
+func (s *Server) servedEntry(c *gin.Context, entryPath string) *entries.Entry {
+	var entry *entries.Entry
+
+	if s.getCollection().Get(entryPath) != nil {
+		collection, err := s.store.Collection()
+		if err != nil {
+			storeError(c, err)
+			return nil
+		}
+
+		entry = collection.Get(entryPath)
+	}
+
+	if entry == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error_type": "entry not found",
+			"error":      "no entry with path " + entryPath,
+		})
+	}
+
+	return entry
+}
+
+// checkIfMatch checks the request's If-Match header against the current ETag of the entry, so that changes aren't
+// made to an entry which has changed since the client read it. If required is false, requests without the header are
+// allowed. It returns false if the request has been aborted.
+func checkIfMatch(c *gin.Context, entry *entries.Entry, required bool) bool {
+	etag := entryETag(entry)
+
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		if !required {
+			return true
+		}
+
+		c.AbortWithStatusJSON(http.StatusPreconditionRequired, gin.H{
+			"error_type": "precondition required",
+			"error":      "an If-Match header with the entry's ETag is required",
+		})
+		return false
+	}
+
+	// Only strong comparison is allowed for If-Match, see RFC 7232 section 3.1.
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+
+	c.Header("ETag", etag)
+	c.AbortWithStatusJSON(http.StatusPreconditionFailed, gin.H{
+		"error_type": "entry changed",
+		"error":      "entry " + entry.Path + " has changed since it was read",
+	})
+	return false
+}
+
+// bindWriteRequest decodes the body of a request which creates or updates an entry. It returns false if the request
+// has been aborted.
+func bindWriteRequest(c *gin.Context) (writeRequest, bool) {
+	var req writeRequest
+
+	// Only accepting JSON means browsers won't send the request from another origin without a CORS preflight.
+	if c.ContentType() != "application/json" {
+		c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{
+			"error_type": "unsupported media type",
+			"error":      "body must be JSON with a Content-Type of application/json",
+		})
+		return req, false
+	}
+
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error_type": "error parsing body",
+			"error":      err.Error(),
+		})
+		return req, false
+	}
+
+	return req, true
+}
+
+// storeError aborts a request because the store gave an error while reading or modifying it.
+func storeError(c *gin.Context, err error) {
+	var (
+		alreadyExists albatross.ErrEntryAlreadyExists
+		doesntExist   albatross.ErrEntryDoesntExist
+		encrypted     albatross.ErrStoreEncrypted
+	)
+
+	status, errorType := http.StatusInternalServerError, "error modifying store"
+
+	switch {
+	case errors.As(err, &alreadyExists):
+		status, errorType = http.StatusConflict, "entry already exists"
+	case errors.As(err, &doesntExist):
+		status, errorType = http.StatusNotFound, "entry not found"
+	case errors.As(err, &encrypted):
+		status, errorType = http.StatusConflict, "store encrypted"
+	}
+
+	c.AbortWithStatusJSON(status, gin.H{
+		"error_type": errorType,
+		"error":      err.Error(),
+	})
+}
+
+// refresh serves the store's entries again after a change, returning the entry at the path given as it is now.
+func (s *Server) refresh(entryPath string) (*entries.Entry, error) {
+	collection, err := s.store.Collection()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := collection
+	if s.filter != nil {
+		filtered, err = collection.Filter(s.filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.SetCollection(filtered)
+
+	return collection.Get(entryPath), nil
+}
+
+// respondEntry writes the entry at the path given after it has been changed, along with its new ETag.
+func (s *Server) respondEntry(c *gin.Context, status int, entryPath string) {
+	entry, err := s.refresh(entryPath)
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	if entry == nil {
+		// The entry couldn't be parsed, but it has still been written.
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.Header("ETag", entryETag(entry))
+	c.JSON(status, entry)
+}
+
+// createHandler handles requests to create a new entry, such as POST /entries/food/pizza.
+func (s *Server) createHandler(c *gin.Context) {
+	entryPath, ok := writePath(c)
+	if !ok {
+		return
+	}
+
+	req, ok := bindWriteRequest(c)
+	if !ok {
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	err := s.store.Create(entryPath, req.Contents)
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	c.Header("Location", "/entries/"+entryPath)
+	s.respondEntry(c, http.StatusCreated, entryPath)
+}
+
+// updateHandler handles requests to replace the contents of an entry, such as PUT /entries/food/pizza. The request must
+// have an If-Match header with the ETag of the entry as the client last read it, so that changes made since then
+// aren't overwritten.
+func (s *Server) updateHandler(c *gin.Context) {
+	entryPath, ok := writePath(c)
+	if !ok {
+		return
+	}
+
+	req, ok := bindWriteRequest(c)
+	if !ok {
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	entry := s.servedEntry(c, entryPath)
+	if entry == nil || !checkIfMatch(c, entry, true) {
+		return
+	}
+
+	err := s.store.Update(entryPath, req.Contents)
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	s.respondEntry(c, http.StatusOK, entryPath)
+}
+
+// deleteHandler handles requests to delete an entry and its attachments, such as DELETE /entries/food/pizza. If the
+// request has an If-Match header, the entry is only deleted if it hasn't changed.
+func (s *Server) deleteHandler(c *gin.Context) {
+	entryPath, ok := writePath(c)
+	if !ok {
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	entry := s.servedEntry(c, entryPath)
+	if entry == nil || !checkIfMatch(c, entry, false) {
+		return
+	}
+
+	err := s.store.Delete(entryPath)
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	_, err = s.refresh(entryPath)
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deleteResponse{Path: entryPath})
+}
+
+// attachHandler handles requests to attach a file to an entry, such as POST /attachments/food/pizza. The file is sent
+// as the "file" field of a multipart form and keeps its name, which mustn't be the name of an existing attachment.
+func (s *Server) attachHandler(c *gin.Context) {
+	entryPath, ok := writePath(c)
+	if !ok {
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error_type": "error reading file",
+			"error":      err.Error(),
+		})
+		return
+	}
+
+	name := filepath.Base(filepath.FromSlash(strings.ReplaceAll(header.Filename, `\`, "/")))
+	if name == "" || strings.HasPrefix(name, ".") || name == "entry.md" || name == albatross.AnnotationsFile {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error_type": "invalid file name",
+			"error":      fmt.Sprintf("can't attach a file named %q", header.Filename),
+		})
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	entry := s.servedEntry(c, entryPath)
+	if entry == nil || !checkIfMatch(c, entry, false) {
+		return
+	}
+
+	attachments, err := s.store.Attachments(entryPath)
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	for _, attachment := range attachments {
+		if attachment == name {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+				"error_type": "attachment already exists",
+				"error":      fmt.Sprintf("entry %s already has an attachment named %s", entryPath, name),
+			})
+			return
+		}
+	}
+
+	// Store.Attach names attachments after the file being attached, so the upload is saved under its own name.
+	dir, err := ioutil.TempDir("", "albatross-upload")
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, name)
+
+	err = c.SaveUploadedFile(header, file)
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	err = s.store.Attach(entryPath, file)
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	_, err = s.refresh(entryPath)
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	listed, _, err := s.attachments(entryPath)
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attachmentsResponse{Path: entryPath, Attachments: listed})
+}
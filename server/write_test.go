@@ -0,0 +1,64 @@
+package server
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestWritesNeedWriteGrant(t *testing.T) {
+	s, cleanup := writableTestServer(t, nil)
+	defer cleanup()
+
+	w := testRequest{Method: "POST", Path: "/entries/food/burger", ContentType: "application/json", Body: `{"contents": "A burger."}`}.do(s)
+	Equal(t, http.StatusUnauthorized, w.Code, "expecting writes to need a token even without an ACL")
+
+	setACL(t, s,
+		Grant{Name: "reader", Token: "reader", Paths: []string{""}},
+		Grant{Name: "writer", Token: "writer", Paths: []string{""}, Write: true},
+	)
+
+	w = testRequest{Method: "POST", Path: "/entries/food/burger", Token: "reader", ContentType: "application/json", Body: `{"contents": "A burger."}`}.do(s)
+	Equal(t, http.StatusForbidden, w.Code, "expecting read-only grants not to be able to write")
+
+	w = testRequest{Method: "DELETE", Path: "/entries/food/pizza", Token: "reader"}.do(s)
+	Equal(t, http.StatusForbidden, w.Code, "expecting read-only grants not to be able to delete")
+
+	w = testRequest{Method: "POST", Path: "/entries/food/burger", Token: "writer", ContentType: "application/json", Body: `{"contents": "A burger."}`}.do(s)
+	Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func TestWritesNeedJSON(t *testing.T) {
+	s, cleanup := writableTestServer(t, func(s *Server) {
+		setACL(t, s, Grant{Name: "writer", Token: "writer", Paths: []string{""}, Write: true})
+	})
+	defer cleanup()
+
+	for _, contentType := range []string{"text/plain", "application/x-www-form-urlencoded", ""} {
+		w := testRequest{Method: "POST", Path: "/entries/food/burger", Token: "writer", ContentType: contentType, Body: `{"contents": "A burger."}`}.do(s)
+		Equal(t, http.StatusUnsupportedMediaType, w.Code, "expecting a Content-Type of %q to be rejected", contentType)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	NoError(t, form.WriteField("contents", "A burger."))
+	NoError(t, form.Close())
+
+	w := testRequest{Method: "PUT", Path: "/entries/food/pizza", Token: "writer", ContentType: form.FormDataContentType(), Body: body.String(), Header: map[string]string{"If-Match": "*"}}.do(s)
+	Equal(t, http.StatusUnsupportedMediaType, w.Code, "expecting multipart updates to be rejected")
+
+	w = testRequest{Method: "POST", Path: "/entries/food/burger", Token: "writer", ContentType: "application/json; charset=utf-8", Body: `{"contents": "A burger."}`}.do(s)
+	Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func TestReadOnlyServer(t *testing.T) {
+	s := testServer(t, func(s *Server) {
+		setACL(t, s, Grant{Name: "writer", Token: "writer", Paths: []string{""}, Write: true})
+	})
+
+	w := testRequest{Method: "POST", Path: "/entries/food/burger", Token: "writer", ContentType: "application/json", Body: `{"contents": "A burger."}`}.do(s)
+	Equal(t, http.StatusForbidden, w.Code, "expecting servers to be read-only unless SetWritable is used")
+}